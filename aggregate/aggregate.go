@@ -0,0 +1,249 @@
+/*
+Package aggregate implements the fan-out of backend for frontend (BFF) actions that compose the
+results of multiple downstream actions into a single response. The branches of an aggregate run
+concurrently, each with its own timeout, and their results are mapped into the fields of the
+composite response.
+
+A branch may be optional: if an optional branch fails the composite response is built from the
+results of the other branches and the failure is reported in the Result. If a required branch
+fails the other branches are canceled and Run returns an *Error.
+
+Actions that declare an aggregate with the Aggregate DSL get a generated constructor that builds
+the aggregate from the branch implementations provided by the controller, e.g.:
+
+	a := app.NewShowDashboardAggregate(&app.ShowDashboardAggregateBranches{Order: fetchOrder, Bottles: fetchBottles})
+	res := &app.Dashboard{}
+	if _, err := a.Run(ctx, res); err != nil {
+		return err
+	}
+*/
+package aggregate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"context"
+)
+
+type (
+	// FetchFunc is the function that calls a downstream action. The returned value must be
+	// serializable to JSON.
+	FetchFunc func(ctx context.Context) (interface{}, error)
+
+	// Mapping maps a field of the result of a branch to a field of the composite response.
+	Mapping struct {
+		// From is the dot separated path to the field of the branch result, the empty string
+		// denotes the whole result.
+		From string
+		// To is the dot separated path to the field of the composite response.
+		To string
+	}
+
+	// Branch is a single downstream call of an aggregate.
+	Branch struct {
+		// Name is the branch name.
+		Name string
+		// Fetch calls the downstream action.
+		Fetch FetchFunc
+		// Timeout is the maximum duration of the call, zero means no timeout.
+		Timeout time.Duration
+		// Optional is true if the composite response can be built without the branch result.
+		Optional bool
+		// Mappings lists the fields of the branch result copied to the composite response.
+		Mappings []*Mapping
+	}
+
+	// Aggregate runs branches concurrently and composes their results.
+	Aggregate struct {
+		branches []*Branch
+	}
+
+	// Result describes the outcome of an aggregate run.
+	Result struct {
+		// Failed contains the errors returned by the optional branches that failed indexed
+		// by branch name.
+		Failed map[string]error
+	}
+
+	// Error is the error returned by Run when a required branch fails.
+	Error struct {
+		// Branch is the name of the branch that failed.
+		Branch string
+		// Err is the error returned by the branch.
+		Err error
+	}
+
+	// outcome is the result of a branch.
+	outcome struct {
+		branch *Branch
+		value  interface{}
+		err    error
+	}
+)
+
+// New creates an empty aggregate. Branches are added with Add.
+func New() *Aggregate {
+	return &Aggregate{}
+}
+
+// Add adds a branch to the aggregate.
+func (a *Aggregate) Add(b *Branch) *Aggregate {
+	a.branches = append(a.branches, b)
+	return a
+}
+
+// Branches returns the names of the aggregate branches.
+func (a *Aggregate) Branches() []string {
+	names := make([]string, len(a.branches))
+	for i, b := range a.branches {
+		names[i] = b.Name
+	}
+	return names
+}
+
+// Run calls all the branches concurrently and maps their results into res which must be a
+// pointer to the composite response. Run returns an *Error if a required branch fails, the other
+// branches are canceled in this case. The failures of optional branches are reported in the
+// returned Result.
+func (a *Aggregate) Run(ctx context.Context, res interface{}) (*Result, error) {
+	for _, b := range a.branches {
+		if b.Fetch == nil {
+			return nil, fmt.Errorf("aggregate: no implementation for branch %s", b.Name)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make([]*outcome, len(a.branches))
+	var wg sync.WaitGroup
+	for i, b := range a.branches {
+		wg.Add(1)
+		go func(i int, b *Branch) {
+			defer wg.Done()
+			bctx := ctx
+			if b.Timeout > 0 {
+				var bcancel context.CancelFunc
+				bctx, bcancel = context.WithTimeout(ctx, b.Timeout)
+				defer bcancel()
+			}
+			v, err := b.Fetch(bctx)
+			if err == nil && bctx.Err() != nil {
+				err = bctx.Err()
+			}
+			if err != nil && !b.Optional {
+				cancel()
+			}
+			outcomes[i] = &outcome{branch: b, value: v, err: err}
+		}(i, b)
+	}
+	wg.Wait()
+
+	result := &Result{}
+	composite := make(map[string]interface{})
+	var failed *Error
+	for _, o := range outcomes {
+		if o.err != nil {
+			if !o.branch.Optional {
+				if failed == nil || failed.Err == context.Canceled {
+					failed = &Error{Branch: o.branch.Name, Err: o.err}
+				}
+				continue
+			}
+			if result.Failed == nil {
+				result.Failed = make(map[string]error)
+			}
+			result.Failed[o.branch.Name] = o.err
+			continue
+		}
+		if err := o.apply(composite); err != nil {
+			return nil, err
+		}
+	}
+	if failed != nil {
+		return nil, failed
+	}
+	js, err := json.Marshal(composite)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(js, res); err != nil {
+		return nil, fmt.Errorf("aggregate: failed to build composite response: %s", err)
+	}
+	return result, nil
+}
+
+// Failures returns the sorted names of the optional branches that failed.
+func (r *Result) Failures() []string {
+	names := make([]string, 0, len(r.Failed))
+	for n := range r.Failed {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return fmt.Sprintf("branch %s failed: %s", e.Branch, e.Err)
+}
+
+// apply copies the mapped fields of the branch result into composite.
+func (o *outcome) apply(composite map[string]interface{}) error {
+	js, err := json.Marshal(o.value)
+	if err != nil {
+		return fmt.Errorf("aggregate: branch %s: %s", o.branch.Name, err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(js, &value); err != nil {
+		return fmt.Errorf("aggregate: branch %s: %s", o.branch.Name, err)
+	}
+	for _, m := range o.branch.Mappings {
+		v, ok := lookup(value, m.From)
+		if !ok {
+			continue
+		}
+		if err := assign(composite, m.To, v); err != nil {
+			return fmt.Errorf("aggregate: branch %s: %s", o.branch.Name, err)
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of the field with the given path.
+func lookup(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, value != nil
+	}
+	for _, elem := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = obj[elem]; !ok {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+// assign sets the field with the given path creating the intermediary objects as needed.
+func assign(obj map[string]interface{}, path string, value interface{}) error {
+	elems := strings.Split(path, ".")
+	for _, elem := range elems[:len(elems)-1] {
+		child, ok := obj[elem]
+		if !ok {
+			child = make(map[string]interface{})
+			obj[elem] = child
+		}
+		if obj, ok = child.(map[string]interface{}); !ok {
+			return fmt.Errorf("cannot assign %s: %s is not an object", path, elem)
+		}
+	}
+	obj[elems[len(elems)-1]] = value
+	return nil
+}
@@ -0,0 +1,99 @@
+package aggregate_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa/aggregate"
+)
+
+type (
+	bottle struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	dashboard struct {
+		Order      map[string]interface{} `json:"order,omitempty"`
+		BottleName string                 `json:"bottle_name,omitempty"`
+		Reviews    []string               `json:"reviews,omitempty"`
+	}
+)
+
+func value(v interface{}) aggregate.FetchFunc {
+	return func(context.Context) (interface{}, error) { return v, nil }
+}
+
+func failure(msg string) aggregate.FetchFunc {
+	return func(context.Context) (interface{}, error) { return nil, errors.New(msg) }
+}
+
+func TestRun(t *testing.T) {
+	a := aggregate.New().
+		Add(&aggregate.Branch{Name: "order", Fetch: value(map[string]interface{}{"id": 1}), Mappings: []*aggregate.Mapping{{To: "order"}}}).
+		Add(&aggregate.Branch{Name: "bottle", Fetch: value(&bottle{ID: 2, Name: "merlot"}), Mappings: []*aggregate.Mapping{{From: "name", To: "bottle_name"}}}).
+		Add(&aggregate.Branch{Name: "reviews", Fetch: failure("unavailable"), Optional: true, Mappings: []*aggregate.Mapping{{To: "reviews"}}})
+
+	var res dashboard
+	result, err := a.Run(context.Background(), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := dashboard{Order: map[string]interface{}{"id": float64(1)}, BottleName: "merlot"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("got %+v, expected %+v", res, expected)
+	}
+	if f := result.Failures(); !reflect.DeepEqual(f, []string{"reviews"}) {
+		t.Errorf("got failures %v, expected [reviews]", f)
+	}
+}
+
+func TestRunRequiredFailure(t *testing.T) {
+	canceled := make(chan struct{})
+	a := aggregate.New().
+		Add(&aggregate.Branch{Name: "order", Fetch: failure("boom")}).
+		Add(&aggregate.Branch{Name: "bottle", Fetch: func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			close(canceled)
+			return nil, ctx.Err()
+		}})
+
+	_, err := a.Run(context.Background(), &dashboard{})
+	aerr, ok := err.(*aggregate.Error)
+	if !ok {
+		t.Fatalf("got error %v, expected *aggregate.Error", err)
+	}
+	if aerr.Branch != "order" {
+		t.Errorf("got failed branch %s, expected order", aerr.Branch)
+	}
+	select {
+	case <-canceled:
+	default:
+		t.Error("other branches were not canceled")
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	slow := func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return "late", nil
+		}
+	}
+	a := aggregate.New().
+		Add(&aggregate.Branch{Name: "reviews", Fetch: slow, Timeout: 10 * time.Millisecond, Optional: true})
+
+	result, err := a.Run(context.Background(), &dashboard{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if result.Failed["reviews"] != context.DeadlineExceeded {
+		t.Errorf("got %v, expected deadline exceeded", result.Failed["reviews"])
+	}
+}
@@ -33,6 +33,9 @@ type (
 		UserAgent string
 		// Dump indicates whether to dump request response.
 		Dump bool
+		// Retry is the policy used to retry requests that fail with a transient error, nil
+		// disables retries.
+		Retry *RetryPolicy
 	}
 )
 
@@ -77,7 +80,7 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	if c.Dump {
 		c.dumpRequest(ctx, req)
 	}
-	resp, err := c.Doer.Do(ctx, req)
+	resp, err := c.do(ctx, req)
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return nil, err
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// ResponseError is the error returned by generated clients when a service responds with a 4xx or
+// 5xx status. It exposes the status, headers and decoded body so that client code can handle the
+// error programmatically.
+type ResponseError struct {
+	// Status is the HTTP status code of the response.
+	Status int
+	// Header contains the response headers.
+	Header http.Header
+	// Body is the decoded response body, nil if the body could not be decoded. Its type is
+	// the type of the error media type defined in the design for the response status, by
+	// default *goa.ErrorResponse.
+	Body interface{}
+	// RawBody is the response body when it could not be decoded, e.g. the HTML or plain text
+	// error page of a proxy. It is only set if the body was buffered with BufferBody.
+	RawBody []byte
+	// RetryAfter is the delay indicated by the service before the request may be retried,
+	// zero if not specified.
+	RetryAfter time.Duration
+}
+
+// NewResponseError creates a response error from the given response and decoded body. body is
+// nil if the response body could not be decoded, RawBody is then set to the response body if it
+// was buffered with BufferBody.
+func NewResponseError(resp *http.Response, body interface{}) *ResponseError {
+	e := &ResponseError{Status: resp.StatusCode, Header: resp.Header, Body: body}
+	if b, ok := resp.Body.(*bufferedBody); ok && body == nil {
+		e.RawBody = b.raw
+	}
+	e.RetryAfter = retryAfter(resp.Header)
+	if e.RetryAfter == 0 {
+		if er := e.ErrorResponse(); er != nil && er.RetryAfter > 0 {
+			e.RetryAfter = time.Duration(er.RetryAfter) * time.Second
+		}
+	}
+	return e
+}
+
+// Temporary returns true if the request that caused the error may be retried, that is if the
+// response status is 429 or 503 or if the decoded error is flagged as transient.
+func (e *ResponseError) Temporary() bool {
+	if e.Status == http.StatusTooManyRequests || e.Status == http.StatusServiceUnavailable {
+		return true
+	}
+	if er := e.ErrorResponse(); er != nil {
+		return er.Transient
+	}
+	return false
+}
+
+// Error returns the error message, it includes the body error message when the body is an error.
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	if err, ok := e.Body.(error); ok {
+		msg += ": " + err.Error()
+	}
+	return msg
+}
+
+// ErrorResponse returns the decoded body if it is a goa error response, nil otherwise.
+func (e *ResponseError) ErrorResponse() *goa.ErrorResponse {
+	if resp, ok := e.Body.(*goa.ErrorResponse); ok {
+		return resp
+	}
+	return nil
+}
+
+// IsResponseError returns the response error wrapped by err if any and true, nil and false
+// otherwise. It follows the chain of errors implementing Cause (as created by
+// github.com/pkg/errors) until it finds a response error.
+func IsResponseError(err error) (*ResponseError, bool) {
+	type causer interface {
+		Cause() error
+	}
+	for err != nil {
+		if e, ok := err.(*ResponseError); ok {
+			return e, true
+		}
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+	return nil, false
+}
@@ -0,0 +1,94 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+	"github.com/pkg/errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResponseError", func() {
+	var resp *http.Response
+	var body interface{}
+	var respErr *client.ResponseError
+
+	BeforeEach(func() {
+		resp = &http.Response{
+			StatusCode: 404,
+			Header:     http.Header{"X-Request-Id": []string{"foo"}},
+		}
+		body = nil
+	})
+
+	JustBeforeEach(func() {
+		respErr = client.NewResponseError(resp, body)
+	})
+
+	It("exposes the status and headers", func() {
+		Ω(respErr.Status).Should(Equal(404))
+		Ω(respErr.Header.Get("X-Request-Id")).Should(Equal("foo"))
+		Ω(respErr.Error()).Should(Equal("404 Not Found"))
+		Ω(respErr.ErrorResponse()).Should(BeNil())
+	})
+
+	Context("with a goa error response body", func() {
+		BeforeEach(func() {
+			body = goa.ErrNotFound("bottle not found")
+		})
+
+		It("exposes the decoded body", func() {
+			Ω(respErr.ErrorResponse()).ShouldNot(BeNil())
+			Ω(respErr.ErrorResponse().Detail).Should(Equal("bottle not found"))
+			Ω(respErr.Error()).Should(ContainSubstring("bottle not found"))
+		})
+	})
+
+	Context("with a buffered body that could not be decoded", func() {
+		BeforeEach(func() {
+			resp.StatusCode = 502
+			resp.Header.Set("Content-Type", "text/html")
+			resp.Body = ioutil.NopCloser(strings.NewReader("<html>Bad Gateway</html>"))
+			client.BufferBody(resp)
+		})
+
+		It("keeps the raw body", func() {
+			Ω(respErr.Body).Should(BeNil())
+			Ω(string(respErr.RawBody)).Should(Equal("<html>Bad Gateway</html>"))
+		})
+	})
+
+	Context("with a transient error response body", func() {
+		BeforeEach(func() {
+			resp.StatusCode = 429
+			body = goa.RateLimitError("slow down", 5*time.Second)
+		})
+
+		It("exposes the retry delay", func() {
+			Ω(respErr.Temporary()).Should(BeTrue())
+			Ω(respErr.RetryAfter).Should(Equal(5 * time.Second))
+		})
+	})
+
+	Context("IsResponseError", func() {
+		It("returns the response error", func() {
+			e, ok := client.IsResponseError(respErr)
+			Ω(ok).Should(BeTrue())
+			Ω(e).Should(Equal(respErr))
+			_, ok = client.IsResponseError(errors.New("foo"))
+			Ω(ok).Should(BeFalse())
+		})
+
+		It("unwraps wrapped errors", func() {
+			e, ok := client.IsResponseError(errors.Wrap(respErr, "request failed"))
+			Ω(ok).Should(BeTrue())
+			Ω(e).Should(Equal(respErr))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// maxErrorBodySize is the maximum size of error response bodies decoded to determine whether the
+// error is transient.
+const maxErrorBodySize = 1 << 16
+
+// RetryPolicy describes how requests that fail with a transient error are retried. A response is
+// considered transient if its status is 429 (Too Many Requests) or 503 (Service Unavailable) or if
+// its body is a goa error response flagged as transient. The delay between attempts honors the
+// Retry-After response header or the error response retry_after field when present and otherwise
+// doubles for each attempt starting at Backoff.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the initial request.
+	MaxAttempts int
+	// Backoff is the delay before the first retry when the response does not specify one.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between two attempts, zero means no cap.
+	MaxBackoff time.Duration
+}
+
+// Delay returns the delay before making the given attempt (starting at 1 for the first retry) of a
+// request whose previous attempt received resp and whether the request should be retried at all.
+func (p *RetryPolicy) Delay(resp *http.Response, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	var delay time.Duration
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		er := errorResponse(resp)
+		if er == nil || !er.Transient {
+			return 0, false
+		}
+		delay = time.Duration(er.RetryAfter) * time.Second
+	}
+	if d := retryAfter(resp.Header); d > 0 {
+		delay = d
+	}
+	if delay == 0 {
+		delay = p.Backoff << uint(attempt-1)
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay, true
+}
+
+// do sends the request and retries it according to the client retry policy.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, err := c.Doer.Do(ctx, req)
+	if c.Retry == nil {
+		return resp, err
+	}
+	for attempt := 1; err == nil; attempt++ {
+		delay, ok := c.Retry.Delay(resp, attempt)
+		if !ok || (req.Body != nil && req.GetBody == nil) {
+			break
+		}
+		resp.Body.Close()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = c.Doer.Do(ctx, req)
+	}
+	return resp, err
+}
+
+// errorResponse decodes the body of resp as a goa error response. It returns nil if the response
+// is not an error, if its content type is not JSON or if its body cannot be decoded. The response
+// body is buffered so that it may be read again by the caller.
+func errorResponse(resp *http.Response) *goa.ErrorResponse {
+	if resp.StatusCode < 400 || !isJSON(resp.Header.Get("Content-Type")) {
+		return nil
+	}
+	b := BufferBody(resp)
+	if b == nil {
+		return nil
+	}
+	var er goa.ErrorResponse
+	if err := json.Unmarshal(b, &er); err != nil {
+		return nil
+	}
+	return &er
+}
+
+// BufferBody reads the body of resp in memory and replaces it so that it may be read again. It
+// returns the body or nil if it cannot be read or is larger than 64KB, in which case the body is
+// left unread. Generated clients buffer the bodies of error responses so that the response errors
+// keep the raw bodies that cannot be decoded, see NewResponseError.
+func BufferBody(resp *http.Response) []byte {
+	if resp.Body == nil {
+		return nil
+	}
+	if b, ok := resp.Body.(*bufferedBody); ok {
+		b.Reset(b.raw)
+		return b.raw
+	}
+	body := resp.Body
+	b, err := ioutil.ReadAll(io.LimitReader(body, maxErrorBodySize+1))
+	if err != nil || len(b) > maxErrorBodySize {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(b), body), body}
+		return nil
+	}
+	resp.Body = &bufferedBody{Reader: bytes.NewReader(b), raw: b, closer: body}
+	return b
+}
+
+// isJSON returns true if the given Content-Type header value is a JSON media type.
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// readCloser combines a reader with the closer of the original response body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bufferedBody is a response body read in memory by BufferBody.
+type bufferedBody struct {
+	*bytes.Reader
+	raw    []byte
+	closer io.Closer
+}
+
+// Close closes the original response body.
+func (b *bufferedBody) Close() error {
+	return b.closer.Close()
+}
+
+// retryAfter parses the value of the Retry-After header, it returns zero if the header is missing
+// or invalid.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	var policy *client.RetryPolicy
+	var resp *http.Response
+
+	BeforeEach(func() {
+		policy = &client.RetryPolicy{MaxAttempts: 3, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+		resp = &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	})
+
+	It("backs off exponentially", func() {
+		delay, ok := policy.Delay(resp, 1)
+		Ω(ok).Should(BeTrue())
+		Ω(delay).Should(Equal(time.Second))
+		delay, ok = policy.Delay(resp, 2)
+		Ω(ok).Should(BeTrue())
+		Ω(delay).Should(Equal(2 * time.Second))
+		_, ok = policy.Delay(resp, 3)
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("honors the Retry-After header", func() {
+		resp.Header.Set("Retry-After", "2")
+		delay, ok := policy.Delay(resp, 1)
+		Ω(ok).Should(BeTrue())
+		Ω(delay).Should(Equal(2 * time.Second))
+	})
+
+	It("caps the delay", func() {
+		resp.Header.Set("Retry-After", "60")
+		delay, _ := policy.Delay(resp, 1)
+		Ω(delay).Should(Equal(3 * time.Second))
+	})
+
+	It("does not retry non transient responses", func() {
+		resp.StatusCode = http.StatusBadRequest
+		_, ok := policy.Delay(resp, 1)
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("retries transient error responses", func() {
+		resp.StatusCode = http.StatusBadGateway
+		resp.Header.Set("Content-Type", "application/vnd.goa.error+json")
+		resp.Body = ioutil.NopCloser(strings.NewReader(`{"code":"upstream","transient":true,"retry_after":2}`))
+		delay, ok := policy.Delay(resp, 1)
+		Ω(ok).Should(BeTrue())
+		Ω(delay).Should(Equal(2 * time.Second))
+		b, err := ioutil.ReadAll(resp.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(ContainSubstring("upstream"))
+	})
+
+	It("does not retry non transient error responses", func() {
+		resp.StatusCode = http.StatusInternalServerError
+		resp.Body = ioutil.NopCloser(strings.NewReader(`{"code":"internal"}`))
+		_, ok := policy.Delay(resp, 1)
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("does not decode error responses that are not JSON", func() {
+		resp.StatusCode = http.StatusBadGateway
+		resp.Header.Set("Content-Type", "text/plain")
+		resp.Body = ioutil.NopCloser(strings.NewReader(`{"transient":true}`))
+		_, ok := policy.Delay(resp, 1)
+		Ω(ok).Should(BeFalse())
+		b, err := ioutil.ReadAll(resp.Body)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"transient":true}`))
+	})
+})
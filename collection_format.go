@@ -0,0 +1,19 @@
+package goa
+
+import "strings"
+
+// SplitCollection splits the values of an array param whose elements are encoded in a single
+// value separated by sep, e.g. "?tags=a,b" with sep ",". Empty elements are omitted so that an
+// empty param value produces an empty array. The values may be given multiple times in which case
+// the elements are concatenated.
+func SplitCollection(values []string, sep string) []string {
+	var res []string
+	for _, v := range values {
+		for _, e := range strings.Split(v, sep) {
+			if e != "" {
+				res = append(res, e)
+			}
+		}
+	}
+	return res
+}
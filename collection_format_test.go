@@ -0,0 +1,19 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SplitCollection", func() {
+	It("splits the delimited values", func() {
+		Ω(goa.SplitCollection([]string{"a,b", "c"}, ",")).Should(Equal([]string{"a", "b", "c"}))
+		Ω(goa.SplitCollection([]string{"a|b"}, "|")).Should(Equal([]string{"a", "b"}))
+	})
+
+	It("omits the empty elements", func() {
+		Ω(goa.SplitCollection([]string{""}, ",")).Should(BeEmpty())
+		Ω(goa.SplitCollection([]string{"a,,b,"}, ",")).Should(Equal([]string{"a", "b"}))
+	})
+})
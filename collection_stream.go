@@ -0,0 +1,144 @@
+package goa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type (
+	// CollectionDecoder decodes the elements of a JSON array read from a stream one at a time so
+	// that the array does not need to be held in memory, see the apidsl package Streaming
+	// function. The request body of actions with a streaming payload is typically read with:
+	//
+	//	dec := goa.NewCollectionDecoder(ctx.Payload)
+	//	for {
+	//		var record app.Record
+	//		if err := dec.Decode(&record); err == io.EOF {
+	//			break
+	//		} else if err != nil {
+	//			return err
+	//		}
+	//		// process record
+	//	}
+	CollectionDecoder struct {
+		dec     *json.Decoder
+		started bool
+		done    bool
+	}
+
+	// CollectionEncoder writes a JSON array one element at a time to a stream, see the apidsl
+	// package Streaming function. Close must be called once all the elements have been written.
+	CollectionEncoder struct {
+		w      io.Writer
+		count  int
+		closed bool
+	}
+
+	// chunkedWriter writes the body of a streaming response and flushes it after each write.
+	chunkedWriter struct {
+		ctx context.Context
+		rw  http.ResponseWriter
+	}
+)
+
+// NewCollectionDecoder returns a decoder that reads the elements of the JSON array read from r.
+func NewCollectionDecoder(r io.Reader) *CollectionDecoder {
+	return &CollectionDecoder{dec: json.NewDecoder(r)}
+}
+
+// Decode decodes the next element of the array into v. It returns io.EOF once all the elements
+// have been decoded.
+func (d *CollectionDecoder) Decode(v interface{}) error {
+	if d.done {
+		return io.EOF
+	}
+	if !d.started {
+		t, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := t.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("invalid collection, expected a JSON array but got %v", t)
+		}
+		d.started = true
+	}
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return err
+		}
+		d.done = true
+		return io.EOF
+	}
+	return d.dec.Decode(v)
+}
+
+// NewCollectionEncoder returns an encoder that writes a JSON array to w.
+func NewCollectionEncoder(w io.Writer) *CollectionEncoder {
+	return &CollectionEncoder{w: w}
+}
+
+// Encode writes the JSON encoding of v as the next element of the array.
+func (e *CollectionEncoder) Encode(v interface{}) error {
+	if e.closed {
+		return fmt.Errorf("collection encoder is closed")
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sep := ","
+	if e.count == 0 {
+		sep = "["
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(b); err != nil {
+		return err
+	}
+	e.count++
+	return nil
+}
+
+// Close ends the array. It writes an empty array if no element was encoded.
+func (e *CollectionEncoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	end := "]"
+	if e.count == 0 {
+		end = "[]"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// NewChunkedResponse writes the headers of a response with the given status code and content type
+// using the response writer of ctx and returns the writer used to write the response body. The
+// body is sent in chunks: each write is flushed so that clients receive the data right away.
+// Writes fail once the request is done.
+func NewChunkedResponse(ctx context.Context, status int, contentType string) io.Writer {
+	rw := ContextResponse(ctx)
+	if contentType != "" {
+		rw.Header().Set("Content-Type", contentType)
+	}
+	rw.WriteHeader(status)
+	return &chunkedWriter{ctx: ctx, rw: rw}
+}
+
+// Write writes b to the response and flushes it.
+func (w *chunkedWriter) Write(b []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := w.rw.Write(b)
+	if err != nil {
+		return n, err
+	}
+	flushResponse(w.rw)
+	return n, nil
+}
@@ -0,0 +1,120 @@
+package goa_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CollectionDecoder", func() {
+	type record struct {
+		Name string `json:"name"`
+	}
+
+	decodeAll := func(body string) ([]string, error) {
+		dec := goa.NewCollectionDecoder(strings.NewReader(body))
+		var names []string
+		for {
+			var r record
+			if err := dec.Decode(&r); err == io.EOF {
+				return names, nil
+			} else if err != nil {
+				return names, err
+			}
+			names = append(names, r.Name)
+		}
+	}
+
+	It("decodes the elements one at a time", func() {
+		names, err := decodeAll(`[{"name":"a"}, {"name":"b"}]`)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(names).Should(Equal([]string{"a", "b"}))
+	})
+
+	It("decodes empty arrays", func() {
+		names, err := decodeAll(`[]`)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(names).Should(BeEmpty())
+	})
+
+	It("rejects values that are not arrays", func() {
+		_, err := decodeAll(`{"name":"a"}`)
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("reports truncated arrays", func() {
+		names, err := decodeAll(`[{"name":"a"}, {"na`)
+		Ω(err).Should(HaveOccurred())
+		Ω(names).Should(Equal([]string{"a"}))
+	})
+})
+
+var _ = Describe("CollectionEncoder", func() {
+	var (
+		buf strings.Builder
+		enc *goa.CollectionEncoder
+	)
+
+	BeforeEach(func() {
+		buf.Reset()
+		enc = goa.NewCollectionEncoder(&buf)
+	})
+
+	It("writes a JSON array", func() {
+		Ω(enc.Encode(map[string]int{"n": 1})).Should(Succeed())
+		Ω(enc.Encode(2)).Should(Succeed())
+		Ω(enc.Close()).Should(Succeed())
+		Ω(buf.String()).Should(Equal(`[{"n":1},2]`))
+	})
+
+	It("writes empty arrays", func() {
+		Ω(enc.Close()).Should(Succeed())
+		Ω(buf.String()).Should(Equal(`[]`))
+	})
+
+	It("rejects elements once closed", func() {
+		Ω(enc.Close()).Should(Succeed())
+		Ω(enc.Encode(1)).ShouldNot(Succeed())
+		Ω(enc.Close()).Should(Succeed())
+		Ω(buf.String()).Should(Equal(`[]`))
+	})
+})
+
+var _ = Describe("NewChunkedResponse", func() {
+	var (
+		rw  *httptest.ResponseRecorder
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		rw = httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/records", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		ctx = goa.NewContext(nil, rw, req, nil)
+	})
+
+	It("writes and flushes the response body", func() {
+		w := goa.NewChunkedResponse(ctx, 200, "application/json")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+		_, err := io.WriteString(w, "[1")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Flushed).Should(BeTrue())
+		Ω(rw.Body.String()).Should(Equal("[1"))
+	})
+
+	It("stops writing once the request is done", func() {
+		cctx, cancel := context.WithCancel(ctx)
+		w := goa.NewChunkedResponse(cctx, 200, "application/json")
+		cancel()
+		_, err := io.WriteString(w, "[1")
+		Ω(err).Should(MatchError(context.Canceled))
+		Ω(rw.Body.String()).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,54 @@
+package goa
+
+import "time"
+
+// Compile performs the preparation work that is otherwise deferred to the first requests handled
+// by the service:
+//
+//   - it builds the service and controller middleware chains,
+//   - it indexes the routes that do not define wildcards so that the default mux serves them
+//     without searching the route trie,
+//   - it indexes the decoders and encoders by Content-Type and Accept header values so that
+//     requests do not need to parse the former or negotiate the latter,
+//   - it runs the contextual escaping of the HTML templates used to render media type views, see
+//     HTMLTemplate. The templates are executed once with no data to that end,
+//   - it snapshots the custom formats and validation patterns so that validating requests does
+//     not require locking.
+//
+// Moving this work out of the request path keeps the latency of the first requests in line with
+// the steady state, which matters for deployments that start a new process per burst of traffic.
+//
+// Compile must be called once all the middleware has been mounted. ListenAndServe,
+// ListenAndServeTLS and Serve call it before accepting connections, services that are served
+// through other means (e.g. by a serverless adapter) should call it explicitly after mounting the
+// controllers. Calling Compile more than once is harmless: it prepares the handlers mounted since
+// the previous call and rebuilds the indexes.
+//
+// Compile returns the time spent compiling and records it in the "goa.compile" sample.
+func (service *Service) Compile() time.Duration {
+	start := time.Now()
+	service.compileLock.Lock()
+	compilers := service.compilers
+	service.compilers = nil
+	service.compileLock.Unlock()
+	for _, c := range compilers {
+		c()
+	}
+	if rc, ok := service.Mux.(routeCompiler); ok {
+		rc.compileRoutes()
+	}
+	service.Decoder.compile()
+	service.Encoder.compile()
+	service.compileHTMLTemplates()
+	compileValidations()
+	MeasureSince([]string{"goa", "compile"}, start)
+	return time.Since(start)
+}
+
+// onCompile registers a preparation step run by Compile. The step must be idempotent as it may
+// also run lazily when a request is handled before the service is compiled.
+func (service *Service) onCompile(c func()) {
+	service.compileLock.Lock()
+	defer service.compileLock.Unlock()
+	service.compilers = append(service.compilers, c)
+}
@@ -0,0 +1,260 @@
+package goa_test
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compile", func() {
+	var s *goa.Service
+	var ctrl *goa.Controller
+	var built int
+
+	BeforeEach(func() {
+		built = 0
+		s = goa.New("compile")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		ctrl = s.NewController("test")
+		ctrl.Use(func(h goa.Handler) goa.Handler {
+			built++
+			return h
+		})
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.WriteHeader(204)
+			return nil
+		}
+		s.Mux.Handle("GET", "/a", ctrl.MuxHandler("a", handler, nil))
+		s.Mux.Handle("GET", "/b", ctrl.MuxHandler("b", handler, nil))
+	})
+
+	It("builds the middleware chains before any request is handled", func() {
+		s.Compile()
+		Ω(built).Should(Equal(2))
+	})
+
+	It("does not build the middleware chains again when requests are handled", func() {
+		s.Compile()
+		req, _ := http.NewRequest("GET", "/a", nil)
+		rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+		s.Mux.ServeHTTP(rw, req)
+		Ω(rw.Status).Should(Equal(204))
+		Ω(built).Should(Equal(2))
+	})
+
+	It("only compiles the handlers mounted since the previous call", func() {
+		s.Compile()
+		s.Compile()
+		Ω(built).Should(Equal(2))
+	})
+
+	Context("without a call to Compile", func() {
+		It("builds the middleware chains lazily", func() {
+			req, _ := http.NewRequest("GET", "/a", nil)
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			s.Mux.ServeHTTP(rw, req)
+			Ω(rw.Status).Should(Equal(204))
+			Ω(built).Should(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Compile indexes", func() {
+	var s *goa.Service
+	var ctrl *goa.Controller
+	var served string
+
+	BeforeEach(func() {
+		served = ""
+		s = goa.New("compile")
+		s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+		s.Decoder.Register(goa.NewJSONDecoder, "application/json")
+		ctrl = s.NewController("test")
+		handler := func(name string) goa.Handler {
+			return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				served = name
+				rw.WriteHeader(204)
+				return nil
+			}
+		}
+		s.Mux.Handle("GET", "/static", ctrl.MuxHandler("static", handler("static"), nil))
+		s.Mux.Handle("GET", "/static/:id", ctrl.MuxHandler("wildcard", handler("wildcard"), nil))
+		s.Compile()
+		s.Mux.Handle("GET", "/late", ctrl.MuxHandler("late", handler("late"), nil))
+	})
+
+	serve := func(method, path string) int {
+		req, _ := http.NewRequest(method, path, nil)
+		rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+		s.Mux.ServeHTTP(rw, req)
+		return rw.Status
+	}
+
+	It("serves the static and wildcard routes", func() {
+		Ω(serve("GET", "/static")).Should(Equal(204))
+		Ω(served).Should(Equal("static"))
+		Ω(serve("GET", "/static/1")).Should(Equal(204))
+		Ω(served).Should(Equal("wildcard"))
+		Ω(serve("POST", "/static")).Should(Equal(405))
+	})
+
+	It("serves the routes mounted after the service was compiled", func() {
+		Ω(serve("GET", "/late")).Should(Equal(204))
+		Ω(served).Should(Equal("late"))
+	})
+
+	It("decodes bodies with a charset parameter", func() {
+		var v map[string]interface{}
+		err := s.Decoder.Decode(&v, strings.NewReader(`{"a":1}`), "application/json; charset=utf-8")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(HaveKeyWithValue("a", 1.0))
+	})
+
+	It("encodes responses with the negotiated encoder", func() {
+		var b strings.Builder
+		Ω(s.Encoder.Encode(map[string]int{"a": 1}, &b, "application/json")).ShouldNot(HaveOccurred())
+		Ω(b.String()).Should(Equal("{\"a\":1}\n"))
+		Ω(s.Encoder.Encode(map[string]int{"a": 1}, &b, "application/xml")).Should(HaveOccurred())
+	})
+
+	It("uses the encoders registered after the service was compiled", func() {
+		s.Encoder.Register(func(w io.Writer) goa.Encoder { return upperEncoder{w} }, "text/plain")
+		var b strings.Builder
+		Ω(s.Encoder.Encode("a", &b, "text/plain")).ShouldNot(HaveOccurred())
+		Ω(b.String()).Should(Equal("A"))
+	})
+
+	It("validates the formats registered after the service was compiled", func() {
+		goa.RegisterFormat("compile-test", func(v string) error {
+			if v != "ok" {
+				return fmt.Errorf("not ok")
+			}
+			return nil
+		})
+		Ω(goa.ValidateFormat("compile-test", "ok")).ShouldNot(HaveOccurred())
+		Ω(goa.ValidateFormat("compile-test", "ko")).Should(HaveOccurred())
+	})
+
+	It("renders the HTML templates registered before the service was compiled", func() {
+		s.HTMLTemplate("application/vnd.bottle", "default",
+			template.Must(template.New("bottle").Parse(`<h1>{{ .Name }}</h1>`)))
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return s.SendHTML(ctx, 200, "application/vnd.bottle", "default", &transformedBottle{Name: "<merlot>"})
+		}
+		s.Mux.Handle("GET", "/bottle", ctrl.MuxHandler("html", h, nil))
+		s.Compile()
+		req, _ := http.NewRequest("GET", "/bottle", nil)
+		req.Header.Set("Accept", "text/html")
+		rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+		s.Mux.ServeHTTP(rw, req)
+		Ω(rw.Status).Should(Equal(200))
+		Ω(string(rw.Body)).Should(Equal("<h1>&lt;merlot&gt;</h1>"))
+	})
+})
+
+// upperEncoder writes strings in upper case.
+type upperEncoder struct{ w io.Writer }
+
+func (e upperEncoder) Encode(v interface{}) error {
+	_, err := io.WriteString(e.w, strings.ToUpper(v.(string)))
+	return err
+}
+
+var _ = Describe("CompilePatterns", func() {
+	It("compiles patterns used by ValidatePattern", func() {
+		goa.CompilePatterns(`^[a-z]+$`, `^\d+$`)
+		Ω(goa.ValidatePattern(`^[a-z]+$`, "abc")).Should(BeTrue())
+		Ω(goa.ValidatePattern(`^\d+$`, "abc")).Should(BeFalse())
+	})
+})
+
+// newBenchService returns a service with n controllers each mounting a static and a wildcard
+// route behind a middleware.
+func newBenchService(n int) *goa.Service {
+	s := goa.New("bench")
+	s.WithLogger(goa.NewLogger(log.New(ioutil.Discard, "", 0)))
+	s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+	s.Decoder.Register(goa.NewJSONDecoder, "application/json")
+	s.Use(func(h goa.Handler) goa.Handler { return h })
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return s.Send(ctx, 200, map[string]string{"name": "bottle"})
+	}
+	for i := 0; i < n; i++ {
+		ctrl := s.NewController(fmt.Sprintf("ctrl%d", i))
+		ctrl.Use(func(h goa.Handler) goa.Handler { return h })
+		s.Mux.Handle("GET", fmt.Sprintf("/r%d", i), ctrl.MuxHandler("list", handler, nil))
+		s.Mux.Handle("GET", fmt.Sprintf("/r%d/:id", i), ctrl.MuxHandler("show", handler, nil))
+	}
+	return s
+}
+
+// benchRequest serves one request for the static route of each controller.
+func benchRequest(b *testing.B, s *goa.Service, n int) {
+	for i := 0; i < n; i++ {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/r%d", i), nil)
+		req.Header.Set("Accept", "application/json")
+		rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+		s.Mux.ServeHTTP(rw, req)
+		if rw.Status != 200 {
+			b.Fatalf("unexpected status %d", rw.Status)
+		}
+	}
+}
+
+// BenchmarkColdStart measures the first request made to each of the 50 controllers of a service
+// that was not compiled and of a compiled service, the time spent compiling is excluded.
+func BenchmarkColdStart(b *testing.B) {
+	const n = 50
+	for _, compile := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compiled=%v", compile), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s := newBenchService(n)
+				if compile {
+					s.Compile()
+				}
+				b.StartTimer()
+				benchRequest(b, s, n)
+			}
+		})
+	}
+}
+
+// BenchmarkSteadyState measures requests made to each of the 50 controllers of a service that
+// has already served them, with and without compiling the service first.
+func BenchmarkSteadyState(b *testing.B) {
+	const n = 50
+	for _, compile := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compiled=%v", compile), func(b *testing.B) {
+			s := newBenchService(n)
+			if compile {
+				s.Compile()
+			}
+			benchRequest(b, s, n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				benchRequest(b, s, n)
+			}
+		})
+	}
+}
+
+// BenchmarkCompile measures compiling a service with 50 controllers.
+func BenchmarkCompile(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := newBenchService(50)
+		b.StartTimer()
+		s.Compile()
+	}
+}
@@ -0,0 +1,146 @@
+package goa
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimiter caps the number of requests handled concurrently by an action. Requests
+// received while the limit is reached wait in a bounded queue if one is configured and are
+// rejected otherwise.
+//
+// The limiter reports its saturation with the "goa.concurrency.<resource>.<action>.in_flight" and
+// "goa.concurrency.<resource>.<action>.queued" gauges, the time requests spend in the queue with
+// the "goa.concurrency.<resource>.<action>.wait" sample and the number of rejected requests with
+// the "goa.concurrency.<resource>.<action>.rejected" counter.
+type ConcurrencyLimiter struct {
+	key          []string
+	limit        int
+	queueSize    int
+	queueTimeout time.Duration
+	slots        chan struct{}
+
+	mu       sync.Mutex
+	inFlight int
+	queued   int
+}
+
+// NewConcurrencyLimiter returns a limiter that lets at most limit requests to the given action
+// run concurrently. Up to queueSize additional requests wait for a slot to free up for at most
+// queueTimeout, a zero queueTimeout means requests wait until they are canceled.
+func NewConcurrencyLimiter(resource, action string, limit, queueSize int, queueTimeout time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		key:          []string{"goa", "concurrency", resource, action},
+		limit:        limit,
+		queueSize:    queueSize,
+		queueTimeout: queueTimeout,
+		slots:        make(chan struct{}, limit),
+	}
+}
+
+// Handle returns a handler that runs h once a slot is available. The handler returns an error
+// of class ErrRateLimited if the limit is reached and the limiter has no queue, an error of class
+// ErrUnavailable if the queue is full or the request times out waiting in the queue.
+func (l *ConcurrencyLimiter) Handle(h Handler) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if err := l.acquire(ctx); err != nil {
+			return err
+		}
+		defer l.release()
+		return h(ctx, rw, req)
+	}
+}
+
+// InFlight returns the number of requests currently handled.
+func (l *ConcurrencyLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// Queued returns the number of requests currently waiting for a slot.
+func (l *ConcurrencyLimiter) Queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queued
+}
+
+// acquire reserves a slot for a request, waiting in the queue if needed.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		l.update(1, 0)
+		return nil
+	default:
+	}
+	if l.queueSize == 0 {
+		IncrCounter(l.metric("rejected"), 1.0)
+		return RateLimitError("too many concurrent requests", 0, "limit", l.limit)
+	}
+	l.mu.Lock()
+	if l.queued >= l.queueSize {
+		depth := l.queued
+		l.mu.Unlock()
+		IncrCounter(l.metric("rejected"), 1.0)
+		return UnavailableError("request queue is full", 0,
+			"limit", l.limit, "queue_depth", depth, "queue_size", l.queueSize)
+	}
+	l.queued++
+	depth := l.queued
+	l.mu.Unlock()
+	l.report()
+	defer l.update(0, -1)
+	defer MeasureSince(l.metric("wait"), time.Now())
+
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		t := time.NewTimer(l.queueTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.update(1, 0)
+		return nil
+	case <-timeout:
+		IncrCounter(l.metric("rejected"), 1.0)
+		return UnavailableError("timed out waiting for a request slot", l.queueTimeout,
+			"limit", l.limit, "queue_depth", depth, "queue_size", l.queueSize)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot reserved by a request.
+func (l *ConcurrencyLimiter) release() {
+	<-l.slots
+	l.update(-1, 0)
+}
+
+// update adjusts the number of in-flight and queued requests and reports the new values.
+func (l *ConcurrencyLimiter) update(inFlight, queued int) {
+	l.mu.Lock()
+	l.inFlight += inFlight
+	l.queued += queued
+	l.mu.Unlock()
+	l.report()
+}
+
+// report sets the saturation gauges of the limiter.
+func (l *ConcurrencyLimiter) report() {
+	l.mu.Lock()
+	f, q := l.inFlight, l.queued
+	l.mu.Unlock()
+	SetGauge(l.metric("in_flight"), float32(f))
+	SetGauge(l.metric("queued"), float32(q))
+}
+
+// metric returns the key of the limiter metric with the given name.
+func (l *ConcurrencyLimiter) metric(name string) []string {
+	key := make([]string, len(l.key)+1)
+	copy(key, l.key)
+	key[len(l.key)] = name
+	return key
+}
@@ -0,0 +1,105 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConcurrencyLimiter", func() {
+	var queueSize int
+	var queueTimeout time.Duration
+	var limiter *goa.ConcurrencyLimiter
+	var release chan struct{}
+	var handler goa.Handler
+
+	BeforeEach(func() {
+		queueSize = 0
+		queueTimeout = 0
+		release = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		limiter = goa.NewConcurrencyLimiter("res", "act", 1, queueSize, queueTimeout)
+		handler = limiter.Handle(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			<-release
+			return nil
+		})
+	})
+
+	// serve runs the handler in the background and returns the channel that receives its result.
+	serve := func() chan error {
+		done := make(chan error, 1)
+		go func() {
+			req, _ := http.NewRequest("GET", "/", nil)
+			done <- handler(context.Background(), httptest.NewRecorder(), req)
+		}()
+		return done
+	}
+
+	Context("with no queue", func() {
+		It("rejects requests received while the limit is reached", func() {
+			first := serve()
+			Eventually(limiter.InFlight).Should(Equal(1))
+			err := <-serve()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(429))
+			close(release)
+			Ω(<-first).ShouldNot(HaveOccurred())
+			Ω(limiter.InFlight()).Should(Equal(0))
+		})
+	})
+
+	Context("with a queue", func() {
+		BeforeEach(func() {
+			queueSize = 1
+		})
+
+		It("runs queued requests once a slot frees up", func() {
+			first := serve()
+			Eventually(limiter.InFlight).Should(Equal(1))
+			second := serve()
+			Eventually(limiter.Queued).Should(Equal(1))
+			close(release)
+			Ω(<-first).ShouldNot(HaveOccurred())
+			Ω(<-second).ShouldNot(HaveOccurred())
+			Ω(limiter.Queued()).Should(Equal(0))
+		})
+
+		It("rejects requests received while the queue is full", func() {
+			first := serve()
+			Eventually(limiter.InFlight).Should(Equal(1))
+			second := serve()
+			Eventually(limiter.Queued).Should(Equal(1))
+			err := <-serve()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(503))
+			Ω(err.(*goa.ErrorResponse).Meta).Should(HaveKeyWithValue("queue_depth", 1))
+			close(release)
+			Ω(<-first).ShouldNot(HaveOccurred())
+			Ω(<-second).ShouldNot(HaveOccurred())
+		})
+
+		Context("with a queue timeout", func() {
+			BeforeEach(func() {
+				queueTimeout = 10 * time.Millisecond
+			})
+
+			It("rejects requests that wait too long", func() {
+				first := serve()
+				Eventually(limiter.InFlight).Should(Equal(1))
+				err := <-serve()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(503))
+				Ω(limiter.Queued()).Should(Equal(0))
+				close(release)
+				Ω(<-first).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,314 @@
+package goa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// errNoSender is the error returned when sending to a connection that has no sender.
+var errNoSender = errors.New("connection has no sender, use Bind")
+
+// ConnectionSender sends events to the client of a long-lived connection. *EventStream
+// implements it, websocket handlers may wrap the connection with a ConnectionSenderFunc.
+type ConnectionSender interface {
+	// Send sends an event of the given type with the given data.
+	Send(event string, data interface{}) error
+}
+
+// ConnectionSenderFunc is an adapter that makes it possible to use a function as a
+// ConnectionSender.
+type ConnectionSenderFunc func(event string, data interface{}) error
+
+// Send calls f(event, data).
+func (f ConnectionSenderFunc) Send(event string, data interface{}) error {
+	return f(event, data)
+}
+
+// Connection is a long-lived server-sent event stream or websocket connection tracked by a
+// ConnectionRegistry. The methods of Connection may be called concurrently.
+type Connection struct {
+	// ID uniquely identifies the connection in the registry.
+	ID string
+	// Identity identifies the resource the connection is scoped to, e.g. "bottles/42".
+	// Broadcasts target all the connections with a given identity.
+	Identity string
+	// ConnectedAt is the time the connection was registered.
+	ConnectedAt time.Time
+
+	registry *ConnectionRegistry
+	seq      uint64
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	lock     sync.Mutex
+	sender   ConnectionSender
+	metadata map[string]string
+}
+
+// ConnectionRegistry tracks the long-lived connections of streaming actions. It enforces
+// connection limits, broadcasts events to the connections scoped to a resource and drains the
+// connections when the service shuts down, see DrainOnShutdown. Action handlers register the
+// connection once the request is accepted and close it when they return:
+//
+//	func (c *BottleController) Watch(ctx *app.WatchBottleContext) error {
+//		conn, err := c.registry.Register(ctx, fmt.Sprintf("bottles/%d", ctx.BottleID))
+//		if err != nil {
+//			return err
+//		}
+//		defer conn.Close()
+//		conn.Bind(goa.NewEventStream(ctx))
+//		<-conn.Context().Done()
+//		return nil
+//	}
+//
+// Other handlers then notify the clients watching a bottle with:
+//
+//	c.registry.Broadcast(fmt.Sprintf("bottles/%d", id), "updated", bottle)
+//
+type ConnectionRegistry struct {
+	// MaxConnections is the maximum number of connections tracked by the registry, zero
+	// means no limit.
+	MaxConnections int
+	// MaxConnectionsPerIdentity is the maximum number of connections scoped to the same
+	// resource, zero means no limit.
+	MaxConnectionsPerIdentity int
+
+	lock       sync.Mutex
+	seq        uint64
+	conns      map[string]*Connection
+	identities map[string]int
+	draining   bool
+	idle       chan struct{}
+}
+
+// NewConnectionRegistry returns a registry with no connection limit.
+func NewConnectionRegistry() *ConnectionRegistry {
+	return &ConnectionRegistry{
+		conns:      make(map[string]*Connection),
+		identities: make(map[string]int),
+	}
+}
+
+// Register tracks a new connection scoped to the resource with the given identity. The
+// connection context is canceled when ctx is done, when the connection is closed or when the
+// registry drains. Register returns an error of class ErrUnavailable if the registry is draining
+// or holds MaxConnections connections and an error of class ErrRateLimited if the resource
+// already has MaxConnectionsPerIdentity connections.
+func (r *ConnectionRegistry) Register(ctx context.Context, identity string) (*Connection, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.draining {
+		return nil, UnavailableError("service is shutting down", 0)
+	}
+	if r.MaxConnections > 0 && len(r.conns) >= r.MaxConnections {
+		return nil, UnavailableError("too many connections", 0, "limit", r.MaxConnections)
+	}
+	if r.MaxConnectionsPerIdentity > 0 && r.identities[identity] >= r.MaxConnectionsPerIdentity {
+		return nil, RateLimitError("too many connections", 0,
+			"identity", identity, "limit", r.MaxConnectionsPerIdentity)
+	}
+	r.seq++
+	cctx, cancel := context.WithCancel(ctx)
+	c := &Connection{
+		ID:          strconv.FormatUint(r.seq, 10),
+		Identity:    identity,
+		ConnectedAt: time.Now(),
+		registry:    r,
+		seq:         r.seq,
+		ctx:         cctx,
+		cancel:      cancel,
+		metadata:    make(map[string]string),
+	}
+	r.conns[c.ID] = c
+	r.identities[identity]++
+	SetGauge([]string{"goa", "connections"}, float32(len(r.conns)))
+	go func() {
+		<-cctx.Done()
+		r.remove(c)
+	}()
+	return c, nil
+}
+
+// Count returns the number of connections tracked by the registry.
+func (r *ConnectionRegistry) Count() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.conns)
+}
+
+// Connections returns the connections scoped to the resource with the given identity in
+// registration order.
+func (r *ConnectionRegistry) Connections(identity string) []*Connection {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var conns []*Connection
+	for _, c := range r.conns {
+		if c.Identity == identity {
+			conns = append(conns, c)
+		}
+	}
+	sortConnections(conns)
+	return conns
+}
+
+// Broadcast sends the event to all the connections scoped to the resource with the given
+// identity. Connections whose send fails are closed, connections with no sender are skipped.
+// Broadcast returns the number of connections that received the event.
+func (r *ConnectionRegistry) Broadcast(identity, event string, data interface{}) int {
+	return broadcast(r.Connections(identity), event, data)
+}
+
+// BroadcastAll sends the event to all the connections tracked by the registry, see Broadcast.
+func (r *ConnectionRegistry) BroadcastAll(event string, data interface{}) int {
+	r.lock.Lock()
+	conns := make([]*Connection, 0, len(r.conns))
+	for _, c := range r.conns {
+		conns = append(conns, c)
+	}
+	r.lock.Unlock()
+	sortConnections(conns)
+	return broadcast(conns, event, data)
+}
+
+// Drain rejects new connections, cancels the context of the existing connections and waits
+// until they are all closed or ctx is done in which case it returns the context error.
+func (r *ConnectionRegistry) Drain(ctx context.Context) error {
+	idle := r.startDrain()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DrainOnShutdown drains the registry when the server shuts down. http.Server.Shutdown does not
+// cancel the requests in flight so that without draining it waits for the event streams to end.
+func (r *ConnectionRegistry) DrainOnShutdown(server *http.Server) {
+	server.RegisterOnShutdown(func() { r.startDrain() })
+}
+
+// startDrain flags the registry as draining, cancels the existing connections and returns a
+// channel closed once all connections are closed.
+func (r *ConnectionRegistry) startDrain() chan struct{} {
+	r.lock.Lock()
+	if !r.draining {
+		r.draining = true
+		r.idle = make(chan struct{})
+		if len(r.conns) == 0 {
+			close(r.idle)
+		}
+	}
+	idle := r.idle
+	conns := make([]*Connection, 0, len(r.conns))
+	for _, c := range r.conns {
+		conns = append(conns, c)
+	}
+	r.lock.Unlock()
+	for _, c := range conns {
+		c.cancel()
+	}
+	return idle
+}
+
+// remove stops tracking the given connection.
+func (r *ConnectionRegistry) remove(c *Connection) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.conns[c.ID]; !ok {
+		return
+	}
+	delete(r.conns, c.ID)
+	if r.identities[c.Identity]--; r.identities[c.Identity] == 0 {
+		delete(r.identities, c.Identity)
+	}
+	SetGauge([]string{"goa", "connections"}, float32(len(r.conns)))
+	if r.draining && len(r.conns) == 0 {
+		close(r.idle)
+	}
+}
+
+// Context returns the connection context, it is canceled when the request context is done, when
+// the connection is closed or when the registry drains.
+func (c *Connection) Context() context.Context {
+	return c.ctx
+}
+
+// Bind sets the sender used to deliver the events broadcast to the connection.
+func (c *Connection) Bind(sender ConnectionSender) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.sender = sender
+}
+
+// Send sends an event to the connection client.
+func (c *Connection) Send(event string, data interface{}) error {
+	c.lock.Lock()
+	sender := c.sender
+	c.lock.Unlock()
+	if sender == nil {
+		return errNoSender
+	}
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return sender.Send(event, data)
+}
+
+// Set sets the value of the connection metadata with the given key.
+func (c *Connection) Set(key, value string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.metadata[key] = value
+}
+
+// Get returns the value of the connection metadata with the given key.
+func (c *Connection) Get(key string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.metadata[key]
+}
+
+// Metadata returns a copy of the connection metadata.
+func (c *Connection) Metadata() map[string]string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	m := make(map[string]string, len(c.metadata))
+	for k, v := range c.metadata {
+		m[k] = v
+	}
+	return m
+}
+
+// Close cancels the connection context and stops tracking the connection.
+func (c *Connection) Close() {
+	c.cancel()
+	c.registry.remove(c)
+}
+
+// broadcast sends the event to the given connections and closes the connections whose send
+// fails. Connections with no sender are skipped. It returns the number of connections that
+// received the event.
+func broadcast(conns []*Connection, event string, data interface{}) int {
+	n := 0
+	for _, c := range conns {
+		if err := c.Send(event, data); err != nil {
+			if err != errNoSender {
+				c.Close()
+			}
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// sortConnections sorts the given connections in registration order.
+func sortConnections(conns []*Connection) {
+	sort.Slice(conns, func(i, j int) bool { return conns[i].seq < conns[j].seq })
+}
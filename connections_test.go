@@ -0,0 +1,125 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConnectionRegistry", func() {
+	var registry *goa.ConnectionRegistry
+
+	// recorder returns a sender that records the events it sends.
+	recorder := func(events *[]string) goa.ConnectionSender {
+		return goa.ConnectionSenderFunc(func(event string, data interface{}) error {
+			*events = append(*events, event)
+			return nil
+		})
+	}
+
+	BeforeEach(func() {
+		registry = goa.NewConnectionRegistry()
+	})
+
+	It("tracks connections until they are closed", func() {
+		conn, err := registry.Register(context.Background(), "bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+		conn.Set("user", "joe")
+		Ω(conn.Get("user")).Should(Equal("joe"))
+		Ω(conn.Metadata()).Should(Equal(map[string]string{"user": "joe"}))
+		Ω(registry.Count()).Should(Equal(1))
+		Ω(registry.Connections("bottles/1")).Should(Equal([]*goa.Connection{conn}))
+		conn.Close()
+		Ω(conn.Context().Err()).Should(HaveOccurred())
+		Ω(registry.Count()).Should(BeZero())
+	})
+
+	It("stops tracking connections whose request is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := registry.Register(ctx, "bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+		cancel()
+		Eventually(registry.Count).Should(BeZero())
+	})
+
+	It("broadcasts events to the connections scoped to a resource", func() {
+		var first, second, other []string
+		for identity, events := range map[string]*[]string{"bottles/1": &first, "bottles/2": &other} {
+			conn, err := registry.Register(context.Background(), identity)
+			Ω(err).ShouldNot(HaveOccurred())
+			conn.Bind(recorder(events))
+		}
+		conn, err := registry.Register(context.Background(), "bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+		conn.Bind(recorder(&second))
+		_, err = registry.Register(context.Background(), "bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(registry.Broadcast("bottles/1", "updated", nil)).Should(Equal(2))
+		Ω(first).Should(Equal([]string{"updated"}))
+		Ω(second).Should(Equal([]string{"updated"}))
+		Ω(other).Should(BeEmpty())
+		Ω(registry.BroadcastAll("deleted", nil)).Should(Equal(3))
+		Ω(other).Should(Equal([]string{"deleted"}))
+	})
+
+	It("closes the connections whose send fails", func() {
+		conn, err := registry.Register(context.Background(), "bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+		conn.Bind(goa.ConnectionSenderFunc(func(string, interface{}) error { return errors.New("broken pipe") }))
+		Ω(registry.Broadcast("bottles/1", "updated", nil)).Should(BeZero())
+		Ω(registry.Count()).Should(BeZero())
+	})
+
+	Context("with connection limits", func() {
+		BeforeEach(func() {
+			registry.MaxConnections = 2
+			registry.MaxConnectionsPerIdentity = 1
+		})
+
+		It("rejects the connections that exceed the limits", func() {
+			_, err := registry.Register(context.Background(), "bottles/1")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = registry.Register(context.Background(), "bottles/1")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusTooManyRequests))
+			_, err = registry.Register(context.Background(), "bottles/2")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = registry.Register(context.Background(), "bottles/3")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	Context("draining", func() {
+		It("cancels the connections and waits until they are closed", func() {
+			conn, err := registry.Register(context.Background(), "bottles/1")
+			Ω(err).ShouldNot(HaveOccurred())
+			go func() {
+				<-conn.Context().Done()
+				conn.Close()
+			}()
+			Ω(registry.Drain(context.Background())).Should(Succeed())
+			Ω(registry.Count()).Should(BeZero())
+			_, err = registry.Register(context.Background(), "bottles/1")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("drains when the server shuts down", func() {
+			conn, err := registry.Register(context.Background(), "bottles/1")
+			Ω(err).ShouldNot(HaveOccurred())
+			server := &http.Server{}
+			registry.DrainOnShutdown(server)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			Ω(server.Shutdown(ctx)).Should(Succeed())
+			Eventually(conn.Context().Done()).Should(BeClosed())
+		})
+	})
+})
@@ -19,6 +19,8 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	grantedScopesKey
+	queryCostKey
 )
 
 type (
@@ -31,6 +33,9 @@ type (
 		// Params contains the raw values for the parameters defined in the design including
 		// path parameters, query string parameters and header parameters.
 		Params url.Values
+
+		formFiles        []*FormFile  // Files decoded from multipart forms, see DecodeMultipartForm
+		payloadValidator func() error // Deferred payload validation, see DeferPayloadValidation
 	}
 
 	// ResponseData provides access to the underlying HTTP response.
@@ -41,6 +46,9 @@ type (
 		Service *Service
 		// ErrorCode is the code of the error returned by the action if any.
 		ErrorCode string
+		// ErrorDesign is the design location of the error returned by the action if any, see
+		// WithDesignLocation.
+		ErrorDesign string
 		// Status is the response HTTP status code.
 		Status int
 		// Length is the response body length.
@@ -0,0 +1,57 @@
+package goa
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DateLayout is the layout of the values of Date attributes, e.g. "2017-03-21".
+const DateLayout = "2006-01-02"
+
+// Date is the type of the generated fields, parameters and headers of Date attributes. It is
+// encoded in JSON as a full-date, e.g. "2017-03-21", and decodes both full-dates and RFC3339
+// date-times.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses the value of a Date attribute. The value is either a full-date such as
+// "2017-03-21" or an RFC3339 date-time. The generated code uses ParseDate to decode Date request
+// parameters and headers and to initialize default values.
+func ParseDate(val string) (Date, error) {
+	t, err := time.Parse(DateLayout, val)
+	if err == nil {
+		return Date{t}, nil
+	}
+	if t, err2 := time.Parse(time.RFC3339, val); err2 == nil {
+		return Date{t}, nil
+	}
+	return Date{t}, err
+}
+
+// String returns the date formatted with DateLayout.
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON encodes the date as a JSON string formatted with DateLayout.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(DateLayout))
+}
+
+// UnmarshalJSON decodes a JSON string containing a full-date or an RFC3339 date-time.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
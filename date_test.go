@@ -0,0 +1,57 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseDate", func() {
+	It("parses full-date values", func() {
+		d, err := goa.ParseDate("2017-03-21")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(d.Time).Should(Equal(time.Date(2017, 3, 21, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("parses RFC3339 date-time values", func() {
+		d, err := goa.ParseDate("2017-03-21T10:30:00Z")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(d.Time).Should(Equal(time.Date(2017, 3, 21, 10, 30, 0, 0, time.UTC)))
+	})
+
+	It("fails with invalid values", func() {
+		_, err := goa.ParseDate("21/03/2017")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("Date", func() {
+	type payload struct {
+		Day    goa.Date  `json:"day"`
+		Pickup *goa.Date `json:"pickup,omitempty"`
+	}
+
+	It("is encoded as a full-date", func() {
+		d := goa.Date{Time: time.Date(2017, 3, 21, 10, 30, 0, 0, time.UTC)}
+		b, err := json.Marshal(payload{Day: d, Pickup: &d})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"day":"2017-03-21","pickup":"2017-03-21"}`))
+		Ω(d.String()).Should(Equal("2017-03-21"))
+	})
+
+	It("decodes full-dates and date-times", func() {
+		var p payload
+		Ω(json.Unmarshal([]byte(`{"day":"2017-03-21","pickup":"2017-03-22T10:30:00Z"}`), &p)).ShouldNot(HaveOccurred())
+		Ω(p.Day.Time).Should(Equal(time.Date(2017, 3, 21, 0, 0, 0, 0, time.UTC)))
+		Ω(p.Pickup.Time).Should(Equal(time.Date(2017, 3, 22, 10, 30, 0, 0, time.UTC)))
+	})
+
+	It("rejects invalid values", func() {
+		var p payload
+		Ω(json.Unmarshal([]byte(`{"day":"21/03/2017"}`), &p)).Should(HaveOccurred())
+		Ω(json.Unmarshal([]byte(`{"day":20170321}`), &p)).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,197 @@
+package goa
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DeepObjectParams returns the values of the query string parameters that encode the fields of
+// the deep object parameter with the given name using the "name[key]=value" notation, e.g.
+// "filter[status]=open&filter[owner]=me". The values are indexed by key.
+func DeepObjectParams(params url.Values, name string) url.Values {
+	var res url.Values
+	prefix := name + "["
+	for k, vals := range params {
+		if !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, "]") {
+			continue
+		}
+		if res == nil {
+			res = make(url.Values)
+		}
+		key := k[len(prefix) : len(k)-1]
+		res[key] = append(res[key], vals...)
+	}
+	return res
+}
+
+// DecodeDeepObject decodes the deep object parameter values returned by DeepObjectParams into
+// the struct pointed to by v. The keys are matched against the names given by the "form" tags of
+// the struct fields. Fields may be strings, booleans, numbers, types that implement
+// encoding.TextUnmarshaler such as time.Time, pointers to these types or slices of these types.
+// Keys that do not match any field are ignored. DecodeDeepObject returns an error if the key of a
+// field whose "form" tag includes the "required" option is missing, e.g. `form:"latitude,required"`.
+func DecodeDeepObject(values url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("deep object must be decoded into a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		tag := strings.Split(f.Tag.Get("form"), ",")
+		name := tag[0]
+		if name == "" {
+			name = f.Name
+		}
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			for _, opt := range tag[1:] {
+				if opt == "required" {
+					return fmt.Errorf("missing required key %s", name)
+				}
+			}
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice && !isTextUnmarshaler(fv.Type()) {
+			s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for j, val := range vals {
+				if err := setDeepObjectValue(s.Index(j), val); err != nil {
+					return fmt.Errorf("invalid value %#v for %s: %s", val, name, err)
+				}
+			}
+			fv.Set(s)
+			continue
+		}
+		if err := setDeepObjectValue(fv, vals[0]); err != nil {
+			return fmt.Errorf("invalid value %#v for %s: %s", vals[0], name, err)
+		}
+	}
+	return nil
+}
+
+// EncodeDeepObject encodes the fields of the struct v or pointed to by v into query string
+// values using the "name[key]=value" notation. The keys are the names given by the "form" tags of
+// the struct fields. Nil pointers and slices are omitted.
+func EncodeDeepObject(name string, v interface{}) url.Values {
+	res := make(url.Values)
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return res
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return res
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("form"), ",")[0]
+		if key == "" {
+			key = f.Name
+		}
+		key = name + "[" + key + "]"
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice && !isTextMarshaler(fv.Type()) {
+			for j := 0; j < fv.Len(); j++ {
+				if s, ok := deepObjectValue(fv.Index(j)); ok {
+					res.Add(key, s)
+				}
+			}
+			continue
+		}
+		if s, ok := deepObjectValue(fv); ok {
+			res.Set(key, s)
+		}
+	}
+	return res
+}
+
+// deepObjectValue returns the string representation of v, false if v is a nil pointer.
+func deepObjectValue(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err == nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), true
+	default:
+		return fmt.Sprintf("%v", v.Interface()), true
+	}
+}
+
+// setDeepObjectValue sets v to the value represented by s.
+func setDeepObjectValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := setDeepObjectValue(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	if isTextUnmarshaler(v.Type()) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(s))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// isTextUnmarshaler returns true if pointers to values of type t implement
+// encoding.TextUnmarshaler.
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// isTextMarshaler returns true if values of type t implement encoding.TextMarshaler.
+func isTextMarshaler(t reflect.Type) bool {
+	return t.Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem())
+}
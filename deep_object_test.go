@@ -0,0 +1,115 @@
+package goa_test
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type deepObjectFilter struct {
+	Status *string    `form:"status,omitempty" json:"status,omitempty"`
+	Owner  string     `form:"owner" json:"owner"`
+	Limit  *int       `form:"limit,omitempty" json:"limit,omitempty"`
+	Since  *time.Time `form:"since,omitempty" json:"since,omitempty"`
+	Tags   []string   `form:"tags,omitempty" json:"tags,omitempty"`
+}
+
+var _ = Describe("DeepObjectParams", func() {
+	It("extracts the values of the deep object parameter", func() {
+		params := url.Values{
+			"filter[status]": {"open"},
+			"filter[owner]":  {"me"},
+			"filter":         {"ignored"},
+			"other[status]":  {"closed"},
+		}
+		Ω(goa.DeepObjectParams(params, "filter")).Should(Equal(url.Values{
+			"status": {"open"},
+			"owner":  {"me"},
+		}))
+	})
+
+	It("returns nil when the parameter is absent", func() {
+		Ω(goa.DeepObjectParams(url.Values{"q": {"v"}}, "filter")).Should(BeNil())
+	})
+})
+
+var _ = Describe("DecodeDeepObject", func() {
+	var values url.Values
+	var filter deepObjectFilter
+	var err error
+
+	JustBeforeEach(func() {
+		filter = deepObjectFilter{}
+		err = goa.DecodeDeepObject(values, &filter)
+	})
+
+	Context("with valid values", func() {
+		BeforeEach(func() {
+			values = url.Values{
+				"status": {"open"},
+				"owner":  {"me"},
+				"limit":  {"10"},
+				"since":  {"2017-01-02T03:04:05Z"},
+				"tags":   {"a", "b"},
+				"other":  {"x"},
+			}
+		})
+
+		It("decodes the fields", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*filter.Status).Should(Equal("open"))
+			Ω(filter.Owner).Should(Equal("me"))
+			Ω(*filter.Limit).Should(Equal(10))
+			Ω(filter.Since.Equal(time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC))).Should(BeTrue())
+			Ω(filter.Tags).Should(Equal([]string{"a", "b"}))
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			values = url.Values{"limit": {"ten"}}
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`invalid value "ten" for limit`))
+		})
+	})
+
+	It("rejects values missing required keys", func() {
+		var near goa.Proximity
+		err := goa.DecodeDeepObject(url.Values{"latitude": {"48.85"}}, &near)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("missing required key longitude"))
+	})
+})
+
+var _ = Describe("EncodeDeepObject", func() {
+	It("encodes the non nil fields", func() {
+		status := "open"
+		filter := &deepObjectFilter{Status: &status, Owner: "me", Tags: []string{"a", "b"}}
+		Ω(goa.EncodeDeepObject("filter", filter)).Should(Equal(url.Values{
+			"filter[status]": {"open"},
+			"filter[owner]":  {"me"},
+			"filter[tags]":   {"a", "b"},
+		}))
+	})
+
+	It("encodes nothing for nil values", func() {
+		var filter *deepObjectFilter
+		Ω(goa.EncodeDeepObject("filter", filter)).Should(BeEmpty())
+	})
+
+	It("round trips through DecodeDeepObject", func() {
+		limit := 5
+		since := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+		filter := &deepObjectFilter{Owner: "me", Limit: &limit, Since: &since}
+		encoded := goa.EncodeDeepObject("filter", filter)
+		var decoded deepObjectFilter
+		Ω(goa.DecodeDeepObject(goa.DeepObjectParams(encoded, "filter"), &decoded)).Should(Succeed())
+		Ω(decoded).Should(Equal(*filter))
+	})
+})
@@ -9,6 +9,50 @@ import (
 	"github.com/goadesign/goa/dslengine"
 )
 
+// AliasPolicy defines how the requests made to a route alias are handled.
+type AliasPolicy string
+
+const (
+	// AliasServe means that requests made to the alias are handled by the route action.
+	AliasServe AliasPolicy = "serve"
+	// AliasRedirect means that requests made to the alias are permanently redirected (301) to
+	// the route path.
+	AliasRedirect AliasPolicy = "redirect"
+	// AliasGone means that requests made to the alias receive a 410 Gone response.
+	AliasGone AliasPolicy = "gone"
+)
+
+// Pagination styles, see the Paginates DSL.
+const (
+	// PagePagination selects the page with the "page" and "per_page" query string params.
+	// Responses include the total number of results in the X-Total-Count header.
+	PagePagination = "page"
+	// CursorPagination selects the page with the "cursor" and "per_page" query string params,
+	// the cursor being an opaque value that identifies the first result of the page.
+	CursorPagination = "cursor"
+)
+
+// Pagination defaults, see the Paginates DSL.
+const (
+	// DefaultPageSize is the default number of results per page.
+	DefaultPageSize = 20
+	// DefaultMaxPageSize is the default maximum number of results per page.
+	DefaultMaxPageSize = 100
+)
+
+// Collection formats of array params, see the CollectionFormat DSL.
+const (
+	// CollectionFormatMulti encodes each array element as a separate param, e.g.
+	// "?tag=a&tag=b". This is the default format.
+	CollectionFormatMulti = "multi"
+	// CollectionFormatCSV encodes the array elements as comma separated values, e.g.
+	// "?tags=a,b".
+	CollectionFormatCSV = "csv"
+	// CollectionFormatPipes encodes the array elements as pipe separated values, e.g.
+	// "?tags=a|b".
+	CollectionFormatPipes = "pipes"
+)
+
 // MediaTypeRoot is the data structure that represents the additional DSL definition root
 // that contains the media type definition set created by CollectionOf index by canonical id.
 type MediaTypeRoot map[string]*MediaTypeDefinition
@@ -79,6 +123,14 @@ var (
 	// WildcardRegex is the regular expression used to capture path parameters.
 	WildcardRegex = regexp.MustCompile(`/(?::|\*)([a-zA-Z0-9_]+)`)
 
+	// OptionalSegmentRegex is the regular expression used to capture the optional trailing
+	// segment of a route path, e.g. "(/versions/:version)?" in "/items/:id(/versions/:version)?".
+	OptionalSegmentRegex = regexp.MustCompile(`\((/[^()]+)\)\?$`)
+
+	// CatchAllRegex is the regular expression used to capture catch-all path parameters, e.g.
+	// "/*filepath". A catch-all parameter matches the remainder of the request path.
+	CatchAllRegex = regexp.MustCompile(`/\*([a-zA-Z0-9_]+)`)
+
 	// DefaultDecoders contains the decoding definitions used when no Consumes DSL is found.
 	DefaultDecoders []*EncodingDefinition
 
@@ -88,31 +140,35 @@ var (
 	// KnownEncoders contains the list of encoding packages and factories known by goa indexed
 	// by MIME type.
 	KnownEncoders = map[string]string{
-		"application/json":      "github.com/goadesign/goa",
-		"application/xml":       "github.com/goadesign/goa",
-		"application/gob":       "github.com/goadesign/goa",
-		"application/x-gob":     "github.com/goadesign/goa",
-		"application/binc":      "github.com/goadesign/goa/encoding/binc",
-		"application/x-binc":    "github.com/goadesign/goa/encoding/binc",
-		"application/cbor":      "github.com/goadesign/goa/encoding/cbor",
-		"application/x-cbor":    "github.com/goadesign/goa/encoding/cbor",
-		"application/msgpack":   "github.com/goadesign/goa/encoding/msgpack",
-		"application/x-msgpack": "github.com/goadesign/goa/encoding/msgpack",
+		"application/json":                  "github.com/goadesign/goa",
+		"application/geo+json":              "github.com/goadesign/goa",
+		"application/xml":                   "github.com/goadesign/goa",
+		"application/gob":                   "github.com/goadesign/goa",
+		"application/x-gob":                 "github.com/goadesign/goa",
+		"application/binc":                  "github.com/goadesign/goa/encoding/binc",
+		"application/x-binc":                "github.com/goadesign/goa/encoding/binc",
+		"application/cbor":                  "github.com/goadesign/goa/encoding/cbor",
+		"application/x-cbor":                "github.com/goadesign/goa/encoding/cbor",
+		"application/msgpack":               "github.com/goadesign/goa/encoding/msgpack",
+		"application/x-msgpack":             "github.com/goadesign/goa/encoding/msgpack",
+		"application/x-www-form-urlencoded": "github.com/goadesign/goa",
 	}
 
 	// KnownEncoderFunctions contains the list of encoding encoder and decoder functions known
 	// by goa indexed by MIME type.
 	KnownEncoderFunctions = map[string][2]string{
-		"application/json":      {"NewJSONEncoder", "NewJSONDecoder"},
-		"application/xml":       {"NewXMLEncoder", "NewXMLDecoder"},
-		"application/gob":       {"NewGobEncoder", "NewGobDecoder"},
-		"application/x-gob":     {"NewGobEncoder", "NewGobDecoder"},
-		"application/binc":      {"NewEncoder", "NewDecoder"},
-		"application/x-binc":    {"NewEncoder", "NewDecoder"},
-		"application/cbor":      {"NewEncoder", "NewDecoder"},
-		"application/x-cbor":    {"NewEncoder", "NewDecoder"},
-		"application/msgpack":   {"NewEncoder", "NewDecoder"},
-		"application/x-msgpack": {"NewEncoder", "NewDecoder"},
+		"application/json":                  {"NewJSONEncoder", "NewJSONDecoder"},
+		"application/geo+json":              {"NewGeoJSONEncoder", "NewJSONDecoder"},
+		"application/xml":                   {"NewXMLEncoder", "NewXMLDecoder"},
+		"application/gob":                   {"NewGobEncoder", "NewGobDecoder"},
+		"application/x-gob":                 {"NewGobEncoder", "NewGobDecoder"},
+		"application/binc":                  {"NewEncoder", "NewDecoder"},
+		"application/x-binc":                {"NewEncoder", "NewDecoder"},
+		"application/cbor":                  {"NewEncoder", "NewDecoder"},
+		"application/x-cbor":                {"NewEncoder", "NewDecoder"},
+		"application/msgpack":               {"NewEncoder", "NewDecoder"},
+		"application/x-msgpack":             {"NewEncoder", "NewDecoder"},
+		"application/x-www-form-urlencoded": {"NewFormEncoder", "NewFormDecoder"},
 	}
 
 	// JSONContentTypes list the Content-Type header values that cause goa to encode or decode
@@ -166,6 +222,11 @@ var (
 			Description: "an application-specific error code, expressed as a string value.",
 			Example:     "invalid_value",
 		},
+		"kind": &AttributeDefinition{
+			Type:        String,
+			Description: "a stable machine-readable code identifying the kind of request validation error.",
+			Example:     "missing_param",
+		},
 		"detail": &AttributeDefinition{
 			Type:        String,
 			Description: "a human-readable explanation specific to this occurrence of the problem.",
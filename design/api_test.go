@@ -120,3 +120,38 @@ var _ = Describe("MediaTypeRoot", func() {
 		Ω(sets[0][1]).Should(Equal(root["bar"]))
 	})
 })
+
+var _ = Describe("ApplyOverlay", func() {
+	It("sets feature flags on APIs that do not define any", func() {
+		api := &design.APIDefinition{
+			Name:     "test",
+			Overlays: map[string]*design.OverlayDefinition{"production": {Features: map[string]bool{"search": true}}},
+		}
+		Ω(api.ApplyOverlay("production")).ShouldNot(HaveOccurred())
+		Ω(api.Features).Should(Equal(map[string]bool{"search": true}))
+	})
+})
+
+var _ = Describe("RoutePath", func() {
+	var api *design.APIDefinition
+
+	BeforeEach(func() {
+		api = &design.APIDefinition{Name: "test", BasePath: "/cellar"}
+	})
+
+	It("inserts the path prefix after the API base path", func() {
+		v := &design.VersionDefinition{Name: "v2", Scheme: design.VersionPath, Key: "/v2", Parent: api}
+		Ω(v.RoutePath("/cellar/accounts/:id")).Should(Equal("/cellar/v2/accounts/:id"))
+		Ω(v.RoutePath("/cellar")).Should(Equal("/cellar/v2"))
+	})
+
+	It("prefixes the paths outside of the API base path", func() {
+		v := &design.VersionDefinition{Name: "v2", Scheme: design.VersionPath, Key: "/v2", Parent: api}
+		Ω(v.RoutePath("/cellars/:id")).Should(Equal("/v2/cellars/:id"))
+	})
+
+	It("does not change the path of versions selected with a header", func() {
+		v := &design.VersionDefinition{Name: "v2", Scheme: design.VersionHeader, Key: "X-Api-Version", Parent: api}
+		Ω(v.RoutePath("/cellar/accounts")).Should(Equal("/cellar/accounts"))
+	})
+})
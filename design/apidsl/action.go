@@ -2,6 +2,8 @@ package apidsl
 
 import (
 	"fmt"
+	"strings"
+	"time"
 	"unicode"
 
 	"github.com/goadesign/goa/design"
@@ -123,11 +125,22 @@ func Action(name string, dsl func()) {
 // [httptreemux](https://godoc.org/github.com/dimfeld/httptreemux) package documentation. These
 // wildcards define parameters using the `:name` or `*name` syntax where `:name` matches a path
 // segment and `*name` is a catch-all that matches the path until the end.
+//
+// The last segment of a route path may be made optional using the `(/segment)?` syntax, for
+// example:
+//
+//	Routing(GET("/items/:id(/versions/:version)?"))
+//
+// is equivalent to the two routes "/items/:id" and "/items/:id/versions/:version". The params
+// captured by the optional segment are optional action params, they cannot be required.
 func Routing(routes ...*design.RouteDefinition) {
 	if a, ok := actionDefinition(); ok {
 		for _, r := range routes {
 			r.Parent = a
 			a.Routes = append(a.Routes, r)
+			if full := r.SplitOptional(); full != nil {
+				a.Routes = append(a.Routes, full)
+			}
 		}
 	}
 }
@@ -246,6 +259,34 @@ func PATCH(path string, dsl ...func()) *design.RouteDefinition {
 	return route
 }
 
+// Alias can be used in: GET, HEAD, POST, PUT, DELETE, OPTIONS, TRACE, CONNECT, PATCH
+//
+// Alias declares an alternate path for the route, typically a legacy path. The policy defines how
+// requests made to the alias are handled: design.AliasServe (the default) handles them like
+// requests made to the route, design.AliasRedirect permanently redirects them to the route path
+// and design.AliasGone responds with 410 Gone. Aliases that are served or redirected must define
+// the same wildcards as the route. Example:
+//
+//	Routing(GET("/:bottleID", func() {
+//		Alias("/bottle/:bottleID")                              // Served as is
+//		Alias("/wines/:bottleID", design.AliasRedirect)         // 301 to /bottles/:bottleID
+//		Alias("/v0/bottles/:bottleID", design.AliasGone)        // 410 Gone
+//	}))
+//
+func Alias(path string, policy ...design.AliasPolicy) {
+	if len(policy) > 1 {
+		dslengine.ReportError("too many arguments given to Alias")
+		return
+	}
+	if r, ok := routeDefinition(); ok {
+		alias := &design.RouteAliasDefinition{Path: path, Policy: design.AliasServe, Parent: r}
+		if len(policy) == 1 {
+			alias.Policy = policy[0]
+		}
+		r.Aliases = append(r.Aliases, alias)
+	}
+}
+
 // Headers can be used in: Action, Response, Resource
 //
 // Headers implements the DSL for describing HTTP headers. The DSL syntax is identical to the one
@@ -316,6 +357,28 @@ func Headers(params ...interface{}) {
 	}
 }
 
+// Cookies can be used in: Action
+//
+// Cookies describes the cookies read by the action, e.g. a session or a CSRF token cookie. Each
+// cookie is described with Cookie which uses the same DSL as Attribute. Cookies must be primitives,
+// the generated code reads their values with http.Request.Cookie and validates them like params:
+//
+//	Cookies(func() {
+//		Cookie("session", String, func() {
+//			MinLength(32)
+//		})
+//		Cookie("csrf_token")
+//		Required("session")
+//	})
+func Cookies(dsl func()) {
+	if a, ok := actionDefinition(); ok {
+		cookies := newAttribute(a.Parent.MediaType)
+		if dslengine.Execute(dsl, cookies) {
+			a.Cookies = a.Cookies.Merge(cookies)
+		}
+	}
+}
+
 // Params can be used in: Action, Resource, API
 //
 // Params describe the action parameters, either path parameters identified via wildcards or query
@@ -418,63 +481,967 @@ func OptionalPayload(p interface{}, dsls ...func()) {
 	payload(true, p, dsls...)
 }
 
+// MultipartForm can be used in: Action
+//
+// MultipartForm declares that the action payload is encoded as a "multipart/form-data" request
+// body. The payload attributes of type File hold the uploaded files, the other attributes hold
+// the form values. File attributes cannot be used anywhere else. The generated code decodes the
+// form parts as they are read: the MaxLength validation of a File attribute sets the maximum
+// size of the file in bytes and requests are rejected with a 413 Request Entity Too Large
+// response as soon as a file exceeds it. File contents that do not fit in the memory set by the
+// service MaxMultipartMemory field are written to temporary files which are removed once the
+// request completes. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST("/documents"))
+//		MultipartForm()
+//		Payload(func() {
+//			Attribute("title", String)
+//			Attribute("document", File, func() {
+//				MaxLength(10 * 1024 * 1024) // 10MB
+//			})
+//			Required("document")
+//		})
+//		Response(Created)
+//	})
+//
+func MultipartForm() {
+	if a, ok := actionDefinition(); ok {
+		a.PayloadMultipart = true
+	}
+}
+
+// Streaming can be used in: Action, Response
+//
+// Streaming declares that a large collection is streamed instead of being fully buffered. When
+// used in an Action DSL the action payload, which must be a collection, is streamed: the generated
+// context Payload field is the request body reader and the action handler decodes the elements
+// one at a time, for example using goa.NewCollectionDecoder. When used in a Response DSL the
+// response body is streamed: the response media type must be a collection, the generated response
+// method writes the headers and returns the writer used to write the body, for example using
+// goa.NewCollectionEncoder. Example:
+//
+//	Action("import", func() {
+//		Routing(POST("/import"))
+//		Payload(ArrayOf(Record))
+//		Streaming()
+//		Response(OK, RecordCollection, func() {
+//			Streaming()
+//		})
+//	})
+//
+func Streaming() {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.PayloadStreaming = true
+	case *design.ResponseDefinition:
+		def.Chunked = true
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 func payload(isOptional bool, p interface{}, dsls ...func()) {
+	if a, ok := actionDefinition(); ok {
+		if ut := actionType(a, "Payload", "Payload", p, dsls...); ut != nil {
+			a.Payload = writablePayload(a, ut)
+			a.PayloadOptional = isOptional
+		}
+	}
+}
+
+// writablePayload returns the payload type stripped of its read-only attributes. The stripped type
+// is named after the action and the resource like the payload types built by the Payload DSL.
+func writablePayload(a *design.ActionDefinition, ut *design.UserTypeDefinition) *design.UserTypeDefinition {
+	if ut.Type == nil {
+		return ut
+	}
+	o := ut.Type.ToObject()
+	if o == nil {
+		return ut
+	}
+	var readOnly []string
+	for n, att := range o {
+		if att.ReadOnly {
+			readOnly = append(readOnly, n)
+		}
+	}
+	if len(readOnly) == 0 {
+		return ut
+	}
+	att := design.DupAtt(ut.AttributeDefinition)
+	stripped := make(design.Object, len(o)-len(readOnly))
+	for n, child := range o {
+		if !child.ReadOnly {
+			stripped[n] = child
+		}
+	}
+	att.Type = stripped
+	if att.Validation != nil {
+		var required []string
+		for _, n := range att.Validation.Required {
+			if _, ok := stripped[n]; ok {
+				required = append(required, n)
+			}
+		}
+		att.Validation.Required = required
+	}
+	return &design.UserTypeDefinition{
+		AttributeDefinition: att,
+		TypeName:            fmt.Sprintf("%s%sPayload", camelize(a.Name), camelize(a.Parent.Name)),
+	}
+}
+
+// actionType returns the user type described by the arguments of the Payload or Inbound DSL
+// named fn. The type of the attribute built by the DSL is named after the action, the resource
+// and the given suffix.
+func actionType(a *design.ActionDefinition, fn, suffix string, p interface{}, dsls ...func()) *design.UserTypeDefinition {
 	if len(dsls) > 1 {
-		dslengine.ReportError("too many arguments given to Payload")
+		dslengine.ReportError("too many arguments given to %s", fn)
+		return nil
+	}
+	var att *design.AttributeDefinition
+	var dsl func()
+	switch actual := p.(type) {
+	case func():
+		dsl = actual
+		att = newAttribute(a.Parent.MediaType)
+		att.Type = design.Object{}
+	case *design.AttributeDefinition:
+		att = design.DupAtt(actual)
+	case *design.UserTypeDefinition:
+		if len(dsls) == 0 {
+			return actual
+		}
+		att = design.DupAtt(actual.Definition())
+	case *design.MediaTypeDefinition:
+		att = design.DupAtt(actual.AttributeDefinition)
+	case string:
+		ut, ok := design.Design.Types[actual]
+		if !ok {
+			dslengine.ReportError("unknown %s type %s", strings.ToLower(fn), actual)
+			return nil
+		}
+		att = design.DupAtt(ut.AttributeDefinition)
+	case *design.Array:
+		att = &design.AttributeDefinition{Type: actual}
+	case *design.Hash:
+		att = &design.AttributeDefinition{Type: actual}
+	case design.Primitive:
+		att = &design.AttributeDefinition{Type: actual}
+	default:
+		dslengine.ReportError("invalid %s argument, must be a type, a media type or a DSL building a type", fn)
+		return nil
+	}
+	if len(dsls) == 1 {
+		if dsl != nil {
+			dslengine.ReportError("invalid arguments in %s call, must be (type), (dsl) or (type, dsl)", fn)
+		}
+		dsl = dsls[0]
+	}
+	if dsl != nil {
+		dslengine.Execute(dsl, att)
+	}
+	rn := camelize(a.Parent.Name)
+	an := camelize(a.Name)
+	return &design.UserTypeDefinition{
+		AttributeDefinition: att,
+		TypeName:            fmt.Sprintf("%s%s%s", an, rn, suffix),
+	}
+}
+
+// WebSocket can be used in: Action
+//
+// WebSocket declares that the action is a websocket endpoint and describes the messages exchanged
+// over the connection. The DSL uses Inbound to define the type of the messages sent by clients
+// and Outbound to define the media type of the messages sent by the server, both are optional
+// but at least one must be defined. The action scheme defaults to "ws" if none is set with
+// Scheme. Websocket actions cannot have a payload.
+//
+// The generated action context exposes a ReceiveMessage method that reads and validates the next
+// inbound message and a SendMessage method that writes an outbound message, messages are encoded
+// in JSON. Example:
+//
+//	Action("chat", func() {
+//		Routing(GET("/rooms/:roomID/chat"))
+//		WebSocket(func() {
+//			Inbound(func() {
+//				Attribute("text", String, func() {
+//					MaxLength(280)
+//				})
+//				Required("text")
+//			})
+//			Outbound(ChatEventMedia)
+//		})
+//		Response(SwitchingProtocols)
+//	})
+//
+func WebSocket(dsl func()) {
+	if a, ok := actionDefinition(); ok {
+		ws := &design.WebSocketDefinition{Parent: a}
+		if !dslengine.Execute(dsl, ws) {
+			return
+		}
+		a.Messages = ws
+		if len(a.Schemes) == 0 {
+			a.Schemes = []string{"ws"}
+		}
+	}
+}
+
+// Inbound can be used in: WebSocket
+//
+// Inbound defines the type of the messages sent by clients over the websocket connection. It
+// accepts the same arguments as Payload, the type must be an object. The messages are validated
+// like payloads.
+func Inbound(p interface{}, dsls ...func()) {
+	if w, ok := webSocketDefinition(); ok {
+		if ut := actionType(w.Parent, "Inbound", "InboundMessage", p, dsls...); ut != nil {
+			w.Inbound = ut
+		}
+	}
+}
+
+// Outbound can be used in: WebSocket
+//
+// Outbound defines the media type of the messages sent by the server over the websocket
+// connection. The first argument is the media type definition or identifier, the optional second
+// argument is the name of the view used to render the messages and defaults to "default".
+func Outbound(mt interface{}, view ...string) {
+	w, ok := webSocketDefinition()
+	if !ok {
+		return
+	}
+	switch actual := mt.(type) {
+	case *design.MediaTypeDefinition:
+		w.Outbound = actual.Identifier
+	case string:
+		w.Outbound = actual
+	default:
+		dslengine.ReportError("invalid Outbound argument, must be a media type or a media type identifier")
 		return
 	}
+	w.OutboundView = "default"
+	if len(view) > 0 {
+		w.OutboundView = view[0]
+	}
+}
+
+// SLO can be used in: Action
+//
+// SLO defines the service level objectives of the action: the maximum 99th percentile latency
+// expressed as a Go duration string and the minimum availability expressed as a percentage.
+// The objectives are used by the "slo" goagen command to generate Prometheus recording and
+// alerting rules. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		SLO("200ms", 99.9)	// 99% of requests complete under 200ms, 99.9% do not fail
+//	})
+//
+func SLO(latencyP99 string, availability float64) {
+	if a, ok := actionDefinition(); ok {
+		latency, err := time.ParseDuration(latencyP99)
+		if err != nil {
+			dslengine.ReportError("invalid SLO latency %#v: %s", latencyP99, err)
+			return
+		}
+		a.SLO = &design.SLODefinition{LatencyP99: latency, Availability: availability}
+	}
+}
+
+// MaxConcurrency can be used in: Action
+//
+// MaxConcurrency caps the number of requests handled concurrently by the action. The generated
+// code rejects the requests received while the limit is reached with 429 Too Many Requests
+// unless a queue is declared with ConcurrencyQueue. The generated code also reports the number
+// of in-flight and queued requests as metrics, see goa.ConcurrencyLimiter. Example:
+//
+//	Action("export", func() {
+//		Routing(POST("/exports"))
+//		MaxConcurrency(4)
+//		ConcurrencyQueue(20, "10s")
+//	})
+//
+func MaxConcurrency(limit int) {
+	if a, ok := actionDefinition(); ok {
+		if a.Concurrency == nil {
+			a.Concurrency = &design.ConcurrencyDefinition{}
+		}
+		a.Concurrency.MaxInFlight = limit
+	}
+}
+
+// ConcurrencyQueue can be used in: Action
+//
+// ConcurrencyQueue declares the queue of the requests received while the limit set with
+// MaxConcurrency is reached. At most size requests wait for a slot for at most timeout, expressed
+// as a Go duration string. The empty string means requests wait until they are canceled. The
+// generated code rejects requests with 503 Service Unavailable when the queue is full or when they
+// time out, the error metadata includes the queue depth.
+func ConcurrencyQueue(size int, timeout string) {
 	if a, ok := actionDefinition(); ok {
-		var att *design.AttributeDefinition
-		var dsl func()
-		switch actual := p.(type) {
-		case func():
-			dsl = actual
-			att = newAttribute(a.Parent.MediaType)
-			att.Type = design.Object{}
-		case *design.AttributeDefinition:
-			att = design.DupAtt(actual)
-		case *design.UserTypeDefinition:
-			if len(dsls) == 0 {
-				a.Payload = actual
-				a.PayloadOptional = isOptional
+		var d time.Duration
+		if timeout != "" {
+			var err error
+			if d, err = time.ParseDuration(timeout); err != nil {
+				dslengine.ReportError("invalid concurrency queue timeout %#v: %s", timeout, err)
 				return
 			}
-			att = design.DupAtt(actual.Definition())
+		}
+		if a.Concurrency == nil {
+			a.Concurrency = &design.ConcurrencyDefinition{}
+		}
+		a.Concurrency.QueueSize = size
+		a.Concurrency.QueueTimeout = d
+	}
+}
+
+// ReplayProtected can be used in: Action
+//
+// ReplayProtected flags the action as protected against request replays. The generated code
+// runs the middleware mounted with the generated UseReplayProtectionMiddleware function prior to
+// invoking the action controller, see the middleware package ReplayProtection function.
+// Example:
+//
+//	Action("transfer", func() {
+//		Routing(POST("/transfers"))
+//		ReplayProtected()
+//	})
+//
+func ReplayProtected() {
+	if a, ok := actionDefinition(); ok {
+		a.ReplayProtected = true
+	}
+}
+
+// RequireDigest can be used in: Action
+//
+// RequireDigest requires requests made to the action to include a Digest header (RFC 3230)
+// computed from the request body using the given algorithm. The algorithm must be one of
+// "SHA-256" (default), "SHA-512" or "MD5". When the algorithm is "MD5" requests may also use the
+// Content-MD5 header (RFC 1864). The generated code verifies the digest while decoding the request
+// payload and responds with a 400 "integrity_error" error on mismatch. The generated client
+// computes and sets the header. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST("/documents"))
+//		Payload(DocumentPayload)
+//		RequireDigest("SHA-256")
+//	})
+//
+func RequireDigest(algorithm ...string) {
+	if len(algorithm) > 1 {
+		dslengine.ReportError("too many arguments given to RequireDigest")
+		return
+	}
+	if a, ok := actionDefinition(); ok {
+		a.DigestAlgorithm = "SHA-256"
+		if len(algorithm) == 1 {
+			a.DigestAlgorithm = algorithm[0]
+		}
+	}
+}
+
+// AcceptRanges can be used in: Action
+//
+// AcceptRanges enables range requests on the action. The generated action context exposes a
+// ServeContent method that writes the response content handling the Range, If-Range and
+// conditional request headers: requests for a single range produce a 206 Partial Content response
+// while requests for multiple ranges produce a multipart/byteranges response. This makes it
+// possible for clients to resume large downloads. Example:
+//
+//	Action("download", func() {
+//		Routing(GET("/:id/content"))
+//		AcceptRanges()
+//		Response(OK, "application/octet-stream")
+//	})
+//
+func AcceptRanges() {
+	if a, ok := actionDefinition(); ok {
+		a.AcceptRanges = true
+	}
+}
+
+// ResumableUpload can be used in: Action
+//
+// ResumableUpload declares the action as a resumable upload endpoint implementing the tus
+// protocol (https://tus.io). The action must define a POST route used to create uploads, the
+// generated code also mounts the HEAD and PATCH handlers on the route path suffixed with
+// "/:upload_id" and the OPTIONS handler on the route path. Upload offsets are tracked using the
+// storage set in the service context with the tus package WithStorage function. The action
+// controller is invoked once an upload completes, the generated context Upload field describes
+// the completed upload. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST("/uploads"))
+//		ResumableUpload()
+//		Response(NoContent)
+//	})
+//
+func ResumableUpload() {
+	if a, ok := actionDefinition(); ok {
+		a.Resumable = true
+	}
+}
+
+// SignedURL can be used in: Action
+//
+// SignedURL lets clients invoke the action using pre-signed URLs, typically to share download
+// links with parties that cannot authenticate. Requests whose URL carries a signature are verified
+// by the middleware mounted with the generated UseSignedURLMiddleware function, see the signedurl
+// package New function. If the action also defines a security scheme then requests with no
+// signature are authenticated using the scheme, otherwise they are rejected. The generated client
+// exposes a Sign method that produces signed URLs for the action. Example:
+//
+//	Action("download", func() {
+//		Routing(GET("/files/:id"))
+//		Security(JWT)
+//		SignedURL()
+//	})
+//
+func SignedURL() {
+	if a, ok := actionDefinition(); ok {
+		a.SignedURL = true
+	}
+}
+
+// RenderHTML can be used in: Action
+//
+// RenderHTML lets the action serve both API clients and browsers: the responses that use a media
+// type are rendered as HTML when the request Accept header prefers text/html and a template is
+// registered for the media type and view with the service HTMLTemplate method. Other requests
+// get the response encoded as usual. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		RenderHTML()
+//		Response(OK, BottleMedia)
+//	})
+//
+func RenderHTML() {
+	if a, ok := actionDefinition(); ok {
+		a.RenderHTML = true
+	}
+}
+
+// OptimisticConcurrency can be used in: Action
+//
+// OptimisticConcurrency requires requests made to the action to carry an If-Match header whose
+// value is the version of the resource the client last read. versionAttribute is the name of the
+// media type attribute that holds the version. The generated context ExpectedVersion field is
+// initialized with the header value, requests with no If-Match header are rejected with a 428
+// response. The controller compares the expected version with the current one using the
+// generated context CheckVersion method which returns an error resulting in a 412 response on
+// mismatch. Example:
+//
+//	Action("update", func() {
+//		Routing(PUT("/:id"))
+//		Payload(BottlePayload)
+//		OptimisticConcurrency("version")
+//	})
+//
+func OptimisticConcurrency(versionAttribute string) {
+	if a, ok := actionDefinition(); ok {
+		a.VersionAttribute = versionAttribute
+	}
+}
+
+// Near can be used in: Action
+//
+// Near declares a query string parameter of type Proximity that lets clients of collection
+// actions select the resources located within a distance of a point. The parameter name defaults
+// to "near", the parameter is encoded using the deep object notation, e.g.
+// "?near[latitude]=48.85&near[longitude]=2.35&near[radius]=500" where the radius is in meters.
+// The generated context field is initialized with the parsed and validated goa.Proximity value,
+// use its Includes method to filter the resources. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Near()
+//		Within("bbox")
+//		Response(OK, CollectionOf(StoreMedia))
+//	})
+//
+func Near(name ...string) {
+	geoParam("Near", "near", design.Proximity, name)
+}
+
+// Within can be used in: Action
+//
+// Within declares a query string parameter of type BoundingBox that lets clients of collection
+// actions select the resources located in a geographic area. The parameter name defaults to
+// "within", the parameter is encoded using the deep object notation, e.g.
+// "?within[west]=2.2&within[south]=48.8&within[east]=2.5&within[north]=48.9". The generated
+// context field is initialized with the parsed and validated goa.BoundingBox value, use its
+// Contains method to filter the resources. See Near for an example.
+func Within(name ...string) {
+	geoParam("Within", "within", design.BoundingBox, name)
+}
+
+// geoParam adds the parameter declared with the Near or Within DSL to the current action.
+func geoParam(dsl, def string, t *design.UserTypeDefinition, name []string) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if len(name) > 1 {
+		dslengine.ReportError("%s: too many arguments", dsl)
+		return
+	}
+	if len(name) == 1 {
+		def = name[0]
+	}
+	params := &design.AttributeDefinition{Type: design.Object{def: &design.AttributeDefinition{
+		Type:        t,
+		Description: t.Description,
+	}}}
+	a.Params = a.Params.Merge(params)
+}
+
+// Emits can be used in: Action
+//
+// Emits declares the names of the events published by the action. The "outbox" goagen command
+// generates a transactional outbox for these events so that they are recorded in the same database
+// transaction as the changes made by the action controller and delivered asynchronously by a relay
+// worker. Example:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Payload(BottlePayload)
+//		Emits("bottle.created")
+//	})
+//
+func Emits(events ...string) {
+	if a, ok := actionDefinition(); ok {
+		a.Emits = append(a.Emits, events...)
+	}
+}
+
+// Saga can be used in: Action
+//
+// Saga declares the compensable multi-step operation run by the action. The DSL lists the saga
+// steps in execution order using Step. The generated code includes a struct with one field per
+// step holding the step implementation and a constructor that builds the saga coordinator, see
+// the saga package. If a step fails the steps that completed are compensated in reverse order.
+// Example:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Saga("place_order", func() {
+//			Step("reserve", "Reserve the items in the inventory")
+//			Step("charge", "Charge the customer")
+//			Step("ship")
+//		})
+//	})
+//
+func Saga(name string, dsl func()) {
+	if a, ok := actionDefinition(); ok {
+		saga := &design.SagaDefinition{Parent: a, Name: name}
+		if !dslengine.Execute(dsl, saga) {
+			return
+		}
+		a.Saga = saga
+	}
+}
+
+// Step can be used in: Saga
+//
+// Step adds a step to the saga. The optional description is used in the generated code comments.
+func Step(name string, description ...string) {
+	if s, ok := sagaDefinition(); ok {
+		step := &design.SagaStepDefinition{Name: name}
+		if len(description) > 0 {
+			step.Description = description[0]
+		}
+		s.Steps = append(s.Steps, step)
+	}
+}
+
+// Enrich can be used in: Action
+//
+// Enrich declares a value derived from an action param that is resolved before the controller
+// runs, e.g. the user record identified by a user ID param. The generated code includes a struct
+// with one field per enrichment holding the function that looks up the value given the typed
+// param, a function to mount these lookups on the service and a method on the action context that
+// returns the value. Concurrent lookups of the same param value are coalesced into a single call
+// and the results may be cached with CacheFor, see the enrich package. The optional DSL may use
+// Description and CacheFor. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:bottleID"))
+//		Params(func() {
+//			Param("bottleID", Integer)
+//			Param("ownerID", Integer)
+//			Required("ownerID")
+//		})
+//		Enrich("owner", "ownerID", func() {
+//			Description("The bottle owner account")
+//			CacheFor(5 * time.Minute)
+//		})
+//	})
+//
+func Enrich(name, param string, dsl ...func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Enrich")
+		return
+	}
+	e := &design.EnrichmentDefinition{Parent: a, Name: name, Param: param}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], e) {
+			return
+		}
+	}
+	a.Enrichments = append(a.Enrichments, e)
+}
+
+// CacheFor can be used in: Enrich
+//
+// CacheFor sets the duration during which the values looked up by the enrichment are cached.
+// Values are not cached by default.
+func CacheFor(ttl time.Duration) {
+	if e, ok := enrichmentDefinition(); ok {
+		e.TTL = ttl
+	}
+}
+
+// Middleware can be used in: API, Resource, Action
+//
+// Middleware declares a middleware mounted on the service, on the resource controller or on the
+// action. The DSL lists the context values set by the middleware with Provides so that the design
+// validation can check that the values required by the actions are available, the middleware
+// itself is implemented and mounted by the service as usual. The optional DSL may also use
+// Description. Example:
+//
+//	Resource("bottle", func() {
+//		Middleware("tenant", func() {
+//			Description("Resolves the tenant from the request host")
+//			Provides("tenant")
+//		})
+//	})
+//
+func Middleware(name string, dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Middleware")
+		return
+	}
+	var mws *[]*design.MiddlewareDefinition
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		mws = &def.Middleware
+	case *design.ResourceDefinition:
+		mws = &def.Middleware
+	case *design.ActionDefinition:
+		mws = &def.Middleware
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	m := &design.MiddlewareDefinition{Name: name, Parent: dslengine.CurrentDefinition()}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], m) {
+			return
+		}
+	}
+	*mws = append(*mws, m)
+}
+
+// Provides can be used in: Middleware, BasicAuthSecurity, APIKeySecurity, OAuth2Security,
+// JWTSecurity
+//
+// Provides lists the names of the context values set by the middleware or by the middleware that
+// implements the security scheme. The values must be declared with ContextValue. Example:
+//
+//	JWTSecurity("jwt", func() {
+//		Header("Authorization")
+//		Provides("user")
+//	})
+//
+func Provides(names ...string) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.MiddlewareDefinition:
+		def.Provides = append(def.Provides, names...)
+	case *design.SecuritySchemeDefinition:
+		def.Provides = append(def.Provides, names...)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// RequiresContext can be used in: Action
+//
+// RequiresContext lists the names of the context values the action depends on. The values must
+// be declared with ContextValue and provided by a middleware or a security scheme that applies to
+// the action, see Provides. The controller retrieves the values with the generated functions,
+// e.g. ContextUser. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:bottleID"))
+//		RequiresContext("tenant", "user")
+//	})
+//
+func RequiresContext(names ...string) {
+	if a, ok := actionDefinition(); ok {
+		a.RequiredContext = append(a.RequiredContext, names...)
+	}
+}
+
+// Aggregate can be used in: Action
+//
+// Aggregate declares that the action composes the results of downstream actions into the given
+// composite media type, typically to implement a backend for frontend (BFF) endpoint. The media
+// type can be given as a pointer to MediaTypeDefinition or as an identifier. The DSL lists the
+// downstream calls using Fetch. The downstream actions may be defined by the same API or by a
+// service declared with Uses.
+//
+// The generated code includes a struct with one field per branch holding the function that calls
+// the downstream action and a constructor that builds the aggregate, see the aggregate package.
+// The branches run concurrently, each with its own timeout. If a required branch fails the
+// action fails, the failures of optional branches leave the mapped fields empty. Example:
+//
+//	Action("dashboard", func() {
+//		Routing(GET("/:orderID"))
+//		Aggregate(Dashboard, func() {
+//			Fetch("order", "order.show", func() {	// Maps the result to the "order" attribute
+//				Timeout(500 * time.Millisecond)
+//			})
+//			Fetch("bottles", "cellar:bottle.list", func() {
+//				Optional()
+//				Map("", "recommendations")	// Maps the whole result
+//			})
+//		})
+//		Response(OK, Dashboard)
+//	})
+//
+func Aggregate(mediaType interface{}, dsl func()) {
+	if a, ok := actionDefinition(); ok {
+		agg := &design.AggregateDefinition{Parent: a}
+		switch m := mediaType.(type) {
 		case *design.MediaTypeDefinition:
-			att = design.DupAtt(actual.AttributeDefinition)
-		case string:
-			ut, ok := design.Design.Types[actual]
-			if !ok {
-				dslengine.ReportError("unknown payload type %s", actual)
+			if m != nil {
+				agg.MediaType = m.Identifier
 			}
-			att = design.DupAtt(ut.AttributeDefinition)
-		case *design.Array:
-			att = &design.AttributeDefinition{Type: actual}
-		case *design.Hash:
-			att = &design.AttributeDefinition{Type: actual}
-		case design.Primitive:
-			att = &design.AttributeDefinition{Type: actual}
+		case string:
+			agg.MediaType = m
 		default:
-			dslengine.ReportError("invalid Payload argument, must be a type, a media type or a DSL building a type")
+			dslengine.ReportError("media type must be a string or a pointer to MediaTypeDefinition, got %#v", mediaType)
 			return
 		}
-		if len(dsls) == 1 {
-			if dsl != nil {
-				dslengine.ReportError("invalid arguments in Payload call, must be (type), (dsl) or (type, dsl)")
-			}
-			dsl = dsls[0]
+		if !dslengine.Execute(dsl, agg) {
+			return
 		}
-		if dsl != nil {
-			dslengine.Execute(dsl, att)
+		a.Aggregate = agg
+	}
+}
+
+// Fetch can be used in: Aggregate
+//
+// Fetch adds a branch to the aggregate that calls a downstream action. The target has the form
+// "resource.action" for actions of the same API or "service:resource.action" for actions of a
+// service declared with Uses. By default the whole result of the call is mapped to the attribute
+// of the composite media type with the same name as the branch, use Map to change the mapping.
+// The optional DSL may use Timeout, Optional and Map.
+func Fetch(name, target string, dsl ...func()) {
+	agg, ok := aggregateDefinition()
+	if !ok {
+		return
+	}
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Fetch")
+		return
+	}
+	b := &design.AggregateBranchDefinition{Parent: agg, Name: name}
+	if idx := strings.Index(target, ":"); idx >= 0 {
+		b.Service, target = target[:idx], target[idx+1:]
+	}
+	elems := strings.Split(target, ".")
+	if len(elems) != 2 {
+		dslengine.ReportError(`invalid Fetch target %#v, must be of the form "[service:]resource.action"`, target)
+		return
+	}
+	b.Resource, b.Action = elems[0], elems[1]
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], b) {
+			return
+		}
+	}
+	if len(b.Mappings) == 0 {
+		b.Mappings = []*design.AggregateMappingDefinition{{To: name}}
+	}
+	agg.Branches = append(agg.Branches, b)
+}
+
+// Timeout can be used in: Fetch
+//
+// Timeout sets the maximum duration of the downstream call. The call is canceled once the timeout
+// elapses and the branch fails.
+func Timeout(d time.Duration) {
+	if b, ok := aggregateBranchDefinition(); ok {
+		b.Timeout = d
+	}
+}
+
+// Optional can be used in: Fetch
+//
+// Optional indicates that the composite response can be built without the branch result: if the
+// downstream call fails the mapped attributes are left empty instead of failing the action.
+func Optional() {
+	if b, ok := aggregateBranchDefinition(); ok {
+		b.Optional = true
+	}
+}
+
+// Map can be used in: Fetch
+//
+// Map copies the field of the branch result with path from to the attribute of the composite
+// media type with path to. Paths are dot separated attribute names, an empty from denotes the
+// whole result. Map may be called multiple times. Example:
+//
+//	Fetch("bottle", "cellar:bottle.show", func() {
+//		Map("name", "bottle_name")
+//		Map("vineyard.country", "origin.country")
+//	})
+//
+func Map(from, to string) {
+	if b, ok := aggregateBranchDefinition(); ok {
+		b.Mappings = append(b.Mappings, &design.AggregateMappingDefinition{From: from, To: to})
+	}
+}
+
+// Paginates can be used in: Action
+//
+// Paginates splits the collection returned by the action into pages. The style is one of
+// PagePagination or CursorPagination: page pagination adds the "page" and "per_page" query string
+// params, cursor pagination adds the "cursor" and "per_page" params. The OK response gets a Link
+// header (RFC 8288) with the URLs of the neighbouring pages and, with page pagination, a
+// X-Total-Count header with the total number of results. The OK response media type must be a
+// collection, it is flagged as paginated so that the generators emit consistent pagination code
+// across resources: the generated context exposes a Paginate method that sets the headers. The
+// optional DSL may use PageSize. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Paginates(PagePagination, func() {
+//			PageSize(25, 200)
+//		})
+//		Response(OK, CollectionOf(BottleMedia))
+//	})
+//
+func Paginates(style string, dsl ...func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Paginates")
+		return
+	}
+	if a.Pagination != nil {
+		dslengine.ReportError("Paginates may only be used once per action")
+		return
+	}
+	p := &design.PaginationDefinition{
+		Parent:          a,
+		Style:           style,
+		DefaultPageSize: design.DefaultPageSize,
+		MaxPageSize:     design.DefaultMaxPageSize,
+	}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], p) {
+			return
+		}
+	}
+	a.Pagination = p
+	a.Params = a.Params.Merge(paginationParams(p))
+}
+
+// PageSize can be used in: Paginates
+//
+// PageSize sets the number of results per page used when requests do not specify the "per_page"
+// param and the maximum value accepted for that param. The default is 20 results per page and
+// at most 100.
+func PageSize(defaultSize, maxSize int) {
+	if p, ok := paginationDefinition(); ok {
+		p.DefaultPageSize = defaultSize
+		p.MaxPageSize = maxSize
+	}
+}
+
+// Subscription can be used in: Action
+//
+// Subscription declares a subscription variant of the enclosing collection action: a sibling action
+// with the given name that streams the creations, updates and deletions made to the collection as
+// server-sent events so that clients can keep local caches in sync. The subscription inherits the
+// params of the collection action and responds with a stream of deltas: each event is a typed
+// envelope holding the operation ("create", "update" or "delete"), the identifier of the element
+// media type and the element rendered with the view of the collection response. The ID of each
+// event is a resume token, clients that reconnect send the last token they received in the
+// Last-Event-ID header so that the handler can replay the changes they missed. The DSL defines
+// the subscription routes and may use any action DSL except Response. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Response(OK, CollectionOf(BottleMedia))
+//		Subscription("watch", func() {
+//			Routing(GET("/changes"))
+//		})
+//	})
+//
+func Subscription(name string, dsl func()) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	r := a.Parent
+	if r.Actions == nil {
+		r.Actions = make(map[string]*design.ActionDefinition)
+	}
+	if _, ok := r.Actions[name]; ok || name == a.Name {
+		dslengine.ReportError("action %#v already defined in resource %#v", name, r.Name)
+		return
+	}
+	sub := &design.ActionDefinition{
+		Parent:   r,
+		Name:     name,
+		Metadata: make(dslengine.MetadataDefinition),
+	}
+	sub.Subscription = &design.SubscriptionDefinition{Parent: sub, Source: a}
+	if !dslengine.Execute(dsl, sub) {
+		return
+	}
+	r.Actions[name] = sub
+}
+
+// paginationParams returns the query string params that select the page.
+func paginationParams(p *design.PaginationDefinition) *design.AttributeDefinition {
+	minSize, maxSize, minPage := 1.0, float64(p.MaxPageSize), 1.0
+	params := design.Object{
+		"per_page": &design.AttributeDefinition{
+			Type:         design.Integer,
+			Description:  "Number of results per page",
+			DefaultValue: p.DefaultPageSize,
+			Validation:   &dslengine.ValidationDefinition{Minimum: &minSize, Maximum: &maxSize},
+		},
+	}
+	switch p.Style {
+	case design.PagePagination:
+		params["page"] = &design.AttributeDefinition{
+			Type:         design.Integer,
+			Description:  "Page number, starting at 1",
+			DefaultValue: 1,
+			Validation:   &dslengine.ValidationDefinition{Minimum: &minPage},
 		}
-		rn := camelize(a.Parent.Name)
-		an := camelize(a.Name)
-		a.Payload = &design.UserTypeDefinition{
-			AttributeDefinition: att,
-			TypeName:            fmt.Sprintf("%s%sPayload", an, rn),
+	case design.CursorPagination:
+		params["cursor"] = &design.AttributeDefinition{
+			Type:        design.String,
+			Description: "Opaque cursor identifying the first result of the page",
 		}
-		a.PayloadOptional = isOptional
 	}
+	return &design.AttributeDefinition{Type: params}
 }
 
 // newAttribute creates a new attribute definition using the media type with the given identifier
@@ -2,6 +2,7 @@ package apidsl_test
 
 import (
 	"strconv"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -94,6 +95,936 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with a SLO", func() {
+		var latency string
+		var availability float64
+
+		BeforeEach(func() {
+			name = "foo"
+			latency = "200ms"
+			availability = 99.9
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(GET("/:id"))
+					SLO(latency, availability)
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action service level objectives", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.SLO).ShouldNot(BeNil())
+			Ω(action.SLO.LatencyP99).Should(Equal(200 * time.Millisecond))
+			Ω(action.SLO.Availability).Should(Equal(99.9))
+		})
+
+		Context("with an invalid latency", func() {
+			BeforeEach(func() {
+				latency = "foo"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with an invalid availability", func() {
+			BeforeEach(func() {
+				availability = 120
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a maximum concurrency", func() {
+		var queueTimeout string
+		var limit int
+
+		BeforeEach(func() {
+			name = "foo"
+			limit = 4
+			queueTimeout = "10s"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(POST("/"))
+					MaxConcurrency(limit)
+					ConcurrencyQueue(20, queueTimeout)
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action concurrency limit and queue", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Concurrency).Should(Equal(&ConcurrencyDefinition{
+				MaxInFlight:  4,
+				QueueSize:    20,
+				QueueTimeout: 10 * time.Second,
+			}))
+		})
+
+		Context("with an invalid queue timeout", func() {
+			BeforeEach(func() {
+				queueTimeout = "foo"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with an invalid limit", func() {
+			BeforeEach(func() {
+				limit = 0
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with optimistic concurrency control", func() {
+		var versionAttribute string
+
+		BeforeEach(func() {
+			name = "foo"
+			versionAttribute = "version"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			mt := MediaType("application/vnd.versioned", func() {
+				Attributes(func() {
+					Attribute("version", String)
+				})
+				View("default", func() {
+					Attribute("version")
+				})
+			})
+			Resource("res", func() {
+				DefaultMedia(mt)
+				Action(name, func() {
+					Routing(PUT("/:id"))
+					OptimisticConcurrency(versionAttribute)
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action version attribute", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.VersionAttribute).Should(Equal("version"))
+		})
+
+		Context("with an unknown version attribute", func() {
+			BeforeEach(func() {
+				versionAttribute = "etag"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a saga", func() {
+		var steps func()
+
+		BeforeEach(func() {
+			name = "foo"
+			steps = func() {
+				Step("reserve", "Reserve the items")
+				Step("charge")
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(POST(""))
+					Saga("place_order", steps)
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action saga", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Saga).ShouldNot(BeNil())
+			Ω(action.Saga.Name).Should(Equal("place_order"))
+			Ω(action.Saga.Steps).Should(HaveLen(2))
+			Ω(action.Saga.Steps[0].Name).Should(Equal("reserve"))
+			Ω(action.Saga.Steps[0].Description).Should(Equal("Reserve the items"))
+			Ω(action.Saga.Steps[1].Name).Should(Equal("charge"))
+		})
+
+		Context("with duplicate steps", func() {
+			BeforeEach(func() {
+				steps = func() {
+					Step("charge")
+					Step("charge")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a websocket", func() {
+		var wsDSL func()
+		var schemes []string
+		var withPayload bool
+
+		BeforeEach(func() {
+			name = "chat"
+			schemes = nil
+			withPayload = false
+			wsDSL = func() {
+				Inbound(func() {
+					Attribute("text", String, func() {
+						MaxLength(280)
+					})
+					Required("text")
+				})
+				Outbound("application/vnd.chat.event")
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.chat.event", func() {
+				Attributes(func() {
+					Attribute("text", String)
+				})
+				View("default", func() {
+					Attribute("text")
+				})
+			})
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(GET("/chat"))
+					if schemes != nil {
+						Scheme(schemes...)
+					}
+					if withPayload {
+						Payload(func() {
+							Attribute("text", String)
+						})
+					}
+					WebSocket(wsDSL)
+					Response(SwitchingProtocols)
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action messages and defaults the scheme to ws", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.WebSocket()).Should(BeTrue())
+			Ω(action.Schemes).Should(Equal([]string{"ws"}))
+			Ω(action.Messages).ShouldNot(BeNil())
+			Ω(action.Messages.Inbound).ShouldNot(BeNil())
+			Ω(action.Messages.Inbound.TypeName).Should(Equal("ChatResInboundMessage"))
+			Ω(action.Messages.Inbound.IsRequired("text")).Should(BeTrue())
+			Ω(action.Messages.Outbound).Should(Equal("application/vnd.chat.event"))
+			Ω(action.Messages.OutboundView).Should(Equal("default"))
+			Ω(action.Messages.OutboundMediaType()).ShouldNot(BeNil())
+		})
+
+		Context("with a http scheme", func() {
+			BeforeEach(func() {
+				schemes = []string{"https"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`schemes are not "ws" or "wss"`))
+			})
+		})
+
+		Context("with a payload", func() {
+			BeforeEach(func() {
+				withPayload = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("websocket actions cannot have a payload"))
+			})
+		})
+
+		Context("with no messages", func() {
+			BeforeEach(func() {
+				wsDSL = func() {}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("at least one of Inbound or Outbound"))
+			})
+		})
+
+		Context("with an unknown outbound view", func() {
+			BeforeEach(func() {
+				wsDSL = func() {
+					Outbound("application/vnd.chat.event", "tiny")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`does not define view "tiny"`))
+			})
+		})
+
+		Context("with an unknown outbound media type", func() {
+			BeforeEach(func() {
+				wsDSL = func() {
+					Outbound("application/vnd.unknown")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("unknown outbound message media type"))
+			})
+		})
+
+		Context("with an invalid inbound message", func() {
+			BeforeEach(func() {
+				wsDSL = func() {
+					Inbound(func() {
+						Attribute("text", String)
+						Required("foo")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a deep object param", func() {
+		var route, filterDSL func()
+
+		BeforeEach(func() {
+			name = "foo"
+			route = func() { Routing(GET("/")) }
+			filterDSL = func() {
+				Attribute("status", String)
+				Attribute("tags", ArrayOf(String))
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			filter := Type("Filter", filterDSL)
+			Resource("res", func() {
+				Action(name, func() {
+					route()
+					Params(func() {
+						Param("filter", filter)
+					})
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("produces a valid action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.QueryParams.Type.ToObject()["filter"].IsDeepObject()).Should(BeTrue())
+		})
+
+		Context("used in the request path", func() {
+			BeforeEach(func() {
+				route = func() { Routing(GET("/:filter")) }
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("deep object"))
+			})
+		})
+
+		Context("with nested object attributes", func() {
+			BeforeEach(func() {
+				filterDSL = func() {
+					Attribute("owner", func() {
+						Attribute("name", String)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid type"))
+			})
+		})
+	})
+
+	Context("with geo params", func() {
+		BeforeEach(func() {
+			name = "list"
+			dsl = func() {
+				Routing(GET("/"))
+				Params(func() {
+					Param("limit", Integer)
+				})
+				Near()
+				Within("bbox")
+			}
+		})
+
+		It("adds the deep object params", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			params := action.QueryParams.Type.ToObject()
+			Ω(params).Should(HaveKey("limit"))
+			Ω(params).Should(HaveKey("near"))
+			Ω(params["near"].Type).Should(Equal(Proximity))
+			Ω(params["near"].IsDeepObject()).Should(BeTrue())
+			Ω(params).Should(HaveKey("bbox"))
+			Ω(params["bbox"].Type).Should(Equal(BoundingBox))
+		})
+
+		Context("with too many arguments", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/"))
+					Near("a", "b")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Near: too many arguments"))
+			})
+		})
+	})
+
+	Context("with route aliases", func() {
+		var aliases func()
+
+		BeforeEach(func() {
+			name = "foo"
+			aliases = func() {
+				Alias("/legacy/:id")
+				Alias("/old/:id", AliasRedirect)
+				Alias("/v0", AliasGone)
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				BasePath("/res")
+				Action(name, func() {
+					Routing(GET("/:id", aliases))
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the route aliases", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			aliases := action.Routes[0].Aliases
+			Ω(aliases).Should(HaveLen(3))
+			Ω(aliases[0].Policy).Should(Equal(AliasServe))
+			Ω(aliases[0].FullPath()).Should(Equal("/res/legacy/:id"))
+			Ω(aliases[1].Policy).Should(Equal(AliasRedirect))
+			Ω(aliases[2].Policy).Should(Equal(AliasGone))
+		})
+
+		Context("with an alias missing a wildcard", func() {
+			BeforeEach(func() {
+				aliases = func() {
+					Alias("/legacy", AliasRedirect)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with an alias conflicting with a route", func() {
+			BeforeEach(func() {
+				aliases = func() {
+					Alias("/:id")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("conflicts with route GET"))
+			})
+		})
+	})
+
+	Context("with an array param using a collection format", func() {
+		var format string
+		var paramType DataType
+
+		BeforeEach(func() {
+			name = "list"
+			format = "csv"
+			paramType = ArrayOf(Integer)
+			dsl = func() {
+				Routing(GET(""))
+				Params(func() {
+					Param("ids", paramType, func() {
+						CollectionFormat(format)
+					})
+				})
+			}
+		})
+
+		It("sets the collection format", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			ids := action.Params.Type.ToObject()["ids"]
+			Ω(ids.CollectionFormat).Should(Equal(CollectionFormatCSV))
+			Ω(ids.CollectionSeparator()).Should(Equal(","))
+		})
+
+		Context("with an unknown format", func() {
+			BeforeEach(func() {
+				format = "tsv"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid collection format "tsv"`))
+			})
+		})
+
+		Context("with a param that is not an array", func() {
+			BeforeEach(func() {
+				paramType = String
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("defines a collection format but is not an array"))
+			})
+		})
+	})
+
+	Context("with an optional trailing path segment", func() {
+		var path string
+		var required bool
+
+		BeforeEach(func() {
+			name = "show"
+			path = "/:id(/versions/:version)?"
+			required = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("items", func() {
+				BasePath("/items")
+				Action(name, func() {
+					Routing(GET(path))
+					Params(func() {
+						Param("id", Integer)
+						Param("version", Integer)
+						if required {
+							Required("version")
+						}
+					})
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["items"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("defines a route with and without the segment", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Routes).Should(HaveLen(2))
+			Ω(action.Routes[0].FullPath()).Should(Equal("/items/:id"))
+			Ω(action.Routes[0].Optional).Should(BeEmpty())
+			Ω(action.Routes[1].FullPath()).Should(Equal("/items/:id/versions/:version"))
+			Ω(action.Routes[1].Optional).Should(Equal([]string{"version"}))
+		})
+
+		It("makes the segment params optional", func() {
+			Ω(action.OptionalPathParams()).Should(Equal([]string{"version"}))
+			Ω(action.Params.IsPrimitivePointer("id")).Should(BeFalse())
+			Ω(action.Params.IsPrimitivePointer("version")).Should(BeTrue())
+			Ω(action.QueryParams.Type.ToObject()).ShouldNot(HaveKey("version"))
+		})
+
+		Context("with a required segment param", func() {
+			BeforeEach(func() {
+				required = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`param "version" of optional path segment cannot be required`))
+			})
+		})
+
+		Context("with an optional segment that is not last", func() {
+			BeforeEach(func() {
+				path = "/:id(/versions/:version)?/raw"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("only the trailing segment may be optional"))
+			})
+		})
+	})
+
+	Context("with an aggregate", func() {
+		var branches func()
+
+		BeforeEach(func() {
+			name = "foo"
+			branches = func() {
+				Fetch("order", "res.show", func() {
+					Timeout(time.Second)
+				})
+				Fetch("bottles", "cellar:bottle.list", func() {
+					Optional()
+					Map("items", "recommendations")
+				})
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				Uses("cellar", "bottle")
+			})
+			dashboard := MediaType("application/vnd.dashboard+json", func() {
+				Attributes(func() {
+					Attribute("order", Any)
+					Attribute("recommendations", ArrayOf(Any))
+				})
+				View("default", func() {
+					Attribute("order")
+					Attribute("recommendations")
+				})
+			})
+			Resource("res", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+				})
+				Action(name, func() {
+					Routing(GET("/dashboard/:id"))
+					Aggregate(dashboard, branches)
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action aggregate", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Aggregate).ShouldNot(BeNil())
+			Ω(action.Aggregate.MediaType).Should(Equal("application/vnd.dashboard+json"))
+			Ω(action.Aggregate.Branches).Should(HaveLen(2))
+			order, bottles := action.Aggregate.Branches[0], action.Aggregate.Branches[1]
+			Ω(order.Service).Should(BeEmpty())
+			Ω(order.Resource).Should(Equal("res"))
+			Ω(order.Action).Should(Equal("show"))
+			Ω(order.Timeout).Should(Equal(time.Second))
+			Ω(order.Mappings).Should(Equal([]*AggregateMappingDefinition{{To: "order"}}))
+			Ω(bottles.Service).Should(Equal("cellar"))
+			Ω(bottles.Optional).Should(BeTrue())
+			Ω(bottles.Mappings).Should(Equal([]*AggregateMappingDefinition{{From: "items", To: "recommendations"}}))
+		})
+
+		Context("with invalid branches", func() {
+			BeforeEach(func() {
+				branches = func() {
+					Fetch("order", "res.delete")
+					Fetch("bottles", "cellar:vineyard.list")
+					Fetch("reviews", "res.show", func() {
+						Map("", "reviews")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				msg := dslengine.Errors.Error()
+				Ω(msg).Should(ContainSubstring(`unknown action "delete" of resource "res"`))
+				Ω(msg).Should(ContainSubstring(`resource "vineyard" of service "cellar" is not declared with Uses`))
+				Ω(msg).Should(ContainSubstring(`has no attribute "reviews"`))
+			})
+		})
+	})
+
+	Context("with enrichments", func() {
+		var enrichments func()
+
+		BeforeEach(func() {
+			name = "foo"
+			enrichments = func() {
+				Enrich("owner", "ownerID", func() {
+					Description("The owner account")
+					CacheFor(time.Minute)
+				})
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+						Param("ownerID", Integer)
+					})
+					enrichments()
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions[name]
+			}
+		})
+
+		It("sets the action enrichments", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Enrichments).Should(HaveLen(1))
+			e := action.Enrichments[0]
+			Ω(e.Name).Should(Equal("owner"))
+			Ω(e.Param).Should(Equal("ownerID"))
+			Ω(e.Description).Should(Equal("The owner account"))
+			Ω(e.TTL).Should(Equal(time.Minute))
+		})
+
+		Context("using an unknown param", func() {
+			BeforeEach(func() {
+				enrichments = func() {
+					Enrich("owner", "userID")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown param "userID"`))
+			})
+		})
+
+		Context("with a name clashing with a param", func() {
+			BeforeEach(func() {
+				enrichments = func() {
+					Enrich("id", "ownerID")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`name clashes with param "id"`))
+			})
+		})
+	})
+
+	Context("with cookies", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Cookies(func() {
+						Cookie("session", String, "Session ID")
+						Cookie("csrf_token")
+						Required("session")
+					})
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions["show"]
+			}
+		})
+
+		It("sets the action cookies", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Cookies).ShouldNot(BeNil())
+			cookies := action.Cookies.Type.ToObject()
+			Ω(cookies).Should(HaveLen(2))
+			Ω(cookies["session"].Type).Should(Equal(String))
+			Ω(cookies["session"].Description).Should(Equal("Session ID"))
+			Ω(cookies["csrf_token"].Type).Should(Equal(String))
+			Ω(action.Cookies.IsRequired("session")).Should(BeTrue())
+		})
+	})
+
+	Context("with middleware and required context values", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				ContextValue("user", String, "The authenticated user")
+			})
+			Resource("res", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Middleware("auth", func() {
+						Description("Loads the authenticated user")
+						Provides("user")
+					})
+					RequiresContext("user")
+				})
+			})
+			dslengine.Run()
+			if r, ok := Design.Resources["res"]; ok {
+				action = r.Actions["show"]
+			}
+		})
+
+		It("sets the action middleware and required context values", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.ContextValues).Should(HaveKey("user"))
+			Ω(Design.ContextValues["user"].Type).Should(Equal(String))
+			Ω(Design.ContextValues["user"].Description).Should(Equal("The authenticated user"))
+			Ω(action.Middleware).Should(HaveLen(1))
+			m := action.Middleware[0]
+			Ω(m.Name).Should(Equal("auth"))
+			Ω(m.Description).Should(Equal("Loads the authenticated user"))
+			Ω(m.Provides).Should(Equal([]string{"user"}))
+			Ω(m.Parent).Should(Equal(action))
+			Ω(action.RequiredContext).Should(Equal([]string{"user"}))
+		})
+	})
+
+	Context("with a multipart form payload", func() {
+		var multipart bool
+		var payloadDSL func()
+
+		BeforeEach(func() {
+			name = "upload"
+			multipart = true
+			payloadDSL = func() {
+				Attribute("title", String)
+				Attribute("document", File, func() {
+					MaxLength(1024)
+				})
+				Attribute("attachments", ArrayOf(File))
+				Required("document")
+			}
+			dsl = func() {
+				Routing(POST("/documents"))
+				if multipart {
+					MultipartForm()
+				}
+				Payload(func() { payloadDSL() })
+			}
+		})
+
+		It("produces a valid action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.PayloadMultipart).Should(BeTrue())
+			doc := action.Payload.ToObject()["document"]
+			Ω(doc.Type).Should(Equal(File))
+			Ω(*doc.Validation.MaxLength).Should(Equal(1024))
+		})
+
+		Context("without MultipartForm", func() {
+			BeforeEach(func() {
+				multipart = false
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("payload.attachments[] is a File, actions whose payload contains files must use MultipartForm"))
+			})
+		})
+
+		Context("with a nested file", func() {
+			BeforeEach(func() {
+				payloadDSL = func() {
+					Attribute("meta", func() {
+						Attribute("thumbnail", File)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("payload.meta.thumbnail is a File, files must be top level attributes"))
+			})
+		})
+
+		Context("with a file param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(POST("/documents"))
+					Params(func() {
+						Param("document", File)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("document is a File, File attributes can only be used in the payloads of actions that use MultipartForm"))
+			})
+		})
+
+		Context("with no payload", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(POST("/documents"))
+					MultipartForm()
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("MultipartForm used on action with no payload"))
+			})
+		})
+	})
+
 	Context("with a string payload", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -363,4 +1294,388 @@ var _ = Describe("Payload", func() {
 		})
 	})
 
+	Context("with a hash whose key is not a primitive", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET(""))
+					Payload(HashOf(ArrayOf(String), Integer))
+				})
+			})
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Run()
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("hash key type must be a primitive, got array"))
+		})
+	})
+
+})
+
+var _ = Describe("Streaming", func() {
+	var payload interface{}
+	var multipart bool
+	var single bool
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		payload = ArrayOf(String)
+		multipart = false
+		single = false
+	})
+
+	JustBeforeEach(func() {
+		record := MediaType("application/vnd.record", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("res", func() {
+			Action("import", func() {
+				Routing(POST("/import"))
+				Payload(payload)
+				if multipart {
+					MultipartForm()
+				}
+				Streaming()
+				var mt *MediaTypeDefinition = CollectionOf(record)
+				if single {
+					mt = record
+				}
+				Response(OK, mt, func() {
+					Streaming()
+				})
+			})
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["res"]; ok {
+			action = r.Actions["import"]
+		}
+	})
+
+	It("streams the payload and the response", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action.PayloadStreaming).Should(BeTrue())
+		Ω(action.Responses[OK].Chunked).Should(BeTrue())
+		Ω(action.Responses[OK].Streaming).Should(BeFalse())
+	})
+
+	Context("with a payload that is not a collection", func() {
+		BeforeEach(func() {
+			payload = String
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("streaming payload must be a collection, got string"))
+		})
+	})
+
+	Context("with a multipart payload", func() {
+		BeforeEach(func() {
+			multipart = true
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("streaming payload cannot be encoded as a multipart form"))
+		})
+	})
+
+	Context("with a response that is not a collection", func() {
+		BeforeEach(func() {
+			single = true
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`streaming response media type "application/vnd.record" must be a collection`))
+		})
+	})
+
+	Context("with a response that overrides the media type with a type", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action("import", func() {
+					Routing(POST("/import"))
+					Response(OK, ArrayOf(String), func() {
+						Streaming()
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("streaming response must have exactly one media type"))
+		})
+	})
+})
+
+var _ = Describe("Paginates", func() {
+	var style string
+	var dsl func()
+	var single bool
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		style = PagePagination
+		dsl = nil
+		single = false
+	})
+
+	JustBeforeEach(func() {
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET("/bottles"))
+				if dsl != nil {
+					Paginates(style, dsl)
+				} else {
+					Paginates(style)
+				}
+				var mt *MediaTypeDefinition = CollectionOf(bottle)
+				if single {
+					mt = bottle
+				}
+				Response(OK, mt)
+			})
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["bottle"]; ok {
+			action = r.Actions["list"]
+		}
+	})
+
+	It("adds the page params and the pagination headers", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action.Pagination).ShouldNot(BeNil())
+		params := action.Params.Type.ToObject()
+		Ω(params).Should(HaveKey("page"))
+		Ω(params).Should(HaveKey("per_page"))
+		Ω(params["per_page"].DefaultValue).Should(Equal(DefaultPageSize))
+		Ω(*params["per_page"].Validation.Maximum).Should(Equal(float64(DefaultMaxPageSize)))
+		Ω(action.QueryParams.Type.ToObject()).Should(HaveKey("page"))
+		headers := action.Responses[OK].Headers.Type.ToObject()
+		Ω(headers).Should(HaveKey("Link"))
+		Ω(headers).Should(HaveKey("X-Total-Count"))
+		Ω(Design.MediaTypeWithIdentifier(action.Responses[OK].MediaType).Paginated).Should(BeTrue())
+	})
+
+	Context("with cursor pagination and custom page sizes", func() {
+		BeforeEach(func() {
+			style = CursorPagination
+			dsl = func() {
+				PageSize(10, 50)
+			}
+		})
+
+		It("adds the cursor params and the Link header", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			params := action.Params.Type.ToObject()
+			Ω(params).Should(HaveKey("cursor"))
+			Ω(params).ShouldNot(HaveKey("page"))
+			Ω(params["per_page"].DefaultValue).Should(Equal(10))
+			Ω(*params["per_page"].Validation.Maximum).Should(Equal(50.0))
+			headers := action.Responses[OK].Headers.Type.ToObject()
+			Ω(headers).Should(HaveKey("Link"))
+			Ω(headers).ShouldNot(HaveKey("X-Total-Count"))
+		})
+	})
+
+	Context("with an unknown style", func() {
+		BeforeEach(func() {
+			style = "offset"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid pagination style "offset"`))
+		})
+	})
+
+	Context("with a default page size greater than the maximum", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				PageSize(50, 10)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("maximum page size 10 cannot be less than the default page size 50"))
+		})
+	})
+
+	Context("with a response that is not a collection", func() {
+		BeforeEach(func() {
+			single = true
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("paginated actions must define an OK response whose media type is a collection"))
+		})
+	})
+})
+
+var _ = Describe("RateLimit", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {
+			RateLimit(1000, time.Minute)
+		})
+		Resource("bottle", func() {
+			RateLimit(100, time.Minute)
+			Action("show", func() {
+				Routing(GET("/:id"))
+			})
+			Action("export", func() {
+				Routing(POST("/exports"))
+				RateLimit(5, time.Hour)
+			})
+		})
+		Resource("account", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("records the rate limits", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		bottle := Design.Resources["bottle"]
+		Ω(bottle.RateLimit).Should(Equal(&RateLimitDefinition{Requests: 100, Period: time.Minute}))
+		Ω(bottle.Actions["export"].RateLimit).Should(Equal(&RateLimitDefinition{Requests: 5, Period: time.Hour}))
+		Ω(bottle.Actions["show"].RateLimit).Should(BeNil())
+	})
+
+	It("computes the rate limit that applies to each action", func() {
+		Ω(Design.Resources["bottle"].Actions["export"].EffectiveRateLimit().Requests).Should(Equal(5))
+		Ω(Design.Resources["bottle"].Actions["show"].EffectiveRateLimit().Requests).Should(Equal(100))
+		Ω(Design.Resources["account"].Actions["show"].EffectiveRateLimit().Requests).Should(Equal(1000))
+	})
+})
+
+var _ = Describe("Subscription", func() {
+	var name string
+	var dsl func()
+	var single bool
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		name = "watch"
+		dsl = nil
+		single = false
+	})
+
+	JustBeforeEach(func() {
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+			View("tiny", func() {
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET("/bottles"))
+				Params(func() {
+					Param("vintage", Integer)
+				})
+				var mt *MediaTypeDefinition = CollectionOf(bottle)
+				if single {
+					mt = bottle
+				}
+				Response(OK, func() {
+					Media(mt, "tiny")
+				})
+				Subscription(name, func() {
+					Routing(GET("/bottles/changes"))
+					if dsl != nil {
+						dsl()
+					}
+				})
+			})
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["bottle"]; ok {
+			action = r.Actions["watch"]
+		}
+	})
+
+	It("adds a subscription action streaming the collection deltas", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action).ShouldNot(BeNil())
+		Ω(action.Subscription.Source.Name).Should(Equal("list"))
+		Ω(action.Params.Type.ToObject()).Should(HaveKey("vintage"))
+		Ω(action.QueryParams.Type.ToObject()).Should(HaveKey("vintage"))
+		resp := action.Responses[OK]
+		Ω(resp).ShouldNot(BeNil())
+		Ω(resp.Streaming).Should(BeTrue())
+		Ω(resp.Deltas).Should(BeTrue())
+		Ω(resp.MediaType).Should(Equal("application/vnd.bottle"))
+		Ω(resp.ViewName).Should(Equal("tiny"))
+	})
+
+	Context("with a source action that does not return a collection", func() {
+		BeforeEach(func() {
+			single = true
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("collection of media types"))
+		})
+	})
+
+	Context("with a subscription defining an OK response", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Response(OK)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot define an OK response"))
+		})
+	})
+
+	Context("with a name already used by an action", func() {
+		BeforeEach(func() {
+			name = "list"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("already defined"))
+		})
+	})
 })
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -70,6 +71,16 @@ import (
 //				Required("header")
 //			})
 //		})
+//		RateLimit(100, time.Minute)		// Default rate limit
+//		Feature("search", false)		// Feature flag and default state
+//		Overlay("production", func() {		// Environment specific overrides
+//			Host("api.goa.design")
+//			Scheme("https")
+//			RateLimit(1000, time.Minute)
+//			Feature("search", true)
+//			Disable("debug")
+//		})
+//		Uses("cellar", "bottle")		// Resources of other services called by this service
 //	}
 //
 func API(name string, dsl func()) *design.APIDefinition {
@@ -90,13 +101,86 @@ func API(name string, dsl func()) *design.APIDefinition {
 	return design.Design
 }
 
-// Version can be used in: API
+// Version can be used in: API, Resource, MediaType
+//
+// Version specifies the API version when used in API with no DSL.
+//
+// Version defines one of the API versions described by the design when used in API with a DSL.
+// The DSL defines how requests select the version: using a path prefix with BasePath, a header
+// with Header or a query string parameter with Query. Requests that use a header or a query
+// string parameter set it to the version name.
+//
+// Version lists the versions that expose the resource or in which the media type is available
+// when used in Resource or MediaType. Resources and media types that do not list versions are part
+// of all versions.
+//
+// The generated controllers mount the actions under the path prefix of each version that exposes
+// the resource in addition to their regular paths and respond with 404 Not Found to requests that
+// select a version that does not expose the resource with a header or a query string parameter.
+// The generated Swagger specification lists the versions in the "x-versions" extensions. Example:
+//
+//	API("cellar", func() {
+//		Version("v1", func() {
+//			Description("Initial version")
+//			BasePath("/v1")
+//		})
+//		Version("v2", func() {
+//			Header("X-Api-Version")
+//		})
+//	})
 //
-// Version specifies the API version. One design describes one version.
-func Version(ver string) {
-	if api, ok := apiDefinition(); ok {
-		api.Version = ver
+//	Resource("account", func() {
+//		Version("v2")	// Only exposed by version v2
+//	})
+//
+func Version(ver string, dsl ...func()) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		if len(dsl) == 0 {
+			def.Version = ver
+			return
+		}
+		if ver == "" {
+			dslengine.ReportError("version name cannot be empty")
+			return
+		}
+		if _, ok := def.Versions[ver]; ok {
+			dslengine.ReportError("multiple definitions for version %s", ver)
+			return
+		}
+		v := &design.VersionDefinition{Name: ver, Parent: def}
+		if !dslengine.Execute(dsl[0], v) {
+			return
+		}
+		if def.Versions == nil {
+			def.Versions = make(map[string]*design.VersionDefinition)
+		}
+		def.Versions[ver] = v
+	case *design.ResourceDefinition:
+		if len(dsl) > 0 {
+			dslengine.ReportError("version DSL can only be used in API")
+			return
+		}
+		def.Versions = append(def.Versions, ver)
+	case *design.MediaTypeDefinition:
+		if len(dsl) > 0 {
+			dslengine.ReportError("version DSL can only be used in API")
+			return
+		}
+		def.Versions = append(def.Versions, ver)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// versionScheme sets the way requests select the version being defined.
+func versionScheme(v *design.VersionDefinition, scheme design.VersionScheme, key string) {
+	if v.Scheme != "" {
+		dslengine.ReportError("version %s selection previously defined with %s", v.Name, v.Scheme)
+		return
 	}
+	v.Scheme = scheme
+	v.Key = key
 }
 
 // Description can be used in: API, Resource, Action, or MediaType
@@ -116,26 +200,39 @@ func Description(d string) {
 		def.Description = d
 	case *design.AttributeDefinition:
 		def.Description = d
+	case *design.UserTypeDefinition:
+		def.Description = d
 	case *design.ResponseDefinition:
 		def.Description = d
 	case *design.DocsDefinition:
 		def.Description = d
 	case *design.SecuritySchemeDefinition:
 		def.Description = d
+	case *design.OverlayDefinition:
+		def.Description = d
+	case *design.VersionDefinition:
+		def.Description = d
+	case *design.EnrichmentDefinition:
+		def.Description = d
+	case *design.MiddlewareDefinition:
+		def.Description = d
 	default:
 		dslengine.IncompatibleDSL()
 	}
 }
 
-// BasePath can used in: API, Resource
+// BasePath can used in: API, Resource, Version
 //
 // BasePath defines the API base path, i.e. the common path prefix to all the API actions.
 // The path may define wildcards (see Routing for a description of the wildcard syntax).
-// The corresponding parameters must be described using Params.
+// The corresponding parameters must be described using Params. Used in Version, BasePath defines
+// the path prefix that requests use to select the version.
 func BasePath(val string) {
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
 		def.BasePath = val
+	case *design.VersionDefinition:
+		versionScheme(def, design.VersionPath, val)
 	case *design.ResourceDefinition:
 		def.BasePath = val
 		if !strings.HasPrefix(val, "//") {
@@ -251,7 +348,7 @@ func TermsOfService(terms string) {
 // Regular expression used to validate RFC1035 hostnames*/
 var hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
 
-// Host used in: API
+// Host used in: API, Overlay
 //
 // Host sets the API hostname.
 func Host(host string) {
@@ -260,12 +357,17 @@ func Host(host string) {
 		return
 	}
 
-	if a, ok := apiDefinition(); ok {
-		a.Host = host
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Host = host
+	case *design.OverlayDefinition:
+		def.Host = host
+	default:
+		dslengine.IncompatibleDSL()
 	}
 }
 
-// Scheme can be used in: API, Resource, Action
+// Scheme can be used in: API, Resource, Action, Overlay
 //
 // Scheme sets the API URL schemes.
 func Scheme(vals ...string) {
@@ -287,6 +389,8 @@ func Scheme(vals ...string) {
 		def.Schemes = append(def.Schemes, vals...)
 	case *design.ActionDefinition:
 		def.Schemes = append(def.Schemes, vals...)
+	case *design.OverlayDefinition:
+		def.Schemes = append(def.Schemes, vals...)
 	default:
 		dslengine.IncompatibleDSL()
 	}
@@ -317,25 +421,59 @@ func License(dsl func()) {
 	}
 }
 
-// Docs can be used in: API, Action, Files
+// Docs can be used in: API, Action, Files, Attribute
 //
-// Docs provides external documentation pointers.
-func Docs(dsl func()) {
-	docs := new(design.DocsDefinition)
-	if !dslengine.Execute(dsl, docs) {
-		return
-	}
-
+// Docs provides external documentation pointers when given a DSL and long form documentation
+// written in markdown when given a string. The markdown complements the short description set
+// with Description: it is appended to the descriptions of the Swagger specification and rendered
+// by the docs generator together with code samples for each generated client. Examples:
+//
+//	Action("show", func() {
+//		Description("Retrieve bottle with given id")
+//		Docs(`Bottles are looked up in the **current account** only, use the
+//	[search](#search) action to look up bottles across accounts.`)
+//		Docs(func() {
+//			Description("Bottle guide")
+//			URL("https://example.com/guides/bottles")
+//		})
+//	})
+//
+//	Attribute("vintage", Integer, func() {
+//		Description("Vintage year")
+//		Docs("The year the grapes were *harvested*.")
+//	})
+func Docs(docs interface{}) {
+	var target **design.DocsDefinition
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
-		def.Docs = docs
+		target = &def.Docs
 	case *design.ActionDefinition:
-		def.Docs = docs
+		target = &def.Docs
 	case *design.FileServerDefinition:
-		def.Docs = docs
+		target = &def.Docs
+	case *design.AttributeDefinition:
+		target = &def.Docs
 	default:
 		dslengine.IncompatibleDSL()
+		return
 	}
+
+	d := *target
+	if d == nil {
+		d = new(design.DocsDefinition)
+	}
+	switch actual := docs.(type) {
+	case string:
+		d.Markdown = actual
+	case func():
+		if !dslengine.Execute(actual, d) {
+			return
+		}
+	default:
+		dslengine.ReportError("invalid Docs argument, must be a markdown string or a DSL function")
+		return
+	}
+	*target = d
 }
 
 // Name can be used in: Contact, License.
@@ -600,10 +738,31 @@ func Trait(name string, val ...func()) {
 	}
 }
 
-// UseTrait can be used in: Resource, Action, Type, MediaType, Attribute
+// UseTrait can be used in: Resource, Action, Type, MediaType, Attribute, Payload
 //
 // UseTrait executes the API trait with the given name. An API level DSL trait must be
-// defined first. UseTrait takes a variable number of trait names.
+// defined first. UseTrait takes a variable number of trait names. Traits make it possible to
+// define common params, headers and responses once and reuse them across actions:
+//
+//	Trait("Paginated", func() {
+//		Attribute("page", Integer, "Page number", func() {
+//			Minimum(1)
+//		})
+//	})
+//
+//	Trait("Traced", func() {
+//		Headers(func() {
+//			Header("X-Request-Id")
+//		})
+//		Response(NotFound)
+//	})
+//
+//	Action("list", func() {
+//		Params(func() {
+//			UseTrait("Paginated")
+//		})
+//		UseTrait("Traced")
+//	})
 func UseTrait(names ...string) {
 	var def dslengine.Definition
 
@@ -616,6 +775,8 @@ func UseTrait(names ...string) {
 		def = typedDef
 	case *design.MediaTypeDefinition:
 		def = typedDef
+	case *design.UserTypeDefinition:
+		def = typedDef
 	default:
 		dslengine.IncompatibleDSL()
 	}
@@ -630,3 +791,255 @@ func UseTrait(names ...string) {
 		}
 	}
 }
+
+// RateLimit can be used in: API, Overlay, Resource, Action
+//
+// RateLimit sets the maximum number of requests allowed during the given period of time. The limit
+// set on a resource overrides the API limit for all the resource actions and the limit set on an
+// action overrides both, see ActionDefinition.EffectiveRateLimit. The limits are part of the API
+// contract: they are exposed to middleware generators and rendered as x-ratelimit extensions in
+// the Swagger specification. Example:
+//
+//	Resource("bottle", func() {
+//		RateLimit(100, time.Minute)
+//		Action("export", func() {
+//			Routing(POST("/exports"))
+//			RateLimit(5, time.Hour)
+//		})
+//	})
+//
+func RateLimit(requests int, per time.Duration) {
+	if requests <= 0 || per <= 0 {
+		dslengine.ReportError("invalid rate limit, the number of requests and the period must be positive")
+		return
+	}
+	limit := &design.RateLimitDefinition{Requests: requests, Period: per}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.RateLimit = limit
+	case *design.OverlayDefinition:
+		def.RateLimit = limit
+	case *design.ResourceDefinition:
+		def.RateLimit = limit
+	case *design.ActionDefinition:
+		def.RateLimit = limit
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Feature can be used in: API, Overlay
+//
+// Feature declares a feature flag and its default state when used in API. When used in Overlay
+// it overrides the state of a feature flag declared in API.
+func Feature(name string, enabled bool) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		if def.Features == nil {
+			def.Features = make(map[string]bool)
+		}
+		def.Features[name] = enabled
+	case *design.OverlayDefinition:
+		if def.Features == nil {
+			def.Features = make(map[string]bool)
+		}
+		def.Features[name] = enabled
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// AttributeNaming can be used in: API
+//
+// AttributeNaming sets the naming convention that the names of the attributes of the API types,
+// media types and payloads must follow, one of design.SnakeCase or design.CamelCase. Validating
+// the design fails if an attribute name does not follow the convention. Example:
+//
+//	API("cellar", func() {
+//		AttributeNaming(design.SnakeCase)
+//	})
+func AttributeNaming(convention design.NamingConvention) {
+	if a, ok := apiDefinition(); ok {
+		a.AttributeNaming = convention
+	}
+}
+
+// WireNaming can be used in: API
+//
+// WireNaming sets the naming convention of the attribute names in request and response bodies,
+// one of design.SnakeCase or design.CamelCase. The names used in the design are converted to the
+// convention in the generated struct field tags, validation errors and JSON schemas while the
+// generated Go field names are derived from the design names as usual. This makes it possible to
+// design attributes in snake_case and serialize them in camelCase for example:
+//
+//	API("cellar", func() {
+//		AttributeNaming(design.SnakeCase)
+//		WireNaming(design.CamelCase)	// Attribute "first_name" is serialized as "firstName"
+//	})
+//
+// Attributes that define the "struct:tag:json" metadata keep the name set in the metadata.
+func WireNaming(convention design.NamingConvention) {
+	if a, ok := apiDefinition(); ok {
+		a.WireNaming = convention
+	}
+}
+
+// FlagsEncoding can be used in: API
+//
+// FlagsEncoding sets the representation of the values of the flags types in request and response
+// bodies, one of design.FlagsArray - the default - or design.FlagsInteger. See Flags.
+//
+//	API("cellar", func() {
+//		FlagsEncoding(design.FlagsInteger)	// Flags values are serialized as integers
+//	})
+func FlagsEncoding(format design.FlagsFormat) {
+	if a, ok := apiDefinition(); ok {
+		a.FlagsFormat = format
+	}
+}
+
+// MoneyEncoding can be used in: API
+//
+// MoneyEncoding sets the representation of the amounts of the values of the built-in Money type
+// in request and response bodies, one of design.MoneyMinorUnits - the default - or
+// design.MoneyDecimal.
+//
+//	API("cellar", func() {
+//		MoneyEncoding(design.MoneyDecimal)	// {"amount": "12.34", "currency": "USD"}
+//	})
+func MoneyEncoding(format design.MoneyFormat) {
+	if a, ok := apiDefinition(); ok {
+		a.MoneyFormat = format
+	}
+}
+
+// Overlay can be used in: API
+//
+// Overlay defines the changes made to the API design for a given environment. The overlay can
+// override the API host, schemes, rate limit and feature flags and disable actions or entire
+// resources. Overlays are merged into the design when generating code with the goagen --env flag
+// or by calling ApplyOverlay on the API definition. Example:
+//
+//	Overlay("staging", func() {
+//		Description("Staging environment")
+//		Host("staging.goa.design")
+//		Scheme("https")
+//		Feature("search", true)
+//		Disable("bottle", "delete")	// Disable the "delete" action of the "bottle" resource
+//		Disable("admin")		// Disable all the "admin" resource actions
+//	})
+func Overlay(env string, dsl func()) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	if env == "" {
+		dslengine.ReportError("overlay environment name cannot be empty")
+		return
+	}
+	if _, ok := a.Overlays[env]; ok {
+		dslengine.ReportError("multiple definitions for overlay %s", env)
+		return
+	}
+	o := &design.OverlayDefinition{Name: env, Parent: a}
+	if !dslengine.Execute(dsl, o) {
+		return
+	}
+	if a.Overlays == nil {
+		a.Overlays = make(map[string]*design.OverlayDefinition)
+	}
+	a.Overlays[env] = o
+}
+
+// ContextValue can be used in: API
+//
+// ContextValue declares a request-scoped value stored in the request context by a middleware,
+// e.g. the authenticated user or the tenant. The value type may be a primitive or a user type.
+// Actions list the values they depend on with RequiresContext and middleware list the values
+// they set with Provides, the design validation checks that each value required by an action is
+// provided by a middleware that applies to it. The generated code includes typed functions that
+// store and retrieve the values, e.g. WithTenant and ContextTenant. Example:
+//
+//	API("cellar", func() {
+//		ContextValue("tenant", String, "The tenant that owns the requested resources")
+//		ContextValue("user", User, "The authenticated user")
+//	})
+func ContextValue(name string, dataType design.DataType, description ...string) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	if len(description) > 1 {
+		dslengine.ReportError("too many arguments given to ContextValue")
+		return
+	}
+	if _, ok := a.ContextValues[name]; ok {
+		dslengine.ReportError("multiple definitions for context value %s", name)
+		return
+	}
+	c := &design.ContextValueDefinition{Parent: a, Name: name, Type: dataType}
+	if len(description) == 1 {
+		c.Description = description[0]
+	}
+	if a.ContextValues == nil {
+		a.ContextValues = make(map[string]*design.ContextValueDefinition)
+	}
+	a.ContextValues[name] = c
+}
+
+// Disable can be used in: Overlay
+//
+// Disable removes the actions with the given names from the resource when the overlay is applied.
+// Disable removes the entire resource if no action name is given.
+func Disable(resource string, actions ...string) {
+	if o, ok := overlayDefinition(); ok {
+		if o.Disabled == nil {
+			o.Disabled = make(map[string][]string)
+		}
+		if len(actions) == 0 {
+			o.Disabled[resource] = nil
+			return
+		}
+		if prev, ok := o.Disabled[resource]; ok && len(prev) == 0 {
+			return
+		}
+		o.Disabled[resource] = append(o.Disabled[resource], actions...)
+	}
+}
+
+// Uses can be used in: API
+//
+// Uses declares that the service calls the API of another service of the same workspace using its
+// generated client. The first argument is the name of the other API, the optional other arguments
+// list the names of the resources that are called, all the resources are called if none is given.
+// "goagen workspace" validates the references and generates the services in dependency order.
+// Example:
+//
+//	var _ = API("orders", func() {
+//		Uses("cellar", "bottle")
+//	})
+func Uses(service string, resources ...string) {
+	if a, ok := apiDefinition(); ok {
+		if service == "" {
+			dslengine.ReportError("service name cannot be empty")
+			return
+		}
+		if a.Uses == nil {
+			a.Uses = make(map[string][]string)
+		}
+		used := a.Uses[service]
+		for _, r := range resources {
+			found := false
+			for _, u := range used {
+				if u == r {
+					found = true
+					break
+				}
+			}
+			if !found {
+				used = append(used, r)
+			}
+		}
+		a.Uses[service] = used
+	}
+}
@@ -1,6 +1,8 @@
 package apidsl_test
 
 import (
+	"time"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -56,6 +58,38 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("using an unknown trait", func() {
+		BeforeEach(func() {
+			name = "foo"
+		})
+
+		It("produces an error", func() {
+			Resource("bottle", func() {
+				UseTrait("Unknown")
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("unknown trait Unknown"))
+		})
+	})
+
+	Context("with a CORS policy authorizing an invalid method", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Origin("https://ui.example.com", func() {
+					Methods("get")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			err := Design.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`invalid method "get"`))
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -360,6 +394,278 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with overlays", func() {
+			var overlay func()
+
+			BeforeEach(func() {
+				overlay = func() {
+					Host("api.goa.design")
+					Scheme("https")
+					RateLimit(1000, time.Minute)
+					Feature("search", true)
+					Disable("bottle", "delete")
+				}
+			})
+
+			JustBeforeEach(func() {
+				API(name, func() {
+					Host("localhost")
+					Scheme("http")
+					RateLimit(10, time.Second)
+					Feature("search", false)
+					Overlay("production", overlay)
+				})
+				Resource("bottle", func() {
+					Action("show", func() {
+						Routing(GET("/:id"))
+					})
+					Action("delete", func() {
+						Routing(DELETE("/:id"))
+					})
+				})
+				dslengine.Run()
+			})
+
+			It("records the overlay", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Overlays).Should(HaveKey("production"))
+				o := Design.Overlays["production"]
+				Ω(o.Host).Should(Equal("api.goa.design"))
+				Ω(o.Schemes).Should(Equal([]string{"https"}))
+				Ω(o.RateLimit).Should(Equal(&RateLimitDefinition{Requests: 1000, Period: time.Minute}))
+				Ω(o.Features).Should(Equal(map[string]bool{"search": true}))
+				Ω(o.Disabled).Should(Equal(map[string][]string{"bottle": {"delete"}}))
+			})
+
+			It("merges the overlay into the design", func() {
+				Ω(Design.ApplyOverlay("production")).ShouldNot(HaveOccurred())
+				Ω(Design.Host).Should(Equal("api.goa.design"))
+				Ω(Design.Schemes).Should(Equal([]string{"https"}))
+				Ω(Design.RateLimit.Requests).Should(Equal(1000))
+				Ω(Design.Features["search"]).Should(BeTrue())
+				Ω(Design.Resources["bottle"].Actions).Should(HaveKey("show"))
+				Ω(Design.Resources["bottle"].Actions).ShouldNot(HaveKey("delete"))
+			})
+
+			It("fails to apply an unknown overlay", func() {
+				Ω(Design.ApplyOverlay("staging")).Should(HaveOccurred())
+			})
+
+			Context("that does not apply to the API", func() {
+				BeforeEach(func() {
+					overlay = func() {
+						Feature("unknown", true)
+						Disable("bottle", "list")
+						Disable("account")
+					}
+				})
+
+				It("produces validation errors", func() {
+					err := Design.Validate()
+					Ω(err).Should(HaveOccurred())
+					Ω(err.Error()).Should(ContainSubstring(`feature "unknown" is not declared by the API`))
+					Ω(err.Error()).Should(ContainSubstring(`cannot disable unknown action "list" of resource "bottle"`))
+					Ω(err.Error()).Should(ContainSubstring(`cannot disable unknown resource "account"`))
+				})
+			})
+		})
+
+		Context("with naming conventions", func() {
+			var attName string
+
+			BeforeEach(func() {
+				attName = "first_name"
+			})
+
+			JustBeforeEach(func() {
+				API(name, func() {
+					AttributeNaming(SnakeCase)
+					WireNaming(CamelCase)
+				})
+				Type("Person", func() {
+					Attribute("address", func() {
+						Attribute(attName, String)
+					})
+				})
+				dslengine.Run()
+			})
+
+			It("sets the naming conventions", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.AttributeNaming).Should(Equal(SnakeCase))
+				Ω(Design.WireNaming).Should(Equal(CamelCase))
+				Ω(Design.WireName("first_name")).Should(Equal("firstName"))
+			})
+
+			Context("with attribute names that do not follow the convention", func() {
+				BeforeEach(func() {
+					attName = "firstName"
+				})
+
+				It("produces a validation error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring(`attribute name "firstName" does not follow the snake_case naming convention, use "first_name" instead`))
+				})
+			})
+		})
+
+		Context("with a CORS policy", func() {
+			BeforeEach(func() {
+				name = "foo"
+				dsl = func() {
+					Origin("https://ui.example.com", func() {
+						Headers("X-Shared-Secret")
+						Methods("GET", "POST")
+						Expose("X-Time")
+						MaxAge(600)
+						Credentials()
+					})
+					Origin("/(api|swagger)[.]example[.]com/", func() {})
+				}
+			})
+
+			It("sets the API origins", func() {
+				Ω(Design.Origins).Should(HaveLen(2))
+				cors := Design.Origins["https://ui.example.com"]
+				Ω(cors).ShouldNot(BeNil())
+				Ω(cors.Headers).Should(Equal([]string{"X-Shared-Secret"}))
+				Ω(cors.Methods).Should(Equal([]string{"GET", "POST"}))
+				Ω(cors.Exposed).Should(Equal([]string{"X-Time"}))
+				Ω(cors.MaxAge).Should(BeEquivalentTo(600))
+				Ω(cors.Credentials).Should(BeTrue())
+				Ω(cors.Regexp).Should(BeFalse())
+				re := Design.Origins["/(api|swagger)[.]example[.]com/"]
+				Ω(re).ShouldNot(BeNil())
+				Ω(re.Regexp).Should(BeTrue())
+				Ω(re.Origin).Should(Equal("(api|swagger)[.]example[.]com"))
+			})
+		})
+
+		Context("with versions", func() {
+			var v2 func()
+			var accountVersion string
+
+			BeforeEach(func() {
+				v2 = func() {
+					Header("X-Api-Version")
+				}
+				accountVersion = "v2"
+			})
+
+			JustBeforeEach(func() {
+				API(name, func() {
+					Version("v1", func() {
+						Description("Initial version")
+						BasePath("/v1")
+					})
+					Version("v2", v2)
+				})
+				account := MediaType("application/vnd.account", func() {
+					if accountVersion != "" {
+						Version(accountVersion)
+					}
+					Attributes(func() {
+						Attribute("id", Integer)
+					})
+					View("default", func() {
+						Attribute("id")
+					})
+				})
+				Resource("account", func() {
+					Version("v2")
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Response(OK, account)
+					})
+				})
+				Resource("bottle", func() {
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Response(OK, account)
+					})
+				})
+				dslengine.Run()
+			})
+
+			Context("that are consistent", func() {
+				BeforeEach(func() {
+					accountVersion = ""
+				})
+
+				It("records the versions", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					Ω(Design.Versions).Should(HaveLen(2))
+					Ω(Design.Versions["v1"]).Should(Equal(&VersionDefinition{
+						Name:        "v1",
+						Description: "Initial version",
+						Scheme:      VersionPath,
+						Key:         "/v1",
+						Parent:      Design,
+					}))
+					Ω(Design.Versions["v2"].Scheme).Should(Equal(VersionHeader))
+					Ω(Design.Versions["v2"].Key).Should(Equal("X-Api-Version"))
+					Ω(Design.Resources["account"].SupportsVersion("v1")).Should(BeFalse())
+					Ω(Design.Resources["account"].SupportsVersion("v2")).Should(BeTrue())
+				})
+			})
+
+			Context("with a resource exposing a media type missing from one of its versions", func() {
+				It("produces a validation error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring(`media type "application/vnd.account" is not available in version "v1"`))
+				})
+			})
+
+			Context("with a version selected twice", func() {
+				BeforeEach(func() {
+					accountVersion = ""
+					v2 = func() {
+						Header("X-Api-Version")
+						Query("version")
+					}
+				})
+
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("version v2 selection previously defined with header"))
+				})
+			})
+
+			Context("with a version that does not define how it is selected", func() {
+				BeforeEach(func() {
+					v2 = func() {}
+				})
+
+				It("produces a validation error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("version must be selected with a path prefix, a header or a query string parameter"))
+				})
+			})
+		})
+
+		Context("with service dependencies", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Uses("cellar", "bottle")
+					Uses("cellar", "account")
+					Uses("billing")
+				}
+			})
+
+			JustBeforeEach(func() {
+				API(name, dsl)
+				dslengine.Run()
+			})
+
+			It("records the resources used", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Uses).Should(Equal(map[string][]string{
+					"cellar":  {"bottle", "account"},
+					"billing": nil,
+				}))
+			})
+		})
+
 		Context("using Traits", func() {
 			const traitName = "Authenticated"
 
@@ -400,6 +706,61 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("using Traits in types and actions", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Trait("Paginated", func() {
+						Attribute("page", Integer)
+					})
+					Trait("Traced", func() {
+						Headers(func() {
+							Header("X-Request-Id")
+						})
+						Response(NotFound)
+					})
+				}
+			})
+
+			JustBeforeEach(func() {
+				API(name, dsl)
+				Type("Filter", func() {
+					UseTrait("Paginated")
+					Attribute("q", String)
+				})
+				Resource("bottle", func() {
+					Action("list", func() {
+						Routing(GET(""))
+						Params(func() {
+							UseTrait("Paginated")
+						})
+						UseTrait("Traced")
+					})
+					Action("search", func() {
+						Routing(POST("/search"))
+						Payload(func() {
+							UseTrait("Paginated")
+						})
+						UseTrait("Traced")
+					})
+				})
+				dslengine.Run()
+			})
+
+			It("executes the traits in each definition", func() {
+				Ω(Design.Types).Should(HaveKey("Filter"))
+				Ω(Design.Types["Filter"].Type.ToObject()).Should(HaveKey("page"))
+				Ω(Design.Types["Filter"].Type.ToObject()).Should(HaveKey("q"))
+				bottle := Design.Resources["bottle"]
+				list := bottle.Actions["list"]
+				Ω(list.Params.Type.ToObject()).Should(HaveKey("page"))
+				Ω(list.Headers.Type.ToObject()).Should(HaveKey("X-Request-Id"))
+				Ω(list.Responses).Should(HaveKey("NotFound"))
+				search := bottle.Actions["search"]
+				Ω(search.Payload.Type.ToObject()).Should(HaveKey("page"))
+				Ω(search.Responses).Should(HaveKey("NotFound"))
+			})
+		})
+
 		Context("using variadic Traits", func() {
 			const traitName1 = "Authenticated"
 			const traitName2 = "AuthenticatedTwo"
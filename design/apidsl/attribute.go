@@ -5,7 +5,6 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -19,7 +18,7 @@ import (
 // attributes may include other attributes. At the basic level an attribute has a name,
 // a type and optionally a default value and validation rules. The type of an attribute can be one of:
 //
-// * The primitive types Boolean, Integer, Number, DateTime, UUID or String.
+// * The primitive types Boolean, Integer, Number, DateTime, Date, UUID or String.
 //
 // * A type defined via the Type function.
 //
@@ -139,6 +138,57 @@ func Attribute(name string, args ...interface{}) {
 	}
 }
 
+// Computed can be used in: Attributes, Type
+//
+// Computed defines an attribute whose value is computed at render time from the values of other
+// attributes. from lists the names of the attributes used to compute the value and is typically
+// built with From, the remaining arguments are the same as the Attribute arguments. Computed
+// attributes and the attributes they are computed from must be primitives. The generated code
+// includes a function that registers the computation for each computed attribute of a media
+// type, the registered function is invoked by the response helpers prior to rendering. Computed
+// attributes are read-only in the generated JSON schema and Swagger specifications. Example:
+//
+//	MediaType("application/vnd.user+json", func() {
+//		Attributes(func() {
+//			Attribute("first", String)
+//			Attribute("last", String)
+//			Computed("full_name", From("first", "last"), String, "First and last name")
+//		})
+//		View("default", func() {
+//			Attribute("first")
+//			Attribute("last")
+//			Attribute("full_name")
+//		})
+//	})
+//
+// The function is registered with the generated function:
+//
+//	app.RegisterUserFullName(func(first, last *string) string { ... })
+//
+func Computed(name string, from []string, args ...interface{}) {
+	var dsl func()
+	if len(args) > 0 {
+		if d, ok := args[len(args)-1].(func()); ok {
+			dsl = d
+			args = args[:len(args)-1]
+		}
+	}
+	args = append(args, func() {
+		if dsl != nil {
+			dsl()
+		}
+		if a, ok := attributeDefinition(); ok {
+			a.ComputedFrom = from
+		}
+	})
+	Attribute(name, args...)
+}
+
+// From returns the names of the attributes used by Computed to compute an attribute value.
+func From(names ...string) []string {
+	return names
+}
+
 // attributeFromRef returns a base attribute given a reference data type.
 // It takes care of running the DSL on the reference type if it hasn't run yet.
 func attributeFromRef(name string, ref design.DataType) *design.AttributeDefinition {
@@ -241,13 +291,28 @@ func parseAttributeArgs(baseAttr *design.AttributeDefinition, args ...interface{
 	return dataType, description, dsl
 }
 
-// Header can be used in: Headers, APIKeySecurity, JWTSecurity
+// Header can be used in: Headers, APIKeySecurity, JWTSecurity, Version, Response
 //
 // Header is an alias of Attribute for the most part.
 //
 // Within an APIKeySecurity or JWTSecurity definition, Header
 // defines that an implementation must check the given header to get
 // the API Key.  In this case, no `args` parameter is necessary.
+//
+// Within a Version definition, Header defines the header that requests set to the version name
+// to select the version. In this case, no `args` parameter is necessary either.
+//
+// Within a Response definition, Header defines a required string header whose value is computed
+// from the attributes of the response media type. The single argument is the template of the
+// value, occurrences of "{attribute}" are replaced with the values of the corresponding
+// attributes of the response body. The generated response methods set the header
+// automatically and return an error if the body is missing one of the attributes:
+//
+//	Response(Created, func() {
+//		Media(OrderMedia)
+//		Header("Location", "/orders/{id}")
+//	})
+//
 func Header(name string, args ...interface{}) {
 	if _, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
 		if len(args) != 0 {
@@ -257,7 +322,35 @@ func Header(name string, args ...interface{}) {
 		inHeader(name)
 		return
 	}
+	if v, ok := dslengine.CurrentDefinition().(*design.VersionDefinition); ok {
+		if len(args) != 0 {
+			dslengine.ReportError("do not specify args")
+			return
+		}
+		versionScheme(v, design.VersionHeader, name)
+		return
+	}
+	if r, ok := dslengine.CurrentDefinition().(*design.ResponseDefinition); ok {
+		if len(args) != 1 {
+			dslengine.ReportError("Header used in Response must be given the header value template")
+			return
+		}
+		tmpl, ok := args[0].(string)
+		if !ok {
+			dslengine.InvalidArgError("string", args[0])
+			return
+		}
+		headerTemplate(r, name, tmpl)
+		return
+	}
+
+	Attribute(name, args...)
+}
 
+// Cookie can be used in: Cookies
+//
+// Cookie is an alias of Attribute.
+func Cookie(name string, args ...interface{}) {
 	Attribute(name, args...)
 }
 
@@ -271,13 +364,20 @@ func Member(name string, args ...interface{}) {
 // Param can be used in: Params
 //
 // Param is an alias of Attribute.
+//
+// Query string params may also use a user type whose attributes are primitives or arrays of
+// primitives. Such params are encoded as deep objects, e.g. "filter[status]=open&filter[owner]=me":
+//
+//	Param("filter", Filter)
 func Param(name string, args ...interface{}) {
 	Attribute(name, args...)
 }
 
 // Default can be used in: Attribute
 //
-// Default sets the default value for an attribute.
+// Default sets the default value for an attribute. The value must be compatible with the attribute
+// type and satisfy its validations (Enum, Minimum, Maximum, MinLength, MaxLength and Pattern). The
+// generated code initializes absent fields with their default values when decoding requests.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor10.
 func Default(def interface{}) {
 	if a, ok := attributeDefinition(); ok {
@@ -296,7 +396,7 @@ func Default(def interface{}) {
 	}
 }
 
-// Example can be used in: Attribute, Header, Param, HashOf, ArrayOf
+// Example can be used in: Attribute, Header, Param, HashOf, ArrayOf, MediaType, Type
 //
 // Example sets the example of an attribute to be used for the documentation:
 //
@@ -310,9 +410,25 @@ func Default(def interface{}) {
 //		Attribute("price", String) //If no Example() is provided, goa generates one that fits your specification
 //	})
 //
+// The example must be compatible with the attribute type and satisfy its validations. Media types
+// and user types may define an example for the whole value which takes precedence over the
+// examples generated from their attributes:
+//
+//	var BottleMedia = MediaType("application/vnd.goa.example.bottle", func() {
+//		Attributes(func() {
+//			Attribute("ID", Integer)
+//			Attribute("name", String)
+//		})
+//		Example(map[string]interface{}{"ID": 1, "name": "Cabernet Sauvignon"})
+//		View("default", func() {
+//			Attribute("ID")
+//			Attribute("name")
+//		})
+//	})
+//
 // If you do not want an auto-generated example for an attribute, add NoExample() to it.
 func Example(exp interface{}) {
-	if a, ok := attributeDefinition(); ok {
+	if a, ok := exampleAttribute(); ok {
 		if pass := a.SetExample(exp); !pass {
 			dslengine.ReportError("example value %#v is incompatible with attribute of type %s",
 				exp, a.Type.Name())
@@ -320,26 +436,56 @@ func Example(exp interface{}) {
 	}
 }
 
-// NoExample can be used in: Attribute, Header, Param, HashOf, ArrayOf
+// NoExample can be used in: Attribute, Header, Param, HashOf, ArrayOf, MediaType, Type
 //
 // NoExample sets the example of an attribute to be blank for the documentation. It is used when
 // users don't want any custom or auto-generated example
 func NoExample() {
+	if api, ok := dslengine.CurrentDefinition().(*design.APIDefinition); ok {
+		api.NoExamples = true
+		return
+	}
+	if a, ok := exampleAttribute(); ok {
+		a.SetExample(nil)
+	}
+}
+
+// exampleAttribute returns the attribute definition that holds the example set in the current
+// context.
+func exampleAttribute() (*design.AttributeDefinition, bool) {
 	switch def := dslengine.CurrentDefinition().(type) {
-	case *design.APIDefinition:
-		def.NoExamples = true
 	case *design.AttributeDefinition:
-		def.SetExample(nil)
-	default:
-		dslengine.IncompatibleDSL()
+		return def, true
+	case *design.MediaTypeDefinition:
+		return def.AttributeDefinition, true
+	case *design.UserTypeDefinition:
+		return def.AttributeDefinition, true
 	}
+	dslengine.IncompatibleDSL()
+	return nil, false
 }
 
-// Enum can be used in: Attribute, Header, Param, HashOf, ArrayOf
+// Enum can be used in: Top level, Attribute, Header, Param, HashOf, ArrayOf
 //
 // Enum adds a "enum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor76.
-func Enum(val ...interface{}) {
+//
+// When used at the top level Enum defines an enum user type instead. The first argument is the
+// name of the type and the other arguments its values which must all be strings, all integers
+// or all numbers. The generated code defines a Go type with one constant per value as well as
+// IsValid and String methods. Enum types can be used as the type of the attributes of user
+// types, media types and payloads:
+//
+//	var OrderStatus = Enum("OrderStatus", "open", "closed", "cancelled")
+//
+//	var Order = Type("Order", func() {
+//		Attribute("status", OrderStatus)
+//	})
+//
+func Enum(val ...interface{}) *design.UserTypeDefinition {
+	if dslengine.IsTopLevelDefinition() {
+		return enumType(val)
+	}
 	if a, ok := attributeDefinition(); ok {
 		ok := true
 		for i, v := range val {
@@ -371,24 +517,350 @@ func Enum(val ...interface{}) {
 			a.AddValues(val)
 		}
 	}
+	return nil
+}
+
+// enumType creates the enum user type defined with the top level Enum DSL.
+func enumType(val []interface{}) *design.UserTypeDefinition {
+	if len(val) < 2 {
+		dslengine.ReportError("enum type must be given a name and at least one value")
+		return nil
+	}
+	name, ok := val[0].(string)
+	if !ok || name == "" {
+		dslengine.ReportError("invalid enum type name %#v, must be a non empty string", val[0])
+		return nil
+	}
+	values := val[1:]
+	var t design.DataType
+	for _, p := range []design.DataType{design.String, design.Integer, design.Number} {
+		compatible := true
+		for _, v := range values {
+			if !p.IsCompatible(v) {
+				compatible = false
+				break
+			}
+		}
+		if compatible {
+			t = p
+			break
+		}
+	}
+	if t == nil {
+		dslengine.ReportError("values of enum type %#v must all be strings, all integers or all numbers", name)
+		return nil
+	}
+	if design.Design.Types == nil {
+		design.Design.Types = make(map[string]*design.UserTypeDefinition)
+	} else if _, ok := design.Design.Types[name]; ok {
+		dslengine.ReportError("type %#v defined twice", name)
+		return nil
+	}
+	ut := &design.UserTypeDefinition{
+		TypeName:            name,
+		AttributeDefinition: &design.AttributeDefinition{Type: t},
+		Enum:                true,
+	}
+	ut.AddValues(values)
+	design.Design.Types[name] = ut
+	return ut
+}
+
+// Flags can be used in: Top level
+//
+// Flags defines a flags type, that is a set of named bits. The first argument is the name of the
+// type and the other arguments the names of the flags. The generated code defines a Go integer
+// type with one constant per flag as well as Has, Set and Clear methods. The values are encoded
+// as the arrays of the names of the flags that are set or as integers depending on the API
+// FlagsEncoding. Decoding arrays that contain unknown flag names fails and validating integers
+// that set unknown bits fails. Flags types can be used as the type of the attributes of user
+// types, media types and payloads:
+//
+//	var Permissions = Flags("Permissions", "read", "write", "admin")
+//
+//	var Member = Type("Member", func() {
+//		Attribute("permissions", Permissions)
+//	})
+//
+func Flags(name string, flags ...string) *design.UserTypeDefinition {
+	if !dslengine.IsTopLevelDefinition() {
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+	if len(flags) == 0 || len(flags) > design.MaxFlags {
+		dslengine.ReportError("flags type %#v must define between 1 and %d flags", name, design.MaxFlags)
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, f := range flags {
+		if f == "" || seen[f] {
+			dslengine.ReportError("invalid flag name %#v in flags type %#v, names must be unique and not empty", f, name)
+			return nil
+		}
+		seen[f] = true
+	}
+	if design.Design.Types == nil {
+		design.Design.Types = make(map[string]*design.UserTypeDefinition)
+	} else if _, ok := design.Design.Types[name]; ok {
+		dslengine.ReportError("type %#v defined twice", name)
+		return nil
+	}
+	min, max := 0.0, float64(uint64(1)<<uint(len(flags))-1)
+	ut := &design.UserTypeDefinition{
+		TypeName: name,
+		AttributeDefinition: &design.AttributeDefinition{
+			Type:       design.Integer,
+			Validation: &dslengine.ValidationDefinition{Minimum: &min, Maximum: &max},
+		},
+		Flags: flags,
+	}
+	design.Design.Types[name] = ut
+	return ut
+}
+
+// OneOf can be used in: Top level
+//
+// OneOf defines a union type whose values are values of exactly one of the given member types. The
+// first argument is the name of the type, the other arguments are the member types given as user
+// types or user type names optionally followed by a DSL that may use Description and
+// Discriminator. The discriminator attribute, "type" by default, identifies the member type of
+// the values: each member type must define it as a required string attribute whose Enum
+// validation lists the values that identify the member, the values of different members cannot
+// overlap. The generated code defines a struct with one field per member type and JSON decoding
+// that dispatches on the discriminator. OneOf types can be used as the type of the attributes of
+// user types, media types and payloads:
+//
+//	var Cat = Type("Cat", func() {
+//		Attribute("kind", String, func() {
+//			Enum("cat")
+//		})
+//		Attribute("lives", Integer)
+//		Required("kind")
+//	})
+//
+//	var Dog = Type("Dog", func() {
+//		Attribute("kind", String, func() {
+//			Enum("dog")
+//		})
+//		Attribute("breed", String)
+//		Required("kind")
+//	})
+//
+//	var Pet = OneOf("Pet", Cat, Dog, func() {
+//		Description("A cat or a dog")
+//		Discriminator("kind")
+//	})
+//
+func OneOf(name string, args ...interface{}) *design.UserTypeDefinition {
+	if !dslengine.IsTopLevelDefinition() {
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+	var dsl func()
+	if len(args) > 0 {
+		if d, ok := args[len(args)-1].(func()); ok {
+			dsl = d
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) < 2 {
+		dslengine.ReportError("one of type %#v must define at least two member types", name)
+		return nil
+	}
+	if design.Design.Types == nil {
+		design.Design.Types = make(map[string]*design.UserTypeDefinition)
+	} else if _, ok := design.Design.Types[name]; ok {
+		dslengine.ReportError("type %#v defined twice", name)
+		return nil
+	}
+	ut := &design.UserTypeDefinition{
+		TypeName:            name,
+		AttributeDefinition: &design.AttributeDefinition{Type: make(design.Object)},
+		Discriminator:       design.DefaultDiscriminator,
+	}
+	if !dslengine.Execute(dsl, ut) {
+		return nil
+	}
+	// Resolve the members once all the types are declared so that types may be given by name
+	ut.DSLFunc = func() {
+		members := make([]*design.UserTypeDefinition, 0, len(args))
+		for _, arg := range args {
+			m, ok := resolveType(arg).(*design.UserTypeDefinition)
+			if !ok {
+				dslengine.ReportError("invalid member type %#v of one of type %#v, members must be user types", arg, name)
+				continue
+			}
+			members = append(members, m)
+		}
+		ut.OneOf = members
+		ut.Type = design.Object{ut.Discriminator: &design.AttributeDefinition{
+			Type:        design.String,
+			Description: "Identifies the member type",
+		}}
+		ut.Validation = &dslengine.ValidationDefinition{Required: []string{ut.Discriminator}}
+	}
+	design.Design.Types[name] = ut
+	return ut
 }
 
-// SupportedValidationFormats lists the supported formats for use with the
-// Format DSL.
-var SupportedValidationFormats = []string{
-	"cidr",
-	"date-time",
-	"email",
-	"hostname",
-	"ipv4",
-	"ipv6",
-	"ip",
-	"mac",
-	"regexp",
-	"rfc1123",
-	"uri",
+// Discriminator can be used in: OneOf
+//
+// Discriminator sets the name of the attribute of the member types whose value identifies the
+// member type of the values of a OneOf type, see OneOf. The default is "type".
+func Discriminator(name string) {
+	ut, ok := dslengine.CurrentDefinition().(*design.UserTypeDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	ut.Discriminator = name
 }
 
+// Transition can be used in: Attribute
+//
+// Transition declares the states that can be reached from the given state for a string attribute
+// that describes a lifecycle, e.g. the status of an order. The generated payload types define a
+// method that checks whether the attribute value is a valid next state and returns an
+// invalid_transition error listing the allowed next states otherwise. The transitions are also
+// rendered as a state diagram in the attribute documentation. Example:
+//
+//	Attribute("status", String, func() {
+//		Enum("pending", "paid", "shipped", "cancelled")
+//		Transition("pending", "paid", "cancelled")
+//		Transition("paid", "shipped", "cancelled")
+//	})
+//
+func Transition(from string, to ...string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.StringKind {
+			incompatibleAttributeType("transition", a.Type.Name(), "a string")
+			return
+		}
+		if a.Transitions == nil {
+			a.Transitions = make(map[string][]string)
+		}
+		a.Transitions[from] = append(a.Transitions[from], to...)
+	}
+}
+
+// VisibleTo can be used in: Attribute
+//
+// VisibleTo restricts the visibility of a media type attribute to the callers that were granted at
+// least one of the given scopes or roles. The response helpers of the generated contexts omit the
+// attribute when the scopes granted to the caller - as recorded in the request context by the
+// security middleware, see goa.WithGrantedScopes - do not include any of the given values. The
+// restrictions apply to nested media types and collections of media types as well. Only the JWT
+// security middleware records the granted scopes out of the box, services using other security
+// schemes must record them with goa.WithGrantedScopes. Attributes with restricted visibility
+// cannot be required. Example:
+//
+//	MediaType("application/vnd.user+json", func() {
+//		Attributes(func() {
+//			Attribute("name", String)
+//			Attribute("email", String, func() {
+//				VisibleTo("admin", "user:read")
+//			})
+//		})
+//		View("default", func() {
+//			Attribute("name")
+//			Attribute("email")
+//		})
+//	})
+//
+func VisibleTo(scopes ...string) {
+	if a, ok := attributeDefinition(); ok {
+		a.VisibleTo = append(a.VisibleTo, scopes...)
+	}
+}
+
+// Nullable can be used in: Attribute
+//
+// Nullable makes it possible to set the attribute to null explicitly. The generated code
+// distinguishes an absent attribute from an attribute set to null: the struct fields use the
+// goa.NullString, goa.NullInt, goa.NullFloat64, goa.NullBool or goa.NullTime types which record
+// both whether the attribute was set and whether it is null. A required nullable attribute must be
+// present but may be null. Nullable applies to the string, integer, number, boolean and date time
+// attributes of payloads, user types and media types. Example:
+//
+//	Type("Patch", func() {
+//		Attribute("nickname", String, func() {
+//			Nullable() // null clears the nickname, omitting it leaves it unchanged
+//			MinLength(3)
+//		})
+//	})
+//
+func Nullable() {
+	if a, ok := attributeDefinition(); ok {
+		a.Nullable = true
+	}
+}
+
+// ReadOnly can be used in: Attribute
+//
+// ReadOnly declares that the attribute is set by the server only so that the same user type may
+// describe both the payloads and the media types of a resource. Read-only attributes are stripped
+// from the payloads that use the type: the generated payload decoding ignores them and does not
+// require them. Read-only attributes are flagged as such in the generated JSON schema and Swagger
+// specifications. Example:
+//
+//	var BottleType = Type("Bottle", func() {
+//		Attribute("id", Integer, func() {
+//			ReadOnly()
+//		})
+//		Attribute("name", String)
+//		Required("id", "name")
+//	})
+//
+func ReadOnly() {
+	if a, ok := attributeDefinition(); ok {
+		a.ReadOnly = true
+	}
+}
+
+// WriteOnly can be used in: Attribute
+//
+// WriteOnly declares that the attribute is sent by clients only, for example a password. Views
+// cannot render write-only attributes so that the attribute never appears in responses. An
+// attribute cannot be both read-only and write-only and write-only attributes cannot be computed
+// or have a restricted visibility. Example:
+//
+//	var AccountType = Type("Account", func() {
+//		Attribute("email", String)
+//		Attribute("password", String, func() {
+//			WriteOnly()
+//		})
+//	})
+//
+func WriteOnly() {
+	if a, ok := attributeDefinition(); ok {
+		a.WriteOnly = true
+	}
+}
+
+// CollectionFormat can be used in: Param
+//
+// CollectionFormat defines how the elements of an array param are encoded in the request. The
+// format is one of "multi" (each element is a separate param, e.g. "?tag=a&tag=b", the default),
+// "csv" (comma separated values, e.g. "?tags=a,b") or "pipes" (pipe separated values, e.g.
+// "?tags=a|b"). The generated code splits the values and validates each element. Example:
+//
+//	Params(func() {
+//		Param("tags", ArrayOf(String), func() {
+//			CollectionFormat("csv")
+//			MinLength(1)
+//		})
+//	})
+//
+func CollectionFormat(format string) {
+	if a, ok := attributeDefinition(); ok {
+		a.CollectionFormat = format
+	}
+}
+
+// SupportedValidationFormats lists the standard formats for use with the Format DSL. Designs may
+// use custom formats registered with design.RegisterFormat as well.
+var SupportedValidationFormats = design.StandardFormats
+
 // Format can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // Format adds a "format" validation to the attribute.
@@ -405,6 +877,8 @@ var SupportedValidationFormats = []string{
 //
 // "uri": RFC3986 URI
 //
+// "uuid": RFC4122 UUID
+//
 // "mac": IEEE 802 MAC-48, EUI-48 or EUI-64 MAC address
 //
 // "cidr": RFC4632 or RFC4291 CIDR notation IP address
@@ -412,27 +886,31 @@ var SupportedValidationFormats = []string{
 // "regexp": RE2 regular expression
 //
 // "rfc1123": RFC1123 date time
+//
+// "currency": ISO 4217 currency code
+//
+// "phone": ITU-T E.164 phone number, the "phone:region" metadata sets the region used to accept
+// numbers in national format, see design.PhoneRegionMetadata
+//
+// Custom formats registered with design.RegisterFormat may also be used, the design validation
+// rejects the formats that are neither standard nor registered. Example:
+//
+//	func init() {
+//		design.RegisterFormat("semver", validateSemver)
+//	}
+//
+//	Attribute("version", String, func() {
+//		Format("semver")
+//	})
 func Format(f string) {
 	if a, ok := attributeDefinition(); ok {
 		if a.Type != nil && a.Type.Kind() != design.StringKind {
 			incompatibleAttributeType("format", a.Type.Name(), "a string")
 		} else {
-			supported := false
-			for _, s := range SupportedValidationFormats {
-				if s == f {
-					supported = true
-					break
-				}
-			}
-			if !supported {
-				dslengine.ReportError("unsupported format %#v, supported formats are: %s",
-					f, strings.Join(SupportedValidationFormats, ", "))
-			} else {
-				if a.Validation == nil {
-					a.Validation = &dslengine.ValidationDefinition{}
-				}
-				a.Validation.Format = f
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
 			}
+			a.Validation.Format = f
 		}
 	}
 }
@@ -527,10 +1005,11 @@ func Maximum(val interface{}) {
 //
 // MinLength adds a "minItems" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor45.
+// The length of a File attribute is the size of the file in bytes, see MultipartForm.
 func MinLength(val int) {
 	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.StringKind && a.Type.Kind() != design.ArrayKind && a.Type.Kind() != design.HashKind {
-			incompatibleAttributeType("minimum length", a.Type.Name(), "a string or an array")
+		if a.Type != nil && a.Type.Kind() != design.StringKind && a.Type.Kind() != design.ArrayKind && a.Type.Kind() != design.HashKind && a.Type.Kind() != design.FileKind {
+			incompatibleAttributeType("minimum length", a.Type.Name(), "a string, an array or a file")
 		} else {
 			if a.Validation == nil {
 				a.Validation = &dslengine.ValidationDefinition{}
@@ -544,10 +1023,11 @@ func MinLength(val int) {
 //
 // MaxLength adds a "maxItems" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor42.
+// The length of a File attribute is the size of the file in bytes, see MultipartForm.
 func MaxLength(val int) {
 	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.StringKind && a.Type.Kind() != design.ArrayKind {
-			incompatibleAttributeType("maximum length", a.Type.Name(), "a string or an array")
+		if a.Type != nil && a.Type.Kind() != design.StringKind && a.Type.Kind() != design.ArrayKind && a.Type.Kind() != design.FileKind {
+			incompatibleAttributeType("maximum length", a.Type.Name(), "a string, an array or a file")
 		} else {
 			if a.Validation == nil {
 				a.Validation = &dslengine.ValidationDefinition{}
@@ -584,6 +1064,47 @@ func Required(names ...string) {
 	}
 }
 
+// RequiredWhen can be used in: Attributes, Payload, Type
+//
+// RequiredWhen adds a conditional "required" validation to the attribute: the field name is
+// required only when the sibling field has the given value. The field must be a string, integer,
+// number or boolean attribute and the value must be compatible with its type. The generated
+// validation code enforces the condition. Example:
+//
+//	Payload(func() {
+//		Attribute("delivery_method", String, func() {
+//			Enum("ship", "pickup")
+//		})
+//		Attribute("shipping_address", AddressType)
+//		Required("delivery_method")
+//		RequiredWhen("shipping_address", "delivery_method", "ship")
+//	})
+//
+func RequiredWhen(name, field string, value interface{}) {
+	var at *design.AttributeDefinition
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		at = def
+	case *design.MediaTypeDefinition:
+		at = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+
+	if at.Type != nil && at.Type.Kind() != design.ObjectKind {
+		incompatibleAttributeType("conditional required", at.Type.Name(), "an object")
+	} else {
+		if at.Validation == nil {
+			at.Validation = &dslengine.ValidationDefinition{}
+		}
+		at.Validation.AddRequiredWhen([]*dslengine.RequiredWhenDefinition{
+			{Name: name, Field: field, Value: value},
+		})
+	}
+}
+
 // incompatibleAttributeType reports an error for validations defined on
 // incompatible attributes (e.g. max value on string).
 func incompatibleAttributeType(validation, actual, expected string) {
@@ -598,6 +1119,8 @@ func qualifiedTypeName(t design.DataType) string {
 	switch t.Kind() {
 	case design.DateTimeKind:
 		return "datetime"
+	case design.DateKind:
+		return "date"
 	case design.ArrayKind:
 		return fmt.Sprintf("%s<%s>", t.Name(), qualifiedTypeName(t.ToArray().ElemType.Type))
 	case design.HashKind:
@@ -381,3 +381,310 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 })
+
+var _ = Describe("Computed", func() {
+	var from []string
+	var parent *AttributeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		from = []string{"first", "last"}
+	})
+
+	JustBeforeEach(func() {
+		Type("type", func() {
+			Attribute("first", String)
+			Attribute("last", String)
+			Computed("full_name", From(from...), String, "First and last name", func() {
+				MinLength(1)
+			})
+		})
+		dslengine.Run()
+		if t, ok := Design.Types["type"]; ok {
+			parent = t.AttributeDefinition
+		}
+	})
+
+	It("produces a computed attribute", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		att := parent.Type.ToObject()["full_name"]
+		Ω(att).ShouldNot(BeNil())
+		Ω(att.Type).Should(Equal(String))
+		Ω(att.Description).Should(Equal("First and last name"))
+		Ω(att.ComputedFrom).Should(Equal([]string{"first", "last"}))
+		Ω(att.Validation).ShouldNot(BeNil())
+	})
+
+	Context("using an unknown attribute", func() {
+		BeforeEach(func() {
+			from = []string{"first", "middle"}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Nullable", func() {
+	var dt DataType
+	var dsl func()
+	var parent *AttributeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dt = String
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Type("type", func() {
+			Attribute("nickname", dt, func() {
+				Nullable()
+				if dsl != nil {
+					dsl()
+				}
+			})
+			Required("nickname")
+		})
+		dslengine.Run()
+		if t, ok := Design.Types["type"]; ok {
+			parent = t.AttributeDefinition
+		}
+	})
+
+	It("produces a nullable attribute", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		att := parent.Type.ToObject()["nickname"]
+		Ω(att).ShouldNot(BeNil())
+		Ω(att.Nullable).Should(BeTrue())
+		Ω(parent.IsRequired("nickname")).Should(BeTrue())
+	})
+
+	Context("on an attribute that cannot be null", func() {
+		BeforeEach(func() {
+			dt = ArrayOf(String)
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("nullable attributes must be of type"))
+		})
+	})
+
+	Context("with a default value", func() {
+		BeforeEach(func() {
+			dsl = func() { Default("bob") }
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot have a default value"))
+		})
+	})
+})
+
+var _ = Describe("RequiredWhen", func() {
+	var field string
+	var value interface{}
+	var parent *AttributeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		field = "delivery_method"
+		value = "ship"
+	})
+
+	JustBeforeEach(func() {
+		Type("Order", func() {
+			Attribute("delivery_method", String)
+			Attribute("shipping_address", String)
+			Attribute("items", ArrayOf(String))
+			RequiredWhen("shipping_address", field, value)
+		})
+		dslengine.Run()
+		if t, ok := Design.Types["Order"]; ok {
+			parent = t.AttributeDefinition
+		}
+	})
+
+	It("adds a conditional required validation", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(parent.Validation.RequiredWhen).Should(HaveLen(1))
+		r := parent.Validation.RequiredWhen[0]
+		Ω(r.Name).Should(Equal("shipping_address"))
+		Ω(r.Field).Should(Equal("delivery_method"))
+		Ω(r.Value).Should(Equal("ship"))
+		Ω(parent.IsRequired("shipping_address")).Should(BeFalse())
+	})
+
+	Context("with an unknown field", func() {
+		BeforeEach(func() {
+			field = "method"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`field "method" used by the required condition`))
+		})
+	})
+
+	Context("with a value incompatible with the field type", func() {
+		BeforeEach(func() {
+			value = 42
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("is incompatible with field"))
+		})
+	})
+
+	Context("with a field that is not a primitive", func() {
+		BeforeEach(func() {
+			field = "items"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("must be a string, integer, number or boolean"))
+		})
+	})
+})
+
+var _ = Describe("ReadOnly and WriteOnly", func() {
+	var dsl func()
+	var view func()
+	var action *ActionDefinition
+	var mt *MediaTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+		view = nil
+	})
+
+	JustBeforeEach(func() {
+		account := Type("Account", func() {
+			Attribute("id", Integer, func() {
+				ReadOnly()
+			})
+			Attribute("email", String)
+			Attribute("password", String, func() {
+				WriteOnly()
+				if dsl != nil {
+					dsl()
+				}
+			})
+			Required("id", "email")
+		})
+		MediaType("application/vnd.account", func() {
+			Reference(account)
+			Attributes(func() {
+				Attribute("id")
+				Attribute("email")
+				Attribute("password")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("email")
+				if view != nil {
+					view()
+				}
+			})
+		})
+		Resource("account", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(account)
+			})
+		})
+		dslengine.Run()
+		action = Design.Resources["account"].Actions["create"]
+		mt = Design.MediaTypeWithIdentifier("application/vnd.account")
+	})
+
+	It("strips the read-only attributes from payloads", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(action.Payload.TypeName).Should(Equal("CreateAccountPayload"))
+		Ω(action.Payload.Type.ToObject()).ShouldNot(HaveKey("id"))
+		Ω(action.Payload.Type.ToObject()).Should(HaveKey("password"))
+		Ω(action.Payload.AllRequired()).Should(Equal([]string{"email"}))
+		Ω(Design.Types["Account"].Type.ToObject()).Should(HaveKey("id"))
+		Ω(mt.Type.ToObject()["id"].ReadOnly).Should(BeTrue())
+		Ω(mt.Type.ToObject()["password"].WriteOnly).Should(BeTrue())
+	})
+
+	Context("with a view rendering a write-only attribute", func() {
+		BeforeEach(func() {
+			view = func() { Attribute("password") }
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot be rendered in a view"))
+		})
+	})
+
+	Context("with an attribute both read-only and write-only", func() {
+		BeforeEach(func() {
+			dsl = func() { ReadOnly() }
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("both read-only and write-only"))
+		})
+	})
+})
+
+var _ = Describe("Docs", func() {
+	var docs interface{}
+	var parent *AttributeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		docs = nil
+	})
+
+	JustBeforeEach(func() {
+		Type("type", func() {
+			Attribute("vintage", Integer, "Vintage year", func() {
+				Docs(docs)
+				Docs(func() {
+					URL("http://example.com/vintages")
+				})
+			})
+		})
+		dslengine.Run()
+		if t, ok := Design.Types["type"]; ok {
+			parent = t.AttributeDefinition
+		}
+	})
+
+	Context("with markdown", func() {
+		BeforeEach(func() {
+			docs = "The year the grapes were *harvested*."
+		})
+
+		It("sets the attribute long form and external docs", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			att := parent.Type.ToObject()["vintage"]
+			Ω(att.Description).Should(Equal("Vintage year"))
+			Ω(att.Docs).ShouldNot(BeNil())
+			Ω(att.Docs.Markdown).Should(Equal("The year the grapes were *harvested*."))
+			Ω(att.Docs.URL).Should(Equal("http://example.com/vintages"))
+		})
+	})
+
+	Context("with an invalid argument", func() {
+		BeforeEach(func() {
+			docs = 42
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid Docs argument"))
+		})
+	})
+})
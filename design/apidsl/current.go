@@ -22,6 +22,16 @@ func apiDefinition() (*design.APIDefinition, bool) {
 	return a, ok
 }
 
+// overlayDefinition returns true and current context if it is an OverlayDefinition,
+// nil and false otherwise.
+func overlayDefinition() (*design.OverlayDefinition, bool) {
+	o, ok := dslengine.CurrentDefinition().(*design.OverlayDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return o, ok
+}
+
 // encodingDefinition returns true and current context if it is an EncodingDefinition,
 // nil and false otherwise.
 func encodingDefinition() (*design.EncodingDefinition, bool) {
@@ -92,6 +102,16 @@ func attributeDefinition() (*design.AttributeDefinition, bool) {
 	return a, ok
 }
 
+// routeDefinition returns true and current context if it is a RouteDefinition,
+// nil and false otherwise.
+func routeDefinition() (*design.RouteDefinition, bool) {
+	r, ok := dslengine.CurrentDefinition().(*design.RouteDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return r, ok
+}
+
 // resourceDefinition returns true and current context if it is a ResourceDefinition,
 // nil and false otherwise.
 func resourceDefinition() (*design.ResourceDefinition, bool) {
@@ -122,6 +142,66 @@ func actionDefinition() (*design.ActionDefinition, bool) {
 	return a, ok
 }
 
+// sagaDefinition returns true and current context if it is a SagaDefinition,
+// nil and false otherwise.
+func sagaDefinition() (*design.SagaDefinition, bool) {
+	s, ok := dslengine.CurrentDefinition().(*design.SagaDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return s, ok
+}
+
+// webSocketDefinition returns true and current context if it is a WebSocketDefinition,
+// nil and false otherwise.
+func webSocketDefinition() (*design.WebSocketDefinition, bool) {
+	w, ok := dslengine.CurrentDefinition().(*design.WebSocketDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return w, ok
+}
+
+// enrichmentDefinition returns true and current context if it is an EnrichmentDefinition,
+// nil and false otherwise.
+func enrichmentDefinition() (*design.EnrichmentDefinition, bool) {
+	e, ok := dslengine.CurrentDefinition().(*design.EnrichmentDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return e, ok
+}
+
+// aggregateDefinition returns true and current context if it is an AggregateDefinition,
+// nil and false otherwise.
+func aggregateDefinition() (*design.AggregateDefinition, bool) {
+	a, ok := dslengine.CurrentDefinition().(*design.AggregateDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return a, ok
+}
+
+// aggregateBranchDefinition returns true and current context if it is an
+// AggregateBranchDefinition, nil and false otherwise.
+func aggregateBranchDefinition() (*design.AggregateBranchDefinition, bool) {
+	b, ok := dslengine.CurrentDefinition().(*design.AggregateBranchDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return b, ok
+}
+
+// paginationDefinition returns true and current context if it is a PaginationDefinition,
+// nil and false otherwise.
+func paginationDefinition() (*design.PaginationDefinition, bool) {
+	p, ok := dslengine.CurrentDefinition().(*design.PaginationDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return p, ok
+}
+
 // responseDefinition returns true and current context if it is a ResponseDefinition,
 // nil and false otherwise.
 func responseDefinition() (*design.ResponseDefinition, bool) {
@@ -307,6 +307,7 @@ func buildView(name string, mt *design.MediaTypeDefinition, at *design.Attribute
 			if existing, ok := mto[n]; ok {
 				dup := design.DupAtt(existing)
 				dup.View = cat.View
+				dup.Metadata = existing.Metadata.Merge(cat.Metadata)
 				o[n] = dup
 			} else if n != "links" {
 				return nil, fmt.Errorf("unknown attribute %#v", n)
@@ -369,6 +370,62 @@ func Link(name string, view ...string) {
 	}
 }
 
+// Embeddable can be used in: MediaType
+//
+// Embeddable declares media type attributes that describe related resources and that are only
+// rendered when requested. Clients request the relations to embed with the "expand" query string
+// parameter which accepts a comma separated list of relation names, e.g. "?expand=account,owner".
+// The generated contexts parse and validate the parameter and the response helpers omit the
+// relations that were not requested. Embeddable attributes must be media types or collections of
+// media types and cannot be required. Example:
+//
+//	MediaType("application/vnd.bottle+json", func() {
+//		Attributes(func() {
+//			Attribute("id", Integer)
+//			Attribute("account", Account)
+//		})
+//		Embeddable("account")
+//		View("default", func() {
+//			Attribute("id")
+//			Attribute("account")
+//		})
+//	})
+//
+func Embeddable(names ...string) {
+	if mt, ok := mediaTypeDefinition(); ok {
+		mt.Embeddables = append(mt.Embeddables, names...)
+	}
+}
+
+// Geometry can be used in: MediaType
+//
+// Geometry makes it possible to render the media type as GeoJSON features. The argument is the
+// name of the media type attribute of type Point or BoundingBox that holds the feature geometry.
+// The generated media type and collection data structures implement goa.GeoJSONer so that they
+// are rendered as a GeoJSON feature and feature collection respectively when encoded with the
+// "application/geo+json" encoder, see Produces. The feature properties consist of the media type
+// attributes. Example:
+//
+//	MediaType("application/vnd.store+json", func() {
+//		Attributes(func() {
+//			Attribute("id", Integer)
+//			Attribute("name", String)
+//			Attribute("location", Point)
+//		})
+//		Geometry("location")
+//		View("default", func() {
+//			Attribute("id")
+//			Attribute("name")
+//			Attribute("location")
+//		})
+//	})
+//
+func Geometry(name string) {
+	if mt, ok := mediaTypeDefinition(); ok {
+		mt.Geometry = name
+	}
+}
+
 // CollectionOf creates a collection media type from its element media type and an optional
 // identifier. A collection media type represents the content of responses that return a collection
 // of resources such as "list" actions. This function can be called from any place where a media
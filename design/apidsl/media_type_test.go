@@ -67,6 +67,24 @@ var _ = Describe("MediaType", func() {
 		})
 	})
 
+	Context("with a file attribute", func() {
+		BeforeEach(func() {
+			name = "application/foo"
+			dslFunc = func() {
+				Attributes(func() {
+					Attribute("doc", File)
+				})
+				View("default", func() { Attribute("doc") })
+			}
+		})
+
+		It("produces an error", func() {
+			err := mt.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("attribute doc is a File"))
+		})
+	})
+
 	Context("with a content type", func() {
 		const attName = "att"
 		const contentType = "application/json"
@@ -89,6 +107,56 @@ var _ = Describe("MediaType", func() {
 		})
 	})
 
+	Context("with a geometry", func() {
+		var geometry string
+
+		BeforeEach(func() {
+			name = "application/foo"
+			geometry = "location"
+			dslFunc = func() {
+				Attributes(func() {
+					Attribute("name")
+					Attribute("location", Point)
+				})
+				Geometry(geometry)
+				View("default", func() {
+					Attribute("name")
+					Attribute("location")
+				})
+			}
+		})
+
+		It("sets the geometry", func() {
+			Ω(mt).ShouldNot(BeNil())
+			Ω(mt.Validate()).ShouldNot(HaveOccurred())
+			Ω(mt.Geometry).Should(Equal("location"))
+		})
+
+		Context("that is not a geo attribute", func() {
+			BeforeEach(func() {
+				geometry = "name"
+			})
+
+			It("produces an error", func() {
+				err := mt.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("must be of type Point or BoundingBox"))
+			})
+		})
+
+		Context("that is not an attribute", func() {
+			BeforeEach(func() {
+				geometry = "foo"
+			})
+
+			It("produces an error", func() {
+				err := mt.Validate()
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("is not an attribute"))
+			})
+		})
+	})
+
 	Context("with a description", func() {
 		const description = "desc"
 
@@ -545,6 +613,38 @@ var _ = Describe("Example", func() {
 			Ω(attr.Example).Should(BeNumerically("<=", 2))
 		})
 
+		It("accepts an example for the whole media type", func() {
+			mt := MediaType("application/vnd.example+json", func() {
+				Example(map[string]interface{}{"test1": "foo"})
+				Attributes(func() {
+					Attribute("test1", String)
+				})
+				View("default", func() {
+					Attribute("test1")
+				})
+			})
+
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(mt.Example).Should(Equal(map[string]interface{}{"test1": "foo"}))
+		})
+
+		It("rejects a media type example incompatible with its attributes", func() {
+			MediaType("application/vnd.example+json", func() {
+				Example("foo")
+				Attributes(func() {
+					Attribute("test1", String)
+				})
+				View("default", func() {
+					Attribute("test1")
+				})
+			})
+
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`example value "foo" is incompatible with attribute of type object`))
+		})
+
 		It("produces media type examples from the linked media type", func() {
 			mt := MediaType("application/vnd.example+json", func() {
 				Attributes(func() {
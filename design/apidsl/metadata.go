@@ -5,12 +5,24 @@ import (
 	"github.com/goadesign/goa/dslengine"
 )
 
-// Metadata can be used in: Attributes, MediaType, Action, Response, Resource, API
+// Metadata can be used in: API, Resource, Action, Routing, Response, Files, Security, Type,
+// MediaType, View, Attribute, Param, Header, Payload
 //
 // Metadata is a set of key/value pairs that can be assigned to an object. Each value consists of a
 // slice of strings so that multiple invocation of the Metadata function on the same target using
 // the same key builds up the slice.
 //
+// The metadata is stored in the Metadata field of the corresponding design definition so that
+// generators, including the plugins run with "goagen gen", can be driven by design annotations.
+// Attributes that use a Reference start with a copy of the metadata of the referenced attributes.
+// The metadata of the attributes listed in a media type view is merged with the metadata of the
+// corresponding media type attributes, the keys set in the view take precedence. The
+// dslengine.MetadataDefinition Last method returns the last value set for a key:
+//
+//        if table, ok := ut.Metadata.Last("gorm:table"); ok {
+//                // ...
+//        }
+//
 // While keys can have any value the following names are handled explicitly by goagen when set on
 // attributes.
 //
@@ -34,6 +46,35 @@ import (
 //
 //        Metadata("struct:tag:json", "myName,omitempty")
 //        Metadata("struct:tag:xml", "myName,attr")
+//        Metadata("struct:tag:gorm", "primaryKey")
+//
+// `cost`: sets the weight of an attribute in the query cost model of the actions whose responses
+// declare embeddable relations, see Embeddable. Attributes weigh 1 by default and relations weigh
+// the sum of the weights of the related media type attributes. Collections weigh ten times their
+// element.
+// Applicable to attributes only.
+//
+//        Metadata("cost", "5")
+//
+// `deprecated`: marks the action or attribute as deprecated. The optional value is the sunset date
+// after which it may be removed using the YYYY-MM-DD format. Deprecated actions are flagged in the
+// Swagger specification and deprecations are listed in the changelog generated by goagen.
+// Applicable to actions and attributes.
+//
+//        Metadata("deprecated", "2027-01-31")
+//
+// `form:name`: sets the name of the key that holds the attribute value in
+// "application/x-www-form-urlencoded" request bodies. Defaults to the attribute name.
+// Applicable to attributes only.
+//
+//        Metadata("form:name", "user_email")
+//
+// `sql:table`: marks the type as persisted in the database table with the given name, used by the
+// goagen migration command. The attribute metadata `sql:column`, `sql:type`, `sql:primary`,
+// `sql:index` and `sql:ignore` customize the corresponding columns.
+// Applicable to user types and media types.
+//
+//        Metadata("sql:table", "bottles")
 //
 // `swagger:generate`: specifies whether Swagger specification should be generated. Defaults to
 // true.
@@ -120,6 +120,60 @@ var _ = Describe("Metadata", func() {
 
 	})
 
+	Context("with Metadata on view and referencing attributes", func() {
+		var ut *UserTypeDefinition
+
+		JustBeforeEach(func() {
+			api = API("Example API", func() {})
+			base := Type("Base", func() {
+				Attribute("id", Integer, func() {
+					Metadata("struct:tag:gorm", "primaryKey")
+					Metadata("sql:column", "bottle_id")
+				})
+			})
+			ut = Type("Payload", func() {
+				Reference(base)
+				Attribute("id", func() {
+					Metadata("sql:column", "id")
+				})
+			})
+			mtd = MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer, func() {
+						Metadata("struct:tag:gorm", "primaryKey")
+					})
+				})
+				View("default", func() {
+					Attribute("id", func() {
+						Metadata("swagger:extension:x-view", "true")
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("merges the metadata", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			id := ut.Type.ToObject()["id"]
+			Ω(id.Metadata).Should(Equal(dslengine.MetadataDefinition{
+				"struct:tag:gorm": {"primaryKey"},
+				"sql:column":      {"bottle_id", "id"},
+			}))
+			col, ok := id.Metadata.Last("sql:column")
+			Ω(ok).Should(BeTrue())
+			Ω(col).Should(Equal("id"))
+			baseID := Design.Types["Base"].Type.ToObject()["id"]
+			Ω(baseID.Metadata["sql:column"]).Should(Equal([]string{"bottle_id"}))
+
+			viewID := mtd.Views["default"].Type.ToObject()["id"]
+			Ω(viewID.Metadata).Should(Equal(dslengine.MetadataDefinition{
+				"struct:tag:gorm":          {"primaryKey"},
+				"swagger:extension:x-view": {"true"},
+			}))
+			Ω(mtd.Type.ToObject()["id"].Metadata).Should(HaveLen(1))
+		})
+	})
+
 	Context("with no Metadata declaration", func() {
 		JustBeforeEach(func() {
 			api = API("Example API", func() {})
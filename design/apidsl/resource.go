@@ -1,6 +1,9 @@
 package apidsl
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -127,3 +130,117 @@ func CanonicalActionName(a string) {
 		r.CanonicalActionName = a
 	}
 }
+
+// CRUD can be used in: Resource
+//
+// CRUD defines the five standard actions of a resource whose representation is described by the
+// given media type. The media type must define an "id" attribute which is used to identify the
+// resource collection items. The corresponding path parameter is named after the resource, e.g.
+// "bottleID" for the "bottle" resource, so that child resources may use CRUD as well. CRUD also
+// sets the resource default media type if not already set. The following actions are defined:
+//
+//	list:   GET "" responds with OK and a collection of the media type
+//	show:   GET "/:bottleID" responds with OK or NotFound
+//	create: POST "" responds with Created or BadRequest
+//	update: PUT "/:bottleID" responds with NoContent, NotFound or BadRequest
+//	delete: DELETE "/:bottleID" responds with NoContent or NotFound
+//
+// The create and update payloads consist of the media type attributes minus "id" and the computed
+// attributes. The actions may be customized afterwards by calling Action with the same name, the
+// DSL given to Action is applied on top of the definition built by CRUD:
+//
+//	Resource("bottle", func() {
+//		BasePath("/bottles")
+//		CRUD(BottleMedia)
+//		Action("list", func() {
+//			Params(func() {
+//				Param("year", Integer)
+//			})
+//		})
+//	})
+func CRUD(mt *design.MediaTypeDefinition) {
+	r, ok := resourceDefinition()
+	if !ok {
+		return
+	}
+	if mt == nil || mt.UserTypeDefinition == nil {
+		dslengine.ReportError("invalid CRUD argument, media type is not initialized")
+		return
+	}
+	id, ok := mt.Type.ToObject()["id"]
+	if !ok {
+		dslengine.ReportError("media type %#v used in CRUD must define an \"id\" attribute", mt.Identifier)
+		return
+	}
+	if r.MediaType == "" || r.MediaType == "text/plain" {
+		DefaultMedia(mt)
+	}
+	payload := crudPayload(mt)
+	idName := "id"
+	if n := camelize(r.Name); n != "" {
+		idName = strings.ToLower(n[:1]) + n[1:] + "ID"
+	}
+	idPath := "/:" + idName
+	idParam := func() {
+		Param(idName, id.Type, id.Description)
+	}
+
+	Action("list", func() {
+		Description(fmt.Sprintf("List the %s collection", r.Name))
+		Routing(GET(""))
+		Response(design.OK, CollectionOf(mt))
+	})
+	Action("show", func() {
+		Description(fmt.Sprintf("Retrieve the %s with the given id", r.Name))
+		Routing(GET(idPath))
+		Params(idParam)
+		Response(design.OK, mt)
+		Response(design.NotFound)
+	})
+	Action("create", func() {
+		Description(fmt.Sprintf("Create a new %s", r.Name))
+		Routing(POST(""))
+		Payload(payload)
+		Response(design.Created)
+		Response(design.BadRequest, design.ErrorMedia)
+	})
+	Action("update", func() {
+		Description(fmt.Sprintf("Replace the %s with the given id", r.Name))
+		Routing(PUT(idPath))
+		Params(idParam)
+		Payload(payload)
+		Response(design.NoContent)
+		Response(design.NotFound)
+		Response(design.BadRequest, design.ErrorMedia)
+	})
+	Action("delete", func() {
+		Description(fmt.Sprintf("Delete the %s with the given id", r.Name))
+		Routing(DELETE(idPath))
+		Params(idParam)
+		Response(design.NoContent)
+		Response(design.NotFound)
+	})
+}
+
+// crudPayload returns the attribute used to define the create and update payloads of the
+// actions defined by CRUD.
+func crudPayload(mt *design.MediaTypeDefinition) *design.AttributeDefinition {
+	att := design.DupAtt(mt.AttributeDefinition)
+	obj := make(design.Object)
+	for n, a := range mt.Type.ToObject() {
+		if n != "id" && len(a.ComputedFrom) == 0 {
+			obj[n] = design.DupAtt(a)
+		}
+	}
+	att.Type = obj
+	if att.Validation != nil {
+		var required []string
+		for _, n := range att.Validation.Required {
+			if _, ok := obj[n]; ok {
+				required = append(required, n)
+			}
+		}
+		att.Validation.Required = required
+	}
+	return att
+}
@@ -339,4 +339,53 @@ var _ = Describe("Resource", func() {
 			Ω(res.Description).Should(Equal(description))
 		})
 	})
+
+	Context("with CRUD", func() {
+		BeforeEach(func() {
+			name = "bottle"
+			mt := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+					Required("id", "name")
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+			})
+			dsl = func() {
+				BasePath("/bottles")
+				CRUD(mt)
+				Action("list", func() {
+					Params(func() {
+						Param("name", String)
+					})
+				})
+			}
+		})
+
+		It("defines the standard actions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(res.MediaType).Should(Equal("application/vnd.bottle"))
+			Ω(res.Actions).Should(HaveLen(5))
+			Ω(res.Actions["show"].Routes[0].Verb).Should(Equal("GET"))
+			Ω(res.Actions["show"].Routes[0].Path).Should(Equal("/:bottleID"))
+			Ω(res.Actions["show"].Params.Type.ToObject()["bottleID"].Type).Should(Equal(Integer))
+			Ω(res.Actions["delete"].Responses).Should(HaveKey("NotFound"))
+			Ω(res.Actions["create"].Responses).Should(HaveKey("Created"))
+		})
+
+		It("excludes the id from the payloads", func() {
+			obj := res.Actions["update"].Payload.Type.ToObject()
+			Ω(obj).Should(HaveKey("name"))
+			Ω(obj).ShouldNot(HaveKey("id"))
+			Ω(res.Actions["update"].Payload.Validation.Required).Should(Equal([]string{"name"}))
+		})
+
+		It("applies customizations", func() {
+			Ω(res.Actions["list"].Params.Type.ToObject()).Should(HaveKey("name"))
+			Ω(res.Actions["list"].Routes).Should(HaveLen(1))
+		})
+	})
 })
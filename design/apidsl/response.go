@@ -1,6 +1,10 @@
 package apidsl
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -83,7 +87,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 			return
 		}
 		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
-			if resp.Status == 200 && resp.MediaType == "" {
+			if resp.Status == 200 && resp.MediaType == "" && resp.Filename == "" {
 				resp.MediaType = def.Parent.MediaType
 				resp.ViewName = def.Parent.DefaultViewName
 			}
@@ -100,7 +104,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 			return
 		}
 		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
-			if resp.Status == 200 && resp.MediaType == "" {
+			if resp.Status == 200 && resp.MediaType == "" && resp.Filename == "" {
 				resp.MediaType = def.MediaType
 				resp.ViewName = def.DefaultViewName
 			}
@@ -122,6 +126,132 @@ func Status(status int) {
 	}
 }
 
+// Download can be used in: Response, ResponseTemplate
+//
+// Download declares that the response is a file download. The generated response method sets the
+// Content-Disposition header so that clients save the response body to a file with the given
+// name. The name may refer to action params using the "{param}" notation, such params must be
+// required or have a default value. The response media type defaults to
+// "application/octet-stream" and must not be a media type defined in the design. Example:
+//
+//	Action("download", func() {
+//		Routing(GET("/invoices/:id/pdf"))
+//		Params(func() {
+//			Param("id", Integer)
+//			Required("id")
+//		})
+//		Response(OK, func() {
+//			Media("application/pdf")
+//			Download("invoice-{id}.pdf")
+//		})
+//	})
+//
+func Download(filename string) {
+	if r, ok := responseDefinition(); ok {
+		r.Filename = filename
+		if r.MediaType == "" {
+			r.MediaType = "application/octet-stream"
+		}
+	}
+}
+
+// Redirect can be used in: Action
+//
+// Redirect declares a redirect response with the given 3xx status code, e.g. 301, 302 or 307. The
+// response is named after the status (e.g. MovedPermanently) and defines the required Location
+// header. The location may refer to the action params using the ":param" notation of routes, such
+// params must be captured by the action routes, be required or have a default value. The
+// generated response method sets the
+// Location header from the request params. The optional DSL may use the same functions as the
+// Response DSL, e.g. Description. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/v1/users/:id"))
+//		Params(func() {
+//			Param("id", Integer)
+//		})
+//		Redirect("/v2/users/:id", 301)
+//	})
+//
+func Redirect(location string, status int, dsls ...func()) {
+	if len(dsls) > 1 {
+		dslengine.ReportError("too many arguments given to Redirect")
+		return
+	}
+	if location == "" {
+		dslengine.ReportError("redirect location cannot be empty")
+		return
+	}
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	name := strings.Replace(http.StatusText(status), " ", "", -1)
+	if name == "" {
+		name = fmt.Sprintf("Redirect%d", status)
+	}
+	Response(name, func() {
+		Status(status)
+		Headers(func() {
+			Header("Location", design.String, "URL of the redirect target")
+			Required("Location")
+		})
+		if len(dsls) == 1 {
+			dsls[0]()
+		}
+	})
+	if r, ok := a.Responses[name]; ok {
+		r.Location = location
+	}
+}
+
+// StreamingResponse can be used in: Action
+//
+// StreamingResponse declares that the action responds with a server-sent event stream (a
+// "text/event-stream" response with status code 200) whose events hold data rendered with the
+// given media type. The media type must be defined in the design and is given by definition or by
+// identifier, the optional DSL may use the same functions as the Response DSL, e.g. Description or
+// Headers. The generated response method starts the stream and returns a value used to send the
+// events. Example:
+//
+//	Action("watch", func() {
+//		Routing(GET("/:id/watch"))
+//		StreamingResponse(BottleMedia)
+//	})
+//
+func StreamingResponse(mt interface{}, dsls ...func()) {
+	if len(dsls) > 1 {
+		dslengine.ReportError("too many arguments given to StreamingResponse")
+		return
+	}
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	Response(design.OK, func() {
+		Media(mt)
+		if len(dsls) == 1 {
+			dsls[0]()
+		}
+	})
+	if r, ok := a.Responses[design.OK]; ok {
+		r.Streaming = true
+	}
+}
+
+// headerTemplate records the template of the value of the given response header and declares the
+// header.
+func headerTemplate(r *design.ResponseDefinition, name, tmpl string) {
+	if r.HeaderTemplates == nil {
+		r.HeaderTemplates = make(map[string]string)
+	}
+	r.HeaderTemplates[name] = tmpl
+	Headers(func() {
+		Header(name, design.String, fmt.Sprintf("Computed from %q", tmpl))
+		Required(name)
+	})
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
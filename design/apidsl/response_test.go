@@ -1,6 +1,8 @@
 package apidsl_test
 
 import (
+	"strings"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -177,6 +179,41 @@ var _ = Describe("Response", func() {
 		})
 	})
 
+	Context("with a download", func() {
+		const filename = "report.csv"
+
+		BeforeEach(func() {
+			name = "OK"
+			dsl = func() {
+				Download(filename)
+			}
+		})
+
+		It("sets the filename and defaults the media type", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.Filename).Should(Equal(filename))
+			Ω(res.MediaType).Should(Equal("application/octet-stream"))
+		})
+	})
+
+	Context("with a download referring to an unknown param", func() {
+		BeforeEach(func() {
+			name = "OK"
+			dsl = func() {
+				Media("text/csv")
+				Download("report-{id}.csv")
+			}
+		})
+
+		It("produces an invalid response definition", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.MediaType).Should(Equal("text/csv"))
+			Ω(res.FilenameParams()).Should(Equal([]string{"id"}))
+			Ω(res.Validate()).Should(HaveOccurred())
+		})
+	})
+
 	Context("not from the goa default definitions", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -196,5 +233,309 @@ var _ = Describe("Response", func() {
 			Ω(res.Standard).Should(BeTrue())
 		})
 	})
+})
 
+var _ = Describe("Download", func() {
+	var filename string
+	var requireFormat bool
+	var res *ResponseDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		filename = "report-{id}.csv"
+		requireFormat = false
+	})
+
+	JustBeforeEach(func() {
+		Resource("res", func() {
+			Action("action", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer)
+					Param("format", String)
+					Required("id")
+					if requireFormat {
+						Required("format")
+					}
+				})
+				Response(OK, func() {
+					Download(filename)
+				})
+			})
+		})
+		dslengine.Run()
+		res = Design.Resources["res"].Actions["action"].Responses["OK"]
+	})
+
+	It("accepts required path params in the filename", func() {
+		Ω(res.Validate()).ShouldNot(HaveOccurred())
+	})
+
+	Context("with an optional query param in the filename", func() {
+		BeforeEach(func() {
+			filename = "report.{format}"
+		})
+
+		It("produces an invalid response definition", func() {
+			Ω(res.Validate()).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a required query param in the filename", func() {
+		BeforeEach(func() {
+			filename = "report.{format}"
+			requireFormat = true
+		})
+
+		It("accepts the param", func() {
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Redirect", func() {
+	var location string
+	var status int
+	var res *ResponseDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		location = "/v2/users/:id"
+		status = 301
+	})
+
+	JustBeforeEach(func() {
+		Resource("res", func() {
+			Action("action", func() {
+				Routing(GET("/v1/users/:id"))
+				Params(func() {
+					Param("id", Integer)
+					Param("format", String)
+					Required("id")
+				})
+				Redirect(location, status, func() {
+					Description("User moved to v2")
+				})
+			})
+		})
+		dslengine.Run()
+		res = nil
+		if a, ok := Design.Resources["res"].Actions["action"]; ok {
+			for _, r := range a.Responses {
+				res = r
+			}
+		}
+	})
+
+	It("defines a redirect response with a required Location header", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(res.Name).Should(Equal(MovedPermanently))
+		Ω(res.Status).Should(Equal(301))
+		Ω(res.Description).Should(Equal("User moved to v2"))
+		Ω(res.Location).Should(Equal("/v2/users/:id"))
+		Ω(res.LocationParams()).Should(Equal([]string{"id"}))
+		Ω(res.Headers.Type.ToObject()).Should(HaveKey("Location"))
+		Ω(res.Headers.IsRequired("Location")).Should(BeTrue())
+	})
+
+	Context("with a temporary redirect", func() {
+		BeforeEach(func() {
+			status = 307
+		})
+
+		It("names the response after the status", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(res.Name).Should(Equal(TemporaryRedirect))
+		})
+	})
+
+	Context("with a status that is not 3xx", func() {
+		BeforeEach(func() {
+			status = 200
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("redirect response must have a 3xx status code, got 200"))
+		})
+	})
+
+	Context("with an optional param in the location", func() {
+		BeforeEach(func() {
+			location = "/v2/users/:id/:format"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`param "format" used in redirect location must be required or have a default value`))
+			Ω(strings.Count(dslengine.Errors.Error(), `param "format"`)).Should(Equal(1))
+		})
+	})
+
+	Context("with the documented example", func() {
+		BeforeEach(func() {
+			Resource("users", func() {
+				Action("show", func() {
+					Routing(GET("/v1/users/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Redirect("/v2/users/:id", 301)
+				})
+			})
+		})
+
+		It("does not require the route params", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with an unknown param in the location", func() {
+		BeforeEach(func() {
+			location = "https://example.com:8443/v2/accounts/:account"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown param "account" in redirect location`))
+		})
+	})
+
+	Context("with an empty location", func() {
+		BeforeEach(func() {
+			location = ""
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("redirect location cannot be empty"))
+		})
+	})
+})
+
+var _ = Describe("Header in Response", func() {
+	var template string
+	var res *ResponseDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		template = "/orders/{id}"
+	})
+
+	JustBeforeEach(func() {
+		MediaType("application/vnd.order", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("items", ArrayOf(String))
+				Attribute("note", String)
+				Required("id")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("items")
+			})
+		})
+		Resource("res", func() {
+			Action("create", func() {
+				Routing(POST("/orders"))
+				Response(Created, func() {
+					Media("application/vnd.order")
+					Header("Location", template)
+				})
+			})
+		})
+		dslengine.Run()
+		res = Design.Resources["res"].Actions["create"].Responses[Created]
+	})
+
+	It("defines a required header computed from the media type attributes", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(res.HeaderTemplates).Should(Equal(map[string]string{"Location": "/orders/{id}"}))
+		Ω(res.HeaderTemplateAttributes("Location")).Should(Equal([]string{"id"}))
+		Ω(res.Headers.Type.ToObject()).Should(HaveKey("Location"))
+		Ω(res.Headers.IsRequired("Location")).Should(BeTrue())
+	})
+
+	Context("with an unknown attribute", func() {
+		BeforeEach(func() {
+			template = "/orders/{number}"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown attribute "number" in template of header "Location"`))
+		})
+	})
+
+	Context("with an attribute that is not a primitive", func() {
+		BeforeEach(func() {
+			template = "/orders/{items}"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`attribute "items" used in template of header "Location" must be a non nullable primitive`))
+		})
+	})
+
+	Context("with an attribute not rendered by the response view", func() {
+		BeforeEach(func() {
+			template = "/orders/{note}"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`attribute "note" used in template of header "Location" is not rendered by view "default"`))
+		})
+	})
+})
+
+var _ = Describe("StreamingResponse", func() {
+	var mt interface{}
+	var res *ResponseDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		mt = "application/vnd.event"
+	})
+
+	JustBeforeEach(func() {
+		MediaType("application/vnd.event", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("res", func() {
+			Action("watch", func() {
+				Routing(GET("/watch"))
+				StreamingResponse(mt, func() {
+					Description("Stream of events")
+				})
+			})
+		})
+		dslengine.Run()
+		res = Design.Resources["res"].Actions["watch"].Responses[OK]
+	})
+
+	It("defines a streaming OK response", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(res.Streaming).Should(BeTrue())
+		Ω(res.Status).Should(Equal(200))
+		Ω(res.MediaType).Should(Equal("application/vnd.event"))
+		Ω(res.Description).Should(Equal("Stream of events"))
+	})
+
+	Context("with a media type that is not defined in the design", func() {
+		BeforeEach(func() {
+			mt = "application/json"
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("must be defined in the design"))
+		})
+	})
 })
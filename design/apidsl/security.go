@@ -310,11 +310,16 @@ func inHeader(headerName string) {
 	dslengine.IncompatibleDSL()
 }
 
-// Query can be used in: APIKeySecurity, JWTSecurity
+// Query can be used in: APIKeySecurity, JWTSecurity, Version
 //
 // Query defines that an APIKeySecurity or JWTSecurity implementation must check in the query
-// parameter named "parameterName" to get the api key.
+// parameter named "parameterName" to get the api key. Used in Version, Query defines the query
+// string parameter that requests set to the version name to select the version.
 func Query(parameterName string) {
+	if v, ok := dslengine.CurrentDefinition().(*design.VersionDefinition); ok {
+		versionScheme(v, design.VersionQuery, parameterName)
+		return
+	}
 	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
 		if current.Kind == design.APIKeySecurityKind || current.Kind == design.JWTSecurityKind {
 			if current.In != "" {
@@ -173,6 +173,42 @@ var _ = Describe("Security", func() {
 			Ω(dslengine.Errors).Should(HaveOccurred())
 		})
 
+		It("should fail because of a scope not defined by the scheme", func() {
+			API("", func() {
+				OAuth2Security("googAuthz", func() {
+					AccessCodeFlow("http://example.com/auth", "http://example.com/token")
+					Scope("scope:1", "Desc 1")
+				})
+			})
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/first"))
+					Security("googAuthz", func() {
+						Scope("scope:unknown")
+					})
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`scope "scope:unknown" is not defined by security scheme "googAuthz"`))
+		})
+
+	})
+
+	Context("with a scheme not registered in the API", func() {
+		It("should fail validation", func() {
+			API("", nil)
+			scheme := &SecuritySchemeDefinition{Kind: APIKeySecurityKind, SchemeName: "orphan", Type: "apiKey"}
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/first"))
+					Security(scheme)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown security scheme "orphan"`))
+		})
 	})
 
 	Context("with resources and actions", func() {
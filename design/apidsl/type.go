@@ -34,6 +34,17 @@ import (
 //		Attribute("Country")
 //	})
 //
+// Types may be recursive, attributes refer to the type being defined using its name:
+//
+//	Type("Comment", func() {
+//		Attribute("body", String)
+//		Attribute("parent", "Comment")
+//		Attribute("replies", ArrayOf("Comment"))
+//	})
+//
+// At least one attribute of a recursive cycle must be optional (or an array or hash) so that
+// values are finite.
+//
 // This function returns the newly defined type so the value can be used throughout the dsl.
 func Type(name string, dsl func()) *design.UserTypeDefinition {
 	if design.Design.Types == nil {
@@ -177,7 +188,7 @@ func resolveType(v interface{}) design.DataType {
 		if ut, ok := design.Design.Types[name]; ok {
 			return ut
 		}
-		if mt, ok := design.Design.MediaTypes[name]; ok {
+		if mt := design.Design.MediaTypeWithIdentifier(name); mt != nil {
 			return mt
 		}
 	}
@@ -108,6 +108,184 @@ var _ = Describe("Type", func() {
 	})
 })
 
+var _ = Describe("Enum", func() {
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("used at the top level", func() {
+		BeforeEach(func() {
+			ut = Enum("OrderStatus", "open", "closed")
+			dslengine.Run()
+		})
+
+		It("defines an enum type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Types).Should(HaveKeyWithValue("OrderStatus", ut))
+			Ω(ut.Enum).Should(BeTrue())
+			Ω(ut.Type).Should(Equal(String))
+			Ω(ut.Validation.Values).Should(Equal([]interface{}{"open", "closed"}))
+			Ω(IsEnum(ut)).Should(BeTrue())
+		})
+	})
+
+	Context("with integer values", func() {
+		BeforeEach(func() {
+			ut = Enum("Priority", 1, 2, 3)
+			dslengine.Run()
+		})
+
+		It("defines an integer enum type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut.Type).Should(Equal(Integer))
+		})
+	})
+
+	Context("with values of different types", func() {
+		BeforeEach(func() {
+			ut = Enum("Mixed", "open", 1)
+		})
+
+		It("reports an error", func() {
+			Ω(ut).Should(BeNil())
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("used by an attribute", func() {
+		BeforeEach(func() {
+			status := Enum("OrderStatus", "open", "closed")
+			Type("Order", func() {
+				Attribute("status", status, func() {
+					Default("open")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the attribute type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			status := Design.Types["Order"].Type.ToObject()["status"]
+			Ω(IsEnum(status.Type)).Should(BeTrue())
+			Ω(status.Validation).Should(BeNil())
+		})
+	})
+})
+
+var _ = Describe("Flags", func() {
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("used at the top level", func() {
+		BeforeEach(func() {
+			ut = Flags("Permissions", "read", "write", "admin")
+			dslengine.Run()
+		})
+
+		It("defines a flags type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Types).Should(HaveKeyWithValue("Permissions", ut))
+			Ω(IsFlags(ut)).Should(BeTrue())
+			Ω(ut.Flags).Should(Equal([]string{"read", "write", "admin"}))
+			Ω(ut.Type).Should(Equal(Integer))
+			Ω(*ut.Validation.Maximum).Should(Equal(7.0))
+		})
+	})
+
+	Context("with duplicate flag names", func() {
+		BeforeEach(func() {
+			ut = Flags("Permissions", "read", "read")
+		})
+
+		It("reports an error", func() {
+			Ω(ut).Should(BeNil())
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("OneOf", func() {
+	var ut *UserTypeDefinition
+	var member func(string, string) func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		member = func(att, value string) func() {
+			return func() {
+				Attribute(att, String, func() {
+					Enum(value)
+				})
+				Required(att)
+			}
+		}
+	})
+
+	Context("used at the top level", func() {
+		BeforeEach(func() {
+			cat := Type("Cat", member("kind", "cat"))
+			ut = OneOf("Pet", cat, "Dog", func() {
+				Description("A cat or a dog")
+				Discriminator("kind")
+			})
+			Type("Dog", member("kind", "dog"))
+			dslengine.Run()
+		})
+
+		It("defines a one of type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Types).Should(HaveKeyWithValue("Pet", ut))
+			Ω(IsOneOf(ut)).Should(BeTrue())
+			Ω(ut.Description).Should(Equal("A cat or a dog"))
+			Ω(ut.Discriminator).Should(Equal("kind"))
+			Ω(ut.OneOf).Should(HaveLen(2))
+			Ω(ut.OneOf[0].TypeName).Should(Equal("Cat"))
+			Ω(ut.OneOf[1].TypeName).Should(Equal("Dog"))
+			Ω(ut.Type.ToObject()).Should(HaveKey("kind"))
+			Ω(ut.IsRequired("kind")).Should(BeTrue())
+		})
+	})
+
+	Context("with the default discriminator", func() {
+		BeforeEach(func() {
+			ut = OneOf("Pet", Type("Cat", member("type", "cat")), Type("Dog", member("type", "dog")))
+			dslengine.Run()
+		})
+
+		It("uses the type attribute", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut.Discriminator).Should(Equal("type"))
+		})
+	})
+
+	Context("with a single member type", func() {
+		BeforeEach(func() {
+			ut = OneOf("Pet", Type("Cat", member("type", "cat")))
+		})
+
+		It("reports an error", func() {
+			Ω(ut).Should(BeNil())
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an unknown member type", func() {
+		BeforeEach(func() {
+			ut = OneOf("Pet", Type("Cat", member("type", "cat")), "Bird")
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid member type "Bird"`))
+		})
+	})
+})
+
 var _ = Describe("ArrayOf", func() {
 	Context("used on a global variable", func() {
 		var (
@@ -225,6 +403,31 @@ var _ = Describe("ArrayOf", func() {
 			Ω(et.Type.(*MediaTypeDefinition).TypeName).Should(Equal("Test"))
 		})
 	})
+
+	Context("defined with a media type identifier with a suffix", func() {
+		var mt *MediaTypeDefinition
+		BeforeEach(func() {
+			dslengine.Reset()
+			mt = MediaType("application/vnd.test+json", func() {
+				Attributes(func() {
+					Attribute("children", ArrayOf("application/vnd.test+json"))
+				})
+				View("default", func() {
+					Attribute("children")
+				})
+			})
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("resolves the media type", func() {
+			et := mt.Type.ToObject()["children"].Type.ToArray().ElemType
+			Ω(et.Type).Should(Equal(mt))
+		})
+	})
 })
 
 var _ = Describe("HashOf", func() {
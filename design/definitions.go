@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -74,6 +76,37 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// RateLimit is the default rate limit that applies to all API requests if any.
+		RateLimit *RateLimitDefinition
+		// Features lists the API feature flags and their default state indexed by name.
+		Features map[string]bool
+		// AttributeNaming is the naming convention that the names of the attributes of the
+		// API types, media types and payloads must follow if any.
+		AttributeNaming NamingConvention
+		// WireNaming is the naming convention the attribute names are converted to in
+		// request and response bodies if any, see WireName.
+		WireNaming NamingConvention
+		// FlagsFormat is the representation of the values of flags types in request and
+		// response bodies if any, see FlagsWireFormat.
+		FlagsFormat FlagsFormat
+		// MoneyFormat is the representation of the amounts of Money values in request and
+		// response bodies if any, see MoneyWireFormat.
+		MoneyFormat MoneyFormat
+		// Overlays lists the environment specific overlays indexed by environment name.
+		Overlays map[string]*OverlayDefinition
+		// Versions lists the API versions described by the design indexed by name.
+		Versions map[string]*VersionDefinition
+		// Uses lists the resources of the other services of the workspace called by this
+		// service indexed by API name. An empty list means all the resources are used.
+		Uses map[string][]string
+		// ClientPkgs lists the import paths of the generated client packages of the services
+		// listed in Uses indexed by API name, see ImportClients.
+		ClientPkgs map[string]string
+		// ContextValues lists the request-scoped values stored in the request context by
+		// middleware indexed by name.
+		ContextValues map[string]*ContextValueDefinition
+		// Middleware lists the middleware that apply to all the API actions.
+		Middleware []*MiddlewareDefinition
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -97,12 +130,47 @@ type (
 		URL string `json:"url,omitempty"`
 	}
 
-	// DocsDefinition points to external documentation.
+	// DocsDefinition points to external documentation and holds the long form documentation
+	// written in markdown.
 	DocsDefinition struct {
 		// Description of documentation.
 		Description string `json:"description,omitempty"`
 		// URL to documentation.
 		URL string `json:"url,omitempty"`
+		// Markdown is the long form documentation, it complements the short description of
+		// the documented definition.
+		Markdown string `json:"markdown,omitempty"`
+	}
+
+	// RateLimitDefinition describes the maximum number of requests allowed during a period of
+	// time.
+	RateLimitDefinition struct {
+		// Requests is the maximum number of requests.
+		Requests int
+		// Period is the period of time the limit applies to.
+		Period time.Duration
+	}
+
+	// OverlayDefinition contains the environment specific values that override the API design
+	// when the overlay is applied.
+	OverlayDefinition struct {
+		// Name is the name of the environment the overlay applies to.
+		Name string
+		// Description of overlay
+		Description string
+		// Host overrides the API hostname if not empty.
+		Host string
+		// Schemes overrides the API URL schemes if not empty.
+		Schemes []string
+		// RateLimit overrides the API rate limit if not nil.
+		RateLimit *RateLimitDefinition
+		// Features overrides the state of the API feature flags indexed by name.
+		Features map[string]bool
+		// Disabled lists the names of the actions removed from the API indexed by resource
+		// name. An empty list of action names removes the entire resource.
+		Disabled map[string][]string
+		// Parent is the API the overlay applies to.
+		Parent *APIDefinition
 	}
 
 	// ResourceDefinition describes a REST resource.
@@ -144,6 +212,13 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// Versions lists the names of the API versions that expose the resource, empty
+		// means all versions.
+		Versions []string
+		// Middleware lists the middleware that apply to all the resource actions.
+		Middleware []*MiddlewareDefinition
+		// RateLimit overrides the API rate limit for all the resource actions if not nil.
+		RateLimit *RateLimitDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -204,6 +279,25 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Standard is true if the response definition comes from the goa default responses
 		Standard bool
+		// Filename is the name of the file downloaded by clients if the response is a file
+		// download. Occurrences of "{param}" are replaced with the values of the action params.
+		Filename string
+		// Location is the template of the Location header of redirect responses.
+		// Occurrences of ":param" are replaced with the values of the action params.
+		Location string
+		// HeaderTemplates indexes the templates of the header values computed from the
+		// response media type attributes by header name. Occurrences of "{attribute}" are
+		// replaced with the values of the attributes of the response body.
+		HeaderTemplates map[string]string
+		// Streaming is true if the response is a server-sent event stream whose events hold
+		// data rendered with the response media type.
+		Streaming bool
+		// Chunked is true if the response body is a collection that the action handler writes
+		// as a stream one element at a time, the body is sent with chunked transfer encoding.
+		Chunked bool
+		// Deltas is true if the server-sent events of the streaming response describe the
+		// changes made to a collection, see the Subscription DSL.
+		Deltas bool
 	}
 
 	// ResponseTemplateDefinition defines a response template.
@@ -245,12 +339,230 @@ type (
 		Payload *UserTypeDefinition
 		// PayloadOptional is true if the request payload is optional, false otherwise.
 		PayloadOptional bool
+		// PayloadMultipart is true if the request payload is encoded as a multipart form.
+		PayloadMultipart bool
+		// PayloadStreaming is true if the request payload is a collection that the action
+		// handler reads from the request body as a stream instead of being decoded up front.
+		PayloadStreaming bool
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
+		// Request cookies that need to be made available to action
+		Cookies *AttributeDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// SLO defines the service level objectives of the action if any
+		SLO *SLODefinition
+		// Concurrency caps the number of requests handled concurrently by the action if any
+		Concurrency *ConcurrencyDefinition
+		// RateLimit overrides the resource and API rate limits if not nil.
+		RateLimit *RateLimitDefinition
+		// ReplayProtected is true if requests must carry a nonce and timestamp that are
+		// validated by the replay protection middleware.
+		ReplayProtected bool
+		// DigestAlgorithm is the algorithm used to compute the Digest header value that
+		// requests must provide, empty if no digest is required.
+		DigestAlgorithm string
+		// AcceptRanges is true if the action supports range requests.
+		AcceptRanges bool
+		// Resumable is true if the action implements the tus resumable upload protocol.
+		Resumable bool
+		// SignedURL is true if the action may be invoked with pre-signed URLs.
+		SignedURL bool
+		// RenderHTML is true if the action renders its media type responses as HTML when
+		// the request prefers it.
+		RenderHTML bool
+		// VersionAttribute is the name of the media type attribute that holds the resource
+		// version when the action requires optimistic concurrency control.
+		VersionAttribute string
+		// Emits lists the names of the events published by the action.
+		Emits []string
+		// Saga describes the compensable multi-step operation run by the action if any.
+		Saga *SagaDefinition
+		// Aggregate describes the downstream actions composed by the action if any.
+		Aggregate *AggregateDefinition
+		// Enrichments lists the values derived from the action params that are resolved
+		// before the controller runs.
+		Enrichments []*EnrichmentDefinition
+		// Messages describes the messages exchanged over the connection if the action is a
+		// websocket endpoint declared with the WebSocket DSL.
+		Messages *WebSocketDefinition
+		// Middleware lists the middleware that apply to the action.
+		Middleware []*MiddlewareDefinition
+		// RequiredContext lists the names of the context values the action depends on.
+		RequiredContext []string
+		// Pagination describes how the action results are split into pages if the action
+		// is paginated.
+		Pagination *PaginationDefinition
+		// Expand lists the embeddable relations of the action responses that clients may
+		// request with the "expand" query string parameter added to the action params by
+		// Finalize. It is nil if the action defines its own "expand" parameter.
+		Expand []string
+		// Subscription describes the collection action whose changes the action streams if
+		// the action is a subscription.
+		Subscription *SubscriptionDefinition
+	}
+
+	// SubscriptionDefinition describes a subscription action: a variant of a collection action
+	// that streams the changes made to the collection, see the Subscription DSL.
+	SubscriptionDefinition struct {
+		// Parent is the subscription action.
+		Parent *ActionDefinition
+		// Source is the collection action the subscription is a variant of.
+		Source *ActionDefinition
+	}
+
+	// PaginationDefinition describes how the collection returned by an action is split into
+	// pages, see the Paginates DSL.
+	PaginationDefinition struct {
+		// Parent is the paginated action.
+		Parent *ActionDefinition
+		// Style is the pagination style, one of PagePagination or CursorPagination.
+		Style string
+		// DefaultPageSize is the number of results per page used when requests do not
+		// specify it.
+		DefaultPageSize int
+		// MaxPageSize is the maximum number of results per page.
+		MaxPageSize int
+	}
+
+	// ContextValueDefinition describes a request-scoped value stored in the request context by
+	// a middleware, e.g. the authenticated user or the tenant.
+	ContextValueDefinition struct {
+		// Parent is the API.
+		Parent *APIDefinition
+		// Name is the name of the value.
+		Name string
+		// Type is the type of the value.
+		Type DataType
+		// Description is the optional description of the value.
+		Description string
+	}
+
+	// MiddlewareDefinition describes a middleware mounted on the API, a resource or an action.
+	MiddlewareDefinition struct {
+		// Parent is the API, resource or action definition.
+		Parent dslengine.Definition
+		// Name is the name of the middleware.
+		Name string
+		// Description is the optional description of the middleware.
+		Description string
+		// Provides lists the names of the context values set by the middleware.
+		Provides []string
+	}
+
+	// WebSocketDefinition describes the messages exchanged over the connection established by
+	// a websocket action.
+	WebSocketDefinition struct {
+		// Parent is the websocket action.
+		Parent *ActionDefinition
+		// Inbound is the type of the messages sent by clients, nil if clients do not send
+		// messages.
+		Inbound *UserTypeDefinition
+		// Outbound is the identifier of the media type of the messages sent by the server,
+		// empty if the server does not send messages.
+		Outbound string
+		// OutboundView is the view used to render the messages sent by the server.
+		OutboundView string
+	}
+
+	// EnrichmentDefinition describes a value derived from an action param, e.g. the user
+	// record identified by a user ID param.
+	EnrichmentDefinition struct {
+		// Parent is the action.
+		Parent *ActionDefinition
+		// Name is the name of the derived value.
+		Name string
+		// Param is the name of the param used to look up the value.
+		Param string
+		// Description is the optional description of the value.
+		Description string
+		// TTL is the duration during which the values are cached, zero disables caching.
+		TTL time.Duration
+	}
+
+	// SagaDefinition describes a compensable multi-step operation.
+	SagaDefinition struct {
+		// Parent is the action running the saga.
+		Parent *ActionDefinition
+		// Name is the saga name.
+		Name string
+		// Steps lists the saga steps in execution order.
+		Steps []*SagaStepDefinition
+	}
+
+	// SagaStepDefinition describes a single saga step.
+	SagaStepDefinition struct {
+		// Name is the step name.
+		Name string
+		// Description is the optional step description.
+		Description string
+	}
+
+	// AggregateDefinition describes an action that composes the results of downstream actions
+	// into a composite media type.
+	AggregateDefinition struct {
+		// Parent is the aggregate action.
+		Parent *ActionDefinition
+		// MediaType is the identifier of the composite media type.
+		MediaType string
+		// Branches lists the downstream calls.
+		Branches []*AggregateBranchDefinition
+	}
+
+	// AggregateBranchDefinition describes a downstream call of an aggregate action.
+	AggregateBranchDefinition struct {
+		// Parent is the aggregate.
+		Parent *AggregateDefinition
+		// Name is the branch name.
+		Name string
+		// Service is the name of the service that implements the downstream action, empty
+		// if the action is defined by the same API.
+		Service string
+		// Resource is the name of the downstream action resource.
+		Resource string
+		// Action is the name of the downstream action.
+		Action string
+		// Timeout is the maximum duration of the call, zero means no timeout.
+		Timeout time.Duration
+		// Optional is true if the composite response can be built without the branch
+		// result.
+		Optional bool
+		// Mappings lists the fields of the branch result copied to the composite media type.
+		Mappings []*AggregateMappingDefinition
+	}
+
+	// AggregateMappingDefinition maps a field of the result of an aggregate branch to a field
+	// of the composite media type.
+	AggregateMappingDefinition struct {
+		// From is the dot separated path to the field of the branch result, the empty string
+		// denotes the whole result.
+		From string
+		// To is the dot separated path to the field of the composite media type.
+		To string
+	}
+
+	// SLODefinition defines the service level objectives of an action.
+	SLODefinition struct {
+		// LatencyP99 is the maximum 99th percentile latency of the action.
+		LatencyP99 time.Duration
+		// Availability is the minimum percentage of requests that must not fail with a
+		// server error, e.g. 99.9.
+		Availability float64
+	}
+
+	// ConcurrencyDefinition defines the maximum number of requests handled concurrently by an
+	// action and the queue of the requests waiting for a slot.
+	ConcurrencyDefinition struct {
+		// MaxInFlight is the maximum number of requests handled concurrently.
+		MaxInFlight int
+		// QueueSize is the maximum number of requests waiting for a slot, zero if requests
+		// received while the limit is reached are rejected right away.
+		QueueSize int
+		// QueueTimeout is the maximum duration requests wait in the queue, zero if requests
+		// wait until they are canceled.
+		QueueTimeout time.Duration
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -307,6 +619,22 @@ type (
 		Parent *ActionDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
+		// Aliases lists the alternate paths of the route, e.g. legacy paths.
+		Aliases []*RouteAliasDefinition
+		// Optional lists the path params captured by the optional trailing segment the
+		// route was expanded from, see SplitOptional.
+		Optional []string
+	}
+
+	// RouteAliasDefinition describes an alternate path of a route.
+	RouteAliasDefinition struct {
+		// Path is the alias path, it is relative to the same base path as the route path
+		// unless it starts with "//".
+		Path string
+		// Policy defines how requests made to the alias path are handled.
+		Policy AliasPolicy
+		// Parent is the route the alias applies to.
+		Parent *RouteDefinition
 	}
 
 	// AttributeDefinition defines a JSON object member with optional description, default
@@ -318,6 +646,8 @@ type (
 		Reference DataType
 		// Optional description
 		Description string
+		// Docs points to the attribute external and long form documentation
+		Docs *DocsDefinition
 		// Optional validations
 		Validation *dslengine.ValidationDefinition
 		// Metadata is a list of key/value pairs
@@ -333,6 +663,28 @@ type (
 		NonZeroAttributes map[string]bool
 		// DSLFunc contains the initialization DSL. This is used for user types.
 		DSLFunc func()
+		// Transitions lists the states that can be reached from each state for attributes
+		// that describe a lifecycle, indexed by originating state.
+		Transitions map[string][]string
+		// ComputedFrom lists the names of the sibling attributes used to compute the value
+		// of computed attributes.
+		ComputedFrom []string
+		// VisibleTo lists the scopes or roles granted to the callers that may see the
+		// attribute in responses.
+		VisibleTo []string
+		// Nullable is true if the attribute accepts null as a value distinct from the
+		// attribute being absent.
+		Nullable bool
+		// CollectionFormat defines how the elements of an array param are encoded in the
+		// request, one of CollectionFormatMulti, CollectionFormatCSV or
+		// CollectionFormatPipes. The empty string means CollectionFormatMulti.
+		CollectionFormat string
+		// ReadOnly is true if the attribute is set by the server only: it is stripped from
+		// the payloads that use the type.
+		ReadOnly bool
+		// WriteOnly is true if the attribute is sent by clients only: it cannot be rendered
+		// in responses.
+		WriteOnly bool
 	}
 
 	// ContainerDefinition defines a generic container definition that contains attributes.
@@ -643,6 +995,12 @@ func (a *APIDefinition) Finalize() {
 	if len(a.Produces) == 0 {
 		a.Produces = DefaultEncoders
 	}
+	a.IterateUserTypes(func(u *UserTypeDefinition) error {
+		if u.OneOf != nil {
+			u.finalizeOneOf()
+		}
+		return nil
+	})
 	a.IterateResources(func(r *ResourceDefinition) error {
 		returnsError := func(resp *ResponseDefinition) bool {
 			if resp.MediaType == ErrorMediaIdentifier {
@@ -675,6 +1033,79 @@ func (a *APIDefinition) Finalize() {
 	})
 }
 
+// ApplyOverlay merges the overlay for the given environment into the API definition: it overrides
+// the host, schemes, rate limit and feature flags and removes the disabled actions and resources.
+func (a *APIDefinition) ApplyOverlay(env string) error {
+	o, ok := a.Overlays[env]
+	if !ok {
+		var names []string
+		for n := range a.Overlays {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown overlay %#v, known overlays are %s", env, strings.Join(names, ", "))
+	}
+	if o.Host != "" {
+		a.Host = o.Host
+	}
+	if len(o.Schemes) > 0 {
+		a.Schemes = o.Schemes
+	}
+	if o.RateLimit != nil {
+		a.RateLimit = o.RateLimit
+	}
+	if len(o.Features) > 0 && a.Features == nil {
+		a.Features = make(map[string]bool)
+	}
+	for n, v := range o.Features {
+		a.Features[n] = v
+	}
+	for res, actions := range o.Disabled {
+		if len(actions) == 0 {
+			delete(a.Resources, res)
+			continue
+		}
+		if r, ok := a.Resources[res]; ok {
+			for _, n := range actions {
+				delete(r.Actions, n)
+			}
+		}
+	}
+	return nil
+}
+
+// ImportMediaTypes records the Go packages that define the media types with the given identifiers
+// in the "struct:pkg:path" metadata of the media types. Code generators reuse the types defined
+// in these packages instead of generating new ones.
+func (a *APIDefinition) ImportMediaTypes(pkgs map[string]string) error {
+	for id, pkg := range pkgs {
+		mt := a.MediaTypeWithIdentifier(id)
+		if mt == nil {
+			return fmt.Errorf("unknown media type %#v", id)
+		}
+		if mt.Metadata == nil {
+			mt.Metadata = make(dslengine.MetadataDefinition)
+		}
+		mt.Metadata["struct:pkg:path"] = []string{pkg}
+	}
+	return nil
+}
+
+// ImportClients records the import paths of the generated client packages of the services used
+// by the API. Code generators inject the corresponding clients in the API controllers.
+func (a *APIDefinition) ImportClients(pkgs map[string]string) error {
+	for name, pkg := range pkgs {
+		if _, ok := a.Uses[name]; !ok {
+			return fmt.Errorf("service %#v is not used by API %#v", name, a.Name)
+		}
+		if a.ClientPkgs == nil {
+			a.ClientPkgs = make(map[string]string)
+		}
+		a.ClientPkgs[name] = pkg
+	}
+	return nil
+}
+
 // NewResourceDefinition creates a resource definition but does not
 // execute the DSL.
 func NewResourceDefinition(name string, dsl func()) *ResourceDefinition {
@@ -1038,6 +1469,39 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 	return false
 }
 
+// CollectionSeparator returns the separator of the array param elements encoded in a single value,
+// "," for the csv format and "|" for the pipes format. It returns the empty string if each element
+// is encoded as a separate param.
+func (a *AttributeDefinition) CollectionSeparator() string {
+	switch a.CollectionFormat {
+	case CollectionFormatCSV:
+		return ","
+	case CollectionFormatPipes:
+		return "|"
+	}
+	return ""
+}
+
+// IsDeepObject returns true if the attribute type is a user type whose attributes are all
+// primitives or arrays of primitives. Action params of such types are encoded in query strings
+// using the deep object notation "name[key]=value".
+func (a *AttributeDefinition) IsDeepObject() bool {
+	ut, ok := a.Type.(*UserTypeDefinition)
+	if !ok || !ut.IsObject() {
+		return false
+	}
+	for _, att := range ut.ToObject() {
+		if att.Type.IsPrimitive() {
+			continue
+		}
+		if att.Type.IsArray() && att.Type.ToArray().ElemType.Type.IsPrimitive() {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
 // SetExample sets the custom example. SetExample also handles the case when the user doesn't
 // want any example or any auto-generated example.
 func (a *AttributeDefinition) SetExample(example interface{}) bool {
@@ -1084,6 +1548,10 @@ func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []stri
 	}
 
 	switch {
+	case IsOneOf(a.Type):
+		// Use the example of the first member type which sets the discriminator
+		a.Example = a.Type.(*UserTypeDefinition).OneOf[0].GenerateExample(rand, seen)
+
 	case a.Type.IsArray():
 		a.Example = a.arrayExample(rand, seen)
 
@@ -1240,6 +1708,10 @@ func (a *AttributeDefinition) inheritRecursive(parent *AttributeDefinition, seen
 			if att.Description == "" {
 				att.Description = patt.Description
 			}
+			if att.Docs == nil {
+				att.Docs = patt.Docs
+			}
+			att.Metadata = patt.Metadata.Merge(att.Metadata)
 			att.inheritValidations(patt)
 			if att.DefaultValue == nil {
 				att.DefaultValue = patt.DefaultValue
@@ -1284,6 +1756,11 @@ func (c *ContactDefinition) Context() string {
 	return "unnamed contact"
 }
 
+// Context returns the generic definition name used in error messages.
+func (o *OverlayDefinition) Context() string {
+	return fmt.Sprintf("overlay %#v", o.Name)
+}
+
 // Context returns the generic definition name used in error messages.
 func (l *LicenseDefinition) Context() string {
 	if l.Name != "" {
@@ -1340,6 +1817,42 @@ func (r *ResponseDefinition) Finalize() {
 	r.MediaType = mt.Identifier
 }
 
+// filenameParamRegex is the regular expression used to capture the params of download filenames.
+var filenameParamRegex = regexp.MustCompile(`{([a-zA-Z0-9_]+)}`)
+
+// FilenameParams returns the names of the action params that appear in the download filename
+// in order of appearance.
+func (r *ResponseDefinition) FilenameParams() []string {
+	matches := filenameParamRegex.FindAllStringSubmatch(r.Filename, -1)
+	params := make([]string, len(matches))
+	for i, m := range matches {
+		params[i] = m[1]
+	}
+	return params
+}
+
+// HeaderTemplateAttributes returns the names of the media type attributes that appear in the
+// template of the given header in order of appearance.
+func (r *ResponseDefinition) HeaderTemplateAttributes(header string) []string {
+	matches := filenameParamRegex.FindAllStringSubmatch(r.HeaderTemplates[header], -1)
+	atts := make([]string, len(matches))
+	for i, m := range matches {
+		atts[i] = m[1]
+	}
+	return atts
+}
+
+// LocationParams returns the names of the action params that appear in the redirect location in
+// order of appearance.
+func (r *ResponseDefinition) LocationParams() []string {
+	matches := WildcardRegex.FindAllStringSubmatch(r.Location, -1)
+	params := make([]string, len(matches))
+	for i, m := range matches {
+		params[i] = m[1]
+	}
+	return params
+}
+
 // Dup returns a copy of the response definition.
 func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	res := ResponseDefinition{
@@ -1348,10 +1861,21 @@ func (r *ResponseDefinition) Dup() *ResponseDefinition {
 		Description: r.Description,
 		MediaType:   r.MediaType,
 		ViewName:    r.ViewName,
+		Filename:    r.Filename,
+		Location:    r.Location,
+		Streaming:   r.Streaming,
+		Chunked:     r.Chunked,
+		Deltas:      r.Deltas,
 	}
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
 	}
+	if r.HeaderTemplates != nil {
+		res.HeaderTemplates = make(map[string]string, len(r.HeaderTemplates))
+		for n, t := range r.HeaderTemplates {
+			res.HeaderTemplates[n] = t
+		}
+	}
 	return &res
 }
 
@@ -1373,6 +1897,21 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if r.Filename == "" {
+		r.Filename = other.Filename
+	}
+	if r.Location == "" {
+		r.Location = other.Location
+	}
+	if !r.Streaming {
+		r.Streaming = other.Streaming
+	}
+	if !r.Chunked {
+		r.Chunked = other.Chunked
+	}
+	if !r.Deltas {
+		r.Deltas = other.Deltas
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1387,6 +1926,14 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 			}
 		}
 	}
+	for n, t := range other.HeaderTemplates {
+		if r.HeaderTemplates == nil {
+			r.HeaderTemplates = make(map[string]string)
+		}
+		if _, ok := r.HeaderTemplates[n]; !ok {
+			r.HeaderTemplates[n] = t
+		}
+	}
 }
 
 // Context returns the generic definition name used in error messages.
@@ -1411,6 +1958,138 @@ func (a *ActionDefinition) Context() string {
 	return prefix + suffix
 }
 
+// Context returns the generic definition name used in error messages.
+func (w *WebSocketDefinition) Context() string {
+	var prefix string
+	if w.Parent != nil {
+		prefix = w.Parent.Context() + " "
+	}
+	return prefix + "websocket"
+}
+
+// OutboundMediaType returns the media type of the messages sent by the server, nil if there is
+// none.
+func (w *WebSocketDefinition) OutboundMediaType() *MediaTypeDefinition {
+	if w.Outbound == "" {
+		return nil
+	}
+	return Design.MediaTypeWithIdentifier(w.Outbound)
+}
+
+// Context returns the generic definition name used in error messages.
+func (s *SagaDefinition) Context() string {
+	var prefix string
+	if s.Parent != nil {
+		prefix = s.Parent.Context() + " "
+	}
+	return fmt.Sprintf("%ssaga %#v", prefix, s.Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (e *EnrichmentDefinition) Context() string {
+	var prefix string
+	if e.Parent != nil {
+		prefix = e.Parent.Context() + " "
+	}
+	return fmt.Sprintf("%senrichment %#v", prefix, e.Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (c *ContextValueDefinition) Context() string {
+	return fmt.Sprintf("context value %#v", c.Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (s *SubscriptionDefinition) Context() string {
+	var prefix string
+	if s.Parent != nil {
+		prefix = s.Parent.Context() + " "
+	}
+	var source string
+	if s.Source != nil {
+		source = s.Source.Name
+	}
+	return fmt.Sprintf("%ssubscription to %#v", prefix, source)
+}
+
+// SourceResponse returns the OK response of the source collection action, nil if there is none.
+func (s *SubscriptionDefinition) SourceResponse() *ResponseDefinition {
+	if s.Source == nil {
+		return nil
+	}
+	if r, ok := s.Source.Responses["OK"]; ok {
+		return r
+	}
+	if s.Source.Parent != nil {
+		return s.Source.Parent.Responses["OK"]
+	}
+	return nil
+}
+
+// ElementMediaType returns the media type of the elements of the collection returned by the
+// source action, nil if the source action does not return a collection of media types defined in
+// the design.
+func (s *SubscriptionDefinition) ElementMediaType() *MediaTypeDefinition {
+	r := s.SourceResponse()
+	if r == nil {
+		return nil
+	}
+	mt := r.PaginatedMediaType()
+	if mt == nil {
+		return nil
+	}
+	elem, ok := mt.Type.ToArray().ElemType.Type.(*MediaTypeDefinition)
+	if !ok {
+		return nil
+	}
+	return elem
+}
+
+// Context returns the generic definition name used in error messages.
+func (p *PaginationDefinition) Context() string {
+	var prefix string
+	if p.Parent != nil {
+		prefix = p.Parent.Context() + " "
+	}
+	return fmt.Sprintf("%s%s pagination", prefix, p.Style)
+}
+
+// Context returns the generic definition name used in error messages.
+func (m *MiddlewareDefinition) Context() string {
+	var prefix string
+	if m.Parent != nil {
+		prefix = m.Parent.Context() + " "
+	}
+	return fmt.Sprintf("%smiddleware %#v", prefix, m.Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (a *AggregateDefinition) Context() string {
+	if a.Parent != nil {
+		return a.Parent.Context() + " aggregate"
+	}
+	return "aggregate"
+}
+
+// Context returns the generic definition name used in error messages.
+func (b *AggregateBranchDefinition) Context() string {
+	var prefix string
+	if b.Parent != nil {
+		prefix = b.Parent.Context() + " "
+	}
+	return fmt.Sprintf("%sbranch %#v", prefix, b.Name)
+}
+
+// OptionalPathParams returns the names of the path parameters captured by optional trailing route
+// segments. The parameters are not set when the request path does not include the segment.
+func (a *ActionDefinition) OptionalPathParams() []string {
+	var names []string
+	for _, r := range a.Routes {
+		names = append(names, r.Optional...)
+	}
+	return names
+}
+
 // PathParams returns the path parameters of the action across all its routes.
 func (a *ActionDefinition) PathParams() *AttributeDefinition {
 	obj := make(Object)
@@ -1425,6 +2104,45 @@ func (a *ActionDefinition) PathParams() *AttributeDefinition {
 	return &AttributeDefinition{Type: obj}
 }
 
+// ContextProviders returns the names of the middleware that set the context value with the given
+// name prior to the action running: the middleware declared on the API, on the action resource and
+// on the action as well as the security scheme that applies to the action.
+func (a *ActionDefinition) ContextProviders(name string) []string {
+	var providers []string
+	var mws []*MiddlewareDefinition
+	if Design != nil {
+		mws = append(mws, Design.Middleware...)
+	}
+	if a.Parent != nil {
+		mws = append(mws, a.Parent.Middleware...)
+	}
+	mws = append(mws, a.Middleware...)
+	for _, m := range mws {
+		for _, p := range m.Provides {
+			if p == name {
+				providers = append(providers, m.Name)
+				break
+			}
+		}
+	}
+	sec := a.Security
+	if sec == nil && a.Parent != nil {
+		sec = a.Parent.Security
+	}
+	if sec == nil && Design != nil {
+		sec = Design.Security
+	}
+	if sec != nil && sec.Scheme != nil && sec.Scheme.Kind != NoSecurityKind {
+		for _, p := range sec.Scheme.Provides {
+			if p == name {
+				providers = append(providers, sec.Scheme.SchemeName)
+				break
+			}
+		}
+	}
+	return providers
+}
+
 // AllParams returns the path and query string parameters of the action across all its routes.
 func (a *ActionDefinition) AllParams() *AttributeDefinition {
 	var res *AttributeDefinition
@@ -1456,6 +2174,22 @@ func (a *ActionDefinition) HasAbsoluteRoutes() bool {
 	return true
 }
 
+// EffectiveRateLimit returns the rate limit that applies to the action: the action rate limit if
+// any, the resource rate limit otherwise and the API rate limit as a last resort. It returns nil
+// if none is defined.
+func (a *ActionDefinition) EffectiveRateLimit() *RateLimitDefinition {
+	if a.RateLimit != nil {
+		return a.RateLimit
+	}
+	if a.Parent != nil && a.Parent.RateLimit != nil {
+		return a.Parent.RateLimit
+	}
+	if Design != nil {
+		return Design.RateLimit
+	}
+	return nil
+}
+
 // CanonicalScheme returns the preferred scheme for making requests. Favor secure schemes.
 func (a *ActionDefinition) CanonicalScheme() string {
 	if a.WebSocket() {
@@ -1527,9 +2261,12 @@ func (a *ActionDefinition) Finalize() {
 		a.Payload.Finalize()
 	}
 
+	a.finalizeSubscription()
 	a.mergeResponses()
+	a.finalizeExpand()
 	a.initImplicitParams()
 	a.initQueryParams()
+	a.finalizePagination()
 }
 
 // UserTypes returns all the user types used by the action payload and parameters.
@@ -1614,6 +2351,133 @@ func (a *ActionDefinition) mergeResponses() {
 	}
 }
 
+// finalizeSubscription inherits the params of the source action of subscription actions and
+// declares the OK response that streams the changes made to the source collection.
+func (a *ActionDefinition) finalizeSubscription() {
+	s := a.Subscription
+	if s == nil || s.Source == nil {
+		return
+	}
+	if s.Source.Params != nil {
+		a.Params = DupAtt(s.Source.Params).Merge(a.Params)
+	}
+	mt := s.ElementMediaType()
+	if mt == nil {
+		return
+	}
+	if _, ok := a.Responses["OK"]; ok {
+		return
+	}
+	if a.Responses == nil {
+		a.Responses = make(map[string]*ResponseDefinition)
+	}
+	a.Responses["OK"] = &ResponseDefinition{
+		Name:        "OK",
+		Status:      200,
+		Description: fmt.Sprintf("Stream of the changes made to the collection returned by the %s action", s.Source.Name),
+		MediaType:   mt.Identifier,
+		ViewName:    s.SourceResponse().ViewName,
+		Parent:      a,
+		Streaming:   true,
+		Deltas:      true,
+	}
+}
+
+// finalizePagination declares the pagination headers of the OK response of paginated actions and
+// flags its media type as paginated.
+func (a *ActionDefinition) finalizePagination() {
+	if a.Pagination == nil {
+		return
+	}
+	resp, ok := a.Responses["OK"]
+	if !ok {
+		return
+	}
+	if resp.Headers == nil {
+		resp.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	headers := resp.Headers.Type.ToObject()
+	if headers == nil {
+		return
+	}
+	if _, ok := headers["Link"]; !ok {
+		headers["Link"] = &AttributeDefinition{
+			Type:        String,
+			Description: "Links to the neighbouring pages (RFC 8288)",
+		}
+	}
+	if a.Pagination.Style == PagePagination {
+		if _, ok := headers["X-Total-Count"]; !ok {
+			headers["X-Total-Count"] = &AttributeDefinition{
+				Type:        Integer,
+				Description: "Total number of results",
+			}
+		}
+	}
+	if mt := resp.PaginatedMediaType(); mt != nil {
+		mt.Paginated = true
+	}
+}
+
+// finalizeExpand adds the "expand" query string parameter to the params of actions whose
+// responses declare embeddable relations.
+func (a *ActionDefinition) finalizeExpand() {
+	names := make(map[string]bool)
+	for _, r := range a.Responses {
+		mt := Design.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil {
+			continue
+		}
+		if mt.IsArray() {
+			if elem, ok := mt.ToArray().ElemType.Type.(*MediaTypeDefinition); ok {
+				mt = elem
+			}
+		}
+		for _, e := range mt.Embeddables {
+			names[e] = true
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	if _, ok := a.AllParams().Type.ToObject()["expand"]; ok {
+		return
+	}
+	a.Expand = make([]string, 0, len(names))
+	for n := range names {
+		a.Expand = append(a.Expand, n)
+	}
+	sort.Strings(a.Expand)
+	values := make([]interface{}, len(a.Expand))
+	for i, n := range a.Expand {
+		values[i] = n
+	}
+	if a.Params == nil {
+		a.Params = &AttributeDefinition{Type: Object{}}
+	}
+	a.Params.Type.ToObject()["expand"] = &AttributeDefinition{
+		Type: &Array{ElemType: &AttributeDefinition{
+			Type:       String,
+			Validation: &dslengine.ValidationDefinition{Values: values},
+		}},
+		Description:      "Embeddable relations to include in the response",
+		CollectionFormat: CollectionFormatCSV,
+	}
+}
+
+// PaginatedMediaType returns the media type of the response if it is a collection defined in the
+// design, nil otherwise.
+func (r *ResponseDefinition) PaginatedMediaType() *MediaTypeDefinition {
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if mt == nil || !mt.IsArray() {
+		return nil
+	}
+	return mt
+}
+
 // initImplicitParams creates params for path segments that don't have one.
 func (a *ActionDefinition) initImplicitParams() {
 	for _, ro := range a.Routes {
@@ -1664,10 +2528,16 @@ func (a *ActionDefinition) initQueryParams() {
 			a.Params = &AttributeDefinition{Type: Object{}}
 		}
 		a.Params.NonZeroAttributes = make(map[string]bool)
+		optional := make(map[string]bool)
+		for _, n := range a.OptionalPathParams() {
+			optional[n] = true
+		}
 		for _, route := range a.Routes {
 			pnames := route.Params()
 			for _, pname := range pnames {
-				a.Params.NonZeroAttributes[pname] = true
+				if !optional[pname] {
+					a.Params.NonZeroAttributes[pname] = true
+				}
 				delete(queryParams.Type.ToObject(), pname)
 				if queryParams.Validation != nil {
 					req := queryParams.Validation.Required
@@ -1775,12 +2645,53 @@ func (r *RouteDefinition) Context() string {
 	return fmt.Sprintf(`route %s "%s" of %s`, r.Verb, r.Path, r.Parent.Context())
 }
 
+// Context returns the generic definition name used in error messages.
+func (a *RouteAliasDefinition) Context() string {
+	return fmt.Sprintf(`alias "%s" of %s`, a.Path, a.Parent.Context())
+}
+
+// FullPath returns the alias full path computed the same way as the full path of its route.
+func (a *RouteAliasDefinition) FullPath() string {
+	r := &RouteDefinition{Verb: a.Parent.Verb, Path: a.Path, Parent: a.Parent.Parent}
+	return r.FullPath()
+}
+
 // Params returns the route parameters.
 // For example for the route "GET /foo/:fooID" Params returns []string{"fooID"}.
 func (r *RouteDefinition) Params() []string {
 	return ExtractWildcards(r.FullPath())
 }
 
+// SplitOptional removes the optional trailing segment from the route path, e.g.
+// "/items/:id(/versions/:version)?" becomes "/items/:id", and returns the route whose path includes
+// the segment, e.g. "/items/:id/versions/:version". The params captured by the segment are listed
+// in the Optional field of the returned route. SplitOptional returns nil if the route path does
+// not end with an optional segment.
+func (r *RouteDefinition) SplitOptional() *RouteDefinition {
+	loc := OptionalSegmentRegex.FindStringSubmatchIndex(r.Path)
+	if loc == nil {
+		return nil
+	}
+	segment := r.Path[loc[2]:loc[3]]
+	r.Path = r.Path[:loc[0]]
+	return &RouteDefinition{
+		Verb:     r.Verb,
+		Path:     r.Path + segment,
+		Parent:   r.Parent,
+		Metadata: r.Metadata,
+		Optional: ExtractWildcards(segment),
+	}
+}
+
+// CatchAll returns the name of the route catch-all parameter or the empty string if the route has
+// none. For example for the route "GET /files/*filepath" CatchAll returns "filepath".
+func (r *RouteDefinition) CatchAll() string {
+	if m := CatchAllRegex.FindStringSubmatch(r.FullPath()); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
 // FullPath returns the action full path computed by concatenating the API and resource base paths
 // with the action specific path.
 func (r *RouteDefinition) FullPath() string {
@@ -0,0 +1,55 @@
+package design
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// DeprecatedMetadata is the metadata key that marks actions and attributes as deprecated. The
+// optional value is the sunset date, i.e. the date after which the action or attribute may be
+// removed, using the YYYY-MM-DD format:
+//
+//	Action("list", func() {
+//		Metadata("deprecated", "2027-01-31") // use "search" instead
+//	})
+//
+// Deprecated actions are flagged in the Swagger specification and listed with their sunset date
+// in the changelog produced by goagen.
+const DeprecatedMetadata = "deprecated"
+
+// SunsetDateFormat is the layout of the sunset dates set with the DeprecatedMetadata metadata.
+const SunsetDateFormat = "2006-01-02"
+
+// Deprecation returns true if the action is deprecated and its sunset date if any.
+func (a *ActionDefinition) Deprecation() (deprecated bool, sunset string) {
+	return deprecation(a.Metadata)
+}
+
+// Deprecation returns true if the attribute is deprecated and its sunset date if any.
+func (a *AttributeDefinition) Deprecation() (deprecated bool, sunset string) {
+	return deprecation(a.Metadata)
+}
+
+// deprecation implements the Deprecation methods.
+func deprecation(md dslengine.MetadataDefinition) (bool, string) {
+	vals, ok := md[DeprecatedMetadata]
+	if !ok {
+		return false, ""
+	}
+	if len(vals) == 0 {
+		return true, ""
+	}
+	return true, vals[len(vals)-1]
+}
+
+// validateDeprecation checks that the sunset date set in md, if any, uses the YYYY-MM-DD format.
+func validateDeprecation(ctx string, def dslengine.Definition, md dslengine.MetadataDefinition, verr *dslengine.ValidationErrors) {
+	_, sunset := deprecation(md)
+	if sunset == "" {
+		return
+	}
+	if _, err := time.Parse(SunsetDateFormat, sunset); err != nil {
+		verr.Add(def, "%sinvalid %s metadata %#v, the sunset date must use the YYYY-MM-DD format", ctx, DeprecatedMetadata, sunset)
+	}
+}
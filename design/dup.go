@@ -43,13 +43,21 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 	dup := AttributeDefinition{
 		Type:              att.Type,
 		Description:       att.Description,
+		Docs:              att.Docs,
 		Validation:        valDup,
-		Metadata:          att.Metadata,
+		Metadata:          att.Metadata.Dup(),
 		DefaultValue:      att.DefaultValue,
 		NonZeroAttributes: att.NonZeroAttributes,
 		View:              att.View,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
+		Transitions:       att.Transitions,
+		ComputedFrom:      att.ComputedFrom,
+		VisibleTo:         att.VisibleTo,
+		Nullable:          att.Nullable,
+		CollectionFormat:  att.CollectionFormat,
+		ReadOnly:          att.ReadOnly,
+		WriteOnly:         att.WriteOnly,
 	}
 	return &dup
 }
@@ -164,13 +164,17 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 			}
 			return res
 		}(),
-		"cidr":    "192.168.100.14/24",
-		"regexp":  eg.r.faker.Characters(3) + ".*",
-		"rfc1123": time.Unix(int64(eg.r.Int())%1454957045, 0).Format(time.RFC1123), // to obtain a "fixed" rand
+		"cidr":     "192.168.100.14/24",
+		"currency": "USD",
+		"phone":    "+14155552671",
+		"regexp":   eg.r.faker.Characters(3) + ".*",
+		"rfc1123":  time.Unix(int64(eg.r.Int())%1454957045, 0).Format(time.RFC1123), // to obtain a "fixed" rand
+		"uuid":     eg.r.UUID().String(),
 	}[format]; ok {
 		return res
 	}
-	panic("Validation: unknown format '" + format + "'") // bug
+	// Custom formats, see RegisterFormat.
+	return nil
 }
 
 func (eg *exampleGenerator) hasPatternValidation() bool {
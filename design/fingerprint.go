@@ -0,0 +1,249 @@
+package design
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Fingerprint returns a digest of the API design. The generators embed the fingerprint in the
+// artifacts they produce so that a service can verify at startup that the generated code it
+// was compiled with and the specifications it serves were all produced from the same design,
+// see goa.Service.CheckDesign. The fingerprint only depends on the parts of the design that
+// affect the behavior of the generated code: changing a description or an example does not
+// change it.
+func (a *APIDefinition) Fingerprint() string {
+	h := sha256.New()
+	f := &fingerprinter{w: h}
+	f.api(a)
+	sum := h.Sum(nil)
+	return hex.EncodeToString(sum[:16])
+}
+
+// fingerprinter writes a canonical representation of a design to w. Maps are always written
+// sorted by key and user types are written by name so that the representation is deterministic
+// and recursive types do not cause infinite loops.
+type fingerprinter struct {
+	w io.Writer
+}
+
+// printf writes a line to the fingerprinter writer.
+func (f *fingerprinter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(f.w, format+"\n", args...)
+}
+
+// api writes the API definition.
+func (f *fingerprinter) api(a *APIDefinition) {
+	f.printf("api %s %s %s %v", a.Name, a.Version, a.BasePath, a.Schemes)
+	f.attribute("params", a.Params)
+	f.rateLimit(a.RateLimit)
+	for _, enc := range a.Consumes {
+		f.printf("consumes %v %s %s", enc.MIMETypes, enc.PackagePath, enc.Function)
+	}
+	for _, enc := range a.Produces {
+		f.printf("produces %v %s %s", enc.MIMETypes, enc.PackagePath, enc.Function)
+	}
+	a.IterateUserTypes(func(ut *UserTypeDefinition) error {
+		f.printf("type %s %v %v %s", ut.TypeName, ut.Enum, ut.Flags, ut.Discriminator)
+		for _, m := range ut.OneOf {
+			f.printf("oneof %s", m.TypeName)
+		}
+		f.attribute("", ut.AttributeDefinition)
+		return nil
+	})
+	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		f.printf("mediatype %s %s %s %v %v", mt.Identifier, mt.TypeName, mt.ContentType,
+			mt.Embeddables, mt.Versions)
+		f.attribute("", mt.AttributeDefinition)
+		for _, n := range sortedKeys(mt.Links) {
+			l := mt.Links[n]
+			f.printf("link %s %s %s", l.Name, l.View, l.URITemplate)
+		}
+		mt.IterateViews(func(v *ViewDefinition) error {
+			f.printf("view %s", v.Name)
+			f.attribute("", v.AttributeDefinition)
+			return nil
+		})
+		return nil
+	})
+	a.IterateResponses(func(r *ResponseDefinition) error {
+		f.response(r)
+		return nil
+	})
+	a.IterateResources(func(r *ResourceDefinition) error {
+		f.resource(r)
+		return nil
+	})
+}
+
+// resource writes the resource definition and its actions.
+func (f *fingerprinter) resource(r *ResourceDefinition) {
+	f.printf("resource %s %s %s %s %s %s %v %v", r.Name, r.BasePath, r.ParentName, r.MediaType,
+		r.DefaultViewName, r.CanonicalActionName, r.Schemes, r.Versions)
+	f.attribute("params", r.Params)
+	f.attribute("headers", r.Headers)
+	f.rateLimit(r.RateLimit)
+	for _, n := range sortedKeys(r.Responses) {
+		f.response(r.Responses[n])
+	}
+	r.IterateFileServers(func(fs *FileServerDefinition) error {
+		f.printf("files %s %s", fs.RequestPath, fs.FilePath)
+		return nil
+	})
+	r.IterateActions(func(a *ActionDefinition) error {
+		f.action(a)
+		return nil
+	})
+}
+
+// action writes the action definition.
+func (f *fingerprinter) action(a *ActionDefinition) {
+	f.printf("action %s %v", a.Name, a.Schemes)
+	for _, r := range a.Routes {
+		f.printf("route %s %s", r.Verb, r.Path)
+	}
+	f.attribute("params", a.Params)
+	f.attribute("query", a.QueryParams)
+	f.attribute("headers", a.Headers)
+	f.attribute("cookies", a.Cookies)
+	if a.Payload != nil {
+		f.printf("payload %s %v %v %v", a.Payload.TypeName, a.PayloadOptional, a.PayloadMultipart,
+			a.PayloadStreaming)
+		f.attribute("", a.Payload.AttributeDefinition)
+	}
+	f.metadata(a.Metadata)
+	for _, n := range sortedKeys(a.Responses) {
+		f.response(a.Responses[n])
+	}
+	f.printf("flags %v %s %v %v %v %v %s %v", a.ReplayProtected, a.DigestAlgorithm, a.AcceptRanges,
+		a.Resumable, a.SignedURL, a.RenderHTML, a.VersionAttribute, a.RequiredContext)
+	f.rateLimit(a.RateLimit)
+	if p := a.Pagination; p != nil {
+		f.printf("pagination %s %d %d", p.Style, p.DefaultPageSize, p.MaxPageSize)
+	}
+	if s := a.Subscription; s != nil && s.Source != nil {
+		f.printf("subscription %s", s.Source.Name)
+	}
+}
+
+// rateLimit writes the rate limit definition if not nil.
+func (f *fingerprinter) rateLimit(rl *RateLimitDefinition) {
+	if rl != nil {
+		f.printf("ratelimit %d %s", rl.Requests, rl.Period)
+	}
+}
+
+// response writes the response definition.
+func (f *fingerprinter) response(r *ResponseDefinition) {
+	var typeName string
+	if r.Type != nil {
+		typeName = r.Type.Name()
+	}
+	f.printf("response %s %d %s %s %s %s %s %v %v", r.Name, r.Status, r.MediaType, r.ViewName,
+		typeName, r.Filename, r.Location, r.Streaming, r.Chunked)
+	if r.Deltas {
+		f.printf("deltas")
+	}
+	f.attribute("headers", r.Headers)
+	if len(r.HeaderTemplates) > 0 {
+		f.json("templates", r.HeaderTemplates)
+	}
+}
+
+// attribute writes the attribute definition. User types are written by name, their definition
+// is written once by api.
+func (f *fingerprinter) attribute(name string, att *AttributeDefinition) {
+	if att == nil {
+		return
+	}
+	f.printf("attribute %s %s %s %v %s %v", name, typeSignature(att.Type), att.View, att.Nullable,
+		att.CollectionFormat, att.ComputedFrom)
+	if att.Validation != nil {
+		f.json("validation", att.Validation)
+	}
+	f.json("default", att.DefaultValue)
+	if len(att.Transitions) > 0 {
+		f.json("transitions", att.Transitions)
+	}
+	if att.ReadOnly || att.WriteOnly {
+		f.printf("access %v %v", att.ReadOnly, att.WriteOnly)
+	}
+	f.metadata(att.Metadata)
+	switch actual := att.Type.(type) {
+	case *UserTypeDefinition:
+		if actual.TypeName == "" {
+			f.attribute("", actual.AttributeDefinition)
+		}
+	case *MediaTypeDefinition:
+		if actual.TypeName == "" {
+			f.attribute("", actual.AttributeDefinition)
+		}
+	case Object:
+		actual.IterateAttributes(func(n string, at *AttributeDefinition) error {
+			f.attribute(n, at)
+			return nil
+		})
+	case *Array:
+		f.attribute("elem", actual.ElemType)
+	case *Hash:
+		f.attribute("key", actual.KeyType)
+		f.attribute("elem", actual.ElemType)
+	}
+}
+
+// metadata writes the metadata if not empty.
+func (f *fingerprinter) metadata(m map[string][]string) {
+	if len(m) > 0 {
+		f.json("metadata", m)
+	}
+}
+
+// json writes the JSON representation of v if v is not nil.
+func (f *fingerprinter) json(name string, v interface{}) {
+	if v == nil {
+		return
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		f.printf("%s %#v", name, v)
+		return
+	}
+	f.printf("%s %s", name, js)
+}
+
+// typeSignature returns the name of the data type, user types are identified by their type name.
+func typeSignature(t DataType) string {
+	switch actual := t.(type) {
+	case nil:
+		return "nil"
+	case *UserTypeDefinition:
+		if actual.TypeName != "" {
+			return actual.TypeName
+		}
+	case *MediaTypeDefinition:
+		if actual.TypeName != "" {
+			return actual.TypeName
+		}
+	}
+	return t.Name()
+}
+
+// sortedKeys returns the keys of the given map of definitions sorted alphabetically.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch actual := m.(type) {
+	case map[string]*LinkDefinition:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*ResponseDefinition:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+package design_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fingerprint", func() {
+	var api *APIDefinition
+
+	newAPI := func() *APIDefinition {
+		ut := &UserTypeDefinition{
+			TypeName: "Bottle",
+			AttributeDefinition: &AttributeDefinition{
+				Type: Object{
+					"name":    &AttributeDefinition{Type: String, Description: "bottle name"},
+					"vintage": &AttributeDefinition{Type: Integer},
+				},
+				Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+			},
+		}
+		res := &ResourceDefinition{Name: "bottle", BasePath: "/bottles"}
+		res.Actions = map[string]*ActionDefinition{
+			"create": {
+				Name:    "create",
+				Parent:  res,
+				Routes:  []*RouteDefinition{{Verb: "POST", Path: ""}},
+				Payload: ut,
+			},
+		}
+		return &APIDefinition{
+			Name:      "cellar",
+			Types:     map[string]*UserTypeDefinition{"Bottle": ut},
+			Resources: map[string]*ResourceDefinition{"bottle": res},
+		}
+	}
+
+	BeforeEach(func() {
+		api = newAPI()
+	})
+
+	It("is deterministic", func() {
+		Ω(api.Fingerprint()).Should(HaveLen(32))
+		Ω(api.Fingerprint()).Should(Equal(newAPI().Fingerprint()))
+	})
+
+	It("ignores descriptions", func() {
+		fp := api.Fingerprint()
+		api.Types["Bottle"].Type.ToObject()["name"].Description = "the name"
+		api.Description = "The cellar API"
+		Ω(api.Fingerprint()).Should(Equal(fp))
+	})
+
+	It("changes when a type changes", func() {
+		fp := api.Fingerprint()
+		api.Types["Bottle"].Type.ToObject()["vintage"].Type = String
+		Ω(api.Fingerprint()).ShouldNot(Equal(fp))
+	})
+
+	It("changes when a validation changes", func() {
+		fp := api.Fingerprint()
+		api.Types["Bottle"].Validation.Required = []string{"name", "vintage"}
+		Ω(api.Fingerprint()).ShouldNot(Equal(fp))
+	})
+
+	It("changes when a route changes", func() {
+		fp := api.Fingerprint()
+		api.Resources["bottle"].Actions["create"].Routes[0].Verb = "PUT"
+		Ω(api.Fingerprint()).ShouldNot(Equal(fp))
+	})
+})
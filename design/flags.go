@@ -0,0 +1,38 @@
+package design
+
+// FlagsFormat is the representation of the values of flags types in request and response
+// bodies, see the Flags and FlagsEncoding DSLs.
+type FlagsFormat string
+
+const (
+	// FlagsArray encodes flags values as the arrays of the names of the flags that are set,
+	// e.g. ["read", "write"]. This is the default encoding.
+	FlagsArray FlagsFormat = "array"
+
+	// FlagsInteger encodes flags values as integers whose bits are the flags, e.g. 3.
+	FlagsInteger FlagsFormat = "integer"
+)
+
+// MaxFlags is the maximum number of flags a flags type may define so that integer encoded values
+// are represented exactly by JSON numbers.
+const MaxFlags = 53
+
+// IsValid returns true if the encoding is one of FlagsArray or FlagsInteger.
+func (e FlagsFormat) IsValid() bool {
+	return e == FlagsArray || e == FlagsInteger
+}
+
+// IsFlags returns true if dt is a user type defined with the Flags DSL.
+func IsFlags(dt DataType) bool {
+	ut, ok := dt.(*UserTypeDefinition)
+	return ok && ut.Flags != nil
+}
+
+// FlagsWireFormat returns the encoding of the values of flags types in request and response
+// bodies.
+func (a *APIDefinition) FlagsWireFormat() FlagsFormat {
+	if a == nil || a.FlagsFormat == "" {
+		return FlagsArray
+	}
+	return a.FlagsFormat
+}
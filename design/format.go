@@ -0,0 +1,69 @@
+package design
+
+import "sort"
+
+// StandardFormats lists the formats supported by goa for use with the Format DSL, see
+// RegisterFormat to add custom formats.
+var StandardFormats = []string{
+	"cidr",
+	"currency",
+	"date-time",
+	"email",
+	"hostname",
+	"ipv4",
+	"ipv6",
+	"ip",
+	"mac",
+	"phone",
+	"regexp",
+	"rfc1123",
+	"uri",
+	"uuid",
+}
+
+// customFormats records the formats registered with RegisterFormat indexed by name.
+var customFormats = make(map[string]func(string) error)
+
+// RegisterFormat registers a custom format that attributes may use in Format validations, e.g.
+// "iban" or "semver". validator returns an error if the given value does not conform to the
+// format, it is used to validate the default values and examples defined in the design. Custom
+// formats must be registered before the design is validated, typically in an init function. The
+// service must register the same format with the goa package RegisterFormat function so that the
+// generated code validates the request values. Standard formats cannot be overridden.
+func RegisterFormat(name string, validator func(string) error) {
+	if IsStandardFormat(name) {
+		return
+	}
+	customFormats[name] = validator
+}
+
+// IsStandardFormat returns true if name is one of the StandardFormats.
+func IsStandardFormat(name string) bool {
+	for _, f := range StandardFormats {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSupportedFormat returns true if name is a standard format or a format registered with
+// RegisterFormat.
+func IsSupportedFormat(name string) bool {
+	if IsStandardFormat(name) {
+		return true
+	}
+	_, ok := customFormats[name]
+	return ok
+}
+
+// SupportedFormats returns the names of the standard formats followed by the sorted names of the
+// formats registered with RegisterFormat.
+func SupportedFormats() []string {
+	custom := make([]string, 0, len(customFormats))
+	for n := range customFormats {
+		custom = append(custom, n)
+	}
+	sort.Strings(custom)
+	return append(append([]string{}, StandardFormats...), custom...)
+}
@@ -0,0 +1,73 @@
+package design
+
+import "github.com/goadesign/goa/dslengine"
+
+var (
+	// Point is the built-in type for geographic points using WGS 84 coordinates. The generated
+	// code represents Point values with goa.Point.
+	Point = &UserTypeDefinition{
+		TypeName: "Point",
+		AttributeDefinition: &AttributeDefinition{
+			Type: Object{
+				"latitude":  coordinate("Latitude in degrees", 90),
+				"longitude": coordinate("Longitude in degrees", 180),
+			},
+			Description: "Geographic point",
+			Validation:  &dslengine.ValidationDefinition{Required: []string{"latitude", "longitude"}},
+		},
+	}
+
+	// BoundingBox is the built-in type for geographic areas delimited by two meridians and two
+	// parallels. The generated code represents BoundingBox values with goa.BoundingBox.
+	BoundingBox = &UserTypeDefinition{
+		TypeName: "BoundingBox",
+		AttributeDefinition: &AttributeDefinition{
+			Type: Object{
+				"west":  coordinate("Longitude of the western edge in degrees", 180),
+				"south": coordinate("Latitude of the southern edge in degrees", 90),
+				"east":  coordinate("Longitude of the eastern edge in degrees", 180),
+				"north": coordinate("Latitude of the northern edge in degrees", 90),
+			},
+			Description: "Geographic bounding box",
+			Validation:  &dslengine.ValidationDefinition{Required: []string{"west", "south", "east", "north"}},
+		},
+	}
+
+	// Proximity is the built-in type for the areas within a given distance of a point, see the
+	// Near DSL. The generated code represents Proximity values with goa.Proximity.
+	Proximity = &UserTypeDefinition{
+		TypeName: "Proximity",
+		AttributeDefinition: &AttributeDefinition{
+			Type: Object{
+				"latitude":  coordinate("Latitude of the center in degrees", 90),
+				"longitude": coordinate("Longitude of the center in degrees", 180),
+				"radius": &AttributeDefinition{
+					Type:        Number,
+					Description: "Distance to the center in meters",
+					Validation:  &dslengine.ValidationDefinition{Minimum: float64Ptr(0)},
+				},
+			},
+			Description: "Area within a distance of a geographic point",
+			Validation:  &dslengine.ValidationDefinition{Required: []string{"latitude", "longitude"}},
+		},
+	}
+)
+
+// IsGeo returns true if dt is one of the built-in Point, BoundingBox or Proximity types.
+func IsGeo(dt DataType) bool {
+	ut, ok := dt.(*UserTypeDefinition)
+	return ok && (ut == Point || ut == BoundingBox || ut == Proximity)
+}
+
+// coordinate returns the attribute describing a coordinate in the range [-max, max].
+func coordinate(description string, max float64) *AttributeDefinition {
+	return &AttributeDefinition{
+		Type:        Number,
+		Description: description,
+		Validation:  &dslengine.ValidationDefinition{Minimum: float64Ptr(-max), Maximum: float64Ptr(max)},
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,60 @@
+package design
+
+import "github.com/goadesign/goa/dslengine"
+
+// MoneyFormat is the representation of the amounts of Money values in request and response
+// bodies, see the MoneyEncoding DSL.
+type MoneyFormat string
+
+const (
+	// MoneyMinorUnits encodes amounts as integers in the minor units of the currency, e.g.
+	// {"amount": 1234, "currency": "USD"} for 12.34 US dollars. This is the default format.
+	MoneyMinorUnits MoneyFormat = "minor"
+
+	// MoneyDecimal encodes amounts as decimal strings, e.g.
+	// {"amount": "12.34", "currency": "USD"}.
+	MoneyDecimal MoneyFormat = "decimal"
+)
+
+// Money is the built-in type for monetary amounts. Money values consist of an amount and an
+// ISO 4217 currency code. The generated code represents them with goa.Money - or
+// goa.DecimalMoney if the API uses the MoneyDecimal format - whose amounts are integers in the
+// minor units of the currency so that the arithmetic is exact.
+var Money = &UserTypeDefinition{
+	TypeName: "Money",
+	AttributeDefinition: &AttributeDefinition{
+		Type: Object{
+			"amount": &AttributeDefinition{
+				Type:        Integer,
+				Description: "Amount in minor units of the currency",
+			},
+			"currency": &AttributeDefinition{
+				Type:        String,
+				Description: "ISO 4217 currency code",
+				Validation:  &dslengine.ValidationDefinition{Format: "currency"},
+			},
+		},
+		Description: "Monetary amount",
+		Validation:  &dslengine.ValidationDefinition{Required: []string{"amount", "currency"}},
+	},
+}
+
+// IsValid returns true if the format is one of MoneyMinorUnits or MoneyDecimal.
+func (f MoneyFormat) IsValid() bool {
+	return f == MoneyMinorUnits || f == MoneyDecimal
+}
+
+// IsMoney returns true if dt is the built-in Money type.
+func IsMoney(dt DataType) bool {
+	ut, ok := dt.(*UserTypeDefinition)
+	return ok && ut == Money
+}
+
+// MoneyWireFormat returns the representation of the amounts of Money values in request and
+// response bodies.
+func (a *APIDefinition) MoneyWireFormat() MoneyFormat {
+	if a == nil || a.MoneyFormat == "" {
+		return MoneyMinorUnits
+	}
+	return a.MoneyFormat
+}
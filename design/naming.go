@@ -0,0 +1,105 @@
+package design
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NamingConvention is a convention for the names of the attributes of the API types, media
+// types and payloads, see the AttributeNaming and WireNaming DSLs.
+type NamingConvention string
+
+const (
+	// SnakeCase is the naming convention where names consist of lowercase words separated
+	// with underscores, e.g. "first_name".
+	SnakeCase NamingConvention = "snake_case"
+
+	// CamelCase is the naming convention where names consist of words starting with an
+	// uppercase letter except for the first one, e.g. "firstName".
+	CamelCase NamingConvention = "camelCase"
+)
+
+var (
+	snakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	camelCaseRegex = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+)
+
+// IsValid returns true if the naming convention is one of SnakeCase or CamelCase.
+func (c NamingConvention) IsValid() bool {
+	return c == SnakeCase || c == CamelCase
+}
+
+// Follows returns true if name follows the naming convention.
+func (c NamingConvention) Follows(name string) bool {
+	switch c {
+	case SnakeCase:
+		return snakeCaseRegex.MatchString(name)
+	case CamelCase:
+		return camelCaseRegex.MatchString(name)
+	}
+	return true
+}
+
+// Apply converts name to the naming convention, e.g. "first_name" to "firstName" with CamelCase
+// and "firstName" or "FirstName" to "first_name" with SnakeCase. Acronyms are considered single
+// words so that "userID" becomes "user_id". Apply returns name unchanged if the naming
+// convention is not valid.
+func (c NamingConvention) Apply(name string) string {
+	words := nameWords(name)
+	if len(words) == 0 || !c.IsValid() {
+		return name
+	}
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	if c == SnakeCase {
+		return strings.Join(words, "_")
+	}
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
+
+// WireName returns the name used in request and response bodies for the attribute with the given
+// name, see the WireNaming DSL.
+func (a *APIDefinition) WireName(name string) string {
+	if a == nil || a.WireNaming == "" {
+		return name
+	}
+	return a.WireNaming.Apply(name)
+}
+
+// nameWords splits name into words. Words are separated by underscores, dashes and spaces or
+// start with an uppercase letter that follows a lowercase letter or a digit or that precedes a
+// lowercase letter in a sequence of uppercase letters.
+func nameWords(name string) []string {
+	var (
+		words []string
+		word  []rune
+		runes = []rune(name)
+	)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
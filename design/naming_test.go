@@ -0,0 +1,44 @@
+package design_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NamingConvention", func() {
+	Context("Apply", func() {
+		It("converts names to camelCase", func() {
+			Ω(CamelCase.Apply("first_name")).Should(Equal("firstName"))
+			Ω(CamelCase.Apply("FirstName")).Should(Equal("firstName"))
+			Ω(CamelCase.Apply("user_ID")).Should(Equal("userId"))
+		})
+
+		It("converts names to snake_case", func() {
+			Ω(SnakeCase.Apply("firstName")).Should(Equal("first_name"))
+			Ω(SnakeCase.Apply("userID")).Should(Equal("user_id"))
+			Ω(SnakeCase.Apply("HTTPServer")).Should(Equal("http_server"))
+			Ω(SnakeCase.Apply("first-name")).Should(Equal("first_name"))
+			Ω(SnakeCase.Apply("address2Line")).Should(Equal("address2_line"))
+		})
+
+		It("leaves names unchanged with invalid conventions", func() {
+			Ω(NamingConvention("kebab").Apply("firstName")).Should(Equal("firstName"))
+		})
+	})
+
+	Context("Follows", func() {
+		It("checks snake_case names", func() {
+			Ω(SnakeCase.Follows("first_name")).Should(BeTrue())
+			Ω(SnakeCase.Follows("name")).Should(BeTrue())
+			Ω(SnakeCase.Follows("firstName")).Should(BeFalse())
+			Ω(SnakeCase.Follows("first__name")).Should(BeFalse())
+		})
+
+		It("checks camelCase names", func() {
+			Ω(CamelCase.Follows("firstName")).Should(BeTrue())
+			Ω(CamelCase.Follows("first_name")).Should(BeFalse())
+			Ω(CamelCase.Follows("FirstName")).Should(BeFalse())
+		})
+	})
+})
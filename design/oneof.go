@@ -0,0 +1,47 @@
+package design
+
+import "github.com/goadesign/goa/dslengine"
+
+// DefaultDiscriminator is the name of the discriminator attribute of the types defined with the
+// OneOf DSL that do not use the Discriminator DSL.
+const DefaultDiscriminator = "type"
+
+// IsOneOf returns true if dt is a user type defined with the OneOf DSL.
+func IsOneOf(dt DataType) bool {
+	ut, ok := dt.(*UserTypeDefinition)
+	return ok && ut.OneOf != nil
+}
+
+// DiscriminatorValues returns the values of the discriminator attribute that identify the given
+// member type of the OneOf type u, that is the values of the member attribute Enum validation.
+func (u *UserTypeDefinition) DiscriminatorValues(member *UserTypeDefinition) []interface{} {
+	obj := member.ToObject()
+	if obj == nil {
+		return nil
+	}
+	att, ok := obj[u.Discriminator]
+	if !ok || att.Validation == nil {
+		return nil
+	}
+	return att.Validation.Values
+}
+
+// finalizeOneOf lists the values of the discriminator attributes of the member types in the
+// Enum validation of the OneOf type discriminator attribute and uses the example of the first
+// member type as example. It is called once all the DSLs have run.
+func (u *UserTypeDefinition) finalizeOneOf() {
+	var values []interface{}
+	for _, m := range u.OneOf {
+		values = append(values, u.DiscriminatorValues(m)...)
+	}
+	if att, ok := u.ToObject()[u.Discriminator]; ok && values != nil {
+		if att.Validation == nil {
+			att.Validation = &dslengine.ValidationDefinition{}
+		}
+		att.Validation.Values = values
+		att.Example = nil
+	}
+	if len(u.OneOf) > 0 {
+		u.Example = u.OneOf[0].GenerateExample(Design.RandomGenerator(), nil)
+	}
+}
@@ -0,0 +1,32 @@
+package design
+
+import "regexp"
+
+// PhoneRegionMetadata is the metadata key that sets the ISO 3166-1 alpha-2 region used to
+// interpret the numbers in national format of attributes with the "phone" format, e.g.:
+//
+//	Attribute("mobile", String, func() {
+//		Format("phone")
+//		Metadata("phone:region", "FR") // accepts "06 12 34 56 78" as well as "+33612345678"
+//	})
+//
+// Attributes with no region only accept numbers in international format. The generated code
+// normalizes the numbers to the E.164 format when decoding requests.
+const PhoneRegionMetadata = "phone:region"
+
+// regionRegex matches ISO 3166-1 alpha-2 region codes.
+var regionRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// IsPhoneNumber returns true if the attribute is a string attribute with the "phone" format.
+func (a *AttributeDefinition) IsPhoneNumber() bool {
+	return a.Type == String && a.Validation != nil && a.Validation.Format == "phone"
+}
+
+// PhoneRegion returns the region set with the PhoneRegionMetadata metadata, empty if there is
+// none.
+func (a *AttributeDefinition) PhoneRegion() string {
+	if r := a.Metadata[PhoneRegionMetadata]; len(r) > 0 {
+		return r[0]
+	}
+	return ""
+}
@@ -71,6 +71,8 @@ type SecuritySchemeDefinition struct {
 	AuthorizationURL string `json:"authorization_url,omitempty"`
 	// Metadata is a list of key/value pairs
 	Metadata dslengine.MetadataDefinition
+	// Provides lists the names of the context values set by the scheme middleware.
+	Provides []string `json:"-"`
 }
 
 // DSL returns the DSL function
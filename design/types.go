@@ -113,6 +113,18 @@ type (
 		*AttributeDefinition
 		// Name of type
 		TypeName string
+		// Enum is true if the type was defined with the top level Enum DSL, the type
+		// underlying data type is then a primitive and its validation lists the enum values.
+		Enum bool
+		// Flags lists the names of the flags of the type if it was defined with the Flags
+		// DSL, the type underlying data type is then an integer whose bits are the flags.
+		Flags []string
+		// OneOf lists the member types of the type if it was defined with the OneOf DSL, the
+		// values of the type are values of exactly one of the member types.
+		OneOf []*UserTypeDefinition
+		// Discriminator is the name of the attribute of the member types of a OneOf type
+		// whose value identifies the member type.
+		Discriminator string
 	}
 
 	// MediaTypeDefinition describes the rendering of a resource using property and link
@@ -135,6 +147,18 @@ type (
 		Views map[string]*ViewDefinition
 		// Resource this media type is the canonical representation for if any
 		Resource *ResourceDefinition
+		// Embeddables lists the names of the related media type attributes that are only
+		// rendered when requested with the "expand" parameter.
+		Embeddables []string
+		// Versions lists the names of the API versions the media type is available in,
+		// empty means all versions.
+		Versions []string
+		// Geometry is the name of the Point or BoundingBox attribute used as the geometry
+		// of the GeoJSON features that represent the media type, if any.
+		Geometry string
+		// Paginated is true if the media type is a collection returned by paginated
+		// actions, see the Paginates DSL.
+		Paginated bool
 	}
 )
 
@@ -163,6 +187,10 @@ const (
 	UserTypeKind
 	// MediaTypeKind represents a media type.
 	MediaTypeKind
+	// FileKind represents a file uploaded in a multipart form.
+	FileKind
+	// DateKind represents a JSON string that is parsed as a goa.Date
+	DateKind
 )
 
 const (
@@ -182,14 +210,34 @@ const (
 	// DateTime expects an RFC3339 formatted value.
 	DateTime = Primitive(DateTimeKind)
 
+	// Date is the type for a JSON string parsed as a goa.Date
+	// Date expects a full-date value as defined by RFC3339, e.g. "2017-03-21". Request
+	// parameters, headers and bodies also accept RFC3339 date-time values. The generated
+	// structs use goa.Date fields which the JSON encoder encodes as full-dates.
+	Date = Primitive(DateKind)
+
 	// UUID is the type for a JSON string parsed as a Go uuid.UUID
 	// UUID expects an RFC4122 formatted value.
 	UUID = Primitive(UUIDKind)
 
-	// Any is the type for an arbitrary JSON value (interface{} in Go).
+	// Any is the type for an arbitrary JSON value (interface{} in Go). Any attributes are
+	// used for values whose structure is not known at design time, the generated code does
+	// not validate their content. Use the "struct:field:type" metadata to decode them as
+	// json.RawMessage instead:
+	//
+	//	Attribute("data", Any, func() {
+	//		Metadata("struct:field:type", "json.RawMessage", "encoding/json")
+	//	})
 	Any = Primitive(AnyKind)
+
+	// File is the type for a file uploaded in a multipart form (goa.FormFile in Go). File
+	// attributes can only be used in the payloads of actions that use MultipartForm.
+	File = Primitive(FileKind)
 )
 
+// DateLayout is the layout of Date values as used by time.Parse and time.Format.
+const DateLayout = "2006-01-02"
+
 // DataType implementation
 
 // Kind implements DataKind.
@@ -204,10 +252,12 @@ func (p Primitive) Name() string {
 		return "integer"
 	case Number:
 		return "number"
-	case String, DateTime, UUID:
+	case String, DateTime, Date, UUID:
 		return "string"
 	case Any:
 		return "any"
+	case File:
+		return "file"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -239,6 +289,15 @@ func (p Primitive) ToHash() *Hash { return nil }
 
 // CanHaveDefault returns whether the primitive can have a default value.
 func (p Primitive) CanHaveDefault() (ok bool) {
+	switch p {
+	case Boolean, Integer, Number, String, DateTime, Date:
+		ok = true
+	}
+	return
+}
+
+// CanBeNullable returns whether attributes of the primitive type can be nullable.
+func (p Primitive) CanBeNullable() (ok bool) {
 	switch p {
 	case Boolean, Integer, Number, String, DateTime:
 		ok = true
@@ -248,7 +307,7 @@ func (p Primitive) CanHaveDefault() (ok bool) {
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != Date && p != UUID && p != Any && p != File {
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -262,13 +321,20 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 	case float32, float64:
 		return p == Number
 	case string:
-		if p == String {
+		if p == String || p == File {
 			return true
 		}
 		if p == DateTime {
 			_, err := time.Parse(time.RFC3339, val.(string))
 			return err == nil
 		}
+		if p == Date {
+			if _, err := time.Parse(DateLayout, val.(string)); err == nil {
+				return true
+			}
+			_, err := time.Parse(time.RFC3339, val.(string))
+			return err == nil
+		}
 		if p == UUID {
 			_, err := uuid.FromString(val.(string))
 			return err == nil
@@ -292,11 +358,15 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 		return r.String()
 	case DateTime:
 		return r.DateTime()
+	case Date:
+		return r.DateTime().Format(DateLayout) // Generate string so that it is not marshaled as a date-time
 	case UUID:
 		return r.UUID().String() // Generate string to can be JSON marshaled
 	case Any:
 		// to not make it too complicated, pick one of the primitive types
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
+	case File:
+		return r.String() // File name
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -635,6 +705,12 @@ func NewUserTypeDefinition(name string, dsl func()) *UserTypeDefinition {
 // Kind implements DataKind.
 func (u *UserTypeDefinition) Kind() Kind { return UserTypeKind }
 
+// IsEnum returns true if dt is a user type defined with the top level Enum DSL.
+func IsEnum(dt DataType) bool {
+	ut, ok := dt.(*UserTypeDefinition)
+	return ok && ut.Enum
+}
+
 // Name returns the JSON type name.
 func (u *UserTypeDefinition) Name() string { return u.Type.Name() }
 
@@ -818,6 +894,15 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 		Parent:              p,
 	}}
 
+	for _, e := range m.Embeddables {
+		if _, ok := viewObj[e]; ok {
+			p.Embeddables = append(p.Embeddables, e)
+		}
+	}
+	if _, ok := viewObj[m.Geometry]; ok {
+		p.Geometry = m.Geometry
+	}
+
 	ProjectedMediaTypes[canonical] = p
 	projectedObj := p.Type.ToObject()
 	mtObj := m.Type.ToObject()
@@ -1033,7 +1118,14 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf("")
 	case DateTimeKind:
 		return reflect.TypeOf(time.Time{})
-	case ObjectKind, UserTypeKind, MediaTypeKind:
+	case DateKind:
+		return reflect.TypeOf("")
+	case UserTypeKind:
+		if ut := dtype.(*UserTypeDefinition); ut.IsPrimitive() {
+			return toReflectType(ut.Type)
+		}
+		return reflect.TypeOf(map[string]interface{}{})
+	case ObjectKind, MediaTypeKind:
 		return reflect.TypeOf(map[string]interface{}{})
 	case ArrayKind:
 		return reflect.SliceOf(toReflectType(dtype.ToArray().ElemType.Type))
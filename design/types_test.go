@@ -603,3 +603,22 @@ var _ = Describe("GenerateExample", func() {
 		})
 	})
 })
+
+var _ = Describe("Date", func() {
+	It("is a string", func() {
+		Ω(Date.Name()).Should(Equal("string"))
+		Ω(Date.CanHaveDefault()).Should(BeTrue())
+	})
+
+	It("is compatible with full-date and date-time values", func() {
+		Ω(Date.IsCompatible("2017-03-21")).Should(BeTrue())
+		Ω(Date.IsCompatible("2017-03-21T10:30:00Z")).Should(BeTrue())
+		Ω(Date.IsCompatible("21/03/2017")).Should(BeFalse())
+		Ω(Date.IsCompatible(20170321)).Should(BeFalse())
+	})
+
+	It("generates full-date examples", func() {
+		ex := Date.GenerateExample(NewRandomGenerator("date"), nil)
+		Ω(ex).Should(MatchRegexp(`^\d{4}-\d{2}-\d{2}$`))
+	})
+})
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/goadesign/goa/dslengine"
 )
@@ -85,6 +86,12 @@ func (a *APIDefinition) Validate() error {
 	a.validateLicense(verr)
 	a.validateDocs(verr)
 	a.validateOrigins(verr)
+	a.validateAliases(verr)
+	a.validateOverlays(verr)
+	a.validateVersions(verr)
+	a.validateNaming(verr)
+	a.validateContextValues(verr)
+	validateSecurity(a, a.Security, verr)
 
 	var allRoutes []*routeInfo
 	a.IterateResources(func(r *ResourceDefinition) error {
@@ -199,6 +206,244 @@ func (a *APIDefinition) validateOrigins(verr *dslengine.ValidationErrors) {
 	}
 }
 
+// validateAliases checks that route aliases do not conflict with routes or other aliases.
+func (a *APIDefinition) validateAliases(verr *dslengine.ValidationErrors) {
+	key := func(verb, path string) string {
+		return verb + " " + WildcardRegex.ReplaceAllLiteralString(path, "/*")
+	}
+	routes := make(map[string]dslengine.Definition)
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			for _, ro := range ac.Routes {
+				routes[key(ro.Verb, ro.FullPath())] = ro
+			}
+			return nil
+		})
+	})
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			for _, ro := range ac.Routes {
+				for _, al := range ro.Aliases {
+					k := key(ro.Verb, al.FullPath())
+					if other, ok := routes[k]; ok {
+						verr.Add(al, "alias path %s conflicts with %s", al.FullPath(), other.Context())
+						continue
+					}
+					routes[k] = al
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (a *APIDefinition) validateOverlays(verr *dslengine.ValidationErrors) {
+	for _, o := range a.Overlays {
+		verr.Merge(o.Validate())
+	}
+}
+
+// validateContextValues checks that the context values are named and typed and that the values set
+// by the API middleware and by the security schemes are declared.
+func (a *APIDefinition) validateContextValues(verr *dslengine.ValidationErrors) {
+	for n, c := range a.ContextValues {
+		if n == "" {
+			verr.Add(c, "context value name cannot be empty")
+		}
+		if c.Type == nil {
+			verr.Add(c, "context value type cannot be nil")
+		}
+	}
+	for _, m := range a.Middleware {
+		verr.Merge(m.Validate())
+	}
+	for _, sc := range a.SecuritySchemes {
+		for _, p := range sc.Provides {
+			if _, ok := a.ContextValues[p]; !ok {
+				verr.Add(sc, "unknown context value %#v, context values must be declared with ContextValue", p)
+			}
+		}
+	}
+}
+
+// validateNaming checks that the naming conventions are valid and that the names of the
+// attributes of the API types, media types and payloads follow the AttributeNaming convention.
+func (a *APIDefinition) validateNaming(verr *dslengine.ValidationErrors) {
+	if a.FlagsFormat != "" && !a.FlagsFormat.IsValid() {
+		verr.Add(a, "invalid flags format %#v, must be one of %#v or %#v", a.FlagsFormat, FlagsArray, FlagsInteger)
+	}
+	if a.MoneyFormat != "" && !a.MoneyFormat.IsValid() {
+		verr.Add(a, "invalid money format %#v, must be one of %#v or %#v", a.MoneyFormat, MoneyMinorUnits, MoneyDecimal)
+	}
+	if a.WireNaming != "" && !a.WireNaming.IsValid() {
+		verr.Add(a, "invalid wire naming convention %#v, must be one of %#v or %#v", a.WireNaming, SnakeCase, CamelCase)
+	}
+	if a.AttributeNaming == "" {
+		return
+	}
+	if !a.AttributeNaming.IsValid() {
+		verr.Add(a, "invalid attribute naming convention %#v, must be one of %#v or %#v", a.AttributeNaming, SnakeCase, CamelCase)
+		return
+	}
+	checked := make(map[*AttributeDefinition]bool)
+	check := func(def dslengine.Definition, att *AttributeDefinition) {
+		att.Walk(func(at *AttributeDefinition) error {
+			o, ok := at.Type.(Object)
+			if !ok || checked[at] {
+				return nil
+			}
+			checked[at] = true
+			return o.IterateAttributes(func(n string, _ *AttributeDefinition) error {
+				if !a.AttributeNaming.Follows(n) {
+					verr.Add(def, "attribute name %#v does not follow the %s naming convention, use %#v instead",
+						n, a.AttributeNaming, a.AttributeNaming.Apply(n))
+				}
+				return nil
+			})
+		})
+	}
+	a.IterateUserTypes(func(ut *UserTypeDefinition) error {
+		check(ut, ut.AttributeDefinition)
+		return nil
+	})
+	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		check(mt, mt.AttributeDefinition)
+		return nil
+	})
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			if ac.Payload != nil {
+				check(ac, ac.Payload.AttributeDefinition)
+			}
+			return nil
+		})
+	})
+}
+
+// validateVersions checks that the API versions define how requests select them and that the
+// resources and media types only refer to existing versions. It also checks that the responses
+// of the actions of a resource only use media types available in the versions of the resource.
+func (a *APIDefinition) validateVersions(verr *dslengine.ValidationErrors) {
+	prefixes := make(map[string]string)
+	a.IterateVersions(func(v *VersionDefinition) error {
+		verr.Merge(v.Validate())
+		if v.Scheme == VersionPath {
+			if other, ok := prefixes[v.Key]; ok {
+				verr.Add(v, "path prefix %#v is already used by version %#v", v.Key, other)
+			}
+			prefixes[v.Key] = v.Name
+		}
+		return nil
+	})
+	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
+		for _, n := range mt.Versions {
+			if _, ok := a.Versions[n]; !ok {
+				verr.Add(mt, "unknown version %#v", n)
+			}
+		}
+		return nil
+	})
+	a.IterateResources(func(r *ResourceDefinition) error {
+		for _, n := range r.Versions {
+			if _, ok := a.Versions[n]; !ok {
+				verr.Add(r, "unknown version %#v", n)
+			}
+		}
+		if p := r.Parent(); p != nil {
+			for _, n := range r.Versions {
+				if !p.SupportsVersion(n) {
+					verr.Add(r, "version %#v is not exposed by parent resource %#v", n, p.Name)
+				}
+			}
+		}
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			return ac.IterateResponses(func(resp *ResponseDefinition) error {
+				mt := a.MediaTypeWithIdentifier(resp.MediaType)
+				if mt == nil {
+					return nil
+				}
+				a.IterateVersions(func(v *VersionDefinition) error {
+					if r.SupportsVersion(v.Name) && !mt.SupportsVersion(v.Name) {
+						verr.Add(resp, "media type %#v is not available in version %#v", mt.Identifier, v.Name)
+					}
+					return nil
+				})
+				return nil
+			})
+		})
+	})
+}
+
+// Validate checks that the version defines how requests select it.
+func (v *VersionDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	switch v.Scheme {
+	case "":
+		verr.Add(v, "version must be selected with a path prefix, a header or a query string parameter, use BasePath, Header or Query")
+	case VersionPath:
+		if !strings.HasPrefix(v.Key, "/") || v.Key == "/" {
+			verr.Add(v, "invalid path prefix %#v, must start with / and not be empty", v.Key)
+		}
+	case VersionHeader, VersionQuery:
+		if v.Key == "" {
+			verr.Add(v, "%s name cannot be empty", v.Scheme)
+		}
+	default:
+		verr.Add(v, "invalid version scheme %#v", v.Scheme)
+	}
+	return verr.AsError()
+}
+
+// Validate tests whether the overlay applies to the API: the feature flags it overrides must be
+// declared by the API and the actions it disables must exist and must not be required by the
+// remaining resources.
+func (o *OverlayDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	for n := range o.Features {
+		if _, ok := o.Parent.Features[n]; !ok {
+			verr.Add(o, "feature %#v is not declared by the API", n)
+		}
+	}
+	for res, actions := range o.Disabled {
+		r, ok := o.Parent.Resources[res]
+		if !ok {
+			verr.Add(o, "cannot disable unknown resource %#v", res)
+			continue
+		}
+		for _, n := range actions {
+			if _, ok := r.Actions[n]; !ok {
+				verr.Add(o, "cannot disable unknown action %#v of resource %#v", n, res)
+			}
+		}
+	}
+	o.Parent.IterateResources(func(r *ResourceDefinition) error {
+		if r.ParentName == "" {
+			return nil
+		}
+		if actions, ok := o.Disabled[r.Name]; ok && len(actions) == 0 {
+			return nil
+		}
+		actions, ok := o.Disabled[r.ParentName]
+		if !ok {
+			return nil
+		}
+		if len(actions) == 0 {
+			verr.Add(o, "cannot disable resource %#v, it is the parent of resource %#v", r.ParentName, r.Name)
+			return nil
+		}
+		if p, ok := o.Parent.Resources[r.ParentName]; ok && p.CanonicalAction() != nil {
+			for _, n := range actions {
+				if n == p.CanonicalAction().Name {
+					verr.Add(o, "cannot disable canonical action %#v of resource %#v, it is the parent of resource %#v",
+						n, p.Name, r.Name)
+				}
+			}
+		}
+		return nil
+	})
+	return verr
+}
+
 // Validate tests whether the resource definition is consistent: action names are valid and each action is
 // valid.
 func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
@@ -216,9 +461,14 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Params != nil {
 		verr.Merge(r.Params.Validate("resource parameters", r))
 	}
+	validateRequestHeaders(r, "resource headers", r.Headers, verr)
+	for _, m := range r.Middleware {
+		verr.Merge(m.Validate())
+	}
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	validateSecurity(r, r.Security, verr)
 	return verr.AsError()
 }
 
@@ -249,7 +499,7 @@ func (r *ResourceDefinition) validateParent(verr *dslengine.ValidationErrors) {
 	}
 }
 
-// Validate makes sure the CORS definition origin is valid.
+// Validate makes sure the CORS definition origin and methods are valid.
 func (cors *CORSDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
 	if !cors.Regexp && strings.Count(cors.Origin, "*") > 1 {
@@ -261,9 +511,20 @@ func (cors *CORSDefinition) Validate() *dslengine.ValidationErrors {
 			verr.Add(cors, "invalid origin, should be a valid regular expression")
 		}
 	}
+	for _, m := range cors.Methods {
+		if m != "*" && !corsMethods[m] {
+			verr.Add(cors, "invalid method %#v, must be an upper case HTTP method or \"*\"", m)
+		}
+	}
 	return verr
 }
 
+// corsMethods lists the HTTP methods that may be authorized by CORS policies.
+var corsMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+	"OPTIONS": true, "TRACE": true, "CONNECT": true,
+}
+
 // Validate validates the encoding MIME type and Go package path if set.
 func (enc *EncodingDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -320,6 +581,12 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if len(a.Routes) == 0 {
 		verr.Add(a, "No route defined for action")
 	}
+	for _, r := range a.Routes {
+		verr.Merge(r.Validate())
+		for _, al := range r.Aliases {
+			verr.Merge(al.Validate())
+		}
+	}
 	for i, r := range a.Responses {
 		for j, r2 := range a.Responses {
 			if i != j && r.Status == r2.Status {
@@ -332,11 +599,41 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
 	}
+	verr.Merge(a.validateFiles())
+	verr.Merge(a.validateStreaming())
+	validateSecurity(a, a.Security, verr)
+	validateDeprecation("", a, a.Metadata, verr)
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
+			if IsEnum(p.Type) || p.Type.IsArray() && IsEnum(p.Type.ToArray().ElemType.Type) {
+				verr.Add(a, "Param %s uses an enum type, enum types can only be used in payloads, user types and media types, use an Enum validation instead", n)
+				continue
+			}
+			if IsFlags(p.Type) || p.Type.IsArray() && IsFlags(p.Type.ToArray().ElemType.Type) {
+				verr.Add(a, "Param %s uses a flags type, flags types can only be used in payloads, user types and media types", n)
+				continue
+			}
+			if IsOneOf(p.Type) {
+				verr.Add(a, "Param %s uses a one of type, one of types can only be used in payloads, user types and media types", n)
+				continue
+			}
+			if p.Nullable {
+				verr.Add(a, "Param %s is nullable, only the attributes of payloads, user types and media types can be nullable", n)
+				continue
+			}
+			if p.CollectionFormat != "" {
+				switch p.CollectionFormat {
+				case CollectionFormatMulti, CollectionFormatCSV, CollectionFormatPipes:
+				default:
+					verr.Add(a, "Param %s has an invalid collection format %#v, must be one of %#v, %#v or %#v", n, p.CollectionFormat, CollectionFormatMulti, CollectionFormatCSV, CollectionFormatPipes)
+				}
+				if !p.Type.IsArray() {
+					verr.Add(a, "Param %s defines a collection format but is not an array", n)
+				}
+			}
 			if p.Type.IsPrimitive() {
 				continue
 			}
@@ -345,13 +642,134 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 					continue
 				}
 			}
-			verr.Add(a, "Param %s has an invalid type, action params must be primitives or arrays of primitives", n)
+			if p.IsDeepObject() {
+				for _, r := range a.Routes {
+					for _, wc := range ExtractWildcards(r.FullPath()) {
+						if wc == n {
+							verr.Add(a, "Param %s is a deep object and cannot be used in the request path", n)
+						}
+					}
+				}
+				continue
+			}
+			verr.Add(a, "Param %s has an invalid type, action params must be primitives, arrays of primitives or user types whose attributes are primitives or arrays of primitives", n)
+		}
+	}
+	validateRequestHeaders(a, "action headers", a.Headers, verr)
+	a.validateCookies(verr)
+	if a.DigestAlgorithm != "" {
+		switch a.DigestAlgorithm {
+		case "SHA-256", "SHA-512", "MD5":
+		default:
+			verr.Add(a, "unsupported digest algorithm %#v, must be one of SHA-256, SHA-512 or MD5", a.DigestAlgorithm)
+		}
+		if a.Payload == nil {
+			verr.Add(a, "RequireDigest used on action with no payload")
+		}
+	}
+	if a.Resumable {
+		hasPost := false
+		for _, r := range a.Routes {
+			if r.Verb == "POST" {
+				hasPost = true
+				break
+			}
+		}
+		if !hasPost {
+			verr.Add(a, "resumable upload action must define a POST route")
+		}
+		if a.Payload != nil {
+			verr.Add(a, "resumable upload action cannot define a payload")
+		}
+	}
+	for _, e := range a.Emits {
+		if e == "" {
+			verr.Add(a, "event names cannot be empty")
+		}
+	}
+	if a.Saga != nil {
+		verr.Merge(a.Saga.Validate())
+	}
+	if a.Aggregate != nil {
+		verr.Merge(a.Aggregate.Validate())
+	}
+	if a.Messages != nil {
+		verr.Merge(a.Messages.Validate())
+	}
+	for _, m := range a.Middleware {
+		verr.Merge(m.Validate())
+	}
+	for _, n := range a.RequiredContext {
+		if _, ok := Design.ContextValues[n]; !ok {
+			verr.Add(a, "unknown context value %#v, context values must be declared with ContextValue", n)
+			continue
+		}
+		if len(a.ContextProviders(n)) == 0 {
+			verr.Add(a, "no middleware provides the required context value %#v, use Provides in a Middleware or security scheme that applies to the action", n)
+		}
+	}
+	enrichments := make(map[string]bool)
+	for _, e := range a.Enrichments {
+		if enrichments[e.Name] {
+			verr.Add(a, "duplicate enrichment %#v", e.Name)
+		}
+		enrichments[e.Name] = true
+		verr.Merge(e.Validate())
+	}
+	if a.VersionAttribute != "" && !a.hasVersionAttribute() {
+		verr.Add(a, "version attribute %#v is not defined by the resource or response media types", a.VersionAttribute)
+	}
+	if a.SLO != nil {
+		if a.SLO.LatencyP99 <= 0 {
+			verr.Add(a, "SLO latency must be strictly positive")
+		}
+		if a.SLO.Availability <= 0 || a.SLO.Availability > 100 {
+			verr.Add(a, "SLO availability must be a percentage greater than 0 and less than or equal to 100, got %v", a.SLO.Availability)
+		}
+	}
+	if c := a.Concurrency; c != nil {
+		if c.MaxInFlight <= 0 {
+			verr.Add(a, "max concurrency must be strictly positive, got %d", c.MaxInFlight)
+		}
+		if c.QueueSize < 0 {
+			verr.Add(a, "concurrency queue size cannot be negative, got %d", c.QueueSize)
+		}
+		if c.QueueTimeout < 0 {
+			verr.Add(a, "concurrency queue timeout cannot be negative, got %s", c.QueueTimeout)
 		}
 	}
+	if a.Subscription != nil {
+		verr.Merge(a.Subscription.Validate())
+	}
+	if a.Pagination != nil {
+		verr.Merge(a.Pagination.Validate())
+	}
 
 	return verr.AsError()
 }
 
+// hasVersionAttribute returns true if the action version attribute is defined by the parent
+// resource media type or by one of the action response media types.
+func (a *ActionDefinition) hasVersionAttribute() bool {
+	ids := []string{}
+	if a.Parent != nil && a.Parent.MediaType != "" {
+		ids = append(ids, a.Parent.MediaType)
+	}
+	for _, r := range a.Responses {
+		ids = append(ids, r.MediaType)
+	}
+	for _, id := range ids {
+		mt := Design.MediaTypeWithIdentifier(id)
+		if mt == nil || !mt.Type.IsObject() {
+			continue
+		}
+		if _, ok := mt.Type.ToObject()[a.VersionAttribute]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -370,13 +788,222 @@ func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 			verr.Add(f, "invalid request path %s, must end with a wildcard starting with *", f.RequestPath)
 		}
 	}
-	if len(matches) > 2 {
-		verr.Add(f, "invalid request path, may only contain one wildcard")
+	if len(matches) > 1 {
+		verr.Add(f, "invalid request path %s, may only contain one wildcard", f.RequestPath)
+	}
+	validateSecurity(f, f.Security, verr)
+
+	return verr.AsError()
+}
+
+// validateSecurity checks that the security requirement of the given definition refers to a
+// security scheme defined in the API and that the required scopes are defined by the scheme.
+// Schemes that define no scope accept any scope.
+func validateSecurity(def dslengine.Definition, s *SecurityDefinition, verr *dslengine.ValidationErrors) {
+	if s == nil {
+		return
+	}
+	if s.Scheme == nil {
+		verr.Add(def, "security requirement does not specify a scheme")
+		return
+	}
+	if s.Scheme.Kind == NoSecurityKind {
+		return
+	}
+	var scheme *SecuritySchemeDefinition
+	for _, sc := range Design.SecuritySchemes {
+		if sc.SchemeName == s.Scheme.SchemeName {
+			scheme = sc
+			break
+		}
+	}
+	if scheme == nil {
+		verr.Add(def, "unknown security scheme %#v", s.Scheme.SchemeName)
+		return
+	}
+	if len(scheme.Scopes) == 0 {
+		return
 	}
+	for _, scope := range s.Scopes {
+		if _, ok := scheme.Scopes[scope]; !ok {
+			verr.Add(def, "scope %#v is not defined by security scheme %#v", scope, scheme.SchemeName)
+		}
+	}
+}
 
+// validateStreaming checks that streaming payloads are collections that are not encoded in
+// multipart forms and that the action writes at most one response as a stream.
+func (a *ActionDefinition) validateStreaming() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if a.PayloadStreaming {
+		if a.Payload == nil {
+			verr.Add(a, "Streaming used on action with no payload")
+		} else if !a.Payload.IsArray() {
+			verr.Add(a, "streaming payload must be a collection, got %s", a.Payload.Type.Name())
+		}
+		if a.PayloadMultipart {
+			verr.Add(a, "streaming payload cannot be encoded as a multipart form")
+		}
+		if a.WebSocket() {
+			verr.Add(a, "websocket actions cannot have a streaming payload")
+		}
+	}
+	var chunked []string
+	for n, r := range a.Responses {
+		if r.Chunked {
+			chunked = append(chunked, n)
+		}
+	}
+	if len(chunked) > 1 {
+		sort.Strings(chunked)
+		verr.Add(a, "action can write only one response as a stream, got %s", strings.Join(chunked, ", "))
+	}
 	return verr.AsError()
 }
 
+// validateRequestHeaders checks that the request headers defined by an action or a resource can be
+// decoded from HTTP headers: each header must be a primitive or an array of primitives.
+func validateRequestHeaders(def dslengine.Definition, ctx string, headers *AttributeDefinition, verr *dslengine.ValidationErrors) {
+	if headers == nil {
+		return
+	}
+	verr.Merge(headers.Validate(ctx, def))
+	for n, h := range headers.Type.ToObject() {
+		if IsEnum(h.Type) || h.Type.IsArray() && IsEnum(h.Type.ToArray().ElemType.Type) {
+			verr.Add(def, "Header %s uses an enum type, enum types can only be used in payloads, user types and media types, use an Enum validation instead", n)
+			continue
+		}
+		if IsFlags(h.Type) || h.Type.IsArray() && IsFlags(h.Type.ToArray().ElemType.Type) {
+			verr.Add(def, "Header %s uses a flags type, flags types can only be used in payloads, user types and media types", n)
+			continue
+		}
+		if h.Nullable {
+			verr.Add(def, "Header %s is nullable, only the attributes of payloads, user types and media types can be nullable", n)
+			continue
+		}
+		if h.Type.IsPrimitive() || h.Type.IsArray() && h.Type.ToArray().ElemType.Type.IsPrimitive() {
+			continue
+		}
+		verr.Add(def, "Header %s has an invalid type, request headers must be primitives or arrays of primitives", n)
+	}
+}
+
+// validateCookies checks that the action cookies are primitives that can be read from the request
+// cookies and that their names do not clash with the names of the action params and headers.
+func (a *ActionDefinition) validateCookies(verr *dslengine.ValidationErrors) {
+	if a.Cookies == nil {
+		return
+	}
+	verr.Merge(a.Cookies.Validate("action cookies", a))
+	params := a.AllParams()
+	headers := []*AttributeDefinition{a.Headers}
+	if a.Parent != nil {
+		headers = append(headers, a.Parent.Headers)
+	}
+	for n, c := range a.Cookies.Type.ToObject() {
+		if !c.Type.IsPrimitive() || IsEnum(c.Type) || IsFlags(c.Type) {
+			verr.Add(a, "Cookie %s has an invalid type, cookies must be primitives", n)
+		}
+		if c.Nullable {
+			verr.Add(a, "Cookie %s is nullable, only the attributes of payloads, user types and media types can be nullable", n)
+		}
+		if params != nil && params.Type.ToObject()[n] != nil {
+			verr.Add(a, "Cookie %s clashes with the param with the same name", n)
+		}
+		for _, h := range headers {
+			if h != nil && h.Type.ToObject()[n] != nil {
+				verr.Add(a, "Cookie %s clashes with the header with the same name", n)
+			}
+		}
+	}
+}
+
+// validateFiles checks that File attributes are only used in multipart payloads and that the
+// attributes of multipart payloads can be encoded in forms.
+func (a *ActionDefinition) validateFiles() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	for _, att := range []*AttributeDefinition{a.Params, a.Headers, a.Cookies} {
+		if att == nil {
+			continue
+		}
+		att.Type.ToObject().IterateAttributes(func(n string, p *AttributeDefinition) error {
+			if path := filePath(p, n, nil); path != "" {
+				verr.Add(a, "%s is a File, File attributes can only be used in the payloads of actions that use MultipartForm", path)
+			}
+			return nil
+		})
+	}
+	if a.Payload == nil {
+		if a.PayloadMultipart {
+			verr.Add(a, "MultipartForm used on action with no payload")
+		}
+		return verr.AsError()
+	}
+	if !a.PayloadMultipart {
+		if path := filePath(a.Payload.AttributeDefinition, "payload", nil); path != "" {
+			verr.Add(a, "%s is a File, actions whose payload contains files must use MultipartForm", path)
+		}
+		return verr.AsError()
+	}
+	obj := a.Payload.ToObject()
+	if obj == nil {
+		verr.Add(a, "multipart payload must be an object, got %s", a.Payload.Type.Name())
+		return verr.AsError()
+	}
+	obj.IterateAttributes(func(n string, att *AttributeDefinition) error {
+		t := att.Type
+		if arr := t.ToArray(); arr != nil {
+			t = arr.ElemType.Type
+		}
+		if t.Kind() == FileKind {
+			return nil
+		}
+		if path := filePath(att, "payload."+n, nil); path != "" {
+			verr.Add(a, "%s is a File, files must be top level attributes of multipart payloads or elements of top level arrays", path)
+		}
+		return nil
+	})
+	return verr.AsError()
+}
+
+// filePath returns the path to the first File attribute found in att given the path to att, the
+// empty string if att does not contain files.
+func filePath(att *AttributeDefinition, path string, seen map[string]bool) string {
+	switch t := att.Type.(type) {
+	case Primitive:
+		if t.Kind() == FileKind {
+			return path
+		}
+	case *Array:
+		return filePath(t.ElemType, path+"[]", seen)
+	case *Hash:
+		return filePath(t.ElemType, path+"[*]", seen)
+	case Object:
+		var found string
+		t.IterateAttributes(func(n string, catt *AttributeDefinition) error {
+			if found == "" {
+				found = filePath(catt, path+"."+n, seen)
+			}
+			return nil
+		})
+		return found
+	case *UserTypeDefinition, *MediaTypeDefinition:
+		ut, ok := t.(*UserTypeDefinition)
+		if !ok {
+			ut = t.(*MediaTypeDefinition).UserTypeDefinition
+		}
+		if seen[ut.TypeName] {
+			return ""
+		}
+		if seen == nil {
+			seen = make(map[string]bool)
+		}
+		seen[ut.TypeName] = true
+		return filePath(ut.AttributeDefinition, path, seen)
+	}
+	return ""
+}
+
 // ValidateParams checks the action parameters (make sure they have names, members and types).
 func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -419,9 +1046,6 @@ func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
 		ctx := fmt.Sprintf("parameter %s", n)
 		verr.Merge(p.Validate(ctx, a))
 	}
-	for _, resp := range a.Responses {
-		verr.Merge(resp.Validate())
-	}
 	return verr.AsError()
 }
 
@@ -460,6 +1084,57 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Add(parent, "%sdefault value %#v is not one of the accepted values: %#v", ctx, a.DefaultValue, a.Validation.Values)
 		}
 	}
+	if a.Validation != nil && a.Validation.Format != "" && !IsSupportedFormat(a.Validation.Format) {
+		verr.Add(parent, "%sunsupported format %#v, supported formats are: %s", ctx, a.Validation.Format, strings.Join(SupportedFormats(), ", "))
+	}
+	if _, ok := a.Metadata[PhoneRegionMetadata]; ok {
+		if !a.IsPhoneNumber() {
+			verr.Add(parent, "%s%s metadata can only be used on string attributes with the phone format", ctx, PhoneRegionMetadata)
+		} else if r := a.PhoneRegion(); !regionRegex.MatchString(r) {
+			verr.Add(parent, "%sinvalid %s metadata %#v, must be an ISO 3166-1 alpha-2 region code such as \"FR\"", ctx, PhoneRegionMetadata, r)
+		}
+	}
+	validateDeprecation(ctx, parent, a.Metadata, verr)
+	if a.DefaultValue != nil && a.Validation != nil {
+		a.validateValue(ctx, "default value", a.DefaultValue, parent, verr)
+	}
+	if a.Example != nil && a.Example != "-" {
+		a.validateExample(ctx, parent, verr)
+	}
+	if a.Transitions != nil {
+		if a.Type.Kind() != StringKind {
+			verr.Add(parent, "%stransitions can only be defined on string attributes", ctx)
+		} else if a.Validation != nil && a.Validation.Values != nil {
+			for from, tos := range a.Transitions {
+				for _, s := range append([]string{from}, tos...) {
+					found := false
+					for _, e := range a.Validation.Values {
+						if e == s {
+							found = true
+							break
+						}
+					}
+					if !found {
+						verr.Add(parent, "%stransition state %#v is not one of the accepted values: %#v", ctx, s, a.Validation.Values)
+					}
+				}
+			}
+		}
+	}
+	if a.Nullable {
+		if p, ok := a.Type.(Primitive); !ok || !p.CanBeNullable() {
+			verr.Add(parent, "%snullable attributes must be of type string, integer, number, boolean or date time, got %s", ctx, a.Type.Name())
+		}
+		if a.DefaultValue != nil || a.Transitions != nil || a.ComputedFrom != nil {
+			verr.Add(parent, "%snullable attributes cannot have a default value, transitions or be computed", ctx)
+		}
+	}
+	if a.ReadOnly && a.WriteOnly {
+		verr.Add(parent, "%sattributes cannot be both read-only and write-only", ctx)
+	}
+	if a.WriteOnly && (a.ComputedFrom != nil || a.VisibleTo != nil) {
+		verr.Add(parent, "%swrite-only attributes are never rendered and cannot be computed or have a restricted visibility", ctx)
+	}
 	o := a.Type.ToObject()
 	if o != nil {
 		for _, n := range a.AllRequired() {
@@ -474,6 +1149,48 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 				verr.Add(parent, `%srequired field "%s" does not exist`, ctx, n)
 			}
 		}
+		if a.Validation != nil {
+			for _, r := range a.Validation.RequiredWhen {
+				if _, ok := o[r.Name]; !ok {
+					verr.Add(parent, `%sconditionally required field "%s" does not exist`, ctx, r.Name)
+				}
+				fatt, ok := o[r.Field]
+				if !ok {
+					verr.Add(parent, `%sfield "%s" used by the required condition of "%s" does not exist`, ctx, r.Field, r.Name)
+					continue
+				}
+				if r.Field == r.Name {
+					verr.Add(parent, `%sfield "%s" cannot be required on its own value`, ctx, r.Name)
+				}
+				switch fatt.Type.Kind() {
+				case StringKind, IntegerKind, NumberKind, BooleanKind:
+					if !fatt.Type.IsCompatible(r.Value) {
+						verr.Add(parent, `%svalue %#v of the required condition of "%s" is incompatible with field "%s" of type %s`,
+							ctx, r.Value, r.Name, r.Field, fatt.Type.Name())
+					}
+				default:
+					verr.Add(parent, `%sfield "%s" used by the required condition of "%s" must be a string, integer, number or boolean`,
+						ctx, r.Field, r.Name)
+				}
+			}
+		}
+		for n, att := range o {
+			for _, f := range att.ComputedFrom {
+				if fatt, ok := o[f]; !ok {
+					verr.Add(parent, `%scomputed field "%s" uses field "%s" which does not exist`, ctx, n, f)
+				} else if !fatt.Type.IsPrimitive() || fatt.ComputedFrom != nil {
+					verr.Add(parent, `%scomputed field "%s" uses field "%s" which is not a primitive non computed field`, ctx, n, f)
+				} else if fatt.Nullable {
+					verr.Add(parent, `%scomputed field "%s" uses field "%s" which is nullable`, ctx, n, f)
+				}
+			}
+			if att.ComputedFrom != nil && !att.Type.IsPrimitive() {
+				verr.Add(parent, `%scomputed field "%s" must be a primitive`, ctx, n)
+			}
+			if att.VisibleTo != nil && (a.IsRequired(n) || a.HasDefaultValue(n) || a.IsNonZero(n)) {
+				verr.Add(parent, `%sfield "%s" with restricted visibility cannot be required, non-zero or have a default value`, ctx, n)
+			}
+		}
 		for n, att := range o {
 			ctx = fmt.Sprintf("field %s", n)
 			verr.Merge(att.Validate(ctx, parent))
@@ -481,13 +1198,112 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 	} else {
 		if a.Type.IsArray() {
 			elemType := a.Type.ToArray().ElemType
+			if elemType.Nullable {
+				verr.Add(parent, "%sarray elements cannot be nullable", ctx)
+			}
 			verr.Merge(elemType.Validate(ctx, a))
 		}
+		if h := a.Type.ToHash(); h != nil {
+			if !h.KeyType.Type.IsPrimitive() {
+				verr.Add(parent, "%shash key type must be a primitive, got %s", ctx, h.KeyType.Type.Name())
+			}
+			if h.KeyType.Nullable || h.ElemType.Nullable {
+				verr.Add(parent, "%shash keys and elements cannot be nullable", ctx)
+			}
+			verr.Merge(h.KeyType.Validate(ctx, a))
+			verr.Merge(h.ElemType.Validate(ctx, a))
+		}
 	}
 
 	return verr.AsError()
 }
 
+// validateExample checks that the custom example of the attribute is compatible with its type and
+// satisfies its validations. The example of a media type or user type may be set before its
+// attributes are defined so the type compatibility cannot always be checked by the DSL.
+func (a *AttributeDefinition) validateExample(ctx string, parent dslengine.Definition, verr *dslengine.ValidationErrors) {
+	if !a.Type.IsCompatible(a.Example) {
+		verr.Add(parent, "%sexample value %#v is incompatible with attribute of type %s", ctx, a.Example, a.Type.Name())
+		return
+	}
+	if a.Validation == nil {
+		return
+	}
+	if a.Type.IsPrimitive() && a.Validation.Values != nil {
+		var found bool
+		for _, e := range a.Validation.Values {
+			if e == a.Example {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(parent, "%sexample value %#v is not one of the accepted values: %#v", ctx, a.Example, a.Validation.Values)
+		}
+	}
+	a.validateValue(ctx, "example value", a.Example, parent, verr)
+}
+
+// validateValue checks that val, the default value or example of the attribute, satisfies the
+// range, length and pattern validations of the attribute. name is used in the error messages.
+func (a *AttributeDefinition) validateValue(ctx, name string, val interface{}, parent dslengine.Definition, verr *dslengine.ValidationErrors) {
+	v := a.Validation
+	if f, ok := toFloat(val); ok {
+		if v.Minimum != nil && f < *v.Minimum {
+			verr.Add(parent, "%s%s %#v is lower than the minimum %v", ctx, name, val, *v.Minimum)
+		}
+		if v.Maximum != nil && f > *v.Maximum {
+			verr.Add(parent, "%s%s %#v is greater than the maximum %v", ctx, name, val, *v.Maximum)
+		}
+	}
+	l := -1
+	switch actual := val.(type) {
+	case string:
+		l = utf8.RuneCountInString(actual)
+		if v.Pattern != "" {
+			if re, err := regexp.Compile(v.Pattern); err == nil && !re.MatchString(actual) {
+				verr.Add(parent, "%s%s %#v does not match the pattern %#v", ctx, name, actual, v.Pattern)
+			}
+		}
+		if validator, ok := customFormats[v.Format]; ok && validator != nil {
+			if err := validator(actual); err != nil {
+				verr.Add(parent, "%s%s %#v is not a valid %s value, %s", ctx, name, actual, v.Format, err)
+			}
+		}
+	case []interface{}:
+		l = len(actual)
+	case map[interface{}]interface{}:
+		l = len(actual)
+	}
+	if l >= 0 {
+		if v.MinLength != nil && l < *v.MinLength {
+			verr.Add(parent, "%s%s %#v is shorter than the minimum length %d", ctx, name, val, *v.MinLength)
+		}
+		if v.MaxLength != nil && l > *v.MaxLength {
+			verr.Add(parent, "%s%s %#v is longer than the maximum length %d", ctx, name, val, *v.MaxLength)
+		}
+	}
+}
+
+// toFloat converts numerical values to float64.
+func toFloat(val interface{}) (float64, bool) {
+	switch actual := val.(type) {
+	case int:
+		return float64(actual), true
+	case int32:
+		return float64(actual), true
+	case int64:
+		return float64(actual), true
+	case uint:
+		return float64(actual), true
+	case float32:
+		return float64(actual), true
+	case float64:
+		return actual, true
+	}
+	return 0, false
+}
+
 // Validate checks that the response definition is consistent: its status is set and the media
 // type definition if any is valid.
 func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
@@ -498,15 +1314,493 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	if r.Filename != "" {
+		verr.Merge(r.validateDownload())
+	}
+	if r.Location != "" {
+		verr.Merge(r.validateRedirect())
+	}
+	if len(r.HeaderTemplates) > 0 {
+		verr.Merge(r.validateHeaderTemplates())
+	}
+	if r.Streaming {
+		verr.Merge(r.validateStreaming())
+	}
+	if r.Chunked {
+		verr.Merge(r.validateChunked())
+	}
+	return verr.AsError()
+}
+
+// validateChunked checks that the response written as a stream has exactly one media type which
+// is a collection defined in the design.
+func (r *ResponseDefinition) validateChunked() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Streaming {
+		verr.Add(r, "server-sent event stream responses cannot use Streaming")
+	}
+	if r.Filename != "" {
+		verr.Add(r, "streaming response cannot be a file download")
+	}
+	if r.MediaType == "" {
+		verr.Add(r, "streaming response must have exactly one media type")
+		return verr.AsError()
+	}
+	if r.Type != nil {
+		if mt, ok := r.Type.(*MediaTypeDefinition); !ok || mt.Identifier != r.MediaType {
+			verr.Add(r, "streaming response must have exactly one media type")
+			return verr.AsError()
+		}
+	}
+	if mt := Design.MediaTypeWithIdentifier(r.MediaType); mt == nil {
+		verr.Add(r, "streaming response media type %#v must be defined in the design", r.MediaType)
+	} else if !mt.IsArray() {
+		verr.Add(r, "streaming response media type %#v must be a collection", r.MediaType)
+	}
+	return verr.AsError()
+}
+
+// validateStreaming checks that the server-sent event stream response has status code 200, is not
+// a file download and uses a media type defined in the design to render the events.
+func (r *ResponseDefinition) validateStreaming() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Status != 200 {
+		verr.Add(r, "streaming response must have status code 200, got %d", r.Status)
+	}
+	if r.Filename != "" {
+		verr.Add(r, "streaming response cannot be a file download")
+	}
+	if r.Type != nil {
+		verr.Add(r, "streaming response cannot override the media type with a type")
+	}
+	if mt := Design.MediaTypeWithIdentifier(r.MediaType); mt == nil {
+		verr.Add(r, "streaming response media type %#v must be defined in the design", r.MediaType)
+	} else if r.ViewName != "" {
+		if _, ok := mt.Views[r.ViewName]; !ok {
+			verr.Add(r, "streaming response media type %#v does not define view %#v", r.MediaType, r.ViewName)
+		}
+	}
+	if a, ok := r.Parent.(*ActionDefinition); ok && a.WebSocket() {
+		verr.Add(r, "websocket actions cannot have a streaming response")
+	}
+	return verr.AsError()
+}
+
+// validateRedirect checks that the redirect response has a 3xx status code, a required Location
+// header and no body and that its location only refers to action params that are always set.
+func (r *ResponseDefinition) validateRedirect() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Status < 300 || r.Status >= 400 {
+		verr.Add(r, "redirect response must have a 3xx status code, got %d", r.Status)
+	}
+	if r.Headers == nil || !r.Headers.IsRequired("Location") {
+		verr.Add(r, "redirect response must define the Location header as required")
+	}
+	if r.Type != nil || r.MediaType != "" || r.Filename != "" || r.Streaming || r.Chunked {
+		verr.Add(r, "redirect response cannot have a body")
+	}
+	if a, ok := r.Parent.(*ActionDefinition); ok {
+		validateTemplateParams(r, "redirect location", a, r.LocationParams(), verr)
+	}
+	return verr.AsError()
+}
+
+// validateTemplateParams checks that the params interpolated in the given response template
+// (download filename or redirect location) are primitive action params that are always set: the
+// params captured by the action routes outside of optional segments, the required params and the
+// params with a default value.
+func validateTemplateParams(r *ResponseDefinition, kind string, a *ActionDefinition, names []string, verr *dslengine.ValidationErrors) {
+	params := a.AllParams()
+	optional := make(map[string]bool)
+	for _, n := range a.OptionalPathParams() {
+		optional[n] = true
+	}
+	wildcards := make(map[string]bool)
+	for _, route := range a.Routes {
+		for _, n := range route.Params() {
+			if !optional[n] {
+				wildcards[n] = true
+			}
+		}
+	}
+	for _, n := range names {
+		var att *AttributeDefinition
+		if params != nil {
+			att = params.Type.ToObject()[n]
+		}
+		if att == nil {
+			verr.Add(r, "unknown param %#v in %s", n, kind)
+		} else if !att.Type.IsPrimitive() {
+			verr.Add(r, "param %#v used in %s must be a primitive", n, kind)
+		} else if !wildcards[n] && params.IsPrimitivePointer(n) {
+			verr.Add(r, "param %#v used in %s must be required or have a default value", n, kind)
+		}
+	}
+}
+
+// validateHeaderTemplates checks that the response body is rendered with a media type defined in
+// the design that is not a collection and that the header templates only refer to primitive
+// attributes rendered by the response view, the default view if the response does not specify
+// one. The response methods generated for the views that do not render the attributes do not set
+// the headers.
+func (r *ResponseDefinition) validateHeaderTemplates() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Streaming || r.Chunked {
+		verr.Add(r, "header templates cannot be used with streaming responses")
+	}
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if mt == nil || !mt.Type.IsObject() {
+		verr.Add(r, "header templates require a media type defined in the design that is not a collection")
+		return verr.AsError()
+	}
+	viewName := r.ViewName
+	if viewName == "" {
+		viewName = "default"
+	}
+	view := mt.Views[viewName]
+	headers := make([]string, 0, len(r.HeaderTemplates))
+	for h := range r.HeaderTemplates {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	for _, h := range headers {
+		for _, n := range r.HeaderTemplateAttributes(h) {
+			att := mt.Type.ToObject()[n]
+			if att == nil {
+				verr.Add(r, "unknown attribute %#v in template of header %#v", n, h)
+			} else if !att.Type.IsPrimitive() || att.Nullable {
+				verr.Add(r, "attribute %#v used in template of header %#v must be a non nullable primitive", n, h)
+			} else if view != nil && view.Type.ToObject()[n] == nil {
+				verr.Add(r, "attribute %#v used in template of header %#v is not rendered by view %#v", n, h, viewName)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
+// validateDownload checks that the file download response does not use a media type defined in
+// the design and that its filename only refers to action params that are always set.
+func (r *ResponseDefinition) validateDownload() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Type != nil || Design.MediaTypeWithIdentifier(r.MediaType) != nil {
+		verr.Add(r, "file download response cannot use a type or a media type defined in the design")
+	}
+	a, ok := r.Parent.(*ActionDefinition)
+	if !ok {
+		return verr.AsError()
+	}
+	validateTemplateParams(r, "download filename", a, r.FilenameParams(), verr)
+	return verr.AsError()
+}
+
+// Validate checks that the websocket definition is consistent: the action uses the "ws" or "wss"
+// schemes and has no payload, at least one of the inbound and outbound messages is defined, the
+// inbound message type is a valid object that does not contain files and the outbound message
+// media type and view exist.
+func (w *WebSocketDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if w.Parent != nil {
+		if !w.Parent.WebSocket() {
+			verr.Add(w, `WebSocket used on action whose schemes are not "ws" or "wss"`)
+		}
+		if w.Parent.Payload != nil {
+			verr.Add(w, "websocket actions cannot have a payload, use Inbound to describe the messages sent by clients")
+		}
+	}
+	if w.Inbound == nil && w.Outbound == "" {
+		verr.Add(w, "websocket must define at least one of Inbound or Outbound")
+	}
+	if w.Inbound != nil {
+		verr.Merge(w.Inbound.Validate("inbound message", w))
+		if !w.Inbound.IsObject() {
+			verr.Add(w, "inbound message must be an object, got %s", w.Inbound.Type.Name())
+		}
+		if path := filePath(w.Inbound.AttributeDefinition, "message", nil); path != "" {
+			verr.Add(w, "%s is a File, File attributes can only be used in the payloads of actions that use MultipartForm", path)
+		}
+	}
+	if w.Outbound != "" {
+		mt := w.OutboundMediaType()
+		if mt == nil {
+			verr.Add(w, "unknown outbound message media type %#v", w.Outbound)
+		} else if _, ok := mt.Views[w.OutboundView]; !ok {
+			verr.Add(w, "outbound message media type %#v does not define view %#v", w.Outbound, w.OutboundView)
+		}
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the saga definition is consistent: it has a name and at least one step
+// and the step names are unique.
+func (s *SagaDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if s.Name == "" {
+		verr.Add(s, "saga name cannot be empty")
+	}
+	if len(s.Steps) == 0 {
+		verr.Add(s, "saga must define at least one step")
+	}
+	names := make(map[string]bool)
+	for _, st := range s.Steps {
+		if st.Name == "" {
+			verr.Add(s, "saga step name cannot be empty")
+			continue
+		}
+		if names[st.Name] {
+			verr.Add(s, "duplicate saga step %#v", st.Name)
+		}
+		names[st.Name] = true
+	}
 	return verr.AsError()
 }
 
+// Validate checks that the pagination definition is consistent: it uses a known style, sane page
+// sizes and the paginated action returns a collection.
+func (p *PaginationDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if p.Style != PagePagination && p.Style != CursorPagination {
+		verr.Add(p, "invalid pagination style %#v, must be one of %#v or %#v", p.Style, PagePagination, CursorPagination)
+	}
+	if p.DefaultPageSize <= 0 {
+		verr.Add(p, "default page size must be strictly positive, got %d", p.DefaultPageSize)
+	}
+	if p.MaxPageSize < p.DefaultPageSize {
+		verr.Add(p, "maximum page size %d cannot be less than the default page size %d", p.MaxPageSize, p.DefaultPageSize)
+	}
+	if p.Parent == nil {
+		return verr.AsError()
+	}
+	resp, ok := p.Parent.Responses["OK"]
+	if !ok && p.Parent.Parent != nil {
+		resp, ok = p.Parent.Parent.Responses["OK"]
+	}
+	if !ok || resp.PaginatedMediaType() == nil {
+		verr.Add(p, "paginated actions must define an OK response whose media type is a collection")
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the subscription streams the changes made to a collection of media types
+// and that it does not override the delta stream response.
+func (s *SubscriptionDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if s.Parent == nil || s.Source == nil {
+		return verr.AsError()
+	}
+	if s.Parent.WebSocket() {
+		verr.Add(s, "subscriptions stream server-sent events and cannot use the websocket scheme")
+	}
+	if s.Source.Subscription != nil {
+		verr.Add(s, "cannot subscribe to subscription action %#v", s.Source.Name)
+	}
+	if r, ok := s.Parent.Responses["OK"]; ok && !r.Deltas {
+		verr.Add(s, "subscriptions cannot define an OK response, the response streams the collection deltas")
+	}
+	mt := s.ElementMediaType()
+	if mt == nil {
+		verr.Add(s, "action %#v must define an OK response whose media type is a collection of media types", s.Source.Name)
+		return verr.AsError()
+	}
+	if view := s.SourceResponse().ViewName; view != "" {
+		if _, ok := mt.Views[view]; !ok {
+			verr.Add(s, "unknown view %#v for media type %#v", view, mt.Identifier)
+		}
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the middleware definition is named and only provides declared context
+// values.
+func (m *MiddlewareDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if m.Name == "" {
+		verr.Add(m, "middleware name cannot be empty")
+	}
+	for _, p := range m.Provides {
+		if _, ok := Design.ContextValues[p]; !ok {
+			verr.Add(m, "unknown context value %#v, context values must be declared with ContextValue", p)
+		}
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the enrichment definition is consistent: it has a name that does not clash
+// with the action params and headers and it is looked up with a primitive action param.
+func (e *EnrichmentDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if e.Name == "" {
+		verr.Add(e, "enrichment name cannot be empty")
+	}
+	if e.TTL < 0 {
+		verr.Add(e, "cache TTL cannot be negative")
+	}
+	if e.Parent == nil {
+		return verr.AsError()
+	}
+	params := e.Parent.AllParams()
+	if params == nil || params.Type.ToObject()[e.Param] == nil {
+		verr.Add(e, "unknown param %#v", e.Param)
+	} else if !params.Type.ToObject()[e.Param].Type.IsPrimitive() {
+		verr.Add(e, "param %#v must be a primitive", e.Param)
+	}
+	if params != nil && params.Type.ToObject()[e.Name] != nil {
+		verr.Add(e, "name clashes with param %#v", e.Name)
+	}
+	if headers := e.Parent.Headers; headers != nil && headers.Type.ToObject()[e.Name] != nil {
+		verr.Add(e, "name clashes with header %#v", e.Name)
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the aggregate definition is consistent: the composite media type exists,
+// there is at least one branch, the branch names are unique, the downstream actions exist and the
+// mappings target attributes of the composite media type.
+func (a *AggregateDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	mt := Design.MediaTypeWithIdentifier(a.MediaType)
+	if mt == nil {
+		verr.Add(a, "unknown composite media type %#v", a.MediaType)
+	} else if !mt.Type.IsObject() {
+		verr.Add(a, "composite media type %#v must be an object", a.MediaType)
+	}
+	if len(a.Branches) == 0 {
+		verr.Add(a, "aggregate must define at least one branch")
+	}
+	names := make(map[string]bool)
+	for _, b := range a.Branches {
+		if b.Name == "" {
+			verr.Add(a, "aggregate branch name cannot be empty")
+			continue
+		}
+		if names[b.Name] {
+			verr.Add(a, "duplicate aggregate branch %#v", b.Name)
+		}
+		names[b.Name] = true
+		verr.Merge(b.Validate())
+		if mt == nil || !mt.Type.IsObject() {
+			continue
+		}
+		for _, m := range b.Mappings {
+			if !hasAttributePath(mt.AttributeDefinition, m.To) {
+				verr.Add(b, "composite media type %#v has no attribute %#v", a.MediaType, m.To)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the aggregate branch refers to an existing action or to a resource of a
+// service used by the API.
+func (b *AggregateBranchDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if b.Resource == "" || b.Action == "" {
+		verr.Add(b, "downstream resource and action cannot be empty")
+		return verr.AsError()
+	}
+	if b.Timeout < 0 {
+		verr.Add(b, "timeout cannot be negative")
+	}
+	if b.Service == "" {
+		r, ok := Design.Resources[b.Resource]
+		if !ok {
+			verr.Add(b, "unknown resource %#v", b.Resource)
+		} else if _, ok := r.Actions[b.Action]; !ok {
+			verr.Add(b, "unknown action %#v of resource %#v", b.Action, b.Resource)
+		} else if b.Parent != nil && b.Parent.Parent != nil && r.Actions[b.Action] == b.Parent.Parent {
+			verr.Add(b, "aggregate action cannot fetch itself")
+		}
+		return verr.AsError()
+	}
+	used, ok := Design.Uses[b.Service]
+	if !ok {
+		verr.Add(b, "service %#v is not declared with Uses", b.Service)
+	} else if len(used) > 0 {
+		found := false
+		for _, r := range used {
+			if r == b.Resource {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(b, "resource %#v of service %#v is not declared with Uses", b.Resource, b.Service)
+		}
+	}
+	return verr.AsError()
+}
+
+// hasAttributePath returns true if the dot separated path denotes an attribute of att.
+func hasAttributePath(att *AttributeDefinition, path string) bool {
+	for _, elem := range strings.Split(path, ".") {
+		if att == nil || !att.Type.IsObject() {
+			return false
+		}
+		att = att.Type.ToObject()[elem]
+	}
+	return att != nil
+}
+
 // Validate checks that the route definition is consistent: it has a parent.
 func (r *RouteDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
 	if r.Parent == nil {
 		verr.Add(r, "missing route parent action")
 	}
+	if strings.ContainsAny(r.Path, "()") {
+		verr.Add(r, "invalid path %s, only the trailing segment may be optional using the syntax (/segment)?", r.Path)
+	}
+	validateCatchAll(r, r.FullPath(), verr)
+	if r.Parent == nil || r.Parent.Parent == nil {
+		return verr.AsError()
+	}
+	params := r.Parent.AllParams()
+	if n := r.CatchAll(); n != "" {
+		if att, ok := params.Type.ToObject()[n]; ok && att.Type.Kind() != StringKind {
+			verr.Add(r, "catch-all param %#v must be a string", n)
+		}
+	}
+	for _, n := range r.Optional {
+		if params.IsRequired(n) {
+			verr.Add(r, "param %#v of optional path segment cannot be required", n)
+		}
+	}
+	return verr.AsError()
+}
+
+// validateCatchAll checks that path contains at most one catch-all wildcard and that it is the
+// last segment of the path.
+func validateCatchAll(def dslengine.Definition, path string, verr *dslengine.ValidationErrors) {
+	matches := CatchAllRegex.FindAllStringIndex(path, -1)
+	if len(matches) > 1 {
+		verr.Add(def, "invalid path %s, may only contain one catch-all wildcard", path)
+	} else if len(matches) == 1 && matches[0][1] != len(path) {
+		verr.Add(def, "invalid path %s, catch-all wildcard must appear at the end of the path", path)
+	}
+}
+
+// Validate checks that the alias policy is known and that aliases that are served or redirected
+// define the same wildcards as their route.
+func (a *RouteAliasDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	switch a.Policy {
+	case AliasServe, AliasRedirect, AliasGone:
+	default:
+		verr.Add(a, "invalid alias policy %#v, must be one of %#v, %#v or %#v", a.Policy, AliasServe, AliasRedirect, AliasGone)
+	}
+	validateCatchAll(a, a.FullPath(), verr)
+	if a.Policy == AliasGone {
+		return verr.AsError()
+	}
+	wcs := ExtractWildcards(a.FullPath())
+	rwcs := ExtractWildcards(a.Parent.FullPath())
+	sort.Strings(wcs)
+	sort.Strings(rwcs)
+	if strings.Join(wcs, ",") != strings.Join(rwcs, ",") {
+		verr.Add(a, "alias must define the same wildcards as its route: %s", strings.Join(rwcs, ", "))
+	}
 	return verr.AsError()
 }
 
@@ -518,9 +1812,100 @@ func (u *UserTypeDefinition) Validate(ctx string, parent dslengine.Definition) *
 		verr.Add(parent, "%s - %s", ctx, "User type must have a name")
 	}
 	verr.Merge(u.AttributeDefinition.Validate(ctx, u))
+	if u.TypeName != "" {
+		if path := requiredCycle(u.AttributeDefinition, "", u.TypeName, make(map[string]bool)); path != "" {
+			verr.Add(u, "recursive attribute %s cannot be required, at least one attribute of the cycle must be optional", path)
+		}
+	}
+	if u.OneOf != nil {
+		u.validateOneOf(verr)
+	}
 	return verr.AsError()
 }
 
+// validateOneOf checks that the member types of a OneOf type are distinct object types that
+// define the discriminator attribute as a required string attribute with an Enum validation and
+// that no two member types share a discriminator value so that values are never ambiguous.
+func (u *UserTypeDefinition) validateOneOf(verr *dslengine.ValidationErrors) {
+	if u.Discriminator == "" {
+		verr.Add(u, "discriminator attribute name cannot be empty")
+		return
+	}
+	if len(u.OneOf) < 2 {
+		verr.Add(u, "one of type must define at least two member types")
+	}
+	members := make(map[string]bool)
+	owners := make(map[interface{}]string)
+	for _, m := range u.OneOf {
+		if members[m.TypeName] {
+			verr.Add(u, "member type %#v is listed more than once", m.TypeName)
+			continue
+		}
+		members[m.TypeName] = true
+		if !m.IsObject() || IsOneOf(m) {
+			verr.Add(u, "member type %#v must be an object type other than a one of type", m.TypeName)
+			continue
+		}
+		att, ok := m.ToObject()[u.Discriminator]
+		if !ok {
+			verr.Add(u, "member type %#v must define the discriminator attribute %#v", m.TypeName, u.Discriminator)
+			continue
+		}
+		values := u.DiscriminatorValues(m)
+		if att.Type.Kind() != StringKind || len(values) == 0 || !m.IsRequired(u.Discriminator) {
+			verr.Add(u, "discriminator attribute %#v of member type %#v must be a required string attribute with an Enum validation", u.Discriminator, m.TypeName)
+			continue
+		}
+		for _, v := range values {
+			if other, ok := owners[v]; ok {
+				verr.Add(u, "discriminator value %#v of member type %#v is also a value of member type %#v", v, m.TypeName, other)
+				continue
+			}
+			owners[v] = m.TypeName
+		}
+	}
+}
+
+// requiredCycle returns the path to the first attribute of att that refers to the type with the
+// given name through required attributes only, the empty string if there is none. Recursive types
+// must be broken by at least one optional attribute, array or hash so that values are finite.
+func requiredCycle(att *AttributeDefinition, path, name string, seen map[string]bool) string {
+	obj := att.Type.ToObject()
+	if obj == nil {
+		return ""
+	}
+	var found string
+	obj.IterateAttributes(func(n string, catt *AttributeDefinition) error {
+		if found != "" || !att.IsRequired(n) {
+			return nil
+		}
+		p := n
+		if path != "" {
+			p = path + "." + n
+		}
+		switch t := catt.Type.(type) {
+		case *UserTypeDefinition, *MediaTypeDefinition:
+			ut, ok := t.(*UserTypeDefinition)
+			if !ok {
+				ut = t.(*MediaTypeDefinition).UserTypeDefinition
+			}
+			if ut.TypeName == name {
+				found = p
+				return nil
+			}
+			if seen[ut.TypeName] {
+				return nil
+			}
+			seen[ut.TypeName] = true
+			found = requiredCycle(ut.AttributeDefinition, p, name, seen)
+		case Object:
+			found = requiredCycle(catt, p, name, seen)
+		}
+		return nil
+	})
+	return found
+}
+
 // Validate checks that the media type definition is consistent: its identifier is a valid media
 // type identifier.
 func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
@@ -550,6 +1935,9 @@ func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
 	if obj != nil {
 		for n, att := range obj {
 			verr.Merge(att.Validate("attribute "+n, m))
+			if path := filePath(att, n, map[string]bool{m.TypeName: true}); path != "" {
+				verr.Add(m, "attribute %s is a File, File attributes can only be used in the payloads of actions that use MultipartForm", path)
+			}
 			if att.View != "" {
 				cmt, ok := att.Type.(*MediaTypeDefinition)
 				if !ok {
@@ -575,6 +1963,31 @@ func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
 	for _, l := range m.Links {
 		verr.Merge(l.Validate())
 	}
+
+	for _, e := range m.Embeddables {
+		att, ok := obj[e]
+		if !ok {
+			verr.Add(m, "embeddable relation %#v is not an attribute of the media type", e)
+			continue
+		}
+		rt := att.Type
+		if a := rt.ToArray(); a != nil {
+			rt = a.ElemType.Type
+		}
+		if _, ok := rt.(*MediaTypeDefinition); !ok {
+			verr.Add(m, "embeddable relation %#v must be a media type or a collection of media types", e)
+		}
+		if m.IsRequired(e) {
+			verr.Add(m, "embeddable relation %#v cannot be required", e)
+		}
+	}
+	if m.Geometry != "" {
+		if att, ok := obj[m.Geometry]; !ok {
+			verr.Add(m, "geometry %#v is not an attribute of the media type", m.Geometry)
+		} else if att.Type != Point && att.Type != BoundingBox {
+			verr.Add(m, "geometry attribute %#v must be of type Point or BoundingBox", m.Geometry)
+		}
+	}
 	return verr.AsError()
 }
 
@@ -623,5 +2036,13 @@ func (v *ViewDefinition) Validate() *dslengine.ValidationErrors {
 		verr.Add(v, "View must have a parent media type")
 	}
 	verr.Merge(v.AttributeDefinition.Validate("", v))
+	if v.Parent != nil && v.Parent.Type != nil && v.Type != nil {
+		mtObj := v.Parent.Type.ToObject()
+		for n := range v.Type.ToObject() {
+			if att, ok := mtObj[n]; ok && att.WriteOnly {
+				verr.Add(v, "write-only attribute %#v cannot be rendered in a view", n)
+			}
+		}
+	}
 	return verr.AsError()
 }
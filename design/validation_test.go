@@ -1,6 +1,7 @@
 package design_test
 
 import (
+	"errors"
 	"go/build"
 	"io/ioutil"
 	"os"
@@ -83,6 +84,168 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with a default value lower than the minimum", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						Minimum(10)
+						Default(4)
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - default value 4 is lower than the minimum 10`))
+			})
+		})
+
+		Context("with a default value longer than the maximum length", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, ArrayOf(Integer), func() {
+						MaxLength(1)
+						Default([]interface{}{1, 2})
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("is longer than the maximum length 1"))
+			})
+		})
+
+		Context("with a default value that does not match the pattern", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Pattern("^[a-z]+$")
+						Default("Foo")
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - default value "Foo" does not match the pattern "^[a-z]+$"`))
+			})
+		})
+
+		Context("with an unknown format", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Format("isbn")
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unsupported format "isbn", supported formats are: cidr, currency, date-time`))
+			})
+		})
+
+		Context("with a custom format", func() {
+			var def interface{}
+
+			BeforeEach(func() {
+				def = "DE89370400440532013000"
+				RegisterFormat("iban", func(v string) error {
+					if len(v) < 15 {
+						return errors.New("too short")
+					}
+					return nil
+				})
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Format("iban")
+						Default(def)
+					})
+				}
+			})
+
+			It("accepts the format", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(IsSupportedFormat("iban")).Should(BeTrue())
+				Ω(SupportedFormats()).Should(ContainElement("iban"))
+			})
+
+			Context("with a default value that does not conform to the format", func() {
+				BeforeEach(func() {
+					def = "DE89"
+				})
+				It("produces an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring(`default value "DE89" is not a valid iban value, too short`))
+				})
+			})
+		})
+
+		Context("with a default value that satisfies the validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Pattern("^[a-z]+$")
+						MinLength(2)
+						MaxLength(5)
+						Default("foo")
+					})
+				}
+			})
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(att.DefaultValue).Should(Equal("foo"))
+			})
+		})
+
+		Context("with an example that doesn't exist in enum", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Enum("red", "blue")
+						Example("green")
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - example value "green" is not one of the accepted values: []interface {}{"red", "blue"}`))
+			})
+		})
+
+		Context("with an example greater than the maximum", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						Maximum(10)
+						Example(42)
+					})
+				}
+			})
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "bar": field attName - example value 42 is greater than the maximum 10`))
+			})
+		})
+
+		Context("with an example that satisfies the validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						Pattern("^[a-z]+$")
+						MaxLength(5)
+						Example("foo")
+					})
+				}
+			})
+			It("does not produce an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(att.Example).Should(Equal("foo"))
+			})
+		})
+
 		Context("with a valid format validation", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -293,6 +456,584 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Context("with an action param using an enum type", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			status := Enum("OrderStatus", "open", "closed")
+			Resource("orders", func() {
+				Action("list", func() {
+					Routing(GET("/orders"))
+					Params(func() {
+						Param("status", status)
+					})
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("Param status uses an enum type"))
+		})
+	})
+
+	Context("with a nullable action param", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			Resource("orders", func() {
+				Action("list", func() {
+					Routing(GET("/orders"))
+					Params(func() {
+						Param("owner", String, func() {
+							Nullable()
+						})
+					})
+					Response(NoContent)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("Param owner is nullable"))
+		})
+	})
+
+	Context("with a phone number attribute", func() {
+		var format, region string
+
+		BeforeEach(func() {
+			format = "phone"
+			region = "FR"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Contact", func() {
+				Attribute("mobile", String, func() {
+					Format(format)
+					Metadata("phone:region", region)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("does not report an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			att := Design.Types["Contact"].Type.ToObject()["mobile"]
+			Ω(att.IsPhoneNumber()).Should(BeTrue())
+			Ω(att.PhoneRegion()).Should(Equal("FR"))
+		})
+
+		Context("with an invalid region", func() {
+			BeforeEach(func() {
+				region = "France"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid phone:region metadata "France"`))
+			})
+		})
+
+		Context("with a region but no phone format", func() {
+			BeforeEach(func() {
+				format = "email"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("phone:region metadata can only be used on string attributes with the phone format"))
+			})
+		})
+	})
+
+	Context("with a file server", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = "/assets/*filepath"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("public", func() {
+				Files(path, "/www/data/assets")
+			})
+			dslengine.Run()
+		})
+
+		It("does not report an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["public"].FileServers[0].IsDir()).Should(BeTrue())
+		})
+
+		Context("with a wildcard that is not last", func() {
+			BeforeEach(func() {
+				path = "/assets/*filepath/raw"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("must end with a wildcard starting with *"))
+			})
+		})
+
+		Context("with two wildcards", func() {
+			BeforeEach(func() {
+				path = "/assets/*dir/*filepath"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("may only contain one wildcard"))
+			})
+		})
+	})
+
+	Context("with a catch-all route", func() {
+		var path string
+		var paramType DataType
+
+		BeforeEach(func() {
+			path = "/files/*filepath"
+			paramType = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("files", func() {
+				Action("show", func() {
+					Routing(GET(path))
+					if paramType != nil {
+						Params(func() {
+							Param("filepath", paramType)
+						})
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("defines a string param", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["files"].Actions["show"]
+			Ω(a.Routes[0].CatchAll()).Should(Equal("filepath"))
+			Ω(a.Params.Type.ToObject()).Should(HaveKey("filepath"))
+			Ω(a.Params.Type.ToObject()["filepath"].Type).Should(Equal(String))
+			Ω(a.Params.IsPrimitivePointer("filepath")).Should(BeFalse())
+		})
+
+		Context("with a catch-all that is not last", func() {
+			BeforeEach(func() {
+				path = "/files/*filepath/meta"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("catch-all wildcard must appear at the end of the path"))
+			})
+		})
+
+		Context("with two catch-alls", func() {
+			BeforeEach(func() {
+				path = "/files/*dir/*filepath"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("may only contain one catch-all wildcard"))
+			})
+		})
+
+		Context("with a catch-all param that is not a string", func() {
+			BeforeEach(func() {
+				paramType = Integer
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`catch-all param "filepath" must be a string`))
+			})
+		})
+	})
+
+	Context("with request headers", func() {
+		var headers func()
+
+		BeforeEach(func() {
+			headers = func() {
+				Header("X-Request-Id", String, func() {
+					Format("uuid")
+				})
+				Required("X-Request-Id")
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/bottles"))
+					Headers(headers)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			h := Design.Resources["bottle"].Actions["show"].Headers
+			Ω(h.IsRequired("X-Request-Id")).Should(BeTrue())
+			Ω(h.Type.ToObject()["X-Request-Id"].Validation.Format).Should(Equal("uuid"))
+		})
+
+		Context("with an unsupported format", func() {
+			BeforeEach(func() {
+				headers = func() {
+					Header("X-Request-Id", String, func() {
+						Format("guid")
+					})
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unsupported format "guid"`))
+			})
+		})
+
+		Context("with a required header that is not defined", func() {
+			BeforeEach(func() {
+				headers = func() {
+					Header("X-Request-Id")
+					Required("X-Account")
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`required field "X-Account" does not exist`))
+			})
+		})
+
+		Context("with an object header", func() {
+			BeforeEach(func() {
+				headers = func() {
+					Header("X-Account", func() {
+						Attribute("id", Integer)
+					})
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Header X-Account has an invalid type"))
+			})
+		})
+	})
+
+	Context("with cookies", func() {
+		var cookies func()
+
+		BeforeEach(func() {
+			cookies = func() {
+				Cookie("session", String, func() {
+					MinLength(32)
+				})
+				Required("session")
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Cookies(cookies)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with a cookie that is not a primitive", func() {
+			BeforeEach(func() {
+				cookies = func() {
+					Cookie("prefs", ArrayOf(String))
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Cookie prefs has an invalid type, cookies must be primitives"))
+			})
+		})
+
+		Context("with a cookie named after a param", func() {
+			BeforeEach(func() {
+				cookies = func() {
+					Cookie("id", Integer)
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Cookie id clashes with the param with the same name"))
+			})
+		})
+	})
+
+	Context("with context values", func() {
+		var apiDSL, resourceDSL, actionDSL func()
+
+		BeforeEach(func() {
+			apiDSL = func() {
+				ContextValue("tenant", String)
+				ContextValue("user", String)
+			}
+			resourceDSL = func() {
+				Middleware("tenant", func() {
+					Provides("tenant")
+				})
+			}
+			actionDSL = func() {
+				RequiresContext("tenant")
+			}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", apiDSL)
+			Resource("bottle", func() {
+				resourceDSL()
+				Action("show", func() {
+					Routing(GET("/bottles"))
+					actionDSL()
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["bottle"].Actions["show"]
+			Ω(a.ContextProviders("tenant")).Should(Equal([]string{"tenant"}))
+		})
+
+		Context("with a value that no middleware provides", func() {
+			BeforeEach(func() {
+				actionDSL = func() {
+					RequiresContext("tenant", "user")
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`no middleware provides the required context value "user"`))
+			})
+		})
+
+		Context("with a value provided by the security scheme", func() {
+			BeforeEach(func() {
+				apiDSL = func() {
+					ContextValue("user", String)
+					BasicAuthSecurity("basic", func() {
+						Provides("user")
+					})
+				}
+				resourceDSL = func() {
+					Security("basic")
+				}
+				actionDSL = func() {
+					RequiresContext("user")
+				}
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an undeclared value", func() {
+			BeforeEach(func() {
+				resourceDSL = func() {
+					Middleware("tenant", func() {
+						Provides("tenant", "account")
+					})
+				}
+				actionDSL = func() {
+					RequiresContext("region")
+				}
+			})
+
+			It("reports errors", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`middleware "tenant": unknown context value "account"`))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown context value "region"`))
+			})
+		})
+	})
+
+	Context("with deprecated definitions", func() {
+		var sunset string
+
+		BeforeEach(func() {
+			sunset = "2027-01-31"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottle", func() {
+				Action("list", func() {
+					Metadata("deprecated", sunset)
+					Routing(GET("/bottles"))
+					Params(func() {
+						Param("page", Integer, func() {
+							Metadata("deprecated")
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("does not report an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			action := Design.Resources["bottle"].Actions["list"]
+			deprecated, date := action.Deprecation()
+			Ω(deprecated).Should(BeTrue())
+			Ω(date).Should(Equal("2027-01-31"))
+			deprecated, date = action.Params.Type.ToObject()["page"].Deprecation()
+			Ω(deprecated).Should(BeTrue())
+			Ω(date).Should(BeEmpty())
+		})
+
+		Context("with an invalid sunset date", func() {
+			BeforeEach(func() {
+				sunset = "01/31/2027"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid deprecated metadata "01/31/2027"`))
+			})
+		})
+	})
+
+	Context("with recursive types", func() {
+		var required []string
+
+		BeforeEach(func() {
+			required = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Comment", func() {
+				Attribute("body", String)
+				Attribute("parent", "Comment")
+				Attribute("replies", ArrayOf("Comment"))
+				Attribute("thread", "Thread")
+				Required(required...)
+			})
+			Type("Thread", func() {
+				Attribute("first", "Comment")
+				Required("first")
+			})
+			dslengine.Run()
+		})
+
+		It("does not report an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with required recursive arrays", func() {
+			BeforeEach(func() {
+				required = []string{"replies"}
+			})
+
+			It("does not report an error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with a cycle of required attributes", func() {
+			BeforeEach(func() {
+				required = []string{"thread"}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`type "Comment": recursive attribute thread.first cannot be required`))
+			})
+		})
+	})
+
+	Context("with one of types", func() {
+		var dogValues []interface{}
+		var dogRequired []string
+
+		BeforeEach(func() {
+			dogValues = []interface{}{"dog"}
+			dogRequired = []string{"kind"}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Type("Cat", func() {
+				Attribute("kind", String, func() {
+					Enum("cat", "kitten")
+				})
+				Required("kind")
+			})
+			Type("Dog", func() {
+				Attribute("kind", String, func() {
+					Enum(dogValues...)
+				})
+				Required(dogRequired...)
+			})
+			OneOf("Pet", "Cat", "Dog", func() {
+				Discriminator("kind")
+			})
+			dslengine.Run()
+		})
+
+		It("does not report an error", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		Context("with overlapping discriminator values", func() {
+			BeforeEach(func() {
+				dogValues = []interface{}{"dog", "kitten"}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`discriminator value "kitten" of member type "Dog" is also a value of member type "Cat"`))
+			})
+		})
+
+		Context("with an optional discriminator", func() {
+			BeforeEach(func() {
+				dogRequired = nil
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`discriminator attribute "kind" of member type "Dog" must be a required string attribute`))
+			})
+		})
+	})
+
 	Describe("EncoderDefinition", func() {
 		var (
 			enc           *EncodingDefinition
@@ -0,0 +1,113 @@
+package design
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VersionScheme defines how requests select an API version.
+type VersionScheme string
+
+const (
+	// VersionPath means that requests select the version with a path prefix, e.g. "/v2".
+	VersionPath VersionScheme = "path"
+	// VersionHeader means that requests select the version by setting a header to the
+	// version name.
+	VersionHeader VersionScheme = "header"
+	// VersionQuery means that requests select the version by setting a query string parameter
+	// to the version name.
+	VersionQuery VersionScheme = "query"
+)
+
+type (
+	// VersionDefinition defines one of the versions of the API described by the design.
+	VersionDefinition struct {
+		// Name of the version, e.g. "v2"
+		Name string
+		// Description of the version
+		Description string
+		// Scheme defines how requests select the version.
+		Scheme VersionScheme
+		// Key is the path prefix, header name or query string parameter name used by
+		// requests to select the version depending on Scheme.
+		Key string
+		// Parent API
+		Parent *APIDefinition
+	}
+
+	// VersionIterator is the type of functions given to IterateVersions.
+	VersionIterator func(v *VersionDefinition) error
+)
+
+// Context returns the generic definition name used in error messages.
+func (v *VersionDefinition) Context() string {
+	return fmt.Sprintf("version %#v", v.Name)
+}
+
+// IterateVersions calls the given iterator passing in each API version sorted in alphabetical
+// order. Iteration stops if an iterator returns an error and in this case IterateVersions
+// returns that error.
+func (a *APIDefinition) IterateVersions(it VersionIterator) error {
+	names := make([]string, len(a.Versions))
+	i := 0
+	for n := range a.Versions {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if err := it(a.Versions[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RoutePath returns the path used to request the route with the given full path in the version.
+// Versions selected with a path prefix insert the prefix after the API base path, e.g.
+// "/cellar/v2/accounts", other versions use the path unchanged.
+func (v *VersionDefinition) RoutePath(path string) string {
+	if v.Scheme != VersionPath {
+		return path
+	}
+	var base string
+	if v.Parent != nil {
+		base = strings.TrimSuffix(v.Parent.BasePath, "/")
+	}
+	if base != "" && (path == base || strings.HasPrefix(path, base+"/")) {
+		return base + v.Key + path[len(base):]
+	}
+	return v.Key + path
+}
+
+// SupportsVersion returns true if the resource is exposed by the API version with the given
+// name. Resources that do not list versions are exposed by all versions.
+func (r *ResourceDefinition) SupportsVersion(name string) bool {
+	return supportsVersion(r.Versions, name)
+}
+
+// SupportsVersion returns true if the media type is available in the API version with the given
+// name. Media types that do not list versions are available in all versions. Collection media
+// types are available in the versions of their elements.
+func (m *MediaTypeDefinition) SupportsVersion(name string) bool {
+	if m.IsArray() {
+		if elem, ok := m.ToArray().ElemType.Type.(*MediaTypeDefinition); ok && !elem.SupportsVersion(name) {
+			return false
+		}
+	}
+	return supportsVersion(m.Versions, name)
+}
+
+// supportsVersion returns true if versions is empty or contains name.
+func supportsVersion(versions []string, name string) bool {
+	if len(versions) == 0 {
+		return true
+	}
+	for _, v := range versions {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package goa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithDesignLocation records the location of the design definition that produced err in the
+// error metadata under the "design" key. The generated code calls it on the errors produced while
+// loading and validating requests so that contract mismatches can be traced back to the design
+// immediately. The location consists of the resource and action names followed by the path to
+// the offending attribute when known, for example:
+//
+//	bottle#create.payload.vintage
+//	bottle#show.id
+//
+// When multiple validation errors were merged the attribute path refers to the last one. err is
+// returned unchanged if it is not an *ErrorResponse or if it already has a design location.
+func WithDesignLocation(err error, resource, action string) error {
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		return err
+	}
+	if _, ok := e.Meta["design"]; ok {
+		return err
+	}
+	location := fmt.Sprintf("%s#%s", resource, action)
+	if path := errorAttributePath(e); path != "" {
+		location += "." + path
+	}
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+	e.Meta["design"] = location
+	return e
+}
+
+// ErrorDesignLocation returns the design location recorded in err by WithDesignLocation if any,
+// the empty string otherwise.
+func ErrorDesignLocation(err error) string {
+	if e, ok := err.(*ErrorResponse); ok {
+		if location, ok := e.Meta["design"].(string); ok {
+			return location
+		}
+	}
+	return ""
+}
+
+// errorAttributePath computes the path to the attribute that caused a validation error from the
+// error metadata. The "raw" prefix used by the generated payload validation code is replaced with
+// "payload".
+func errorAttributePath(e *ErrorResponse) string {
+	var path string
+	if p, ok := e.Meta["param"].(string); ok {
+		path = p
+	} else if a, ok := e.Meta["attribute"].(string); ok {
+		path = a
+		if parent, ok := e.Meta["parent"].(string); ok && parent != "" {
+			path = parent + "." + a
+		}
+	}
+	if path == "raw" || strings.HasPrefix(path, "raw.") || strings.HasPrefix(path, "raw[") {
+		path = "payload" + path[3:]
+	}
+	return path
+}
@@ -0,0 +1,79 @@
+package goa_test
+
+import (
+	"errors"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithDesignLocation", func() {
+	var err error
+
+	JustBeforeEach(func() {
+		err = goa.WithDesignLocation(err, "bottle", "create")
+	})
+
+	Context("with a missing payload attribute", func() {
+		BeforeEach(func() {
+			err = goa.MissingAttributeError("raw.address", "city")
+		})
+
+		It("records the path to the attribute", func() {
+			Ω(goa.ErrorDesignLocation(err)).Should(Equal("bottle#create.payload.address.city"))
+			Ω(err.(*goa.ErrorResponse).Meta["design"]).Should(Equal("bottle#create.payload.address.city"))
+		})
+	})
+
+	Context("with an invalid payload attribute", func() {
+		BeforeEach(func() {
+			err = goa.InvalidRangeError("raw.vintage", 1800, 1900, true)
+		})
+
+		It("records the path to the attribute", func() {
+			Ω(goa.ErrorDesignLocation(err)).Should(Equal("bottle#create.payload.vintage"))
+		})
+	})
+
+	Context("with an invalid param", func() {
+		BeforeEach(func() {
+			err = goa.InvalidParamTypeError("id", "foo", "integer")
+		})
+
+		It("records the path to the param", func() {
+			Ω(goa.ErrorDesignLocation(err)).Should(Equal("bottle#create.id"))
+		})
+	})
+
+	Context("with an error that does not identify an attribute", func() {
+		BeforeEach(func() {
+			err = goa.MissingPayloadError()
+		})
+
+		It("records the action", func() {
+			Ω(goa.ErrorDesignLocation(err)).Should(Equal("bottle#create"))
+		})
+	})
+
+	Context("with an error that already has a location", func() {
+		BeforeEach(func() {
+			err = goa.WithDesignLocation(goa.MissingPayloadError(), "account", "update")
+		})
+
+		It("keeps the original location", func() {
+			Ω(goa.ErrorDesignLocation(err)).Should(Equal("account#update"))
+		})
+	})
+
+	Context("with an error that is not an error response", func() {
+		BeforeEach(func() {
+			err = errors.New("boom")
+		})
+
+		It("returns the error unchanged", func() {
+			Ω(err.Error()).Should(Equal("boom"))
+			Ω(goa.ErrorDesignLocation(err)).Should(BeEmpty())
+		})
+	})
+})
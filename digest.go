@@ -0,0 +1,94 @@
+package goa
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DigestAlgorithms lists the algorithms supported in Digest headers indexed by name.
+var DigestAlgorithms = map[string]func() hash.Hash{
+	"SHA-256": sha256.New,
+	"SHA-512": sha512.New,
+	"MD5":     md5.New,
+}
+
+// DigestReader wraps a request body and computes its digest as it is read. The generated code
+// uses it to verify the body of requests sent to actions that require a digest.
+type DigestReader struct {
+	io.ReadCloser
+	algorithm string
+	expected  string
+	hash      hash.Hash
+}
+
+// NewDigestReader returns a reader that computes the digest of the request body using the given
+// algorithm and checks it against the value of the request Digest header (RFC 3230). When the
+// algorithm is MD5 and the request has no Digest header the value of the Content-MD5 header
+// (RFC 1864) is used instead. It returns an integrity error if the header is missing or does not
+// contain a value for the algorithm.
+func NewDigestReader(req *http.Request, algorithm string) (*DigestReader, error) {
+	newHash, ok := DigestAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported digest algorithm %#v", algorithm)
+	}
+	header := req.Header.Get("Digest")
+	if header == "" && algorithm == "MD5" {
+		if md5 := req.Header.Get("Content-MD5"); md5 != "" {
+			header = "MD5=" + md5
+		}
+	}
+	if header == "" {
+		return nil, ErrIntegrity("missing required Digest header", "algorithm", algorithm)
+	}
+	var expected string
+	for _, v := range strings.Split(header, ",") {
+		elems := strings.SplitN(strings.TrimSpace(v), "=", 2)
+		if len(elems) == 2 && strings.EqualFold(elems[0], algorithm) {
+			expected = elems[1]
+			break
+		}
+	}
+	if expected == "" {
+		return nil, ErrIntegrity("Digest header does not include a "+algorithm+" value", "algorithm", algorithm)
+	}
+	return &DigestReader{ReadCloser: req.Body, algorithm: algorithm, expected: expected, hash: newHash()}, nil
+}
+
+// Read reads from the underlying body and updates the digest.
+func (r *DigestReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	return n, err
+}
+
+// Verify reads the remainder of the body and compares the computed digest with the value of the
+// Digest header. It returns an integrity error if they differ.
+func (r *DigestReader) Verify() error {
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return err
+	}
+	actual := base64.StdEncoding.EncodeToString(r.hash.Sum(nil))
+	if actual != r.expected {
+		return ErrIntegrity("request body does not match digest", "algorithm", r.algorithm, "expected", r.expected, "actual", actual)
+	}
+	return nil
+}
+
+// Digest computes the value of the Digest header for the given body using the given algorithm.
+func Digest(algorithm string, body []byte) (string, error) {
+	newHash, ok := DigestAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported digest algorithm %#v", algorithm)
+	}
+	h := newHash()
+	h.Write(body)
+	return algorithm + "=" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
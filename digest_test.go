@@ -0,0 +1,85 @@
+package goa_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestReader", func() {
+	const body = `{"name":"foo"}`
+	var req *http.Request
+	var header string
+
+	BeforeEach(func() {
+		var err error
+		header, err = goa.Digest("SHA-256", []byte(body))
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		if header != "" {
+			req.Header.Set("Digest", header)
+		}
+	})
+
+	It("verifies the body digest", func() {
+		r, err := goa.NewDigestReader(req, "SHA-256")
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := ioutil.ReadAll(r)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(body))
+		Ω(r.Verify()).ShouldNot(HaveOccurred())
+	})
+
+	Context("with a digest that does not match the body", func() {
+		BeforeEach(func() {
+			var err error
+			header, err = goa.Digest("SHA-256", []byte("other"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an integrity error", func() {
+			r, err := goa.NewDigestReader(req, "SHA-256")
+			Ω(err).ShouldNot(HaveOccurred())
+			err = r.Verify()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Code).Should(Equal(goa.IntegrityErrorCode))
+		})
+	})
+
+	Context("with no Digest header", func() {
+		BeforeEach(func() {
+			header = ""
+		})
+
+		It("returns an integrity error", func() {
+			_, err := goa.NewDigestReader(req, "SHA-256")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Code).Should(Equal(goa.IntegrityErrorCode))
+		})
+	})
+
+	Context("with a Content-MD5 header", func() {
+		BeforeEach(func() {
+			header = ""
+		})
+
+		It("verifies the MD5 body digest", func() {
+			d, err := goa.Digest("MD5", []byte(body))
+			Ω(err).ShouldNot(HaveOccurred())
+			req.Header.Set("Content-MD5", strings.TrimPrefix(d, "MD5="))
+			r, err := goa.NewDigestReader(req, "MD5")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(r.Verify()).ShouldNot(HaveOccurred())
+		})
+	})
+})
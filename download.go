@@ -0,0 +1,9 @@
+package goa
+
+import "mime"
+
+// AttachmentDisposition returns the value of the Content-Disposition header that instructs
+// clients to save the response body to a file with the given name.
+func AttachmentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
@@ -0,0 +1,17 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AttachmentDisposition", func() {
+	It("sets the filename parameter", func() {
+		Ω(goa.AttachmentDisposition("report.csv")).Should(Equal("attachment; filename=report.csv"))
+	})
+
+	It("quotes filenames that are not tokens", func() {
+		Ω(goa.AttachmentDisposition("my report.csv")).Should(Equal(`attachment; filename="my report.csv"`))
+	})
+})
@@ -1,6 +1,9 @@
 package dslengine
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 type (
 
@@ -103,6 +106,20 @@ type (
 		// Required list the required fields of object attributes as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 		Required []string
+		// RequiredWhen lists the fields of object attributes that are required only when
+		// another field has a given value.
+		RequiredWhen []*RequiredWhenDefinition
+	}
+
+	// RequiredWhenDefinition describes a conditional required validation: the field Name is
+	// required when the sibling field Field has the value Value.
+	RequiredWhenDefinition struct {
+		// Name is the name of the conditionally required field.
+		Name string
+		// Field is the name of the field whose value is tested.
+		Field string
+		// Value is the value of Field that makes Name required.
+		Value interface{}
 	}
 )
 
@@ -119,6 +136,43 @@ func (t *TraitDefinition) DSL() func() {
 	return t.DSLFunc
 }
 
+// Last returns the last value of the given key, false if the key is not set or has no value.
+func (m MetadataDefinition) Last(key string) (string, bool) {
+	vals := m[key]
+	if len(vals) == 0 {
+		return "", false
+	}
+	return vals[len(vals)-1], true
+}
+
+// Dup returns a copy of m so that values may be added to the copy without modifying m.
+func (m MetadataDefinition) Dup() MetadataDefinition {
+	if m == nil {
+		return nil
+	}
+	res := make(MetadataDefinition, len(m))
+	for k, v := range m {
+		res[k] = append(make([]string, 0, len(v)), v...)
+	}
+	return res
+}
+
+// Merge returns a new metadata definition containing the keys of m and other. The values of the
+// keys set in both come from other. Merge returns nil if both m and other are empty.
+func (m MetadataDefinition) Merge(other MetadataDefinition) MetadataDefinition {
+	if len(m) == 0 && len(other) == 0 {
+		return nil
+	}
+	res := make(MetadataDefinition, len(m)+len(other))
+	for k, v := range m {
+		res[k] = v
+	}
+	for k, v := range other {
+		res[k] = v
+	}
+	return res
+}
+
 // Context returns the generic definition name used in error messages.
 func (v *ValidationDefinition) Context() string {
 	return "validation"
@@ -148,6 +202,7 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 		v.MaxLength = other.MaxLength
 	}
 	v.AddRequired(other.Required)
+	v.AddRequiredWhen(other.RequiredWhen)
 }
 
 // AddRequired merges the required fields from other into v
@@ -166,6 +221,22 @@ func (v *ValidationDefinition) AddRequired(required []string) {
 	}
 }
 
+// AddRequiredWhen merges the conditional required validations from other into v.
+func (v *ValidationDefinition) AddRequiredWhen(requiredWhen []*RequiredWhenDefinition) {
+	for _, r := range requiredWhen {
+		found := false
+		for _, rr := range v.RequiredWhen {
+			if r.Name == rr.Name && r.Field == rr.Field && reflect.DeepEqual(r.Value, rr.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.RequiredWhen = append(v.RequiredWhen, r)
+		}
+	}
+}
+
 // HasRequiredOnly returns true if the validation only has the Required field with a non-zero value.
 func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if len(v.Values) > 0 {
@@ -177,19 +248,23 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MaxLength != nil) {
 		return false
 	}
+	if len(v.RequiredWhen) > 0 {
+		return false
+	}
 	return true
 }
 
 // Dup makes a shallow dup of the validation.
 func (v *ValidationDefinition) Dup() *ValidationDefinition {
 	return &ValidationDefinition{
-		Values:    v.Values,
-		Format:    v.Format,
-		Pattern:   v.Pattern,
-		Minimum:   v.Minimum,
-		Maximum:   v.Maximum,
-		MinLength: v.MinLength,
-		MaxLength: v.MaxLength,
-		Required:  v.Required,
+		Values:       v.Values,
+		Format:       v.Format,
+		Pattern:      v.Pattern,
+		Minimum:      v.Minimum,
+		Maximum:      v.Maximum,
+		MinLength:    v.MinLength,
+		MaxLength:    v.MaxLength,
+		Required:     v.Required,
+		RequiredWhen: v.RequiredWhen,
 	}
 }
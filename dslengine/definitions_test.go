@@ -0,0 +1,62 @@
+package dslengine_test
+
+import (
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetadataDefinition", func() {
+	var md dslengine.MetadataDefinition
+
+	BeforeEach(func() {
+		md = dslengine.MetadataDefinition{
+			"struct:tag:json": {"name", "omitempty"},
+			"sql:column":      {"name"},
+			"empty":           {},
+		}
+	})
+
+	Describe("Last", func() {
+		It("returns the last value of the key", func() {
+			v, ok := md.Last("struct:tag:json")
+			Ω(ok).Should(BeTrue())
+			Ω(v).Should(Equal("omitempty"))
+		})
+
+		It("returns false for keys with no value", func() {
+			_, ok := md.Last("empty")
+			Ω(ok).Should(BeFalse())
+			_, ok = md.Last("unknown")
+			Ω(ok).Should(BeFalse())
+			_, ok = dslengine.MetadataDefinition(nil).Last("unknown")
+			Ω(ok).Should(BeFalse())
+		})
+	})
+
+	Describe("Dup", func() {
+		It("returns a copy", func() {
+			dup := md.Dup()
+			Ω(dup).Should(Equal(md))
+			dup["sql:column"] = append(dup["sql:column"], "full_name")
+			Ω(md["sql:column"]).Should(Equal([]string{"name"}))
+		})
+	})
+
+	Describe("Merge", func() {
+		It("returns the keys of both with the values of other winning", func() {
+			merged := md.Merge(dslengine.MetadataDefinition{"sql:column": {"full_name"}, "sql:index": {"true"}})
+			Ω(merged).Should(Equal(dslengine.MetadataDefinition{
+				"struct:tag:json": {"name", "omitempty"},
+				"sql:column":      {"full_name"},
+				"sql:index":       {"true"},
+				"empty":           {},
+			}))
+			Ω(md["sql:column"]).Should(Equal([]string{"name"}))
+		})
+
+		It("returns nil when both are empty", func() {
+			Ω(dslengine.MetadataDefinition(nil).Merge(nil)).Should(BeNil())
+		})
+	})
+})
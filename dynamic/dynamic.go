@@ -0,0 +1,588 @@
+/*
+Package dynamic serves an API straight from its design without code generation. The design is
+loaded at startup from the JSON hyper-schema written by "goagen schema" and the actions it
+describes are mounted on a goa service. The request payloads are decoded into generic values
+and validated against the schema, the responses are rendered by a Handler or, by default, mocked
+using the examples of the design.
+
+Dynamic mode is slower than the generated code and the params are not typed but it makes it
+possible to stand up an API without compiling it, e.g. for prototyping, mock environments or to
+preview design changes:
+
+	schema, err := dynamic.LoadFile("schema/schema.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	service := goa.New("cellar")
+	service.Use(middleware.ErrorHandler(service, true))
+	if err := dynamic.Mount(service, schema, nil); err != nil {
+		log.Fatal(err)
+	}
+	service.ListenAndServe(":8080")
+
+The query string params are validated against the "x-params" extension of the links written by
+"goagen schema", they are given to the handlers as strings together with the path params.
+*/
+package dynamic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Schema is a JSON hyper-schema as written by "goagen schema". The root schema describes
+	// the API and its definitions describe the resources and types.
+	Schema struct {
+		ID                   string             `json:"id,omitempty"`
+		Title                string             `json:"title,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Definitions          map[string]*Schema `json:"definitions,omitempty"`
+		DefaultValue         interface{}        `json:"default,omitempty"`
+		Example              interface{}        `json:"example,omitempty"`
+		Links                []*Link            `json:"links,omitempty"`
+		Ref                  string             `json:"$ref,omitempty"`
+		Enum                 []interface{}      `json:"enum,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		Minimum              *float64           `json:"minimum,omitempty"`
+		Maximum              *float64           `json:"maximum,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		AdditionalProperties bool               `json:"additionalProperties,omitempty"`
+		AnyOf                []*Schema          `json:"anyOf,omitempty"`
+		Nullable             bool               `json:"x-nullable,omitempty"`
+
+		// pattern is Pattern compiled by Load.
+		pattern *regexp.Regexp
+	}
+
+	// Link describes an action of a resource.
+	Link struct {
+		// Title is the name of the action.
+		Title string `json:"title,omitempty"`
+		// Rel is the relation of the link, "self" for the canonical action.
+		Rel string `json:"rel,omitempty"`
+		// Href is the action path, path params are written {name}.
+		Href string `json:"href,omitempty"`
+		// Method is the HTTP method of the action.
+		Method string `json:"method,omitempty"`
+		// Schema describes the action payload if any.
+		Schema *Schema `json:"schema,omitempty"`
+		// TargetSchema describes the action response body if any.
+		TargetSchema *Schema `json:"targetSchema,omitempty"`
+		// MediaType is the identifier of the response media type if any.
+		MediaType string `json:"mediaType,omitempty"`
+		// Params describes the action query string params if any.
+		Params *Schema `json:"x-params,omitempty"`
+	}
+
+	// Request is a request made to an action served in dynamic mode.
+	Request struct {
+		// Resource is the name of the resource.
+		Resource string
+		// Action is the name of the action.
+		Action string
+		// Params contains the path and query string params.
+		Params url.Values
+		// Payload is the decoded and validated request payload if any.
+		Payload interface{}
+		// Link is the schema link describing the action.
+		Link *Link
+	}
+
+	// Handler implements the actions served in dynamic mode. It returns the response status
+	// code and body, a nil body produces an empty response.
+	Handler func(ctx context.Context, req *Request) (int, interface{}, error)
+)
+
+// hrefParamRegex matches the path params of the schema hrefs.
+var hrefParamRegex = regexp.MustCompile(`\{([a-zA-Z0-9_]+)\}`)
+
+// Load reads a JSON hyper-schema. It returns an error if the schema patterns are not valid
+// regular expressions.
+func Load(r io.Reader) (*Schema, error) {
+	var s Schema
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("invalid JSON hyper-schema: %s", err)
+	}
+	if err := compile(&s, "schema"); err != nil {
+		return nil, fmt.Errorf("invalid JSON hyper-schema: %s", err)
+	}
+	return &s, nil
+}
+
+// LoadFile reads the JSON hyper-schema stored in the given file.
+func LoadFile(path string) (*Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Mount mounts the actions described by schema onto service, one controller is created per
+// resource. The actions are implemented by handler, a nil handler uses Mock. The schema itself
+// is served at /schema. Mount registers the JSON encoder and decoder with the service.
+func Mount(service *goa.Service, schema *Schema, handler Handler) error {
+	if handler == nil {
+		handler = Mock(schema)
+	}
+	service.Encoder.Register(goa.NewJSONEncoder, "application/json", "*/*")
+	service.Decoder.Register(goa.NewJSONDecoder, "application/json", "*/*")
+	names := make([]string, 0, len(schema.Definitions))
+	for n := range schema.Definitions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	// The links to related media types point to the canonical actions of their resource, the
+	// first route of a canonical action is the link with the "self" relation.
+	canonical := make(map[string]bool)
+	for _, def := range schema.Definitions {
+		for _, l := range def.Links {
+			if l.Rel == "self" {
+				canonical[route(l)] = true
+			}
+		}
+	}
+	mounted := make(map[string]bool)
+	for _, res := range names {
+		def := schema.Definitions[res]
+		var ctrl *goa.Controller
+		for _, l := range def.Links {
+			if l.Method == "" || l.Href == "" {
+				continue
+			}
+			r := route(l)
+			if mounted[r] || l.Rel != "self" && canonical[r] {
+				continue
+			}
+			mounted[r] = true
+			path := hrefParamRegex.ReplaceAllString(l.Href, ":$1")
+			if ctrl == nil {
+				ctrl = service.NewController(res)
+			}
+			goa.LogInfo(ctrl.Context, "mount", "ctrl", res, "action", l.Title, "route", l.Method+" "+path)
+			ctrl.Service.Mux.Handle(l.Method, path, ctrl.MuxHandler(l.Title, handle(service, schema, res, l, handler), unmarshal(schema, l)))
+		}
+	}
+	js, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	if !mounted["GET /schema"] {
+		ctrl := service.NewController("Schema")
+		ctrl.Service.Mux.Handle("GET", "/schema", ctrl.MuxHandler("show", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Header().Set("Content-Type", "application/schema+json")
+			_, err := rw.Write(js)
+			return err
+		}, nil))
+	}
+	return nil
+}
+
+// Mock returns a handler that responds to the actions described by schema with the example of
+// their response body. The example is built from the examples, default values and enums of the
+// schema when there is none.
+func Mock(schema *Schema) Handler {
+	return func(ctx context.Context, req *Request) (int, interface{}, error) {
+		if req.Link.TargetSchema == nil {
+			return http.StatusNoContent, nil, nil
+		}
+		status := http.StatusOK
+		if req.Link.Method == "POST" {
+			status = http.StatusCreated
+		}
+		return status, Example(schema, req.Link.TargetSchema), nil
+	}
+}
+
+// Validate validates val against s, root is the schema that contains the definitions referred
+// to by s. ctx is the name of the value used in the error messages, e.g. "payload".
+func Validate(root, s *Schema, ctx string, val interface{}) error {
+	s = resolve(root, s)
+	if s == nil {
+		return nil
+	}
+	if val == nil {
+		return nil
+	}
+	if len(s.AnyOf) > 0 {
+		var err error
+		for _, alt := range s.AnyOf {
+			if err = Validate(root, alt, ctx, val); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+	if len(s.Enum) > 0 && !contains(s.Enum, val) {
+		return goa.InvalidEnumValueError(ctx, val, s.Enum)
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return goa.InvalidAttributeTypeError(ctx, val, "object")
+		}
+		var err error
+		for _, n := range s.Required {
+			if v, ok := obj[n]; !ok || v == nil && !resolveNullable(root, s.Properties[n]) {
+				err = goa.MergeErrors(err, goa.MissingAttributeError(ctx, n))
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for n := range s.Properties {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if v, ok := obj[n]; ok {
+				err = goa.MergeErrors(err, Validate(root, s.Properties[n], ctx+"."+n, v))
+			}
+		}
+		return err
+	case "array":
+		arr, ok := val.([]interface{})
+		if !ok {
+			return goa.InvalidAttributeTypeError(ctx, val, "array")
+		}
+		var err error
+		err = goa.MergeErrors(err, validateLength(s, ctx, val, len(arr)))
+		if s.Items != nil {
+			for i, e := range arr {
+				err = goa.MergeErrors(err, Validate(root, s.Items, fmt.Sprintf("%s[%d]", ctx, i), e))
+			}
+		}
+		return err
+	case "string":
+		str, ok := val.(string)
+		if !ok {
+			return goa.InvalidAttributeTypeError(ctx, val, "string")
+		}
+		var err error
+		if f := goa.Format(s.Format); f != "" && goa.IsKnownFormat(f) {
+			if ferr := goa.ValidateFormat(f, str); ferr != nil {
+				err = goa.MergeErrors(err, goa.InvalidFormatError(ctx, str, f, ferr))
+			}
+		}
+		if s.Pattern != "" {
+			var ok bool
+			if s.pattern != nil {
+				ok = s.pattern.MatchString(str)
+			} else {
+				// s was not loaded with Load
+				ok, _ = regexp.MatchString(s.Pattern, str)
+			}
+			if !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(ctx, str, s.Pattern))
+			}
+		}
+		return goa.MergeErrors(err, validateLength(s, ctx, val, len([]rune(str))))
+	case "integer", "number":
+		f, ok := val.(float64)
+		if !ok || s.Type == "integer" && f != math.Trunc(f) {
+			return goa.InvalidAttributeTypeError(ctx, val, s.Type)
+		}
+		var err error
+		if s.Minimum != nil && f < *s.Minimum {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(ctx, f, *s.Minimum, true))
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(ctx, f, *s.Maximum, false))
+		}
+		return err
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			return goa.InvalidAttributeTypeError(ctx, val, "boolean")
+		}
+	}
+	return nil
+}
+
+// Example returns an example value for s, root is the schema that contains the definitions
+// referred to by s.
+func Example(root, s *Schema) interface{} {
+	return example(root, s, make(map[string]bool))
+}
+
+// example implements Example, seen records the definitions being rendered to stop recursion.
+func example(root, s *Schema, seen map[string]bool) interface{} {
+	if s != nil && s.Ref != "" {
+		if seen[s.Ref] {
+			return nil
+		}
+		seen[s.Ref] = true
+		defer delete(seen, s.Ref)
+	}
+	s = resolve(root, s)
+	switch {
+	case s == nil:
+		return nil
+	case s.Example != nil:
+		return s.Example
+	case s.DefaultValue != nil:
+		return s.DefaultValue
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AnyOf) > 0:
+		return example(root, s.AnyOf[0], seen)
+	}
+	switch s.Type {
+	case "object":
+		obj := make(map[string]interface{}, len(s.Properties))
+		for n, p := range s.Properties {
+			if v := example(root, p, seen); v != nil {
+				obj[n] = v
+			}
+		}
+		return obj
+	case "array":
+		if s.Items == nil {
+			return []interface{}{}
+		}
+		if v := example(root, s.Items, seen); v != nil {
+			return []interface{}{v}
+		}
+		return []interface{}{}
+	case "string":
+		return ""
+	case "integer", "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// handle returns the goa handler of the action described by l, root is the schema that contains
+// the definitions referred to by l.
+func handle(service *goa.Service, root *Schema, res string, l *Link, handler Handler) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		r := goa.ContextRequest(ctx)
+		if err := validateParams(root, l, r.Params); err != nil {
+			return err
+		}
+		status, body, err := handler(ctx, &Request{
+			Resource: res,
+			Action:   l.Title,
+			Params:   r.Params,
+			Payload:  r.Payload,
+			Link:     l,
+		})
+		if err != nil {
+			return err
+		}
+		resp := goa.ContextResponse(ctx)
+		if body == nil {
+			resp.WriteHeader(status)
+			return nil
+		}
+		if l.MediaType != "" {
+			resp.Header().Set("Content-Type", l.MediaType)
+		}
+		return service.Send(ctx, status, body)
+	}
+}
+
+// route returns the method and href of l.
+func route(l *Link) string {
+	return l.Method + " " + l.Href
+}
+
+// unmarshal returns the unmarshaler that decodes and validates the payload of the action
+// described by l, it returns nil if the action has no payload.
+func unmarshal(root *Schema, l *Link) goa.Unmarshaler {
+	if l.Schema == nil {
+		return nil
+	}
+	return func(ctx context.Context, service *goa.Service, req *http.Request) error {
+		var payload interface{}
+		if err := service.DecodeRequest(req, &payload); err != nil {
+			return err
+		}
+		if err := Validate(root, l.Schema, "payload", payload); err != nil {
+			return err
+		}
+		goa.ContextRequest(ctx).Payload = payload
+		return nil
+	}
+}
+
+// validateParams validates the query string params against the params schema of l.
+func validateParams(root *Schema, l *Link, params url.Values) error {
+	s := resolve(root, l.Params)
+	if s == nil {
+		return nil
+	}
+	var err error
+	for _, n := range s.Required {
+		if _, ok := params[n]; !ok {
+			err = goa.MergeErrors(err, goa.MissingParamError(n))
+		}
+	}
+	names := make([]string, 0, len(s.Properties))
+	for n := range s.Properties {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		raw, ok := params[n]
+		if !ok || len(raw) == 0 {
+			continue
+		}
+		p := resolve(root, s.Properties[n])
+		if p == nil {
+			continue
+		}
+		val, perr := paramValue(root, p, n, raw)
+		if perr != nil {
+			err = goa.MergeErrors(err, perr)
+			continue
+		}
+		err = goa.MergeErrors(err, Validate(root, p, n, val))
+	}
+	return err
+}
+
+// paramValue converts the raw values of the param name to the JSON value described by s so they
+// can be validated.
+func paramValue(root, s *Schema, name string, raw []string) (interface{}, error) {
+	if s.Type == "array" {
+		items := resolve(root, s.Items)
+		if items == nil {
+			items = &Schema{}
+		}
+		vals := make([]interface{}, len(raw))
+		for i, r := range raw {
+			v, err := paramValue(root, items, name, []string{r})
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = v
+		}
+		return vals, nil
+	}
+	switch s.Type {
+	case "integer", "number":
+		f, err := strconv.ParseFloat(raw[0], 64)
+		if err != nil {
+			return nil, goa.InvalidParamTypeError(name, raw[0], s.Type)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw[0])
+		if err != nil {
+			return nil, goa.InvalidParamTypeError(name, raw[0], s.Type)
+		}
+		return b, nil
+	}
+	return raw[0], nil
+}
+
+// compile compiles the patterns of s and of the schemas it contains. ctx is the path of s used in
+// the error messages.
+func compile(s *Schema, ctx string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" && s.pattern == nil {
+		r, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q of %s: %s", s.Pattern, ctx, err)
+		}
+		s.pattern = r
+	}
+	if err := compile(s.Items, ctx+".items"); err != nil {
+		return err
+	}
+	for n, p := range s.Properties {
+		if err := compile(p, ctx+".properties."+n); err != nil {
+			return err
+		}
+	}
+	for n, d := range s.Definitions {
+		if err := compile(d, "#/definitions/"+n); err != nil {
+			return err
+		}
+	}
+	for i, alt := range s.AnyOf {
+		if err := compile(alt, fmt.Sprintf("%s.anyOf[%d]", ctx, i)); err != nil {
+			return err
+		}
+	}
+	for _, l := range s.Links {
+		lctx := ctx + " link " + l.Title
+		if err := compile(l.Schema, lctx+" schema"); err != nil {
+			return err
+		}
+		if err := compile(l.TargetSchema, lctx+" targetSchema"); err != nil {
+			return err
+		}
+		if err := compile(l.Params, lctx+" params"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLength validates the length of a string or array.
+func validateLength(s *Schema, ctx string, val interface{}, ln int) error {
+	if s.MinLength != nil && ln < *s.MinLength {
+		return goa.InvalidLengthError(ctx, val, ln, *s.MinLength, true)
+	}
+	if s.MaxLength != nil && ln > *s.MaxLength {
+		return goa.InvalidLengthError(ctx, val, ln, *s.MaxLength, false)
+	}
+	return nil
+}
+
+// resolve follows the references of s to the definitions of root.
+func resolve(root, s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		s = root.Definitions[name]
+	}
+	return s
+}
+
+// resolveNullable returns true if the schema referred to by s accepts null values.
+func resolveNullable(root, s *Schema) bool {
+	s = resolve(root, s)
+	return s != nil && s.Nullable
+}
+
+// contains returns true if vals contains val. Numbers are compared as float64 since decoded
+// JSON numbers and the enum values of the schema both are.
+func contains(vals []interface{}, val interface{}) bool {
+	for _, v := range vals {
+		if reflect.DeepEqual(v, val) {
+			return true
+		}
+	}
+	return false
+}
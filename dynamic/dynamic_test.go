@@ -0,0 +1,295 @@
+package dynamic_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/dynamic"
+	"github.com/goadesign/goa/middleware"
+)
+
+const schema = `{
+  "$schema": "http://json-schema.org/draft-04/hyper-schema",
+  "id": "http://localhost/schema",
+  "title": "Cellar",
+  "type": "object",
+  "definitions": {
+    "BottleMedia": {
+      "title": "Mediatype identifier: application/vnd.bottle+json",
+      "type": "object",
+      "properties": {
+        "id": {"type": "integer", "example": 1},
+        "name": {"type": "string", "example": "Number 8"},
+        "color": {"type": "string", "enum": ["red", "white"]},
+        "account": {"$ref": "#/definitions/AccountMedia"}
+      },
+      "required": ["id", "name"],
+      "links": [{
+        "title": "account",
+        "rel": "account",
+        "href": "/cellar/accounts/{accountID}",
+        "method": "GET",
+        "targetSchema": {"$ref": "#/definitions/AccountMedia"},
+        "mediaType": "application/vnd.account+json"
+      }]
+    },
+    "AccountMedia": {
+      "title": "Mediatype identifier: application/vnd.account+json",
+      "type": "object",
+      "properties": {
+        "id": {"type": "integer", "example": 7},
+        "bottles": {"type": "array", "items": {"$ref": "#/definitions/BottleMedia"}}
+      }
+    },
+    "account": {
+      "title": "account",
+      "type": "object",
+      "links": [{
+        "title": "show",
+        "rel": "self",
+        "href": "/cellar/accounts/{accountID}",
+        "method": "GET",
+        "targetSchema": {"$ref": "#/definitions/AccountMedia"},
+        "mediaType": "application/vnd.account+json"
+      }]
+    },
+    "bottle": {
+      "title": "bottle",
+      "type": "object",
+      "links": [{
+        "title": "show",
+        "rel": "self",
+        "href": "/cellar/bottles/{bottleID}",
+        "method": "GET",
+        "targetSchema": {"$ref": "#/definitions/BottleMedia"},
+        "mediaType": "application/vnd.bottle+json"
+      }, {
+        "title": "create",
+        "rel": "create",
+        "href": "/cellar/bottles",
+        "method": "POST",
+        "schema": {
+          "type": "object",
+          "properties": {
+            "name": {"type": "string", "minLength": 2},
+            "vintage": {"type": "integer", "minimum": 1900},
+            "color": {"type": "string", "enum": ["red", "white"]}
+          },
+          "required": ["name"]
+        }
+      }, {
+        "title": "list",
+        "rel": "list",
+        "href": "/cellar/bottles",
+        "method": "GET",
+        "x-params": {
+          "type": "object",
+          "properties": {
+            "years": {"type": "array", "items": {"type": "integer", "minimum": 1900}},
+            "sort": {"type": "string", "pattern": "^(name|vintage)$"},
+            "sommelier": {"type": "string", "format": "sommelier"}
+          },
+          "required": ["sort"]
+        }
+      }, {
+        "title": "delete",
+        "rel": "delete",
+        "href": "/cellar/bottles/{bottleID}",
+        "method": "DELETE"
+      }]
+    }
+  }
+}`
+
+func newService(t *testing.T, handler dynamic.Handler) *goa.Service {
+	s, err := dynamic.Load(strings.NewReader(schema))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	service := goa.New("cellar")
+	service.Use(middleware.ErrorHandler(service, true))
+	if err := dynamic.Mount(service, s, handler); err != nil {
+		t.Fatalf("failed to mount schema: %s", err)
+	}
+	return service
+}
+
+func serve(service *goa.Service, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rw := httptest.NewRecorder()
+	service.Mux.ServeHTTP(rw, req)
+	return rw
+}
+
+func TestMock(t *testing.T) {
+	service := newService(t, nil)
+
+	rw := serve(service, "GET", "/cellar/bottles/1", "")
+	if rw.Code != 200 {
+		t.Fatalf("got status %d, expected 200: %s", rw.Code, rw.Body.String())
+	}
+	if ct := rw.Header().Get("Content-Type"); ct != "application/vnd.bottle+json" {
+		t.Errorf("got content type %q, expected application/vnd.bottle+json", ct)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rw.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response body %q: %s", rw.Body.String(), err)
+	}
+	if body["id"] != 1.0 || body["name"] != "Number 8" || body["color"] != "red" {
+		t.Errorf("unexpected example %v", body)
+	}
+	account, ok := body["account"].(map[string]interface{})
+	if !ok || account["id"] != 7.0 {
+		t.Errorf("unexpected account example %v", body["account"])
+	}
+
+	rw = serve(service, "POST", "/cellar/bottles", `{"name": "Number 9"}`)
+	if rw.Code != 204 {
+		t.Errorf("got status %d, expected 204", rw.Code)
+	}
+
+	rw = serve(service, "GET", "/cellar/accounts/7", "")
+	if rw.Code != 200 || rw.Header().Get("Content-Type") != "application/vnd.account+json" {
+		t.Errorf("got status %d and content type %q", rw.Code, rw.Header().Get("Content-Type"))
+	}
+
+	rw = serve(service, "GET", "/schema", "")
+	if rw.Code != 200 || !strings.Contains(rw.Body.String(), `"title":"Cellar"`) {
+		t.Errorf("got status %d and body %q", rw.Code, rw.Body.String())
+	}
+}
+
+func TestValidation(t *testing.T) {
+	service := newService(t, nil)
+
+	rw := serve(service, "POST", "/cellar/bottles", `{"name": "N", "vintage": 1800.5, "color": "blue"}`)
+	if rw.Code != 400 {
+		t.Fatalf("got status %d, expected 400", rw.Code)
+	}
+	body := rw.Body.String()
+	for _, msg := range []string{
+		`payload.color must be one of`,
+		`length of payload.name must be greater than or equal to 2`,
+		`type of payload.vintage must be integer`,
+	} {
+		if !strings.Contains(body, msg) {
+			t.Errorf("response %q does not contain %q", body, msg)
+		}
+	}
+
+	rw = serve(service, "POST", "/cellar/bottles", `{"vintage": 1800}`)
+	if rw.Code != 400 {
+		t.Fatalf("got status %d, expected 400", rw.Code)
+	}
+	body = rw.Body.String()
+	for _, msg := range []string{
+		`attribute \"name\" of payload is missing and required`,
+		`payload.vintage must be greater than or equal to 1900`,
+	} {
+		if !strings.Contains(body, msg) {
+			t.Errorf("response %q does not contain %q", body, msg)
+		}
+	}
+}
+
+func TestParamsValidation(t *testing.T) {
+	service := newService(t, nil)
+
+	rw := serve(service, "GET", "/cellar/bottles?sort=name&years=1990&years=2001&sommelier=jo", "")
+	if rw.Code != 204 {
+		t.Fatalf("got status %d, expected 204: %s", rw.Code, rw.Body.String())
+	}
+
+	rw = serve(service, "GET", "/cellar/bottles?sort=color&years=1800", "")
+	if rw.Code != 400 {
+		t.Fatalf("got status %d, expected 400", rw.Code)
+	}
+	body := rw.Body.String()
+	for _, msg := range []string{
+		`sort must match the regexp`,
+		`years[0] must be greater than or equal to 1900`,
+	} {
+		if !strings.Contains(body, msg) {
+			t.Errorf("response %q does not contain %q", body, msg)
+		}
+	}
+
+	rw = serve(service, "GET", "/cellar/bottles?years=recent", "")
+	if rw.Code != 400 {
+		t.Fatalf("got status %d, expected 400", rw.Code)
+	}
+	body = rw.Body.String()
+	for _, msg := range []string{
+		`missing required parameter \"sort\"`,
+		`invalid value \"recent\" for parameter \"years\", must be a integer`,
+	} {
+		if !strings.Contains(body, msg) {
+			t.Errorf("response %q does not contain %q", body, msg)
+		}
+	}
+}
+
+func TestLoadInvalidPattern(t *testing.T) {
+	_, err := dynamic.Load(strings.NewReader(`{"definitions": {"Bottle": {"properties": {"name": {"type": "string", "pattern": "("}}}}}`))
+	if err == nil || !strings.Contains(err.Error(), `invalid pattern "(" of #/definitions/Bottle.properties.name`) {
+		t.Errorf("got error %v, expected invalid pattern error", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var got *dynamic.Request
+	service := newService(t, func(ctx context.Context, req *dynamic.Request) (int, interface{}, error) {
+		got = req
+		if req.Action == "delete" {
+			return 404, nil, nil
+		}
+		return 200, map[string]interface{}{"id": req.Params.Get("bottleID")}, nil
+	})
+
+	rw := serve(service, "GET", "/cellar/bottles/42?view=tiny", "")
+	if rw.Code != 200 || strings.TrimSpace(rw.Body.String()) != `{"id":"42"}` {
+		t.Errorf("got status %d and body %q", rw.Code, rw.Body.String())
+	}
+	if got.Resource != "bottle" || got.Action != "show" || got.Params.Get("view") != "tiny" {
+		t.Errorf("unexpected request %+v", got)
+	}
+
+	rw = serve(service, "POST", "/cellar/bottles", `{"name": "Number 9"}`)
+	payload, ok := got.Payload.(map[string]interface{})
+	if rw.Code != 200 || !ok || payload["name"] != "Number 9" {
+		t.Errorf("got status %d and payload %v", rw.Code, got.Payload)
+	}
+
+	rw = serve(service, "DELETE", "/cellar/bottles/42", "")
+	if rw.Code != 404 || rw.Body.Len() != 0 {
+		t.Errorf("got status %d and body %q", rw.Code, rw.Body.String())
+	}
+}
+
+func TestExample(t *testing.T) {
+	s, err := dynamic.Load(strings.NewReader(schema))
+	if err != nil {
+		t.Fatalf("failed to load schema: %s", err)
+	}
+	// AccountMedia and BottleMedia refer to each other.
+	ex := dynamic.Example(s, &dynamic.Schema{Ref: "#/definitions/AccountMedia"})
+	account, ok := ex.(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected example %#v", ex)
+	}
+	bottles, ok := account["bottles"].([]interface{})
+	if !ok || len(bottles) != 1 {
+		t.Fatalf("unexpected bottles example %#v", account["bottles"])
+	}
+	if _, ok := bottles[0].(map[string]interface{})["account"]; ok {
+		t.Errorf("recursive example %#v", bottles[0])
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"mime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -60,6 +61,7 @@ type (
 	// known Content-Type to decoder mapping.
 	HTTPDecoder struct {
 		pools map[string]*decoderPool // Registered decoders
+		index atomic.Value            // Decoders indexed by raw Content-Type, see compile
 	}
 
 	// HTTPEncoder is a Encoder that encodes HTTP request or response bodies given a set of
@@ -67,9 +69,20 @@ type (
 	HTTPEncoder struct {
 		pools        map[string]*encoderPool // Registered encoders
 		contentTypes []string                // List of content types for type negotiation
+		index        atomic.Value            // Negotiated encoders indexed by Accept, see compile
+	}
+
+	// negotiatedEncoder is the result of the content type negotiation for a given Accept value.
+	negotiatedEncoder struct {
+		contentType string
+		pool        *encoderPool
 	}
 )
 
+// compiledCharsets lists the charset parameters added to the registered content types when
+// indexing the decoders.
+var compiledCharsets = []string{"; charset=utf-8", ";charset=utf-8", "; charset=UTF-8"}
+
 // NewJSONEncoder is an adapter for the encoding package JSON encoder.
 func NewJSONEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
 
@@ -106,7 +119,24 @@ func NewHTTPDecoder() *HTTPDecoder {
 func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType string) error {
 	now := time.Now()
 	defer MeasureSince([]string{"goa", "decode", contentType}, now)
-	var p *decoderPool
+	index, _ := decoder.index.Load().(map[string]*decoderPool)
+	p, ok := index[contentType]
+	if !ok {
+		p = decoder.lookup(contentType)
+	}
+	if p == nil {
+		return nil
+	}
+
+	// the decoderPool will handle whether or not a pool is actually in use
+	d := p.Get(body)
+	defer p.Put(d)
+	return d.Decode(v)
+}
+
+// lookup returns the decoder registered for the given Content-Type header value, nil if there
+// isn't one and there is no default decoder.
+func (decoder *HTTPDecoder) lookup(contentType string) *decoderPool {
 	if contentType == "" {
 		// Default to JSON
 		contentType = "application/json"
@@ -115,23 +145,32 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 			contentType = mediaType
 		}
 	}
-	p = decoder.pools[contentType]
+	p := decoder.pools[contentType]
 	if p == nil {
 		p = decoder.pools["*/*"]
 	}
-	if p == nil {
-		return nil
-	}
+	return p
+}
 
-	// the decoderPool will handle whether or not a pool is actually in use
-	d := p.Get(body)
-	defer p.Put(d)
-	return d.Decode(v)
+// compile indexes the decoders by the Content-Type header values that requests commonly use so
+// that Decode does not need to parse them.
+func (decoder *HTTPDecoder) compile() {
+	index := make(map[string]*decoderPool, len(decoder.pools)*(len(compiledCharsets)+1)+1)
+	index[""] = decoder.lookup("")
+	for contentType := range decoder.pools {
+		index[contentType] = decoder.lookup(contentType)
+		for _, charset := range compiledCharsets {
+			index[contentType+charset] = decoder.lookup(contentType + charset)
+		}
+	}
+	decoder.index.Store(index)
 }
 
 // Register sets a specific decoder to be used for the specified content types. If a decoder is
 // already registered, it is overwritten.
 func (decoder *HTTPDecoder) Register(f DecoderFunc, contentTypes ...string) {
+	// Decoders registered after the service was compiled invalidate the index.
+	decoder.index.Store(map[string]*decoderPool(nil))
 	p := newDecodePool(f)
 
 	for _, contentType := range contentTypes {
@@ -186,6 +225,30 @@ func (p *decoderPool) Put(d Decoder) {
 // using the given writer.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
 	now := time.Now()
+	index, _ := encoder.index.Load().(map[string]negotiatedEncoder)
+	n, ok := index[accept]
+	if !ok {
+		n = encoder.negotiate(accept)
+	}
+	contentType, p := n.contentType, n.pool
+	defer MeasureSince([]string{"goa", "encode", contentType}, now)
+	if p == nil {
+		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
+	}
+
+	// the encoderPool will handle whether or not a pool is actually in use
+	e := p.Get(resp)
+	if err := e.Encode(v); err != nil {
+		return err
+	}
+	p.Put(e)
+
+	return nil
+}
+
+// negotiate returns the content type and the encoder used to encode responses for the given
+// Accept header value.
+func (encoder *HTTPEncoder) negotiate(accept string) negotiatedEncoder {
 	if accept == "" {
 		accept = "*/*"
 	}
@@ -196,28 +259,28 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 			break
 		}
 	}
-	defer MeasureSince([]string{"goa", "encode", contentType}, now)
 	p := encoder.pools[contentType]
 	if p == nil && contentType != "*/*" {
 		p = encoder.pools["*/*"]
 	}
-	if p == nil {
-		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
-	}
+	return negotiatedEncoder{contentType: contentType, pool: p}
+}
 
-	// the encoderPool will handle whether or not a pool is actually in use
-	e := p.Get(resp)
-	if err := e.Encode(v); err != nil {
-		return err
+// compile indexes the result of the content type negotiation by the Accept header values that
+// select a registered encoder so that Encode does not need to negotiate.
+func (encoder *HTTPEncoder) compile() {
+	index := make(map[string]negotiatedEncoder, len(encoder.contentTypes)+2)
+	for _, accept := range append([]string{"", "*/*"}, encoder.contentTypes...) {
+		index[accept] = encoder.negotiate(accept)
 	}
-	p.Put(e)
-
-	return nil
+	encoder.index.Store(index)
 }
 
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
 // already registered, it is overwritten.
 func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
+	// Encoders registered after the service was compiled invalidate the index.
+	encoder.index.Store(map[string]negotiatedEncoder(nil))
 	p := newEncodePool(f)
 	for _, contentType := range contentTypes {
 		mediaType, _, err := mime.ParseMediaType(contentType)
@@ -2,6 +2,10 @@
 Package form provides a "application/x-www-form-encoding" encoder and decoder.  It uses
 github.com/ajg/form for the actual implementation which can be used directly as well.  The goal of
 this package is to raise awareness of the package above and its direct compatibility with goa.
+
+Note that goa provides its own "application/x-www-form-urlencoded" encoder and decoder, see
+goa.NewFormEncoder and goa.NewFormDecoder. These are used by default when the design lists the
+MIME type in Consumes or Produces without a package.
 */
 package form
 
@@ -0,0 +1,164 @@
+/*
+Package enrich implements the caching used by the request enrichment stage. Actions that declare
+enrichments with the Enrich DSL resolve values derived from their params, e.g. a user record from
+a user ID, before the controller runs. The generated code looks up the values using the functions
+mounted with the generated Use<Action><Resource>Enrichers function and stores them in the request
+context where the controller retrieves them with the generated action context methods, e.g.:
+
+	app.UseShowBottleEnrichers(service, &app.ShowBottleEnrichers{
+		Owner: func(ctx context.Context, ownerID int) (interface{}, error) {
+			return db.LoadUser(ctx, ownerID)
+		},
+	})
+
+	func (c *BottleController) Show(ctx *app.ShowBottleContext) error {
+		owner := ctx.Owner().(*User)
+		...
+	}
+
+Concurrent lookups of the same key are coalesced into a single call and the results are cached
+for the duration specified in the design.
+*/
+package enrich
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"context"
+)
+
+type (
+	// FetchFunc is the function that looks up a value.
+	FetchFunc func(ctx context.Context) (interface{}, error)
+
+	// Cache caches the values looked up by key. Concurrent lookups of the same key result in a
+	// single call to the fetch function. Expired values are evicted at most once per TTL.
+	Cache struct {
+		// TTL is the duration during which the values are cached, zero disables caching.
+		TTL time.Duration
+
+		mu        sync.Mutex
+		entries   map[string]*entry
+		calls     map[string]*call
+		now       func() time.Time
+		nextSweep time.Time
+	}
+
+	// entry is a cached value.
+	entry struct {
+		value   interface{}
+		expires time.Time
+	}
+
+	// call is a lookup in progress. canceled is true if the context of the caller that
+	// made the lookup was canceled before the lookup completed.
+	call struct {
+		done     chan struct{}
+		value    interface{}
+		err      error
+		canceled bool
+	}
+
+	// valueKey is the type of the context keys used to store the enriched values.
+	valueKey string
+)
+
+// NewCache creates a cache that keeps the values for the given duration.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		TTL:     ttl,
+		entries: make(map[string]*entry),
+		calls:   make(map[string]*call),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value cached for key if any, otherwise it calls fetch. If a call for the same
+// key is in progress Get waits for it and returns its result. Errors are not cached. If the call
+// in progress fails because the context of its caller is canceled Get makes a new call with ctx.
+// If fetch panics the callers waiting for the call get an error and the panic is propagated to
+// the caller of fetch.
+func (c *Cache) Get(ctx context.Context, key string, fetch FetchFunc) (interface{}, error) {
+	c.mu.Lock()
+	now := c.now()
+	if now.After(c.nextSweep) {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.TTL)
+	}
+	if e, ok := c.entries[key]; ok {
+		if now.Before(e.expires) {
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		delete(c.entries, key)
+	}
+	if cl, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+			if cl.canceled && ctx.Err() == nil {
+				return c.Get(ctx, key, fetch)
+			}
+			return cl.value, cl.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			cl.value, cl.err = nil, fmt.Errorf("lookup of %q panicked: %v", key, r)
+			c.finish(key, cl)
+			panic(r)
+		}
+	}()
+	cl.value, cl.err = fetch(ctx)
+	cl.canceled = cl.err != nil && ctx.Err() != nil
+	c.finish(key, cl)
+	return cl.value, cl.err
+}
+
+// finish records the result of the call made for key and releases the callers waiting for it.
+func (c *Cache) finish(key string, cl *call) {
+	c.mu.Lock()
+	delete(c.calls, key)
+	if cl.err == nil && c.TTL > 0 {
+		c.entries[key] = &entry{value: cl.value, expires: c.now().Add(c.TTL)}
+	}
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+// Len returns the number of values held in the cache including the expired values that have
+// not been evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Purge removes the value cached for key if any.
+func (c *Cache) Purge(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// WithValue returns a copy of ctx that holds the enriched value with the given name.
+func WithValue(ctx context.Context, name string, value interface{}) context.Context {
+	return context.WithValue(ctx, valueKey(name), value)
+}
+
+// Value returns the enriched value with the given name stored in ctx, nil if there is none.
+func Value(ctx context.Context, name string) interface{} {
+	return ctx.Value(valueKey(name))
+}
@@ -0,0 +1,180 @@
+package enrich_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa/enrich"
+)
+
+func TestGetCaches(t *testing.T) {
+	c := enrich.NewCache(time.Minute)
+	var calls int32
+	fetch := func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "joe", nil
+	}
+	for i := 0; i < 3; i++ {
+		v, err := c.Get(context.Background(), "1", fetch)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if v != "joe" {
+			t.Errorf("got %v, expected joe", v)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls, expected 1", calls)
+	}
+	c.Purge("1")
+	c.Get(context.Background(), "1", fetch)
+	if calls != 2 {
+		t.Errorf("got %d calls after purge, expected 2", calls)
+	}
+}
+
+func TestGetCoalescesConcurrentCalls(t *testing.T) {
+	c := enrich.NewCache(time.Minute)
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 42, nil
+	}
+	var wg sync.WaitGroup
+	results := make([]interface{}, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.Get(context.Background(), "k", fetch)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("got %d calls, expected 1", calls)
+	}
+	for i, r := range results {
+		if r != 42 {
+			t.Errorf("result %d: got %v, expected 42", i, r)
+		}
+	}
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	c := enrich.NewCache(time.Minute)
+	var calls int32
+	fetch := func(context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, errors.New("not found")
+	}
+	c.Get(context.Background(), "1", fetch)
+	if _, err := c.Get(context.Background(), "1", fetch); err == nil {
+		t.Error("expected an error")
+	}
+	if calls != 2 {
+		t.Errorf("got %d calls, expected 2", calls)
+	}
+}
+
+func TestValue(t *testing.T) {
+	ctx := enrich.WithValue(context.Background(), "user", "joe")
+	if v := enrich.Value(ctx, "user"); v != "joe" {
+		t.Errorf("got %v, expected joe", v)
+	}
+	if v := enrich.Value(ctx, "account"); v != nil {
+		t.Errorf("got %v, expected nil", v)
+	}
+}
+
+func TestGetEvictsExpiredValues(t *testing.T) {
+	c := enrich.NewCache(10 * time.Millisecond)
+	fetch := func(context.Context) (interface{}, error) { return "joe", nil }
+	c.Get(context.Background(), "1", fetch)
+	c.Get(context.Background(), "2", fetch)
+	time.Sleep(20 * time.Millisecond)
+	c.Get(context.Background(), "3", fetch)
+	if n := c.Len(); n != 1 {
+		t.Errorf("got %d cached values, expected 1", n)
+	}
+}
+
+func TestGetRetriesWhenLeaderIsCanceled(t *testing.T) {
+	c := enrich.NewCache(time.Minute)
+	started := make(chan struct{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	go c.Get(leaderCtx, "k", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+	done := make(chan struct{})
+	var v interface{}
+	var err error
+	go func() {
+		defer close(done)
+		v, err = c.Get(context.Background(), "k", func(context.Context) (interface{}, error) {
+			return 42, nil
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-done
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v != 42 {
+		t.Errorf("got %v, expected 42", v)
+	}
+}
+
+func TestGetRecoversFromPanickingFetch(t *testing.T) {
+	c := enrich.NewCache(time.Minute)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	leader := make(chan interface{}, 1)
+	go func() {
+		defer func() { leader <- recover() }()
+		c.Get(context.Background(), "k", func(context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := c.Get(context.Background(), "k", func(context.Context) (interface{}, error) {
+			return "unexpected", nil
+		})
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if r := <-leader; r != "boom" {
+		t.Errorf("got leader panic %v, expected boom", r)
+	}
+	select {
+	case err := <-waiter:
+		if err == nil {
+			t.Error("expected an error for the caller waiting on the panicking lookup")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("caller waiting on the panicking lookup blocked")
+	}
+	v, err := c.Get(context.Background(), "k", func(context.Context) (interface{}, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Errorf("got %v, %v after panic, expected 42, nil", v, err)
+	}
+}
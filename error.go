@@ -21,6 +21,11 @@ encountered error via the Error Merge method. The helper functions are error cla
 global variable. This means your code can override their values to produce arbitrary error
 responses.
 
+The helper functions build their errors with the ErrorBuilder returned by NewError. Controllers may
+use it too so that the errors they produce have the same structure:
+
+	return goa.NewError(goa.ErrorKindInvalidRange).Field("payload.age").Value(age).Expected(">= 18").Err()
+
 goa includes an error handler middleware that takes care of mapping back any error returned by
 previously called middleware or action handler into HTTP responses. If the error was created via an
 error class then the corresponding content including the HTTP status is used otherwise an internal
@@ -35,6 +40,15 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
+)
+
+const (
+	// IntegrityErrorCode is the code of the errors created with ErrIntegrity.
+	IntegrityErrorCode = "integrity_error"
+
+	// RequestTooLargeCode is the code of the errors created with ErrRequestBodyTooLarge.
+	RequestTooLargeCode = "request_too_large"
 )
 
 var (
@@ -56,7 +70,7 @@ var (
 
 	// ErrRequestBodyTooLarge is the error produced when the size of a request body exceeds
 	// MaxRequestBodyLength bytes.
-	ErrRequestBodyTooLarge = NewErrorClass("request_too_large", 413)
+	ErrRequestBodyTooLarge = NewErrorClass(RequestTooLargeCode, 413)
 
 	// ErrNoAuthMiddleware is the error produced when no auth middleware is mounted for a
 	// security scheme defined in the design.
@@ -75,6 +89,32 @@ var (
 
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
+
+	// ErrRateLimited is the class of errors returned to requests that exceed a rate limit.
+	ErrRateLimited = NewErrorClass("rate_limited", 429)
+
+	// ErrUnavailable is the class of errors returned when the service is temporarily unable to
+	// handle requests.
+	ErrUnavailable = NewErrorClass("unavailable", 503)
+
+	// ErrIntegrity is the class of errors produced when a request body does not match the
+	// value of its Digest header.
+	ErrIntegrity = NewErrorClass(IntegrityErrorCode, 400)
+
+	// ErrPreconditionRequired is the class of errors produced when a request made to an action
+	// that requires optimistic concurrency control does not specify the If-Match header.
+	ErrPreconditionRequired = NewErrorClass("precondition_required", 428)
+
+	// ErrPreconditionFailed is the class of errors produced when the version specified in a
+	// request If-Match header does not match the current resource version.
+	ErrPreconditionFailed = NewErrorClass("precondition_failed", 412)
+
+	// ErrInvalidTransition is the class of errors produced when a request attempts to move a
+	// resource to a state that cannot be reached from its current state.
+	ErrInvalidTransition = NewErrorClass("invalid_transition", 409)
+
+	// ErrGone is the class of errors returned to requests made to a retired alias path.
+	ErrGone = NewErrorClass("gone", 410)
 )
 
 type (
@@ -116,12 +156,22 @@ type (
 		ID string `json:"id" xml:"id" form:"id"`
 		// Code identifies the class of errors.
 		Code string `json:"code" xml:"code" form:"code"`
+		// Kind is the code of the ErrorKind of the errors produced by the generated code
+		// when a request does not match the design, e.g. "missing_param".
+		Kind string `json:"kind,omitempty" xml:"kind,omitempty" form:"kind,omitempty"`
 		// Status is the HTTP status code used by responses that cary the error.
 		Status int `json:"status" xml:"status" form:"status"`
 		// Detail describes the specific error occurrence.
 		Detail string `json:"detail" xml:"detail" form:"detail"`
 		// Meta contains additional key/value pairs useful to clients.
 		Meta map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+		// RetryAfter is the number of seconds clients should wait before retrying the
+		// request, zero if not specified. The error handler middleware sets the
+		// Retry-After response header accordingly.
+		RetryAfter int `json:"retry_after,omitempty" xml:"retry_after,omitempty" form:"retry_after,omitempty"`
+		// Transient is true if the error condition is temporary and the request may be
+		// retried.
+		Transient bool `json:"transient,omitempty" xml:"transient,omitempty" form:"transient,omitempty"`
 	}
 )
 
@@ -159,40 +209,86 @@ func NewErrorClass(code string, status int) ErrorClass {
 
 // MissingPayloadError is the error produced when a request is missing a required payload.
 func MissingPayloadError() error {
-	return ErrInvalidRequest("missing required payload")
+	return NewError(ErrorKindMissingPayload).Detail("missing required payload").Err()
 }
 
 // InvalidParamTypeError is the error produced when the type of a parameter does not match the type
 // defined in the design.
 func InvalidParamTypeError(name string, val interface{}, expected string) error {
-	msg := fmt.Sprintf("invalid value %#v for parameter %#v, must be a %s", val, name, expected)
-	return ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected)
+	return NewError(ErrorKindInvalidParamType).
+		Detail("invalid value %#v for parameter %#v, must be a %s", val, name, expected).
+		Meta("param", name).
+		Value(val).
+		Expected(expected).
+		Err()
 }
 
 // MissingParamError is the error produced for requests that are missing path or querystring
 // parameters.
 func MissingParamError(name string) error {
-	msg := fmt.Sprintf("missing required parameter %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return NewError(ErrorKindMissingParam).
+		Detail("missing required parameter %#v", name).
+		Meta("name", name).
+		Err()
 }
 
 // InvalidAttributeTypeError is the error produced when the type of payload field does not match
 // the type defined in the design.
 func InvalidAttributeTypeError(ctx string, val interface{}, expected string) error {
-	msg := fmt.Sprintf("type of %s must be %s but got value %#v", ctx, expected, val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected)
+	return NewError(ErrorKindInvalidAttributeType).
+		Detail("type of %s must be %s but got value %#v", ctx, expected, val).
+		Field(ctx).
+		Value(val).
+		Expected(expected).
+		Err()
 }
 
 // MissingAttributeError is the error produced when a request payload is missing a required field.
 func MissingAttributeError(ctx, name string) error {
-	msg := fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx)
-	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx)
+	return NewError(ErrorKindMissingAttribute).
+		Detail("attribute %#v of %s is missing and required", name, ctx).
+		Field(name).
+		Meta("parent", ctx).
+		Err()
+}
+
+// MissingNullableAttributeError is the error produced when a request payload is missing a
+// required field that accepts null. Such fields must be present but may be set to null explicitly.
+func MissingNullableAttributeError(ctx, name string) error {
+	return NewError(ErrorKindMissingAttribute).
+		Detail("attribute %#v of %s is missing and required, it may be set to null but not omitted", name, ctx).
+		Field(name).
+		Meta("parent", ctx).
+		Meta("nullable", true).
+		Err()
+}
+
+// MissingConditionalAttributeError is the error produced when a request payload is missing a field
+// that is required because another field of the payload has a given value.
+func MissingConditionalAttributeError(ctx, name, field string, value interface{}) error {
+	return NewError(ErrorKindMissingAttribute).
+		Detail("attribute %#v of %s is missing and required when %#v is %#v", name, ctx, field, value).
+		Field(name).
+		Meta("parent", ctx).
+		Meta("when", field).
+		Value(value).
+		Err()
 }
 
 // MissingHeaderError is the error produced when a request is missing a required header.
 func MissingHeaderError(name string) error {
-	msg := fmt.Sprintf("missing required HTTP header %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return NewError(ErrorKindMissingHeader).
+		Detail("missing required HTTP header %#v", name).
+		Meta("name", name).
+		Err()
+}
+
+// MissingCookieError is the error produced when a request is missing a required cookie.
+func MissingCookieError(name string) error {
+	return NewError(ErrorKindMissingCookie).
+		Detail("missing required cookie %#v", name).
+		Meta("name", name).
+		Err()
 }
 
 // InvalidEnumValueError is the error produced when the value of a parameter or payload field does
@@ -202,22 +298,36 @@ func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}) e
 	for i, a := range allowed {
 		elems[i] = fmt.Sprintf("%#v", a)
 	}
-	msg := fmt.Sprintf("value of %s must be one of %s but got value %#v", ctx, strings.Join(elems, ", "), val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	expected := strings.Join(elems, ", ")
+	return NewError(ErrorKindInvalidEnumValue).
+		Detail("value of %s must be one of %s but got value %#v", ctx, expected, val).
+		Field(ctx).
+		Value(val).
+		Expected(expected).
+		Err()
 }
 
 // InvalidFormatError is the error produced when the value of a parameter or payload field does not
 // match the format validation defined in the design.
 func InvalidFormatError(ctx, target string, format Format, formatError error) error {
-	msg := fmt.Sprintf("%s must be formatted as a %s but got value %#v, %s", ctx, format, target, formatError.Error())
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	return NewError(ErrorKindInvalidFormat).
+		Detail("%s must be formatted as a %s but got value %#v, %s", ctx, format, target, formatError.Error()).
+		Field(ctx).
+		Value(target).
+		Expected(format).
+		Meta("error", formatError.Error()).
+		Err()
 }
 
 // InvalidPatternError is the error produced when the value of a parameter or payload field does
 // not match the pattern validation defined in the design.
 func InvalidPatternError(ctx, target string, pattern string) error {
-	msg := fmt.Sprintf("%s must match the regexp %#v but got value %#v", ctx, pattern, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern)
+	return NewError(ErrorKindInvalidPattern).
+		Detail("%s must match the regexp %#v but got value %#v", ctx, pattern, target).
+		Field(ctx).
+		Value(target).
+		Meta("regexp", pattern).
+		Err()
 }
 
 // InvalidRangeError is the error produced when the value of a parameter or payload field does
@@ -227,8 +337,76 @@ func InvalidRangeError(ctx string, target interface{}, value interface{}, min bo
 	if !min {
 		comp = "less than or equal to"
 	}
-	msg := fmt.Sprintf("%s must be %s %v but got value %#v", ctx, comp, value, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	return NewError(ErrorKindInvalidRange).
+		Detail("%s must be %s %v but got value %#v", ctx, comp, value, target).
+		Field(ctx).
+		Value(target).
+		Expected(value).
+		Meta("comp", comp).
+		Err()
+}
+
+// InvalidTransitionError is the error produced when the value of a payload field that describes a
+// lifecycle is not one of the states that can be reached from the current state.
+func InvalidTransitionError(ctx, from, to string, allowed []string) error {
+	elems := make([]string, len(allowed))
+	for i, a := range allowed {
+		elems[i] = fmt.Sprintf("%#v", a)
+	}
+	msg := fmt.Sprintf("%s cannot transition from %#v to %#v", ctx, from, to)
+	if len(elems) > 0 {
+		msg += fmt.Sprintf(", allowed next states are %s", strings.Join(elems, ", "))
+	} else {
+		msg += fmt.Sprintf(", %#v is a final state", from)
+	}
+	return NewError(ErrorKindInvalidTransition).
+		Class(ErrInvalidTransition).
+		Detail("%s", msg).
+		Field(ctx).
+		Meta("from", from).
+		Meta("to", to).
+		Meta("allowed", allowed).
+		Err()
+}
+
+// InvalidOneOfError is the error produced when a value of a OneOf type does not hold exactly one
+// value of the member types, count is the number of member values that are set.
+func InvalidOneOfError(ctx string, count int, members []string) error {
+	expected := strings.Join(members, ", ")
+	return NewError(ErrorKindInvalidOneOf).
+		Detail("%s must hold exactly one of %s but got %d values", ctx, expected, count).
+		Field(ctx).
+		Expected(expected).
+		Meta("count", count).
+		Err()
+}
+
+// InvalidPhoneNumberError is the error produced when the value of a parameter or payload field
+// with the phone format is not a phone number. region is the region used to interpret numbers in
+// national format, empty if there is none.
+func InvalidPhoneNumberError(ctx, target, region string, phoneError error) error {
+	e := NewError(ErrorKindInvalidPhoneNumber).
+		Detail("%s must be a phone number but got value %#v, %s", ctx, target, phoneError.Error()).
+		Field(ctx).
+		Value(target).
+		Expected(FormatPhone).
+		Meta("error", phoneError.Error())
+	if region != "" {
+		e = e.Meta("region", region)
+	}
+	return e.Err()
+}
+
+// UnsupportedPhoneRegionError is the error produced when the value of a parameter or payload
+// field with the phone format is a number in national format and the country calling code of the
+// region used to interpret it is not known.
+func UnsupportedPhoneRegionError(ctx, target, region string) error {
+	return NewError(ErrorKindUnsupportedPhoneRegion).
+		Detail("%s uses the national phone number %#v but region %#v is not supported, use the international format", ctx, target, region).
+		Field(ctx).
+		Value(target).
+		Meta("region", region).
+		Err()
 }
 
 // InvalidLengthError is the error produced when the value of a parameter or payload field does
@@ -238,8 +416,14 @@ func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool)
 	if !min {
 		comp = "less than or equal to"
 	}
-	msg := fmt.Sprintf("length of %s must be %s %d but got value %#v (len=%d)", ctx, comp, value, target, ln)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	return NewError(ErrorKindInvalidLength).
+		Detail("length of %s must be %s %d but got value %#v (len=%d)", ctx, comp, value, target, ln).
+		Field(ctx).
+		Value(target).
+		Expected(value).
+		Meta("len", ln).
+		Meta("comp", comp).
+		Err()
 }
 
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
@@ -260,6 +444,29 @@ func MethodNotAllowedError(method string, allowed []string) error {
 	return ErrMethodNotAllowed(msg, "method", method, "allowed", strings.Join(allowed, ", "))
 }
 
+// RateLimitError is the error produced when a request exceeds a rate limit. retryAfter indicates
+// how long clients should wait before retrying the request, it is rounded up to the second.
+func RateLimitError(message interface{}, retryAfter time.Duration, keyvals ...interface{}) error {
+	return retryable(ErrRateLimited(message, keyvals...), retryAfter)
+}
+
+// UnavailableError is the error produced when the service is temporarily unable to handle the
+// request. retryAfter indicates how long clients should wait before retrying the request, it is
+// rounded up to the second. A zero value means unknown.
+func UnavailableError(message interface{}, retryAfter time.Duration, keyvals ...interface{}) error {
+	return retryable(ErrUnavailable(message, keyvals...), retryAfter)
+}
+
+// retryable flags the given error response as transient and sets its retry delay.
+func retryable(err error, retryAfter time.Duration) error {
+	e := err.(*ErrorResponse)
+	e.Transient = true
+	if retryAfter > 0 {
+		e.RetryAfter = int((retryAfter + time.Second - 1) / time.Second)
+	}
+	return e
+}
+
 // Error returns the error occurrence details.
 func (e *ErrorResponse) Error() string {
 	msg := fmt.Sprintf("[%s] %d %s: %s", e.ID, e.Status, e.Code, e.Detail)
@@ -285,6 +492,8 @@ func (e *ErrorResponse) Token() string { return e.ID }
 //
 // * If the status or code of e and other don't match then the result is a 400 "bad_request"
 //
+// * If the kind of e and other don't match then the result has no kind
+//
 // The Detail field is updated by concatenating the Detail fields of e and other separated
 // by a semi-colon. The MetaValues field of is updated by merging the map of other MetaValues
 // into e's where values in e with identical keys to values in other get overwritten.
@@ -322,6 +531,9 @@ func MergeErrors(err, other error) error {
 		e.Status = 400
 		e.Code = "bad_request"
 	}
+	if e.Kind != o.Kind {
+		e.Kind = ""
+	}
 	e.Detail = e.Detail + "; " + o.Detail
 
 	if e.Meta == nil && len(o.Meta) > 0 {
@@ -0,0 +1,120 @@
+package goa
+
+import "fmt"
+
+// ErrorBuilder builds structured errors with a fluent API, see NewError. The helper functions
+// used by the generated code such as InvalidParamTypeError or InvalidRangeError build their errors
+// with it. A builder must not be used after Err is called.
+type ErrorBuilder struct {
+	kind     ErrorKind
+	class    ErrorClass
+	detail   string
+	field    string
+	value    interface{}
+	hasValue bool
+	expected interface{}
+	hasExp   bool
+	keyvals  []interface{}
+}
+
+// NewError returns a builder of errors of the given kind. The errors belong to the
+// ErrInvalidRequest class unless Class is used. Controllers may use the builder to produce errors
+// that are structured like the ones produced by the generated code:
+//
+//	return goa.NewError(goa.ErrorKindInvalidRange).
+//		Field("payload.age").
+//		Value(age).
+//		Expected(">= 18").
+//		Meta("hint", "users must be adults").
+//		Err()
+func NewError(kind ErrorKind) *ErrorBuilder {
+	return &ErrorBuilder{kind: kind}
+}
+
+// Class sets the class of the error, ErrInvalidRequest by default.
+func (b *ErrorBuilder) Class(class ErrorClass) *ErrorBuilder {
+	b.class = class
+	return b
+}
+
+// Detail sets the error detail using fmt.Sprintf. The detail defaults to a message built from the
+// kind title, the field, the expected value and the value.
+func (b *ErrorBuilder) Detail(format string, args ...interface{}) *ErrorBuilder {
+	b.detail = fmt.Sprintf(format, args...)
+	return b
+}
+
+// Field sets the name of the invalid parameter or attribute, e.g. "payload.age". It is returned to
+// clients in the "attribute" metadata key.
+func (b *ErrorBuilder) Field(name string) *ErrorBuilder {
+	b.field = name
+	return b
+}
+
+// Value sets the invalid value. It is returned to clients in the "value" metadata key.
+func (b *ErrorBuilder) Value(v interface{}) *ErrorBuilder {
+	b.value = v
+	b.hasValue = true
+	return b
+}
+
+// Expected describes the expected value, e.g. a type name or a range bound. It is returned to
+// clients in the "expected" metadata key.
+func (b *ErrorBuilder) Expected(e interface{}) *ErrorBuilder {
+	b.expected = e
+	b.hasExp = true
+	return b
+}
+
+// Meta adds a metadata key/value pair returned to clients.
+func (b *ErrorBuilder) Meta(key string, value interface{}) *ErrorBuilder {
+	b.keyvals = append(b.keyvals, key, value)
+	return b
+}
+
+// Err returns the error. The error is an *ErrorResponse whose Kind field is set to the code of the
+// builder kind unless the error class was overridden to produce errors of a different type. The
+// JSON pointer to the invalid attribute is recorded when the field is rooted in a document, see
+// ErrorPointer.
+func (b *ErrorBuilder) Err() error {
+	class := b.class
+	if class == nil {
+		class = ErrInvalidRequest
+	}
+	var keyvals []interface{}
+	if b.field != "" {
+		keyvals = append(keyvals, "attribute", b.field)
+	}
+	if b.hasValue {
+		keyvals = append(keyvals, "value", b.value)
+	}
+	if b.hasExp {
+		keyvals = append(keyvals, "expected", b.expected)
+	}
+	keyvals = append(keyvals, b.keyvals...)
+	detail := b.detail
+	if detail == "" {
+		detail = b.defaultDetail()
+	}
+	err := WithErrorKind(class(detail, keyvals...), b.kind)
+	if e, ok := err.(*ErrorResponse); ok {
+		setErrorPointer(e)
+	}
+	return err
+}
+
+// defaultDetail returns the detail of errors built without calling Detail, e.g. "Invalid range for
+// payload.age, expected >= 18, got 12".
+func (b *ErrorBuilder) defaultDetail() string {
+	msg := b.kind.Title()
+	if b.field != "" {
+		msg += " for " + b.field
+	}
+	if b.hasExp {
+		msg += fmt.Sprintf(", expected %v", b.expected)
+	}
+	if b.hasValue {
+		msg += fmt.Sprintf(", got %#v", b.value)
+	}
+	return msg
+}
@@ -0,0 +1,73 @@
+package goa
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorBuilder", func() {
+	It("builds structured errors", func() {
+		err := NewError(ErrorKindInvalidRange).
+			Field("payload.age").
+			Value(12).
+			Expected(">= 18").
+			Meta("hint", "users must be adults").
+			Err()
+		Ω(err).Should(HaveOccurred())
+		Ω(err).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		resp := err.(*ErrorResponse)
+		Ω(resp.Status).Should(Equal(400))
+		Ω(resp.Code).Should(Equal("invalid_request"))
+		Ω(resp.Kind).Should(Equal("invalid_range"))
+		Ω(resp.Detail).Should(Equal("Invalid range for payload.age, expected >= 18, got 12"))
+		Ω(resp.Meta).Should(Equal(map[string]interface{}{
+			"attribute": "payload.age",
+			"value":     12,
+			"expected":  ">= 18",
+			"hint":      "users must be adults",
+			"pointer":   "/age",
+		}))
+	})
+
+	It("uses the given detail", func() {
+		err := NewError(ErrorKindMissingHeader).Detail("missing %s", "X-Key").Err()
+		Ω(err.(*ErrorResponse).Detail).Should(Equal("missing X-Key"))
+		Ω(err.(*ErrorResponse).Meta).Should(BeEmpty())
+	})
+
+	It("uses the given class", func() {
+		err := NewError(ErrorKindInvalidTransition).Class(ErrInvalidTransition).Err()
+		resp := err.(*ErrorResponse)
+		Ω(resp.Status).Should(Equal(409))
+		Ω(resp.Kind).Should(Equal("invalid_transition"))
+		Ω(resp.Detail).Should(Equal("Invalid transition"))
+	})
+
+	It("records nil values", func() {
+		err := NewError(ErrorKindInvalidAttributeType).Field("payload.name").Value(nil).Err()
+		Ω(err.(*ErrorResponse).Meta).Should(HaveKey("value"))
+		Ω(err.(*ErrorResponse).Detail).Should(Equal("Invalid attribute type for payload.name, got <nil>"))
+	})
+
+	It("leaves errors of custom classes untouched", func() {
+		custom := func(interface{}, ...interface{}) error { return errors.New("custom") }
+		err := NewError(ErrorKindInvalidFormat).Class(custom).Err()
+		Ω(err).Should(MatchError("custom"))
+	})
+
+	It("produces the same errors as the helper functions", func() {
+		helper := InvalidRangeError("payload.age", 12, 18, true).(*ErrorResponse)
+		built := NewError(ErrorKindInvalidRange).
+			Detail("payload.age must be greater than or equal to 18 but got value 12").
+			Field("payload.age").
+			Value(12).
+			Expected(18).
+			Meta("comp", "greater than or equal to").
+			Err().(*ErrorResponse)
+		Ω(built.Detail).Should(Equal(helper.Detail))
+		Ω(built.Kind).Should(Equal(helper.Kind))
+		Ω(built.Meta).Should(Equal(helper.Meta))
+	})
+})
@@ -0,0 +1,186 @@
+package goa
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrorKind identifies the kind of error produced by the code generated by goagen when a request
+// does not match the design, e.g. a missing parameter or a value that fails a validation. All
+// these errors belong to the ErrInvalidRequest class, the kind tells them apart.
+//
+// Each kind has a stable snake_case code that is sent to clients in the "kind" field of the error
+// responses so that they can switch on the code rather than on human readable messages. Codes
+// are never changed or reused once released and new kinds are only ever added, clients should
+// treat the codes they do not know as ErrorKindUnknown.
+//
+// Services may define additional kinds for their own errors with RegisterErrorKind. The methods
+// of ErrorKind never panic: kinds that are not registered behave like ErrorKindUnknown.
+type ErrorKind int
+
+const (
+	// ErrorKindUnknown is the kind of errors that do not have a kind or whose kind code is not
+	// known.
+	ErrorKindUnknown ErrorKind = iota
+	// ErrorKindMissingPayload is the kind of errors produced when a request is missing a
+	// required payload.
+	ErrorKindMissingPayload
+	// ErrorKindMissingParam is the kind of errors produced when a request is missing a
+	// required path or querystring parameter.
+	ErrorKindMissingParam
+	// ErrorKindInvalidParamType is the kind of errors produced when the value of a parameter
+	// cannot be coerced to the type defined in the design.
+	ErrorKindInvalidParamType
+	// ErrorKindMissingHeader is the kind of errors produced when a request is missing a
+	// required header.
+	ErrorKindMissingHeader
+	// ErrorKindMissingAttribute is the kind of errors produced when a request payload is
+	// missing a required field.
+	ErrorKindMissingAttribute
+	// ErrorKindInvalidAttributeType is the kind of errors produced when the type of a payload
+	// field does not match the type defined in the design.
+	ErrorKindInvalidAttributeType
+	// ErrorKindInvalidEnumValue is the kind of errors produced when a value is not one of the
+	// values listed in the Enum validation.
+	ErrorKindInvalidEnumValue
+	// ErrorKindInvalidFormat is the kind of errors produced when a value does not match the
+	// Format validation.
+	ErrorKindInvalidFormat
+	// ErrorKindInvalidPattern is the kind of errors produced when a value does not match the
+	// Pattern validation.
+	ErrorKindInvalidPattern
+	// ErrorKindInvalidRange is the kind of errors produced when a value does not satisfy the
+	// Minimum or Maximum validations.
+	ErrorKindInvalidRange
+	// ErrorKindInvalidLength is the kind of errors produced when the length of a value does
+	// not satisfy the MinLength or MaxLength validations.
+	ErrorKindInvalidLength
+	// ErrorKindInvalidTransition is the kind of errors produced when a value that describes a
+	// lifecycle is not one of the states that can be reached from the current state.
+	ErrorKindInvalidTransition
+	// ErrorKindInvalidOneOf is the kind of errors produced when a value of a OneOf type does
+	// not hold exactly one of the member types.
+	ErrorKindInvalidOneOf
+	// ErrorKindInvalidPhoneNumber is the kind of errors produced when a value is not a phone
+	// number that can be normalized to the E.164 format.
+	ErrorKindInvalidPhoneNumber
+	// ErrorKindUnsupportedPhoneRegion is the kind of errors produced when a phone number in
+	// national format uses a region whose country calling code is not known.
+	ErrorKindUnsupportedPhoneRegion
+	// ErrorKindMissingCookie is the kind of errors produced when a request is missing a
+	// required cookie.
+	ErrorKindMissingCookie
+)
+
+// errorKinds lists the codes and titles of the error kinds indexed by kind. RegisterErrorKind
+// appends to it.
+var errorKinds = []struct{ code, title string }{
+	ErrorKindUnknown:                {"unknown", "Unknown error"},
+	ErrorKindMissingPayload:         {"missing_payload", "Missing payload"},
+	ErrorKindMissingParam:           {"missing_param", "Missing parameter"},
+	ErrorKindInvalidParamType:       {"invalid_param_type", "Invalid parameter type"},
+	ErrorKindMissingHeader:          {"missing_header", "Missing header"},
+	ErrorKindMissingAttribute:       {"missing_attribute", "Missing attribute"},
+	ErrorKindInvalidAttributeType:   {"invalid_attribute_type", "Invalid attribute type"},
+	ErrorKindInvalidEnumValue:       {"invalid_enum_value", "Invalid enum value"},
+	ErrorKindInvalidFormat:          {"invalid_format", "Invalid format"},
+	ErrorKindInvalidPattern:         {"invalid_pattern", "Invalid pattern"},
+	ErrorKindInvalidRange:           {"invalid_range", "Invalid range"},
+	ErrorKindInvalidLength:          {"invalid_length", "Invalid length"},
+	ErrorKindInvalidTransition:      {"invalid_transition", "Invalid transition"},
+	ErrorKindInvalidOneOf:           {"invalid_one_of", "Invalid one of value"},
+	ErrorKindInvalidPhoneNumber:     {"invalid_phone_number", "Invalid phone number"},
+	ErrorKindUnsupportedPhoneRegion: {"unsupported_phone_region", "Unsupported phone region"},
+	ErrorKindMissingCookie:          {"missing_cookie", "Missing cookie"},
+}
+
+// errorKindsLock is the mutex used to access errorKinds.
+var errorKindsLock = &sync.RWMutex{}
+
+// RegisterErrorKind defines a new error kind with the given code and title and returns it. The
+// code should be snake_case and must not clash with the codes of the goa kinds, registering a
+// code that is already registered returns the existing kind and leaves its title unchanged.
+// RegisterErrorKind returns ErrorKindUnknown if code is empty. Services typically register their
+// kinds when initializing package variables:
+//
+//	var ErrorKindQuotaExceeded = goa.RegisterErrorKind("quota_exceeded", "Quota exceeded")
+func RegisterErrorKind(code, title string) ErrorKind {
+	if code == "" {
+		return ErrorKindUnknown
+	}
+	errorKindsLock.Lock()
+	defer errorKindsLock.Unlock()
+	for k, info := range errorKinds {
+		if info.code == code {
+			return ErrorKind(k)
+		}
+	}
+	errorKinds = append(errorKinds, struct{ code, title string }{code, title})
+	return ErrorKind(len(errorKinds) - 1)
+}
+
+// ParseErrorKind returns the kind with the given code, ErrorKindUnknown if there is none.
+func ParseErrorKind(code string) ErrorKind {
+	errorKindsLock.RLock()
+	defer errorKindsLock.RUnlock()
+	for k, info := range errorKinds {
+		if info.code == code {
+			return ErrorKind(k)
+		}
+	}
+	return ErrorKindUnknown
+}
+
+// Code returns the stable snake_case code of the kind, e.g. "missing_param", or "unknown" if the
+// kind is not registered.
+func (k ErrorKind) Code() string {
+	info, _ := k.info()
+	return info.code
+}
+
+// Title returns a short human readable summary of the kind, e.g. "Missing parameter", or
+// "Unknown error" if the kind is not registered.
+func (k ErrorKind) Title() string {
+	errorKindsLock.RLock()
+	defer errorKindsLock.RUnlock()
+	if k >= 0 && int(k) < len(errorKinds) && errorKinds[k].title != "" {
+		return errorKinds[k].title
+	}
+	return errorKinds[ErrorKindUnknown].title
+}
+
+// String returns the code of the kind or "ErrorKind(n)" if the kind is not registered.
+func (k ErrorKind) String() string {
+	info, ok := k.info()
+	if !ok {
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+	return info.code
+}
+
+// info returns the code and title of the kind and true if the kind is registered, the ones of
+// ErrorKindUnknown and false otherwise.
+func (k ErrorKind) info() (struct{ code, title string }, bool) {
+	errorKindsLock.RLock()
+	defer errorKindsLock.RUnlock()
+	if k < 0 || int(k) >= len(errorKinds) {
+		return errorKinds[ErrorKindUnknown], false
+	}
+	return errorKinds[k], true
+}
+
+// ErrorKind returns the kind of the error, ErrorKindUnknown if the error does not have a kind or if
+// the kind code is not known.
+func (e *ErrorResponse) ErrorKind() ErrorKind {
+	return ParseErrorKind(e.Kind)
+}
+
+// WithErrorKind sets the kind of err if it is an *ErrorResponse and returns it. err may be of a
+// different type if the error class that created it was overridden in which case it is returned
+// unchanged.
+func WithErrorKind(err error, kind ErrorKind) error {
+	if e, ok := err.(*ErrorResponse); ok {
+		e.Kind = kind.Code()
+	}
+	return err
+}
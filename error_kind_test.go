@@ -0,0 +1,166 @@
+package goa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorKind", func() {
+	It("has stable codes", func() {
+		Ω(ErrorKindUnknown.Code()).Should(Equal("unknown"))
+		Ω(ErrorKindMissingParam.Code()).Should(Equal("missing_param"))
+		Ω(ErrorKindInvalidEnumValue.Code()).Should(Equal("invalid_enum_value"))
+		Ω(ErrorKindInvalidTransition.Code()).Should(Equal("invalid_transition"))
+	})
+
+	It("round trips codes", func() {
+		for k := range errorKinds {
+			kind := ErrorKind(k)
+			Ω(ParseErrorKind(kind.Code())).Should(Equal(kind))
+			Ω(kind.String()).Should(Equal(kind.Code()))
+			Ω(kind.Title()).ShouldNot(BeEmpty())
+		}
+	})
+
+	It("parses unknown codes as ErrorKindUnknown", func() {
+		Ω(ParseErrorKind("added_later")).Should(Equal(ErrorKindUnknown))
+		Ω(ParseErrorKind("")).Should(Equal(ErrorKindUnknown))
+	})
+
+	It("has a code and a title for all the goa kinds", func() {
+		cases := []struct {
+			kind  ErrorKind
+			code  string
+			title string
+		}{
+			{ErrorKindUnknown, "unknown", "Unknown error"},
+			{ErrorKindMissingPayload, "missing_payload", "Missing payload"},
+			{ErrorKindMissingParam, "missing_param", "Missing parameter"},
+			{ErrorKindInvalidParamType, "invalid_param_type", "Invalid parameter type"},
+			{ErrorKindMissingHeader, "missing_header", "Missing header"},
+			{ErrorKindMissingAttribute, "missing_attribute", "Missing attribute"},
+			{ErrorKindInvalidAttributeType, "invalid_attribute_type", "Invalid attribute type"},
+			{ErrorKindInvalidEnumValue, "invalid_enum_value", "Invalid enum value"},
+			{ErrorKindInvalidFormat, "invalid_format", "Invalid format"},
+			{ErrorKindInvalidPattern, "invalid_pattern", "Invalid pattern"},
+			{ErrorKindInvalidRange, "invalid_range", "Invalid range"},
+			{ErrorKindInvalidLength, "invalid_length", "Invalid length"},
+			{ErrorKindInvalidTransition, "invalid_transition", "Invalid transition"},
+			{ErrorKindInvalidOneOf, "invalid_one_of", "Invalid one of value"},
+			{ErrorKindInvalidPhoneNumber, "invalid_phone_number", "Invalid phone number"},
+			{ErrorKindUnsupportedPhoneRegion, "unsupported_phone_region", "Unsupported phone region"},
+		}
+		for _, c := range cases {
+			Ω(c.kind.Code()).Should(Equal(c.code))
+			Ω(c.kind.Title()).Should(Equal(c.title))
+			Ω(c.kind.String()).Should(Equal(c.code))
+		}
+	})
+
+	It("does not panic on undefined kinds", func() {
+		for _, kind := range []ErrorKind{-1, 99, ErrorKind(1 << 30)} {
+			Ω(func() { kind.Title() }).ShouldNot(Panic())
+			Ω(kind.Code()).Should(Equal("unknown"))
+			Ω(kind.Title()).Should(Equal("Unknown error"))
+		}
+		Ω(ErrorKind(99).String()).Should(Equal("ErrorKind(99)"))
+		Ω(ErrorKind(-1).String()).Should(Equal("ErrorKind(-1)"))
+	})
+
+	Context("with custom kinds", func() {
+		It("registers them", func() {
+			kind := RegisterErrorKind("test_quota_exceeded", "Quota exceeded")
+			Ω(kind).Should(BeNumerically(">", ErrorKindInvalidTransition))
+			Ω(kind.Code()).Should(Equal("test_quota_exceeded"))
+			Ω(kind.Title()).Should(Equal("Quota exceeded"))
+			Ω(kind.String()).Should(Equal("test_quota_exceeded"))
+			Ω(ParseErrorKind("test_quota_exceeded")).Should(Equal(kind))
+		})
+
+		It("returns the existing kind when registering a known code", func() {
+			kind := RegisterErrorKind("test_conflict", "Conflict")
+			Ω(RegisterErrorKind("test_conflict", "Other")).Should(Equal(kind))
+			Ω(kind.Title()).Should(Equal("Conflict"))
+			Ω(RegisterErrorKind("missing_param", "Other")).Should(Equal(ErrorKindMissingParam))
+		})
+
+		It("falls back to the unknown title when the title is empty", func() {
+			kind := RegisterErrorKind("test_untitled", "")
+			Ω(kind.Title()).Should(Equal("Unknown error"))
+		})
+
+		It("reads the unknown title while kinds are registered concurrently", func() {
+			kind := RegisterErrorKind("test_untitled_concurrent", "")
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < 100; i++ {
+					RegisterErrorKind(fmt.Sprintf("test_concurrent_%d", i), "Concurrent")
+				}
+			}()
+			for i := 0; i < 100; i++ {
+				Ω(kind.Title()).Should(Equal("Unknown error"))
+			}
+			<-done
+		})
+
+		It("does not register empty codes", func() {
+			Ω(RegisterErrorKind("", "Empty")).Should(Equal(ErrorKindUnknown))
+		})
+
+		It("sets them on error responses", func() {
+			kind := RegisterErrorKind("test_gone", "Gone")
+			err := WithErrorKind(ErrBadRequest("gone"), kind)
+			Ω(err.(*ErrorResponse).Kind).Should(Equal("test_gone"))
+			Ω(err.(*ErrorResponse).ErrorKind()).Should(Equal(kind))
+		})
+
+		It("leaves other errors unchanged", func() {
+			err := errors.New("boom")
+			Ω(WithErrorKind(err, ErrorKindMissingParam)).Should(Equal(err))
+		})
+	})
+
+	It("is set by the request validation errors", func() {
+		cases := map[ErrorKind]error{
+			ErrorKindMissingPayload:         MissingPayloadError(),
+			ErrorKindMissingParam:           MissingParamError("id"),
+			ErrorKindInvalidParamType:       InvalidParamTypeError("id", "a", "integer"),
+			ErrorKindMissingHeader:          MissingHeaderError("X-Key"),
+			ErrorKindMissingAttribute:       MissingAttributeError("payload", "name"),
+			ErrorKindInvalidAttributeType:   InvalidAttributeTypeError("payload.id", "a", "integer"),
+			ErrorKindInvalidEnumValue:       InvalidEnumValueError("payload.color", "blue", []interface{}{"red"}),
+			ErrorKindInvalidFormat:          InvalidFormatError("payload.email", "x", FormatEmail, errors.New("invalid")),
+			ErrorKindInvalidPattern:         InvalidPatternError("payload.name", "x", "^a"),
+			ErrorKindInvalidRange:           InvalidRangeError("payload.age", 1, 2, true),
+			ErrorKindInvalidLength:          InvalidLengthError("payload.name", "x", 1, 2, true),
+			ErrorKindInvalidTransition:      InvalidTransitionError("payload.state", "a", "b", []string{"c"}),
+			ErrorKindInvalidOneOf:           InvalidOneOfError("payload.pet", 2, []string{"Cat", "Dog"}),
+			ErrorKindInvalidPhoneNumber:     InvalidPhoneNumberError("payload.phone", "12", "FR", errors.New("too short")),
+			ErrorKindUnsupportedPhoneRegion: UnsupportedPhoneRegionError("payload.phone", "0612345678", "ZZ"),
+			ErrorKindMissingCookie:          MissingCookieError("session"),
+		}
+		for kind, err := range cases {
+			Ω(err).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+			Ω(err.(*ErrorResponse).ErrorKind()).Should(Equal(kind))
+		}
+	})
+
+	It("is serialized in error responses", func() {
+		b, err := json.Marshal(MissingParamError("id"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(ContainSubstring(`"kind":"missing_param"`))
+	})
+
+	It("is cleared when merging errors of different kinds", func() {
+		err := MergeErrors(MissingParamError("id"), MissingParamError("name"))
+		Ω(err.(*ErrorResponse).ErrorKind()).Should(Equal(ErrorKindMissingParam))
+		err = MergeErrors(err, MissingHeaderError("X-Key"))
+		Ω(err.(*ErrorResponse).Kind).Should(BeEmpty())
+		Ω(err.(*ErrorResponse).ErrorKind()).Should(Equal(ErrorKindUnknown))
+	})
+})
@@ -0,0 +1,141 @@
+package goa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// documentRoots lists the names given by the generated validation code to the documents being
+// validated, e.g. "raw" for request payloads or "type" for user type instances. The paths of the
+// attributes of these documents are converted to JSON pointers.
+var documentRoots = map[string]bool{
+	"raw":      true,
+	"payload":  true,
+	"request":  true,
+	"response": true,
+	"type":     true,
+	"message":  true,
+}
+
+// ErrorPointer returns the RFC 6901 JSON pointer to the element of the validated document that
+// caused err, e.g. "/items/3/price". The pointer is recorded in the "pointer" metadata key of the
+// errors produced by the validation helpers such as InvalidRangeError and returned to clients so
+// that they can map errors back to their input documents. ErrorPointer returns false if err does
+// not relate to an element of a document, for example if it is caused by an invalid parameter.
+func ErrorPointer(err error) (string, bool) {
+	if e, ok := err.(*ErrorResponse); ok {
+		if p, ok := e.Meta["pointer"].(string); ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// WithErrorIndex records the index of the array element that caused err. context is the path
+// to the array, the generated code validates array elements using the path context + "[*]" and
+// calls WithErrorIndex on the errors produced for each element so that their attribute paths and
+// JSON pointers refer to the actual element, e.g. "raw.items[3].price" and "/items/3/price".
+func WithErrorIndex(err error, context string, index int) error {
+	return rewriteErrorPath(err, func(path string) string {
+		elem := context + "[*]"
+		if path == elem || strings.HasPrefix(path, elem+".") || strings.HasPrefix(path, elem+"[") {
+			return fmt.Sprintf("%s[%d]%s", context, index, path[len(elem):])
+		}
+		return path
+	})
+}
+
+// WithErrorContext records the path of the attribute whose Validate method produced err. The
+// errors produced by the Validate method of a user type use paths relative to the type instance,
+// e.g. "type.price", the generated code calls WithErrorContext to make them relative to the
+// enclosing document, e.g. "raw.items[*].price".
+func WithErrorContext(err error, context string) error {
+	return rewriteErrorPath(err, func(path string) string {
+		root := path
+		if i := strings.IndexAny(path, ".["); i >= 0 {
+			root = path[:i]
+		}
+		if !documentRoots[root] {
+			return path
+		}
+		return context + path[len(root):]
+	})
+}
+
+// rewriteErrorPath applies rewrite to the attribute paths recorded in err and updates its JSON
+// pointer.
+func rewriteErrorPath(err error, rewrite func(string) string) error {
+	switch e := err.(type) {
+	case MultiError:
+		for i, err := range e {
+			e[i] = rewriteErrorPath(err, rewrite)
+		}
+	case *ErrorResponse:
+		if parent, ok := e.Meta["parent"].(string); ok {
+			e.Meta["parent"] = rewrite(parent)
+		} else if a, ok := e.Meta["attribute"].(string); ok {
+			e.Meta["attribute"] = rewrite(a)
+		}
+		setErrorPointer(e)
+	}
+	return err
+}
+
+// setErrorPointer records the JSON pointer to the element that caused e in its metadata.
+func setErrorPointer(e *ErrorResponse) {
+	if _, ok := e.Meta["param"]; ok {
+		return
+	}
+	var path string
+	if a, ok := e.Meta["attribute"].(string); ok {
+		path = a
+		if parent, ok := e.Meta["parent"].(string); ok && parent != "" {
+			path = parent + "." + a
+		}
+	}
+	if p, ok := jsonPointer(path); ok {
+		e.Meta["pointer"] = p
+	}
+}
+
+// jsonPointer converts the attribute path used by the validation code into a JSON pointer, e.g.
+// "raw.items[3].price" into "/items/3/price". The pointer stops at the first unresolved element
+// "[*]" such as a hash value. jsonPointer returns false if the path is not rooted in a document.
+func jsonPointer(path string) (string, bool) {
+	root := path
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		root = path[:i]
+	}
+	if !documentRoots[root] {
+		return "", false
+	}
+	var (
+		ptr  string
+		rest = path[len(root):]
+	)
+	for rest != "" {
+		var token string
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				break
+			}
+			token, rest = rest[1:end], rest[end+1:]
+			if token == "*" {
+				break
+			}
+		} else {
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+		ptr += "/" + pointerEscaper.Replace(token)
+	}
+	return ptr, true
+}
+
+// pointerEscaper escapes JSON pointer reference tokens as described in RFC 6901.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
@@ -0,0 +1,86 @@
+package goa
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorPointer", func() {
+	It("records the pointer to invalid payload attributes", func() {
+		ptr, ok := ErrorPointer(InvalidRangeError("raw.age", 12, 18, true))
+		Ω(ok).Should(BeTrue())
+		Ω(ptr).Should(Equal("/age"))
+	})
+
+	It("records the pointer to missing attributes", func() {
+		ptr, ok := ErrorPointer(MissingAttributeError("raw.address", "city"))
+		Ω(ok).Should(BeTrue())
+		Ω(ptr).Should(Equal("/address/city"))
+	})
+
+	It("points to the document for errors on the document itself", func() {
+		ptr, ok := ErrorPointer(InvalidAttributeTypeError("raw", 1, "object"))
+		Ω(ok).Should(BeTrue())
+		Ω(ptr).Should(Equal(""))
+	})
+
+	It("escapes the reference tokens", func() {
+		ptr, _ := ErrorPointer(MissingAttributeError("raw", "a/b~c"))
+		Ω(ptr).Should(Equal("/a~1b~0c"))
+	})
+
+	It("stops at unresolved elements", func() {
+		ptr, _ := ErrorPointer(InvalidPatternError("raw.labels[*]", "x", "^a"))
+		Ω(ptr).Should(Equal("/labels"))
+	})
+
+	It("does not record pointers for parameters", func() {
+		_, ok := ErrorPointer(InvalidRangeError("limit", 0, 1, true))
+		Ω(ok).Should(BeFalse())
+		_, ok = ErrorPointer(InvalidParamTypeError("id", "a", "integer"))
+		Ω(ok).Should(BeFalse())
+	})
+})
+
+var _ = Describe("WithErrorIndex", func() {
+	It("records the index of the invalid element", func() {
+		err := WithErrorIndex(InvalidRangeError("raw.items[*].price", -1, 0, true), "raw.items", 3)
+		Ω(err.(*ErrorResponse).Meta["attribute"]).Should(Equal("raw.items[3].price"))
+		ptr, _ := ErrorPointer(err)
+		Ω(ptr).Should(Equal("/items/3/price"))
+	})
+
+	It("resolves nested arrays from the innermost one", func() {
+		err := InvalidLengthError("raw.rows[*][*]", "", 0, 1, true)
+		err = WithErrorIndex(err, "raw.rows[*]", 2)
+		err = WithErrorIndex(err, "raw.rows", 1)
+		ptr, _ := ErrorPointer(err)
+		Ω(ptr).Should(Equal("/rows/1/2"))
+	})
+
+	It("rewrites the errors of a MultiError", func() {
+		err := WithErrorIndex(NewMultiError(
+			MissingAttributeError("raw.items[*]", "name"),
+			InvalidRangeError("raw.items[*].price", -1, 0, true),
+		), "raw.items", 0)
+		for i, ptr := range []string{"/items/0/name", "/items/0/price"} {
+			p, _ := ErrorPointer(err.(MultiError)[i])
+			Ω(p).Should(Equal(ptr))
+		}
+	})
+
+	It("leaves other errors unchanged", func() {
+		err := WithErrorIndex(MissingParamError("id"), "raw.items", 0)
+		Ω(err.(*ErrorResponse).Meta).Should(Equal(map[string]interface{}{"name": "id"}))
+	})
+})
+
+var _ = Describe("WithErrorContext", func() {
+	It("makes the paths relative to the enclosing document", func() {
+		err := WithErrorContext(MissingAttributeError("type", "city"), "raw.addresses[*]")
+		err = WithErrorIndex(err, "raw.addresses", 1)
+		Ω(err.(*ErrorResponse).Meta["parent"]).Should(Equal("raw.addresses[1]"))
+		ptr, _ := ErrorPointer(err)
+		Ω(ptr).Should(Equal("/addresses/1/city"))
+	})
+})
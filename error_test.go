@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -106,6 +107,63 @@ var _ = Describe("MissingAttributeError", func() {
 	})
 })
 
+var _ = Describe("MissingNullableAttributeError", func() {
+	var valErr error
+	ctx := "ctx"
+	name := "param"
+
+	JustBeforeEach(func() {
+		valErr = MissingNullableAttributeError(ctx, name)
+	})
+
+	It("creates a http error that mentions null", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Code).Should(Equal(MissingAttributeError(ctx, name).(*ErrorResponse).Code))
+		Ω(err.Detail).Should(ContainSubstring(name))
+		Ω(err.Detail).Should(ContainSubstring("null"))
+		Ω(err.Meta).Should(HaveKeyWithValue("nullable", true))
+	})
+})
+
+var _ = Describe("MissingConditionalAttributeError", func() {
+	var valErr error
+	ctx := "ctx"
+	name := "shipping_address"
+
+	JustBeforeEach(func() {
+		valErr = MissingConditionalAttributeError(ctx, name, "delivery_method", "ship")
+	})
+
+	It("creates a http error that mentions the condition", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Code).Should(Equal(MissingAttributeError(ctx, name).(*ErrorResponse).Code))
+		Ω(err.Detail).Should(ContainSubstring(name))
+		Ω(err.Detail).Should(ContainSubstring(`when "delivery_method" is "ship"`))
+		Ω(err.Meta).Should(HaveKeyWithValue("when", "delivery_method"))
+	})
+})
+
+var _ = Describe("MissingCookieError", func() {
+	var valErr error
+	name := "session"
+
+	JustBeforeEach(func() {
+		valErr = MissingCookieError(name)
+	})
+
+	It("creates a http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(ContainSubstring(name))
+		Ω(err.ErrorKind()).Should(Equal(ErrorKindMissingCookie))
+	})
+})
+
 var _ = Describe("MissingHeaderError", func() {
 	var valErr error
 	name := "param"
@@ -122,6 +180,40 @@ var _ = Describe("MissingHeaderError", func() {
 	})
 })
 
+var _ = Describe("RateLimitError", func() {
+	var valErr error
+
+	JustBeforeEach(func() {
+		valErr = RateLimitError("too many requests", 1500*time.Millisecond)
+	})
+
+	It("creates a transient http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(429))
+		Ω(err.Transient).Should(BeTrue())
+		Ω(err.RetryAfter).Should(Equal(2))
+	})
+})
+
+var _ = Describe("UnavailableError", func() {
+	var valErr error
+
+	JustBeforeEach(func() {
+		valErr = UnavailableError("maintenance", 0)
+	})
+
+	It("creates a transient http error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(503))
+		Ω(err.Transient).Should(BeTrue())
+		Ω(err.RetryAfter).Should(Equal(0))
+	})
+})
+
 var _ = Describe("MethodNotAllowedError", func() {
 	var valErr error
 	method := "POST"
@@ -182,6 +274,18 @@ var _ = Describe("InvalidEnumValueError", func() {
 	})
 })
 
+var _ = Describe("InvalidTransitionError", func() {
+	It("creates a http error listing the allowed next states", func() {
+		valErr := InvalidTransitionError("status", "pending", "shipped", []string{"paid", "cancelled"})
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(409))
+		Ω(err.Code).Should(Equal("invalid_transition"))
+		Ω(err.Detail).Should(ContainSubstring(`status cannot transition from "pending" to "shipped"`))
+		Ω(err.Detail).Should(ContainSubstring(`"paid", "cancelled"`))
+	})
+})
+
 var _ = Describe("InvalidFormaerror", func() {
 	var valErr error
 	ctx := "ctx"
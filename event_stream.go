@@ -0,0 +1,161 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DeltaCreate is the operation of the deltas that describe the creation of a collection
+	// element.
+	DeltaCreate = "create"
+	// DeltaUpdate is the operation of the deltas that describe the update of a collection
+	// element.
+	DeltaUpdate = "update"
+	// DeltaDelete is the operation of the deltas that describe the deletion of a collection
+	// element.
+	DeltaDelete = "delete"
+)
+
+// Delta is the envelope of the events sent by subscription actions, see the apidsl package
+// Subscription function. It describes a change made to an element of a collection.
+type Delta struct {
+	// Op is the operation, one of DeltaCreate, DeltaUpdate or DeltaDelete.
+	Op string `json:"op"`
+	// Type is the identifier of the media type of the element.
+	Type string `json:"type"`
+	// Data is the element.
+	Data interface{} `json:"data"`
+}
+
+// ServerEvent is a server-sent event.
+type ServerEvent struct {
+	// ID is the event ID, clients send the ID of the last event they received in the
+	// Last-Event-ID header when reconnecting. The ID is omitted if empty.
+	ID string
+	// Event is the event type, the type is omitted if empty in which case clients dispatch
+	// the event as a "message" event.
+	Event string
+	// Data is the event data, it is encoded in JSON.
+	Data interface{}
+	// Retry is the reconnection delay clients should use if the connection is lost, it is
+	// omitted if zero.
+	Retry time.Duration
+}
+
+// EventStream writes a server-sent event stream response, see the apidsl package
+// StreamingResponse function. The methods of EventStream may be called concurrently.
+type EventStream struct {
+	ctx  context.Context
+	rw   http.ResponseWriter
+	lock sync.Mutex
+}
+
+// NewEventStream writes the headers of a server-sent event stream response with status code 200
+// using the response writer of ctx and returns the stream used to send the events. The stream ends
+// when the action handler returns.
+func NewEventStream(ctx context.Context) *EventStream {
+	rw := ContextResponse(ctx)
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("X-Accel-Buffering", "no")
+	rw.WriteHeader(http.StatusOK)
+	flushResponse(rw)
+	return &EventStream{ctx: ctx, rw: rw}
+}
+
+// LastEventID returns the value of the request Last-Event-ID header: the ID of the last event
+// received by clients that reconnect, the empty string for new clients.
+func (s *EventStream) LastEventID() string {
+	if req := ContextRequest(s.ctx); req != nil {
+		return req.Header.Get("Last-Event-ID")
+	}
+	return ""
+}
+
+// Send sends an event of the given type whose data is the JSON encoding of data. event may be
+// empty in which case clients dispatch the event as a "message" event.
+func (s *EventStream) Send(event string, data interface{}) error {
+	return s.SendEvent(&ServerEvent{Event: event, Data: data})
+}
+
+// SendEvent sends the given event.
+func (s *EventStream) SendEvent(e *ServerEvent) error {
+	var buf bytes.Buffer
+	if e.ID != "" {
+		if strings.ContainsAny(e.ID, "\r\n") {
+			return fmt.Errorf("invalid event ID %#v, must not contain line breaks", e.ID)
+		}
+		fmt.Fprintf(&buf, "id: %s\n", e.ID)
+	}
+	if e.Event != "" {
+		if strings.ContainsAny(e.Event, "\r\n") {
+			return fmt.Errorf("invalid event type %#v, must not contain line breaks", e.Event)
+		}
+		fmt.Fprintf(&buf, "event: %s\n", e.Event)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", e.Retry/time.Millisecond)
+	}
+	if e.Data != nil {
+		// The JSON encoding of a value does not contain line breaks.
+		b, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "data: %s\n", b)
+	}
+	buf.WriteString("\n")
+	return s.write(buf.Bytes())
+}
+
+// SendDelta sends a delta event whose type is the operation and whose data is a Delta envelope.
+// The event ID is the resume token, clients that reconnect send the token of the last delta they
+// received in the Last-Event-ID header. token may be empty in which case the ID is omitted.
+func (s *EventStream) SendDelta(op, mediaType, token string, data interface{}) error {
+	return s.SendEvent(&ServerEvent{
+		ID:    token,
+		Event: op,
+		Data:  &Delta{Op: op, Type: mediaType, Data: data},
+	})
+}
+
+// Comment sends a comment that clients ignore, it may be used to keep the connection alive.
+func (s *EventStream) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(&buf, ": %s\n", strings.TrimRight(line, "\r"))
+	}
+	buf.WriteString("\n")
+	return s.write(buf.Bytes())
+}
+
+// write writes b to the response and flushes it so that clients receive the event right away.
+func (s *EventStream) write(b []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.rw.Write(b); err != nil {
+		return err
+	}
+	flushResponse(s.rw)
+	return nil
+}
+
+// flushResponse flushes the response if the underlying response writer supports it.
+func flushResponse(w http.ResponseWriter) {
+	if rd, ok := w.(*ResponseData); ok {
+		w = rd.ResponseWriter
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
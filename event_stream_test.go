@@ -0,0 +1,85 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventStream", func() {
+	var (
+		rw     *httptest.ResponseRecorder
+		req    *http.Request
+		ctx    context.Context
+		stream *goa.EventStream
+	)
+
+	BeforeEach(func() {
+		var err error
+		rw = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", "/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		ctx = goa.NewContext(nil, rw, req, nil)
+		stream = goa.NewEventStream(ctx)
+	})
+
+	It("writes the event stream headers", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("sends events with JSON data", func() {
+		Ω(stream.Send("update", map[string]int{"count": 2})).Should(Succeed())
+		Ω(stream.Send("", "hello")).Should(Succeed())
+		Ω(rw.Body.String()).Should(Equal("event: update\ndata: {\"count\":2}\n\ndata: \"hello\"\n\n"))
+	})
+
+	It("sends events with an ID and a retry delay", func() {
+		err := stream.SendEvent(&goa.ServerEvent{ID: "42", Event: "tick", Retry: 3 * time.Second})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("id: 42\nevent: tick\nretry: 3000\n\n"))
+	})
+
+	It("rejects IDs and types containing line breaks", func() {
+		Ω(stream.SendEvent(&goa.ServerEvent{ID: "4\n2"})).ShouldNot(Succeed())
+		Ω(stream.Send("a\nb", nil)).ShouldNot(Succeed())
+		Ω(rw.Body.String()).Should(BeEmpty())
+	})
+
+	It("sends deltas with a resume token", func() {
+		Ω(stream.SendDelta(goa.DeltaUpdate, "application/vnd.bottle", "7", map[string]int{"id": 1})).Should(Succeed())
+		Ω(rw.Body.String()).Should(Equal("id: 7\nevent: update\n" +
+			"data: {\"op\":\"update\",\"type\":\"application/vnd.bottle\",\"data\":{\"id\":1}}\n\n"))
+	})
+
+	It("sends comments", func() {
+		Ω(stream.Comment("keep\nalive")).Should(Succeed())
+		Ω(rw.Body.String()).Should(Equal(": keep\n: alive\n\n"))
+	})
+
+	It("stops sending once the request is done", func() {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		Ω(goa.NewEventStream(cctx).Send("late", 1)).Should(MatchError(context.Canceled))
+	})
+
+	Context("with a reconnecting client", func() {
+		BeforeEach(func() {
+			req.Header.Set("Last-Event-ID", "41")
+		})
+
+		It("returns the ID of the last event received by the client", func() {
+			Ω(stream.LastEventID()).Should(Equal("41"))
+		})
+	})
+})
@@ -0,0 +1,79 @@
+package goa
+
+import (
+	"strings"
+
+	"context"
+)
+
+// ExpandParam is the name of the query string parameter that lists the relations to embed in
+// responses.
+const ExpandParam = "expand"
+
+// ParseExpand parses the values of the "expand" query string parameter. Each value may list
+// multiple relations separated with commas. ParseExpand returns an invalid request error if a
+// relation is not one of allowed.
+func ParseExpand(values []string, allowed ...string) ([]string, error) {
+	var expand []string
+	for _, v := range values {
+		for _, rel := range strings.Split(v, ",") {
+			rel = strings.TrimSpace(rel)
+			if rel == "" {
+				continue
+			}
+			if !IsExpanded(allowed, rel) {
+				vals := make([]interface{}, len(allowed))
+				for i, a := range allowed {
+					vals[i] = a
+				}
+				return nil, InvalidEnumValueError(ExpandParam, rel, vals)
+			}
+			if !IsExpanded(expand, rel) {
+				expand = append(expand, rel)
+			}
+		}
+	}
+	return expand, nil
+}
+
+// IsExpanded returns true if expand contains the given relation.
+func IsExpanded(expand []string, relation string) bool {
+	for _, e := range expand {
+		if e == relation {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryCost is the cost model of an action whose responses may embed relations. The cost of a
+// request is the base cost of the action plus the cost of each relation listed in the "expand"
+// parameter.
+type QueryCost struct {
+	// Base is the cost of the action response without embedded relations.
+	Base int
+	// Relations contains the cost of embedding each relation indexed by relation name.
+	Relations map[string]int
+}
+
+// Compute returns the cost of a request that embeds the given relations.
+func (c *QueryCost) Compute(expand []string) int {
+	cost := c.Base
+	for _, rel := range expand {
+		cost += c.Relations[rel]
+	}
+	return cost
+}
+
+// ContextQueryCost extracts the cost model of the action from the given context.
+func ContextQueryCost(ctx context.Context) *QueryCost {
+	if c := ctx.Value(queryCostKey); c != nil {
+		return c.(*QueryCost)
+	}
+	return nil
+}
+
+// WithQueryCost builds a context containing the given action cost model.
+func WithQueryCost(ctx context.Context, cost *QueryCost) context.Context {
+	return context.WithValue(ctx, queryCostKey, cost)
+}
@@ -0,0 +1,51 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseExpand", func() {
+	var values []string
+	var expand []string
+	var err error
+
+	JustBeforeEach(func() {
+		expand, err = goa.ParseExpand(values, "account", "owner")
+	})
+
+	Context("with no value", func() {
+		BeforeEach(func() {
+			values = nil
+		})
+
+		It("expands nothing", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(expand).Should(BeEmpty())
+		})
+	})
+
+	Context("with comma separated and repeated values", func() {
+		BeforeEach(func() {
+			values = []string{"account, owner", "account"}
+		})
+
+		It("returns the relations", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(expand).Should(Equal([]string{"account", "owner"}))
+			Ω(goa.IsExpanded(expand, "owner")).Should(BeTrue())
+		})
+	})
+
+	Context("with an unknown relation", func() {
+		BeforeEach(func() {
+			values = []string{"account,bottles"}
+		})
+
+		It("returns an invalid request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+		})
+	})
+})
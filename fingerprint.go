@@ -0,0 +1,66 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FingerprintExtension is the name of the Swagger extension of the info object where goagen
+// records the fingerprint of the design the specification was generated from.
+const FingerprintExtension = "x-goa-design-fingerprint"
+
+// DesignArtifact describes an artifact generated by goagen together with the fingerprint of the
+// design it was generated from. The generated packages expose their fingerprint via the
+// DesignFingerprint constant.
+type DesignArtifact struct {
+	// Name identifies the artifact in logs and errors, e.g. "swagger".
+	Name string
+	// Fingerprint is the fingerprint of the design the artifact was generated from.
+	Fingerprint string
+}
+
+// SpecFingerprint returns the design fingerprint recorded in the given Swagger specification, the
+// empty string if the specification is not valid JSON or does not record a fingerprint. It makes
+// it possible to verify the specification served by the service when it is not compiled in, for
+// example when it is read from disk.
+func SpecFingerprint(spec []byte) string {
+	var s struct {
+		Info map[string]interface{} `json:"info"`
+	}
+	if err := json.Unmarshal(spec, &s); err != nil {
+		return ""
+	}
+	fp, _ := s.Info[FingerprintExtension].(string)
+	return fp
+}
+
+// CheckDesign verifies that the given artifacts were generated from the design with the given
+// fingerprint, typically the DesignFingerprint constant of the generated app package. It is meant
+// to be called when the service starts to detect artifacts that drifted from the design, for
+// example because a stale generated package or specification was committed:
+//
+//	err := service.CheckDesign(app.DesignFingerprint, true,
+//		goa.DesignArtifact{Name: "swagger", Fingerprint: swagger.DesignFingerprint})
+//
+// Each mismatch is logged. CheckDesign returns an error listing the mismatches if strict is true
+// so that the service can fail fast, it always returns nil otherwise.
+func (service *Service) CheckDesign(fingerprint string, strict bool, artifacts ...DesignArtifact) error {
+	var drifted []string
+	for _, a := range artifacts {
+		if a.Fingerprint == fingerprint {
+			continue
+		}
+		service.LogError("design drift", "artifact", a.Name, "expected", fingerprint, "actual", a.Fingerprint)
+		actual := a.Fingerprint
+		if actual == "" {
+			actual = "none"
+		}
+		drifted = append(drifted, fmt.Sprintf("%s (%s)", a.Name, actual))
+	}
+	if len(drifted) == 0 || !strict {
+		return nil
+	}
+	return fmt.Errorf("artifacts generated from a different design than %s: %s",
+		fingerprint, strings.Join(drifted, ", "))
+}
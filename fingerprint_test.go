@@ -0,0 +1,73 @@
+package goa_test
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpecFingerprint", func() {
+	It("returns the fingerprint recorded in the spec", func() {
+		spec := []byte(`{"swagger":"2.0","info":{"title":"cellar","x-goa-design-fingerprint":"abc"}}`)
+		Ω(goa.SpecFingerprint(spec)).Should(Equal("abc"))
+	})
+
+	It("returns the empty string if the spec has no fingerprint", func() {
+		Ω(goa.SpecFingerprint([]byte(`{"swagger":"2.0","info":{"title":"cellar"}}`))).Should(BeEmpty())
+		Ω(goa.SpecFingerprint([]byte(`not json`))).Should(BeEmpty())
+	})
+})
+
+var _ = Describe("CheckDesign", func() {
+	var service *goa.Service
+	var logs bytes.Buffer
+	var artifacts []goa.DesignArtifact
+	var strict bool
+	var err error
+
+	BeforeEach(func() {
+		logs.Reset()
+		service = goa.New("test")
+		service.WithLogger(goa.NewLogger(log.New(&logs, "", 0)))
+		strict = true
+		artifacts = []goa.DesignArtifact{{Name: "swagger", Fingerprint: "abc"}}
+	})
+
+	JustBeforeEach(func() {
+		err = service.CheckDesign("abc", strict, artifacts...)
+	})
+
+	It("succeeds when the fingerprints match", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(logs.String()).Should(BeEmpty())
+	})
+
+	Context("with artifacts that drifted", func() {
+		BeforeEach(func() {
+			artifacts = append(artifacts,
+				goa.DesignArtifact{Name: "client", Fingerprint: "def"},
+				goa.DesignArtifact{Name: "swagger.json"})
+		})
+
+		It("returns an error listing the drifted artifacts", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(Equal("artifacts generated from a different design than abc: client (def), swagger.json (none)"))
+			Ω(logs.String()).Should(ContainSubstring("design drift"))
+			Ω(logs.String()).Should(ContainSubstring("artifact=client"))
+		})
+
+		Context("in non strict mode", func() {
+			BeforeEach(func() {
+				strict = false
+			})
+
+			It("only logs the drifted artifacts", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(logs.String()).Should(ContainSubstring("artifact=swagger.json"))
+			})
+		})
+	})
+})
@@ -0,0 +1,60 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FlagNames returns the names of the flags that are set in value. names lists the names of the
+// flags in bit order, that is the name of the flag corresponding to bit i is names[i].
+func FlagNames(value uint64, names []string) []string {
+	res := []string{}
+	for i, n := range names {
+		if value&(1<<uint(i)) != 0 {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// FormatFlags returns the names of the flags that are set in value separated with "|", e.g.
+// "read|write".
+func FormatFlags(value uint64, names []string) string {
+	return strings.Join(FlagNames(value, names), "|")
+}
+
+// ParseFlags returns the value where the flags with the given names are set. It returns an error
+// if one of the flags is not one of names.
+func ParseFlags(flags []string, names []string) (uint64, error) {
+	var value uint64
+	for _, f := range flags {
+		found := false
+		for i, n := range names {
+			if n == f {
+				value |= 1 << uint(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown flag %#v, must be one of %s", f, strings.Join(names, ", "))
+		}
+	}
+	return value, nil
+}
+
+// MarshalFlags encodes value as the JSON array of the names of the flags that are set.
+func MarshalFlags(value uint64, names []string) ([]byte, error) {
+	return json.Marshal(FlagNames(value, names))
+}
+
+// UnmarshalFlags decodes the JSON array of the names of the flags that are set. It returns an
+// error if one of the names is not one of names.
+func UnmarshalFlags(data []byte, names []string) (uint64, error) {
+	var flags []string
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return 0, err
+	}
+	return ParseFlags(flags, names)
+}
@@ -0,0 +1,38 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Flags", func() {
+	names := []string{"read", "write", "admin"}
+
+	It("lists the names of the flags that are set", func() {
+		Ω(goa.FlagNames(5, names)).Should(Equal([]string{"read", "admin"}))
+		Ω(goa.FlagNames(0, names)).Should(BeEmpty())
+		Ω(goa.FormatFlags(3, names)).Should(Equal("read|write"))
+	})
+
+	It("parses flag names", func() {
+		v, err := goa.ParseFlags([]string{"admin", "read"}, names)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(Equal(uint64(5)))
+	})
+
+	It("rejects unknown flag names", func() {
+		_, err := goa.ParseFlags([]string{"delete"}, names)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring(`unknown flag "delete"`))
+	})
+
+	It("encodes flags as arrays of names", func() {
+		b, err := goa.MarshalFlags(6, names)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`["write","admin"]`))
+		v, err := goa.UnmarshalFlags(b, names)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(Equal(uint64(6)))
+	})
+})
@@ -0,0 +1,276 @@
+package goa
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+type (
+	// formDecoder decodes "application/x-www-form-urlencoded" bodies.
+	formDecoder struct {
+		r io.Reader
+	}
+
+	// formEncoder encodes values into "application/x-www-form-urlencoded" bodies.
+	formEncoder struct {
+		w io.Writer
+	}
+)
+
+// NewFormDecoder returns a decoder of "application/x-www-form-urlencoded" bodies. The keys of the
+// decoded form are matched against the names given by the "form" tags of the target struct
+// fields, keys that are repeated decode into slices and keys of the form "parent.child" decode
+// into the fields of nested structs. Decoding into a map or an empty interface produces string
+// values for keys that appear once and slices of strings for repeated keys.
+func NewFormDecoder(r io.Reader) Decoder { return &formDecoder{r: r} }
+
+// NewFormEncoder returns an encoder that writes "application/x-www-form-urlencoded" bodies using
+// the same conventions as the decoder returned by NewFormDecoder.
+func NewFormEncoder(w io.Writer) Encoder { return &formEncoder{w: w} }
+
+// Decode decodes the form read from the underlying reader into v.
+func (d *formDecoder) Decode(v interface{}) error {
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("form must be decoded into a non nil pointer, got %T", v)
+	}
+	return decodeForm(values, "", rv.Elem())
+}
+
+// Reset sets the reader used by the decoder.
+func (d *formDecoder) Reset(r io.Reader) { d.r = r }
+
+// Encode writes the form encoding of v to the underlying writer.
+func (e *formEncoder) Encode(v interface{}) error {
+	values := make(url.Values)
+	if err := encodeForm(values, "", reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, values.Encode())
+	return err
+}
+
+// Reset sets the writer used by the encoder.
+func (e *formEncoder) Reset(w io.Writer) { e.w = w }
+
+// decodeForm decodes the values whose keys start with prefix into v.
+func decodeForm(values url.Values, prefix string, v reflect.Value) error {
+	switch {
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(formMap(values, prefix)))
+		return nil
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		m := formMap(values, prefix)
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for k, val := range m {
+			rval := reflect.ValueOf(val)
+			if !rval.Type().AssignableTo(v.Type().Elem()) {
+				return fmt.Errorf("invalid value %#v for %s", val, prefix+k)
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), rval)
+		}
+		return nil
+	case v.Kind() != reflect.Struct:
+		return fmt.Errorf("form cannot be decoded into %s", v.Type())
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := formFieldName(f)
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		fv := v.Field(i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if (ft.Kind() == reflect.Struct || ft.Kind() == reflect.Map) && !isTextUnmarshaler(ft) {
+			if !hasFormPrefix(values, key+".") {
+				continue
+			}
+			target := fv
+			if fv.Kind() == reflect.Ptr {
+				target = reflect.New(ft)
+				fv.Set(target)
+				target = target.Elem()
+			}
+			if err := decodeForm(values, key+".", target); err != nil {
+				return err
+			}
+			continue
+		}
+		vals := values[key]
+		if len(vals) == 0 {
+			continue
+		}
+		if fv.Kind() == reflect.Slice && !isTextUnmarshaler(fv.Type()) {
+			s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for j, val := range vals {
+				if err := setDeepObjectValue(s.Index(j), val); err != nil {
+					return fmt.Errorf("invalid value %#v for %s: %s", val, key, err)
+				}
+			}
+			fv.Set(s)
+			continue
+		}
+		if err := setDeepObjectValue(fv, vals[0]); err != nil {
+			return fmt.Errorf("invalid value %#v for %s: %s", vals[0], key, err)
+		}
+	}
+	return nil
+}
+
+// encodeForm adds the form encoding of v to values using prefix for the keys.
+func encodeForm(values url.Values, prefix string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("form cannot encode %s", v.Type())
+		}
+		for _, k := range v.MapKeys() {
+			if err := encodeFormValue(values, prefix+k.String(), v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := formFieldName(f)
+			if name == "" {
+				continue
+			}
+			fv := v.Field(i)
+			if strings.Contains(f.Tag.Get("form"), ",omitempty") && isEmptyValue(fv) {
+				continue
+			}
+			if err := encodeFormValue(values, prefix+name, fv); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("form cannot encode %s", v.Type())
+	}
+}
+
+// encodeFormValue adds the form encoding of the value v of the given key to values.
+func encodeFormValue(values url.Values, key string, v reflect.Value) error {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !isTextMarshaler(t) && !isTextMarshaler(reflect.PtrTo(t)) {
+		switch t.Kind() {
+		case reflect.Struct, reflect.Map:
+			return encodeForm(values, key+".", v)
+		case reflect.Slice, reflect.Array:
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return nil
+				}
+				v = v.Elem()
+			}
+			for i := 0; i < v.Len(); i++ {
+				if s, ok := deepObjectValue(v.Index(i)); ok {
+					values.Add(key, s)
+				}
+			}
+			return nil
+		}
+	}
+	if s, ok := deepObjectValue(v); ok {
+		values.Add(key, s)
+	}
+	return nil
+}
+
+// formFieldName returns the name of the form key mapped to the given struct field, the empty
+// string if the field should be skipped.
+func formFieldName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("form"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name
+}
+
+// formMap returns the values whose keys start with prefix indexed by the remainder of the keys.
+// Values of keys that appear once are strings, values of repeated keys are slices of strings.
+func formMap(values url.Values, prefix string) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, vals := range values {
+		if !strings.HasPrefix(k, prefix) || len(vals) == 0 {
+			continue
+		}
+		if len(vals) == 1 {
+			m[k[len(prefix):]] = vals[0]
+			continue
+		}
+		s := make([]interface{}, len(vals))
+		for i, val := range vals {
+			s[i] = val
+		}
+		m[k[len(prefix):]] = s
+	}
+	return m
+}
+
+// hasFormPrefix returns true if at least one key of values starts with prefix.
+func hasFormPrefix(values url.Values, prefix string) bool {
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue returns true if v is the zero value of its type or an empty collection.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package goa_test
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type formAddress struct {
+	City *string `form:"city,omitempty"`
+}
+
+type formPayload struct {
+	Email   *string      `form:"user_email,omitempty"`
+	Age     *int         `form:"age,omitempty"`
+	Admin   *bool        `form:"admin,omitempty"`
+	Tags    []string     `form:"tags,omitempty"`
+	Scores  []int        `form:"scores,omitempty"`
+	Address *formAddress `form:"address,omitempty"`
+}
+
+var _ = Describe("NewFormDecoder", func() {
+	var body string
+	var payload formPayload
+	var err error
+
+	JustBeforeEach(func() {
+		payload = formPayload{}
+		err = goa.NewFormDecoder(strings.NewReader(body)).Decode(&payload)
+	})
+
+	Context("with a valid form", func() {
+		BeforeEach(func() {
+			body = "user_email=me%40example.com&age=42&admin=true&tags=a&tags=b&scores=1&scores=2&address.city=Paris&unknown=x"
+		})
+
+		It("decodes the fields", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*payload.Email).Should(Equal("me@example.com"))
+			Ω(*payload.Age).Should(Equal(42))
+			Ω(*payload.Admin).Should(BeTrue())
+			Ω(payload.Tags).Should(Equal([]string{"a", "b"}))
+			Ω(payload.Scores).Should(Equal([]int{1, 2}))
+			Ω(payload.Address).ShouldNot(BeNil())
+			Ω(*payload.Address.City).Should(Equal("Paris"))
+		})
+	})
+
+	Context("with missing fields", func() {
+		BeforeEach(func() {
+			body = "age=1"
+		})
+
+		It("leaves them nil", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload.Email).Should(BeNil())
+			Ω(payload.Tags).Should(BeNil())
+			Ω(payload.Address).Should(BeNil())
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			body = "age=old"
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`invalid value "old" for age`))
+		})
+	})
+
+	It("decodes into empty interfaces", func() {
+		var v interface{}
+		err := goa.NewFormDecoder(strings.NewReader("a=1&b=2&b=3")).Decode(&v)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(Equal(map[string]interface{}{"a": "1", "b": []interface{}{"2", "3"}}))
+	})
+
+	It("is used by the HTTP decoder", func() {
+		decoder := goa.NewHTTPDecoder()
+		decoder.Register(goa.NewFormDecoder, "application/x-www-form-urlencoded")
+		var p formPayload
+		err := decoder.Decode(&p, strings.NewReader("tags=x"), "application/x-www-form-urlencoded")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(p.Tags).Should(Equal([]string{"x"}))
+	})
+})
+
+var _ = Describe("NewFormEncoder", func() {
+	It("encodes the non empty fields", func() {
+		email, city := "me@example.com", "Paris"
+		payload := &formPayload{Email: &email, Tags: []string{"a", "b"}, Address: &formAddress{City: &city}}
+		var buf bytes.Buffer
+		Ω(goa.NewFormEncoder(&buf).Encode(payload)).Should(Succeed())
+		values, err := url.ParseQuery(buf.String())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(values).Should(Equal(url.Values{
+			"user_email":   {"me@example.com"},
+			"tags":         {"a", "b"},
+			"address.city": {"Paris"},
+		}))
+	})
+
+	It("round trips through the decoder", func() {
+		age := 7
+		payload := &formPayload{Age: &age, Scores: []int{3, 4}}
+		var buf bytes.Buffer
+		Ω(goa.NewFormEncoder(&buf).Encode(payload)).Should(Succeed())
+		var decoded formPayload
+		Ω(goa.NewFormDecoder(&buf).Decode(&decoded)).Should(Succeed())
+		Ω(decoded).Should(Equal(*payload))
+	})
+})
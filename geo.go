@@ -0,0 +1,299 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// EarthRadius is the mean radius of the Earth in meters used to compute distances.
+const EarthRadius = 6371008.8
+
+// Point is a geographic point using WGS 84 coordinates. The generated code uses Point for the
+// attributes of type design.Point.
+type Point struct {
+	// Latitude in degrees, between -90 and 90.
+	Latitude float64 `form:"latitude,required" json:"latitude" xml:"latitude"`
+	// Longitude in degrees, between -180 and 180.
+	Longitude float64 `form:"longitude,required" json:"longitude" xml:"longitude"`
+}
+
+// BoundingBox is a geographic area delimited by two meridians and two parallels. West may be
+// greater than East for boxes that cross the antimeridian. The generated code uses BoundingBox for
+// the attributes of type design.BoundingBox.
+type BoundingBox struct {
+	// West is the longitude of the western edge in degrees.
+	West float64 `form:"west,required" json:"west" xml:"west"`
+	// South is the latitude of the southern edge in degrees.
+	South float64 `form:"south,required" json:"south" xml:"south"`
+	// East is the longitude of the eastern edge in degrees.
+	East float64 `form:"east,required" json:"east" xml:"east"`
+	// North is the latitude of the northern edge in degrees.
+	North float64 `form:"north,required" json:"north" xml:"north"`
+}
+
+// Proximity describes the area within a given distance of a point. The generated code uses
+// Proximity for the attributes of type design.Proximity, e.g. the parameters declared with the
+// Near DSL.
+type Proximity struct {
+	// Latitude of the center in degrees, between -90 and 90.
+	Latitude float64 `form:"latitude,required" json:"latitude" xml:"latitude"`
+	// Longitude of the center in degrees, between -180 and 180.
+	Longitude float64 `form:"longitude,required" json:"longitude" xml:"longitude"`
+	// Radius is the distance to the center in meters, zero means no limit.
+	Radius float64 `form:"radius,omitempty" json:"radius,omitempty" xml:"radius,omitempty"`
+}
+
+type (
+	// GeoJSONer is implemented by the values that have a GeoJSON representation. The encoder
+	// created with NewGeoJSONEncoder encodes these values using their GeoJSON representation.
+	GeoJSONer interface {
+		// GeoJSON returns the GeoJSON object, e.g. a Geometry or a Feature.
+		GeoJSON() interface{}
+	}
+
+	// Geometry is a GeoJSON geometry object, see RFC 7946 section 3.1.
+	Geometry struct {
+		// Type is the geometry type, e.g. "Point" or "Polygon".
+		Type string `json:"type"`
+		// Coordinates of the geometry, longitude first.
+		Coordinates interface{} `json:"coordinates"`
+	}
+
+	// Feature is a GeoJSON feature object, see RFC 7946 section 3.2.
+	Feature struct {
+		// Type is always "Feature".
+		Type string `json:"type"`
+		// ID is the optional feature identifier.
+		ID interface{} `json:"id,omitempty"`
+		// Geometry is the feature geometry, nil for unlocated features.
+		Geometry *Geometry `json:"geometry"`
+		// Properties contains the feature properties.
+		Properties interface{} `json:"properties"`
+	}
+
+	// FeatureCollection is a GeoJSON feature collection object, see RFC 7946 section 3.3.
+	FeatureCollection struct {
+		// Type is always "FeatureCollection".
+		Type string `json:"type"`
+		// Features lists the features.
+		Features []*Feature `json:"features"`
+	}
+)
+
+// Validate checks that the point coordinates are in range. The errors are reported using the
+// "type" context, see ValidateAt.
+func (p *Point) Validate() error {
+	return p.ValidateAt("type")
+}
+
+// ValidateAt checks that the point coordinates are in range and reports the errors using ctx as
+// the path of the point attribute, e.g. "raw.location".
+func (p *Point) ValidateAt(ctx string) (err error) {
+	err = validateRange(ctx+".latitude", p.Latitude, -90, 90)
+	return MergeErrors(err, validateRange(ctx+".longitude", p.Longitude, -180, 180))
+}
+
+// UnmarshalJSON decodes the point, both coordinates are required.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	type point Point
+	return unmarshalRequired(data, (*point)(p), "latitude", "longitude")
+}
+
+// Publicize returns p, it makes it possible to use Point in the generated private types.
+func (p *Point) Publicize() *Point {
+	return p
+}
+
+// Distance returns the great-circle distance in meters between p and other.
+func (p Point) Distance(other Point) float64 {
+	lat1, lat2 := p.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dlat := lat2 - lat1
+	dlng := (other.Longitude - p.Longitude) * math.Pi / 180
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dlng/2)*math.Sin(dlng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// GeoJSON returns the GeoJSON point geometry.
+func (p Point) GeoJSON() interface{} {
+	return p.Geometry()
+}
+
+// Geometry returns the GeoJSON point geometry.
+func (p Point) Geometry() *Geometry {
+	return &Geometry{Type: "Point", Coordinates: []float64{p.Longitude, p.Latitude}}
+}
+
+// String returns the coordinates formatted as "latitude,longitude".
+func (p Point) String() string {
+	return fmt.Sprintf("%g,%g", p.Latitude, p.Longitude)
+}
+
+// Validate checks that the box coordinates are in range and that the southern edge is not north
+// of the northern edge. The errors are reported using the "type" context, see ValidateAt.
+func (b *BoundingBox) Validate() error {
+	return b.ValidateAt("type")
+}
+
+// ValidateAt validates the box like Validate and reports the errors using ctx as the path of the
+// box attribute, e.g. "within".
+func (b *BoundingBox) ValidateAt(ctx string) (err error) {
+	err = validateRange(ctx+".west", b.West, -180, 180)
+	err = MergeErrors(err, validateRange(ctx+".south", b.South, -90, 90))
+	err = MergeErrors(err, validateRange(ctx+".east", b.East, -180, 180))
+	err = MergeErrors(err, validateRange(ctx+".north", b.North, -90, 90))
+	if err == nil && b.South > b.North {
+		err = InvalidRangeError(ctx+".south", b.South, b.North, false)
+	}
+	return
+}
+
+// UnmarshalJSON decodes the box, all four edges are required.
+func (b *BoundingBox) UnmarshalJSON(data []byte) error {
+	type box BoundingBox
+	return unmarshalRequired(data, (*box)(b), "west", "south", "east", "north")
+}
+
+// Publicize returns b, it makes it possible to use BoundingBox in the generated private types.
+func (b *BoundingBox) Publicize() *BoundingBox {
+	return b
+}
+
+// Contains returns true if p lies in the box.
+func (b BoundingBox) Contains(p Point) bool {
+	if p.Latitude < b.South || p.Latitude > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return p.Longitude >= b.West && p.Longitude <= b.East
+	}
+	// The box crosses the antimeridian
+	return p.Longitude >= b.West || p.Longitude <= b.East
+}
+
+// GeoJSON returns the GeoJSON polygon geometry of the box.
+func (b BoundingBox) GeoJSON() interface{} {
+	return b.Geometry()
+}
+
+// Geometry returns the GeoJSON polygon geometry of the box.
+func (b BoundingBox) Geometry() *Geometry {
+	ring := [][]float64{
+		{b.West, b.South}, {b.East, b.South}, {b.East, b.North}, {b.West, b.North}, {b.West, b.South},
+	}
+	return &Geometry{Type: "Polygon", Coordinates: [][][]float64{ring}}
+}
+
+// Validate checks that the center coordinates are in range and that the radius is not negative.
+// The errors are reported using the "type" context, see ValidateAt.
+func (p *Proximity) Validate() error {
+	return p.ValidateAt("type")
+}
+
+// ValidateAt validates the proximity like Validate and reports the errors using ctx as the path of
+// the proximity attribute, e.g. "near".
+func (p *Proximity) ValidateAt(ctx string) (err error) {
+	err = validateRange(ctx+".latitude", p.Latitude, -90, 90)
+	err = MergeErrors(err, validateRange(ctx+".longitude", p.Longitude, -180, 180))
+	if p.Radius < 0 {
+		err = MergeErrors(err, InvalidRangeError(ctx+".radius", p.Radius, 0, true))
+	}
+	return
+}
+
+// UnmarshalJSON decodes the proximity, the center coordinates are required.
+func (p *Proximity) UnmarshalJSON(data []byte) error {
+	type proximity Proximity
+	return unmarshalRequired(data, (*proximity)(p), "latitude", "longitude")
+}
+
+// Publicize returns p, it makes it possible to use Proximity in the generated private types.
+func (p *Proximity) Publicize() *Proximity {
+	return p
+}
+
+// Center returns the center point.
+func (p Proximity) Center() Point {
+	return Point{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+// Includes returns true if the distance between point and the center is less than or equal to
+// the radius. It always returns true if the radius is zero.
+func (p Proximity) Includes(point Point) bool {
+	return p.Radius == 0 || p.Center().Distance(point) <= p.Radius
+}
+
+// NewFeature creates a GeoJSON feature with the given identifier, geometry and properties. id may
+// be nil, geometry may be nil for unlocated features.
+func NewFeature(id interface{}, geometry *Geometry, properties interface{}) *Feature {
+	return &Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: properties}
+}
+
+// NewFeatureCollection creates a GeoJSON feature collection.
+func NewFeatureCollection(features ...*Feature) *FeatureCollection {
+	if features == nil {
+		features = []*Feature{}
+	}
+	return &FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// GeoJSON returns f.
+func (f *Feature) GeoJSON() interface{} {
+	return f
+}
+
+// GeoJSON returns c.
+func (c *FeatureCollection) GeoJSON() interface{} {
+	return c
+}
+
+// geoJSONEncoder encodes values using their GeoJSON representation when they have one.
+type geoJSONEncoder struct {
+	enc *json.Encoder
+}
+
+// NewGeoJSONEncoder creates an encoder for the "application/geo+json" content type. Values that
+// implement GeoJSONer are encoded using their GeoJSON representation, other values are encoded as
+// JSON.
+func NewGeoJSONEncoder(w io.Writer) Encoder {
+	return &geoJSONEncoder{enc: json.NewEncoder(w)}
+}
+
+// Encode encodes v.
+func (e *geoJSONEncoder) Encode(v interface{}) error {
+	if g, ok := v.(GeoJSONer); ok {
+		v = g.GeoJSON()
+	}
+	return e.enc.Encode(v)
+}
+
+// validateRange checks that v is between min and max.
+func validateRange(ctx string, v, min, max float64) error {
+	if v < min {
+		return InvalidRangeError(ctx, v, min, true)
+	}
+	if v > max {
+		return InvalidRangeError(ctx, v, max, false)
+	}
+	return nil
+}
+
+// unmarshalRequired decodes the JSON object data into v after checking that it defines the given
+// keys with non null values. A zero coordinate is valid so that missing coordinates cannot be
+// detected once decoded.
+func unmarshalRequired(data []byte, v interface{}, keys ...string) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if raw, ok := fields[k]; !ok || string(raw) == "null" {
+			return fmt.Errorf("missing required attribute %#v", k)
+		}
+	}
+	return json.Unmarshal(data, v)
+}
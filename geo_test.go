@@ -0,0 +1,108 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Geo", func() {
+	paris := goa.Point{Latitude: 48.8566, Longitude: 2.3522}
+	london := goa.Point{Latitude: 51.5074, Longitude: -0.1278}
+
+	It("validates coordinate ranges", func() {
+		Ω(paris.Validate()).ShouldNot(HaveOccurred())
+		Ω((&goa.Point{Latitude: 91}).Validate()).Should(HaveOccurred())
+		Ω((&goa.Point{Longitude: -181}).Validate()).Should(HaveOccurred())
+		Ω((&goa.BoundingBox{West: -10, South: 40, East: 10, North: 50}).Validate()).ShouldNot(HaveOccurred())
+		err := (&goa.BoundingBox{South: 50, North: 40}).Validate()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("type.south"))
+		Ω((&goa.Proximity{Radius: -1}).Validate()).Should(HaveOccurred())
+	})
+
+	It("reports validation errors in the given context", func() {
+		err := (&goa.Point{Latitude: 91}).ValidateAt("raw.location")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("raw.location.latitude"))
+		err = (&goa.BoundingBox{South: 50, North: 40}).ValidateAt("within")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("within.south"))
+		err = (&goa.Proximity{Radius: -1}).ValidateAt("near")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("near.radius"))
+	})
+
+	It("rejects JSON values missing coordinates", func() {
+		var p goa.Point
+		Ω(json.Unmarshal([]byte(`{"latitude":48.8566,"longitude":2.3522}`), &p)).Should(Succeed())
+		Ω(p).Should(Equal(paris))
+		err := json.Unmarshal([]byte(`{"latitude":48.8566}`), &p)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring(`missing required attribute "longitude"`))
+		var b goa.BoundingBox
+		Ω(json.Unmarshal([]byte(`{"west":0,"south":0,"east":0,"north":null}`), &b)).Should(HaveOccurred())
+		var near goa.Proximity
+		Ω(json.Unmarshal([]byte(`{"longitude":2.3522,"radius":10}`), &near)).Should(HaveOccurred())
+		Ω(json.Unmarshal([]byte(`{"latitude":0,"longitude":0}`), &near)).Should(Succeed())
+	})
+
+	It("computes distances", func() {
+		Ω(paris.Distance(london)).Should(BeNumerically("~", 343500, 1000))
+		Ω(paris.Distance(paris)).Should(BeZero())
+	})
+
+	It("checks whether boxes contain points", func() {
+		box := goa.BoundingBox{West: -5, South: 45, East: 5, North: 55}
+		Ω(box.Contains(paris)).Should(BeTrue())
+		Ω(box.Contains(goa.Point{Latitude: 40, Longitude: 0})).Should(BeFalse())
+		pacific := goa.BoundingBox{West: 170, South: -20, East: -170, North: 0}
+		Ω(pacific.Contains(goa.Point{Latitude: -10, Longitude: 179})).Should(BeTrue())
+		Ω(pacific.Contains(goa.Point{Latitude: -10, Longitude: -175})).Should(BeTrue())
+		Ω(pacific.Contains(goa.Point{Latitude: -10, Longitude: 0})).Should(BeFalse())
+	})
+
+	It("checks whether points are near a center", func() {
+		near := goa.Proximity{Latitude: paris.Latitude, Longitude: paris.Longitude, Radius: 10000}
+		Ω(near.Includes(goa.Point{Latitude: 48.9, Longitude: 2.3})).Should(BeTrue())
+		Ω(near.Includes(london)).Should(BeFalse())
+		near.Radius = 0
+		Ω(near.Includes(london)).Should(BeTrue())
+	})
+
+	Context("encoding GeoJSON", func() {
+		var buf bytes.Buffer
+
+		BeforeEach(func() {
+			buf.Reset()
+		})
+
+		It("encodes points and boxes as geometries", func() {
+			Ω(goa.NewGeoJSONEncoder(&buf).Encode(paris)).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(MatchJSON(`{"type":"Point","coordinates":[2.3522,48.8566]}`))
+			buf.Reset()
+			box := goa.BoundingBox{West: 0, South: 1, East: 2, North: 3}
+			Ω(goa.NewGeoJSONEncoder(&buf).Encode(box)).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(MatchJSON(`{"type":"Polygon","coordinates":[[[0,1],[2,1],[2,3],[0,3],[0,1]]]}`))
+		})
+
+		It("encodes feature collections", func() {
+			props := map[string]string{"name": "Paris"}
+			c := goa.NewFeatureCollection(goa.NewFeature(1, paris.Geometry(), props), goa.NewFeature(nil, nil, nil))
+			Ω(goa.NewGeoJSONEncoder(&buf).Encode(c)).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(MatchJSON(`{"type":"FeatureCollection","features":[
+				{"type":"Feature","id":1,"geometry":{"type":"Point","coordinates":[2.3522,48.8566]},"properties":{"name":"Paris"}},
+				{"type":"Feature","geometry":null,"properties":null}]}`))
+		})
+
+		It("encodes other values as JSON", func() {
+			Ω(goa.NewGeoJSONEncoder(&buf).Encode(map[string]int{"a": 1})).ShouldNot(HaveOccurred())
+			var v map[string]int
+			Ω(json.Unmarshal(buf.Bytes(), &v)).ShouldNot(HaveOccurred())
+			Ω(v).Should(Equal(map[string]int{"a": 1}))
+		})
+	})
+})
@@ -12,13 +12,16 @@ import (
 type Finalizer struct {
 	assignmentT      *template.Template
 	arrayAssignmentT *template.Template
-	seen             map[*design.AttributeDefinition]map[*design.AttributeDefinition]*bytes.Buffer
+	hashAssignmentT  *template.Template
+	phoneT           *template.Template
+	userFinalizeT    *template.Template
+	seen             map[string]bool
 }
 
 // NewFinalizer instantiates a finalize code generator.
 func NewFinalizer() *Finalizer {
 	var (
-		f   = &Finalizer{seen: make(map[*design.AttributeDefinition]map[*design.AttributeDefinition]*bytes.Buffer)}
+		f   = &Finalizer{seen: make(map[string]bool)}
 		err error
 	)
 	fm := template.FuncMap{
@@ -27,6 +30,7 @@ func NewFinalizer() *Finalizer {
 		"gotyperef":    GoTypeRef,
 		"add":          Add,
 		"finalizeCode": f.Code,
+		"finalizeElem": f.elemCode,
 	}
 	f.assignmentT, err = template.New("assignment").Funcs(fm).Parse(assignmentTmpl)
 	if err != nil {
@@ -36,29 +40,43 @@ func NewFinalizer() *Finalizer {
 	if err != nil {
 		panic(err)
 	}
+	f.hashAssignmentT, err = template.New("hashAssignment").Funcs(fm).Parse(hashAssignmentTmpl)
+	if err != nil {
+		panic(err)
+	}
+	f.phoneT, err = template.New("phone").Funcs(fm).Parse(phoneTmpl)
+	if err != nil {
+		panic(err)
+	}
+	f.userFinalizeT, err = template.New("userFinalize").Funcs(fm).Parse(userFinalizeTmpl)
+	if err != nil {
+		panic(err)
+	}
 	return f
 }
 
 // Code produces Go code that sets the default values for fields recursively for the given
-// attribute.
+// attribute. The code also normalizes the values of the phone number fields to the E.164 format. Fields whose type is a user type are finalized by calling the Finalize method of
+// the (private) user type so that recursive types produce finite code.
 func (f *Finalizer) Code(att *design.AttributeDefinition, target string, depth int) string {
-	buf := f.recurse(att, att, target, depth)
+	buf := f.recurse(att, target, depth)
 	return buf.String()
 }
 
-func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string, depth int) *bytes.Buffer {
+func (f *Finalizer) recurse(att *design.AttributeDefinition, target string, depth int) *bytes.Buffer {
 	var (
 		buf   = new(bytes.Buffer)
 		first = true
 	)
 
-	if s, ok := f.seen[root]; ok {
-		if buf, ok := s[att]; ok {
+	// Break infinite recursions, media types are inlined as they have no
+	// private counterpart with a Finalize method.
+	if mt, ok := att.Type.(*design.MediaTypeDefinition); ok {
+		if f.seen[mt.TypeName] {
 			return buf
 		}
-		s[att] = buf
-	} else {
-		f.seen[root] = map[*design.AttributeDefinition]*bytes.Buffer{att: buf}
+		f.seen[mt.TypeName] = true
+		defer delete(f.seen, mt.TypeName)
 	}
 
 	if o := att.Type.ToObject(); o != nil {
@@ -69,7 +87,7 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 					"field":      n,
 					"catt":       catt,
 					"depth":      depth,
-					"isDatetime": catt.Type == design.DateTime,
+					"isDatetime": catt.Type == design.DateTime || catt.Type == design.Date,
 					"defaultVal": PrintVal(catt.Type, catt.DefaultValue),
 				}
 				if !first {
@@ -79,9 +97,27 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 				}
 				buf.WriteString(RunTemplate(f.assignmentT, data))
 			}
-			a := f.recurse(root, catt, fmt.Sprintf("%s.%s", target, Goify(n, true)), depth+1).String()
+			if catt.IsPhoneNumber() {
+				data := map[string]interface{}{
+					"target":   target,
+					"field":    n,
+					"depth":    depth,
+					"region":   catt.PhoneRegion(),
+					"nullable": catt.Nullable,
+				}
+				if !first {
+					buf.WriteByte('\n')
+				} else {
+					first = false
+				}
+				buf.WriteString(RunTemplate(f.phoneT, data))
+			}
+			a, ok := f.userTypeCode(catt, fmt.Sprintf("%s.%s", target, Goify(n, true)), depth)
+			if !ok {
+				a = f.recurse(catt, fmt.Sprintf("%s.%s", target, Goify(n, true)), depth+1).String()
+			}
 			if a != "" {
-				if catt.Type.IsObject() {
+				if catt.Type.IsObject() && !ok {
 					a = fmt.Sprintf("%sif %s.%s != nil {\n%s\n%s}",
 						Tabs(depth), target, Goify(n, true), a, Tabs(depth))
 				}
@@ -103,13 +139,88 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 		if as := RunTemplate(f.arrayAssignmentT, data); as != "" {
 			buf.WriteString(as)
 		}
+	} else if h := att.Type.ToHash(); h != nil {
+		data := map[string]interface{}{
+			"elemType": h.ElemType,
+			"target":   target,
+			"depth":    1,
+		}
+		if as := RunTemplate(f.hashAssignmentT, data); as != "" {
+			buf.WriteString(as)
+		}
 	}
 	return buf
 }
 
+// elemCode produces the code that finalizes the elements of an array or hash.
+func (f *Finalizer) elemCode(att *design.AttributeDefinition, target string, depth int) string {
+	if code, ok := f.userTypeCode(att, target, depth); ok {
+		return code
+	}
+	return f.recurse(att, target, depth).String()
+}
+
+// userTypeCode produces the code that finalizes a value whose type is a user type by calling the
+// Finalize method of the type. This makes it possible to finalize recursive types. It returns
+// false if the attribute type is not an object user type.
+func (f *Finalizer) userTypeCode(att *design.AttributeDefinition, target string, depth int) (string, bool) {
+	ut, ok := att.Type.(*design.UserTypeDefinition)
+	if !ok || !ut.IsObject() {
+		return "", false
+	}
+	if !hasDefaultValues(ut) {
+		return "", true
+	}
+	return RunTemplate(f.userFinalizeT, map[string]interface{}{"target": target, "depth": depth}), true
+}
+
+// hasDefaultValues returns true if any attribute of the given user type or of the types it
+// references recursively defines a default value or is a phone number, i.e. if the user type has
+// a Finalize method. The Finalize method of OneOf types finalizes the member values.
+func hasDefaultValues(ut *design.UserTypeDefinition) bool {
+	return utHasDefaultValues(ut, make(map[string]bool))
+}
+
+func utHasDefaultValues(ut *design.UserTypeDefinition, seen map[string]bool) bool {
+	if seen[ut.TypeName] {
+		return false
+	}
+	seen[ut.TypeName] = true
+	for _, m := range ut.OneOf {
+		if utHasDefaultValues(m, seen) {
+			return true
+		}
+	}
+	return attHasDefaultValues(ut.AttributeDefinition, seen)
+}
+
+func attHasDefaultValues(att *design.AttributeDefinition, seen map[string]bool) bool {
+	switch actual := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		return utHasDefaultValues(actual, seen)
+	case *design.MediaTypeDefinition:
+		return utHasDefaultValues(actual.UserTypeDefinition, seen)
+	}
+	if o := att.Type.ToObject(); o != nil {
+		for _, catt := range o {
+			if catt.DefaultValue != nil || catt.IsPhoneNumber() || attHasDefaultValues(catt, seen) {
+				return true
+			}
+		}
+	} else if a := att.Type.ToArray(); a != nil {
+		return attHasDefaultValues(a.ElemType, seen)
+	} else if h := att.Type.ToHash(); h != nil {
+		return attHasDefaultValues(h.ElemType, seen)
+	}
+	return false
+}
+
 // PrintVal prints the given value corresponding to the given data type.
 // The value is already checked for the compatibility with the data type.
 func PrintVal(t design.DataType, val interface{}) string {
+	if design.IsEnum(t) || design.IsFlags(t) {
+		return fmt.Sprintf("%s(%s)", GoTypeName(t, nil, 0, false), PrintVal(t.(*design.UserTypeDefinition).Type, val))
+	}
 	switch {
 	case t.IsPrimitive():
 		// For primitive types, simply print the value
@@ -123,6 +234,8 @@ func PrintVal(t design.DataType, val interface{}) string {
 			s = fmt.Sprintf("%f", v)
 		case design.DateTime:
 			s = fmt.Sprintf("time.Parse(time.RFC3339, %s)", s)
+		case design.Date:
+			s = fmt.Sprintf("goa.ParseDate(%s)", s)
 		}
 		return s
 	case t.IsHash():
@@ -170,8 +283,24 @@ const (
 {{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = {{ .defaultVal }}
 }{{ end }}`
 
-	arrayAssignmentTmpl = `{{ $a := finalizeCode .elemType "e" (add .depth 1) }}{{/*
+	arrayAssignmentTmpl = `{{ $a := finalizeElem .elemType "e" (add .depth 1) }}{{/*
 */}}{{ if $a }}{{ tabs .depth }}for _, e := range {{ .target }} {
 {{ $a }}
 {{ tabs .depth }}}{{ end }}`
+
+	hashAssignmentTmpl = `{{ $a := finalizeElem .elemType "e" (add .depth 1) }}{{/*
+*/}}{{ if $a }}{{ tabs .depth }}for _, e := range {{ .target }} {
+{{ $a }}
+{{ tabs .depth }}}{{ end }}`
+
+	phoneTmpl = `{{ $field := printf "%s.%s" .target (goify .field true) }}{{/*
+*/}}{{ tabs .depth }}if {{ if .nullable }}{{ $field }}.Valid{{ else }}{{ $field }} != nil{{ end }} {
+{{ tabs .depth }}	if n, err := goa.NormalizePhoneNumber("", {{ if .nullable }}{{ $field }}.String{{ else }}*{{ $field }}{{ end }}, {{ printf "%q" .region }}); err == nil {
+{{ tabs .depth }}		{{ if .nullable }}{{ $field }}.String{{ else }}*{{ $field }}{{ end }} = n
+{{ tabs .depth }}	}
+{{ tabs .depth }}}`
+
+	userFinalizeTmpl = `{{ tabs .depth }}if {{ .target }} != nil {
+{{ tabs .depth }}	{{ .target }}.Finalize()
+{{ tabs .depth }}}`
 )
@@ -2,6 +2,7 @@ package codegen_test
 
 import (
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -137,6 +138,24 @@ var _ = Describe("Struct finalize code generation", func() {
 		})
 	})
 
+	Context("given a date field", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"foo": &design.AttributeDefinition{
+						Type:         design.Date,
+						DefaultValue: interface{}("1978-06-30"),
+					},
+				},
+			}
+			target = "ut"
+		})
+		It("finalizes the date fields", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(dateAssignmentCode))
+		})
+	})
+
 	Context("given a recursive user type", func() {
 		BeforeEach(func() {
 			var (
@@ -183,9 +202,79 @@ var _ = Describe("Struct finalize code generation", func() {
 			Ω(code).Should(Equal(recursiveAssignmentCodeB))
 		})
 	})
+
+	Context("given mutually recursive user types with a hash attribute", func() {
+		BeforeEach(func() {
+			var (
+				rt    = &design.UserTypeDefinition{TypeName: "recursive"}
+				other = &design.UserTypeDefinition{TypeName: "other"}
+				h     = &design.Hash{
+					KeyType:  &design.AttributeDefinition{Type: design.String},
+					ElemType: &design.AttributeDefinition{Type: other},
+				}
+			)
+			rt.AttributeDefinition = &design.AttributeDefinition{Type: &design.Object{
+				"children": &design.AttributeDefinition{Type: h},
+			}}
+			other.AttributeDefinition = &design.AttributeDefinition{Type: &design.Object{
+				"parent": &design.AttributeDefinition{Type: rt},
+				"name": &design.AttributeDefinition{
+					Type:         design.String,
+					DefaultValue: "foo",
+				},
+			}}
+
+			att = &design.AttributeDefinition{Type: rt}
+			target = "ut"
+		})
+		It("finalizes the hash elements", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(recursiveAssignmentCodeC))
+		})
+	})
+
+	Context("given a phone number field with a region", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: &design.Object{
+					"phone": &design.AttributeDefinition{
+						Type:       design.String,
+						Validation: &dslengine.ValidationDefinition{Format: "phone"},
+						Metadata:   dslengine.MetadataDefinition{design.PhoneRegionMetadata: {"FR"}},
+					},
+				},
+			}
+			target = "ut"
+		})
+		It("normalizes the field", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(Equal(phoneNormalizationCode))
+		})
+	})
+
+	Context("given a user type without default values", func() {
+		BeforeEach(func() {
+			rt := &design.UserTypeDefinition{TypeName: "recursive"}
+			rt.AttributeDefinition = &design.AttributeDefinition{Type: &design.Object{
+				"child": &design.AttributeDefinition{Type: rt},
+				"name":  &design.AttributeDefinition{Type: design.String},
+			}}
+			att = &design.AttributeDefinition{Type: rt}
+			target = "ut"
+		})
+		It("does not generate code", func() {
+			Ω(finalizer.Code(att, target, 0)).Should(BeEmpty())
+		})
+	})
 })
 
 const (
+	phoneNormalizationCode = `if ut.Phone != nil {
+	if n, err := goa.NormalizePhoneNumber("", *ut.Phone, "FR"); err == nil {
+		*ut.Phone = n
+	}
+}`
+
 	primitiveAssignmentCode = `var defaultFoo = "bar"
 if ut.Foo == nil {
 	ut.Foo = &defaultFoo
@@ -213,11 +302,13 @@ if ut.Foo == nil {
 	ut.Foo = &defaultFoo
 }`
 
+	dateAssignmentCode = `var defaultFoo, _ = goa.ParseDate("1978-06-30")
+if ut.Foo == nil {
+	ut.Foo = &defaultFoo
+}`
+
 	recursiveAssignmentCodeA = `if ut.Child != nil {
-	var defaultOther = "foo"
-	if ut.Child.Other == nil {
-		ut.Child.Other = &defaultOther
-}
+	ut.Child.Finalize()
 }
 var defaultOther = "foo"
 if ut.Other == nil {
@@ -225,13 +316,18 @@ if ut.Other == nil {
 }`
 
 	recursiveAssignmentCodeB = `	for _, e := range ut.Elems {
-		var defaultOther = "foo"
-		if e.Other == nil {
-			e.Other = &defaultOther
-}
+		if e != nil {
+			e.Finalize()
+		}
 	}
 var defaultOther = "foo"
 if ut.Other == nil {
 	ut.Other = &defaultOther
 }`
+
+	recursiveAssignmentCodeC = `	for _, e := range ut.Children {
+		if e != nil {
+			e.Finalize()
+		}
+	}`
 )
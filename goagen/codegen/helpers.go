@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"unicode"
 
@@ -129,6 +130,26 @@ func CanonicalParams(r *design.ResourceDefinition) []string {
 	return params
 }
 
+// PathRegexp returns an anchored regular expression that matches the given route path. Path
+// wildcards match a single path segment while catch-all wildcards match the rest of the path.
+func PathRegexp(path string) string {
+	var b bytes.Buffer
+	b.WriteString("^")
+	last := 0
+	for _, m := range design.WildcardRegex.FindAllStringSubmatchIndex(path, -1) {
+		b.WriteString(regexp.QuoteMeta(path[last:m[0]]))
+		if path[m[0]+1] == '*' {
+			b.WriteString("/.*")
+		} else {
+			b.WriteString("/[^/]+")
+		}
+		last = m[1]
+	}
+	b.WriteString(regexp.QuoteMeta(path[last:]))
+	b.WriteString("$")
+	return b.String()
+}
+
 // Casing exceptions
 var toLower = map[string]string{"OAuth": "oauth"}
 
@@ -170,3 +191,46 @@ func KebabCase(name string) string {
 	name = SnakeCase(name)
 	return strings.Replace(name, "_", "-", -1)
 }
+
+// Slug returns the kebab-case version of the given name with the spaces replaced with dashes,
+// suitable for use in file names.
+func Slug(name string) string {
+	return strings.Join(strings.Fields(KebabCase(name)), "-")
+}
+
+// Example returns the example of the given attribute converted with NormalizeExample, nil if the
+// attribute has no example.
+func Example(att *design.AttributeDefinition, rand *design.RandomGenerator) interface{} {
+	ex := att.GenerateExample(rand, nil)
+	if s, ok := ex.(string); ok && s == "-" {
+		return nil
+	}
+	return NormalizeExample(ex)
+}
+
+// NormalizeExample converts the maps generated by GenerateExample so that they can be encoded to
+// JSON.
+func NormalizeExample(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			res[k] = NormalizeExample(e)
+		}
+		return res
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			res[fmt.Sprint(k)] = NormalizeExample(e)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(actual))
+		for i, e := range actual {
+			res[i] = NormalizeExample(e)
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -16,4 +16,11 @@ func TestHelpers(t *testing.T) {
 	Expect(codegen.KebabCase("teste")).To(Equal("teste"))
 	Expect(codegen.KebabCase("testABC")).To(Equal("testabc"))
 	Expect(codegen.KebabCase("testAbc")).To(Equal("test-abc"))
+	Expect(codegen.PathRegexp("/bottles/:id")).To(Equal("^/bottles/[^/]+$"))
+	Expect(codegen.PathRegexp("/files/*path")).To(Equal("^/files/.*$"))
+	Expect(codegen.PathRegexp("/v1.0")).To(Equal(`^/v1\.0$`))
+	Expect(codegen.Slug("Cellar API")).To(Equal("cellar-api"))
+	Expect(codegen.Slug("listBottles")).To(Equal("list-bottles"))
+	Expect(codegen.NormalizeExample(map[interface{}]interface{}{1: []interface{}{map[interface{}]interface{}{"a": true}}})).
+		To(Equal(map[string]interface{}{"1": []interface{}{map[string]interface{}{"a": true}}}))
 }
@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/goadesign/goa/design"
 )
@@ -30,6 +31,17 @@ func (s *ImportSpec) Code() string {
 	return fmt.Sprintf(`"%s"`, s.Path)
 }
 
+// ImportAlias returns the name used to import the package with the given import path in
+// generated code. The name is made of the last two elements of the path so that the packages
+// generated for different services, which are all named "app" or "client", do not clash.
+func ImportAlias(path string) string {
+	elems := strings.Split(path, "/")
+	if len(elems) > 2 {
+		elems = elems[len(elems)-2:]
+	}
+	return strings.ToLower(Goify(strings.Join(elems, "_"), false))
+}
+
 // AttributeImports will construct a new ImportsSpec slice from an existing slice and add in imports specified in
 // struct:field:type Metadata tags.
 func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, seen []*design.AttributeDefinition) []*ImportSpec {
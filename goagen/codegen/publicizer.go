@@ -53,6 +53,18 @@ func RecursivePublicizer(att *design.AttributeDefinition, source, target string,
 			att = ds.Definition()
 		}
 		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+			if catt.Nullable {
+				// Nullable fields use the same type in both structs.
+				publications = append(publications, Publicizer(
+					catt,
+					fmt.Sprintf("%s.%s", source, Goify(n, true)),
+					fmt.Sprintf("%s.%s", target, Goify(n, true)),
+					false,
+					depth,
+					false,
+				))
+				return nil
+			}
 			publication := Publicizer(
 				catt,
 				fmt.Sprintf("%s.%s", source, Goify(n, true)),
@@ -116,7 +116,9 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 		WriteTabs(&buffer, tabs+1)
 		field := obj[name]
 		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (field.Type.IsPrimitive() && private) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+		if field.Nullable {
+			typedef = GoNullType(field.Type)
+		} else if (field.Type.IsPrimitive() && private) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
 			typedef = "*" + typedef
 		}
 		fname := GoifyAtt(field, name, true)
@@ -162,7 +164,79 @@ func attributeTags(parent, att *design.AttributeDefinition, name string, private
 	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
 		omit = ",omitempty"
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"`", name, omit, name, omit, name, omit)
+	jsonOmit := omit
+	if att.Nullable {
+		// Nullable fields are never empty, omitzero omits the absent values only. The option is
+		// implemented by goa.MarshalNullable for the Go versions that predate it, see
+		// NullableMarshaler.
+		omit, jsonOmit = ",omitempty", ",omitzero"
+	}
+	name = wireName(name)
+	formName := name
+	if n := att.Metadata["form:name"]; len(n) > 0 && n[0] != "" {
+		formName = n[0]
+	}
+	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"`", formName, omit, name, jsonOmit, name, omit)
+}
+
+// nullValueFields lists the names of the value fields of the goa null types indexed by kind.
+var nullValueFields = map[design.Kind]string{
+	design.BooleanKind:  "Bool",
+	design.IntegerKind:  "Int",
+	design.NumberKind:   "Float64",
+	design.StringKind:   "String",
+	design.DateTimeKind: "Time",
+}
+
+// GoNullType returns the name of the goa type used to define the fields of the nullable attributes
+// of type t, e.g. "goa.NullString".
+func GoNullType(t design.DataType) string {
+	return "goa.Null" + NullValueField(t)
+}
+
+// NullValueField returns the name of the field that holds the value in the goa type used to define
+// the fields of the nullable attributes of type t, e.g. "String".
+func NullValueField(t design.DataType) string {
+	f, ok := nullValueFields[t.Kind()]
+	if !ok {
+		panic(fmt.Sprintf("goa bug: type %s cannot be nullable", t.Name()))
+	}
+	return f
+}
+
+// NullableMarshaler returns the MarshalJSON method of the Go type typeName generated from att if
+// the type defines nullable attributes and the empty string otherwise. The method encodes the
+// values with goa.MarshalNullable so that the absent nullable attributes are omitted regardless
+// of the Go version used to compile the generated code.
+func NullableMarshaler(att *design.AttributeDefinition, typeName, receiver string) string {
+	if !hasNullable(att) {
+		return ""
+	}
+	return fmt.Sprintf(`// MarshalJSON encodes %[1]s omitting the nullable attributes that are absent.
+func (%[2]s %[1]s) MarshalJSON() ([]byte, error) {
+	type alias %[1]s
+	return goa.MarshalNullable((*alias)(&%[2]s))
+}
+`, typeName, receiver)
+}
+
+// hasNullable returns true if att or one of its inline child attributes is nullable. The child
+// attributes of user and media types are not considered as these types define their own
+// MarshalJSON method.
+func hasNullable(att *design.AttributeDefinition) bool {
+	switch actual := att.Type.(type) {
+	case design.Object:
+		for _, catt := range actual {
+			if catt.Nullable || hasNullable(catt) {
+				return true
+			}
+		}
+	case *design.Array:
+		return hasNullable(actual.ElemType)
+	case *design.Hash:
+		return hasNullable(actual.KeyType) || hasNullable(actual.ElemType)
+	}
+	return false
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -211,6 +285,19 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 			GoTypeRef(actual.ElemType.Type, actual.ElemType.AllRequired(), tabs+1, private),
 		)
 	case *design.UserTypeDefinition:
+		if actual == design.Money {
+			if design.Design.MoneyWireFormat() == design.MoneyDecimal {
+				return "goa.DecimalMoney"
+			}
+			return "goa.Money"
+		}
+		if design.IsGeo(actual) {
+			return "goa." + actual.TypeName
+		}
+		if actual.Enum || actual.Flags != nil {
+			// Enum and flags types have no private counterpart
+			return Goify(actual.TypeName, true)
+		}
 		return Goify(actual.TypeName, !private)
 	case *design.MediaTypeDefinition:
 		if actual.IsError() {
@@ -237,10 +324,14 @@ func GoNativeType(t design.DataType) string {
 			return "string"
 		case design.DateTimeKind:
 			return "time.Time"
+		case design.DateKind:
+			return "goa.Date"
 		case design.UUIDKind:
 			return "uuid.UUID"
 		case design.AnyKind:
 			return "interface{}"
+		case design.FileKind:
+			return "goa.FormFile"
 		default:
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
@@ -255,6 +255,49 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using form name metadata", func() {
+					BeforeEach(func() {
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"form:name": []string{"foo_field"},
+						}
+					})
+
+					It("sets the form tag only", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
+							"	Foo *int `form:\"foo_field,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
+				Context("using a wire naming convention", func() {
+					var api *APIDefinition
+
+					BeforeEach(func() {
+						api = Design
+						Design = &APIDefinition{WireNaming: SnakeCase}
+						object["fooBar"] = object["foo"]
+						delete(object, "foo")
+					})
+
+					AfterEach(func() {
+						Design = api
+					})
+
+					It("converts the tag names", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
+							"	FooBar *int `form:\"foo_bar,omitempty\" json:\"foo_bar,omitempty\" xml:\"foo_bar,omitempty\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
 				Context("using struct field type metadata", func() {
 					BeforeEach(func() {
 						object["foo"].Metadata = dslengine.MetadataDefinition{
@@ -272,6 +315,39 @@ var _ = Describe("code generation", func() {
 						Ω(st).Should(Equal(expected))
 					})
 				})
+
+				Context("using nullable attributes", func() {
+					BeforeEach(func() {
+						object["bar"].Nullable = true
+						object["baz"].Nullable = true
+					})
+
+					It("uses the null types", func() {
+						expected := "struct {\n" +
+							"	Bar goa.NullString `form:\"bar,omitempty\" json:\"bar,omitzero\" xml:\"bar,omitempty\"`\n" +
+							"	Baz goa.NullTime `form:\"baz,omitempty\" json:\"baz,omitzero\" xml:\"baz,omitempty\"`\n" +
+							"	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+			})
+
+			Context("of date type", func() {
+				BeforeEach(func() {
+					object = Object{
+						"day": &AttributeDefinition{Type: Date},
+					}
+					required = nil
+				})
+
+				It("produces a goa.Date field", func() {
+					expected := "struct {\n" +
+						"	Day *goa.Date `form:\"day,omitempty\" json:\"day,omitempty\" xml:\"day,omitempty\"`\n" +
+						"}"
+					Ω(st).Should(Equal(expected))
+				})
 			})
 
 			Context("of hash of primitive types", func() {
@@ -646,3 +722,33 @@ var _ = Describe("GoTypeDesc", func() {
 		})
 	})
 })
+
+var _ = Describe("NullableMarshaler", func() {
+	var att *AttributeDefinition
+
+	BeforeEach(func() {
+		att = &AttributeDefinition{Type: Object{
+			"name":  &AttributeDefinition{Type: String},
+			"inner": &AttributeDefinition{Type: Object{"note": &AttributeDefinition{Type: String}}},
+		}}
+	})
+
+	It("returns nothing for types without nullable attributes", func() {
+		Ω(codegen.NullableMarshaler(att, "Bottle", "ut")).Should(BeEmpty())
+	})
+
+	It("encodes the types with nullable attributes with goa.MarshalNullable", func() {
+		att.Type.ToObject()["inner"].Type.ToObject()["note"].Nullable = true
+		code := codegen.NullableMarshaler(att, "Bottle", "ut")
+		Ω(code).Should(ContainSubstring("func (ut Bottle) MarshalJSON() ([]byte, error) {"))
+		Ω(code).Should(ContainSubstring("type alias Bottle\n\treturn goa.MarshalNullable((*alias)(&ut))"))
+	})
+
+	It("ignores the nullable attributes of user types", func() {
+		ut := &UserTypeDefinition{TypeName: "Note", AttributeDefinition: &AttributeDefinition{Type: Object{
+			"text": &AttributeDefinition{Type: String, Nullable: true},
+		}}}
+		att.Type.ToObject()["note"] = &AttributeDefinition{Type: ut}
+		Ω(codegen.NullableMarshaler(att, "Bottle", "ut")).Should(BeEmpty())
+	})
+})
@@ -9,15 +9,18 @@ import (
 	"text/template"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 )
 
 var (
-	enumValT     *template.Template
-	formatValT   *template.Template
-	patternValT  *template.Template
-	minMaxValT   *template.Template
-	lengthValT   *template.Template
-	requiredValT *template.Template
+	enumValT         *template.Template
+	formatValT       *template.Template
+	patternValT      *template.Template
+	minMaxValT       *template.Template
+	phoneValT        *template.Template
+	lengthValT       *template.Template
+	requiredValT     *template.Template
+	requiredWhenValT *template.Template
 )
 
 //  init instantiates the templates.
@@ -30,6 +33,7 @@ func init() {
 		"constant": constant,
 		"goifyAtt": GoifyAtt,
 		"add":      Add,
+		"wireName": wireName,
 	}
 	if enumValT, err = template.New("enum").Funcs(fm).Parse(enumValTmpl); err != nil {
 		panic(err)
@@ -43,12 +47,18 @@ func init() {
 	if minMaxValT, err = template.New("minMax").Funcs(fm).Parse(minMaxValTmpl); err != nil {
 		panic(err)
 	}
+	if phoneValT, err = template.New("phone").Funcs(fm).Parse(phoneValTmpl); err != nil {
+		panic(err)
+	}
 	if lengthValT, err = template.New("length").Funcs(fm).Parse(lengthValTmpl); err != nil {
 		panic(err)
 	}
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
+	if requiredWhenValT, err = template.New("requiredWhen").Funcs(fm).Parse(requiredWhenValTmpl); err != nil {
+		panic(err)
+	}
 }
 
 // Validator is the code generator for the 'Validate' type methods.
@@ -91,10 +101,6 @@ func NewValidator() *Validator {
 
 // Code produces Go code that runs the validation checks recursively over the given attribute.
 func (v *Validator) Code(att *design.AttributeDefinition, nonzero, required, hasDefault bool, target, context string, depth int, private bool) string {
-	if _, ok := att.Metadata["struct:field:type"]; ok {
-		// Skip validation generation for attributes with custom types
-		return ""
-	}
 	buf := v.recurse(att, nonzero, required, hasDefault, target, context, depth, private)
 	return buf.String()
 }
@@ -114,16 +120,17 @@ func (v *Validator) arrayValCode(att *design.AttributeDefinition, nonzero, requi
 		buf.WriteString(validation)
 		first = false
 	}
-	val := v.Code(a.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
+	val := v.Code(a.ElemType, true, false, false, "e", context+"[*]", depth+2, false)
 	if val != "" {
-		switch a.ElemType.Type.(type) {
-		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		if hasValidateMethod(a.ElemType.Type) {
 			// For user and media types, call the Validate method
 			val = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth + 2,
-				"target": "e",
+				"depth":   depth + 3,
+				"target":  "e",
+				"context": context + "[*]",
+				"builtin": IsBuiltinType(a.ElemType.Type),
 			})
-			val = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), val, Tabs(depth+1))
+			val = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+2), val, Tabs(depth+2))
 		}
 		data := map[string]interface{}{
 			"elemType":   a.ElemType,
@@ -161,24 +168,26 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 	}
 	keyVal := v.Code(h.KeyType, true, false, false, "k", context+"[*]", depth+1, false)
 	if keyVal != "" {
-		switch h.KeyType.Type.(type) {
-		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		if hasValidateMethod(h.KeyType.Type) {
 			// For user and media types, call the Validate method
 			keyVal = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth + 2,
-				"target": "k",
+				"depth":   depth + 2,
+				"target":  "k",
+				"context": context + "[*]",
+				"builtin": IsBuiltinType(h.KeyType.Type),
 			})
 			keyVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), keyVal, Tabs(depth+1))
 		}
 	}
 	elemVal := v.Code(h.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
 	if elemVal != "" {
-		switch h.ElemType.Type.(type) {
-		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		if hasValidateMethod(h.ElemType.Type) {
 			// For user and media types, call the Validate method
 			elemVal = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth + 2,
-				"target": "e",
+				"depth":   depth + 2,
+				"target":  "e",
+				"context": context + "[*]",
+				"builtin": IsBuiltinType(h.ElemType.Type),
 			})
 			elemVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), elemVal, Tabs(depth+1))
 		}
@@ -207,6 +216,32 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 		first = true
 	)
 
+	// Skip validation generation for attributes with custom types and for attributes whose
+	// structure is not known at design time
+	if _, ok := att.Metadata["struct:field:type"]; ok || att.Type.Kind() == design.AnyKind {
+		return buf
+	}
+
+	// Enum types are validated like primitive types using the enum values of the type
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok && ut.Enum {
+		eatt := &design.AttributeDefinition{
+			Type:       ut,
+			Validation: &dslengine.ValidationDefinition{Values: ut.Validation.Values},
+		}
+		buf.WriteString(ValidationChecker(eatt, nonzero, required, hasDefault, target, context, depth, private))
+		return buf
+	}
+
+	// Flags types are validated by checking that no unknown bit is set
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok && ut.Flags != nil {
+		fatt := &design.AttributeDefinition{
+			Type:       ut.Type,
+			Validation: &dslengine.ValidationDefinition{Maximum: ut.Validation.Maximum},
+		}
+		buf.WriteString(ValidationChecker(fatt, nonzero, required, hasDefault, target, context, depth, private))
+		return buf
+	}
+
 	// Break infinite recursions
 	switch dt := att.Type.(type) {
 	case *design.MediaTypeDefinition:
@@ -257,7 +292,7 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 
 func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, target, context string, depth int, private bool) string {
 	var validation string
-	if ds, ok := catt.Type.(design.DataStructure); ok {
+	if ds, ok := catt.Type.(design.DataStructure); ok && !design.IsEnum(catt.Type) && !design.IsFlags(catt.Type) {
 		// We need to check empirically whether there are validations to be
 		// generated, we can't just generate and check whether something was
 		// generated to avoid infinite recursions.
@@ -281,7 +316,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 				}
 				for _, name := range a.Validation.Required {
 					att := a.Type.ToObject()[name]
-					if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind) {
+					if att != nil && (!att.Type.IsPrimitive() || att.Type.Kind() == design.StringKind || att.Nullable) {
 						hasValidations = true
 						return done
 					}
@@ -291,8 +326,10 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		})
 		if hasValidations {
 			validation = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth,
-				"target": fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
+				"depth":   depth,
+				"target":  fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
+				"context": fmt.Sprintf("%s.%s", context, wireName(n)),
+				"builtin": IsBuiltinType(catt.Type),
 			})
 		}
 	} else {
@@ -306,7 +343,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 			att.IsRequired(n),
 			att.HasDefaultValue(n),
 			fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
-			fmt.Sprintf("%s.%s", context, n),
+			fmt.Sprintf("%s.%s", context, wireName(n)),
 			dp,
 			private,
 		).String()
@@ -332,18 +369,26 @@ func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDe
 		return ""
 	}
 	t := target
+	guard := target + " != nil"
 	isPointer := private || (!required && !hasDefault && !nonzero)
-	if isPointer && att.Type.IsPrimitive() {
+	if att.Nullable {
+		// Null values are not validated.
+		isPointer = true
+		guard = target + ".Valid"
+		t = target + "." + NullValueField(att.Type)
+	} else if isPointer && att.Type.IsPrimitive() {
 		t = "*" + t
 	}
 	data := map[string]interface{}{
 		"attribute": att,
 		"isPointer": private || isPointer,
+		"guard":     guard,
 		"nonzero":   nonzero,
 		"context":   context,
 		"target":    target,
 		"targetVal": t,
 		"string":    att.Type.Kind() == design.StringKind,
+		"file":      att.Type.Kind() == design.FileKind,
 		"array":     att.Type.IsArray(),
 		"hash":      att.Type.IsHash(),
 		"depth":     depth,
@@ -363,7 +408,12 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 	}
 	if format := validation.Format; format != "" {
 		data["format"] = format
-		if val := RunTemplate(formatValT, data); val != "" {
+		t := formatValT
+		if att.IsPhoneNumber() {
+			data["region"] = att.PhoneRegion()
+			t = phoneValT
+		}
+		if val := RunTemplate(t, data); val != "" {
 			res = append(res, val)
 		}
 	}
@@ -424,9 +474,89 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 		}
 		res = append(res, val)
 	}
+	for _, r := range validation.RequiredWhen {
+		if val := requiredWhenCode(att, r, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	return
 }
 
+// requiredWhenCode produces the Go code that checks that the field r.Name of the object
+// attribute att is set when the field r.Field has the value r.Value.
+func requiredWhenCode(att *design.AttributeDefinition, r *dslengine.RequiredWhenDefinition, data map[string]interface{}) string {
+	o := att.Type.ToObject()
+	natt, fatt := o[r.Name], o[r.Field]
+	if natt == nil || fatt == nil {
+		return ""
+	}
+	private := data["private"].(bool)
+	target := data["target"].(string)
+	isPointer := func(n string) bool {
+		return private || (!att.IsRequired(n) && !att.HasDefaultValue(n) && !att.IsNonZero(n))
+	}
+
+	field := fmt.Sprintf("%s.%s", target, GoifyAtt(fatt, r.Field, true))
+	value := fmt.Sprintf("%#v", r.Value)
+	var condition string
+	switch {
+	case fatt.Nullable:
+		condition = fmt.Sprintf("%s.Valid && %s.%s == %s", field, field, NullValueField(fatt.Type), value)
+	case isPointer(r.Field):
+		condition = fmt.Sprintf("%s != nil && *%s == %s", field, field, value)
+	default:
+		condition = fmt.Sprintf("%s == %s", field, value)
+	}
+
+	name := fmt.Sprintf("%s.%s", target, GoifyAtt(natt, r.Name, true))
+	var missing string
+	switch {
+	case natt.Nullable:
+		missing = fmt.Sprintf("!%s.Set", name)
+	case private || !natt.Type.IsPrimitive() || isPointer(r.Name):
+		missing = name + " == nil"
+	case natt.Type.Kind() == design.StringKind:
+		missing = name + ` == ""`
+	default:
+		// Non-pointer primitive fields are always set.
+		return ""
+	}
+
+	return RunTemplate(requiredWhenValT, map[string]interface{}{
+		"depth":     data["depth"],
+		"context":   data["context"],
+		"condition": condition,
+		"missing":   missing,
+		"name":      r.Name,
+		"field":     r.Field,
+		"value":     value,
+	})
+}
+
+// hasValidateMethod returns true if the Go type generated for dt defines a Validate method, that
+// is if dt is a media type or a user type other than an enum or flags type.
+func hasValidateMethod(dt design.DataType) bool {
+	switch dt.(type) {
+	case *design.MediaTypeDefinition:
+		return true
+	case *design.UserTypeDefinition:
+		return !design.IsEnum(dt) && !design.IsFlags(dt)
+	}
+	return false
+}
+
+// IsBuiltinType returns true if dt is a built-in type implemented by the goa package, e.g. Money
+// or Point. The Validate method of the goa types is called with the attribute context through
+// ValidateAt so that the error messages describe the path of the attribute.
+func IsBuiltinType(dt design.DataType) bool {
+	return design.IsMoney(dt) || design.IsGeo(dt)
+}
+
+// wireName returns the name of the attribute with the given name in request and response bodies.
+func wireName(name string) string {
+	return design.Design.WireName(name)
+}
+
 // renderInteger renders a max or min value properly, taking into account
 // overflows due to casting from a float value.
 func renderInteger(f float64) string {
@@ -449,7 +579,8 @@ func oneof(target string, vals []interface{}) string {
 	return strings.Join(elems, " || ")
 }
 
-// constant returns the Go constant name of the format with the given value.
+// constant returns the Go constant name of the format with the given value or the conversion of
+// the name of a custom format.
 func constant(formatName string) string {
 	switch formatName {
 	case "date-time":
@@ -474,13 +605,20 @@ func constant(formatName string) string {
 		return "goa.FormatRegexp"
 	case "rfc1123":
 		return "goa.FormatRFC1123"
+	case "currency":
+		return "goa.FormatCurrency"
 	}
-	panic("unknown format") // bug
+	return fmt.Sprintf("goa.Format(%q)", formatName)
 }
 
 const (
-	arrayValTmpl = `{{ tabs .depth }}for _, e := range {{ .target }} {
+	arrayValTmpl = `{{ tabs .depth }}for i, e := range {{ .target }} {
+{{ tabs .depth }}	if err2 := func() (err error) {
 {{ .validation }}
+{{ tabs .depth }}		return
+{{ tabs .depth }}	}(); err2 != nil {
+{{ tabs .depth }}		err = goa.MergeErrors(err, goa.WithErrorIndex(err2, ` + "`{{ .context }}`" + `, i))
+{{ tabs .depth }}	}
 {{ tabs .depth }}}`
 
 	hashValTmpl = `{{ tabs .depth }}for {{ if .keyValidation }}k{{ else }}_{{ end }}, {{ if .elemValidation }}e{{ else }}_{{ end }} := range {{ .target }} {
@@ -489,33 +627,42 @@ const (
 {{ .elemValidation }}{{ end }}
 {{ tabs .depth }}}`
 
-	userValTmpl = `{{ tabs .depth }}if err2 := {{ .target }}.Validate(); err2 != nil {
+	userValTmpl = `{{ if .builtin }}{{ tabs .depth }}if err2 := {{ .target }}.ValidateAt(` + "`{{ .context }}`" + `); err2 != nil {
 {{ tabs .depth }}	err = goa.MergeErrors(err, err2)
-{{ tabs .depth }}}`
+{{ tabs .depth }}}{{ else }}{{ tabs .depth }}if err2 := {{ .target }}.Validate(); err2 != nil {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.WithErrorContext(err2, ` + "`{{ .context }}`" + `))
+{{ tabs .depth }}}{{ end }}`
 
 	enumValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
-*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .guard }} {
 {{ end }}{{ tabs $depth }}if !({{ oneof .targetVal .values }}) {
 {{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ slice .values }}))
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
 	patternValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
-*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .guard }} {
 {{ end }}{{ tabs $depth }}if ok := goa.ValidatePattern(` + "`{{ .pattern }}`" + `, {{ .targetVal }}); !ok {
 {{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, ` + "`{{ .pattern }}`" + `))
 {{ tabs $depth }}}{{ if .isPointer }}
 {{ tabs .depth }}}{{ end }}`
 
 	formatValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
-*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .guard }} {
 {{ end }}{{ tabs $depth }}if err2 := goa.ValidateFormat({{ constant .format }}, {{ .targetVal }}); err2 != nil {
 {{ tabs $depth }}		err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ constant .format }}, err2))
 {{ if .isPointer }}{{ tabs $depth }}}
+{{ end }}{{ tabs .depth }}}`
+
+	phoneValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .guard }} {
+{{ end }}{{ tabs $depth }}if err2 := goa.ValidatePhoneNumber(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ printf "%q" .region }}); err2 != nil {
+{{ tabs $depth }}	err = goa.MergeErrors(err, err2)
+{{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
 	minMaxValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
-*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .guard }} {
 {{ end }}{{ tabs .depth }}	if {{ .targetVal }} {{ if .isMin }}<{{ else }}>{{ end }} {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }} {
 {{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}))
 {{ if .isPointer }}{{ tabs $depth }}}
@@ -523,16 +670,22 @@ const (
 
 	lengthValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ $target := or (and (or (or .array .hash) .nonzero) .target) .targetVal }}{{/*
-*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
-{{ end }}{{ tabs .depth }}	if {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }} {{ if .isMinLength }}<{{ else }}>{{ end }} {{ if .isMinLength }}{{ .minLength }}{{ else }}{{ .maxLength }}{{ end }} {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `{{ .context }}` + "`" + `, {{ $target }}, {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }}, {{ if .isMinLength }}{{ .minLength }}, true{{ else }}{{ .maxLength }}, false{{ end }}))
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .guard }} {
+{{ end }}{{ tabs .depth }}	if {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else if .file }}{{ .target }}.Size{{ else }}len({{ $target }}){{ end }} {{ if .isMinLength }}<{{ else }}>{{ end }} {{ if .isMinLength }}{{ .minLength }}{{ else }}{{ .maxLength }}{{ end }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `{{ .context }}` + "`" + `, {{ if .file }}{{ .target }}.Filename{{ else }}{{ $target }}{{ end }}, {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else if .file }}int({{ .target }}.Size){{ else }}len({{ $target }}){{ end }}, {{ if .isMinLength }}{{ .minLength }}, true{{ else }}{{ .maxLength }}, false{{ end }}))
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
+	requiredWhenValTmpl = `{{ tabs .depth }}if {{ .condition }} && {{ .missing }} {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.MissingConditionalAttributeError(` + "`" + `{{ .context }}` + "`" + `, "{{ wireName .name }}", "{{ wireName .field }}", {{ .value }}))
+{{ tabs .depth }}}`
+
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
-*/}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
-{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
+*/}}{{ if $att.Nullable }}{{ tabs $.depth }}if !{{ $.target }}.{{ goifyAtt $att .required true }}.Set {
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingNullableAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ wireName .required }}"))
+{{ tabs $.depth }}}{{ else if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ wireName .required }}"))
 {{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
-{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ wireName .required }}"))
 {{ tabs $.depth }}}{{ end }}`
 )
@@ -59,6 +59,19 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of custom format", func() {
+				BeforeEach(func() {
+					attType = design.String
+					validation = &dslengine.ValidationDefinition{
+						Format: "semver",
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(customFormatValCode))
+				})
+			})
+
 			Context("of min value 0", func() {
 				BeforeEach(func() {
 					attType = design.Integer
@@ -332,6 +345,18 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of built-in geo type attribute", func() {
+				BeforeEach(func() {
+					attType = design.Object{"location": &design.AttributeDefinition{Type: design.Point}}
+					validation = nil
+				})
+
+				It("validates the attribute in its context", func() {
+					Ω(code).Should(ContainSubstring("if err2 := val.Location.ValidateAt(`context.location`); err2 != nil {\n\t\terr = goa.MergeErrors(err, err2)\n\t}"))
+					Ω(code).ShouldNot(ContainSubstring("WithErrorContext"))
+				})
+			})
+
 			Context("with a custom type metadata", func() {
 				JustBeforeEach(func() {
 					att.Metadata = map[string][]string{"struct:field:type": {"foo"}}
@@ -349,6 +374,82 @@ var _ = Describe("validation code generation", func() {
 					Ω(code).Should(BeEmpty())
 				})
 			})
+
+			Context("with a child attribute with a custom type metadata", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"raw": &design.AttributeDefinition{
+							Type:       design.String,
+							Metadata:   map[string][]string{"struct:field:type": {"json.RawMessage", "encoding/json"}},
+							Validation: &dslengine.ValidationDefinition{Pattern: "^a"},
+						},
+					}
+					validation = nil
+				})
+
+				It("does not produce validation code", func() {
+					Ω(code).Should(BeEmpty())
+				})
+			})
+
+			Context("of an array of Any", func() {
+				BeforeEach(func() {
+					attType = &design.Array{ElemType: &design.AttributeDefinition{
+						Type:       design.Any,
+						Validation: &dslengine.ValidationDefinition{Values: []interface{}{1, "a"}},
+					}}
+					validation = nil
+				})
+
+				It("does not validate the elements", func() {
+					Ω(code).Should(BeEmpty())
+				})
+			})
+
+			Context("of phone format", func() {
+				BeforeEach(func() {
+					attType = design.String
+					validation = &dslengine.ValidationDefinition{
+						Format: "phone",
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(phoneValCode))
+				})
+			})
+
+			Context("of a required nullable attribute", func() {
+				BeforeEach(func() {
+					min := 2
+					attType = design.Object{"nick": &design.AttributeDefinition{
+						Type:       design.String,
+						Nullable:   true,
+						Validation: &dslengine.ValidationDefinition{MinLength: &min},
+					}}
+					validation = &dslengine.ValidationDefinition{Required: []string{"nick"}}
+				})
+
+				It("checks the presence and validates the non null value", func() {
+					Ω(code).Should(Equal(nullableValCode))
+				})
+			})
+
+			Context("of a conditionally required attribute", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"method":  &design.AttributeDefinition{Type: design.String},
+						"address": &design.AttributeDefinition{Type: design.String},
+					}
+					validation = &dslengine.ValidationDefinition{RequiredWhen: []*dslengine.RequiredWhenDefinition{
+						{Name: "address", Field: "method", Value: "ship"},
+					}}
+				})
+
+				It("checks the presence when the condition holds", func() {
+					Ω(code).Should(Equal(requiredWhenValCode))
+				})
+			})
 		})
 	})
 })
@@ -366,6 +467,12 @@ const (
 		}
 	}`
 
+	customFormatValCode = `	if val != nil {
+		if err2 := goa.ValidateFormat(goa.Format("semver"), *val); err2 != nil {
+				err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`context`" + `, *val, goa.Format("semver"), err2))
+		}
+	}`
+
 	minValCode = `	if val != nil {
 		if *val < 0 {
 			err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
@@ -390,9 +497,14 @@ const (
 		}
 	}`
 
-	arrayElementsValCode = `	for _, e := range val {
-		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+	arrayElementsValCode = `	for i, e := range val {
+		if err2 := func() (err error) {
+			if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.WithErrorIndex(err2, ` + "`" + `context` + "`" + `, i))
 		}
 	}`
 
@@ -462,11 +574,35 @@ const (
 		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
 	}`
 
-	utRequiredCode = `	for _, e := range val.Foo {
-		if e != nil {
-			if err2 := e.Validate(); err2 != nil {
-				err = goa.MergeErrors(err, err2)
+	utRequiredCode = `	for i, e := range val.Foo {
+		if err2 := func() (err error) {
+			if e != nil {
+				if err2 := e.Validate(); err2 != nil {
+					err = goa.MergeErrors(err, goa.WithErrorContext(err2, ` + "`" + `context.foo[*]` + "`" + `))
+				}
 			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.WithErrorIndex(err2, ` + "`" + `context.foo` + "`" + `, i))
+		}
+	}`
+
+	phoneValCode = `	if val != nil {
+		if err2 := goa.ValidatePhoneNumber(` + "`context`" + `, *val, ""); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}
+	}`
+
+	requiredWhenValCode = `	if val.Method != nil && *val.Method == "ship" && val.Address == nil {
+		err = goa.MergeErrors(err, goa.MissingConditionalAttributeError(` + "`context`" + `, "address", "method", "ship"))
+	}`
+
+	nullableValCode = `	if !val.Nick.Set {
+		err = goa.MergeErrors(err, goa.MissingNullableAttributeError(` + "`context`" + `, "nick"))
+	}
+	if val.Nick.Valid {
+		if utf8.RuneCountInString(val.Nick.String) < 2 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`context.nick`" + `, val.Nick.String, utf8.RuneCountInString(val.Nick.String), 2, true))
 		}
 	}`
 )
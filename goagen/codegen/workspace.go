@@ -67,6 +67,7 @@ var (
 		"gotypedesc":          GoTypeDesc,
 		"gotyperef":           GoTypeRef,
 		"join":                strings.Join,
+		"nullableMarshaler":   NullableMarshaler,
 		"recursivePublicizer": RecursivePublicizer,
 		"tabs":                Tabs,
 		"tempvar":             Tempvar,
@@ -234,9 +235,16 @@ func SourceFileFor(path string) (*SourceFile, error) {
 
 // WriteHeader writes the generic generated code header.
 func (f *SourceFile) WriteHeader(title, pack string, imports []*ImportSpec) error {
+	return f.WriteBuildHeader(title, pack, "", imports)
+}
+
+// WriteBuildHeader writes the generic generated code header followed by the given build
+// constraint, e.g. "!js" for code that cannot be compiled to WebAssembly.
+func (f *SourceFile) WriteBuildHeader(title, pack, constraint string, imports []*ImportSpec) error {
 	ctx := map[string]interface{}{
 		"Title":       title,
 		"ToolVersion": version.String(),
+		"Build":       constraint,
 		"Pkg":         pack,
 		"Imports":     imports,
 	}
@@ -376,6 +384,8 @@ const (
 // Command:
 {{comment commandLine}}
 
+{{end}}{{if .Build}}// +build {{.Build}}
+
 {{end}}package {{.Pkg}}
 
 {{if .Imports}}import {{if gt (len .Imports) 1}}(
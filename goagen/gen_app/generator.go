@@ -103,6 +103,15 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateUserTypes(); err != nil {
 		return nil, err
 	}
+	if err := g.generateValidators(); err != nil {
+		return nil, err
+	}
+	if err := g.generateContextValues(); err != nil {
+		return nil, err
+	}
+	if err := g.generateFingerprint(); err != nil {
+		return nil, err
+	}
 	if !g.NoTest {
 		if err := g.generateResourceTest(); err != nil {
 			return nil, err
@@ -144,13 +153,16 @@ func (g *Generator) generateContexts() (err error) {
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/tus"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
 		codegen.SimpleImport("context"),
 	}
 	g.API.IterateResources(func(r *design.ResourceDefinition) error {
@@ -158,6 +170,9 @@ func (g *Generator) generateContexts() (err error) {
 			if a.Payload != nil {
 				imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
 			}
+			if a.Messages != nil && a.Messages.Inbound != nil {
+				imports = codegen.AttributeImports(a.Messages.Inbound.AttributeDefinition, imports, nil)
+			}
 			return nil
 		})
 	})
@@ -184,9 +199,18 @@ func (g *Generator) generateContexts() (err error) {
 				headers = nil // So that {{if .Headers}} returns false in templates
 			}
 			params := a.AllParams()
+			if a.Expand != nil {
+				// The expand param is parsed by the generated code into the Expand field.
+				params.Type = design.Dup(params.Type)
+				delete(params.Type.ToObject(), "expand")
+			}
 			if params != nil && len(params.Type.ToObject()) == 0 {
 				params = nil // So that {{if .Params}} returns false in templates
 			}
+			cookies := a.Cookies
+			if cookies != nil && len(cookies.Type.ToObject()) == 0 {
+				cookies = nil
+			}
 
 			non101 := make(map[string]*design.ResponseDefinition)
 			for k, v := range a.Responses {
@@ -195,17 +219,27 @@ func (g *Generator) generateContexts() (err error) {
 				}
 			}
 			ctxData := ContextTemplateData{
-				Name:         ctxName,
-				ResourceName: r.Name,
-				ActionName:   a.Name,
-				Payload:      a.Payload,
-				Params:       params,
-				Headers:      headers,
-				Routes:       a.Routes,
-				Responses:    non101,
-				API:          g.API,
-				DefaultPkg:   g.Target,
-				Security:     a.Security,
+				Name:             ctxName,
+				ResourceName:     r.Name,
+				ActionName:       a.Name,
+				Payload:          a.Payload,
+				Params:           params,
+				Headers:          headers,
+				Cookies:          cookies,
+				Routes:           a.Routes,
+				Responses:        non101,
+				API:              g.API,
+				DefaultPkg:       g.Target,
+				Security:         a.Security,
+				AcceptRanges:     a.AcceptRanges,
+				Resumable:        a.Resumable,
+				VersionAttribute: a.VersionAttribute,
+				Pagination:       a.Pagination,
+				RenderHTML:       a.RenderHTML,
+				Embeddables:      embeddables(g.API, a),
+				ParentKey:        actionParentKey(a),
+				Messages:         a.Messages,
+				PayloadStreaming: a.PayloadStreaming,
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -236,10 +270,18 @@ func (g *Generator) generateControllers() (err error) {
 	title := fmt.Sprintf("%s: Application Controllers", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/aggregate"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
+		codegen.SimpleImport("github.com/goadesign/goa/enrich"),
+		codegen.SimpleImport("github.com/goadesign/goa/saga"),
+		codegen.SimpleImport("github.com/goadesign/goa/signedurl"),
+		codegen.SimpleImport("github.com/goadesign/goa/tus"),
 		codegen.SimpleImport("regexp"),
 	}
 	encoders, err := BuildEncoders(g.API.Produces, true)
@@ -300,23 +342,51 @@ func (g *Generator) generateControllers() (err error) {
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
 		}
+		g.API.IterateVersions(func(v *design.VersionDefinition) error {
+			switch {
+			case v.Scheme == design.VersionPath && r.SupportsVersion(v.Name):
+				data.PathVersions = append(data.PathVersions, v)
+			case v.Scheme != design.VersionPath && !r.SupportsVersion(v.Name):
+				data.HiddenVersions = append(data.HiddenVersions, v)
+			}
+			return nil
+		})
+		var actions []*design.ActionDefinition
 		r.IterateActions(func(a *design.ActionDefinition) error {
+			actions = append(actions, a)
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
+			key := actionParentKey(a)
 			action := map[string]interface{}{
 				"Name":            codegen.Goify(a.Name, true),
 				"DesignName":      a.Name,
+				"ResourceName":    r.Name,
 				"Routes":          a.Routes,
 				"Context":         context,
 				"Unmarshal":       unmarshal,
 				"Payload":         a.Payload,
 				"PayloadOptional": a.PayloadOptional,
+				"Multipart":       a.PayloadMultipart,
+				"Streaming":       a.PayloadStreaming,
+				"FileLimits":      fileLimits(a),
 				"Security":        a.Security,
+				"ReplayProtected": a.ReplayProtected,
+				"DigestAlgorithm": a.DigestAlgorithm,
+				"Resumable":       a.Resumable,
+				"SignedURL":       a.SignedURL,
+				"QueryCost":       queryCost(g.API, a),
+				"Concurrency":     a.Concurrency,
+				"ParentKey":       key != nil,
+				"Enrich":          len(a.Enrichments) > 0,
+			}
+			if key != nil {
+				data.ParentKey = key
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
 		})
 		if len(data.Actions) > 0 || len(data.FileServers) > 0 {
+			data.Patterns = validationPatterns(actions...)
 			data.Encoders = encoders
 			data.Decoders = decoders
 			data.Origins = r.AllOrigins()
@@ -324,7 +394,19 @@ func (g *Generator) generateControllers() (err error) {
 		}
 		return nil
 	})
-	err = ctlWr.Execute(controllersData)
+	if err = ctlWr.Execute(controllersData); err != nil {
+		return
+	}
+	if err = ctlWr.WriteActionMiddleware(g.API); err != nil {
+		return
+	}
+	if err = ctlWr.WriteSagas(g.API); err != nil {
+		return
+	}
+	if err = ctlWr.WriteAggregates(g.API); err != nil {
+		return
+	}
+	err = ctlWr.WriteEnrichments(g.API)
 	return
 }
 
@@ -445,7 +527,15 @@ func (g *Generator) generateMediaTypes() (err error) {
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
+	imported := make(map[string]bool)
 	for _, v := range g.API.MediaTypes {
+		if pkg, ok := v.Metadata["struct:pkg:path"]; ok && len(pkg) > 0 {
+			if !imported[pkg[0]] {
+				imports = append(imports, codegen.NewImport(codegen.ImportAlias(pkg[0]), pkg[0]))
+				imported[pkg[0]] = true
+			}
+			continue
+		}
 		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
 	}
 	if err = mtWr.WriteHeader(title, g.Target, imports); err != nil {
@@ -486,6 +576,7 @@ func (g *Generator) generateUserTypes() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -504,3 +595,140 @@ func (g *Generator) generateUserTypes() (err error) {
 	})
 	return
 }
+
+// generateContextValues generates the functions that store and retrieve the request-scoped values
+// declared with ContextValue. It does not generate any file if the API declares no such value.
+func (g *Generator) generateContextValues() (err error) {
+	if len(g.API.ContextValues) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(g.API.ContextValues))
+	for n := range g.API.ContextValues {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	values := make([]*design.ContextValueDefinition, len(names))
+	for i, n := range names {
+		values[i] = g.API.ContextValues[n]
+	}
+	var (
+		ctxFile string
+		ctxWr   *ContextValuesWriter
+	)
+	{
+		ctxFile = filepath.Join(g.OutDir, "context_values.go")
+		ctxWr, err = NewContextValuesWriter(ctxFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		ctxWr.Close()
+		if err == nil {
+			err = ctxWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Context Values", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+	}
+	for _, v := range values {
+		imports = codegen.AttributeImports(&design.AttributeDefinition{Type: v.Type}, imports, nil)
+	}
+	if err = ctxWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, ctxFile)
+	err = ctxWr.Execute(values)
+	return
+}
+
+// generateFingerprint generates the file holding the design fingerprint.
+func (g *Generator) generateFingerprint() (err error) {
+	var (
+		fpFile string
+		fpWr   *FingerprintWriter
+	)
+	{
+		fpFile = filepath.Join(g.OutDir, "fingerprint.go")
+		fpWr, err = NewFingerprintWriter(fpFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		fpWr.Close()
+		if err == nil {
+			err = fpWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Design Fingerprint", g.API.Context())
+	if err = fpWr.WriteHeader(title, g.Target, nil); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, fpFile)
+	err = fpWr.Execute(g.API)
+	return
+}
+
+// generateValidators generates the standalone validation functions of the JSON action payloads.
+// It does not generate any file if no action accepts such a payload.
+func (g *Generator) generateValidators() (err error) {
+	var payloads []*design.UserTypeDefinition
+	seen := make(map[string]bool)
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil || a.PayloadStreaming || a.PayloadMultipart || seen[a.Payload.TypeName] {
+				return nil
+			}
+			seen[a.Payload.TypeName] = true
+			payloads = append(payloads, a.Payload)
+			return nil
+		})
+	})
+	if len(payloads) == 0 {
+		return nil
+	}
+	var (
+		valFile string
+		valWr   *ValidatorsWriter
+	)
+	{
+		valFile = filepath.Join(g.OutDir, "validators.go")
+		valWr, err = NewValidatorsWriter(valFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		valWr.Close()
+		if err == nil {
+			err = valWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Payload Validators", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("unicode/utf8"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+	}
+	for _, p := range payloads {
+		imports = codegen.AttributeImports(p.AttributeDefinition, imports, nil)
+	}
+	if err = valWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, valFile)
+	for _, p := range payloads {
+		if err = valWr.Execute(p); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -2,6 +2,7 @@ package genapp_test
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -54,7 +55,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates correct empty files", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(6))
+			Ω(files).Should(HaveLen(7))
 			isEmptySource := func(filename string) {
 				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", filename))
 				Ω(err).ShouldNot(HaveOccurred())
@@ -67,6 +68,98 @@ var _ = Describe("Generate", func() {
 			isEmptySource("hrefs.go")
 			isEmptySource("media_types.go")
 		})
+
+		It("generates the design fingerprint", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "fingerprint.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(
+				fmt.Sprintf("const DesignFingerprint = %q", design.Design.Fingerprint())))
+		})
+	})
+
+	Context("with a media type generated in another package", func() {
+		BeforeEach(func() {
+			att := &design.AttributeDefinition{
+				Type: design.Object{"id": &design.AttributeDefinition{Type: design.Integer}},
+			}
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{AttributeDefinition: att, TypeName: "Bottle"},
+				Identifier:         "application/vnd.bottle+json",
+				Views: map[string]*design.ViewDefinition{
+					"default": {AttributeDefinition: att, Name: "default"},
+					"tiny":    {AttributeDefinition: att, Name: "tiny"},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:       "test api",
+				MediaTypes: map[string]*design.MediaTypeDefinition{mt.Identifier: mt},
+			}
+			Ω(design.Design.ImportMediaTypes(map[string]string{
+				"application/vnd.bottle+json": "github.com/goadesign/services/gen/cellar/app",
+			})).Should(Succeed())
+		})
+
+		It("aliases the types of the other package", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(content)
+			Ω(written).Should(ContainSubstring(`cellarapp "github.com/goadesign/services/gen/cellar/app"`))
+			Ω(written).Should(ContainSubstring("type (\n\tBottle     = cellarapp.Bottle\n\tBottleTiny = cellarapp.BottleTiny\n)"))
+			Ω(written).ShouldNot(ContainSubstring("func (mt *Bottle) Validate"))
+		})
+	})
+
+	Context("with a media type nesting restricted attributes", func() {
+		BeforeEach(func() {
+			ownerAtt := &design.AttributeDefinition{
+				Type: design.Object{
+					"name":  &design.AttributeDefinition{Type: design.String},
+					"email": &design.AttributeDefinition{Type: design.String, VisibleTo: []string{"admin"}},
+				},
+			}
+			owner := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{AttributeDefinition: ownerAtt, TypeName: "Owner"},
+				Identifier:         "application/vnd.owner+json",
+				Views: map[string]*design.ViewDefinition{
+					"default": {AttributeDefinition: ownerAtt, Name: "default"},
+				},
+			}
+			bottleAtt := &design.AttributeDefinition{
+				Type: design.Object{
+					"id":     &design.AttributeDefinition{Type: design.Integer},
+					"owner":  &design.AttributeDefinition{Type: owner},
+					"owners": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: owner}}},
+				},
+			}
+			bottle := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{AttributeDefinition: bottleAtt, TypeName: "Bottle"},
+				Identifier:         "application/vnd.bottle+json",
+				Views: map[string]*design.ViewDefinition{
+					"default": {AttributeDefinition: bottleAtt, Name: "default"},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					owner.Identifier:  owner,
+					bottle.Identifier: bottle,
+				},
+			}
+		})
+
+		It("restricts the nested attributes", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(content)
+			Ω(written).Should(ContainSubstring("func (mt *Owner) Restrict(scopes []string) {"))
+			Ω(written).Should(ContainSubstring("if !goa.HasScope(scopes, \"admin\") {\n\t\tmt.Email = nil"))
+			Ω(written).Should(ContainSubstring("func (mt *Bottle) Restrict(scopes []string) {"))
+			Ω(written).Should(ContainSubstring("\tmt.Owner.Restrict(scopes)\n"))
+			Ω(written).Should(ContainSubstring("for _, e := range mt.Owners {\n\t\te.Restrict(scopes)\n\t}"))
+		})
 	})
 
 	Context("with a simple API", func() {
@@ -190,7 +283,7 @@ var _ = Describe("Generate", func() {
 
 			It("generates the corresponding code", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(8))
+				Ω(files).Should(HaveLen(9))
 
 				isSource("contexts.go", contextsCode)
 				isSource("controllers.go", controllersCode)
@@ -244,6 +337,102 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with an object payload", func() {
+			BeforeEach(func() {
+				payload = &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"name":  &design.AttributeDefinition{Type: design.String},
+							"count": &design.AttributeDefinition{Type: design.Integer, DefaultValue: 1},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+					},
+					TypeName: "GetWidgetPayload",
+				}
+				design.Design.Resources["Widget"].Actions["get"].Payload = payload
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("generates the standalone payload validation function", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(HaveLen(10))
+
+				validatorsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "validators.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(validatorsContent)).Should(ContainSubstring(validatorsCode))
+			})
+		})
+
+		Context("with an object payload with a required integer attribute", func() {
+			BeforeEach(func() {
+				payload = &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"count": &design.AttributeDefinition{Type: design.Integer},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"count"}},
+					},
+					TypeName: "GetWidgetPayload",
+				}
+				design.Design.Resources["Widget"].Actions["get"].Payload = payload
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("validates the decoded payload so that an empty object is rejected", func() {
+				Ω(genErr).Should(BeNil())
+
+				validatorsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "validators.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(validatorsContent)).Should(ContainSubstring(validatorsRequiredIntegerCode))
+
+				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contextsContent)).Should(ContainSubstring(privateRequiredIntegerValidateCode))
+			})
+		})
+
+		Context("with API versions", func() {
+			BeforeEach(func() {
+				design.Design.Versions = map[string]*design.VersionDefinition{
+					"v1": {Name: "v1", Scheme: design.VersionHeader, Key: "X-Api-Version", Parent: design.Design},
+					"v2": {Name: "v2", Scheme: design.VersionPath, Key: "/v2", Parent: design.Design},
+					"v3": {Name: "v3", Scheme: design.VersionPath, Key: "/v3", Parent: design.Design},
+				}
+				design.Design.Resources["Widget"].Versions = []string{"v2"}
+			})
+
+			It("mounts the routes of the versions exposing the resource", func() {
+				Ω(genErr).Should(BeNil())
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(content)
+				Ω(written).Should(ContainSubstring(`h = goa.RejectVersions(h, goa.VersionSelector{Name: "v1", Header: "X-Api-Version"})`))
+				Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, nil))`))
+				Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/v2/:id", ctrl.MuxHandler("get", h, nil))`))
+				Ω(written).ShouldNot(ContainSubstring(`"/v3/:id"`))
+			})
+		})
+
+		Context("with context values", func() {
+			BeforeEach(func() {
+				design.Design.ContextValues = map[string]*design.ContextValueDefinition{
+					"tenant": {Name: "tenant", Type: design.String, Description: "The request tenant"},
+					"user":   {Name: "user", Type: design.Design.MediaTypes["application/vnd.rightscale.codegen.test.widgets"]},
+				}
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("generates the context value accessors", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(HaveLen(10))
+
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "context_values.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(contextValuesCode))
+			})
+		})
+
 	})
 })
 
@@ -374,12 +563,12 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "Widget", "get")
 		}
 		// Build the context
 		rctx, err := NewGetWidgetContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "Widget", "get")
 		}
 		return ctrl.Get(rctx)
 	}
@@ -434,18 +623,22 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "Widget", "get")
+		}
+		// Validate the payload now that the caller is authenticated
+		if err := service.ValidateRequestPayload(ctx, req); err != nil {
+			return goa.WithDesignLocation(goa.ErrBadRequest(err), "Widget", "get")
 		}
 		// Build the context
 		rctx, err := NewGetWidgetContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "Widget", "get")
 		}
 		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.(Collection)
 		} else {
-			return goa.MissingPayloadError()
+			return goa.WithDesignLocation(goa.MissingPayloadError(), "Widget", "get")
 		}
 		return ctrl.Get(rctx)
 	}
@@ -473,12 +666,16 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "Widget", "get")
+		}
+		// Validate the payload now that the caller is authenticated
+		if err := service.ValidateRequestPayload(ctx, req); err != nil {
+			return goa.WithDesignLocation(goa.ErrBadRequest(err), "Widget", "get")
 		}
 		// Build the context
 		rctx, err := NewGetWidgetContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "Widget", "get")
 		}
 		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
@@ -500,3 +697,58 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 	return nil
 }
 `
+
+const contextValuesCode = `// contextValueKey is the type of the keys used to store the context values.
+type contextValueKey int
+
+const (
+	// ctxTenantKey is the key used to store the tenant context value.
+	ctxTenantKey contextValueKey = iota + 1
+	// ctxUserKey is the key used to store the user context value.
+	ctxUserKey
+)
+
+// WithTenant returns a copy of ctx that holds the tenant context value: The request tenant.
+func WithTenant(ctx context.Context, v string) context.Context {
+	return context.WithValue(ctx, ctxTenantKey, v)
+}
+
+// ContextTenant returns the tenant context value stored in ctx with WithTenant, ok is
+// false if ctx does not hold the value.
+func ContextTenant(ctx context.Context) (v string, ok bool) {
+	v, ok = ctx.Value(ctxTenantKey).(string)
+	return
+}
+
+// WithUser returns a copy of ctx that holds the user context value.
+func WithUser(ctx context.Context, v ID) context.Context {
+	return context.WithValue(ctx, ctxUserKey, v)
+}
+`
+
+const validatorsCode = `func ValidateGetWidgetPayload(b []byte) error {
+	payload := &getWidgetPayload{}
+	if err := json.Unmarshal(b, payload); err != nil {
+		return goa.ErrInvalidEncoding(err)
+	}
+	payload.Finalize()
+	return payload.Validate()
+}
+`
+
+const validatorsRequiredIntegerCode = `func ValidateGetWidgetPayload(b []byte) error {
+	payload := &getWidgetPayload{}
+	if err := json.Unmarshal(b, payload); err != nil {
+		return goa.ErrInvalidEncoding(err)
+	}
+	return payload.Validate()
+}
+`
+
+const privateRequiredIntegerValidateCode = `func (payload *getWidgetPayload) Validate() (err error) {
+	if payload.Count == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`raw`" + `, "count"))
+	}
+	return
+}
+`
@@ -43,6 +43,7 @@ type TestMethod struct {
 	Params            []*ObjectType
 	QueryParams       []*ObjectType
 	Headers           []*ObjectType
+	Cookies           []*ObjectType
 	Payload           *ObjectType
 	reservedNames     map[string]bool
 }
@@ -63,6 +64,7 @@ type ObjectType struct {
 	Type        string
 	Pointer     string
 	Validatable bool
+	DeepObject  bool
 }
 
 func (g *Generator) generateResourceTest() error {
@@ -126,7 +128,7 @@ func (g *Generator) generateResourceTest() error {
 				}
 				for routeIndex, route := range action.Routes {
 					mediaType := design.Design.MediaTypeWithIdentifier(response.MediaType)
-					if mediaType == nil {
+					if mediaType == nil || response.Streaming || response.Chunked { // Streams are not decoded
 						methods = append(methods, g.createTestMethod(res, action, response, route, routeIndex, nil, nil))
 					} else {
 						if err := mediaType.IterateViews(func(view *design.ViewDefinition) error {
@@ -162,6 +164,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		path                                         []*ObjectType
 		query                                        []*ObjectType
 		header                                       []*ObjectType
+		cookie                                       []*ObjectType
 		returnType                                   *ObjectType
 		payload                                      *ObjectType
 	)
@@ -206,9 +209,17 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 
 	path = pathParams(action, route)
 	query = queryParams(action)
+	for _, q := range query {
+		if q.DeepObject && !strings.Contains(q.Type, ".") {
+			q.Type = fmt.Sprintf("*%s.%s", g.Target, strings.TrimPrefix(q.Type, "*"))
+		}
+	}
 	header = headers(action, resource.Headers)
+	cookie = cookies(action)
 
-	if action.Payload != nil {
+	if action.Payload != nil && action.PayloadStreaming {
+		payload = &ObjectType{Name: "payload", Type: "io.Reader"}
+	} else if action.Payload != nil {
 		payload = &ObjectType{}
 		payload.Name = "payload"
 		payload.Type = fmt.Sprintf("%s.%s", g.Target, codegen.Goify(action.Payload.TypeName, true))
@@ -230,6 +241,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		Params:            path,
 		QueryParams:       query,
 		Headers:           header,
+		Cookies:           cookie,
 		Payload:           payload,
 		ReturnType:        returnType,
 		ReturnsErrorMedia: mediaType == design.ErrorMedia,
@@ -239,7 +251,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		RouteVerb:         route.Verb,
 		Status:            response.Status,
 		FullPath:          goPathFormat(route.FullPath()),
-		reservedNames:     reservedNames(path, query, header, payload, returnType),
+		reservedNames:     reservedNames(path, query, header, cookie, payload, returnType),
 	}
 }
 
@@ -279,6 +291,24 @@ func headers(action *design.ActionDefinition, headers *design.AttributeDefinitio
 	return objs
 }
 
+// cookies builds the template data structure needed to render the code that sets the cookies of
+// the given action.
+func cookies(action *design.ActionDefinition) []*ObjectType {
+	if action.Cookies == nil {
+		return nil
+	}
+	var names []string
+	for name := range action.Cookies.Type.ToObject() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	objs := make([]*ObjectType, len(names))
+	for i, name := range names {
+		objs[i] = attToObject(name, action.Cookies, action.Cookies.Type.ToObject()[name])
+	}
+	return objs
+}
+
 // queryParams returns the query string params for the given action.
 func queryParams(action *design.ActionDefinition) []*ObjectType {
 	var qparams []string
@@ -299,7 +329,7 @@ func paramFromNames(action *design.ActionDefinition, names []string) (params []*
 	return
 }
 
-func reservedNames(params, queryParams, headers []*ObjectType, payload, returnType *ObjectType) map[string]bool {
+func reservedNames(params, queryParams, headers, cookies []*ObjectType, payload, returnType *ObjectType) map[string]bool {
 	var names = make(map[string]bool)
 	for _, param := range params {
 		names[param.Name] = true
@@ -310,6 +340,9 @@ func reservedNames(params, queryParams, headers []*ObjectType, payload, returnTy
 	for _, header := range headers {
 		names[header.Name] = true
 	}
+	for _, cookie := range cookies {
+		names[cookie.Name] = true
+	}
 	if payload != nil {
 		names[payload.Name] = true
 	}
@@ -324,6 +357,7 @@ func attToObject(name string, parent, att *design.AttributeDefinition) *ObjectTy
 	obj.Label = name
 	obj.Name = codegen.Goify(name, false)
 	obj.Type = codegen.GoTypeRef(att.Type, nil, 0, false)
+	obj.DeepObject = att.IsDeepObject()
 	if att.Type.IsPrimitive() && parent.IsPrimitivePointer(name) {
 		obj.Pointer = "*"
 	}
@@ -364,6 +398,7 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 */}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
+*/}}{{ range $cookie := $test.Cookies }}, {{ $cookie.Name }} {{ $cookie.Pointer }}{{ $cookie.Type }}{{ end }}{{/*
 */}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}){{/*
 */}} (http.ResponseWriter{{ if $test.ReturnType }}, {{ $test.ReturnType.Pointer }}{{ $test.ReturnType.Type }}{{ end }}) {
 	// Setup service
@@ -397,11 +432,14 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	// Setup request context
 	{{ $rw := $test.Escape "rw" }}{{ $rw }} := httptest.NewRecorder()
 {{ $query := $test.Escape "query" }}{{ if $test.QueryParams}}	{{ $query }} := url.Values{}
-{{ range $param := $test.QueryParams }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
+{{ range $param := $test.QueryParams }}{{ if $param.DeepObject }}	for k, v := range goa.EncodeDeepObject({{ printf "%q" $param.Label }}, {{ $param.Name }}) {
+		{{ $query }}[k] = v
+	}
+{{ else }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
 {{ template "convertParam" $param }}
 		{{ $query }}[{{ printf "%q" $param.Label }}] = sliceVal
 	}
-{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
+{{ end }}{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
 		Path: fmt.Sprintf({{ printf "%q" $test.FullPath }}{{ range $param := $test.Params }}, {{ $param.Name }}{{ end }}),
 {{ if $test.QueryParams }}		RawQuery: {{ $query }}.Encode(),
 {{ end }}	}
@@ -413,13 +451,20 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 {{ template "convertParam" $header }}
 		{{ $req }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
 	}
+{{ end }}{{ range $cookie := $test.Cookies }}{{ if $cookie.Pointer }}	if {{ $cookie.Name }} != nil {{ end }}{
+{{ template "convertParam" $cookie }}
+		{{ $req }}.AddCookie(&http.Cookie{Name: {{ printf "%q" $cookie.Label }}, Value: sliceVal[0]})
+	}
 {{ end }} {{ $prms := $test.Escape "prms" }}{{ $prms }} := url.Values{}
 {{ range $param := $test.Params }}	{{ $prms }}["{{ $param.Label }}"] = []string{fmt.Sprintf("%v",{{ $param.Name}})}
-{{ end }}{{ range $param := $test.QueryParams }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
+{{ end }}{{ range $param := $test.QueryParams }}{{ if $param.DeepObject }}	for k, v := range goa.EncodeDeepObject({{ printf "%q" $param.Label }}, {{ $param.Name }}) {
+		{{ $prms }}[k] = v
+	}
+{{ else }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
 {{ template "convertParam" $param }}
 		{{ $prms }}[{{ printf "%q" $param.Label }}] = sliceVal
 	}
-{{ end }}	if ctx == nil {
+{{ end }}{{ end }}	if ctx == nil {
 		ctx = context.Background()
 	}
 	{{ $goaCtx := $test.Escape "goaCtx" }}{{ $goaCtx }} := goa.NewContext(goa.WithAction(ctx, "{{ $test.ResourceName }}Test"), {{ $rw }}, {{ $req }}, {{ $prms }})
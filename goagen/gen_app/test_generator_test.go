@@ -122,6 +122,13 @@ var _ = Describe("Generate", func() {
 									},
 									Validation: &dslengine.ValidationDefinition{Required: []string{"requiredHeader", "requiredResourceHeader"}},
 								},
+								Cookies: &design.AttributeDefinition{
+									Type: design.Object{
+										"session": &design.AttributeDefinition{Type: design.String},
+										"visits":  &design.AttributeDefinition{Type: design.Integer},
+									},
+									Validation: &dslengine.ValidationDefinition{Required: []string{"session"}},
+								},
 								QueryParams: &design.AttributeDefinition{
 									Type: design.Object{
 										"optional": &design.AttributeDefinition{Type: design.Integer},
@@ -185,7 +192,7 @@ var _ = Describe("Generate", func() {
 
 		It("does not call Validate on the resulting media type when it does not exist", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 
@@ -194,7 +201,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the ActionRouteResponse test methods ", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(10))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 
@@ -236,6 +243,16 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring(`req.Header["Requiredresourceheader"] = sliceVal`))
 		})
 
+		It("properly handles cookies", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring(`requiredResourceHeader string, session string, visits *int)`))
+			Ω(content).Should(ContainSubstring(`if visits != nil`))
+			Ω(content).ShouldNot(ContainSubstring(`if session != nil`))
+			Ω(content).Should(ContainSubstring(`req.AddCookie(&http.Cookie{Name: "session", Value: sliceVal[0]})`))
+		})
+
 		It("generates calls to new Context ", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"sort"
 
@@ -62,6 +64,26 @@ type (
 		Validator     *codegen.Validator
 	}
 
+	// ValidatorsWriter generate the standalone payload validation functions.
+	// The functions make it possible to reuse the design validations outside of goa handlers.
+	ValidatorsWriter struct {
+		*codegen.SourceFile
+		Finalizer *codegen.Finalizer
+		Validator *codegen.Validator
+	}
+
+	// ContextValuesWriter generate the functions that store and retrieve the request-scoped
+	// values declared with ContextValue.
+	ContextValuesWriter struct {
+		*codegen.SourceFile
+	}
+
+	// FingerprintWriter generate the constant that holds the fingerprint of the design the
+	// package was generated from.
+	FingerprintWriter struct {
+		*codegen.SourceFile
+	}
+
 	// UserTypesWriter generate code for a goa application user types.
 	// User types are data structures defined in the DSL with "Type".
 	UserTypesWriter struct {
@@ -74,17 +96,27 @@ type (
 	// ContextTemplateData contains all the information used by the template to render the context
 	// code for an action.
 	ContextTemplateData struct {
-		Name         string // e.g. "ListBottleContext"
-		ResourceName string // e.g. "bottles"
-		ActionName   string // e.g. "list"
-		Params       *design.AttributeDefinition
-		Payload      *design.UserTypeDefinition
-		Headers      *design.AttributeDefinition
-		Routes       []*design.RouteDefinition
-		Responses    map[string]*design.ResponseDefinition
-		API          *design.APIDefinition
-		DefaultPkg   string
-		Security     *design.SecurityDefinition
+		Name             string // e.g. "ListBottleContext"
+		ResourceName     string // e.g. "bottles"
+		ActionName       string // e.g. "list"
+		Params           *design.AttributeDefinition
+		Payload          *design.UserTypeDefinition
+		Headers          *design.AttributeDefinition
+		Cookies          *design.AttributeDefinition
+		Routes           []*design.RouteDefinition
+		Responses        map[string]*design.ResponseDefinition
+		API              *design.APIDefinition
+		DefaultPkg       string
+		Security         *design.SecurityDefinition
+		AcceptRanges     bool
+		Resumable        bool
+		VersionAttribute string
+		Pagination       *design.PaginationDefinition
+		RenderHTML       bool
+		Embeddables      []string
+		ParentKey        *design.AttributeDefinition
+		Messages         *design.WebSocketDefinition
+		PayloadStreaming bool
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
@@ -97,6 +129,10 @@ type (
 		Decoders       []*EncoderTemplateData         // Decoder data
 		Origins        []*design.CORSDefinition       // CORS policies
 		PreflightPaths []string
+		ParentKey      *design.AttributeDefinition // Path params identifying the parent resources
+		Patterns       []string                    // Validation patterns compiled at mount time
+		PathVersions   []*design.VersionDefinition // Versions selected with a path prefix that expose the resource
+		HiddenVersions []*design.VersionDefinition // Versions selected with a header or a query string parameter that don't expose the resource
 	}
 
 	// ResourceData contains the information required to generate the resource GoGenerator
@@ -215,6 +251,15 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		"printVal":           codegen.PrintVal,
 		"canonicalHeaderKey": http.CanonicalHeaderKey,
 		"isPathParam":        data.IsPathParam,
+		"hasComputed":        hasComputed,
+		"hasComputedElem":    hasComputedElem,
+		"hasRestricted":      hasRestricted,
+		"hasRestrictedElem":  hasRestrictedElem,
+		"hasEmbeddable":      hasEmbeddable,
+		"hasEmbeddableElem":  hasEmbeddableElem,
+		"finalizeCode":       w.Finalizer.Code,
+		"validationCode":     w.Validator.Code,
+		"isBuiltinType":      codegen.IsBuiltinType,
 	}
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
@@ -235,6 +280,35 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
+			tdata := map[string]interface{}{"Type": data.Payload, "Receiver": "payload"}
+			if err := w.ExecuteTemplate("transitions", transitionsT, nil, tdata); err != nil {
+				return err
+			}
+		}
+	}
+	if data.AcceptRanges {
+		if err := w.ExecuteTemplate("serveContent", ctxServeContentT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.VersionAttribute != "" {
+		if err := w.ExecuteTemplate("checkVersion", ctxCheckVersionT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.Pagination != nil {
+		if err := w.ExecuteTemplate("paginate", ctxPaginateT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.ParentKey != nil {
+		if err := w.ExecuteTemplate("parentKey", ctxParentKeyT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.Messages != nil {
+		if err := w.executeWebSocket(data); err != nil {
+			return err
 		}
 	}
 	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
@@ -242,6 +316,12 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			"Context":  data,
 			"Response": resp,
 		}
+		if resp.Filename != "" {
+			respData["Filename"] = filenameCode(resp, data.Params)
+		}
+		if resp.Location != "" {
+			respData["Location"] = locationCode(resp, data.Params)
+		}
 		var mt *design.MediaTypeDefinition
 		if resp.Type != nil {
 			var ok bool
@@ -273,6 +353,7 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				}
 				respData["Projected"] = projected
 				respData["ViewName"] = view
+				respData["HeaderTemplates"] = headerTemplatesCode(resp, projected)
 				respData["MediaType"] = mt
 				respData["ContentType"] = mt.ContentType
 				if view == "default" {
@@ -281,7 +362,14 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
 					respData["RespName"] = codegen.Goify(base, true)
 				}
-				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
+				tmpl := ctxMTRespT
+				if resp.Streaming {
+					tmpl = ctxStreamRespT
+					respData["StreamName"] = strings.TrimSuffix(data.Name, "Context") + respData["RespName"].(string) + "Stream"
+				} else if resp.Chunked {
+					tmpl = ctxChunkedRespT
+				}
+				if err := w.ExecuteTemplate("response", tmpl, fn, respData); err != nil {
 					return err
 				}
 			}
@@ -291,6 +379,32 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 	})
 }
 
+// executeWebSocket generates the inbound message type if it is not a design type and the context
+// methods that receive and send websocket messages.
+func (w *ContextsWriter) executeWebSocket(data *ContextTemplateData) error {
+	fn := template.FuncMap{
+		"finalizeCode":   w.Finalizer.Code,
+		"validationCode": w.Validator.Code,
+	}
+	msgData := map[string]interface{}{"Context": data}
+	if in := data.Messages.Inbound; in != nil {
+		msgData["Inbound"] = in
+		if _, ok := design.Design.Types[in.TypeName]; !ok {
+			if err := w.ExecuteTemplate("inbound", inboundMessageT, fn, msgData); err != nil {
+				return err
+			}
+		}
+	}
+	if mt := data.Messages.OutboundMediaType(); mt != nil {
+		projected, _, err := mt.Project(data.Messages.OutboundView)
+		if err != nil {
+			return err
+		}
+		msgData["Outbound"] = projected
+	}
+	return w.ExecuteTemplate("webSocket", ctxWebSocketT, fn, msgData)
+}
+
 // NewControllersWriter returns a handlers code writer.
 // Handlers provide the glue between the underlying request data and the user controller.
 func NewControllersWriter(filename string) (*ControllersWriter, error) {
@@ -315,6 +429,150 @@ func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTempla
 	return w.ExecuteTemplate("service", serviceT, nil, ctx)
 }
 
+// WriteActionMiddleware writes the functions used to mount and run the middleware required by
+// actions flagged in the design, e.g. with ReplayProtected or SignedURL.
+func (w *ControllersWriter) WriteActionMiddleware(api *design.APIDefinition) error {
+	replay, signed, costly, redirect, gone := false, false, false, false, false
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			replay = replay || a.ReplayProtected
+			signed = signed || a.SignedURL
+			costly = costly || embeddables(api, a) != nil
+			for _, ro := range a.Routes {
+				for _, al := range ro.Aliases {
+					redirect = redirect || al.Policy == design.AliasRedirect
+					gone = gone || al.Policy == design.AliasGone
+				}
+			}
+			return nil
+		})
+	})
+	if redirect {
+		if err := w.ExecuteTemplate("aliasRedirect", aliasRedirectT, nil, nil); err != nil {
+			return err
+		}
+	}
+	if gone {
+		if err := w.ExecuteTemplate("aliasGone", aliasGoneT, nil, nil); err != nil {
+			return err
+		}
+	}
+	if costly {
+		if err := w.ExecuteTemplate("queryCost", queryCostT, nil, nil); err != nil {
+			return err
+		}
+	}
+	if replay {
+		if err := w.ExecuteTemplate("replay", replayProtectionT, nil, nil); err != nil {
+			return err
+		}
+	}
+	if signed {
+		return w.ExecuteTemplate("signedURL", signedURLT, nil, nil)
+	}
+	return nil
+}
+
+// WriteSagas writes the step structs and constructors of the sagas declared by the API actions.
+func (w *ControllersWriter) WriteSagas(api *design.APIDefinition) error {
+	return api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Saga == nil {
+				return nil
+			}
+			data := map[string]interface{}{
+				"Name":     codegen.Goify(a.Name, true) + codegen.Goify(r.Name, true),
+				"Action":   a.Name,
+				"Resource": r.Name,
+				"Saga":     a.Saga,
+			}
+			return w.ExecuteTemplate("saga", sagaT, nil, data)
+		})
+	})
+}
+
+// WriteAggregates writes the branch structs and constructors of the aggregates declared by the
+// API actions.
+func (w *ControllersWriter) WriteAggregates(api *design.APIDefinition) error {
+	funcs := template.FuncMap{"durationCode": durationCode}
+	return api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Aggregate == nil {
+				return nil
+			}
+			data := map[string]interface{}{
+				"Name":      codegen.Goify(a.Name, true) + codegen.Goify(r.Name, true),
+				"Action":    a.Name,
+				"Resource":  r.Name,
+				"Aggregate": a.Aggregate,
+			}
+			return w.ExecuteTemplate("aggregate", aggregateT, funcs, data)
+		})
+	})
+}
+
+// WriteEnrichments writes the lookup functions struct, the mount and lookup functions and the
+// action context accessors of the values derived from the action params declared with Enrich.
+func (w *ControllersWriter) WriteEnrichments(api *design.APIDefinition) error {
+	funcs := template.FuncMap{"durationCode": durationCode}
+	return api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if len(a.Enrichments) == 0 {
+				return nil
+			}
+			params := a.AllParams()
+			enrichments := make([]map[string]interface{}, len(a.Enrichments))
+			for i, e := range a.Enrichments {
+				att := params.Type.ToObject()[e.Param]
+				pointer := params.IsPrimitivePointer(e.Param)
+				typ := codegen.GoTypeRef(att.Type, nil, 0, false)
+				if pointer {
+					typ = "*" + typ
+				}
+				enrichments[i] = map[string]interface{}{
+					"Name":        e.Name,
+					"Field":       codegen.Goify(e.Name, true),
+					"Description": e.Description,
+					"TTL":         e.TTL,
+					"Param":       e.Param,
+					"ParamField":  codegen.GoifyAtt(att, e.Param, true),
+					"ParamVar":    codegen.Goify(e.Param, false),
+					"ParamType":   typ,
+					"Pointer":     pointer,
+				}
+			}
+			name := codegen.Goify(a.Name, true) + codegen.Goify(r.Name, true)
+			data := map[string]interface{}{
+				"Name":        name,
+				"Private":     codegen.Goify(a.Name, false) + codegen.Goify(r.Name, true),
+				"Context":     name + "Context",
+				"Action":      a.Name,
+				"Resource":    r.Name,
+				"Enrichments": enrichments,
+			}
+			return w.ExecuteTemplate("enrichment", enrichmentT, funcs, data)
+		})
+	})
+}
+
+// durationCode returns the Go code for the given duration.
+func durationCode(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d * time.Hour", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%d * time.Minute", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%d * time.Second", d/time.Second)
+	case d%time.Millisecond == 0:
+		return fmt.Sprintf("%d * time.Millisecond", d/time.Millisecond)
+	default:
+		return fmt.Sprintf("time.Duration(%d)", d)
+	}
+}
+
 // Execute writes the handlers GoGenerator
 func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 	if len(data) == 0 {
@@ -324,7 +582,7 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 		if err := w.ExecuteTemplate("controller", ctrlT, nil, d); err != nil {
 			return err
 		}
-		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
+		if err := w.ExecuteTemplate("mount", mountT, template.FuncMap{"durationCode": durationCode}, d); err != nil {
 			return err
 		}
 		if len(d.Origins) > 0 {
@@ -332,6 +590,11 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 				return err
 			}
 		}
+		if d.ParentKey != nil {
+			if err := w.ExecuteTemplate("parentResolver", parentResolverT, nil, d); err != nil {
+				return err
+			}
+		}
 		fn := template.FuncMap{
 			"finalizeCode":   w.Finalizer.Code,
 			"validationCode": w.Validator.Code,
@@ -389,6 +652,22 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 		mLinks *design.UserTypeDefinition
 		fn     = template.FuncMap{"validationCode": w.Validator.Code}
 	)
+	if pkg, ok := mt.Metadata["struct:pkg:path"]; ok && len(pkg) > 0 {
+		return w.executeImported(mt, pkg[0])
+	}
+	rfn := template.FuncMap{"newComputeData": newComputeData}
+	if err := w.ExecuteTemplate("computedRegistration", computedRegistrationT, rfn, mt); err != nil {
+		return err
+	}
+	cfn := template.FuncMap{
+		"hasComputed":      hasComputed,
+		"computable":       computable,
+		"hasRestricted":    hasRestricted,
+		"restrictable":     restrictable,
+		"restrictableElem": restrictableElem,
+		"hasEmbeddable":    hasEmbeddable,
+		"hasGeometryElem":  hasGeometryElem,
+	}
 	err := mt.IterateViews(func(view *design.ViewDefinition) error {
 		p, links, err := mt.Project(view.Name)
 		if mLinks == nil {
@@ -397,7 +676,20 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 		if err != nil {
 			return err
 		}
-		return w.ExecuteTemplate("mediatype", mediaTypeT, fn, p)
+		if err := w.ExecuteTemplate("mediatype", mediaTypeT, fn, p); err != nil {
+			return err
+		}
+		data := map[string]interface{}{"Projected": p, "MediaType": mt}
+		if err := w.ExecuteTemplate("compute", computeT, cfn, data); err != nil {
+			return err
+		}
+		if err := w.ExecuteTemplate("restrict", restrictT, cfn, data); err != nil {
+			return err
+		}
+		if err := w.ExecuteTemplate("geojson", geoJSONT, cfn, data); err != nil {
+			return err
+		}
+		return w.ExecuteTemplate("embed", embedT, cfn, data)
 	})
 	if err != nil {
 		return err
@@ -410,6 +702,34 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 	return nil
 }
 
+// executeImported writes the aliases of the types generated for the given media type in
+// another package.
+func (w *MediaTypesWriter) executeImported(mt *design.MediaTypeDefinition, pkg string) error {
+	var (
+		names  []string
+		mLinks *design.UserTypeDefinition
+	)
+	err := mt.IterateViews(func(view *design.ViewDefinition) error {
+		p, links, err := mt.Project(view.Name)
+		if err != nil {
+			return err
+		}
+		if mLinks == nil {
+			mLinks = links
+		}
+		names = append(names, codegen.GoTypeName(p, nil, 0, false))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if mLinks != nil {
+		names = append(names, codegen.GoTypeName(mLinks, nil, 0, false))
+	}
+	data := map[string]interface{}{"MediaType": mt, "Names": names, "Package": codegen.ImportAlias(pkg)}
+	return w.ExecuteTemplate("importedmediatype", importedMediaTypeT, nil, data)
+}
+
 // NewUserTypesWriter returns a contexts code writer.
 // User types contain custom data structured defined in the DSL with "Type".
 func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
@@ -424,117 +744,740 @@ func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
 	}, nil
 }
 
-// Execute writes the code for the context types to the writer.
-func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
+// NewValidatorsWriter returns a payload validators code writer.
+func NewValidatorsWriter(filename string) (*ValidatorsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidatorsWriter{
+		SourceFile: file,
+		Finalizer:  codegen.NewFinalizer(),
+		Validator:  codegen.NewValidator(),
+	}, nil
+}
+
+// Execute writes the code for the validation function of the given payload type to the writer.
+func (w *ValidatorsWriter) Execute(payload *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
 	}
-	return w.ExecuteTemplate("types", userTypeT, fn, t)
+	return w.ExecuteTemplate("validator", validatorT, fn, payload)
 }
 
-// newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
-func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, pkg string, depth int) map[string]interface{} {
-	return map[string]interface{}{
-		"Name":      name,
-		"VarName":   codegen.Goify(name, false),
-		"Pointer":   pointer,
-		"Attribute": att,
-		"Pkg":       pkg,
-		"Depth":     depth,
+// NewContextValuesWriter returns a context values code writer.
+func NewContextValuesWriter(filename string) (*ContextValuesWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
 	}
+	return &ContextValuesWriter{SourceFile: file}, nil
 }
 
-// arrayAttribute returns the array element attribute definition.
-func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
-	return a.Type.(*design.Array).ElemType
+// Execute writes the context key type and the typed accessors of the given context values to the
+// writer.
+func (w *ContextValuesWriter) Execute(values []*design.ContextValueDefinition) error {
+	data := make([]map[string]interface{}, len(values))
+	for i, c := range values {
+		data[i] = map[string]interface{}{
+			"Name":        c.Name,
+			"Field":       codegen.Goify(c.Name, true),
+			"Type":        codegen.GoTypeRef(c.Type, nil, 0, false),
+			"Description": c.Description,
+		}
+	}
+	return w.ExecuteTemplate("contextValues", contextValuesT, nil, data)
 }
 
-const (
-	// ctxT generates the code for the context data type.
-	// template input: *ContextTemplateData
-	ctxT = `// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action context.
-type {{ .Name }} struct {
-	context.Context
-	*goa.ResponseData
-	*goa.RequestData
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
-{{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
-{{ end }}{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
-{{ end }}}
-`
-	// coerceT generates the code that coerces the generic deserialized
-	// data to the actual type.
-	// template input: map[string]interface{} as returned by newCoerceData
-	coerceT = `{{ if eq .Attribute.Type.Kind 1 }}{{/*
-
-*/}}{{/* BooleanType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseBool(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
-{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
-{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "boolean"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 2 }}{{/*
+// NewFingerprintWriter returns a design fingerprint code writer.
+func NewFingerprintWriter(filename string) (*FingerprintWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &FingerprintWriter{SourceFile: file}, nil
+}
 
-*/}}{{/* IntegerType */}}{{/*
-*/}}{{ $tmp := tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
-{{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
-{{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
-{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
-{{ end }}{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 3 }}{{/*
+// Execute writes the DesignFingerprint constant holding the fingerprint of the given API design.
+func (w *FingerprintWriter) Execute(api *design.APIDefinition) error {
+	return w.ExecuteTemplate("fingerprint", fingerprintT, nil, api.Fingerprint())
+}
 
-*/}}{{/* NumberType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 64); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
-{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
-{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 4 }}{{/*
+// Execute writes the code for the context types to the writer.
+func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
+	if t.Enum {
+		return w.ExecuteTemplate("enum", enumTypeT, template.FuncMap{"enumConstants": enumConstants}, t)
+	}
+	if t.Flags != nil {
+		data := map[string]interface{}{
+			"Type":      t,
+			"Constants": flagConstants(t),
+			"Array":     design.Design.FlagsWireFormat() == design.FlagsArray,
+		}
+		return w.ExecuteTemplate("flags", flagsTypeT, nil, data)
+	}
+	if t.OneOf != nil {
+		data := map[string]interface{}{
+			"Type":    t,
+			"Members": w.oneOfMembers(t),
+		}
+		return w.ExecuteTemplate("oneof", oneOfTypeT, nil, data)
+	}
+	fn := template.FuncMap{
+		"finalizeCode":   w.Finalizer.Code,
+		"validationCode": w.Validator.Code,
+	}
+	if err := w.ExecuteTemplate("types", userTypeT, fn, t); err != nil {
+		return err
+	}
+	return w.ExecuteTemplate("transitions", transitionsT, nil, map[string]interface{}{"Type": t, "Receiver": "ut"})
+}
 
-*/}}{{/* StringType */}}{{/*
-*/}}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
-{{ end }}{{ if eq .Attribute.Type.Kind 5 }}{{/*
+// enumConstant describes the Go constant generated for a value of an enum type.
+type enumConstant struct {
+	// Name is the name of the constant.
+	Name string
+	// Value is the Go code of the constant value.
+	Value string
+}
 
-*/}}{{/* DateTimeType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := time.Parse(time.RFC3339, raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
-{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
-{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "datetime"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 6 }}{{/*
+// enumConstants returns the Go constants generated for the values of the given enum type. The
+// name of a constant is the name of the type followed by the value, e.g. "OrderStatusOpen".
+func enumConstants(t *design.UserTypeDefinition) []enumConstant {
+	var (
+		typeName = codegen.GoTypeName(t, nil, 0, false)
+		consts   = make([]enumConstant, len(t.Validation.Values))
+		seen     = make(map[string]bool)
+	)
+	for i, v := range t.Validation.Values {
+		var suffix string
+		if t.Type == design.String {
+			suffix = codegen.Goify(v.(string), true)
+		} else {
+			suffix = strings.NewReplacer("-", "Minus", ".", "Point").Replace(fmt.Sprint(v))
+		}
+		name := typeName + suffix
+		if suffix == "" || seen[name] {
+			name = fmt.Sprintf("%sValue%d", typeName, i)
+		}
+		seen[name] = true
+		consts[i] = enumConstant{Name: name, Value: codegen.PrintVal(t.Type, v)}
+	}
+	return consts
+}
 
-*/}}{{/* UUIDType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := uuid.FromString(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
-{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
-{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "uuid"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 7 }}{{/*
+// oneOfMember describes a member of a OneOf type.
+type oneOfMember struct {
+	// Field is the name of the struct field that holds the member values.
+	Field string
+	// Type is the member type.
+	Type *design.UserTypeDefinition
+	// Values lists the values of the discriminator that identify the member.
+	Values []interface{}
+	// Finalize is true if the private member type has a Finalize method.
+	Finalize bool
+	// ValidatePrivate is true if the private member type has a Validate method.
+	ValidatePrivate bool
+	// ValidatePublic is true if the public member type has a Validate method.
+	ValidatePublic bool
+}
 
-*/}}{{/* AnyType */}}{{/*
-*/}}{{ if .Pointer }}{{ $tmp := tempvar }}{{ tabs .Depth }}{{ $tmp }} := interface{}(raw{{ goify .Name true }})
-{{ tabs .Depth }}{{ .Pkg }} = &{{ $tmp }}
-{{ else }}{{ tabs .Depth }}{{ .Pkg }} = raw{{ goify .Name true }}
-{{ end }}{{ end }}`
+// oneOfMembers returns the members of the given OneOf type, the methods of the member types are
+// computed the same way the user type template does.
+func (w *UserTypesWriter) oneOfMembers(t *design.UserTypeDefinition) []*oneOfMember {
+	members := make([]*oneOfMember, len(t.OneOf))
+	for i, m := range t.OneOf {
+		members[i] = &oneOfMember{
+			Field:           codegen.Goify(m.TypeName, true),
+			Type:            m,
+			Values:          t.DiscriminatorValues(m),
+			Finalize:        w.Finalizer.Code(m.AttributeDefinition, "ut", 1) != "",
+			ValidatePrivate: w.Validator.Code(m.AttributeDefinition, false, false, false, "ut", "request", 1, true) != "",
+			ValidatePublic:  w.Validator.Code(m.AttributeDefinition, false, false, false, "ut", "type", 1, false) != "",
+		}
+	}
+	return members
+}
 
-	// ctxNewT generates the code for the context factory method.
-	// template input: *ContextTemplateData
-	ctxNewT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `
+// flagConstants returns the Go constants generated for the flags of the given flags type. The
+// name of a constant is the name of the type followed by the flag name, e.g. "PermissionsRead".
+func flagConstants(t *design.UserTypeDefinition) []enumConstant {
+	var (
+		typeName = codegen.GoTypeName(t, nil, 0, false)
+		consts   = make([]enumConstant, len(t.Flags))
+		seen     = make(map[string]bool)
+	)
+	for i, f := range t.Flags {
+		suffix := codegen.Goify(f, true)
+		name := typeName + suffix
+		if suffix == "" || seen[name] {
+			name = fmt.Sprintf("%sFlag%d", typeName, i)
+		}
+		seen[name] = true
+		consts[i] = enumConstant{Name: name, Value: fmt.Sprintf("1 << %d", i)}
+	}
+	return consts
+}
+
+// computable returns true if the attribute with the given name is a computed attribute whose
+// value can be computed from the other attributes of the media type.
+func computable(mt *design.MediaTypeDefinition, name string) bool {
+	o := mt.Type.ToObject()
+	att, ok := o[name]
+	if !ok || att.ComputedFrom == nil {
+		return false
+	}
+	for _, f := range att.ComputedFrom {
+		if _, ok := o[f]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// newComputeData creates the data given to the "computeFunc" template.
+func newComputeData(mt *design.MediaTypeDefinition, att *design.AttributeDefinition) map[string]interface{} {
+	return map[string]interface{}{"MediaType": mt, "Attribute": att}
+}
+
+// hasComputed returns true if the media type defines computed attributes that can be computed from
+// its other attributes.
+func hasComputed(mt *design.MediaTypeDefinition) bool {
+	if !mt.Type.IsObject() {
+		return false
+	}
+	for n := range mt.Type.ToObject() {
+		if computable(mt, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasComputedElem returns true if the media type is a collection whose elements define computed
+// attributes.
+func hasComputedElem(mt *design.MediaTypeDefinition) bool {
+	if !mt.Type.IsArray() {
+		return false
+	}
+	elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition)
+	return ok && hasComputed(elem)
+}
+
+// hasRestricted returns true if the media type defines attributes whose visibility is restricted
+// either directly or through nested media types and collections of media types.
+func hasRestricted(mt *design.MediaTypeDefinition) bool {
+	return restricted(mt, make(map[*design.MediaTypeDefinition]bool))
+}
+
+// restricted implements hasRestricted, seen records the media types already visited to handle
+// recursive media types.
+func restricted(mt *design.MediaTypeDefinition, seen map[*design.MediaTypeDefinition]bool) bool {
+	if seen[mt] || !mt.Type.IsObject() {
+		return false
+	}
+	seen[mt] = true
+	for _, att := range mt.Type.ToObject() {
+		if att.VisibleTo != nil {
+			return true
+		}
+		if nested := restrictedMediaType(att); nested != nil && restricted(nested, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictedMediaType returns the media type of the attribute or of the elements of the attribute
+// if it is a collection, nil if the attribute is neither.
+func restrictedMediaType(att *design.AttributeDefinition) *design.MediaTypeDefinition {
+	if mt, ok := att.Type.(*design.MediaTypeDefinition); ok && mt.Type.IsObject() {
+		return mt
+	}
+	if att.Type.IsArray() {
+		if mt, ok := att.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition); ok {
+			return mt
+		}
+	}
+	return nil
+}
+
+// restrictable returns true if the attribute is a media type that defines restricted attributes.
+func restrictable(att *design.AttributeDefinition) bool {
+	return !att.Type.IsArray() && restrictableElem(att)
+}
+
+// restrictableElem returns true if the attribute is a media type or a collection of media types
+// that define restricted attributes.
+func restrictableElem(att *design.AttributeDefinition) bool {
+	mt := restrictedMediaType(att)
+	return mt != nil && hasRestricted(mt)
+}
+
+// hasRestrictedElem returns true if the media type is a collection whose elements define
+// attributes whose visibility is restricted.
+func hasRestrictedElem(mt *design.MediaTypeDefinition) bool {
+	if !mt.Type.IsArray() {
+		return false
+	}
+	elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition)
+	return ok && hasRestricted(elem)
+}
+
+// filenameCode returns the Go expression that computes the name of the file downloaded with the
+// given response. The expression interpolates the context fields of the params that appear in the
+// filename.
+func filenameCode(resp *design.ResponseDefinition, params *design.AttributeDefinition) string {
+	names := resp.FilenameParams()
+	if len(names) == 0 {
+		return fmt.Sprintf("%q", resp.Filename)
+	}
+	format := strings.Replace(resp.Filename, "%", "%%", -1)
+	args := make([]string, len(names))
+	for i, n := range names {
+		format = strings.Replace(format, "{"+n+"}", "%v", 1)
+		att := params.Type.ToObject()[n]
+		args[i] = "ctx." + codegen.GoifyAtt(att, n, true)
+	}
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(args, ", "))
+}
+
+// locationCode returns the Go expression that computes the Location header of the given redirect
+// response. The expression interpolates the context fields of the params that appear in the
+// location.
+func locationCode(resp *design.ResponseDefinition, params *design.AttributeDefinition) string {
+	names := resp.LocationParams()
+	if len(names) == 0 {
+		return fmt.Sprintf("%q", resp.Location)
+	}
+	format := strings.Replace(resp.Location, "%", "%%", -1)
+	format = design.WildcardRegex.ReplaceAllLiteralString(format, "/%v")
+	args := make([]string, len(names))
+	for i, n := range names {
+		att := params.Type.ToObject()[n]
+		args[i] = "ctx." + codegen.GoifyAtt(att, n, true)
+	}
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(args, ", "))
+}
+
+// headerTemplatesCode returns the data needed to set the headers of the given response whose
+// values are computed from the response body rendered with the given projected media type: the
+// header name, the Go expression that computes the value and the checks that make the response
+// method return an error when an optional attribute used by the template is missing, since the
+// headers are required. The headers whose template refers to attributes not rendered by the view
+// are skipped.
+func headerTemplatesCode(resp *design.ResponseDefinition, projected *design.MediaTypeDefinition) []map[string]interface{} {
+	if len(resp.HeaderTemplates) == 0 || !projected.Type.IsObject() {
+		return nil
+	}
+	headers := make([]string, 0, len(resp.HeaderTemplates))
+	for h := range resp.HeaderTemplates {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	obj := projected.Type.ToObject()
+	var res []map[string]interface{}
+	for _, h := range headers {
+		names := resp.HeaderTemplateAttributes(h)
+		if len(names) == 0 {
+			res = append(res, map[string]interface{}{"Name": h, "Value": fmt.Sprintf("%q", resp.HeaderTemplates[h])})
+			continue
+		}
+		format := strings.Replace(resp.HeaderTemplates[h], "%", "%%", -1)
+		var (
+			args   []string
+			checks []map[string]string
+		)
+		for _, n := range names {
+			att, ok := obj[n]
+			if !ok {
+				args = nil
+				break
+			}
+			format = strings.Replace(format, "{"+n+"}", "%v", 1)
+			field := "r." + codegen.GoifyAtt(att, n, true)
+			if projected.IsPrimitivePointer(n) {
+				checks = append(checks, map[string]string{
+					"Field": field,
+					"Error": fmt.Sprintf("%q", fmt.Sprintf("missing attribute %q required to compute header %q", n, h)),
+				})
+				field = "*" + field
+			}
+			args = append(args, field)
+		}
+		if args == nil {
+			continue
+		}
+		res = append(res, map[string]interface{}{
+			"Name":   h,
+			"Value":  fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(args, ", ")),
+			"Checks": checks,
+		})
+	}
+	return res
+}
+
+// hasEmbeddable returns true if the media type declares embeddable relations.
+func hasEmbeddable(mt *design.MediaTypeDefinition) bool {
+	return len(mt.Embeddables) > 0
+}
+
+// hasEmbeddableElem returns true if the media type is a collection whose elements declare
+// embeddable relations.
+func hasEmbeddableElem(mt *design.MediaTypeDefinition) bool {
+	if !mt.Type.IsArray() {
+		return false
+	}
+	elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition)
+	return ok && hasEmbeddable(elem)
+}
+
+// hasGeometryElem returns true if the media type is a collection whose elements are rendered as
+// GeoJSON features.
+func hasGeometryElem(mt *design.MediaTypeDefinition) bool {
+	if !mt.Type.IsArray() {
+		return false
+	}
+	elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition)
+	return ok && elem.Geometry != ""
+}
+
+// embeddables returns the sorted names of the relations that the responses of the given action
+// may embed. It returns nil if the action defines its own "expand" parameter.
+func embeddables(api *design.APIDefinition, a *design.ActionDefinition) []string {
+	if a.Expand != nil {
+		return a.Expand
+	}
+	names := make(map[string]bool)
+	for _, r := range a.Responses {
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil {
+			continue
+		}
+		if mt.Type.IsArray() {
+			if elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition); ok {
+				mt = elem
+			}
+		}
+		for _, e := range mt.Embeddables {
+			names[e] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	if _, ok := a.AllParams().Type.ToObject()["expand"]; ok {
+		return nil
+	}
+	res := make([]string, 0, len(names))
+	for n := range names {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// parentKey returns the path parameters that identify the parent resources of the given resource,
+// nil if the resource has no parent or if the parent canonical path has no parameters.
+func parentKey(r *design.ResourceDefinition) *design.AttributeDefinition {
+	p := r.Parent()
+	if p == nil {
+		return nil
+	}
+	ca := p.CanonicalAction()
+	if ca == nil {
+		return nil
+	}
+	key := &design.AttributeDefinition{Type: design.Object{}}
+	for n, att := range ca.PathParams().Type.ToObject() {
+		if att != nil {
+			key.Type.ToObject()[n] = att
+		}
+	}
+	if len(key.Type.ToObject()) == 0 {
+		return nil
+	}
+	return key
+}
+
+// actionParentKey returns the parent key of the resource of the given action if all the action
+// routes include the parent path parameters, nil otherwise.
+func actionParentKey(a *design.ActionDefinition) *design.AttributeDefinition {
+	key := parentKey(a.Parent)
+	if key == nil || len(a.Routes) == 0 {
+		return nil
+	}
+	for _, r := range a.Routes {
+		params := make(map[string]bool)
+		for _, p := range r.Params() {
+			params[p] = true
+		}
+		for n := range key.Type.ToObject() {
+			if !params[n] {
+				return nil
+			}
+		}
+	}
+	return key
+}
+
+// validationPatterns returns the sorted regular expressions used to validate the params, headers
+// and payloads of the given actions.
+func validationPatterns(actions ...*design.ActionDefinition) []string {
+	seen := make(map[string]bool)
+	collect := func(att *design.AttributeDefinition) error {
+		if att.Validation != nil && att.Validation.Pattern != "" {
+			seen[att.Validation.Pattern] = true
+		}
+		return nil
+	}
+	for _, a := range actions {
+		if params := a.AllParams(); params != nil {
+			params.Walk(collect)
+		}
+		if a.Headers != nil {
+			a.Headers.Walk(collect)
+		}
+		if a.Payload != nil {
+			a.Payload.Walk(collect)
+		}
+	}
+	patterns := make([]string, 0, len(seen))
+	for p := range seen {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// fileLimits returns the Go code of the map of the maximum sizes of the files of the action
+// multipart payload indexed by form part name, "nil" if there is no limit.
+func fileLimits(a *design.ActionDefinition) string {
+	if !a.PayloadMultipart || a.Payload == nil {
+		return "nil"
+	}
+	var limits []string
+	a.Payload.ToObject().IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+		if arr := att.Type.ToArray(); arr != nil {
+			att = arr.ElemType
+		}
+		if att.Type.Kind() == design.FileKind && att.Validation != nil && att.Validation.MaxLength != nil {
+			name := n
+			if fn := att.Metadata["form:name"]; len(fn) > 0 && fn[0] != "" {
+				name = fn[0]
+			}
+			limits = append(limits, fmt.Sprintf("%q: %d", name, *att.Validation.MaxLength))
+		}
+		return nil
+	})
+	if len(limits) == 0 {
+		return "nil"
+	}
+	return "map[string]int64{" + strings.Join(limits, ", ") + "}"
+}
+
+// collectionCostFactor is the factor applied to the cost of collections in query cost models.
+const collectionCostFactor = 10
+
+// queryCostData contains the cost model of an action.
+type queryCostData struct {
+	Base      int
+	Relations map[string]int
+}
+
+// queryCost returns the cost model of the given action or nil if its responses do not declare
+// embeddable relations.
+func queryCost(api *design.APIDefinition, a *design.ActionDefinition) *queryCostData {
+	if embeddables(api, a) == nil {
+		return nil
+	}
+	qc := &queryCostData{Relations: make(map[string]int)}
+	for _, r := range a.Responses {
+		mt := api.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil {
+			continue
+		}
+		if base := mediaTypeCost(mt); base > qc.Base {
+			qc.Base = base
+		}
+		factor := 1
+		if mt.Type.IsArray() {
+			elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition)
+			if !ok {
+				continue
+			}
+			mt, factor = elem, collectionCostFactor
+		}
+		for _, e := range mt.Embeddables {
+			att, ok := mt.Type.ToObject()[e]
+			if !ok {
+				continue
+			}
+			cost := attributeCost(att)
+			if _, ok := att.Metadata["cost"]; !ok {
+				if rmt, ok := att.Type.(*design.MediaTypeDefinition); ok {
+					cost = mediaTypeCost(rmt)
+				}
+			}
+			if cost*factor > qc.Relations[e] {
+				qc.Relations[e] = cost * factor
+			}
+		}
+	}
+	return qc
+}
+
+// mediaTypeCost returns the sum of the weights of the attributes of the given media type excluding
+// its embeddable relations.
+func mediaTypeCost(mt *design.MediaTypeDefinition) int {
+	if mt.Type.IsArray() {
+		if elem, ok := mt.Type.ToArray().ElemType.Type.(*design.MediaTypeDefinition); ok {
+			return collectionCostFactor * mediaTypeCost(elem)
+		}
+		return collectionCostFactor
+	}
+	cost := 0
+	for n, att := range mt.Type.ToObject() {
+		embeddable := false
+		for _, e := range mt.Embeddables {
+			embeddable = embeddable || e == n
+		}
+		if !embeddable {
+			cost += attributeCost(att)
+		}
+	}
+	return cost
+}
+
+// attributeCost returns the weight of the given attribute as defined by its "cost" metadata, 1 by
+// default.
+func attributeCost(att *design.AttributeDefinition) int {
+	if c, ok := att.Metadata["cost"]; ok && len(c) > 0 {
+		if n, err := strconv.Atoi(c[0]); err == nil {
+			return n
+		}
+	}
+	return 1
+}
+
+// newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
+func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, pkg string, depth int) map[string]interface{} {
+	return map[string]interface{}{
+		"Name":      name,
+		"VarName":   codegen.Goify(name, false),
+		"Pointer":   pointer,
+		"Attribute": att,
+		"Pkg":       pkg,
+		"Depth":     depth,
+	}
+}
+
+// arrayAttribute returns the array element attribute definition.
+func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
+	return a.Type.(*design.Array).ElemType
+}
+
+const (
+	// ctxT generates the code for the context data type.
+	// template input: *ContextTemplateData
+	ctxT = `// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action context.
+type {{ .Name }} struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
+{{ end }}{{ end }}{{ end }}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if $.Cookies.IsPrimitivePointer $name }}*{{ end }}{{ gotyperef .Type nil 0 false }}
+{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
+{{ end }}{{ end }}{{ if .Payload }}	Payload {{ if .PayloadStreaming }}io.Reader{{ else }}{{ gotyperef .Payload nil 0 false }}{{ end }}
+{{ end }}{{ if .Resumable }}	Upload *tus.Upload
+{{ end }}{{ if .VersionAttribute }}	ExpectedVersion string
+{{ end }}{{ if .Embeddables }}	Expand []string
+{{ end }}}
+`
+	// coerceT generates the code that coerces the generic deserialized
+	// data to the actual type.
+	// template input: map[string]interface{} as returned by newCoerceData
+	coerceT = `{{ if eq .Attribute.Type.Kind 1 }}{{/*
+
+*/}}{{/* BooleanType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseBool(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "boolean"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 2 }}{{/*
+
+*/}}{{/* IntegerType */}}{{/*
+*/}}{{ $tmp := tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
+{{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
+{{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
+{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
+{{ end }}{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 3 }}{{/*
+
+*/}}{{/* NumberType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 64); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 4 }}{{/*
+
+*/}}{{/* StringType */}}{{/*
+*/}}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
+{{ end }}{{ if eq .Attribute.Type.Kind 5 }}{{/*
+
+*/}}{{/* DateTimeType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := time.Parse(time.RFC3339, raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "datetime"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 6 }}{{/*
+
+*/}}{{/* UUIDType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := uuid.FromString(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "uuid"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 14 }}{{/*
+
+*/}}{{/* DateType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := goa.ParseDate(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "date"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 7 }}{{/*
+
+*/}}{{/* AnyType */}}{{/*
+*/}}{{ if .Pointer }}{{ $tmp := tempvar }}{{ tabs .Depth }}{{ $tmp }} := interface{}(raw{{ goify .Name true }})
+{{ tabs .Depth }}{{ .Pkg }} = &{{ $tmp }}
+{{ else }}{{ tabs .Depth }}{{ .Pkg }} = raw{{ goify .Name true }}
+{{ end }}{{ end }}`
+
+	// ctxNewT generates the code for the context factory method.
+	// template input: *ContextTemplateData
+	ctxNewT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `
 // New{{ goify .Name true }} parses the incoming request URL and body, performs validations and creates the
 // context used by the {{ .ResourceName }} controller {{ .ActionName }} action.
 func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service) (*{{ .Name }}, error) {
@@ -545,7 +1488,18 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 	req.Request = r
 	rctx := {{ .Name }}{Context: ctx, ResponseData: resp, RequestData: req}{{/*
 */}}
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
+{{ if .Resumable }}	rctx.Upload = tus.ContextUpload(ctx)
+{{ end }}{{ if .VersionAttribute }}	ifMatch := req.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil, goa.ErrPreconditionRequired("missing required If-Match header", "attribute", {{ printf "%q" .VersionAttribute }})
+	}
+	rctx.ExpectedVersion = strings.Trim(strings.TrimPrefix(ifMatch, "W/"), "\"")
+{{ end }}{{ if .Embeddables }}	if expand, err2 := goa.ParseExpand(req.Params["expand"]{{ range .Embeddables }}, {{ printf "%q" . }}{{ end }}); err2 == nil {
+		rctx.Expand = expand
+	} else {
+		err = goa.MergeErrors(err, err2)
+	}
+{{ end }}{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
 {{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $name true }}) == 0 {
 		err = goa.MergeErrors(err, goa.MissingHeaderError("{{ $name }}"))
 	} else {
@@ -565,7 +1519,38 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 {{ end }}	}
 {{ end }}{{ end }}{{/* if .Headers }}{{/*
 
-*/}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
+*/}}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}{{/*
+*/}}{{ if $.Cookies.IsRequired $name }}	if cookie{{ goify $name true }}, err2 := req.Cookie("{{ $name }}"); err2 != nil {
+		err = goa.MergeErrors(err, goa.MissingCookieError("{{ $name }}"))
+	} else {
+{{ else }}	if cookie{{ goify $name true }}, err2 := req.Cookie("{{ $name }}"); err2 == nil {
+{{ end }}		raw{{ goify $name true }} := cookie{{ goify $name true }}.Value
+{{ template "Coerce" (newCoerceData $name $att ($.Cookies.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{/*
+*/}}{{ $validation := validationChecker $att ($.Cookies.IsNonZero $name) ($.Cookies.IsRequired $name) ($.Cookies.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+*/}}{{ if $validation }}{{ $validation }}
+{{ end }}	}
+{{ end }}{{ end }}{{/* if .Cookies }}{{/*
+
+*/}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{ if $att.IsDeepObject }}{{/*
+*/}}	if raw{{ goify $name true }} := goa.DeepObjectParams(req.Params, "{{ $name }}"); len(raw{{ goify $name true }}) > 0 {
+		param{{ goify $name true }} := &{{ gotypename $att.Type nil 0 true }}{}
+		if err2 := goa.DecodeDeepObject(raw{{ goify $name true }}, param{{ goify $name true }}); err2 != nil {
+			err = goa.MergeErrors(err, goa.ErrInvalidRequest(err2, "param", "{{ $name }}"))
+		} else {
+{{ if finalizeCode $att.Type.AttributeDefinition "ut" 1 }}			param{{ goify $name true }}.Finalize()
+{{ end }}{{ if validationCode $att.Type.AttributeDefinition false false false "ut" "request" 1 true }}{{/*
+*/}}{{ if isBuiltinType $att.Type }}			if err2 := param{{ goify $name true }}.ValidateAt(` + "`{{ $name }}`" + `); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+{{ else }}			if err2 := param{{ goify $name true }}.Validate(); err2 != nil {
+				err = goa.MergeErrors(err, goa.WithErrorContext(err2, ` + "`{{ $name }}`" + `))
+			}
+{{ end }}{{ end }}			{{ printf "rctx.%s" (goifyatt $att $name true) }} = param{{ goify $name true }}.Publicize()
+		}
+	}{{ if $.MustValidate $name }} else {
+		err = goa.MergeErrors(err, goa.MissingParamError("{{ $name }}"))
+	}{{ end }}
+{{ else }}{{/*
 */}}	param{{ goify $name true }} := req.Params["{{ $name }}"]
 {{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
 		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}{{else}}{{/*
@@ -575,7 +1560,8 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 		{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}
 	} else {
 {{ else }}	if len(param{{ goify $name true }}) > 0 {
-{{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if eq (arrayAttribute $att).Type.Kind 4 }}		params := param{{ goify $name true }}
+{{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if $att.CollectionSeparator }}		param{{ goify $name true }} = goa.SplitCollection(param{{ goify $name true }}, {{ printf "%q" $att.CollectionSeparator }})
+{{ end }}{{ if eq (arrayAttribute $att).Type.Kind 4 }}		params := param{{ goify $name true }}
 {{ else }}		params := make({{ gotypedef $att 2 true false }}, len(param{{ goify $name true }}))
 		for i, raw{{ goify $name true}} := range param{{ goify $name true}} {
 {{ template "Coerce" (newCoerceData $name (arrayAttribute $att) ($.Params.IsPrimitivePointer $name) "params[i]" 3) }}{{/*
@@ -586,7 +1572,7 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 */}}{{ $validation := validationChecker $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
 */}}{{ if $validation }}{{ $validation }}
 {{ end }}	}
-{{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
+{{ end }}{{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
 }
 `
 
@@ -598,7 +1584,93 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 {{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
-{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ end }}{{ if hasComputed .Projected }}	r.Compute()
+{{ else if hasComputedElem .Projected }}	for _, e := range r {
+		e.Compute()
+	}
+{{ end }}{{ if hasRestricted .Projected }}	r.Restrict(goa.ContextGrantedScopes(ctx.Context))
+{{ else if hasRestrictedElem .Projected }}	for _, e := range r {
+		e.Restrict(goa.ContextGrantedScopes(ctx.Context))
+	}
+{{ end }}{{ if .Context.Embeddables }}{{ if hasEmbeddable .Projected }}	r.Embed(ctx.Expand)
+{{ else if hasEmbeddableElem .Projected }}	for _, e := range r {
+		e.Embed(ctx.Expand)
+	}
+{{ end }}{{ end }}{{ range .HeaderTemplates }}{{ range .Checks }}	if {{ .Field }} == nil {
+		return fmt.Errorf({{ .Error }})
+	}
+{{ end }}	ctx.ResponseData.Header().Set({{ printf "%q" .Name }}, {{ .Value }})
+{{ end }}{{ if .Context.RenderHTML }}	return ctx.ResponseData.Service.SendHTML(ctx.Context, {{ .Response.Status }}, {{ printf "%q" .MediaType.Identifier }}, {{ printf "%q" .ViewName }}, r)
+{{ else }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ end }}}
+`
+
+	// ctxStreamRespT generates the response helpers for server-sent event stream responses.
+	// template input: map[string]interface{}
+	ctxStreamRespT = `// {{ goify .RespName true }} starts the server-sent event stream response with status code {{ .Response.Status }} and returns
+// the stream used to send the events.
+func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}() *{{ .StreamName }} {
+	return &{{ .StreamName }}{EventStream: goa.NewEventStream(ctx.Context), ctx: ctx}
+}
+
+// {{ .StreamName }} sends the events of the {{ .Context.Name }} {{ goify .RespName true }} server-sent event stream.
+type {{ .StreamName }} struct {
+	*goa.EventStream
+	ctx *{{ .Context.Name }}
+}
+
+{{ if .Response.Deltas }}// Created sends a delta describing the creation of r, token is the resume token of the delta.
+func (s *{{ .StreamName }}) Created(token string, r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	return s.send(goa.DeltaCreate, token, r)
+}
+
+// Updated sends a delta describing the update of r, token is the resume token of the delta.
+func (s *{{ .StreamName }}) Updated(token string, r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	return s.send(goa.DeltaUpdate, token, r)
+}
+
+// Deleted sends a delta describing the deletion of r, token is the resume token of the delta.
+func (s *{{ .StreamName }}) Deleted(token string, r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+	return s.send(goa.DeltaDelete, token, r)
+}
+
+// ResumeToken returns the resume token of the last delta received by reconnecting clients, the
+// empty string for new clients. Handlers send the deltas made since that token first.
+func (s *{{ .StreamName }}) ResumeToken() string {
+	return s.LastEventID()
+}
+
+// send sends a delta with the given operation and resume token whose element is r.
+func (s *{{ .StreamName }}) send(op, token string, r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+{{ else }}// Send sends an event of the given type whose data is r, event may be empty.
+func (s *{{ .StreamName }}) Send(event string, r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+{{ end }}{{ if .Projected.Type.IsArray }}	if r == nil {
+		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
+	}
+{{ end }}{{ if hasComputed .Projected }}	r.Compute()
+{{ else if hasComputedElem .Projected }}	for _, e := range r {
+		e.Compute()
+	}
+{{ end }}{{ if hasRestricted .Projected }}	r.Restrict(goa.ContextGrantedScopes(s.ctx.Context))
+{{ else if hasRestrictedElem .Projected }}	for _, e := range r {
+		e.Restrict(goa.ContextGrantedScopes(s.ctx.Context))
+	}
+{{ end }}{{ if .Context.Embeddables }}{{ if hasEmbeddable .Projected }}	r.Embed(s.ctx.Expand)
+{{ else if hasEmbeddableElem .Projected }}	for _, e := range r {
+		e.Embed(s.ctx.Expand)
+	}
+{{ end }}{{ end }}{{ if .Response.Deltas }}	return s.EventStream.SendDelta(op, {{ printf "%q" .MediaType.Identifier }}, token, r)
+{{ else }}	return s.EventStream.Send(event, r)
+{{ end }}}
+`
+
+	// ctxChunkedRespT generates the response helpers for responses written as a stream.
+	// template input: map[string]interface{}
+	ctxChunkedRespT = `// {{ goify .RespName true }} writes the headers of the HTTP response with status code {{ .Response.Status }} and returns the writer
+// used to write the response body, a JSON array that may be written one element at a time with goa.NewCollectionEncoder.
+// The body is sent in chunks as it is written.
+func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}() io.Writer {
+	return goa.NewChunkedResponse(ctx.Context, {{ .Response.Status }}, {{ printf "%q" (or .ContentType .MediaType.Identifier) }})
 }
 `
 
@@ -609,6 +1681,60 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
 }
+`
+
+	// ctxServeContentT generates the range request aware response helper.
+	// template input: *ContextTemplateData
+	ctxServeContentT = `// ServeContent writes the content read from r handling range requests: it responds with the
+// entire content, with a 206 Partial Content response or with a multipart/byteranges response
+// depending on the request Range and If-Range headers. name is used to infer the content type if
+// it is not already set and modtime to handle conditional requests, it may be the zero time.
+func (ctx *{{ .Name }}) ServeContent(name string, modtime time.Time, r io.ReadSeeker) error {
+	ctx.ResponseData.Header().Set("Accept-Ranges", "bytes")
+	http.ServeContent(ctx.ResponseData, ctx.Request, name, modtime, r)
+	return nil
+}
+`
+
+	// ctxCheckVersionT generates the optimistic concurrency control helper.
+	// template input: *ContextTemplateData
+	ctxCheckVersionT = `// CheckVersion compares the version given in the request If-Match header with the current
+// version of the resource ({{ .VersionAttribute }} attribute). It returns an error that results in a
+// 412 Precondition Failed response if they differ.
+func (ctx *{{ .Name }}) CheckVersion(current string) error {
+	if ctx.ExpectedVersion != "*" && ctx.ExpectedVersion != current {
+		return goa.ErrPreconditionFailed("resource version mismatch", "expected", ctx.ExpectedVersion, "current", current)
+	}
+	return nil
+}
+`
+
+	// ctxPaginateT generates the helper that sets the pagination headers of the response.
+	// template input: *ContextTemplateData
+	ctxPaginateT = `{{ if eq .Pagination.Style "page" }}// Paginate sets the Link and X-Total-Count headers of the response given the total number of
+// results.
+func (ctx *{{ .Name }}) Paginate(total int) {
+	ctx.ResponseData.Header().Set("X-Total-Count", strconv.Itoa(total))
+	ctx.ResponseData.Header().Set("Link", goa.PageLinks(ctx.Request.URL, ctx.Page, ctx.PerPage, total))
+}
+{{ else }}// Paginate sets the Link header of the response given the cursor of the next page, next is
+// empty if the response contains the last page.
+func (ctx *{{ .Name }}) Paginate(next string) {
+	if link := goa.CursorLinks(ctx.Request.URL, next); link != "" {
+		ctx.ResponseData.Header().Set("Link", link)
+	}
+}
+{{ end }}`
+
+	// ctxParentKeyT generates the helper that builds the identity of the parent resources.
+	// template input: *ContextTemplateData
+	ctxParentKeyT = `// ParentKey returns the identity of the parent resources of the {{ .ResourceName }} resource built
+// from the request path parameters.
+func (ctx *{{ .Name }}) ParentKey() *{{ goify .ResourceName true }}ParentKey {
+	return &{{ goify .ResourceName true }}ParentKey{
+{{ range $name, $att := .ParentKey.Type.ToObject }}		{{ goifyatt $att $name true }}: ctx.{{ goifyatt $att $name true }},
+{{ end }}	}
+}
 `
 
 	// ctxNoMTRespT generates the response helpers for responses with no known media type.
@@ -617,6 +1743,8 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 // {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.MediaType }}resp []byte{{ end }}) error {
 {{ if .Response.MediaType }}	ctx.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
+{{ end }}{{ if .Filename }}	ctx.ResponseData.Header().Set("Content-Disposition", goa.AttachmentDisposition({{ .Filename }}))
+{{ end }}{{ if .Location }}	ctx.ResponseData.Header().Set("Location", {{ .Location }})
 {{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
 	_, err := ctx.ResponseData.Write(resp)
 	return err{{ else }}
@@ -650,13 +1778,71 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize()
 
 // {{ gotypename .Payload nil 0 false }} is the {{ .ResourceName }} {{ .ActionName }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
+{{ $marshaler := nullableMarshaler .Payload.AttributeDefinition (gotypename .Payload nil 1 false) "payload" }}{{ if $marshaler }}
+{{ $marshaler }}{{ end }}
+{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
+{{ $validation }}
+	return
+}{{ end }}
+`
+	// inboundMessageT generates the type of the messages sent by clients to a websocket action.
+	// template input: map[string]interface{}
+	inboundMessageT = `{{ $msg := .Inbound }}// {{ gotypename $msg nil 0 true }} is the {{ .Context.ResourceName }} {{ .Context.ActionName }} action inbound message.{{/*
+*/}}{{ $privateTypeName := gotypename $msg nil 1 true }}
+type {{ $privateTypeName }} {{ gotypedef $msg 0 true true }}
+
+{{ $assignment := finalizeCode $msg.AttributeDefinition "msg" 1 }}{{ if $assignment }}// Finalize sets the default values defined in the design.
+func (msg {{ gotyperef $msg $msg.AllRequired 0 true }}) Finalize() {
+{{ $assignment }}
+}{{ end }}
+
+{{ $validation := validationCode $msg.AttributeDefinition false false false "msg" "message" 1 true }}{{ if $validation }}// Validate runs the validation rules defined in the design.
+func (msg {{ gotyperef $msg $msg.AllRequired 0 true }}) Validate() (err error) {
+{{ $validation }}
+	return
+}{{ end }}
+{{ $typeName := gotypename $msg $msg.AllRequired 1 false }}
+// Publicize creates {{ $typeName }} from {{ $privateTypeName }}
+func (msg {{ gotyperef $msg $msg.AllRequired 0 true }}) Publicize() {{ gotyperef $msg $msg.AllRequired 0 false }} {
+	var pub {{ $typeName }}
+	{{ recursivePublicizer $msg.AttributeDefinition "msg" "pub" 1 }}
+	return &pub
+}
+
+// {{ gotypename $msg nil 0 false }} is the {{ .Context.ResourceName }} {{ .Context.ActionName }} action inbound message.
+type {{ gotypename $msg nil 1 false }} {{ gotypedef $msg 0 true false }}
+{{ $marshaler := nullableMarshaler $msg.AttributeDefinition (gotypename $msg nil 1 false) "msg" }}{{ if $marshaler }}
+{{ $marshaler }}{{ end }}
+{{ $validation := validationCode $msg.AttributeDefinition false false false "msg" "message" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
+func (msg {{ gotyperef $msg $msg.AllRequired 0 false }}) Validate() (err error) {
+{{ $validation }}
+	return
+}{{ end }}
+`
+
+	// ctxWebSocketT generates the context methods that receive and send websocket messages.
+	// template input: map[string]interface{}
+	ctxWebSocketT = `{{ if .Inbound }}{{ $msg := .Inbound }}// ReceiveMessage reads the next message sent by the client on the websocket connection ws and
+// validates it.
+func (ctx *{{ .Context.Name }}) ReceiveMessage(ws *websocket.Conn) ({{ gotyperef $msg $msg.AllRequired 0 false }}, error) {
+	msg := &{{ gotypename $msg nil 1 true }}{}
+	if err := websocket.JSON.Receive(ws, msg); err != nil {
+		return nil, err
+	}{{ $assignment := finalizeCode $msg.AttributeDefinition "msg" 1 }}{{ if $assignment }}
+	msg.Finalize(){{ end }}{{ $validation := validationCode $msg.AttributeDefinition false false false "msg" "message" 1 true }}{{ if $validation }}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}{{ end }}
+	return msg.Publicize(), nil
+}
+{{ end }}{{ if .Outbound }}
+// SendMessage writes a message to the client on the websocket connection ws.
+func (ctx *{{ .Context.Name }}) SendMessage(ws *websocket.Conn, msg {{ gotyperef .Outbound .Outbound.AllRequired 0 false }}) error {
+	return websocket.JSON.Send(ws, msg)
+}
+{{ end }}`
 
-{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
-func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
-{{ $validation }}
-	return
-}{{ end }}
-`
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
@@ -694,38 +1880,366 @@ func initService(service *goa.Service) {
 func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
 	initService(service)
 	var h goa.Handler
-{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
+{{ if .Patterns }}	goa.CompilePatterns({{ range $i, $p := .Patterns }}{{ if $i }}, {{ end }}{{ printf "%q" $p }}{{ end }})
+{{ end }}{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
 */}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
 {{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, {{ printf "%q" .ResourceName }}, {{ printf "%q" .DesignName }})
+		}
+{{ if and .Payload (not .Streaming) }}		// Validate the payload now that the caller is authenticated
+		if err := service.ValidateRequestPayload(ctx, req); err != nil {
+			return goa.WithDesignLocation(goa.ErrBadRequest(err), {{ printf "%q" .ResourceName }}, {{ printf "%q" .DesignName }})
 		}
-		// Build the context
+{{ end }}		// Build the context
 		rctx, err := New{{ .Context }}(ctx, req, service)
 		if err != nil {
+			return goa.WithDesignLocation(err, {{ printf "%q" .ResourceName }}, {{ printf "%q" .DesignName }})
+		}
+{{ if .ParentKey }}		// Check that the parent resources exist
+		if err := resolve{{ $res }}Parent(ctx, rctx.ParentKey()); err != nil {
+			return err
+		}
+{{ end }}{{ if .Enrich }}		// Look up the values derived from the params
+		if err := enrich{{ .Name }}{{ $res }}(rctx); err != nil {
 			return err
 		}
-{{ if .Payload }}		// Build the payload
+{{ end }}{{ if .Streaming }}		// Stream the payload
+		rctx.Payload = req.Body
+{{ else if .Payload }}		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.({{ gotyperef .Payload nil 1 false }})
 {{ if not .PayloadOptional }}		} else {
-			return goa.MissingPayloadError()
+			return goa.WithDesignLocation(goa.MissingPayloadError(), {{ printf "%q" .ResourceName }}, {{ printf "%q" .DesignName }})
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ if .Resumable }}	h = tus.Handler(h)
+{{ end }}{{ with .QueryCost }}	h = handleQueryCost(h, &goa.QueryCost{Base: {{ .Base }}, Relations: map[string]int{ {{ range $rel, $cost := .Relations }}{{ printf "%q" $rel }}: {{ $cost }}, {{ end }} }})
+{{ end }}{{ if .ReplayProtected }}	h = handleReplayProtection(h)
+{{ end }}{{ if .SignedURL }}	h = handleSignedURL(h, {{ if .Security }}handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }}){{ else }}nil{{ end }})
+{{ else if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ with .Concurrency }}	h = goa.NewConcurrencyLimiter({{ printf "%q" $action.ResourceName }}, {{ printf "%q" $action.DesignName }}, {{ .MaxInFlight }}, {{ .QueueSize }}, {{ durationCode .QueueTimeout }}).Handle(h)
+{{ end }}{{ if $.HiddenVersions }}	h = goa.RejectVersions(h{{ range $.HiddenVersions }}, goa.VersionSelector{Name: {{ printf "%q" .Name }}, {{ if eq .Scheme "header" }}Header{{ else }}Query{{ end }}: {{ printf "%q" .Key }}}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ end }}{{ range .Routes }}{{ $route := . }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if and $action.Payload (not $action.Streaming) }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
-{{ end }}{{ end }}{{ range .FileServers }}
+{{ range $.PathVersions }}{{ $path := .RoutePath $route.FullPath }}	service.Mux.Handle("{{ $route.Verb }}", {{ printf "%q" $path }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if and $action.Payload (not $action.Streaming) }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" $route.Verb $path) }}, "version", {{ printf "%q" .Name }})
+{{ end }}{{ range .Aliases }}	service.Mux.Handle("{{ $route.Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, {{ if eq .Policy "redirect" }}handleAliasRedirect({{ printf "%q" $route.FullPath }}){{ else if eq .Policy "gone" }}handleAliasGone(){{ else }}h{{ end }}, {{ if and (eq .Policy "serve") $action.Payload (not $action.Streaming) }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "alias", {{ printf "%q" (printf "%s %s" $route.Verb .FullPath) }}, "policy", {{ printf "%q" .Policy }})
+{{ end }}{{ if and $action.Resumable (eq .Verb "POST") }}{{ if not $.Origins }}	service.Mux.Handle("OPTIONS", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, nil))
+{{ end }}	service.Mux.Handle("HEAD", {{ printf "%q" (printf "%s/:upload_id" .FullPath) }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, nil))
+	service.Mux.Handle("PATCH", {{ printf "%q" (printf "%s/:upload_id" .FullPath) }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, nil))
+{{ end }}{{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
 {{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
 {{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
+`
+
+	// parentResolverT generates the parent resources key type and the code that mounts and runs the
+	// parent resolver hook.
+	// template input: *ControllerTemplateData
+	parentResolverT = `{{ $private := goify .Resource false }}
+type (
+	// {{ .Resource }}ParentKey identifies the parent resources of a {{ .Resource }} resource.
+	{{ .Resource }}ParentKey struct {
+{{ range $name, $att := .ParentKey.Type.ToObject }}		{{ goifyatt $att $name true }} {{ gotyperef .Type nil 0 false }}
+{{ end }}	}
+
+	// {{ .Resource }}ParentResolver reports whether the parent resources identified by key exist.
+	{{ .Resource }}ParentResolver func(ctx context.Context, key *{{ .Resource }}ParentKey) (bool, error)
+
+	// Private type used to store the {{ .Resource }} parent resolver in the service context
+	{{ $private }}ParentResolverKey struct{}
+)
+
+// Use{{ .Resource }}ParentResolver mounts the hook that checks that the parent resources exist before
+// the {{ .Resource }} actions run. The actions respond with 404 Not Found if they do not.
+func Use{{ .Resource }}ParentResolver(service *goa.Service, resolver {{ .Resource }}ParentResolver) {
+	service.Context = context.WithValue(service.Context, {{ $private }}ParentResolverKey{}, resolver)
+}
+
+// resolve{{ .Resource }}Parent checks that the parent resources identified by key exist using the
+// resolver mounted with Use{{ .Resource }}ParentResolver if any.
+func resolve{{ .Resource }}Parent(ctx context.Context, key *{{ .Resource }}ParentKey) error {
+	resolver, ok := ctx.Value({{ $private }}ParentResolverKey{}).({{ .Resource }}ParentResolver)
+	if !ok {
+		return nil
+	}
+	found, err := resolver(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return goa.ErrNotFound("parent resource not found"{{ range $name, $att := .ParentKey.Type.ToObject }}, {{ printf "%q" $name }}, key.{{ goifyatt $att $name true }}{{ end }})
+	}
+	return nil
+}
+`
+
+	// aliasRedirectT generates the handler used to redirect requests made to route aliases.
+	// template input: nil
+	aliasRedirectT = `
+// handleAliasRedirect creates a handler that permanently redirects requests made to a route alias
+// to the given route path. The route path wildcards are replaced with the request path parameter
+// values.
+func handleAliasRedirect(path string) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		params := goa.ContextRequest(ctx).Params
+		segments := strings.Split(path, "/")
+		for i, s := range segments {
+			if strings.HasPrefix(s, ":") {
+				segments[i] = url.PathEscape(params.Get(s[1:]))
+			} else if strings.HasPrefix(s, "*") {
+				segments[i] = params.Get(s[1:])
+			}
+		}
+		target := strings.Join(segments, "/")
+		if req.URL.RawQuery != "" {
+			target += "?" + req.URL.RawQuery
+		}
+		http.Redirect(rw, req, target, http.StatusMovedPermanently)
+		return nil
+	}
+}
+`
+
+	// aliasGoneT generates the handler used to respond to requests made to retired route aliases.
+	// template input: nil
+	aliasGoneT = `
+// handleAliasGone creates a handler that responds with 410 Gone to requests made to a retired
+// route alias.
+func handleAliasGone() goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return goa.ErrGone("resource is no longer available at this path", "path", req.URL.Path)
+	}
+}
+`
+
+	// replayProtectionT generates the code that mounts and runs the replay protection middleware.
+	// template input: nil
+	replayProtectionT = `
+type (
+	// Private type used to store the replay protection middleware in the service context
+	replayProtectionKey struct{}
+)
+
+// UseReplayProtectionMiddleware mounts the middleware run by the actions flagged with
+// ReplayProtected in the design, typically created with middleware.ReplayProtection.
+func UseReplayProtectionMiddleware(service *goa.Service, middleware goa.Middleware) {
+	service.Context = context.WithValue(service.Context, replayProtectionKey{}, middleware)
+}
+
+// handleReplayProtection creates a handler that runs the replay protection middleware.
+func handleReplayProtection(h goa.Handler) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		m, ok := ctx.Value(replayProtectionKey{}).(goa.Middleware)
+		if !ok {
+			return goa.ErrInternal("replay protection middleware is not mounted")
+		}
+		return m(h)(ctx, rw, req)
+	}
+}
+`
+
+	// queryCostT generates the code that mounts and runs the query cost middleware.
+	// template input: nil
+	queryCostT = `
+type (
+	// Private type used to store the query cost middleware in the service context
+	queryCostKey struct{}
+)
+
+// UseQueryCostMiddleware mounts the middleware run by the actions whose responses declare
+// embeddable relations, typically created with middleware.QueryCostLimit.
+func UseQueryCostMiddleware(service *goa.Service, middleware goa.Middleware) {
+	service.Context = context.WithValue(service.Context, queryCostKey{}, middleware)
+}
+
+// handleQueryCost creates a handler that records the action cost model in the request context and
+// runs the query cost middleware if mounted.
+func handleQueryCost(h goa.Handler, cost *goa.QueryCost) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		ctx = goa.WithQueryCost(ctx, cost)
+		if m, ok := ctx.Value(queryCostKey{}).(goa.Middleware); ok {
+			return m(h)(ctx, rw, req)
+		}
+		return h(ctx, rw, req)
+	}
+}
+`
+
+	// signedURLT generates the code that mounts and runs the signed URL verification middleware.
+	signedURLT = `
+type (
+	// Private type used to store the signed URL middleware in the service context
+	signedURLKey struct{}
+)
+
+// UseSignedURLMiddleware mounts the middleware that verifies the signature of the URLs used to
+// invoke the actions flagged with SignedURL in the design, typically created with signedurl.New.
+func UseSignedURLMiddleware(service *goa.Service, middleware goa.Middleware) {
+	service.Context = context.WithValue(service.Context, signedURLKey{}, middleware)
+}
+
+// handleSignedURL creates a handler that runs the signed URL middleware prior to invoking signed
+// when the request URL is signed. Other requests are handled by unsigned if not nil and rejected
+// otherwise.
+func handleSignedURL(signed, unsigned goa.Handler) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if !signedurl.IsSigned(req) {
+			if unsigned == nil {
+				return signedurl.ErrInvalidSignature("missing URL signature")
+			}
+			return unsigned(ctx, rw, req)
+		}
+		m, ok := ctx.Value(signedURLKey{}).(goa.Middleware)
+		if !ok {
+			return goa.ErrInternal("signed URL middleware is not mounted")
+		}
+		return m(signed)(ctx, rw, req)
+	}
+}
+`
+
+	// sagaT generates the saga steps struct and constructor of an action.
+	// template input: map[string]interface{}
+	sagaT = `
+// {{ .Name }}SagaSteps contains the implementations of the steps of the {{ .Saga.Name }} saga run by
+// the {{ .Resource }} {{ .Action }} action.
+type {{ .Name }}SagaSteps struct {
+{{ range .Saga.Steps }}	// {{ goify .Name true }} implements the {{ .Name }} step{{ if .Description }}: {{ .Description }}{{ end }}
+	{{ goify .Name true }} saga.Step
+{{ end }}}
+
+// New{{ .Name }}Saga creates the {{ .Saga.Name }} saga coordinator. The steps run in the order
+// {{ range $i, $s := .Saga.Steps }}{{ if $i }}, {{ end }}{{ $s.Name }}{{ end }}, store may be nil.
+func New{{ .Name }}Saga(steps *{{ .Name }}SagaSteps, store saga.Store) *saga.Saga {
+	return saga.New({{ printf "%q" .Saga.Name }}, store){{ range .Saga.Steps }}.
+		Add({{ printf "%q" .Name }}, steps.{{ goify .Name true }}){{ end }}
+}
+`
+
+	// aggregateT generates the aggregate branches struct and constructor of an action.
+	// template input: map[string]interface{}
+	aggregateT = `
+// {{ .Name }}AggregateBranches contains the functions that call the downstream actions composed
+// by the {{ .Resource }} {{ .Action }} action into the {{ .Aggregate.MediaType }} media type.
+type {{ .Name }}AggregateBranches struct {
+{{ range .Aggregate.Branches }}	// {{ goify .Name true }} calls the {{ if .Service }}{{ .Service }} service {{ end }}{{ .Resource }} {{ .Action }} action{{ if .Optional }}, the branch is optional{{ end }}.
+	{{ goify .Name true }} aggregate.FetchFunc
+{{ end }}}
+
+// New{{ .Name }}Aggregate creates the aggregate that runs the branches concurrently and maps their
+// results into the {{ .Aggregate.MediaType }} media type.
+func New{{ .Name }}Aggregate(branches *{{ .Name }}AggregateBranches) *aggregate.Aggregate {
+	return aggregate.New(){{ range .Aggregate.Branches }}.
+		Add(&aggregate.Branch{
+			Name:  {{ printf "%q" .Name }},
+			Fetch: branches.{{ goify .Name true }},
+{{ if .Timeout }}			Timeout: {{ durationCode .Timeout }},
+{{ end }}{{ if .Optional }}			Optional: true,
+{{ end }}			Mappings: []*aggregate.Mapping{
+{{ range .Mappings }}				{From: {{ printf "%q" .From }}, To: {{ printf "%q" .To }}},
+{{ end }}			},
+		}){{ end }}
+}
+`
+
+	// enrichmentT generates the code that looks up the values derived from the params of an
+	// action.
+	// template input: map[string]interface{}
+	enrichmentT = `{{ $private := .Private }}
+type (
+	// {{ .Name }}Enrichers contains the functions that look up the values derived from the params
+	// of the {{ .Resource }} {{ .Action }} action.
+	{{ .Name }}Enrichers struct {
+{{ range .Enrichments }}		// {{ .Field }} looks up the {{ .Name }} value from the {{ .Param }} param{{ if .Description }}: {{ .Description }}{{ end }}
+		{{ .Field }} func(ctx context.Context, {{ .ParamVar }} {{ .ParamType }}) (interface{}, error)
+{{ end }}	}
+
+	// {{ $private }}Enrichment holds the enrichers of the {{ .Resource }} {{ .Action }} action and their caches.
+	{{ $private }}Enrichment struct {
+		enrichers *{{ .Name }}Enrichers
+		caches    map[string]*enrich.Cache
+	}
+
+	// Private type used to store the {{ .Resource }} {{ .Action }} enrichers in the service context
+	{{ $private }}EnrichersKey struct{}
+)
+
+// Use{{ .Name }}Enrichers mounts the functions that look up the values derived from the params of
+// the {{ .Resource }} {{ .Action }} action before the controller runs.
+func Use{{ .Name }}Enrichers(service *goa.Service, enrichers *{{ .Name }}Enrichers) {
+	e := &{{ $private }}Enrichment{enrichers: enrichers, caches: map[string]*enrich.Cache{
+{{ range .Enrichments }}		{{ printf "%q" .Name }}: enrich.NewCache({{ durationCode .TTL }}),
+{{ end }}	}}
+	service.Context = context.WithValue(service.Context, {{ $private }}EnrichersKey{}, e)
+}
+
+// enrich{{ .Name }} looks up the values derived from the params of the {{ .Resource }} {{ .Action }}
+// action using the enrichers mounted with Use{{ .Name }}Enrichers if any.
+func enrich{{ .Name }}(rctx *{{ .Context }}) error {
+	e, ok := rctx.Context.Value({{ $private }}EnrichersKey{}).(*{{ $private }}Enrichment)
+	if !ok {
+		return nil
+	}
+{{ range .Enrichments }}	if e.enrichers.{{ .Field }} != nil{{ if .Pointer }} && rctx.{{ .ParamField }} != nil{{ end }} {
+		{{ .ParamVar }} := rctx.{{ .ParamField }}
+		v, err := e.caches[{{ printf "%q" .Name }}].Get(rctx.Context, fmt.Sprint({{ if .Pointer }}*{{ end }}{{ .ParamVar }}), func(ctx context.Context) (interface{}, error) {
+			return e.enrichers.{{ .Field }}(ctx, {{ .ParamVar }})
+		})
+		if err != nil {
+			return err
+		}
+		rctx.Context = enrich.WithValue(rctx.Context, {{ printf "%q" .Name }}, v)
+	}
+{{ end }}	return nil
+}
+{{ range .Enrichments }}
+// {{ .Field }} returns the {{ .Name }} value derived from the {{ .Param }} param, nil if it was not
+// looked up.
+func (ctx *{{ $.Context }}) {{ .Field }}() interface{} {
+	return enrich.Value(ctx.Context, {{ printf "%q" .Name }})
+}
+{{ end }}`
+
+	// contextValuesT generates the context key type and the typed accessors of the context
+	// values.
+	// template input: []map[string]interface{}
+	contextValuesT = `// contextValueKey is the type of the keys used to store the context values.
+type contextValueKey int
+
+const (
+{{ range $i, $v := . }}	// ctx{{ $v.Field }}Key is the key used to store the {{ $v.Name }} context value.
+	ctx{{ $v.Field }}Key{{ if eq $i 0 }} contextValueKey = iota + 1{{ end }}
+{{ end }})
+{{ range . }}
+// With{{ .Field }} returns a copy of ctx that holds the {{ .Name }} context value{{ if .Description }}: {{ .Description }}{{ end }}.
+func With{{ .Field }}(ctx context.Context, v {{ .Type }}) context.Context {
+	return context.WithValue(ctx, ctx{{ .Field }}Key, v)
+}
+
+// Context{{ .Field }} returns the {{ .Name }} context value stored in ctx with With{{ .Field }}, ok is
+// false if ctx does not hold the value.
+func Context{{ .Field }}(ctx context.Context) (v {{ .Type }}, ok bool) {
+	v, ok = ctx.Value(ctx{{ .Field }}Key).({{ .Type }})
+	return
+}
+{{ end }}`
+
+	// fingerprintT generates the design fingerprint constant.
+	// template input: string
+	fingerprintT = `// DesignFingerprint is the fingerprint of the design this package was generated from. Pass it
+// to the service CheckDesign method together with the fingerprints of the other generated
+// artifacts to detect drift when the service starts.
+const DesignFingerprint = {{ printf "%q" . }}
 `
 
 	// handleCORST generates the code that checks whether a CORS request is authorized
@@ -762,28 +2276,58 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 
 	// unmarshalT generates the code for an action payload unmarshal function.
 	// template input: *ControllerTemplateData
-	unmarshalT = `{{ range .Actions }}{{ if .Payload }}
+	unmarshalT = `{{ range .Actions }}{{ if and .Payload (not .Streaming) }}
 // {{ .Unmarshal }} unmarshals the request body into the context request data Payload field.
 func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Request) error {
-	{{ if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
-	if err := service.DecodeRequest(req, payload); err != nil {
+{{ if .DigestAlgorithm }}	digest, err := goa.NewDigestReader(req, {{ printf "%q" .DigestAlgorithm }})
+	if err != nil {
+		return err
+	}
+	req.Body = digest
+{{ end }}	{{ if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
+	if err := {{ if .Multipart }}service.DecodeMultipartForm(ctx, req, payload, {{ .FileLimits }}){{ else }}service.DecodeRequest(req, payload){{ end }}; err != nil {
 		return err
 	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
 	payload.Finalize(){{ end }}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
 	if err := service.DecodeRequest(req, &payload); err != nil {
 		return err
-	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}
-	if err := payload.Validate(); err != nil {
-		// Initialize payload with private data structure so it can be logged
-		goa.ContextRequest(ctx).Payload = payload
+	}{{ end }}{{ if .DigestAlgorithm }}
+	if err := digest.Verify(); err != nil {
 		return err
-	}{{ end }}
+	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}
+	goa.ContextRequest(ctx).DeferPayloadValidation(func() error {
+		if err := payload.Validate(); err != nil {
+			// Initialize payload with private data structure so it can be logged
+			goa.ContextRequest(ctx).Payload = payload
+			return err
+		}
+		return nil
+	}){{ end }}
 	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
 	return nil
 }
 {{ end }}
 {{ end }}`
 
+	// validatorT generates the code for a standalone payload validation function.
+	// template input: *design.UserTypeDefinition
+	validatorT = `{{ $typeName := gotypename . nil 1 false }}// Validate{{ $typeName }} decodes the JSON document b into a {{ $typeName }}, sets the
+// default values and runs the validation rules defined in the design. It makes it possible to
+// validate request bodies outside of the goa handlers, see middleware.ValidatePayload.
+func Validate{{ $typeName }}(b []byte) error {
+	{{ if .IsObject }}payload := &{{ gotypename . nil 1 true }}{}
+	if err := json.Unmarshal(b, payload); err != nil {
+		return goa.ErrInvalidEncoding(err)
+	}{{ $assignment := finalizeCode .AttributeDefinition "payload" 1 }}{{ if $assignment }}
+	payload.Finalize(){{ end }}{{ else }}var payload {{ gotypename . nil 1 false }}
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return goa.ErrInvalidEncoding(err)
+	}{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "payload" "raw" 1 .IsObject }}{{ if $validation }}
+	return payload.Validate(){{ else }}
+	return nil{{ end }}
+}
+`
+
 	// resourceT generates the code for a resource.
 	// template input: *ResourceData
 	resourceT = `{{ if .CanonicalTemplate }}// {{ .Name }}Href returns the resource href.
@@ -800,7 +2344,8 @@ func {{ .Name }}Href({{ if .CanonicalParams }}{{ join .CanonicalParams ", " }} i
 //
 // Identifier: {{ .Identifier }}{{ $typeName := gotypename . .AllRequired 0 false }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-
+{{ $marshaler := nullableMarshaler .AttributeDefinition $typeName "mt" }}{{ if $marshaler }}
+{{ $marshaler }}{{ end }}
 {{ $validation := validationCode .AttributeDefinition false false false "mt" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} media type instance.
 func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
@@ -809,11 +2354,122 @@ func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ end }}
 `
 
+	// importedMediaTypeT generates the aliases of the types of a media type generated in another
+	// package.
+	// template input: map[string]interface{}
+	importedMediaTypeT = `// {{ gotypedesc .MediaType true }}
+//
+// Identifier: {{ .MediaType.Identifier }}
+type ({{ range .Names }}
+	{{ . }} = {{ $.Package }}.{{ . }}{{ end }}
+)
+`
+
+	// computedRegistrationT generates the registration functions of the computed attributes of
+	// a media type.
+	// template input: *design.MediaTypeDefinition
+	computedRegistrationT = `{{ define "computeFunc" }}func({{ $obj := .MediaType.Type.ToObject }}{{ range $i, $f := .Attribute.ComputedFrom }}{{/*
+*/}}{{ if $i }}, {{ end }}{{ goify $f false }} {{ if $.MediaType.IsPrimitivePointer $f }}*{{ end }}{{ gotyperef (index $obj $f).Type nil 0 false }}{{/*
+*/}}{{ end }}) {{ gotyperef .Attribute.Type nil 0 false }}{{ end }}{{/*
+*/}}{{ $mt := . }}{{ $typeName := gotypename . .AllRequired 0 false }}{{ if .IsObject }}{{ range $name, $att := .Type.ToObject }}{{ if $att.ComputedFrom }}{{/*
+*/}}{{ $field := goifyatt $att $name true }}{{ $data := newComputeData $mt $att }}
+// compute{{ $typeName }}{{ $field }} is the function registered with Register{{ $typeName }}{{ $field }}.
+var compute{{ $typeName }}{{ $field }} {{ template "computeFunc" $data }}
+
+// Register{{ $typeName }}{{ $field }} registers the function that computes the {{ $name }} attribute of the
+// {{ $typeName }} media type from its {{ join $att.ComputedFrom ", " }} attributes. The function is invoked
+// by the response helpers prior to rendering.
+func Register{{ $typeName }}{{ $field }}(fn {{ template "computeFunc" $data }}) {
+	compute{{ $typeName }}{{ $field }} = fn
+}
+{{ end }}{{ end }}{{ end }}`
+
+	// computeT generates the method that sets the computed attributes of a media type view.
+	// template input: map[string]interface{}
+	computeT = `{{ $p := .Projected }}{{ if hasComputed $p }}{{ $typeName := gotypename .MediaType .MediaType.AllRequired 0 false }}{{/*
+*/}}{{ $obj := $p.Type.ToObject }}
+// Compute sets the computed attributes of the media type using the registered functions.
+func (mt {{ gotyperef $p $p.AllRequired 0 false }}) Compute() {
+	if mt == nil {
+		return
+	}
+{{ range $name, $att := $obj }}{{ if computable $p $name }}{{ $field := goifyatt $att $name true }}{{/*
+*/}}	if compute{{ $typeName }}{{ $field }} != nil {
+		v := compute{{ $typeName }}{{ $field }}({{ range $i, $f := $att.ComputedFrom }}{{ if $i }}, {{ end }}mt.{{ goifyatt (index $obj $f) $f true }}{{ end }})
+		mt.{{ $field }} = {{ if $p.IsPrimitivePointer $name }}&{{ end }}v
+	}
+{{ end }}{{ end }}}
+{{ end }}`
+
+	// restrictT generates the method that omits the attributes of a media type view that are not
+	// visible to the caller.
+	// template input: map[string]interface{}
+	restrictT = `{{ $p := .Projected }}{{ if hasRestricted $p }}
+// Restrict omits the attributes that are not visible to callers granted the given scopes.
+func (mt {{ gotyperef $p $p.AllRequired 0 false }}) Restrict(scopes []string) {
+	if mt == nil {
+		return
+	}
+{{ range $name, $att := $p.Type.ToObject }}{{ if $att.VisibleTo }}	if !goa.HasScope(scopes{{ range $att.VisibleTo }}, {{ printf "%q" . }}{{ end }}) {
+		mt.{{ goifyatt $att $name true }} = nil
+	}
+{{ else if restrictable $att }}	mt.{{ goifyatt $att $name true }}.Restrict(scopes)
+{{ else if restrictableElem $att }}	for _, e := range mt.{{ goifyatt $att $name true }} {
+		e.Restrict(scopes)
+	}
+{{ end }}{{ end }}}
+{{ end }}`
+
+	// embedT generates the method that omits the embeddable relations of a media type view that
+	// were not requested.
+	// template input: map[string]interface{}
+	embedT = `{{ $p := .Projected }}{{ if hasEmbeddable $p }}{{ $obj := $p.Type.ToObject }}
+// Embed omits the embeddable relations that are not listed in expand.
+func (mt {{ gotyperef $p $p.AllRequired 0 false }}) Embed(expand []string) {
+	if mt == nil {
+		return
+	}
+{{ range $p.Embeddables }}	if !goa.IsExpanded(expand, {{ printf "%q" . }}) {
+		mt.{{ goifyatt (index $obj .) . true }} = nil
+	}
+{{ end }}}
+{{ end }}`
+
+	// geoJSONT generates the methods that render a media type view or collection as GeoJSON.
+	// template input: map[string]interface{}
+	geoJSONT = `{{ $p := .Projected }}{{ if $p.Geometry }}{{ $obj := $p.Type.ToObject }}{{/*
+*/}}{{ $field := goifyatt (index $obj $p.Geometry) $p.Geometry true }}
+// Feature returns the GeoJSON feature that represents the media type, the feature geometry is the
+// {{ $p.Geometry }} attribute.
+func (mt {{ gotyperef $p $p.AllRequired 0 false }}) Feature() *goa.Feature {
+	var geometry *goa.Geometry
+	if mt.{{ $field }} != nil {
+		geometry = mt.{{ $field }}.Geometry()
+	}
+	return goa.NewFeature(nil, geometry, mt)
+}
+
+// GeoJSON returns the GeoJSON feature that represents the media type.
+func (mt {{ gotyperef $p $p.AllRequired 0 false }}) GeoJSON() interface{} {
+	return mt.Feature()
+}
+{{ else if hasGeometryElem $p }}
+// GeoJSON returns the GeoJSON feature collection that represents the media type.
+func (mt {{ gotyperef $p $p.AllRequired 0 false }}) GeoJSON() interface{} {
+	features := make([]*goa.Feature, len(mt))
+	for i, e := range mt {
+		features[i] = e.Feature()
+	}
+	return goa.NewFeatureCollection(features...)
+}
+{{ end }}`
+
 	// mediaTypeLinkT generates the code for a media type link.
 	// template input: MediaTypeLinkTemplateData
 	mediaTypeLinkT = `// {{ gotypedesc . true }}{{ $typeName := gotypename . .AllRequired 0 false }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ $marshaler := nullableMarshaler .AttributeDefinition $typeName "ut" }}{{ if $marshaler }}
+{{ $marshaler }}{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
@@ -843,13 +2499,236 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllR
 
 // {{ gotypedesc . true }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ $marshaler := nullableMarshaler .AttributeDefinition $typeName "ut" }}{{ if $marshaler }}
+{{ $marshaler }}{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
 `
 
+	// enumTypeT generates the code for an enum user type.
+	// template input: *design.UserTypeDefinition
+	enumTypeT = `{{ $typeName := gotypename . nil 0 false }}{{ $consts := enumConstants . }}{{/*
+*/}}// {{ gotypedesc . true }}
+type {{ $typeName }} {{ gotypedef .AttributeDefinition 0 false false }}
+
+// Values of the {{ $typeName }} type.
+const (
+{{ range $consts }}	{{ .Name }} {{ $typeName }} = {{ .Value }}
+{{ end }})
+
+// IsValid returns true if the value is one of the {{ $typeName }} values.
+func (e {{ $typeName }}) IsValid() bool {
+	switch e {
+	case {{ range $i, $c := $consts }}{{ if $i }}, {{ end }}{{ $c.Name }}{{ end }}:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the value.
+func (e {{ $typeName }}) String() string {
+	return {{ if eq .Type.Kind 4 }}string(e){{ else }}fmt.Sprint({{ gotypedef .AttributeDefinition 0 false false }}(e)){{ end }}
+}
+`
+
+	// flagsTypeT generates the code for a flags user type.
+	// template input: map[string]interface{}
+	flagsTypeT = `{{ $typeName := gotypename .Type nil 0 false }}{{ $names := printf "%sFlagNames" (goify .Type.TypeName false) }}{{/*
+*/}}// {{ gotypedesc .Type true }}
+type {{ $typeName }} uint64
+
+// Flags of the {{ $typeName }} type.
+const (
+{{ range .Constants }}	{{ .Name }} {{ $typeName }} = {{ .Value }}
+{{ end }})
+
+// {{ $names }} lists the names of the {{ $typeName }} flags in bit order.
+var {{ $names }} = {{ printf "%#v" .Type.Flags }}
+
+// Has returns true if all the given flags are set.
+func (f {{ $typeName }}) Has(flags {{ $typeName }}) bool {
+	return f&flags == flags
+}
+
+// Set returns the value with the given flags set.
+func (f {{ $typeName }}) Set(flags {{ $typeName }}) {{ $typeName }} {
+	return f | flags
+}
+
+// Clear returns the value with the given flags cleared.
+func (f {{ $typeName }}) Clear(flags {{ $typeName }}) {{ $typeName }} {
+	return f &^ flags
+}
+
+// Names returns the names of the flags that are set.
+func (f {{ $typeName }}) Names() []string {
+	return goa.FlagNames(uint64(f), {{ $names }})
+}
+
+// String returns the names of the flags that are set separated with "|".
+func (f {{ $typeName }}) String() string {
+	return goa.FormatFlags(uint64(f), {{ $names }})
+}
+{{ if .Array }}
+// MarshalJSON encodes the value as the array of the names of the flags that are set.
+func (f {{ $typeName }}) MarshalJSON() ([]byte, error) {
+	return goa.MarshalFlags(uint64(f), {{ $names }})
+}
+
+// UnmarshalJSON decodes the array of the names of the flags that are set, it fails if one of the
+// names is not the name of a {{ $typeName }} flag.
+func (f *{{ $typeName }}) UnmarshalJSON(data []byte) error {
+	v, err := goa.UnmarshalFlags(data, {{ $names }})
+	if err != nil {
+		return err
+	}
+	*f = {{ $typeName }}(v)
+	return nil
+}
+{{ end }}`
+
+	// oneOfTypeT generates the code for a OneOf user type.
+	// template input: map[string]interface{}
+	oneOfTypeT = `{{ $ut := .Type }}{{ $members := .Members }}{{ $privateTypeName := gotypename $ut nil 0 true }}{{/*
+*/}}{{ $typeName := gotypename $ut nil 0 false }}{{/*
+*/}}{{ $hasFinalize := false }}{{ range $members }}{{ if .Finalize }}{{ $hasFinalize = true }}{{ end }}{{ end }}{{/*
+*/}}// {{ gotypedesc $ut false }}
+type {{ $privateTypeName }} struct {
+{{ range $members }}	{{ .Field }} {{ gotyperef .Type nil 0 true }}
+{{ end }}	// discriminatorErr is the validation error of the discriminator of the decoded value.
+	discriminatorErr error
+}
+
+// UnmarshalJSON decodes the member value identified by the "{{ $ut.Discriminator }}" attribute. A
+// missing or unknown discriminator is reported by Validate.
+func (ut *{{ $privateTypeName }}) UnmarshalJSON(data []byte) error {
+	d, err := goa.DecodeDiscriminator(data, ` + "`request`" + `, "{{ $ut.Discriminator }}")
+	*ut = {{ $privateTypeName }}{discriminatorErr: err}
+	if err != nil {
+		return nil
+	}
+	switch d {
+{{ range $members }}	case {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ printf "%#v" $v }}{{ end }}:
+		ut.{{ .Field }} = &{{ gotypename .Type nil 0 true }}{}
+		return json.Unmarshal(data, ut.{{ .Field }})
+{{ end }}	}
+	ut.discriminatorErr = goa.InvalidEnumValueError(` + "`request.{{ $ut.Discriminator }}`" + `, d, []interface{}{ {{/*
+*/}}{{ range $i, $m := $members }}{{ range $j, $v := $m.Values }}{{ if or $i $j }}, {{ end }}{{ printf "%#v" $v }}{{ end }}{{ end }} })
+	return nil
+}
+{{ if $hasFinalize }}
+// Finalize sets the default values of the member value.
+func (ut *{{ $privateTypeName }}) Finalize() {
+{{ range $members }}{{ if .Finalize }}	if ut.{{ .Field }} != nil {
+		ut.{{ .Field }}.Finalize()
+	}
+{{ end }}{{ end }}}
+{{ end }}
+// Validate validates the {{ $privateTypeName }} type instance.
+func (ut *{{ $privateTypeName }}) Validate() (err error) {
+	if ut.discriminatorErr != nil {
+		return ut.discriminatorErr
+	}
+	var n int
+{{ range $members }}	if ut.{{ .Field }} != nil {
+		n++{{ if .ValidatePrivate }}
+		if err2 := ut.{{ .Field }}.Validate(); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}{{ end }}
+	}
+{{ end }}	if n != 1 {
+		err = goa.MergeErrors(err, goa.InvalidOneOfError(` + "`" + `request` + "`" + `, n, []string{ {{/*
+*/}}{{ range $i, $m := $members }}{{ if $i }}, {{ end }}"{{ $m.Type.TypeName }}"{{ end }} }))
+	}
+	return
+}
+
+// Publicize creates {{ $typeName }} from {{ $privateTypeName }}
+func (ut *{{ $privateTypeName }}) Publicize() *{{ $typeName }} {
+	var pub {{ $typeName }}
+{{ range $members }}	if ut.{{ .Field }} != nil {
+		pub.{{ .Field }} = ut.{{ .Field }}.Publicize()
+	}
+{{ end }}	return &pub
+}
+
+// {{ gotypedesc $ut true }}
+type {{ $typeName }} struct {
+{{ range $members }}	// {{ .Field }} is set when the value is a {{ .Type.TypeName }}.
+	{{ .Field }} {{ gotyperef .Type nil 0 false }}
+{{ end }}	// discriminatorErr is the validation error of the discriminator of the decoded value.
+	discriminatorErr error
+}
+
+// MarshalJSON encodes the member value.
+func (ut {{ $typeName }}) MarshalJSON() ([]byte, error) {
+{{ range $members }}	if ut.{{ .Field }} != nil {
+		return json.Marshal(ut.{{ .Field }})
+	}
+{{ end }}	return []byte("null"), nil
+}
+
+// UnmarshalJSON decodes the member value identified by the "{{ $ut.Discriminator }}" attribute. A
+// missing or unknown discriminator is reported by Validate.
+func (ut *{{ $typeName }}) UnmarshalJSON(data []byte) error {
+	d, err := goa.DecodeDiscriminator(data, ` + "`type`" + `, "{{ $ut.Discriminator }}")
+	*ut = {{ $typeName }}{discriminatorErr: err}
+	if err != nil {
+		return nil
+	}
+	switch d {
+{{ range $members }}	case {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ printf "%#v" $v }}{{ end }}:
+		ut.{{ .Field }} = &{{ gotypename .Type nil 0 false }}{}
+		return json.Unmarshal(data, ut.{{ .Field }})
+{{ end }}	}
+	ut.discriminatorErr = goa.InvalidEnumValueError(` + "`type.{{ $ut.Discriminator }}`" + `, d, []interface{}{ {{/*
+*/}}{{ range $i, $m := $members }}{{ range $j, $v := $m.Values }}{{ if or $i $j }}, {{ end }}{{ printf "%#v" $v }}{{ end }}{{ end }} })
+	return nil
+}
+
+// Validate validates the {{ $typeName }} type instance.
+func (ut *{{ $typeName }}) Validate() (err error) {
+	if ut.discriminatorErr != nil {
+		return ut.discriminatorErr
+	}
+	var n int
+{{ range $members }}	if ut.{{ .Field }} != nil {
+		n++{{ if .ValidatePublic }}
+		if err2 := ut.{{ .Field }}.Validate(); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		}{{ end }}
+	}
+{{ end }}	if n != 1 {
+		err = goa.MergeErrors(err, goa.InvalidOneOfError(` + "`" + `type` + "`" + `, n, []string{ {{/*
+*/}}{{ range $i, $m := $members }}{{ if $i }}, {{ end }}"{{ $m.Type.TypeName }}"{{ end }} }))
+	}
+	return
+}
+`
+
+	// transitionsT generates the state transition checks of the attributes of a user type.
+	// template input: map[string]interface{}
+	transitionsT = `{{ $ut := .Type }}{{ if $ut.IsObject }}{{ range $name, $att := $ut.Type.ToObject }}{{ if $att.Transitions }}{{/*
+*/}}{{ $field := goifyatt $att $name true }}
+// Validate{{ $field }}Transition checks that the value of {{ $name }} is a state that can be reached
+// from the given state and returns an invalid_transition error listing the allowed next states
+// otherwise.
+func ({{ $.Receiver }} {{ gotyperef $ut $ut.AllRequired 0 false }}) Validate{{ $field }}Transition(from string) error {
+	transitions := {{ printf "%#v" $att.Transitions }}
+{{ if $ut.IsPrimitivePointer $name }}	if {{ $.Receiver }}.{{ $field }} == nil {
+		return nil
+	}
+	to := *{{ $.Receiver }}.{{ $field }}
+{{ else }}	to := {{ $.Receiver }}.{{ $field }}
+{{ end }}	if !goa.ValidateTransition(transitions, from, to) {
+		return goa.InvalidTransitionError({{ printf "%q" $name }}, from, to, transitions[from])
+	}
+	return nil
+}
+{{ end }}{{ end }}{{ end }}`
+
 	// securitySchemesT generates the code for the security module.
 	// template input: []*design.SecuritySchemeDefinition
 	securitySchemesT = `
@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -50,7 +51,7 @@ var _ = Describe("ContextsWriter", func() {
 		})
 
 		Context("with data", func() {
-			var params, headers *design.AttributeDefinition
+			var params, headers, cookies *design.AttributeDefinition
 			var payload *design.UserTypeDefinition
 			var responses map[string]*design.ResponseDefinition
 			var routes []*design.RouteDefinition
@@ -60,6 +61,7 @@ var _ = Describe("ContextsWriter", func() {
 			BeforeEach(func() {
 				params = nil
 				headers = nil
+				cookies = nil
 				payload = nil
 				responses = nil
 				routes = nil
@@ -74,6 +76,7 @@ var _ = Describe("ContextsWriter", func() {
 					Params:       params,
 					Payload:      payload,
 					Headers:      headers,
+					Cookies:      cookies,
 					Responses:    responses,
 					Routes:       routes,
 					API:          design.Design,
@@ -94,6 +97,223 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with range requests enabled", func() {
+				It("writes the ServeContent helper", func() {
+					data.AcceptRanges = true
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) ServeContent(name string, modtime time.Time, r io.ReadSeeker) error {"))
+					Ω(written).Should(ContainSubstring("http.ServeContent(ctx.ResponseData, ctx.Request, name, modtime, r)"))
+				})
+			})
+
+			Context("with optimistic concurrency control", func() {
+				It("writes the version precondition check", func() {
+					data.VersionAttribute = "version"
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("ExpectedVersion string"))
+					Ω(written).Should(ContainSubstring(`return nil, goa.ErrPreconditionRequired("missing required If-Match header", "attribute", "version")`))
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) CheckVersion(current string) error {"))
+				})
+			})
+
+			Context("with page pagination", func() {
+				It("writes the Paginate helper", func() {
+					data.Pagination = &design.PaginationDefinition{Style: design.PagePagination}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) Paginate(total int) {"))
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Link", goa.PageLinks(ctx.Request.URL, ctx.Page, ctx.PerPage, total))`))
+				})
+			})
+
+			Context("with cursor pagination", func() {
+				It("writes the Paginate helper", func() {
+					data.Pagination = &design.PaginationDefinition{Style: design.CursorPagination}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) Paginate(next string) {"))
+					Ω(written).Should(ContainSubstring("if link := goa.CursorLinks(ctx.Request.URL, next); link != \"\" {"))
+				})
+			})
+
+			Context("with a parent resource", func() {
+				It("writes the parent key helper", func() {
+					key := &design.AttributeDefinition{Type: design.Object{
+						"accountID": {Type: design.Integer},
+					}}
+					data.Params = key
+					data.ParentKey = key
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) ParentKey() *BottlesParentKey {"))
+					Ω(written).Should(ContainSubstring("AccountID: ctx.AccountID,"))
+				})
+			})
+
+			Context("with websocket messages", func() {
+				It("writes the inbound message type and the message helpers", func() {
+					data.Messages = &design.WebSocketDefinition{
+						Inbound: &design.UserTypeDefinition{
+							TypeName: "ListBottleInboundMessage",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"text": &design.AttributeDefinition{Type: design.String},
+								},
+								Validation: &dslengine.ValidationDefinition{Required: []string{"text"}},
+							},
+						},
+					}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("type listBottleInboundMessage struct {"))
+					Ω(written).Should(ContainSubstring("type ListBottleInboundMessage struct {"))
+					Ω(written).Should(ContainSubstring(inboundMessageReceive))
+					Ω(written).ShouldNot(ContainSubstring("SendMessage"))
+				})
+			})
+
+			Context("with responses declaring embeddable relations", func() {
+				It("writes the expand parameter parsing", func() {
+					data.Embeddables = []string{"account", "owner"}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("Expand []string"))
+					Ω(written).Should(ContainSubstring(`if expand, err2 := goa.ParseExpand(req.Params["expand"], "account", "owner"); err2 == nil {`))
+				})
+			})
+
+			Context("with a resumable upload action", func() {
+				It("writes the Upload field", func() {
+					data.Resumable = true
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("Upload *tus.Upload"))
+					Ω(written).Should(ContainSubstring("rctx.Upload = tus.ContextUpload(ctx)"))
+				})
+			})
+
+			Context("with a file download response", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					params = &design.AttributeDefinition{Type: design.Object{
+						"id": {Type: design.Integer},
+					}}
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: "text/csv",
+						Filename:  "report-{id}.csv",
+					}}
+				})
+
+				It("writes the Content-Disposition header", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Content-Type", "text/csv")`))
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Content-Disposition", goa.AttachmentDisposition(fmt.Sprintf("report-%v.csv", ctx.ID)))`))
+				})
+			})
+
+			Context("with a redirect response", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					params = &design.AttributeDefinition{Type: design.Object{
+						"id": {Type: design.Integer},
+					}}
+					responses = map[string]*design.ResponseDefinition{"MovedPermanently": {
+						Name:     "MovedPermanently",
+						Status:   301,
+						Location: "/v2/users/:id",
+					}}
+				})
+
+				It("writes the Location header", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) MovedPermanently() error {\n"))
+					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Location", fmt.Sprintf("/v2/users/%v", ctx.ID))`))
+					Ω(written).Should(ContainSubstring("ctx.ResponseData.WriteHeader(301)"))
+				})
+			})
+
+			Context("with response headers computed from the media type attributes", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":   {Type: design.Integer},
+									"name": {Type: design.String},
+								},
+								Validation: &dslengine.ValidationDefinition{Required: []string{"id"}},
+							},
+							TypeName: "Order",
+						},
+						Identifier: "application/vnd.goa.order",
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": {
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{"Created": {
+						Name:      "Created",
+						Status:    201,
+						MediaType: mediaType.Identifier,
+						HeaderTemplates: map[string]string{
+							"Location": "/orders/{id}",
+							"X-Name":   "{name}",
+						},
+					}}
+				})
+
+				It("sets the headers from the response body", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(headerTemplatesResponse))
+				})
+			})
+
 			Context("with a media type setting a ContentType", func() {
 				var contentType = "application/json"
 
@@ -134,6 +354,51 @@ var _ = Describe("ContextsWriter", func() {
 					Ω(written).ShouldNot(BeEmpty())
 					Ω(written).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Content-Type", "` + contentType + `")`))
 				})
+
+				Context("with HTML rendering enabled", func() {
+					It("sends the response using the media type and view templates", func() {
+						data.RenderHTML = true
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`return ctx.ResponseData.Service.SendHTML(ctx.Context, 200, "application/vnd.goa.test", "default", r)`))
+						Ω(written).ShouldNot(ContainSubstring("Service.Send(ctx.Context, 200, r)"))
+					})
+				})
+
+				Context("with a streaming response", func() {
+					It("writes the event stream helpers", func() {
+						data.Responses["OK"].Streaming = true
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(streamingResponse))
+						Ω(written).Should(ContainSubstring("return s.EventStream.Send(event, r)"))
+						Ω(written).ShouldNot(ContainSubstring("Service.Send(ctx.Context, 200, r)"))
+					})
+				})
+
+				Context("with a delta stream response", func() {
+					It("writes the delta helpers", func() {
+						data.Responses["OK"].Streaming = true
+						data.Responses["OK"].Deltas = true
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(streamingResponse))
+						Ω(written).Should(ContainSubstring(deltaStreamHelpers))
+						Ω(written).Should(ContainSubstring("return s.send(goa.DeltaCreate, token, r)"))
+						Ω(written).Should(ContainSubstring("return s.send(goa.DeltaDelete, token, r)"))
+						Ω(written).Should(ContainSubstring(`return s.EventStream.SendDelta(op, "application/vnd.goa.test", token, r)`))
+						Ω(written).ShouldNot(ContainSubstring("return s.EventStream.Send(event, r)"))
+					})
+				})
 			})
 
 			Context("with a collection media type", func() {
@@ -178,6 +443,19 @@ var _ = Describe("ContextsWriter", func() {
 	}
 	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
 				})
+
+				Context("with a streaming response", func() {
+					It("writes the response helper that returns the body writer", func() {
+						data.Responses["OK"].Chunked = true
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(chunkedResponse))
+						Ω(written).ShouldNot(ContainSubstring("Service.Send(ctx.Context, 200, r)"))
+					})
+				})
 			})
 
 			Context("with an integer param", func() {
@@ -654,6 +932,21 @@ var _ = Describe("ContextsWriter", func() {
 					})
 				})
 
+				Context("with a csv collection format", func() {
+					BeforeEach(func() {
+						arrayParam.CollectionFormat = design.CollectionFormatCSV
+					})
+
+					It("splits the param values", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(intArrayCSVContextFactory))
+					})
+				})
+
 				Context("with required attribute", func() {
 					BeforeEach(func() {
 						validation.Required = []string{"param"}
@@ -767,6 +1060,57 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a deep object param", func() {
+				BeforeEach(func() {
+					filter := &design.UserTypeDefinition{
+						TypeName: "Filter",
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"status": &design.AttributeDefinition{Type: design.String},
+								"tags":   &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+							},
+							Validation: &dslengine.ValidationDefinition{Required: []string{"status"}},
+						},
+					}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"filter": &design.AttributeDefinition{Type: filter}},
+					}
+				})
+
+				It("decodes the param from the query string", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("Filter *Filter"))
+					Ω(written).Should(ContainSubstring(`if rawFilter := goa.DeepObjectParams(req.Params, "filter"); len(rawFilter) > 0 {`))
+					Ω(written).Should(ContainSubstring("paramFilter := &filter{}"))
+					Ω(written).Should(ContainSubstring("goa.DecodeDeepObject(rawFilter, paramFilter)"))
+					Ω(written).Should(ContainSubstring("goa.WithErrorContext(err2, `filter`)"))
+					Ω(written).Should(ContainSubstring("rctx.Filter = paramFilter.Publicize()"))
+					Ω(written).ShouldNot(ContainSubstring("MissingParamError"))
+				})
+			})
+
+			Context("with a Near param", func() {
+				BeforeEach(func() {
+					params = &design.AttributeDefinition{
+						Type: design.Object{"near": &design.AttributeDefinition{Type: design.Proximity}},
+					}
+				})
+
+				It("validates the param in its context", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("paramNear := &goa.Proximity{}"))
+					Ω(written).Should(ContainSubstring("if err2 := paramNear.ValidateAt(`near`); err2 != nil {"))
+				})
+			})
+
 			Context("with a string header", func() {
 				BeforeEach(func() {
 					strHeader := &design.AttributeDefinition{Type: design.String}
@@ -790,6 +1134,56 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a required header with a format validation", func() {
+				BeforeEach(func() {
+					uuidHeader := &design.AttributeDefinition{
+						Type:       design.String,
+						Validation: &dslengine.ValidationDefinition{Format: "uuid"},
+					}
+					headers = &design.AttributeDefinition{
+						Type:       design.Object{"X-Request-Id": uuidHeader},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"X-Request-Id"}},
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(requiredHeaderContextFactory))
+				})
+			})
+
+			Context("with cookies", func() {
+				BeforeEach(func() {
+					minLength := 32
+					cookies = &design.AttributeDefinition{
+						Type: design.Object{
+							"session": &design.AttributeDefinition{
+								Type:       design.String,
+								Validation: &dslengine.ValidationDefinition{MinLength: &minLength},
+							},
+							"visits": &design.AttributeDefinition{Type: design.Integer},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"session"}},
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(cookiesContext))
+					Ω(written).Should(ContainSubstring(cookiesContextFactory))
+				})
+			})
+
 			Context("with a string header and param with the same name", func() {
 				BeforeEach(func() {
 					str := &design.AttributeDefinition{Type: design.String}
@@ -993,11 +1387,227 @@ var _ = Describe("ControllersWriter", func() {
 			})
 		})
 
+		Context("with a replay protected action", func() {
+			var api *design.APIDefinition
+
+			BeforeEach(func() {
+				api = &design.APIDefinition{
+					Resources: map[string]*design.ResourceDefinition{
+						"transfers": {
+							Name: "transfers",
+							Actions: map[string]*design.ActionDefinition{
+								"create": {Name: "create", ReplayProtected: true},
+							},
+						},
+					},
+				}
+			})
+
+			It("writes the replay protection middleware helpers", func() {
+				err := writer.WriteActionMiddleware(api)
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(b)
+				Ω(written).Should(ContainSubstring("func UseReplayProtectionMiddleware(service *goa.Service, middleware goa.Middleware) {"))
+				Ω(written).Should(ContainSubstring("func handleReplayProtection(h goa.Handler) goa.Handler {"))
+			})
+
+			Context("with an action declaring route aliases", func() {
+				BeforeEach(func() {
+					route := &design.RouteDefinition{Verb: "POST", Path: "/transfers"}
+					route.Aliases = []*design.RouteAliasDefinition{
+						{Path: "/payments", Policy: design.AliasRedirect, Parent: route},
+						{Path: "/v0/transfers", Policy: design.AliasGone, Parent: route},
+					}
+					api.Resources["transfers"].Actions["create"].ReplayProtected = false
+					api.Resources["transfers"].Actions["create"].Routes = []*design.RouteDefinition{route}
+				})
+
+				It("writes the alias handlers", func() {
+					err := writer.WriteActionMiddleware(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func handleAliasRedirect(path string) goa.Handler {"))
+					Ω(written).Should(ContainSubstring("http.Redirect(rw, req, target, http.StatusMovedPermanently)"))
+					Ω(written).Should(ContainSubstring("func handleAliasGone() goa.Handler {"))
+					Ω(written).ShouldNot(ContainSubstring("handleReplayProtection"))
+				})
+			})
+
+			Context("with an action accepting signed URLs", func() {
+				BeforeEach(func() {
+					api.Resources["transfers"].Actions["create"].ReplayProtected = false
+					api.Resources["transfers"].Actions["create"].SignedURL = true
+				})
+
+				It("writes the signed URL middleware helpers", func() {
+					err := writer.WriteActionMiddleware(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func UseSignedURLMiddleware(service *goa.Service, middleware goa.Middleware) {"))
+					Ω(written).Should(ContainSubstring("func handleSignedURL(signed, unsigned goa.Handler) goa.Handler {"))
+					Ω(written).ShouldNot(ContainSubstring("handleReplayProtection"))
+				})
+			})
+
+			Context("with an action whose response declares embeddable relations", func() {
+				BeforeEach(func() {
+					account := &design.MediaTypeDefinition{
+						Identifier: "application/vnd.account",
+						UserTypeDefinition: &design.UserTypeDefinition{
+							TypeName: "Account",
+							AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+								"id":   {Type: design.Integer},
+								"name": {Type: design.String},
+							}},
+						},
+					}
+					api.MediaTypes = map[string]*design.MediaTypeDefinition{
+						"application/vnd.transfer": {
+							Identifier: "application/vnd.transfer",
+							UserTypeDefinition: &design.UserTypeDefinition{
+								TypeName: "Transfer",
+								AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+									"id":      {Type: design.Integer},
+									"account": {Type: account},
+								}},
+							},
+							Embeddables: []string{"account"},
+						},
+					}
+					api.Resources["transfers"].Actions["create"].ReplayProtected = false
+					api.Resources["transfers"].Actions["create"].Responses = map[string]*design.ResponseDefinition{
+						"OK": {Name: "OK", Status: 200, MediaType: "application/vnd.transfer"},
+					}
+				})
+
+				It("writes the query cost middleware helpers", func() {
+					err := writer.WriteActionMiddleware(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func UseQueryCostMiddleware(service *goa.Service, middleware goa.Middleware) {"))
+					Ω(written).Should(ContainSubstring("func handleQueryCost(h goa.Handler, cost *goa.QueryCost) goa.Handler {"))
+				})
+			})
+
+			Context("with an action running a saga", func() {
+				BeforeEach(func() {
+					api.Resources["transfers"].Actions["create"].ReplayProtected = false
+					api.Resources["transfers"].Actions["create"].Saga = &design.SagaDefinition{
+						Name: "transfer",
+						Steps: []*design.SagaStepDefinition{
+							{Name: "debit"},
+							{Name: "credit", Description: "Credit the destination account"},
+						},
+					}
+				})
+
+				It("writes the saga steps and constructor", func() {
+					err := writer.WriteSagas(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("type CreateTransfersSagaSteps struct {"))
+					Ω(written).Should(ContainSubstring("Credit saga.Step"))
+					Ω(written).Should(ContainSubstring("func NewCreateTransfersSaga(steps *CreateTransfersSagaSteps, store saga.Store) *saga.Saga {"))
+					Ω(written).Should(ContainSubstring(`Add("debit", steps.Debit)`))
+				})
+			})
+
+			Context("with an aggregate action", func() {
+				BeforeEach(func() {
+					api.Resources["transfers"].Actions["create"].ReplayProtected = false
+					api.Resources["transfers"].Actions["create"].Aggregate = &design.AggregateDefinition{
+						MediaType: "application/vnd.transfer+json",
+						Branches: []*design.AggregateBranchDefinition{
+							{Name: "account", Resource: "accounts", Action: "show", Timeout: 500 * time.Millisecond, Mappings: []*design.AggregateMappingDefinition{{To: "account"}}},
+							{Name: "rates", Service: "forex", Resource: "rates", Action: "list", Optional: true, Mappings: []*design.AggregateMappingDefinition{{From: "items", To: "rates"}}},
+						},
+					}
+				})
+
+				It("writes the aggregate branches and constructor", func() {
+					err := writer.WriteAggregates(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("type CreateTransfersAggregateBranches struct {"))
+					Ω(written).Should(ContainSubstring("// Rates calls the forex service rates list action, the branch is optional."))
+					Ω(written).Should(ContainSubstring("func NewCreateTransfersAggregate(branches *CreateTransfersAggregateBranches) *aggregate.Aggregate {"))
+					Ω(written).Should(ContainSubstring("Timeout: 500 * time.Millisecond,"))
+					Ω(written).Should(ContainSubstring(`{From: "items", To: "rates"},`))
+				})
+			})
+
+			Context("with an action declaring enrichments", func() {
+				BeforeEach(func() {
+					action := api.Resources["transfers"].Actions["create"]
+					action.ReplayProtected = false
+					action.Params = &design.AttributeDefinition{
+						Type: design.Object{
+							"accountID": &design.AttributeDefinition{Type: design.Integer},
+							"currency":  &design.AttributeDefinition{Type: design.String},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"accountID"}},
+					}
+					action.Enrichments = []*design.EnrichmentDefinition{
+						{Parent: action, Name: "account", Param: "accountID", TTL: time.Minute},
+						{Parent: action, Name: "rate", Param: "currency"},
+					}
+				})
+
+				It("writes the enrichers, lookup and accessors", func() {
+					err := writer.WriteEnrichments(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("Account func(ctx context.Context, accountID int) (interface{}, error)"))
+					Ω(written).Should(ContainSubstring("Rate func(ctx context.Context, currency *string) (interface{}, error)"))
+					Ω(written).Should(ContainSubstring("func UseCreateTransfersEnrichers(service *goa.Service, enrichers *CreateTransfersEnrichers) {"))
+					Ω(written).Should(ContainSubstring(`"account": enrich.NewCache(1 * time.Minute),`))
+					Ω(written).Should(ContainSubstring(`"rate": enrich.NewCache(0),`))
+					Ω(written).Should(ContainSubstring("if e.enrichers.Rate != nil && rctx.Currency != nil {"))
+					Ω(written).Should(ContainSubstring("func (ctx *CreateTransfersContext) Account() interface{} {"))
+				})
+			})
+
+			Context("with no replay protected action", func() {
+				BeforeEach(func() {
+					api.Resources["transfers"].Actions["create"].ReplayProtected = false
+				})
+
+				It("does not write anything", func() {
+					err := writer.WriteActionMiddleware(api)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(BeEmpty())
+				})
+			})
+		})
+
 		Context("with data", func() {
 			var actions, verbs, paths, contexts, unmarshals []string
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
 			var origins []*design.CORSDefinition
+			var parentKey *design.AttributeDefinition
+			var aliases []*design.RouteAliasDefinition
+			var concurrency *design.ConcurrencyDefinition
+			var multipart bool
+			var streaming bool
+			var fileLimits string
+			var patterns []string
 
 			var data []*genapp.ControllerTemplateData
 
@@ -1011,14 +1621,23 @@ var _ = Describe("ControllersWriter", func() {
 				encoders = nil
 				decoders = nil
 				origins = nil
+				parentKey = nil
+				aliases = nil
+				concurrency = nil
+				multipart = false
+				streaming = false
+				fileLimits = "nil"
+				patterns = nil
 			})
 
 			JustBeforeEach(func() {
 				codegen.TempCount = 0
 				api := &design.APIDefinition{}
 				d := &genapp.ControllerTemplateData{
-					Resource: "Bottles",
-					Origins:  origins,
+					Resource:  "Bottles",
+					Origins:   origins,
+					ParentKey: parentKey,
+					Patterns:  patterns,
 				}
 				as := make([]map[string]interface{}, len(actions))
 				for i, a := range actions {
@@ -1030,17 +1649,26 @@ var _ = Describe("ControllersWriter", func() {
 					if i < len(payloads) {
 						payload = payloads[i]
 					}
+					route := &design.RouteDefinition{Verb: verbs[i], Path: paths[i]}
+					if i == 0 {
+						for _, al := range aliases {
+							al.Parent = route
+							route.Aliases = append(route.Aliases, al)
+						}
+					}
 					as[i] = map[string]interface{}{
-						"Name":       codegen.Goify(a, true),
-						"DesignName": a,
-						"Routes": []*design.RouteDefinition{
-							{
-								Verb: verbs[i],
-								Path: paths[i],
-							}},
-						"Context":   contexts[i],
-						"Unmarshal": unmarshal,
-						"Payload":   payload,
+						"Name":         codegen.Goify(a, true),
+						"DesignName":   a,
+						"ResourceName": "bottles",
+						"Routes":       []*design.RouteDefinition{route},
+						"Context":      contexts[i],
+						"Unmarshal":    unmarshal,
+						"Payload":      payload,
+						"ParentKey":    parentKey != nil,
+						"Concurrency":  concurrency,
+						"Multipart":    multipart,
+						"Streaming":    streaming,
+						"FileLimits":   fileLimits,
 					}
 				}
 				if len(as) > 0 {
@@ -1085,6 +1713,94 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with a child resource", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					parentKey = &design.AttributeDefinition{Type: design.Object{
+						"accountID": {Type: design.Integer},
+					}}
+				})
+
+				It("writes the parent resolver", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("if err := resolveBottlesParent(ctx, rctx.ParentKey()); err != nil {"))
+					Ω(written).Should(ContainSubstring("AccountID int"))
+					Ω(written).Should(ContainSubstring("func UseBottlesParentResolver(service *goa.Service, resolver BottlesParentResolver) {"))
+					Ω(written).Should(ContainSubstring(`return goa.ErrNotFound("parent resource not found", "accountID", key.AccountID)`))
+				})
+			})
+
+			Context("with route aliases", func() {
+				BeforeEach(func() {
+					actions = []string{"show"}
+					verbs = []string{"GET"}
+					paths = []string{"/bottles/:id"}
+					contexts = []string{"ShowBottleContext"}
+					aliases = []*design.RouteAliasDefinition{
+						{Path: "/bottle/:id", Policy: design.AliasServe},
+						{Path: "/wines/:id", Policy: design.AliasRedirect},
+						{Path: "/v0/bottles/:id", Policy: design.AliasGone},
+					}
+				})
+
+				It("mounts the aliases", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/bottle/:id", ctrl.MuxHandler("show", h, nil))`))
+					Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/wines/:id", ctrl.MuxHandler("show", handleAliasRedirect("/bottles/:id"), nil))`))
+					Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/v0/bottles/:id", ctrl.MuxHandler("show", handleAliasGone(), nil))`))
+					Ω(written).Should(ContainSubstring(`"alias", "GET /wines/:id", "policy", "redirect"`))
+				})
+			})
+
+			Context("with a maximum concurrency", func() {
+				BeforeEach(func() {
+					actions = []string{"export"}
+					verbs = []string{"POST"}
+					paths = []string{"/bottles/exports"}
+					contexts = []string{"ExportBottleContext"}
+					concurrency = &design.ConcurrencyDefinition{MaxInFlight: 4, QueueSize: 20, QueueTimeout: 10 * time.Second}
+				})
+
+				It("wraps the handler with a concurrency limiter", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`h = goa.NewConcurrencyLimiter("bottles", "export", 4, 20, 10 * time.Second).Handle(h)`))
+				})
+			})
+
+			Context("with validation patterns", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/bottles"}
+					contexts = []string{"ListBottleContext"}
+					patterns = []string{`^[a-z]+$`, `^\d{4}$`}
+				})
+
+				It("compiles the patterns when mounting the controller", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`goa.CompilePatterns("^[a-z]+$", "^\\d{4}$")`))
+				})
+			})
+
 			Context("with actions that take a payload", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
@@ -1150,6 +1866,69 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with actions that take a streaming payload", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+							},
+						},
+					}
+					streaming = true
+				})
+
+				It("gives the request body to the action handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("rctx.Payload = req.Body"))
+					Ω(written).Should(ContainSubstring(`ctrl.MuxHandler("list", h, nil)`))
+					Ω(written).ShouldNot(ContainSubstring("unmarshalListBottlePayload"))
+				})
+			})
+
+			Context("with actions that take a multipart payload", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"POST"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"label": &design.AttributeDefinition{
+										Type: design.File,
+									},
+								},
+							},
+						},
+					}
+					multipart = true
+					fileLimits = `map[string]int64{"label": 1024}`
+				})
+
+				It("decodes the payload as a multipart form", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`service.DecodeMultipartForm(ctx, req, payload, map[string]int64{"label": 1024})`))
+				})
+			})
+
 			Context("with multiple controllers", func() {
 				BeforeEach(func() {
 					actions = []string{"list", "show"}
@@ -1458,6 +2237,136 @@ var _ = Describe("UserTypesWriter", func() {
 				}
 			})
 
+			Context("with an attribute defining state transitions", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"status": &design.AttributeDefinition{
+								Type: design.String,
+								Transitions: map[string][]string{
+									"pending": {"paid", "cancelled"},
+								},
+							},
+						},
+					}
+					typeName = "OrderPayload"
+				})
+
+				It("writes the transition check", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ut *OrderPayload) ValidateStatusTransition(from string) error {"))
+					Ω(written).Should(ContainSubstring(`transitions := map[string][]string{"pending":[]string{"paid", "cancelled"}}`))
+					Ω(written).Should(ContainSubstring("to := *ut.Status"))
+					Ω(written).Should(ContainSubstring(`return goa.InvalidTransitionError("status", from, to, transitions[from])`))
+				})
+			})
+
+			Context("with an enum type", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type:       design.String,
+						Validation: &dslengine.ValidationDefinition{Values: []interface{}{"open", "in-progress"}},
+					}
+					typeName = "OrderStatus"
+				})
+
+				JustBeforeEach(func() {
+					data.Enum = true
+				})
+
+				It("writes the type and its constants", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(enumType))
+					Ω(written).ShouldNot(ContainSubstring("Publicize"))
+				})
+			})
+
+			Context("with a flags type", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{Type: design.Integer}
+					typeName = "Permissions"
+				})
+
+				JustBeforeEach(func() {
+					data.Flags = []string{"read", "write"}
+				})
+
+				It("writes the bitmask type and its helpers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("type Permissions uint64"))
+					Ω(written).Should(ContainSubstring("PermissionsRead Permissions = 1 << 0"))
+					Ω(written).Should(ContainSubstring("PermissionsWrite Permissions = 1 << 1"))
+					Ω(written).Should(ContainSubstring(`var permissionsFlagNames = []string{"read", "write"}`))
+					Ω(written).Should(ContainSubstring("func (f Permissions) Has(flags Permissions) bool {"))
+					Ω(written).Should(ContainSubstring("func (f Permissions) Set(flags Permissions) Permissions {"))
+					Ω(written).Should(ContainSubstring("func (f *Permissions) UnmarshalJSON(data []byte) error {"))
+				})
+			})
+
+			Context("with a one of type", func() {
+				member := func(name, value string, att *design.AttributeDefinition) *design.UserTypeDefinition {
+					return &design.UserTypeDefinition{
+						TypeName: name,
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"kind": &design.AttributeDefinition{
+									Type:       design.String,
+									Validation: &dslengine.ValidationDefinition{Values: []interface{}{value}},
+								},
+								"extra": att,
+							},
+							Validation: &dslengine.ValidationDefinition{Required: []string{"kind"}},
+						},
+					}
+				}
+
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{Type: design.Object{"kind": &design.AttributeDefinition{Type: design.String}}}
+					typeName = "Pet"
+				})
+
+				JustBeforeEach(func() {
+					data.Discriminator = "kind"
+					data.OneOf = []*design.UserTypeDefinition{
+						member("Cat", "cat", &design.AttributeDefinition{Type: design.Integer, DefaultValue: 9}),
+						member("Dog", "dog", &design.AttributeDefinition{Type: design.String}),
+					}
+				})
+
+				It("writes the union types and the decoding dispatching on the discriminator", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(MatchRegexp(`type pet struct {\n\tCat +\*cat\n\tDog +\*dog\n`))
+					Ω(written).Should(ContainSubstring("d, err := goa.DecodeDiscriminator(data, `request`, \"kind\")"))
+					Ω(written).Should(ContainSubstring("*ut = pet{discriminatorErr: err}"))
+					Ω(written).Should(ContainSubstring("\tcase \"cat\":\n\t\tut.Cat = &cat{}\n\t\treturn json.Unmarshal(data, ut.Cat)"))
+					Ω(written).Should(ContainSubstring("ut.discriminatorErr = goa.InvalidEnumValueError(`request.kind`, d, []interface{}{ \"cat\", \"dog\" })"))
+					Ω(written).Should(ContainSubstring("ut.discriminatorErr = goa.InvalidEnumValueError(`type.kind`, d, []interface{}{ \"cat\", \"dog\" })"))
+					Ω(written).Should(ContainSubstring("func (ut *pet) Validate() (err error) {\n\tif ut.discriminatorErr != nil {\n\t\treturn ut.discriminatorErr\n\t}"))
+					Ω(written).Should(ContainSubstring("func (ut *pet) Finalize() {\n\tif ut.Cat != nil {\n\t\tut.Cat.Finalize()\n\t}\n}"))
+					Ω(written).Should(ContainSubstring(`err = goa.MergeErrors(err, goa.InvalidOneOfError(` + "`request`" + `, n, []string{ "Cat", "Dog" }))`))
+					Ω(written).Should(ContainSubstring("func (ut *pet) Publicize() *Pet {"))
+					Ω(written).Should(ContainSubstring("func (ut Pet) MarshalJSON() ([]byte, error) {"))
+					Ω(written).Should(ContainSubstring("func (ut *Pet) UnmarshalJSON(data []byte) error {"))
+					Ω(written).Should(ContainSubstring("ut.Dog = &Dog{}"))
+				})
+			})
+
 			Context("with a simple user type", func() {
 				BeforeEach(func() {
 					attDef = &design.AttributeDefinition{
@@ -1771,6 +2680,70 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 	}
 	return &rctx, err
 }
+`
+
+	requiredHeaderContextFactory = `
+func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListBottleContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
+	headerXRequestID := req.Header["X-Request-Id"]
+	if len(headerXRequestID) == 0 {
+		err = goa.MergeErrors(err, goa.MissingHeaderError("X-Request-Id"))
+	} else {
+		rawXRequestID := headerXRequestID[0]
+		req.Params["X-Request-Id"] = []string{rawXRequestID}
+		rctx.XRequestID = rawXRequestID
+		if err2 := goa.ValidateFormat(goa.Format("uuid"), rctx.XRequestID); err2 != nil {
+				err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`X-Request-Id`" + `, rctx.XRequestID, goa.Format("uuid"), err2))
+		}
+	}
+	return &rctx, err
+}
+`
+
+	cookiesContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Session string
+	Visits *int
+}
+`
+
+	cookiesContextFactory = `
+func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListBottleContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
+	if cookieSession, err2 := req.Cookie("session"); err2 != nil {
+		err = goa.MergeErrors(err, goa.MissingCookieError("session"))
+	} else {
+		rawSession := cookieSession.Value
+		rctx.Session = rawSession
+			if utf8.RuneCountInString(rctx.Session) < 32 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`session`" + `, rctx.Session, utf8.RuneCountInString(rctx.Session), 32, true))
+		}
+	}
+	if cookieVisits, err2 := req.Cookie("visits"); err2 == nil {
+		rawVisits := cookieVisits.Value
+		if visits, err2 := strconv.Atoi(rawVisits); err2 == nil {
+			tmp2 := visits
+			tmp1 := &tmp2
+			rctx.Visits = tmp1
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("visits", rawVisits, "integer"))
+		}
+	}
+	return &rctx, err
+}
 `
 
 	strHeaderParamContextFactory = `
@@ -2082,6 +3055,13 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 }
 `
 
+	intArrayCSVContextFactory = `
+	paramParam := req.Params["param"]
+	if len(paramParam) > 0 {
+		paramParam = goa.SplitCollection(paramParam, ",")
+		params := make([]int, len(paramParam))
+`
+
 	intArrayDefaultContextFactory = `
 func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListBottleContext, error) {
 	var err error
@@ -2265,15 +3245,65 @@ func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *
 	if err := service.DecodeRequest(req, payload); err != nil {
 		return err
 	}
-	if err := payload.Validate(); err != nil {
-		// Initialize payload with private data structure so it can be logged
-		goa.ContextRequest(ctx).Payload = payload
-		return err
-	}
+	goa.ContextRequest(ctx).DeferPayloadValidation(func() error {
+		if err := payload.Validate(); err != nil {
+			// Initialize payload with private data structure so it can be logged
+			goa.ContextRequest(ctx).Payload = payload
+			return err
+		}
+		return nil
+	})
 	goa.ContextRequest(ctx).Payload = payload.Publicize()
 	return nil
 }
 `
+	chunkedResponse = `func (ctx *ListBottleContext) OK() io.Writer {
+	return goa.NewChunkedResponse(ctx.Context, 200, "application/vnd.goa.test; type=collection")
+}
+`
+
+	headerTemplatesResponse = `	ctx.ResponseData.Header().Set("Location", fmt.Sprintf("/orders/%v", r.ID))
+	if r.Name == nil {
+		return fmt.Errorf("missing attribute \"name\" required to compute header \"X-Name\"")
+	}
+	ctx.ResponseData.Header().Set("X-Name", fmt.Sprintf("%v", *r.Name))
+	return ctx.ResponseData.Service.Send(ctx.Context, 201, r)
+`
+
+	streamingResponse = `// OK starts the server-sent event stream response with status code 200 and returns
+// the stream used to send the events.
+func (ctx *ListBottleContext) OK() *ListBottleOKStream {
+	return &ListBottleOKStream{EventStream: goa.NewEventStream(ctx.Context), ctx: ctx}
+}
+
+// ListBottleOKStream sends the events of the ListBottleContext OK server-sent event stream.
+type ListBottleOKStream struct {
+	*goa.EventStream
+	ctx *ListBottleContext
+}
+`
+
+	deltaStreamHelpers = `// ResumeToken returns the resume token of the last delta received by reconnecting clients, the
+// empty string for new clients. Handlers send the deltas made since that token first.
+func (s *ListBottleOKStream) ResumeToken() string {
+	return s.LastEventID()
+}
+`
+
+	inboundMessageReceive = `// ReceiveMessage reads the next message sent by the client on the websocket connection ws and
+// validates it.
+func (ctx *ListBottleContext) ReceiveMessage(ws *websocket.Conn) (*ListBottleInboundMessage, error) {
+	msg := &listBottleInboundMessage{}
+	if err := websocket.JSON.Receive(ws, msg); err != nil {
+		return nil, err
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+	return msg.Publicize(), nil
+}
+`
+
 	payloadNoValidationsObjUnmarshal = `
 func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
 	payload := &listBottlePayload{}
@@ -2395,12 +3425,12 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "list")
 		}
 		// Build the context
 		rctx, err := NewListBottleContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "list")
 		}
 		return ctrl.List(rctx)
 	}
@@ -2416,12 +3446,12 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "list")
 		}
 		// Build the context
 		rctx, err := NewListBottleContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "list")
 		}
 		return ctrl.List(rctx)
 	}
@@ -2445,12 +3475,12 @@ type BottlesController interface {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "list")
 		}
 		// Build the context
 		rctx, err := NewListBottleContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "list")
 		}
 		return ctrl.List(rctx)
 	}
@@ -2460,12 +3490,12 @@ type BottlesController interface {
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "show")
 		}
 		// Build the context
 		rctx, err := NewShowBottleContext(ctx, req, service)
 		if err != nil {
-			return err
+			return goa.WithDesignLocation(err, "bottles", "show")
 		}
 		return ctrl.Show(rctx)
 	}
@@ -2482,6 +3512,30 @@ type BottlesController interface {
 	noParamHref = `func BottleHref() string {
 	return "/bottles"
 }
+`
+
+	enumType = `// OrderStatus user type.
+type OrderStatus string
+
+// Values of the OrderStatus type.
+const (
+	OrderStatusOpen OrderStatus = "open"
+	OrderStatusInProgress OrderStatus = "in-progress"
+)
+
+// IsValid returns true if the value is one of the OrderStatus values.
+func (e OrderStatus) IsValid() bool {
+	switch e {
+	case OrderStatusOpen, OrderStatusInProgress:
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the value.
+func (e OrderStatus) String() string {
+	return string(e)
+}
 `
 
 	simpleUserType = `// simplePayload user type.
@@ -0,0 +1,25 @@
+/*
+Package genchangelog provides a generator for a human readable changelog of the API meant to be
+published to the API consumers. The generator compares the design to the snapshot of the previous
+release saved in changelog.json and writes a section listing the added endpoints and types, the
+changes made to the existing ones, the deprecations and the removals to CHANGELOG.md. Breaking
+changes are flagged as such.
+
+The generator is run once per release:
+
+	goagen changelog -d github.com/acme/cellar/design --release v1.2.0
+
+Running the generator again for the same release replaces its section so that the changelog
+stays accurate while the release is being prepared. Running it for a new release compares the
+design to the snapshot of the last release.
+
+Actions and attributes are deprecated with the "deprecated" metadata, the optional value is the
+sunset date after which the action or attribute may be removed:
+
+	Action("list", func() {
+		Metadata("deprecated", "2027-01-31")
+		Routing(GET(""))
+		Response(OK, CollectionOf(BottleMedia))
+	})
+*/
+package genchangelog
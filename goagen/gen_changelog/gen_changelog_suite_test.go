@@ -0,0 +1,13 @@
+package genchangelog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenChangelog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Changelog Generator Suite")
+}
@@ -0,0 +1,168 @@
+package genchangelog
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+//NewGenerator returns an initialized instance of a Changelog Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the changelog generator.
+type Generator struct {
+	API     *design.APIDefinition // The API definition
+	OutDir  string                // Path to output directory
+	Release string                // Name of the release
+	Date    string                // Date of the release
+}
+
+const (
+	// changelogFile is the name of the generated changelog file.
+	changelogFile = "CHANGELOG.md"
+	// snapshotFile is the name of the file containing the snapshot of the last release.
+	snapshotFile = "changelog.json"
+	// changelogHeader is the header of new changelog files.
+	changelogHeader = "# Changelog\n"
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, release, date, ver string
+	set := flag.NewFlagSet("changelog", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&release, "release", "Unreleased", "")
+	set.StringVar(&date, "date", time.Now().Format(design.SunsetDateFormat), "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Release: release, Date: date, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate writes the section of the release to the changelog and saves the snapshot of the
+// design. The changelog and snapshot are never removed by the generator as they accumulate the
+// history of the API.
+func (g *Generator) Generate() ([]string, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if g.Release == "" {
+		g.Release = "Unreleased"
+	}
+
+	snapshotPath := filepath.Join(g.OutDir, snapshotFile)
+	previous, err := readSnapshot(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	base := previous
+	if previous != nil && previous.Release == g.Release {
+		// Generating the same release again, compare to the same base.
+		base = previous.Base
+	}
+	if base == nil {
+		base = &Snapshot{}
+	}
+	base.Base = nil
+
+	next := TakeSnapshot(g.API, g.Release)
+	if base.Release != "" {
+		next.Base = base
+	}
+	sec := section(g.Release, g.Date, Diff(base, next))
+
+	changelogPath := filepath.Join(g.OutDir, changelogFile)
+	content, err := ioutil.ReadFile(changelogPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := os.MkdirAll(g.OutDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(changelogPath, []byte(insertSection(string(content), g.Release, sec)), 0644); err != nil {
+		return nil, err
+	}
+	js, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(snapshotPath, append(js, '\n'), 0644); err != nil {
+		return nil, err
+	}
+
+	return []string{changelogPath, snapshotPath}, nil
+}
+
+// readSnapshot reads the snapshot saved at the given path, it returns nil if there is none.
+func readSnapshot(path string) (*Snapshot, error) {
+	js, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(js, &s); err != nil {
+		return nil, fmt.Errorf("invalid changelog snapshot %s: %s", path, err)
+	}
+	return &s, nil
+}
+
+// insertSection replaces the section of the given release in the changelog content or inserts
+// it above the previous releases if there is none.
+func insertSection(content, release, sec string) string {
+	if content == "" {
+		return changelogHeader + "\n" + sec
+	}
+	lines := strings.SplitAfter(content, "\n")
+	start, end := -1, len(lines)
+	for i, l := range lines {
+		if !strings.HasPrefix(l, "## ") {
+			continue
+		}
+		if start >= 0 {
+			end = i
+			break
+		}
+		if heading := strings.TrimSpace(l[3:]); heading == release || strings.HasPrefix(heading, release+" ") {
+			start = i
+			continue
+		}
+		// First section of another release, insert above it.
+		return strings.Join(lines[:i], "") + sec + "\n" + strings.Join(lines[i:], "")
+	}
+	if start < 0 {
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + "\n" + sec
+	}
+	if end < len(lines) {
+		sec += "\n"
+	}
+	return strings.Join(lines[:start], "") + sec + strings.Join(lines[end:], "")
+}
@@ -0,0 +1,274 @@
+package genchangelog_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_changelog"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("changelogtest")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	// generate runs the generator for the given design and release and returns the content of
+	// the changelog.
+	generate := func(dsl func(), release string) string {
+		dslengine.Reset()
+		dsl()
+		dslengine.Run()
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		g := genchangelog.NewGenerator(
+			genchangelog.API(design.Design),
+			genchangelog.OutDir(testPkg.Abs()),
+			genchangelog.Release(release),
+			genchangelog.Date("2026-10-15"),
+		)
+		files, err := g.Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{
+			filepath.Join(testPkg.Abs(), "CHANGELOG.md"),
+			filepath.Join(testPkg.Abs(), "changelog.json"),
+		}))
+		content, err := ioutil.ReadFile(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(content)
+	}
+
+	Context("with the first release", func() {
+		It("lists all the endpoints and types as added", func() {
+			Ω(generate(designV1, "v1.0.0")).Should(Equal(changelogV1))
+		})
+	})
+
+	Context("with a new release", func() {
+		It("adds the section of the release above the previous ones", func() {
+			generate(designV1, "v1.0.0")
+			Ω(generate(designV2, "v1.1.0")).Should(Equal(changelogV2))
+		})
+	})
+
+	Context("with a release generated again", func() {
+		It("replaces the section of the release", func() {
+			generate(designV1, "v1.0.0")
+			generate(designV1, "v1.1.0")
+			Ω(generate(designV2, "v1.1.0")).Should(Equal(changelogV2))
+		})
+	})
+
+	Context("with no change", func() {
+		It("says so", func() {
+			generate(designV1, "v1.0.0")
+			Ω(generate(designV1, "v1.1.0")).Should(Equal(changelogNoChange))
+		})
+	})
+
+	Context("with flags", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			designV1()
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String(),
+				"--release=v1.0.0", "--date=2026-10-15"}
+		})
+
+		It("uses them", func() {
+			files, err := genchangelog.Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(2))
+			content, err := ioutil.ReadFile(files[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal(changelogV1))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genchangelog.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genchangelog.NewGenerator(
+				genchangelog.API(args.api),
+				genchangelog.OutDir(args.outDir),
+				genchangelog.Release("v1.0.0"),
+				genchangelog.Date("2026-10-15"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Release).Should(Equal("v1.0.0"))
+			Ω(generator.Date).Should(Equal("2026-10-15"))
+		})
+	})
+})
+
+func designV1() {
+	apidsl.API("cellar", nil)
+	bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+		apidsl.Attributes(func() {
+			apidsl.Attribute("id", design.Integer)
+			apidsl.Attribute("name", design.String)
+		})
+		apidsl.View("default", func() {
+			apidsl.Attribute("id")
+			apidsl.Attribute("name")
+		})
+	})
+	apidsl.Resource("bottle", func() {
+		apidsl.BasePath("/bottles")
+		apidsl.Action("list", func() {
+			apidsl.Routing(apidsl.GET(""))
+			apidsl.Response(design.OK)
+		})
+		apidsl.Action("show", func() {
+			apidsl.Routing(apidsl.GET("/:id"))
+			apidsl.Params(func() {
+				apidsl.Param("id", design.Integer)
+			})
+			apidsl.Response(design.OK, bottle)
+		})
+		apidsl.Action("delete", func() {
+			apidsl.Routing(apidsl.DELETE("/:id"))
+			apidsl.Response(design.NoContent)
+		})
+	})
+}
+
+func designV2() {
+	apidsl.API("cellar", nil)
+	bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+		apidsl.Attributes(func() {
+			apidsl.Attribute("id", design.Integer)
+			apidsl.Attribute("name", design.String)
+			apidsl.Attribute("vintage", design.Integer)
+			apidsl.Required("name")
+		})
+		apidsl.View("default", func() {
+			apidsl.Attribute("id")
+			apidsl.Attribute("name")
+			apidsl.Attribute("vintage")
+		})
+	})
+	apidsl.Resource("bottle", func() {
+		apidsl.BasePath("/bottles")
+		apidsl.Action("list", func() {
+			apidsl.Metadata("deprecated", "2027-01-31")
+			apidsl.Routing(apidsl.GET(""))
+			apidsl.Response(design.OK)
+		})
+		apidsl.Action("show", func() {
+			apidsl.Routing(apidsl.GET("/:id"))
+			apidsl.Params(func() {
+				apidsl.Param("id", design.Integer)
+				apidsl.Param("fields", design.String, func() {
+					apidsl.Metadata("deprecated")
+				})
+			})
+			apidsl.Response(design.OK, bottle)
+		})
+		apidsl.Action("create", func() {
+			apidsl.Routing(apidsl.POST(""))
+			apidsl.Payload(func() {
+				apidsl.Attribute("name", design.String)
+				apidsl.Required("name")
+			})
+			apidsl.Response(design.Created)
+		})
+	})
+}
+
+const changelogV1 = `# Changelog
+
+## v1.0.0 - 2026-10-15
+
+### Added
+
+- ` + "`DELETE /bottles/:id`" + ` (delete bottle)
+- ` + "`GET /bottles`" + ` (list bottle)
+- ` + "`GET /bottles/:id`" + ` (show bottle)
+- type ` + "`Bottle`" + `
+`
+
+const changelogV2 = `# Changelog
+
+## v1.1.0 - 2026-10-15
+
+### Added
+
+- ` + "`POST /bottles`" + ` (create bottle)
+
+### Changed
+
+- ` + "`GET /bottles/:id`" + `: parameter ` + "`fields`" + ` (string) added
+- **Breaking:** type ` + "`Bottle`" + `: field ` + "`name`" + ` is now required
+- type ` + "`Bottle`" + `: field ` + "`vintage`" + ` (integer) added
+
+### Deprecated
+
+- ` + "`GET /bottles`" + `, sunset on 2027-01-31
+- ` + "`GET /bottles/:id`" + `: parameter ` + "`fields`" + `
+
+### Removed
+
+- **Breaking:** ` + "`DELETE /bottles/:id`" + ` (delete bottle)
+
+## v1.0.0 - 2026-10-15
+
+### Added
+
+- ` + "`DELETE /bottles/:id`" + ` (delete bottle)
+- ` + "`GET /bottles`" + ` (list bottle)
+- ` + "`GET /bottles/:id`" + ` (show bottle)
+- type ` + "`Bottle`" + `
+`
+
+const changelogNoChange = `# Changelog
+
+## v1.1.0 - 2026-10-15
+
+No changes to the API.
+
+## v1.0.0 - 2026-10-15
+
+### Added
+
+- ` + "`DELETE /bottles/:id`" + ` (delete bottle)
+- ` + "`GET /bottles`" + ` (list bottle)
+- ` + "`GET /bottles/:id`" + ` (show bottle)
+- type ` + "`Bottle`" + `
+`
@@ -0,0 +1,34 @@
+package genchangelog
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Release Name of the release the changelog section is generated for
+func Release(release string) Option {
+	return func(g *Generator) {
+		g.Release = release
+	}
+}
+
+//Date Date of the release the changelog section is generated for
+func Date(date string) Option {
+	return func(g *Generator) {
+		g.Date = date
+	}
+}
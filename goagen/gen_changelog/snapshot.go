@@ -0,0 +1,349 @@
+package genchangelog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+)
+
+type (
+	// Snapshot captures the parts of a design that matter to API consumers. The snapshot of
+	// each release is saved alongside the changelog so that the next release can be compared
+	// to it.
+	Snapshot struct {
+		// Release is the name of the release, e.g. "v1.2.0".
+		Release string `json:"release"`
+		// Endpoints lists the API endpoints indexed by verb and path, e.g. "GET /bottles/:id".
+		Endpoints map[string]*Endpoint `json:"endpoints"`
+		// Types lists the user types and media types indexed by name.
+		Types map[string]*Type `json:"types"`
+		// Base is the snapshot of the release the snapshot was compared to, it makes it
+		// possible to produce the changelog of the same release again.
+		Base *Snapshot `json:"base,omitempty"`
+	}
+
+	// Endpoint describes an API endpoint.
+	Endpoint struct {
+		// Resource is the name of the endpoint resource.
+		Resource string `json:"resource"`
+		// Action is the name of the endpoint action.
+		Action string `json:"action"`
+		// Params lists the path and query string parameters and the headers indexed by name.
+		Params map[string]*Field `json:"params,omitempty"`
+		// Payload lists the payload fields indexed by path if any.
+		Payload map[string]*Field `json:"payload,omitempty"`
+		// Responses lists the response media types indexed by status code.
+		Responses map[string]string `json:"responses,omitempty"`
+		// Deprecated is true if the endpoint action is deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Sunset is the sunset date of the deprecated endpoint action if any.
+		Sunset string `json:"sunset,omitempty"`
+	}
+
+	// Type describes a user type or media type.
+	Type struct {
+		// Fields lists the type fields indexed by path, e.g. "origin.country".
+		Fields map[string]*Field `json:"fields"`
+	}
+
+	// Field describes a parameter, header or type field.
+	Field struct {
+		// Type is the name of the field type, e.g. "string" or "[]Bottle".
+		Type string `json:"type"`
+		// Required is true if the field is required.
+		Required bool `json:"required,omitempty"`
+		// Deprecated is true if the field is deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Sunset is the sunset date of the deprecated field if any.
+		Sunset string `json:"sunset,omitempty"`
+	}
+
+	// Changes lists the differences between two snapshots in the order they are listed in
+	// the changelog. The breaking changes are prefixed with "**Breaking:**".
+	Changes struct {
+		// Added lists the new endpoints and types.
+		Added []string
+		// Changed lists the changes made to existing endpoints and types.
+		Changed []string
+		// Deprecated lists the endpoints and fields deprecated since the previous release.
+		Deprecated []string
+		// Removed lists the endpoints and types that no longer exist.
+		Removed []string
+	}
+)
+
+// TakeSnapshot returns the snapshot of the given API design.
+func TakeSnapshot(api *design.APIDefinition, release string) *Snapshot {
+	s := &Snapshot{
+		Release:   release,
+		Endpoints: make(map[string]*Endpoint),
+		Types:     make(map[string]*Type),
+	}
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			e := &Endpoint{Resource: res.Name, Action: a.Name, Params: make(map[string]*Field)}
+			params := a.AllParams()
+			for n, att := range params.Type.ToObject() {
+				e.Params[n] = field(att, params.IsRequired(n))
+			}
+			a.IterateHeaders(func(n string, required bool, att *design.AttributeDefinition) error {
+				e.Params["header "+n] = field(att, required)
+				return nil
+			})
+			if a.Payload != nil {
+				e.Payload = make(map[string]*Field)
+				fields("", a.Payload.AttributeDefinition, e.Payload)
+			}
+			if len(a.Responses) > 0 {
+				e.Responses = make(map[string]string, len(a.Responses))
+				for _, r := range a.Responses {
+					e.Responses[fmt.Sprint(r.Status)] = r.MediaType
+				}
+			}
+			e.Deprecated, e.Sunset = a.Deprecation()
+			for _, r := range a.Routes {
+				s.Endpoints[r.Verb+" "+r.FullPath()] = e
+			}
+			return nil
+		})
+	})
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		t := &Type{Fields: make(map[string]*Field)}
+		fields("", ut.AttributeDefinition, t.Fields)
+		s.Types[ut.TypeName] = t
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		t := &Type{Fields: make(map[string]*Field)}
+		fields("", mt.AttributeDefinition, t.Fields)
+		s.Types[mt.TypeName] = t
+		return nil
+	})
+	return s
+}
+
+// Diff returns the changes made between the previous and next snapshots.
+func Diff(previous, next *Snapshot) *Changes {
+	c := new(Changes)
+	for _, key := range sortedKeys(next.Endpoints) {
+		e := next.Endpoints[key]
+		pe, ok := previous.Endpoints[key]
+		if !ok {
+			c.Added = append(c.Added, fmt.Sprintf("`%s` (%s %s)", key, e.Action, e.Resource))
+			if e.Deprecated {
+				c.Deprecated = append(c.Deprecated, deprecated("`"+key+"`", e.Sunset))
+			}
+			continue
+		}
+		subject := "`" + key + "`"
+		c.Changed = append(c.Changed, diffFields(subject, "parameter", pe.Params, e.Params)...)
+		if pe.Payload == nil && e.Payload != nil {
+			c.Changed = append(c.Changed, subject+": payload added")
+		} else if pe.Payload != nil && e.Payload == nil {
+			c.Changed = append(c.Changed, breaking(subject+": payload removed"))
+		}
+		c.Changed = append(c.Changed, diffFields(subject, "payload field", pe.Payload, e.Payload)...)
+		for _, status := range sortedKeys(e.Responses) {
+			prev, ok := pe.Responses[status]
+			if !ok {
+				c.Changed = append(c.Changed, fmt.Sprintf("%s: response %s added", subject, status))
+			} else if prev != e.Responses[status] {
+				c.Changed = append(c.Changed, breaking(fmt.Sprintf("%s: response %s media type changed from %s to %s",
+					subject, status, orNone(prev), orNone(e.Responses[status]))))
+			}
+		}
+		for _, status := range sortedKeys(pe.Responses) {
+			if _, ok := e.Responses[status]; !ok {
+				c.Changed = append(c.Changed, fmt.Sprintf("%s: response %s removed", subject, status))
+			}
+		}
+		if e.Deprecated && !pe.Deprecated {
+			c.Deprecated = append(c.Deprecated, deprecated(subject, e.Sunset))
+		}
+		c.Deprecated = append(c.Deprecated, deprecatedFields(subject, "parameter", pe.Params, e.Params)...)
+		c.Deprecated = append(c.Deprecated, deprecatedFields(subject, "payload field", pe.Payload, e.Payload)...)
+	}
+	for _, key := range sortedKeys(previous.Endpoints) {
+		if _, ok := next.Endpoints[key]; !ok {
+			e := previous.Endpoints[key]
+			c.Removed = append(c.Removed, breaking(fmt.Sprintf("`%s` (%s %s)", key, e.Action, e.Resource)))
+		}
+	}
+	for _, name := range sortedKeys(next.Types) {
+		t := next.Types[name]
+		pt, ok := previous.Types[name]
+		if !ok {
+			c.Added = append(c.Added, fmt.Sprintf("type `%s`", name))
+			continue
+		}
+		subject := "type `" + name + "`"
+		c.Changed = append(c.Changed, diffFields(subject, "field", pt.Fields, t.Fields)...)
+		c.Deprecated = append(c.Deprecated, deprecatedFields(subject, "field", pt.Fields, t.Fields)...)
+	}
+	for _, name := range sortedKeys(previous.Types) {
+		if _, ok := next.Types[name]; !ok {
+			c.Removed = append(c.Removed, fmt.Sprintf("type `%s`", name))
+		}
+	}
+	return c
+}
+
+// IsEmpty returns true if there are no changes.
+func (c *Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Deprecated) == 0 && len(c.Removed) == 0
+}
+
+// diffFields returns the changes made to the given parameters or fields.
+func diffFields(subject, kind string, previous, next map[string]*Field) []string {
+	var changes []string
+	for _, n := range sortedKeys(next) {
+		f := next[n]
+		pf, ok := previous[n]
+		switch {
+		case !ok && f.Required:
+			changes = append(changes, breaking(fmt.Sprintf("%s: required %s `%s` (%s) added", subject, kind, n, f.Type)))
+		case !ok:
+			changes = append(changes, fmt.Sprintf("%s: %s `%s` (%s) added", subject, kind, n, f.Type))
+		case pf.Type != f.Type:
+			changes = append(changes, breaking(fmt.Sprintf("%s: %s `%s` type changed from %s to %s", subject, kind, n, pf.Type, f.Type)))
+		case f.Required && !pf.Required:
+			changes = append(changes, breaking(fmt.Sprintf("%s: %s `%s` is now required", subject, kind, n)))
+		case !f.Required && pf.Required:
+			changes = append(changes, fmt.Sprintf("%s: %s `%s` is now optional", subject, kind, n))
+		}
+	}
+	for _, n := range sortedKeys(previous) {
+		if _, ok := next[n]; !ok {
+			changes = append(changes, breaking(fmt.Sprintf("%s: %s `%s` removed", subject, kind, n)))
+		}
+	}
+	return changes
+}
+
+// deprecatedFields returns the parameters or fields deprecated since the previous snapshot.
+func deprecatedFields(subject, kind string, previous, next map[string]*Field) []string {
+	var deps []string
+	for _, n := range sortedKeys(next) {
+		f := next[n]
+		if pf, ok := previous[n]; f.Deprecated && (!ok || !pf.Deprecated) {
+			deps = append(deps, deprecated(fmt.Sprintf("%s: %s `%s`", subject, kind, n), f.Sunset))
+		}
+	}
+	return deps
+}
+
+// field returns the snapshot of the given attribute.
+func field(att *design.AttributeDefinition, required bool) *Field {
+	f := &Field{Type: typeName(att.Type), Required: required}
+	f.Deprecated, f.Sunset = att.Deprecation()
+	return f
+}
+
+// fields records the fields of the given attribute in res. The fields of the inline objects are
+// recorded using their path, the fields of user types are described by the user type snapshot.
+func fields(prefix string, att *design.AttributeDefinition, res map[string]*Field) {
+	obj := att.Type.ToObject()
+	if obj == nil {
+		return
+	}
+	for n, child := range obj {
+		res[prefix+n] = field(child, att.IsRequired(n))
+		if _, ok := child.Type.(design.Object); ok {
+			fields(prefix+n+".", child, res)
+		}
+	}
+}
+
+// typeName returns the name of the given type used in the snapshots.
+func typeName(t design.DataType) string {
+	switch actual := t.(type) {
+	case *design.Array:
+		return "[]" + typeName(actual.ElemType.Type)
+	case *design.Hash:
+		return "map[" + typeName(actual.KeyType.Type) + "]" + typeName(actual.ElemType.Type)
+	case *design.MediaTypeDefinition:
+		return actual.TypeName
+	case *design.UserTypeDefinition:
+		return actual.TypeName
+	}
+	return t.Name()
+}
+
+// deprecated returns the deprecation entry of subject.
+func deprecated(subject, sunset string) string {
+	if sunset == "" {
+		return subject
+	}
+	return fmt.Sprintf("%s, sunset on %s", subject, sunset)
+}
+
+// breaking flags the given change as a breaking change.
+func breaking(change string) string {
+	return "**Breaking:** " + change
+}
+
+// orNone returns s or "none" if s is empty.
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// sortedKeys returns the sorted keys of the given map.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch actual := m.(type) {
+	case map[string]*Endpoint:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*Type:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*Field:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// section renders the changelog section of the given release.
+func section(release, date string, c *Changes) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "## %s", release)
+	if date != "" {
+		fmt.Fprintf(&b, " - %s", date)
+	}
+	b.WriteString("\n")
+	if c.IsEmpty() {
+		b.WriteString("\nNo changes to the API.\n")
+		return b.String()
+	}
+	list := func(title string, entries []string) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", title)
+		for _, e := range entries {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+	list("Added", c.Added)
+	list("Changed", c.Changed)
+	list("Deprecated", c.Deprecated)
+	list("Removed", c.Removed)
+	return b.String()
+}
@@ -238,7 +238,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 				"Action":          action,
 				"Resource":        action.Parent,
 				"Package":         g.Target,
-				"HasMultiContent": len(g.API.Consumes) > 1,
+				"HasMultiContent": len(g.API.Consumes) > 1 && !action.PayloadMultipart,
 			}
 			var err error
 			if action.WebSocket() {
@@ -320,7 +320,9 @@ func joinNames(useNil bool, atts ...*design.AttributeDefinition) string {
 		for _, n := range keys {
 			a := obj[n]
 			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
-			if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
+			if a.IsDeepObject() {
+				field = "%s"
+			} else if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
 				if useNil {
 					field = flagTypeVal(a, n, field)
 				} else {
@@ -351,7 +353,7 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 		return `intFlagVal("` + key + `", ` + field + ")"
 	case design.String:
 		return `stringFlagVal("` + key + `", ` + field + ")"
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Date, design.Any:
 		return "%s"
 	default:
 		return "&" + field
@@ -364,7 +366,7 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 // %s maps to specialTypeResult.Temps
 func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 	switch a.Type {
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Date, design.Any:
 		return "*%s"
 	default:
 		return field
@@ -376,7 +378,7 @@ func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 // %s maps to specialTypeResult.Temps
 func flagTypeArrayVal(a *design.AttributeDefinition, field string) string {
 	switch a.Type.ToArray().ElemType.Type {
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Date, design.Any:
 		return "%s"
 	}
 	return field
@@ -408,7 +410,7 @@ type specialTypeResult struct {
 // }
 // resp, err := c.ShowX(ctx, path, tmp)
 //
-func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
+func handleSpecialTypes(pkg string, atts ...*design.AttributeDefinition) specialTypeResult {
 	result := specialTypeResult{}
 	for _, att := range atts {
 		if att == nil {
@@ -427,6 +429,37 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 		for _, n := range keys {
 			a := obj[n]
 			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
+			if a.IsDeepObject() {
+				tmpVar := codegen.Tempvar()
+				if att.IsRequired(n) {
+					names = append(names, tmpVar)
+				} else {
+					optNames = append(optNames, tmpVar)
+				}
+				typ := codegen.GoTypeName(a.Type, nil, 0, false)
+				qualified := typ
+				if !strings.Contains(typ, ".") {
+					// Built-in types such as goa.Point are already qualified
+					qualified = pkg + "." + typ
+				}
+				result.Output += fmt.Sprintf(`
+	var %s *%s
+	if %s != "" {
+		%s = &%s{}
+		if err := json.Unmarshal([]byte(%s), %s); err != nil {
+			goa.LogError(ctx, "failed to parse flag into %s value", "flag", "--%s", "err", err)
+			return err
+		}
+	}`, tmpVar, qualified, field, tmpVar, qualified, field, tmpVar, typ, n)
+				if att.IsRequired(n) {
+					result.Output += fmt.Sprintf(`
+	if %s == nil {
+		goa.LogError(ctx, "required flag is missing", "flag", "--%s")
+		return fmt.Errorf("required flag %s is missing")
+	}`, tmpVar, n, n)
+				}
+				continue
+			}
 			typ := cmdFieldType(a.Type, true)
 			var typeHandler, nilVal string
 			if !a.Type.IsArray() {
@@ -440,6 +473,8 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "uuidVal"
 				case design.DateTime:
 					typeHandler = "timeVal"
+				case design.Date:
+					typeHandler = "dateVal"
 				case design.Any:
 					typeHandler = "jsonVal"
 				}
@@ -455,6 +490,8 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "uuidArray"
 				case design.DateTime:
 					typeHandler = "timeArray"
+				case design.Date:
+					typeHandler = "dateArray"
 				case design.Any:
 					typeHandler = "jsonArray"
 				}
@@ -564,12 +601,14 @@ func flagType(att *design.AttributeDefinition) string {
 		return "String"
 	case design.StringKind:
 		return "String"
-	case design.DateTimeKind:
+	case design.DateTimeKind, design.DateKind:
 		return "String"
 	case design.UUIDKind:
 		return "String"
 	case design.AnyKind:
 		return "String"
+	case design.ObjectKind:
+		return "String"
 	case design.ArrayKind:
 		switch att.Type.ToArray().ElemType.Type.Kind() {
 		case design.NumberKind:
@@ -722,7 +761,7 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ else }}{{ $pparams := defaultRouteParams .Action }}	path = fmt.Sprintf({{ printf "%q" (defaultRouteTemplate .Action)}}, {{ joinRouteParams .Action $pparams }})
 {{ end }}	}
 	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
-	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
+	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Package .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	ws, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{/*
 	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
 	if err != nil {
@@ -821,7 +860,7 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ end }}		}
 	}
 {{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
-	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
+	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Package .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
 	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
 	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
@@ -957,6 +996,29 @@ func timeArray(ins []string) ([]time.Time, error) {
 	return vals, nil
 }
 
+func dateVal(val string) (*goa.Date, error) {
+	d, err := goa.ParseDate(val)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func dateArray(ins []string) ([]goa.Date, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []goa.Date
+	for _, id := range ins {
+		val, err := dateVal(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
 func uuidVal(val string) (*uuid.UUID, error) {
 	t, err := uuid.FromString(val)
 	if err != nil {
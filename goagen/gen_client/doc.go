@@ -10,6 +10,13 @@ The generated code includes a client package with:
     * Structs for the action payloads and dependent types
     * Structs for the action media types and corresponding decoder functions
 
+The client package, including the validations of the payload and user types it declares, builds
+with GOOS=js GOARCH=wasm so that browser applications can reuse it: the file server download and
+websocket methods that require the local file system or raw network connections are generated in
+separate "_native.go" files excluded from js builds by a build constraint. Only the client package
+is covered, the app package generated by "goagen app" and its standalone payload validators are
+meant for servers and are not split.
+
 The generated code also includes a CLI tool with commands for each action and sub-commands for
 each resource.
 */
@@ -154,6 +154,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"join":               join,
 			"joinStrings":        strings.Join,
 			"multiComment":       multiComment,
+			"nullableMarshaler":  codegen.NullableMarshaler,
 			"pathParams":         pathParams,
 			"pathTemplate":       pathTemplate,
 			"signerType":         signerType,
@@ -344,7 +345,10 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+		codegen.SimpleImport("github.com/goadesign/goa/signedurl"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
@@ -352,8 +356,30 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 	}
 	g.genfiles = append(g.genfiles, filename)
 
+	// File downloads and websocket connections go to a separate file excluded from js/wasm
+	// builds so that the rest of the client package can be used in browsers.
+	native := file
+	if hasNativeCode(res) {
+		nativeFilename := filepath.Join(pkgDir, resFilename+"_native.go")
+		native, err = codegen.SourceFileFor(nativeFilename)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			native.Close()
+			if err == nil {
+				err = native.FormatCode()
+			}
+		}()
+		title := fmt.Sprintf("%s: %s Resource Client (native platforms)", g.API.Context(), res.Name)
+		if err = native.WriteBuildHeader(title, g.Target, "!js", imports); err != nil {
+			return err
+		}
+		g.genfiles = append(g.genfiles, nativeFilename)
+	}
+
 	err = res.IterateFileServers(func(fs *design.FileServerDefinition) error {
-		return g.generateFileServer(file, fs, funcs)
+		return g.generateFileServer(native, fs, funcs)
 	})
 	if err != nil {
 		return err
@@ -404,11 +430,28 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 				return err
 			}
 		}
+		if action.WebSocket() {
+			return g.generateActionClient(action, native, funcs)
+		}
 		return g.generateActionClient(action, file, funcs)
 	})
 	return
 }
 
+// hasNativeCode returns true if the resource client includes code that cannot be compiled for
+// js/wasm: file server downloads that write to the local file system or websocket connections.
+func hasNativeCode(res *design.ResourceDefinition) bool {
+	if len(res.FileServers) > 0 {
+		return true
+	}
+	for _, a := range res.Actions {
+		if a.WebSocket() {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.FileServerDefinition, funcs template.FuncMap) error {
 	var (
 		dir string
@@ -461,6 +504,7 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
 		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
 		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		signTmpl      = template.Must(template.New("sign").Funcs(funcs).Parse(signTmpl))
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
@@ -497,11 +541,13 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Routes             []*design.RouteDefinition
 		HasPayload         bool
 		HasMultiContent    bool
+		Multipart          bool
 		DefaultContentType string
 		Params             string
 		ParamNames         string
 		CanonicalScheme    string
 		Signer             string
+		DigestAlgorithm    string
 		QueryParams        []*paramData
 		Headers            []*paramData
 	}{
@@ -510,12 +556,14 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Description:        action.Description,
 		Routes:             action.Routes,
 		HasPayload:         action.Payload != nil,
-		HasMultiContent:    len(design.Design.Consumes) > 1,
+		HasMultiContent:    len(design.Design.Consumes) > 1 && !action.PayloadMultipart,
+		Multipart:          action.PayloadMultipart,
 		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
 		Params:             strings.Join(params, ", "),
 		ParamNames:         strings.Join(names, ", "),
 		CanonicalScheme:    action.CanonicalScheme(),
 		Signer:             signer,
+		DigestAlgorithm:    action.DigestAlgorithm,
 		QueryParams:        queryParams,
 		Headers:            headers,
 	}
@@ -525,7 +573,89 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	if err := clientsTmpl.Execute(file, data); err != nil {
 		return err
 	}
-	return requestsTmpl.Execute(file, data)
+	if err := requestsTmpl.Execute(file, data); err != nil {
+		return err
+	}
+	if action.SignedURL {
+		if err := signTmpl.Execute(file, data); err != nil {
+			return err
+		}
+	}
+	responses, err := actionResponses(action)
+	if err != nil {
+		return err
+	}
+	if err := g.generateActionError(action, responses, file, funcs); err != nil {
+		return err
+	}
+	return g.generateActionResult(action, responses, file, funcs)
+}
+
+// generateActionError generates the function that decodes the bodies of the error responses of
+// the action into the error media types defined in the design.
+func (g *Generator) generateActionError(action *design.ActionDefinition, responses []*responseData, file *codegen.SourceFile, funcs template.FuncMap) error {
+	errorTmpl := template.Must(template.New("error").Funcs(funcs).Parse(errorTmpl))
+	var errors []*responseData
+	for _, r := range responses {
+		if r.Status >= 400 && r.DecodeFunc != "" {
+			errors = append(errors, r)
+		}
+	}
+	data := struct {
+		Name         string
+		ResourceName string
+		Responses    []*responseData
+	}{
+		Name:         action.Name,
+		ResourceName: action.Parent.Name,
+		Responses:    errors,
+	}
+	return errorTmpl.Execute(file, data)
+}
+
+// generateActionResult generates the result type and decode function for actions that define
+// more than one response. The result type exposes one field per response so that client code
+// can switch on the actual status rather than on a generic interface.
+func (g *Generator) generateActionResult(action *design.ActionDefinition, responses []*responseData, file *codegen.SourceFile, funcs template.FuncMap) error {
+	if len(action.Responses) < 2 {
+		return nil
+	}
+	resultTmpl := template.Must(template.New("result").Funcs(funcs).Parse(resultTmpl))
+	data := struct {
+		Name         string
+		ResourceName string
+		Responses    []*responseData
+	}{
+		Name:         action.Name,
+		ResourceName: action.Parent.Name,
+		Responses:    responses,
+	}
+	return resultTmpl.Execute(file, data)
+}
+
+// actionResponses returns the data describing the responses of the action sorted by status.
+func actionResponses(action *design.ActionDefinition) ([]*responseData, error) {
+	var responses []*responseData
+	err := action.IterateResponses(func(r *design.ResponseDefinition) error {
+		rd := &responseData{Name: codegen.Goify(r.Name, true), Status: r.Status}
+		if mt := design.Design.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			if p, _, err := mt.Project(view); err == nil {
+				rd.TypeRef = decodeGoTypeRef(p, p.AllRequired(), 0, false)
+				rd.DecodeFunc = "Decode" + typeName(p)
+			}
+		}
+		responses = append(responses, rd)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(byStatus(responses))
+	return responses, nil
 }
 
 // fileServerMethod returns the name of the client method for downloading assets served by the given
@@ -638,6 +768,7 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -735,6 +866,9 @@ func decodeGoTypeName(t design.DataType, required []string, tabs int, private bo
 // cmdFieldType computes the Go type name used to store command flags of the given design type.
 func cmdFieldType(t design.DataType, point bool) string {
 	var pointer, suffix string
+	if ut, ok := t.(*design.UserTypeDefinition); ok && ut.IsObject() {
+		return "*" + codegen.GoTypeName(ut, nil, 0, false)
+	}
 	if point && !t.IsArray() {
 		pointer = "*"
 	}
@@ -748,9 +882,9 @@ func cmdFieldTypeString(t design.DataType, point bool) string {
 	if point && !t.IsArray() {
 		pointer = "*"
 	}
-	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
+	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.DateKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind || t.IsObject() {
 		suffix = "string"
-	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
+	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.DateKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
 		suffix = "[]string"
 	} else {
 		suffix = codegen.GoNativeType(t)
@@ -790,6 +924,8 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 			return fmt.Sprintf("%s := %s", target, name)
 		case design.DateTimeKind:
 			return fmt.Sprintf("%s := %s.Format(time.RFC3339)", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
+		case design.DateKind:
+			return fmt.Sprintf("%s := %s.Format(goa.DateLayout)", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
 		case design.UUIDKind:
 			return fmt.Sprintf("%s := %s.String()", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
 		case design.AnyKind:
@@ -889,6 +1025,7 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 				param.IsArray = true
 				param.ElemAttribute = q.Type.ToArray().ElemType
 			}
+			param.IsDeepObject = q.IsDeepObject()
 			param.MustToString = true
 			param.ValueName = varName
 			param.CheckNil = true
@@ -913,9 +1050,25 @@ type paramData struct {
 	ElemAttribute *design.AttributeDefinition
 	MustToString  bool
 	IsArray       bool
+	IsDeepObject  bool
 	CheckNil      bool
 }
 
+// responseData is the data structure holding the information needed to generate the field and
+// decoding code of a single response in an action result type.
+type responseData struct {
+	Name       string
+	Status     int
+	TypeRef    string
+	DecodeFunc string
+}
+
+type byStatus []*responseData
+
+func (b byStatus) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byStatus) Less(i, j int) bool { return b[i].Status < b[j].Status }
+func (b byStatus) Len() int           { return len(b) }
+
 type byParamName []*paramData
 
 func (b byParamName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -932,7 +1085,8 @@ const (
 
 	payloadTmpl = `// {{ gotypename .Payload nil 0 false }} is the {{ .Parent.Name }} {{ .Name }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-`
+{{ $marshaler := nullableMarshaler .Payload.AttributeDefinition (gotypename .Payload nil 1 false) "payload" }}{{ if $marshaler }}
+{{ $marshaler }}{{ end }}`
 
 	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
 func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
@@ -976,12 +1130,21 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ range .QueryParams }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}{{/*
 
+// DEEP OBJECT
+*/}}{{ if .IsDeepObject }}		for k, v := range goa.EncodeDeepObject("{{ .Name }}", {{ .VarName }}) {
+			values[k] = v
+		}
+{{/*
+
 // ARRAY
-*/}}{{ if .IsArray }}		for _, p := range {{ .VarName }} {
+*/}}{{ else if .IsArray }}		for _, p := range {{ .VarName }} {
 {{ if .MustToString }}{{ $tmp := tempvar }}			{{ toString "p" $tmp .ElemAttribute }}
 			values.Add("{{ .Name }}", {{ $tmp }})
 {{ else }}			values.Add("{{ .Name }}", {{ .ValueName }})
-{{ end }}}{{/*
+{{ end }}}{{ if .Attribute.CollectionSeparator }}
+		if vs, ok := values["{{ .Name }}"]; ok {
+			values.Set("{{ .Name }}", strings.Join(vs, {{ printf "%q" .Attribute.CollectionSeparator }}))
+		}{{ end }}{{/*
 
 // NON STRING
 */}}{{ else if .MustToString }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
@@ -1043,14 +1206,19 @@ func (c * Client) {{ .Name }}(ctx context.Context, {{ if .DirName }}filename, {{
 */}}// {{ $funcName }} create the request corresponding to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if .HasPayload }}{{ if .HasMultiContent }}, contentType string{{ end }}{{ end }}) (*http.Request, error) {
 {{ if .HasPayload }}	var body bytes.Buffer
-{{ if .HasMultiContent }}	if contentType == "" {
+{{ if .Multipart }}	contentType, err := goa.EncodeMultipartForm(&body, payload)
+{{ else }}{{ if .HasMultiContent }}	if contentType == "" {
 		contentType = "*/*" // Use default encoder
 	}
 {{ end }}	err := c.Encoder.Encode(payload, &body, {{ if .HasMultiContent }}contentType{{ else }}"*/*"{{ end }})
-	if err != nil {
+{{ end }}	if err != nil {
 		return nil, fmt.Errorf("failed to encode body: %s", err)
 	}
-{{ end }}	scheme := c.Scheme
+{{ if .DigestAlgorithm }}	digest, err := goa.Digest({{ printf "%q" .DigestAlgorithm }}, body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+{{ end }}{{ end }}	scheme := c.Scheme
 	if scheme == "" {
 		scheme = "{{ .CanonicalScheme }}"
 	}
@@ -1058,13 +1226,22 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ if .QueryParams }}	values := u.Query()
 {{ range .QueryParams }}{{/*
 
+// DEEP OBJECT
+*/}}{{ if .IsDeepObject }}	for k, v := range goa.EncodeDeepObject("{{ .Name }}", {{ .VarName }}) {
+		values[k] = v
+	}
+{{/*
+
 // ARRAY
-*/}}{{ if .IsArray }}		for _, p := range {{ .VarName }} {
+*/}}{{ else if .IsArray }}		for _, p := range {{ .VarName }} {
 {{ if .MustToString }}{{ $tmp := tempvar }}			{{ toString "p" $tmp .ElemAttribute }}
 			values.Add("{{ .Name }}", {{ $tmp }})
 {{ else }}			values.Add("{{ .Name }}", {{ .ValueName }})
 {{ end }}	 }
-{{/*
+{{ if .Attribute.CollectionSeparator }}	if vs, ok := values["{{ .Name }}"]; ok {
+		values.Set("{{ .Name }}", strings.Join(vs, {{ printf "%q" .Attribute.CollectionSeparator }}))
+	}
+{{ end }}{{/*
 
 // NON STRING
 */}}{{ else if .MustToString }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
@@ -1084,12 +1261,14 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 		return nil, err
 	}
 {{ if or .HasPayload .Headers }}	header := req.Header
-{{ if .HasPayload }}{{ if .HasMultiContent }}	if contentType == "*/*" {
+{{ if .HasPayload }}{{ if .Multipart }}	header.Set("Content-Type", contentType)
+{{ else if .HasMultiContent }}	if contentType == "*/*" {
 		header.Set("Content-Type", "{{ .DefaultContentType }}")
 	} else {
 		header.Set("Content-Type", contentType)
 	}
 {{ else }}	header.Set("Content-Type", "{{ .DefaultContentType }}")
+{{ end }}{{ if .DigestAlgorithm }}	header.Set("Digest", digest)
 {{ end }}{{ end }}{{ range .Headers }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 {{ end }}{{ if .MustToString }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
@@ -1102,6 +1281,72 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 {{ end }}	return req, nil
 }
+`
+
+	signTmpl = `{{ $funcName := goify (printf "Sign%s%sURL" (title .Name) (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }} returns a pre-signed URL that may be used to invoke the {{ .Name }} action of
+// the {{ .ResourceName }} resource without authenticating until ttl elapses. claims may be nil.
+func (c *Client) {{ $funcName }}(path string, secret []byte, ttl time.Duration, claims map[string]string) string {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "{{ .CanonicalScheme }}"
+	}
+	u := &url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	return signedurl.Sign(secret, {{ $route := index .Routes 0 }}"{{ $route.Verb }}", u, time.Now().Add(ttl), claims).String()
+}
+`
+
+	resultTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Result is the decoded response of the {{ .Name }} action of the {{ .ResourceName }} resource.
+// Only the field corresponding to Status is set.
+type {{ $funcName }}Result struct {
+	// Status is the HTTP status code of the response.
+	Status int
+{{ range .Responses }}{{ if .TypeRef }}	// {{ .Name }} is set when the response status is {{ .Status }}.
+	{{ .Name }} {{ .TypeRef }}
+{{ end }}{{ end }}}
+
+// Decode{{ $funcName }}Response decodes resp into a {{ $funcName }}Result, the decoder is selected
+// using the response status and content type. Error responses (4xx and 5xx) also cause a
+// *goaclient.ResponseError to be returned.
+func (c *Client) Decode{{ $funcName }}Response(resp *http.Response) (*{{ $funcName }}Result, error) {
+	res := &{{ $funcName }}Result{Status: resp.StatusCode}
+	switch resp.StatusCode {
+{{ range .Responses }}	case {{ .Status }}:
+{{ if .DecodeFunc }}		decoded, err := c.{{ .DecodeFunc }}(resp)
+		if err != nil {
+			return nil, err
+		}
+		res.{{ .Name }} = decoded
+{{ if ge .Status 400 }}		return res, goaclient.NewResponseError(resp, decoded)
+{{ end }}{{ else if ge .Status 400 }}		return res, goaclient.NewResponseError(resp, nil)
+{{ end }}{{ end }}	default:
+		if err := c.Decode{{ $funcName }}Error(resp); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return res, nil
+}
+`
+
+	errorTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// Decode{{ $funcName }}Error decodes the body of a 4xx or 5xx response of the {{ .Name }} action of the
+// {{ .ResourceName }} resource into the error media type defined in the design for the response
+// status, into a goa error response by default. It returns a *goaclient.ResponseError exposing the
+// response status, headers and decoded body, nil if resp is not an error response.
+func (c *Client) Decode{{ $funcName }}Error(resp *http.Response) error {
+{{ if .Responses }}	goaclient.BufferBody(resp)
+	switch resp.StatusCode {
+{{ range .Responses }}	case {{ .Status }}:
+		decoded, err := c.{{ .DecodeFunc }}(resp)
+		if err != nil {
+			return goaclient.NewResponseError(resp, nil)
+		}
+		return goaclient.NewResponseError(resp, decoded)
+{{ end }}	}
+{{ end }}	return c.DecodeError(resp)
+}
 `
 
 	clientTmpl = `// Client is the {{ .API.Name }} service client.
@@ -1136,6 +1381,21 @@ func New(c goaclient.Doer) *Client {
 {{ end }}	return client
 }
 
+// DecodeError decodes the body of a 4xx or 5xx response into a goa error response. It returns a
+// *goaclient.ResponseError exposing the response status, headers and decoded body, nil if resp is
+// not an error response.
+func (c *Client) DecodeError(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	goaclient.BufferBody(resp)
+	var body goa.ErrorResponse
+	if err := c.Decoder.Decode(&body, resp.Body, resp.Header.Get("Content-Type")); err != nil {
+		return goaclient.NewResponseError(resp, nil)
+	}
+	return goaclient.NewResponseError(resp, &body)
+}
+
 {{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
 */}}{{ $name := printf "%sSigner" (goify $security.SchemeName true) }}{{/*
 */}}// Set{{ $name }} sets the request signer for the {{ $security.SchemeName }} security scheme.
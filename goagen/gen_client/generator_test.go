@@ -298,6 +298,23 @@ var _ = Describe("Generate", func() {
 				Ω(files).Should(HaveLen(5)) // 9, minus 4 entries for tool paths
 			})
 		})
+
+		Context("with a csv collection format", func() {
+			BeforeEach(func() {
+				o := design.Design.Resources["foo"].Actions["show"].QueryParams.Type.ToObject()
+				o["fields[bar]"].CollectionFormat = design.CollectionFormatCSV
+			})
+
+			It("joins the array elements", func() {
+				Ω(genErr).Should(BeNil())
+				c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(c)).Should(ContainSubstring(`	if vs, ok := values["fields[bar]"]; ok {
+		values.Set("fields[bar]", strings.Join(vs, ","))
+	}
+`))
+			})
+		})
 	})
 
 	Context("with an action using websocket", func() {
@@ -339,11 +356,15 @@ var _ = Describe("Generate", func() {
 
 		It("generates param initialization code that uses the param name given in the design", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(10))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
 			Ω(content).Should(ContainSubstring("func ShowFooPath("))
+			Ω(content).ShouldNot(ContainSubstring("websocket"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "client", "foo_native.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(c)
 			Ω(content).Should(ContainSubstring(`values.Set("fields[foo]", *fieldsFoo)
 `))
 			Ω(content).Should(ContainSubstring(`	if fieldsBar != nil {
@@ -372,9 +393,19 @@ var _ = Describe("Generate", func() {
 
 			It("should not return an error", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(5)) // 9, minus 4 entries for tool paths
+				Ω(files).Should(HaveLen(6)) // 10, minus 4 entries for tool paths
 			})
 		})
+
+		It("generates the websocket client in a file excluded from js builds", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo_native.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("// +build !js\n"))
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowFoo(ctx context.Context, path string"))
+			Ω(content).Should(ContainSubstring("(*websocket.Conn, error)"))
+		})
 	})
 
 	Context("with an action with multiple routes", func() {
@@ -433,12 +464,17 @@ var _ = Describe("Generate", func() {
 				}
 			})
 
-			It("generates a Download function", func() {
+			It("generates a Download function excluded from js builds", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
-				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+				Ω(files).Should(HaveLen(10))
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo_native.go"))
 				Ω(err).ShouldNot(HaveOccurred())
+				Ω(content).Should(ContainSubstring("// +build !js\n"))
 				Ω(content).Should(ContainSubstring("func (c *Client) DownloadSwaggerJSON("))
+				content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(content).ShouldNot(ContainSubstring("DownloadSwaggerJSON"))
+				Ω(content).ShouldNot(ContainSubstring(`"os"`))
 			})
 
 		})
@@ -512,6 +548,193 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an action with multiple responses", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					design.ErrorMediaIdentifier: design.ErrorMedia,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200},
+									"BadRequest": {
+										Name:      "BadRequest",
+										Status:    400,
+										MediaType: design.ErrorMediaIdentifier,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates a result type with one field per response", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("type ShowFooResult struct {"))
+			Ω(content).Should(ContainSubstring("BadRequest *goa.ErrorResponse"))
+			Ω(content).ShouldNot(ContainSubstring("OK *"))
+		})
+
+		It("generates a decode function that switches on the response status", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func (c *Client) DecodeShowFooResponse(resp *http.Response) (*ShowFooResult, error) {"))
+			Ω(content).Should(ContainSubstring("case 200:\n\tcase 400:\n\t\tdecoded, err := c.DecodeErrorResponse(resp)"))
+		})
+
+		It("returns a response error for error statuses", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("return res, goaclient.NewResponseError(resp, decoded)"))
+			Ω(content).Should(ContainSubstring("if err := c.DecodeShowFooError(resp); err != nil {"))
+			Ω(content).Should(ContainSubstring("func (c *Client) DecodeShowFooError(resp *http.Response) error {"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func (c *Client) DecodeError(resp *http.Response) error {"))
+		})
+	})
+
+	Context("with an action with a single error response", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			conflict := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"reason": {Type: design.String}},
+					},
+					TypeName: "Conflict",
+				},
+				Identifier: "application/vnd.conflict+json",
+			}
+			conflict.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: conflict.AttributeDefinition,
+				Name:                "default",
+				Parent:              conflict,
+			}}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{
+					"application/vnd.conflict": conflict,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"update": {
+								Name: "update",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "PUT",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"Conflict": {
+										Name:      "Conflict",
+										Status:    409,
+										MediaType: "application/vnd.conflict+json",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			updateAct := fooRes.Actions["update"]
+			updateAct.Parent = fooRes
+			updateAct.Routes[0].Parent = updateAct
+		})
+
+		It("decodes the error response into the error media type of the design", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).ShouldNot(ContainSubstring("UpdateFooResult"))
+			Ω(content).Should(ContainSubstring(`func (c *Client) DecodeUpdateFooError(resp *http.Response) error {
+	goaclient.BufferBody(resp)
+	switch resp.StatusCode {
+	case 409:
+		decoded, err := c.DecodeConflict(resp)
+		if err != nil {
+			return goaclient.NewResponseError(resp, nil)
+		}
+		return goaclient.NewResponseError(resp, decoded)
+	}
+	return c.DecodeError(resp)
+}`))
+		})
+	})
+
+	Context("with an action accepting signed URLs", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"download": {
+								Name:      "download",
+								SignedURL: true,
+								Params: &design.AttributeDefinition{Type: design.Object{
+									"id": {Type: design.String},
+								}},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/files/:id",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			downloadAct := fooRes.Actions["download"]
+			downloadAct.Parent = fooRes
+			downloadAct.Routes[0].Parent = downloadAct
+		})
+
+		It("generates the URL signer", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func (c *Client) SignDownloadFooURL(path string, secret []byte, ttl time.Duration, claims map[string]string) string {"))
+			Ω(content).Should(ContainSubstring(`signedurl.Sign(secret, "GET", u, time.Now().Add(ttl), claims)`))
+		})
+	})
+
 	Context("with an action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
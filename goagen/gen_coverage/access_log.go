@@ -0,0 +1,140 @@
+package gencoverage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/goadesign/goa/goagen/gen_proxy"
+)
+
+var (
+	// logLineRegex matches the lines written by the goa log adapter and captures the message
+	// and the key/value pairs.
+	logLineRegex = regexp.MustCompile(`\[(?:INFO|EROR)\] (\S+)(.*)$`)
+
+	// keyRegex matches the keys of the key/value pairs of a log line.
+	keyRegex = regexp.MustCompile(`(?:^|\s)([^\s=]+)=`)
+
+	// httpMethods lists the methods that may appear as keys of the "started" log lines.
+	httpMethods = map[string]bool{
+		"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true,
+		"DELETE": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+	}
+)
+
+// keyval is a key/value pair of a log line.
+type keyval struct {
+	key, value string
+}
+
+// ParseAccessLog reads the records of the requests logged by the LogRequest middleware of the
+// goa middleware package. The lines of a request are correlated using the "req_id" key added by
+// the RequestID middleware. The params and payload of the requests are only available when the
+// middleware is verbose.
+func ParseAccessLog(r io.Reader) ([]*genproxy.Record, error) {
+	var records []*genproxy.Record
+	pending := make(map[string]*genproxy.Record)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		m := logLineRegex.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		kvs := parseKeyvals(m[2])
+		var id string
+		for _, kv := range kvs {
+			if kv.key == "req_id" {
+				id = kv.value
+			}
+		}
+		switch m[1] {
+		case "started":
+			rec := &genproxy.Record{}
+			for _, kv := range kvs {
+				if !httpMethods[kv.key] {
+					continue
+				}
+				rec.Method = kv.key
+				u, err := url.Parse(kv.value)
+				if err != nil {
+					continue
+				}
+				rec.Path = u.Path
+				for k, v := range u.Query() {
+					if rec.Params == nil {
+						rec.Params = make(map[string]interface{})
+					}
+					rec.Params[k] = v[0]
+				}
+			}
+			pending[id] = rec
+		case "params":
+			rec, ok := pending[id]
+			if !ok {
+				continue
+			}
+			if rec.Params == nil {
+				rec.Params = make(map[string]interface{})
+			}
+			for _, kv := range kvs {
+				if kv.key != "req_id" {
+					rec.Params[kv.key] = kv.value
+				}
+			}
+		case "payload":
+			rec, ok := pending[id]
+			if !ok {
+				continue
+			}
+			payload := make(map[string]interface{})
+			for _, kv := range kvs {
+				switch kv.key {
+				case "req_id":
+				case "raw":
+					var raw interface{}
+					if err := json.Unmarshal([]byte(kv.value), &raw); err == nil {
+						rec.Payload = raw
+					}
+				default:
+					payload[kv.key] = kv.value
+				}
+			}
+			if rec.Payload == nil && len(payload) > 0 {
+				rec.Payload = payload
+			}
+		case "completed":
+			rec, ok := pending[id]
+			if !ok {
+				continue
+			}
+			delete(pending, id)
+			for _, kv := range kvs {
+				if kv.key == "status" {
+					rec.Status, _ = strconv.Atoi(kv.value)
+				}
+			}
+			records = append(records, rec)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// parseKeyvals parses the key/value pairs of a log line. Values may contain spaces, a value
+// extends until the next key.
+func parseKeyvals(s string) []keyval {
+	locs := keyRegex.FindAllStringSubmatchIndex(s, -1)
+	kvs := make([]keyval, len(locs))
+	for i, loc := range locs {
+		end := len(s)
+		if i < len(locs)-1 {
+			end = locs[i+1][0]
+		}
+		kvs[i] = keyval{key: s[loc[2]:loc[3]], value: s[loc[1]:end]}
+	}
+	return kvs
+}
@@ -0,0 +1,20 @@
+/*
+Package gencoverage provides a generator that reports which parts of the design have been
+exercised by the traffic of a service: the action routes, the response statuses, the media type
+views and the values of the enum params and payload attributes. Parts that are never exercised
+point to dead API surface or to missing tests.
+
+The traffic is read from access logs written by the LogRequest middleware and from the record
+files written by the debug-proxy command:
+
+	goagen coverage -d github.com/goadesign/goa-cellar/design --log access.log --records traffic.jsonl
+
+Both flags accept comma separated lists of files. The access logs must be written with the
+RequestID middleware so that the lines of a request can be correlated, and with a verbose
+LogRequest middleware for the enum values to be reported. The access logs do not contain the
+response views, only the proxy records do.
+
+The report is written to coverage/coverage.txt and coverage/coverage.json. It also lists the
+requests that do not match any route and the response statuses that are not defined in the design.
+*/
+package gencoverage
@@ -0,0 +1,13 @@
+package gencoverage_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenCoverage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Coverage Generator Suite")
+}
@@ -0,0 +1,148 @@
+package gencoverage
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_proxy"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a design coverage Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design coverage report generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Logs     []string              // Paths to the access log files
+	Records  []string              // Paths to the debug proxy record files
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, logs, records, ver string
+	set := flag.NewFlagSet("coverage", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&logs, "log", "", "")
+	set.StringVar(&records, "records", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Logs: splitList(logs), Records: splitList(records), API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate reads the traffic and writes the coverage report files.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if len(g.Logs) == 0 && len(g.Records) == 0 {
+		return nil, fmt.Errorf("missing traffic, use --log or --records to specify the access logs or debug proxy records")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	var records []*genproxy.Record
+	read := func(paths []string, parse func(io.Reader) ([]*genproxy.Record, error)) error {
+		for _, p := range paths {
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			recs, err := parse(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %s", p, err)
+			}
+			records = append(records, recs...)
+		}
+		return nil
+	}
+	if err = read(g.Logs, ParseAccessLog); err != nil {
+		return
+	}
+	if err = read(g.Records, genproxy.ReadRecords); err != nil {
+		return
+	}
+	report := NewReport(g.API, records)
+
+	g.OutDir = filepath.Join(g.OutDir, "coverage")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	textFile := filepath.Join(g.OutDir, "coverage.txt")
+	f, err := os.Create(textFile)
+	if err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, textFile)
+	report.WriteText(f)
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	jsonFile := filepath.Join(g.OutDir, "coverage.json")
+	js, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(jsonFile, append(js, '\n'), 0644); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, jsonFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// splitList splits a comma separated list of paths.
+func splitList(list string) []string {
+	var paths []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
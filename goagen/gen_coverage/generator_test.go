@@ -0,0 +1,173 @@
+package gencoverage_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_coverage"
+	"github.com/goadesign/goa/goagen/gen_proxy"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const accessLog = `2026/10/15 10:00:00 [INFO] started req_id=a1 GET=/cellar/bottles/1?color=red from=127.0.0.1 ctrl=BottleController action=show
+2026/10/15 10:00:00 [INFO] started req_id=b2 POST=/cellar/bottles from=127.0.0.1 ctrl=BottleController action=create
+2026/10/15 10:00:00 [INFO] payload req_id=b2 raw={"name":"Number 8","kind":"sparkling"}
+2026/10/15 10:00:00 [INFO] completed req_id=a1 status=200 bytes=42 time=1ms ctrl=BottleController action=show
+2026/10/15 10:00:00 [INFO] completed req_id=b2 status=500 error=internal bytes=42 time=1ms ctrl=BottleController action=create
+2026/10/15 10:00:00 [INFO] started req_id=c3 GET=/cellar/wines from=127.0.0.1 ctrl= action=
+2026/10/15 10:00:00 [INFO] completed req_id=c3 status=404 bytes=0 time=1ms ctrl= action=
+`
+
+var _ = Describe("Coverage", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/cellar")
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("name", design.String)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer)
+					apidsl.Param("color", design.String, func() {
+						apidsl.Enum("red", "white")
+					})
+				})
+				apidsl.Response(design.OK, bottle)
+				apidsl.Response(design.NotFound)
+			})
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST(""))
+				apidsl.Payload(func() {
+					apidsl.Attribute("name", design.String)
+					apidsl.Attribute("kind", design.String, func() {
+						apidsl.Enum("still", "sparkling")
+					})
+				})
+				apidsl.Response(design.Created)
+			})
+		})
+		dslengine.Run()
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+	})
+
+	Describe("ParseAccessLog", func() {
+		It("correlates the lines of each request", func() {
+			records, err := gencoverage.ParseAccessLog(strings.NewReader(accessLog))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(records).Should(HaveLen(3))
+			Ω(*records[0]).Should(Equal(genproxy.Record{
+				Method: "GET",
+				Path:   "/cellar/bottles/1",
+				Status: 200,
+				Params: map[string]interface{}{"color": "red"},
+			}))
+			Ω(records[1].Method).Should(Equal("POST"))
+			Ω(records[1].Status).Should(Equal(500))
+			Ω(records[1].Payload).Should(Equal(map[string]interface{}{"name": "Number 8", "kind": "sparkling"}))
+			Ω(records[2].Path).Should(Equal("/cellar/wines"))
+		})
+	})
+
+	Describe("NewReport", func() {
+		var report *gencoverage.Report
+
+		BeforeEach(func() {
+			records, err := gencoverage.ParseAccessLog(strings.NewReader(accessLog))
+			Ω(err).ShouldNot(HaveOccurred())
+			records = append(records, &genproxy.Record{Method: "GET", Path: "/cellar/bottles/2", Status: 200, View: "tiny"})
+			report = gencoverage.NewReport(design.Design, records)
+		})
+
+		It("counts the hits of each part of the design", func() {
+			Ω(report.Routes).Should(Equal([]*gencoverage.Item{
+				{Action: "bottle#create", Name: "POST /cellar/bottles", Hits: 1},
+				{Action: "bottle#show", Name: "GET /cellar/bottles/:id", Hits: 2},
+			}))
+			Ω(report.Responses).Should(Equal([]*gencoverage.Item{
+				{Action: "bottle#create", Name: "201 Created", Hits: 0},
+				{Action: "bottle#show", Name: "404 NotFound", Hits: 0},
+				{Action: "bottle#show", Name: "200 OK", Hits: 2},
+			}))
+			Ω(report.Views).Should(Equal([]*gencoverage.Item{
+				{Action: "bottle#show", Name: "200 application/vnd.bottle+json view default", Hits: 0},
+				{Action: "bottle#show", Name: "200 application/vnd.bottle+json view tiny", Hits: 1},
+			}))
+			Ω(report.Enums).Should(Equal([]*gencoverage.Item{
+				{Action: "bottle#create", Name: "payload.kind=still", Hits: 0},
+				{Action: "bottle#create", Name: "payload.kind=sparkling", Hits: 1},
+				{Action: "bottle#show", Name: "color=red", Hits: 1},
+				{Action: "bottle#show", Name: "color=white", Hits: 0},
+			}))
+		})
+
+		It("lists the traffic not defined in the design", func() {
+			Ω(report.Unknown).Should(Equal([]*gencoverage.Item{
+				{Name: "GET /cellar/wines", Hits: 1},
+				{Action: "bottle#create", Name: "500", Hits: 1},
+			}))
+		})
+
+		It("writes a text report", func() {
+			var buf bytes.Buffer
+			report.WriteText(&buf)
+			Ω(buf.String()).Should(ContainSubstring("Routes: 2/2 exercised\n"))
+			Ω(buf.String()).Should(ContainSubstring("  [ ] bottle#show 404 NotFound\n"))
+			Ω(buf.String()).Should(ContainSubstring("  [x] bottle#show 200 OK (2)\n"))
+			Ω(buf.String()).Should(ContainSubstring("Enum values: 2/4 exercised\n"))
+			Ω(buf.String()).Should(ContainSubstring("  bottle#create 500 (1)\n"))
+		})
+	})
+
+	Describe("Generate", func() {
+		var outDir string
+
+		BeforeEach(func() {
+			var err error
+			outDir, err = ioutil.TempDir("", "coverage")
+			Ω(err).ShouldNot(HaveOccurred())
+			logFile := filepath.Join(outDir, "access.log")
+			Ω(ioutil.WriteFile(logFile, []byte(accessLog), 0644)).Should(Succeed())
+			os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--log=" + logFile, "--version=" + version.String()}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outDir)
+		})
+
+		It("writes the report files", func() {
+			files, err := gencoverage.Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(files).Should(ConsistOf(
+				filepath.Join(outDir, "coverage"),
+				filepath.Join(outDir, "coverage", "coverage.txt"),
+				filepath.Join(outDir, "coverage", "coverage.json"),
+			))
+			js, err := ioutil.ReadFile(filepath.Join(outDir, "coverage", "coverage.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(js)).Should(ContainSubstring(`"name": "GET /cellar/bottles/:id"`))
+		})
+	})
+})
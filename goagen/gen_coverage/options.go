@@ -0,0 +1,34 @@
+package gencoverage
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Logs Paths to the access log files
+func Logs(logs ...string) Option {
+	return func(g *Generator) {
+		g.Logs = logs
+	}
+}
+
+//Records Paths to the debug proxy record files
+func Records(records ...string) Option {
+	return func(g *Generator) {
+		g.Records = records
+	}
+}
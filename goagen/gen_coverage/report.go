@@ -0,0 +1,304 @@
+package gencoverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/gen_proxy"
+)
+
+type (
+	// Report describes the parts of the API design exercised by a set of records.
+	Report struct {
+		// API is the name of the API.
+		API string `json:"api"`
+		// Routes lists the action routes.
+		Routes []*Item `json:"routes"`
+		// Responses lists the action responses.
+		Responses []*Item `json:"responses"`
+		// Views lists the views of the media types of the action responses.
+		Views []*Item `json:"views"`
+		// Enums lists the values of the enum params and payload attributes.
+		Enums []*Item `json:"enums"`
+		// Unknown lists the requests that do not match any route and the response statuses
+		// that are not defined in the design.
+		Unknown []*Item `json:"unknown,omitempty"`
+	}
+
+	// Item is a part of the API design together with the number of records that exercised it.
+	Item struct {
+		// Action is the name of the action the item belongs to, e.g. "bottle#show".
+		Action string `json:"action,omitempty"`
+		// Name describes the item, e.g. "GET /bottles/:id" or "200 OK".
+		Name string `json:"name"`
+		// Hits is the number of records that exercised the item.
+		Hits int `json:"hits"`
+	}
+
+	// actionItems indexes the items of an action.
+	actionItems struct {
+		routes    map[*design.RouteDefinition]*Item
+		responses map[int]*Item
+		views     map[string]*Item
+		enums     []*enumItem
+	}
+
+	// enumItem is an item that records the use of an enum value.
+	enumItem struct {
+		*Item
+		// path is the path to the attribute in the record params or payload.
+		path []string
+		// value is the enum value.
+		value interface{}
+		// split is true if the attribute is an array param whose values may be joined
+		// with commas in the access logs.
+		split bool
+	}
+)
+
+// NewReport computes the design coverage of the given records.
+func NewReport(api *design.APIDefinition, records []*genproxy.Record) *Report {
+	r := &Report{API: api.Name}
+	items := make(map[*design.ActionDefinition]*actionItems)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			name := res.Name + "#" + a.Name
+			ai := &actionItems{
+				routes:    make(map[*design.RouteDefinition]*Item),
+				responses: make(map[int]*Item),
+				views:     make(map[string]*Item),
+			}
+			items[a] = ai
+			for _, route := range a.Routes {
+				it := &Item{Action: name, Name: route.Verb + " " + route.FullPath()}
+				ai.routes[route] = it
+				r.Routes = append(r.Routes, it)
+			}
+			a.IterateResponses(func(resp *design.ResponseDefinition) error {
+				it := &Item{Action: name, Name: fmt.Sprintf("%d %s", resp.Status, resp.Name)}
+				ai.responses[resp.Status] = it
+				r.Responses = append(r.Responses, it)
+				mt, _ := resp.Type.(*design.MediaTypeDefinition)
+				if mt == nil && resp.MediaType != "" {
+					mt = api.MediaTypeWithIdentifier(resp.MediaType)
+				}
+				if mt == nil {
+					return nil
+				}
+				views := []string{resp.ViewName}
+				if resp.ViewName == "" {
+					views = nil
+					for v := range mt.ComputeViews() {
+						views = append(views, v)
+					}
+					sort.Strings(views)
+				}
+				for _, v := range views {
+					it := &Item{Action: name, Name: fmt.Sprintf("%d %s view %s", resp.Status, mt.Identifier, v)}
+					ai.views[fmt.Sprintf("%d %s", resp.Status, v)] = it
+					r.Views = append(r.Views, it)
+				}
+				return nil
+			})
+			params := a.AllParams().Type.ToObject()
+			for _, n := range sortedNames(params) {
+				enums := enumItems(name, []string{n}, params[n], make(map[string]bool))
+				if params[n].Type.IsArray() {
+					for _, e := range enums {
+						e.split = true
+					}
+				}
+				ai.enums = append(ai.enums, enums...)
+			}
+			if a.Payload != nil {
+				ai.enums = append(ai.enums, enumItems(name, []string{"payload"}, a.Payload.AttributeDefinition, make(map[string]bool))...)
+			}
+			for _, e := range ai.enums {
+				r.Enums = append(r.Enums, e.Item)
+			}
+			return nil
+		})
+	})
+
+	unknown := make(map[string]*Item)
+	addUnknown := func(action, name string) {
+		key := action + " " + name
+		if _, ok := unknown[key]; !ok {
+			unknown[key] = &Item{Action: action, Name: name}
+		}
+		unknown[key].Hits++
+	}
+	inspector := genproxy.NewInspector(api)
+	for _, rec := range records {
+		route := inspector.Route(rec.Method, rec.Path)
+		if route == nil {
+			addUnknown("", rec.Method+" "+rec.Path)
+			continue
+		}
+		ai := items[route.Parent]
+		ai.routes[route].Hits++
+		if it, ok := ai.responses[rec.Status]; ok {
+			it.Hits++
+		} else {
+			addUnknown(route.Parent.Parent.Name+"#"+route.Parent.Name, fmt.Sprintf("%d", rec.Status))
+		}
+		if it, ok := ai.views[fmt.Sprintf("%d %s", rec.Status, rec.View)]; ok {
+			it.Hits++
+		}
+		for _, e := range ai.enums {
+			if e.matches(rec) {
+				e.Hits++
+			}
+		}
+	}
+	keys := make([]string, 0, len(unknown))
+	for k := range unknown {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.Unknown = append(r.Unknown, unknown[k])
+	}
+
+	return r
+}
+
+// WriteText writes a human friendly version of the report to w.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Design coverage of the %s API\n", r.API)
+	sections := []struct {
+		title string
+		items []*Item
+	}{
+		{"Routes", r.Routes},
+		{"Responses", r.Responses},
+		{"Views", r.Views},
+		{"Enum values", r.Enums},
+	}
+	for _, s := range sections {
+		exercised := 0
+		for _, it := range s.items {
+			if it.Hits > 0 {
+				exercised++
+			}
+		}
+		fmt.Fprintf(w, "\n%s: %d/%d exercised\n", s.title, exercised, len(s.items))
+		for _, it := range s.items {
+			if it.Hits > 0 {
+				fmt.Fprintf(w, "  [x] %s %s (%d)\n", it.Action, it.Name, it.Hits)
+			} else {
+				fmt.Fprintf(w, "  [ ] %s %s\n", it.Action, it.Name)
+			}
+		}
+	}
+	if len(r.Unknown) > 0 {
+		fmt.Fprintf(w, "\nNot defined in the design:\n")
+		for _, it := range r.Unknown {
+			fmt.Fprintf(w, "  %s (%d)\n", strings.TrimSpace(it.Action+" "+it.Name), it.Hits)
+		}
+	}
+}
+
+// enumItems returns the items of the enum values of att and of its child attributes.
+func enumItems(action string, path []string, att *design.AttributeDefinition, seen map[string]bool) []*enumItem {
+	var items []*enumItem
+	if att.Validation != nil {
+		for _, v := range att.Validation.Values {
+			items = append(items, &enumItem{
+				Item:  &Item{Action: action, Name: fmt.Sprintf("%s=%v", strings.Join(path, "."), v)},
+				path:  path,
+				value: v,
+			})
+		}
+	}
+	var ut *design.UserTypeDefinition
+	switch t := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		ut = t
+	case *design.MediaTypeDefinition:
+		ut = t.UserTypeDefinition
+	case design.Object:
+		for _, n := range sortedNames(t) {
+			items = append(items, enumItems(action, append(path[:len(path):len(path)], n), t[n], seen)...)
+		}
+	case *design.Array:
+		items = append(items, enumItems(action, path, t.ElemType, seen)...)
+	case *design.Hash:
+		items = append(items, enumItems(action, append(path[:len(path):len(path)], "*"), t.ElemType, seen)...)
+	}
+	if ut != nil && !seen[ut.TypeName] {
+		// Guard against recursive types.
+		seen[ut.TypeName] = true
+		items = append(items, enumItems(action, path, ut.AttributeDefinition, seen)...)
+		delete(seen, ut.TypeName)
+	}
+	return items
+}
+
+// matches returns true if the record uses the enum value.
+func (e *enumItem) matches(rec *genproxy.Record) bool {
+	var vals []interface{}
+	if e.path[0] == "payload" {
+		vals = lookup(rec.Payload, e.path[1:])
+	} else {
+		vals = lookup(map[string]interface{}(rec.Params), e.path)
+	}
+	expected := fmt.Sprint(e.value)
+	for _, v := range vals {
+		if s, ok := v.(string); ok && e.split {
+			for _, part := range strings.Split(s, ",") {
+				if strings.TrimSpace(part) == expected {
+					return true
+				}
+			}
+			continue
+		}
+		if fmt.Sprint(v) == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the values found at the given path in val. The elements of arrays are
+// traversed and "*" matches all the keys of a map.
+func lookup(val interface{}, path []string) []interface{} {
+	if a, ok := val.([]interface{}); ok {
+		var vals []interface{}
+		for _, e := range a {
+			vals = append(vals, lookup(e, path)...)
+		}
+		return vals
+	}
+	if len(path) == 0 {
+		if val == nil {
+			return nil
+		}
+		return []interface{}{val}
+	}
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if path[0] != "*" {
+		return lookup(m[path[0]], path[1:])
+	}
+	var vals []interface{}
+	for _, v := range m {
+		vals = append(vals, lookup(v, path[1:])...)
+	}
+	return vals
+}
+
+// sortedNames returns the names of the object attributes in alphabetical order.
+func sortedNames(o design.Object) []string {
+	names := make([]string, 0, len(o))
+	for n := range o {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
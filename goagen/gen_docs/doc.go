@@ -0,0 +1,25 @@
+/*
+Package gendocs provides a generator for a documentation site describing the API. The generator
+writes a MkDocs project to docs/: docs/mkdocs.yml configures the site and docs/pages contains an
+overview page and one page per resource. Each action is documented with its routes, parameters,
+payload and responses followed by code samples showing how to call the action with the generated
+Go client, the generated CLI tool, the generated JavaScript client and curl.
+
+The long form documentation written in markdown with the Docs DSL is rendered below the short
+description of the API, actions and attributes:
+
+	Action("show", func() {
+		Description("Retrieve bottle with given id")
+		Docs(`Bottles are looked up in the **current account** only.`)
+		Routing(GET("/:id"))
+		Params(func() {
+			Param("id", Integer, "Bottle ID", func() {
+				Docs("IDs are allocated sequentially and never reused.")
+			})
+		})
+		Response(OK, BottleMedia)
+	})
+
+The site is built with "mkdocs build" run from the docs directory.
+*/
+package gendocs
@@ -0,0 +1,572 @@
+package gendocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a Docs Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the documentation site generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Target   string                // Name of generated Go client package
+	Tool     string                // Name of generated CLI tool
+	genfiles []string              // Generated files
+}
+
+type (
+	// resourceData is the data used to render a resource page.
+	resourceData struct {
+		Name        string
+		Description string
+		Actions     []*actionData
+	}
+
+	// actionData is the data used to render the documentation of an action.
+	actionData struct {
+		Name        string
+		Description string
+		Docs        *design.DocsDefinition
+		Routes      []string
+		Params      []*attributeData
+		Payload     []*attributeData
+		Example     string
+		Responses   []string
+		Samples     []*sample
+	}
+
+	// attributeData is the data used to render the documentation of a parameter, header or
+	// payload attribute.
+	attributeData struct {
+		Name        string
+		In          string
+		Type        string
+		Required    bool
+		Description string
+		Docs        *design.DocsDefinition
+	}
+
+	// sample is a code sample showing how to call an action.
+	sample struct {
+		Language string
+		Fence    string
+		Code     string
+	}
+)
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, target, tool, ver string
+	set := flag.NewFlagSet("docs", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&target, "pkg", "client", "")
+	set.StringVar(&tool, "tool", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Target: target, Tool: tool, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the documentation site.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Target == "" {
+		g.Target = "client"
+	}
+	if g.Tool == "" || g.Tool == "[API-name]-cli" {
+		g.Tool = strings.Replace(strings.ToLower(g.API.Name), " ", "-", -1) + "-cli"
+	}
+
+	var resources []*resourceData
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		r := &resourceData{Name: res.Name, Description: res.Description}
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			r.Actions = append(r.Actions, g.action(a))
+			return nil
+		})
+		resources = append(resources, r)
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "docs")
+	os.RemoveAll(g.OutDir)
+	pagesDir := filepath.Join(g.OutDir, "pages")
+	if err = os.MkdirAll(pagesDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	title := g.API.Title
+	if title == "" {
+		title = g.API.Name
+	}
+	if err = g.render(filepath.Join(g.OutDir, "mkdocs.yml"), mkdocsT, map[string]interface{}{
+		"Title":     title,
+		"Resources": resources,
+	}); err != nil {
+		return
+	}
+	if err = g.render(filepath.Join(pagesDir, "index.md"), indexT, map[string]interface{}{
+		"Title":     title,
+		"API":       g.API,
+		"Resources": resources,
+	}); err != nil {
+		return
+	}
+	for _, r := range resources {
+		if err = g.render(filepath.Join(pagesDir, page(r.Name)), resourceT, r); err != nil {
+			return
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.RemoveAll(f)
+	}
+	g.genfiles = nil
+}
+
+// render executes the given template with data and writes the result to path.
+func (g *Generator) render(path, tmpl string, data interface{}) error {
+	t, err := template.New(filepath.Base(path)).Funcs(template.FuncMap{
+		"page": page,
+		"yaml": func(s string) string { return fmt.Sprintf("%q", s) },
+	}).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, path)
+	return nil
+}
+
+// action builds the data used to render the documentation of the given action.
+func (g *Generator) action(a *design.ActionDefinition) *actionData {
+	rand := g.API.RandomGenerator()
+	data := &actionData{Name: a.Name, Description: a.Description, Docs: a.Docs}
+	for _, r := range a.Routes {
+		data.Routes = append(data.Routes, r.Verb+" "+r.FullPath())
+	}
+	pathParams := a.PathParams()
+	data.Params = append(data.Params, attributes(pathParams, "path")...)
+	if a.QueryParams != nil {
+		data.Params = append(data.Params, attributes(a.QueryParams, "query")...)
+	}
+	a.IterateHeaders(func(name string, isRequired bool, h *design.AttributeDefinition) error {
+		data.Params = append(data.Params, &attributeData{
+			Name:        name,
+			In:          "header",
+			Type:        h.Type.Name(),
+			Required:    isRequired,
+			Description: h.Description,
+			Docs:        h.Docs,
+		})
+		return nil
+	})
+	var body interface{}
+	if a.Payload != nil {
+		if a.Payload.Type.IsObject() {
+			data.Payload = attributes(a.Payload.AttributeDefinition, "body")
+		}
+		if body = codegen.Example(a.Payload.AttributeDefinition, rand); body != nil {
+			if b, err := json.MarshalIndent(body, "", "  "); err == nil {
+				data.Example = string(b)
+			}
+		}
+	}
+	responses := make([]*design.ResponseDefinition, 0, len(a.Responses))
+	for _, r := range a.Responses {
+		responses = append(responses, r)
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Status < responses[j].Status })
+	for _, r := range responses {
+		resp := fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+		if r.MediaType != "" {
+			resp += fmt.Sprintf(": `%s`", r.MediaType)
+		}
+		if r.Description != "" && r.Description != http.StatusText(r.Status) {
+			resp += " - " + r.Description
+		}
+		data.Responses = append(data.Responses, resp)
+	}
+	if len(a.Routes) > 0 {
+		vals := make(map[string]interface{})
+		examples := func(att *design.AttributeDefinition) {
+			for _, ad := range attributes(att, "") {
+				if v := codegen.Example(att.Type.ToObject()[ad.Name], rand); v != nil {
+					vals[ad.Name] = v
+				}
+			}
+		}
+		examples(a.AllParams())
+		if a.Headers != nil {
+			examples(a.Headers)
+		}
+		data.Samples = []*sample{
+			{Language: "Go", Fence: "go", Code: g.goSample(a, vals, body)},
+			{Language: "CLI", Fence: "sh", Code: g.cliSample(a, vals, body)},
+			{Language: "JavaScript", Fence: "js", Code: g.jsSample(a, vals, body)},
+			{Language: "curl", Fence: "sh", Code: g.curlSample(a, vals, body)},
+		}
+	}
+	return data
+}
+
+// goSample returns the code calling the action with the generated Go client.
+func (g *Generator) goSample(a *design.ActionDefinition, vals map[string]interface{}, body interface{}) string {
+	var buf bytes.Buffer
+	name := codegen.Goify(a.Name+strings.Title(a.Parent.Name), true)
+	route := a.Routes[0]
+	var pathArgs []string
+	for _, p := range route.Params() {
+		pathArgs = append(pathArgs, goLiteral(vals[p]))
+	}
+	args := []string{"ctx", fmt.Sprintf("%s.%sPath(%s)", g.Target, name, strings.Join(pathArgs, ", "))}
+	if a.Payload != nil {
+		ref := codegen.GoTypeRef(a.Payload, a.Payload.AllRequired(), 1, false)
+		fmt.Fprintf(&buf, "var payload %s\n", userTypeRegex.ReplaceAllString(ref, "${1}"+g.Target+".$2"))
+		fmt.Fprintf(&buf, "json.Unmarshal([]byte(`%s`), &payload)\n", compact(body))
+		args = append(args, "payload")
+	}
+	args = append(args, goArgs(a.QueryParams, vals)...)
+	args = append(args, goArgs(a.Headers, vals)...)
+	if a.Payload != nil && len(g.API.Consumes) > 1 && !a.PayloadMultipart {
+		args = append(args, `"application/json"`)
+	}
+	fmt.Fprintf(&buf, "resp, err := c.%s(%s)\n", name, strings.Join(args, ", "))
+	fmt.Fprintf(&buf, "if err != nil {\n\treturn err\n}\n")
+	if len(a.Responses) > 1 {
+		fmt.Fprintf(&buf, "res, err := c.Decode%sResponse(resp)", name)
+	} else {
+		buf.WriteString("defer resp.Body.Close()")
+	}
+	return buf.String()
+}
+
+// cliSample returns the command line calling the action with the generated CLI tool.
+func (g *Generator) cliSample(a *design.ActionDefinition, vals map[string]interface{}, body interface{}) string {
+	parts := []string{g.Tool, codegen.KebabCase(a.Name), codegen.KebabCase(a.Parent.Name)}
+	for _, p := range a.Routes[0].Params() {
+		if v, ok := vals[p]; ok {
+			parts = append(parts, fmt.Sprintf("--%s=%s", p, shellQuote(fmt.Sprint(v))))
+		}
+	}
+	for _, n := range required(a.QueryParams) {
+		if v, ok := vals[n]; ok {
+			parts = append(parts, fmt.Sprintf("--%s=%s", n, shellQuote(fmt.Sprint(v))))
+		}
+	}
+	for _, n := range required(a.Headers) {
+		if v, ok := vals[n]; ok {
+			parts = append(parts, fmt.Sprintf("--%s=%s", n, shellQuote(fmt.Sprint(v))))
+		}
+	}
+	if body != nil {
+		parts = append(parts, "--payload", shellQuote(compact(body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// jsSample returns the code calling the action with the generated JavaScript client.
+func (g *Generator) jsSample(a *design.ActionDefinition, vals map[string]interface{}, body interface{}) string {
+	args := []string{jsLiteral(path(a.Routes[0], vals))}
+	if a.Payload != nil {
+		args = append(args, compact(body))
+	}
+	if a.QueryParams != nil {
+		names := make([]string, 0, len(a.QueryParams.Type.ToObject()))
+		for n := range a.QueryParams.Type.ToObject() {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if v, ok := vals[n]; ok && a.QueryParams.IsRequired(n) {
+				args = append(args, compact(v))
+			} else {
+				args = append(args, "undefined")
+			}
+		}
+	}
+	return fmt.Sprintf("client.%s%s(%s)\n  .then(function (resp) { console.log(resp.data); })\n  .catch(function (resp) { console.error(resp.status); });",
+		a.Name, strings.Title(a.Parent.Name), strings.Join(args, ", "))
+}
+
+// curlSample returns the curl command line making the request to the action.
+func (g *Generator) curlSample(a *design.ActionDefinition, vals map[string]interface{}, body interface{}) string {
+	scheme := "http"
+	if len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
+	}
+	host := g.API.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	u := scheme + "://" + host + path(a.Routes[0], vals)
+	var query []string
+	for _, n := range required(a.QueryParams) {
+		if v, ok := vals[n]; ok {
+			query = append(query, n+"="+fmt.Sprint(v))
+		}
+	}
+	if len(query) > 0 {
+		u += "?" + strings.Join(query, "&")
+	}
+	parts := []string{"curl", "-X", a.Routes[0].Verb, shellQuote(u)}
+	for _, n := range required(a.Headers) {
+		if v, ok := vals[n]; ok {
+			parts = append(parts, "-H", shellQuote(fmt.Sprintf("%s: %v", n, v)))
+		}
+	}
+	if body != nil {
+		parts = append(parts, "-H", shellQuote("Content-Type: application/json"), "-d", shellQuote(compact(body)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// userTypeRegex matches the user type names in Go type references.
+var userTypeRegex = regexp.MustCompile(`(^|[\]*])([A-Z]\w*)`)
+
+// attributes returns the documentation data of the attributes of the given object attribute
+// sorted by name.
+func attributes(att *design.AttributeDefinition, in string) []*attributeData {
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	res := make([]*attributeData, len(names))
+	for i, n := range names {
+		a := obj[n]
+		res[i] = &attributeData{
+			Name:        n,
+			In:          in,
+			Type:        a.Type.Name(),
+			Required:    in == "path" || att.IsRequired(n),
+			Description: a.Description,
+			Docs:        a.Docs,
+		}
+	}
+	return res
+}
+
+// required returns the sorted names of the required attributes of att.
+func required(att *design.AttributeDefinition) []string {
+	if att == nil {
+		return nil
+	}
+	var names []string
+	for n := range att.Type.ToObject() {
+		if att.IsRequired(n) {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// goArgs returns the Go client arguments corresponding to the given query string parameters or
+// headers: the required attributes come first followed by the optional attributes, both sorted
+// by Go name as done by the client generator.
+func goArgs(att *design.AttributeDefinition, vals map[string]interface{}) []string {
+	if att == nil {
+		return nil
+	}
+	var req, opt []string
+	obj := att.Type.ToObject()
+	for n := range obj {
+		if att.IsRequired(n) {
+			req = append(req, n)
+		} else {
+			opt = append(opt, n)
+		}
+	}
+	byGoName := func(names []string) {
+		sort.Slice(names, func(i, j int) bool {
+			return codegen.Goify(names[i], false) < codegen.Goify(names[j], false)
+		})
+	}
+	byGoName(req)
+	byGoName(opt)
+	var args []string
+	for _, n := range req {
+		if obj[n].Type.IsPrimitive() {
+			args = append(args, goLiteral(vals[n]))
+		} else {
+			args = append(args, "nil")
+		}
+	}
+	for range opt {
+		args = append(args, "nil")
+	}
+	return args
+}
+
+// path returns the path of the given route with the wildcards replaced with the given values.
+func path(r *design.RouteDefinition, vals map[string]interface{}) string {
+	return design.WildcardRegex.ReplaceAllStringFunc(r.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		if v, ok := vals[name]; ok {
+			return "/" + fmt.Sprint(v)
+		}
+		return "/" + name
+	})
+}
+
+// page returns the name of the page documenting the given resource.
+func page(resource string) string {
+	return strings.Join(strings.Fields(codegen.KebabCase(resource)), "-") + ".md"
+}
+
+// goLiteral returns the Go literal of the given example value.
+func goLiteral(v interface{}) string {
+	if v == nil {
+		return `""`
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// jsLiteral returns the JavaScript string literal of s.
+func jsLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", `\'`, -1) + "'"
+}
+
+// compact returns the compact JSON representation of v.
+func compact(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// shellQuote quotes s so that it can be used as a single shell argument.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+const mkdocsT = `site_name: {{ yaml .Title }}
+docs_dir: pages
+nav:
+  - Overview: index.md
+{{ range .Resources }}  - {{ yaml .Name }}: {{ page .Name }}
+{{ end }}`
+
+const indexT = `# {{ .Title }}
+{{ with .API.Description }}
+{{ . }}
+{{ end }}{{ with .API.Docs }}{{ with .Markdown }}
+{{ . }}
+{{ end }}{{ if .URL }}
+See [{{ or .Description .URL }}]({{ .URL }}).
+{{ end }}{{ end }}
+## Resources
+{{ range .Resources }}
+* [{{ .Name }}]({{ page .Name }}){{ with .Description }}: {{ . }}{{ end }}{{ end }}
+`
+
+const resourceT = `# {{ .Name }}
+{{ with .Description }}
+{{ . }}
+{{ end }}{{ range .Actions }}
+## {{ .Name }}
+{{ range .Routes }}
+` + "`{{ . }}`" + `
+{{ end }}{{ with .Description }}
+{{ . }}
+{{ end }}{{ with .Docs }}{{ with .Markdown }}
+{{ . }}
+{{ end }}{{ if .URL }}
+See [{{ or .Description .URL }}]({{ .URL }}).
+{{ end }}{{ end }}{{ if .Params }}
+### Parameters
+{{ template "attributes" .Params }}{{ end }}{{ if or .Payload .Example }}
+### Payload
+{{ template "attributes" .Payload }}{{ with .Example }}
+` + "```json" + `
+{{ . }}
+` + "```" + `
+{{ end }}{{ end }}{{ if .Responses }}
+### Responses
+{{ range .Responses }}
+* {{ . }}{{ end }}
+{{ end }}{{ if .Samples }}
+### Examples
+{{ range .Samples }}
+#### {{ .Language }}
+
+` + "```{{ .Fence }}" + `
+{{ .Code }}
+` + "```" + `
+{{ end }}{{ end }}{{ end }}{{ define "attributes" }}{{ range . }}
+#### ` + "`{{ .Name }}`" + `
+
+*{{ .Type }}, {{ .In }}{{ if .Required }}, required{{ end }}*
+{{ with .Description }}
+{{ . }}
+{{ end }}{{ with .Docs }}{{ with .Markdown }}
+{{ . }}
+{{ end }}{{ if .URL }}
+See [{{ or .Description .URL }}]({{ .URL }}).
+{{ end }}{{ end }}{{ end }}{{ end }}`
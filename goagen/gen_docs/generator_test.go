@@ -0,0 +1,205 @@
+package gendocs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_docs"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("docstest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = gendocs.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a documented design", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Title("Cellar")
+				apidsl.Description("The wine cellar API")
+				apidsl.Docs("Bottles are **stored** in accounts.")
+				apidsl.BasePath("/api")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Description("A wine bottle")
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Description("Retrieve bottle with given id")
+					apidsl.Docs("Only bottles of the *current* account are returned.")
+					apidsl.Docs(func() {
+						apidsl.Description("Bottle guide")
+						apidsl.URL("http://example.com/bottles")
+					})
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer, "Bottle ID", func() {
+							apidsl.Example(42)
+							apidsl.Docs("IDs are never reused.")
+						})
+					})
+					apidsl.Response(design.OK, bottle)
+					apidsl.Response(design.NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", design.String, "Bottle name", func() {
+							apidsl.Example("Number 8")
+						})
+						apidsl.Required("name")
+					})
+					apidsl.Response(design.Created)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the site configuration and pages", func() {
+			Ω(genErr).Should(BeNil())
+			dir := filepath.Join(testPkg.Abs(), "docs")
+			Ω(files).Should(ConsistOf(
+				dir,
+				filepath.Join(dir, "mkdocs.yml"),
+				filepath.Join(dir, "pages", "index.md"),
+				filepath.Join(dir, "pages", "bottle.md"),
+			))
+			content, err := ioutil.ReadFile(filepath.Join(dir, "mkdocs.yml"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal(mkdocsYAML))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "pages", "index.md"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal(indexMD))
+		})
+
+		It("renders the markdown docs and the code samples", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "docs", "pages", "bottle.md"))
+			Ω(err).ShouldNot(HaveOccurred())
+			page := string(content)
+			Ω(page).Should(ContainSubstring("## show\n\n`GET /api/bottles/:id`\n\nRetrieve bottle with given id\n\nOnly bottles of the *current* account are returned.\n\nSee [Bottle guide](http://example.com/bottles).\n"))
+			Ω(page).Should(ContainSubstring("#### `id`\n\n*integer, path, required*\n\nBottle ID\n\nIDs are never reused.\n"))
+			Ω(page).Should(ContainSubstring("* 200 OK: `application/vnd.bottle+json`\n* 404 Not Found\n"))
+			Ω(page).Should(ContainSubstring("resp, err := c.ShowBottle(ctx, client.ShowBottlePath(42))\n"))
+			Ω(page).Should(ContainSubstring("res, err := c.DecodeShowBottleResponse(resp)\n"))
+			Ω(page).Should(ContainSubstring("test-api-cli show bottle --id=42\n"))
+			Ω(page).Should(ContainSubstring("client.showBottle('/api/bottles/42')\n"))
+			Ω(page).Should(ContainSubstring("curl -X GET http://localhost:8080/api/bottles/42\n"))
+			Ω(page).Should(ContainSubstring("var payload *client.CreateBottlePayload\njson.Unmarshal([]byte(`{\"name\":\"Number 8\"}`), &payload)\nresp, err := c.CreateBottle(ctx, client.CreateBottlePath(), payload, \"application/json\")\n"))
+			Ω(page).Should(ContainSubstring(`test-api-cli create bottle --payload '{"name":"Number 8"}'`))
+			Ω(page).Should(ContainSubstring(`client.createBottle('/api/bottles', {"name":"Number 8"})`))
+			Ω(page).Should(ContainSubstring(`curl -X POST http://localhost:8080/api/bottles -H 'Content-Type: application/json' -d '{"name":"Number 8"}'`))
+		})
+	})
+
+	Context("with a client package and tool name", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response(design.OK)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			os.Args = append(os.Args, "--pkg=cellar", "--tool=cellar")
+		})
+
+		It("uses them in the code samples", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "docs", "pages", "bottle.md"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("resp, err := c.ListBottle(ctx, cellar.ListBottlePath())\n"))
+			Ω(string(content)).Should(ContainSubstring("defer resp.Body.Close()\n"))
+			Ω(string(content)).Should(ContainSubstring("cellar list bottle\n"))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gendocs.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gendocs.NewGenerator(
+				gendocs.API(args.api),
+				gendocs.OutDir(args.outDir),
+				gendocs.Target("cellar"),
+				gendocs.Tool("cellar-cli"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Target).Should(Equal("cellar"))
+			Ω(generator.Tool).Should(Equal("cellar-cli"))
+		})
+	})
+})
+
+const mkdocsYAML = `site_name: "Cellar"
+docs_dir: pages
+nav:
+  - Overview: index.md
+  - "bottle": bottle.md
+`
+
+const indexMD = `# Cellar
+
+The wine cellar API
+
+Bottles are **stored** in accounts.
+
+## Resources
+
+* [bottle](bottle.md): A wine bottle
+`
@@ -0,0 +1,34 @@
+package gendocs
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Target Name of the generated Go client package used in the code samples
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
+
+//Tool Name of the generated CLI tool used in the code samples
+func Tool(tool string) Option {
+	return func(g *Generator) {
+		g.Tool = tool
+	}
+}
@@ -0,0 +1,16 @@
+/*
+Package gengateway provides a generator for API gateway configurations derived from the design.
+The generator writes the route, authentication, rate limit and CORS configuration of the API to
+the gateway directory:
+
+	gateway/kong.yml	Kong declarative configuration
+	gateway/envoy.yml	Envoy route configuration
+	gateway/traefik.yml	Traefik IngressRoute and middlewares
+
+The routes forward requests to the upstream service URL given on the command line. Authentication
+is configured using the plugins or filters native to each gateway: Kong uses the basic-auth,
+key-auth, jwt and oauth2 plugins, Envoy uses the jwt_authn and ext_authz filters and Traefik uses
+the basicAuth and forwardAuth middlewares (the latter only if an authentication service URL is
+given).
+*/
+package gengateway
@@ -0,0 +1,729 @@
+package gengateway
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of an API gateway configuration Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the API gateway configuration generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Upstream string                // URL of the service the gateways forward requests to
+	AuthURL  string                // URL of the authentication service used by forwardAuth
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, upstream, authURL, ver string
+	set := flag.NewFlagSet("gateway", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&upstream, "upstream", "", "")
+	set.StringVar(&authURL, "auth-url", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Upstream: upstream, AuthURL: authURL, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the Kong, Envoy and Traefik configuration files.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Upstream == "" {
+		g.Upstream = "http://localhost:8080"
+	}
+	u, err := url.Parse(g.Upstream)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream URL: %s", err)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	service := codegen.Slug(g.API.Name)
+	routes, policies, schemes := g.routes(service)
+	data := map[string]interface{}{
+		"Name":        g.API.Name,
+		"Service":     service,
+		"Upstream":    g.Upstream,
+		"Port":        port,
+		"AuthURL":     g.AuthURL,
+		"Host":        g.API.Host,
+		"Protocols":   protocols(g.API.Schemes),
+		"EntryPoints": entryPoints(g.API.Schemes),
+		"RateLimit":   newRateLimit(g.API.RateLimit),
+		"Routes":      routes,
+		"Policies":    policies,
+		"Schemes":     schemes,
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "gateway")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+	for _, gw := range []struct {
+		file string
+		tmpl *template.Template
+	}{
+		{"kong.yml", kongTmpl},
+		{"envoy.yml", envoyTmpl},
+		{"traefik.yml", traefikTmpl},
+	} {
+		if err = g.write(gw.file, gw.tmpl, data); err != nil {
+			return
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// write renders the given template into the file with the given name.
+func (g *Generator) write(name string, tmpl *template.Template, data interface{}) error {
+	path := filepath.Join(g.OutDir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	g.genfiles = append(g.genfiles, path)
+	return tmpl.Execute(f, data)
+}
+
+// routes returns the gateway routes of the API actions and file servers together with the CORS
+// policies and the security schemes they use.
+func (g *Generator) routes(service string) ([]*route, []*corsPolicy, []*auth) {
+	var (
+		routes   []*route
+		policies []*corsPolicy
+		schemes  []*auth
+		seen     = make(map[string]bool)
+	)
+	newRoute := func(name, verb string, paths []string, sec *design.SecurityDefinition, cors *corsPolicy) {
+		rt := &route{Name: name, Methods: []string{verb}, Auth: newAuth(service, sec), CORS: cors}
+		if cors != nil && verb != "OPTIONS" {
+			rt.Methods = append(rt.Methods, "OPTIONS")
+		}
+		for _, p := range paths {
+			rt.Paths = append(rt.Paths, codegen.PathRegexp(p))
+		}
+		if rt.Auth != nil && !seen[rt.Auth.Name] {
+			seen[rt.Auth.Name] = true
+			schemes = append(schemes, rt.Auth)
+		}
+		routes = append(routes, rt)
+	}
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		res := codegen.Slug(r.Name)
+		cors := newCORSPolicy(service+"-"+res+"-cors", r.AllOrigins())
+		if cors != nil {
+			policies = append(policies, cors)
+		}
+		r.IterateActions(func(a *design.ActionDefinition) error {
+			for i, ro := range a.Routes {
+				name := res + "-" + codegen.Slug(a.Name)
+				if i > 0 {
+					name = fmt.Sprintf("%s-%d", name, i+1)
+				}
+				paths := []string{ro.FullPath()}
+				for _, al := range ro.Aliases {
+					paths = append(paths, al.FullPath())
+				}
+				newRoute(name, ro.Verb, paths, a.Security, cors)
+			}
+			return nil
+		})
+		i := 0
+		r.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			i++
+			newRoute(fmt.Sprintf("%s-files-%d", res, i), "GET", []string{fs.RequestPath}, fs.Security, cors)
+			return nil
+		})
+		return nil
+	})
+	return routes, policies, schemes
+}
+
+type (
+	// route describes a gateway route.
+	route struct {
+		// Name is the route name.
+		Name string
+		// Methods lists the HTTP methods matched by the route.
+		Methods []string
+		// Paths lists the anchored regular expressions matching the route paths.
+		Paths []string
+		// Auth describes the route authentication if any.
+		Auth *auth
+		// CORS is the route CORS policy if any.
+		CORS *corsPolicy
+	}
+
+	// auth describes the authentication required by a route.
+	auth struct {
+		// Name is the security scheme name.
+		Name string
+		// Kind is one of "basic", "apiKey", "jwt" or "oauth2".
+		Kind string
+		// In is "header" or "query" for API key and JWT schemes.
+		In string
+		// KeyName is the name of the header or query string parameter holding the key.
+		KeyName string
+		// Flow is the OAuth2 flow.
+		Flow string
+		// Scopes lists the scopes defined by the scheme.
+		Scopes []string
+		// Required lists the scopes required by the route.
+		Required []string
+		// Middleware is the name of the Traefik authentication middleware.
+		Middleware string
+		// Secret is the name of the Kubernetes secret holding the Traefik basic auth users.
+		Secret string
+	}
+
+	// corsPolicy is the CORS policy that results from merging the CORS definitions of a
+	// resource.
+	corsPolicy struct {
+		// Name is the name of the Traefik headers middleware.
+		Name string
+		// Origins lists the allowed origins.
+		Origins []string
+		// Patterns lists the regular expressions matching the allowed origins.
+		Patterns []string
+		// Methods lists the allowed methods.
+		Methods []string
+		// Headers lists the allowed headers.
+		Headers []string
+		// Exposed lists the headers exposed to clients.
+		Exposed []string
+		// MaxAge is how long to cache a preflight request response in seconds.
+		MaxAge uint
+		// Credentials is true if credentials are allowed.
+		Credentials bool
+	}
+
+	// rateLimit describes a rate limit in the units used by the gateways.
+	rateLimit struct {
+		// Requests is the maximum number of requests during Period.
+		Requests int
+		// Period is the period of time the limit applies to.
+		Period time.Duration
+		// Unit is the largest of "second", "minute", "hour" and "day" that divides Period.
+		Unit string
+		// PerUnit is the maximum number of requests per Unit.
+		PerUnit int
+	}
+)
+
+// Regexp returns an anchored regular expression that matches all the route paths.
+func (r *route) Regexp() string {
+	if len(r.Paths) == 1 {
+		return r.Paths[0]
+	}
+	inner := make([]string, len(r.Paths))
+	for i, p := range r.Paths {
+		inner[i] = strings.TrimSuffix(strings.TrimPrefix(p, "^"), "$")
+	}
+	return "^(?:" + strings.Join(inner, "|") + ")$"
+}
+
+// MethodRegexp returns an anchored regular expression that matches the route methods.
+func (r *route) MethodRegexp() string {
+	return "^(?:" + strings.Join(r.Methods, "|") + ")$"
+}
+
+// newAuth returns the authentication described by the given security definition, nil if there is
+// none.
+func newAuth(service string, sec *design.SecurityDefinition) *auth {
+	if sec == nil || sec.Scheme == nil || sec.Scheme.Kind == design.NoSecurityKind {
+		return nil
+	}
+	s := sec.Scheme
+	a := &auth{
+		Name:       s.SchemeName,
+		In:         s.In,
+		KeyName:    s.Name,
+		Flow:       s.Flow,
+		Required:   sec.Scopes,
+		Middleware: service + "-" + codegen.Slug(s.SchemeName) + "-auth",
+		Secret:     service + "-" + codegen.Slug(s.SchemeName) + "-users",
+	}
+	switch s.Kind {
+	case design.BasicAuthSecurityKind:
+		a.Kind = "basic"
+	case design.APIKeySecurityKind:
+		a.Kind = "apiKey"
+	case design.JWTSecurityKind:
+		a.Kind = "jwt"
+	case design.OAuth2SecurityKind:
+		a.Kind = "oauth2"
+	}
+	for sc := range s.Scopes {
+		a.Scopes = append(a.Scopes, sc)
+	}
+	sort.Strings(a.Scopes)
+	return a
+}
+
+// newCORSPolicy merges the given CORS definitions into a single policy, it returns nil if there
+// are no definitions.
+func newCORSPolicy(name string, origins []*design.CORSDefinition) *corsPolicy {
+	if len(origins) == 0 {
+		return nil
+	}
+	var (
+		p                                = &corsPolicy{Name: name}
+		exact, patterns, methods, header = set{}, set{}, set{}, set{}
+		exposed                          = set{}
+	)
+	for _, o := range origins {
+		switch {
+		case o.Regexp:
+			patterns.add(o.Origin)
+		case o.Origin != "*" && strings.Contains(o.Origin, "*"):
+			patterns.add("^" + strings.Replace(regexp.QuoteMeta(o.Origin), `\*`, ".*", -1) + "$")
+		default:
+			exact.add(o.Origin)
+		}
+		methods.add(o.Methods...)
+		header.add(o.Headers...)
+		exposed.add(o.Exposed...)
+		if o.MaxAge > p.MaxAge {
+			p.MaxAge = o.MaxAge
+		}
+		p.Credentials = p.Credentials || o.Credentials
+	}
+	p.Origins, p.Patterns = exact.sorted(), patterns.sorted()
+	p.Methods, p.Headers, p.Exposed = methods.sorted(), header.sorted(), exposed.sorted()
+	return p
+}
+
+// AllOrigins returns the allowed origins followed by the regular expressions matching allowed
+// origins.
+func (p *corsPolicy) AllOrigins() []string {
+	return append(append([]string{}, p.Origins...), p.Patterns...)
+}
+
+// newRateLimit converts the given rate limit definition, it returns nil if there is none.
+func newRateLimit(rl *design.RateLimitDefinition) *rateLimit {
+	if rl == nil {
+		return nil
+	}
+	l := &rateLimit{Requests: rl.Requests, Period: rl.Period, Unit: "second", PerUnit: rl.Requests}
+	units := []struct {
+		name string
+		d    time.Duration
+	}{{"day", 24 * time.Hour}, {"hour", time.Hour}, {"minute", time.Minute}, {"second", time.Second}}
+	for _, u := range units {
+		if rl.Period >= u.d && rl.Period%u.d == 0 {
+			l.Unit = u.name
+			l.PerUnit = int(int64(rl.Requests) * int64(u.d) / int64(rl.Period))
+			break
+		}
+	}
+	if rl.Period < time.Second {
+		l.PerUnit = int(int64(rl.Requests) * int64(time.Second) / int64(rl.Period))
+	}
+	if l.PerUnit < 1 {
+		l.PerUnit = 1
+	}
+	return l
+}
+
+// FillInterval returns the rate limit period formatted as an Envoy duration.
+func (l *rateLimit) FillInterval() string {
+	return strconv.FormatFloat(l.Period.Seconds(), 'f', -1, 64) + "s"
+}
+
+// protocols returns the Kong route protocols corresponding to the given API schemes.
+func protocols(schemes []string) []string {
+	s := set{}
+	for _, sc := range schemes {
+		if sc == "https" || sc == "wss" {
+			s.add("https")
+		} else {
+			s.add("http")
+		}
+	}
+	return s.sorted()
+}
+
+// entryPoints returns the Traefik entry points corresponding to the given API schemes.
+func entryPoints(schemes []string) []string {
+	s := set{}
+	for _, sc := range schemes {
+		if sc == "https" || sc == "wss" {
+			s.add("websecure")
+		} else {
+			s.add("web")
+		}
+	}
+	return s.sorted()
+}
+
+// traefikRule returns the Traefik rule matching the given route.
+func traefikRule(host string, r *route) string {
+	var parts []string
+	if host != "" {
+		parts = append(parts, "Host(`"+host+"`)")
+	}
+	methods := make([]string, len(r.Methods))
+	for i, m := range r.Methods {
+		methods[i] = "Method(`" + m + "`)"
+	}
+	if len(methods) > 1 {
+		parts = append(parts, "("+strings.Join(methods, " || ")+")")
+	} else {
+		parts = append(parts, methods[0])
+	}
+	parts = append(parts, "PathRegexp(`"+r.Regexp()+"`)")
+	return strings.Join(parts, " && ")
+}
+
+// list renders the given values as a YAML flow sequence of quoted strings.
+func list(vals []string) string {
+	quoted := make([]string, len(vals))
+	for i, v := range vals {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// set is a set of strings.
+type set map[string]struct{}
+
+func (s set) add(vals ...string) {
+	for _, v := range vals {
+		s[v] = struct{}{}
+	}
+}
+
+func (s set) sorted() []string {
+	var vals []string
+	for v := range s {
+		vals = append(vals, v)
+	}
+	sort.Strings(vals)
+	return vals
+}
+
+var (
+	funcMap = template.FuncMap{
+		"list":        list,
+		"join":        strings.Join,
+		"traefikRule": traefikRule,
+		"kongPath":    func(p string) string { return strconv.Quote("~" + strings.TrimPrefix(p, "^")) },
+	}
+	kongTmpl    = template.Must(template.New("kong").Funcs(funcMap).Parse(kongT))
+	envoyTmpl   = template.Must(template.New("envoy").Funcs(funcMap).Parse(envoyT))
+	traefikTmpl = template.Must(template.New("traefik").Funcs(funcMap).Parse(traefikT))
+)
+
+const kongT = `# Code generated by goagen, DO NOT EDIT.
+# Kong declarative configuration of the {{ .Name }} API.
+_format_version: "3.0"
+services:
+- name: {{ .Service }}
+  url: {{ .Upstream }}
+{{- with .RateLimit }}
+  plugins:
+  - name: rate-limiting
+    config:
+      {{ .Unit }}: {{ .PerUnit }}
+      policy: local
+{{- end }}
+  routes:{{ if not .Routes }} []{{ end }}
+{{- range .Routes }}
+  - name: {{ .Name }}
+    methods: {{ list .Methods }}
+{{- if $.Protocols }}
+    protocols: {{ list $.Protocols }}
+{{- end }}
+{{- if $.Host }}
+    hosts: [{{ printf "%q" $.Host }}]
+{{- end }}
+    paths:
+{{- range .Paths }}
+    - {{ kongPath . }}
+{{- end }}
+    strip_path: false
+{{- if or .Auth .CORS }}
+    plugins:
+{{- with .Auth }}
+{{- if eq .Kind "basic" }}
+    - name: basic-auth
+{{- else if eq .Kind "apiKey" }}
+    - name: key-auth
+      config:
+        key_names: [{{ printf "%q" .KeyName }}]
+        key_in_header: {{ eq .In "header" }}
+        key_in_query: {{ eq .In "query" }}
+{{- else if eq .Kind "jwt" }}
+    - name: jwt
+{{- if and .KeyName (eq .In "header") }}
+      config:
+        header_names: [{{ printf "%q" .KeyName }}]
+{{- end }}
+{{- else if eq .Kind "oauth2" }}
+    - name: oauth2
+      config:
+        scopes: {{ list .Scopes }}
+        mandatory_scope: {{ if .Required }}true{{ else }}false{{ end }}
+        enable_authorization_code: {{ eq .Flow "accessCode" }}
+        enable_implicit_grant: {{ eq .Flow "implicit" }}
+        enable_password_grant: {{ eq .Flow "password" }}
+        enable_client_credentials: {{ eq .Flow "application" }}
+{{- end }}
+{{- end }}
+{{- with .CORS }}
+    - name: cors
+      config:
+        origins: {{ list .AllOrigins }}
+{{- if .Methods }}
+        methods: {{ list .Methods }}
+{{- end }}
+{{- if .Headers }}
+        headers: {{ list .Headers }}
+{{- end }}
+{{- if .Exposed }}
+        exposed_headers: {{ list .Exposed }}
+{{- end }}
+        credentials: {{ .Credentials }}
+{{- if .MaxAge }}
+        max_age: {{ .MaxAge }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+`
+
+const envoyT = `# Code generated by goagen, DO NOT EDIT.
+# Envoy route configuration of the {{ .Name }} API.
+name: {{ .Service }}
+virtual_hosts:
+- name: {{ .Service }}
+  domains: [{{ if .Host }}{{ printf "%q" .Host }}{{ else }}"*"{{ end }}]
+{{- with .RateLimit }}
+  typed_per_filter_config:
+    envoy.filters.http.local_ratelimit:
+      "@type": type.googleapis.com/envoy.extensions.filters.http.local_ratelimit.v3.LocalRateLimit
+      stat_prefix: {{ $.Service }}_rate_limit
+      token_bucket:
+        max_tokens: {{ .Requests }}
+        tokens_per_fill: {{ .Requests }}
+        fill_interval: {{ .FillInterval }}
+      filter_enabled:
+        default_value: {numerator: 100, denominator: HUNDRED}
+      filter_enforced:
+        default_value: {numerator: 100, denominator: HUNDRED}
+{{- end }}
+  routes:{{ if not .Routes }} []{{ end }}
+{{- range .Routes }}
+  - name: {{ .Name }}
+    match:
+      safe_regex:
+        regex: {{ printf "%q" .Regexp }}
+      headers:
+      - name: ":method"
+        string_match:
+          safe_regex:
+            regex: {{ printf "%q" .MethodRegexp }}
+    route:
+      cluster: {{ $.Service }}
+{{- if or .Auth .CORS }}
+    typed_per_filter_config:
+{{- with .Auth }}
+{{- if eq .Kind "jwt" }}
+      envoy.filters.http.jwt_authn:
+        "@type": type.googleapis.com/envoy.extensions.filters.http.jwt_authn.v3.PerRouteConfig
+        requirement_name: {{ .Name }}
+{{- else }}
+      envoy.filters.http.ext_authz:
+        "@type": type.googleapis.com/envoy.extensions.filters.http.ext_authz.v3.ExtAuthzPerRoute
+        check_settings:
+          context_extensions:
+            scheme: {{ printf "%q" .Name }}
+{{- if .Required }}
+            scopes: {{ printf "%q" (join .Required " ") }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .CORS }}
+      envoy.filters.http.cors:
+        "@type": type.googleapis.com/envoy.config.route.v3.CorsPolicy
+        allow_origin_string_match:
+{{- range .Origins }}
+{{- if eq . "*" }}
+        - safe_regex:
+            regex: ".*"
+{{- else }}
+        - exact: {{ printf "%q" . }}
+{{- end }}
+{{- end }}
+{{- range .Patterns }}
+        - safe_regex:
+            regex: {{ printf "%q" . }}
+{{- end }}
+{{- if .Methods }}
+        allow_methods: {{ printf "%q" (join .Methods ", ") }}
+{{- end }}
+{{- if .Headers }}
+        allow_headers: {{ printf "%q" (join .Headers ", ") }}
+{{- end }}
+{{- if .Exposed }}
+        expose_headers: {{ printf "%q" (join .Exposed ", ") }}
+{{- end }}
+{{- if .MaxAge }}
+        max_age: "{{ .MaxAge }}"
+{{- end }}
+        allow_credentials: {{ .Credentials }}
+{{- end }}
+{{- end }}
+{{- end }}
+`
+
+const traefikT = `# Code generated by goagen, DO NOT EDIT.
+# Traefik configuration of the {{ .Name }} API.
+apiVersion: traefik.io/v1alpha1
+kind: IngressRoute
+metadata:
+  name: {{ .Service }}
+spec:
+{{- if .EntryPoints }}
+  entryPoints: {{ list .EntryPoints }}
+{{- end }}
+  routes:{{ if not .Routes }} []{{ end }}
+{{- range .Routes }}
+  - kind: Rule
+    match: {{ printf "%q" (traefikRule $.Host .) }}
+    services:
+    - name: {{ $.Service }}
+      port: {{ $.Port }}
+{{- $auth := and .Auth (or (eq .Auth.Kind "basic") $.AuthURL) }}
+{{- if or $.RateLimit .CORS $auth }}
+    middlewares:
+{{- if $.RateLimit }}
+    - name: {{ $.Service }}-rate-limit
+{{- end }}
+{{- with .CORS }}
+    - name: {{ .Name }}
+{{- end }}
+{{- if $auth }}
+    - name: {{ .Auth.Middleware }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .RateLimit }}
+---
+apiVersion: traefik.io/v1alpha1
+kind: Middleware
+metadata:
+  name: {{ $.Service }}-rate-limit
+spec:
+  rateLimit:
+    average: {{ .Requests }}
+    period: {{ .Period }}
+    burst: {{ .Requests }}
+{{- end }}
+{{- range .Policies }}
+---
+apiVersion: traefik.io/v1alpha1
+kind: Middleware
+metadata:
+  name: {{ .Name }}
+spec:
+  headers:
+{{- if .Origins }}
+    accessControlAllowOriginList: {{ list .Origins }}
+{{- end }}
+{{- if .Patterns }}
+    accessControlAllowOriginListRegex: {{ list .Patterns }}
+{{- end }}
+{{- if .Methods }}
+    accessControlAllowMethods: {{ list .Methods }}
+{{- end }}
+{{- if .Headers }}
+    accessControlAllowHeaders: {{ list .Headers }}
+{{- end }}
+{{- if .Exposed }}
+    accessControlExposeHeaders: {{ list .Exposed }}
+{{- end }}
+{{- if .MaxAge }}
+    accessControlMaxAge: {{ .MaxAge }}
+{{- end }}
+    accessControlAllowCredentials: {{ .Credentials }}
+{{- end }}
+{{- range .Schemes }}
+{{- if or (eq .Kind "basic") $.AuthURL }}
+---
+apiVersion: traefik.io/v1alpha1
+kind: Middleware
+metadata:
+  name: {{ .Middleware }}
+spec:
+{{- if eq .Kind "basic" }}
+  basicAuth:
+    secret: {{ .Secret }}
+{{- else }}
+  forwardAuth:
+    address: {{ $.AuthURL }}
+{{- end }}
+{{- end }}
+{{- end }}
+`
@@ -0,0 +1,164 @@
+package gengateway_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_gateway"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("gatewaytest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--upstream=http://cellar:8080", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = gengateway.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a design defining security, CORS and rate limits", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Host("api.goa.design")
+				apidsl.Scheme("https")
+				apidsl.RateLimit(100, time.Minute)
+				apidsl.Origin("http://*.goa.design", func() {
+					apidsl.Methods("GET")
+					apidsl.Headers("X-Shared-Secret")
+					apidsl.MaxAge(600)
+				})
+			})
+			jwt := apidsl.JWTSecurity("jwt", func() {
+				apidsl.Header("Authorization")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id", func() {
+						apidsl.Alias("/legacy/:id")
+					}))
+					apidsl.Security(jwt)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		read := func(name string) string {
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "gateway", name))
+			Ω(err).ShouldNot(HaveOccurred())
+			return string(content)
+		}
+
+		It("generates the Kong configuration", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(4))
+			kong := read("kong.yml")
+			Ω(kong).Should(ContainSubstring("- name: test-api\n  url: http://cellar:8080\n"))
+			Ω(kong).Should(ContainSubstring("  - name: rate-limiting\n    config:\n      minute: 100\n"))
+			Ω(kong).Should(ContainSubstring(`  - name: bottle-show
+    methods: ["GET", "OPTIONS"]
+    protocols: ["https"]
+    hosts: ["api.goa.design"]
+    paths:
+    - "~/bottles/[^/]+$"
+    - "~/bottles/legacy/[^/]+$"
+    strip_path: false
+    plugins:
+    - name: jwt
+      config:
+        header_names: ["Authorization"]
+    - name: cors
+      config:
+        origins: ["^http://.*\\.goa\\.design$"]
+        methods: ["GET"]
+        headers: ["X-Shared-Secret"]
+        credentials: false
+        max_age: 600
+`))
+			Ω(kong).Should(ContainSubstring(`    - "~/bottles$"`))
+		})
+
+		It("generates the Envoy route configuration", func() {
+			Ω(genErr).Should(BeNil())
+			envoy := read("envoy.yml")
+			Ω(envoy).Should(ContainSubstring(`  domains: ["api.goa.design"]`))
+			Ω(envoy).Should(ContainSubstring("        fill_interval: 60s\n"))
+			Ω(envoy).Should(ContainSubstring(`        regex: "^(?:/bottles/[^/]+|/bottles/legacy/[^/]+)$"`))
+			Ω(envoy).Should(ContainSubstring(`            regex: "^(?:GET|OPTIONS)$"`))
+			Ω(envoy).Should(ContainSubstring("        requirement_name: jwt\n"))
+			Ω(envoy).Should(ContainSubstring(`        allow_methods: "GET"`))
+		})
+
+		It("generates the Traefik configuration", func() {
+			Ω(genErr).Should(BeNil())
+			traefik := read("traefik.yml")
+			Ω(traefik).Should(ContainSubstring(`  entryPoints: ["websecure"]`))
+			Ω(traefik).Should(ContainSubstring("match: \"Host(`api.goa.design`) && (Method(`GET`) || Method(`OPTIONS`)) && PathRegexp(`^/bottles$`)\""))
+			Ω(traefik).Should(ContainSubstring("      port: 8080\n    middlewares:\n    - name: test-api-rate-limit\n    - name: test-api-bottle-cors\n"))
+			Ω(traefik).Should(ContainSubstring("  rateLimit:\n    average: 100\n    period: 1m0s\n"))
+			Ω(traefik).Should(ContainSubstring(`    accessControlAllowOriginListRegex: ["^http://.*\\.goa\\.design$"]`))
+			Ω(traefik).ShouldNot(ContainSubstring("forwardAuth"))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gengateway.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gengateway.NewGenerator(
+				gengateway.API(args.api),
+				gengateway.OutDir(args.outDir),
+				gengateway.Upstream("http://cellar:8080"),
+				gengateway.AuthURL("http://auth:8080"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Upstream).Should(Equal("http://cellar:8080"))
+			Ω(generator.AuthURL).Should(Equal("http://auth:8080"))
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package gengateway
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Upstream URL of the service the gateways forward requests to
+func Upstream(upstream string) Option {
+	return func(g *Generator) {
+		g.Upstream = upstream
+	}
+}
+
+//AuthURL URL of the authentication service used by Traefik forwardAuth middlewares
+func AuthURL(authURL string) Option {
+	return func(g *Generator) {
+		g.AuthURL = authURL
+	}
+}
@@ -0,0 +1,15 @@
+/*
+Package genhexagonal provides a generator that lays out the skeleton of a service following the
+hexagonal (ports and adapters) architecture. The generated code is organized in four layers:
+
+	app/                 transport layer generated by "goagen app"
+	domain/              domain types mapped from the design user and media types
+	application/         application service interfaces (ports), one per resource, and their stubs
+	adapter/controller/  controllers implementing the transport interfaces by calling the
+	                     application services
+
+The domain types and the application service interfaces are regenerated each time the generator
+runs. The application service stubs, the controller adapters and the main function are only
+generated if they do not exist yet so that they may be edited freely.
+*/
+package genhexagonal
@@ -0,0 +1,13 @@
+package genhexagonal_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenHexagonal(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hexagonal Generator Suite")
+}
@@ -0,0 +1,487 @@
+package genhexagonal
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a hexagonal scaffold Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the hexagonal architecture scaffold generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	AppPkg   string                // Name of generated "app" package
+	Force    bool                  // Whether to override existing scaffolding files
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, appPkg, ver string
+		force               bool
+	)
+	set := flag.NewFlagSet("hexagonal", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&appPkg, "pkg", "app", "")
+	set.BoolVar(&force, "force", false, "")
+	set.Bool("notest", false, "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, AppPkg: appPkg, Force: force, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the domain, application and adapter packages.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+	elems := strings.Split(g.AppPkg, "/")
+	appName := elems[len(elems)-1]
+	outImport, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return nil, err
+	}
+	outImport = filepath.ToSlash(outImport)
+	appImport := g.AppPkg
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err != nil {
+		appImport = path.Join(outImport, g.AppPkg)
+	}
+	domainImport := path.Join(outImport, "domain")
+	applicationImport := path.Join(outImport, "application")
+	controllerImport := path.Join(outImport, "adapter", "controller")
+
+	var services []*serviceData
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		if s := newServiceData(g.API, r, appName); s != nil {
+			services = append(services, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	typeImports := []*codegen.ImportSpec{
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+	}
+	if err = g.writeFile(filepath.Join("domain", "types.go"), "Domain Types", "domain", domainT, domainTypes(g.API), typeImports, true); err != nil {
+		return
+	}
+	if err = g.writeFile(filepath.Join("application", "ports.go"), "Application Services", "application", portsT, services, append(typeImports,
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport(domainImport),
+	), true); err != nil {
+		return
+	}
+	if err = g.writeFile(filepath.Join("adapter", "controller", "convert.go"), "", "controller", convertT, nil, []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+	}, g.Force); err != nil {
+		return
+	}
+	for _, s := range services {
+		name := codegen.SnakeCase(s.Name) + ".go"
+		if err = g.writeFile(filepath.Join("application", name), "", "application", serviceT, s, []*codegen.ImportSpec{
+			codegen.SimpleImport("context"),
+			codegen.SimpleImport("errors"),
+			codegen.SimpleImport(domainImport),
+		}, g.Force); err != nil {
+			return
+		}
+		if err = g.writeFile(filepath.Join("adapter", "controller", name), "", "controller", controllerT, s, []*codegen.ImportSpec{
+			codegen.SimpleImport("github.com/goadesign/goa"),
+			codegen.SimpleImport(appImport),
+			codegen.SimpleImport(applicationImport),
+		}, g.Force); err != nil {
+			return
+		}
+	}
+	data := map[string]interface{}{
+		"API":      g.API,
+		"Services": services,
+		"AppPkg":   appName,
+		"Port":     port(g.API.Host),
+	}
+	if err = g.writeFile("main.go", "", "main", mainT, data, []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.SimpleImport(appImport),
+		codegen.SimpleImport(applicationImport),
+		codegen.SimpleImport(controllerImport),
+	}, g.Force); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// writeFile renders the given template into the file with the given name relative to the output
+// directory. Existing files are only overridden if overwrite is true. Files that are not
+// regenerated have no title.
+func (g *Generator) writeFile(name, title, pkg, tmpl string, data interface{}, imports []*codegen.ImportSpec, overwrite bool) error {
+	filename := filepath.Join(g.OutDir, name)
+	if _, err := os.Stat(filename); err == nil && !overwrite {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	os.Remove(filename)
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, filename)
+	if title != "" {
+		title = fmt.Sprintf("%s: %s", g.API.Context(), title)
+	}
+	if err := file.WriteHeader(title, pkg, imports); err != nil {
+		return err
+	}
+	funcs := template.FuncMap{
+		"lower": func(s string) string { return codegen.Goify(s, false) },
+	}
+	if err := file.ExecuteTemplate(name, tmpl, funcs, data); err != nil {
+		return err
+	}
+	return file.FormatCode()
+}
+
+type (
+	// domainType is a type of the domain package.
+	domainType struct {
+		Name        string // Go type name, e.g. "GoaExampleBottle"
+		Description string // Type description
+		Def         string // Go type definition
+	}
+
+	// serviceData contains the data needed to render the application service and controller
+	// adapter of a resource.
+	serviceData struct {
+		Name        string        // Go name of the resource, e.g. "Bottle"
+		Resource    string        // Design name of the resource, e.g. "bottle"
+		Actions     []*actionData // Resource actions sorted by name
+		Unsupported []*actionData // Actions not mapped to the application service, see streams
+	}
+
+	// actionData contains the data needed to render an application service method.
+	actionData struct {
+		Name       string   // Go name of the action, e.g. "Show"
+		Action     string   // Design name of the action, e.g. "show"
+		Context    string   // Name of the action context, e.g. "ShowBottleContext"
+		Input      string   // Name of the application service input struct, e.g. "ShowBottleInput"
+		Params     []*field // Action parameters sorted by name
+		Payload    string   // Go type of the input payload field if any, e.g. "*domain.BottlePayload"
+		Result     string   // Go type of the domain result if any, e.g. "*domain.Bottle"
+		Response   string   // Go type of the transport response if any, e.g. "*app.Bottle"
+		RespMethod string   // Name of the action context response method, e.g. "OK"
+	}
+
+	// field describes an input struct field.
+	field struct {
+		Name    string // Go struct field name
+		Type    string // Go type
+		Convert bool   // Whether the field holds a domain type converted from the transport type
+	}
+)
+
+// domainTypes returns the types of the domain package: the user types, the action payloads and
+// the views of the media types sorted by name.
+func domainTypes(api *design.APIDefinition) []*domainType {
+	seen := make(map[string]bool)
+	var types []*domainType
+	add := func(ds design.DataStructure, t design.DataType) {
+		name := codegen.GoTypeName(t, nil, 0, false)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		types = append(types, &domainType{
+			Name:        name,
+			Description: codegen.GoTypeDesc(t, true),
+			Def:         codegen.GoTypeDef(ds, 0, true, false),
+		})
+	}
+	api.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		add(t, t)
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				add(a.Payload, a.Payload)
+			}
+			return nil
+		})
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || !(mt.Type.IsObject() || mt.Type.IsArray()) {
+			return nil
+		}
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, links, err := mt.Project(v.Name)
+			if err != nil {
+				return nil
+			}
+			add(p, p)
+			if links != nil {
+				add(links, links)
+			}
+			return nil
+		})
+	})
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	return types
+}
+
+// newServiceData returns the data needed to render the application service of the given resource
+// or nil if the resource has no HTTP action.
+func newServiceData(api *design.APIDefinition, r *design.ResourceDefinition, appPkg string) *serviceData {
+	s := &serviceData{Name: codegen.Goify(r.Name, true), Resource: r.Name}
+	r.IterateActions(func(a *design.ActionDefinition) error {
+		name := codegen.Goify(a.Name, true)
+		ad := &actionData{
+			Name:    name,
+			Action:  a.Name,
+			Context: name + s.Name + "Context",
+			Input:   name + s.Name + "Input",
+		}
+		if streams(a) {
+			s.Unsupported = append(s.Unsupported, ad)
+			return nil
+		}
+		if params := a.AllParams(); params != nil {
+			for n, att := range params.Type.ToObject() {
+				f := &field{Name: codegen.GoifyAtt(att, n, true), Type: codegen.GoTypeRef(att.Type, nil, 0, false)}
+				if att.Type.IsPrimitive() && params.IsPrimitivePointer(n) {
+					f.Type = "*" + f.Type
+				}
+				if att.IsDeepObject() {
+					f.Type = qualify(f.Type, "domain")
+					f.Convert = true
+				}
+				ad.Params = append(ad.Params, f)
+			}
+			sort.Slice(ad.Params, func(i, j int) bool { return ad.Params[i].Name < ad.Params[j].Name })
+		}
+		if a.Payload != nil {
+			ad.Payload = qualify(codegen.GoTypeRef(a.Payload, nil, 0, false), "domain")
+		}
+		if resp, ok := a.Responses["OK"]; ok {
+			if mt, ok := api.MediaTypes[design.CanonicalIdentifier(resp.MediaType)]; ok && !mt.IsError() {
+				view := resp.ViewName
+				if view == "" {
+					view = design.DefaultView
+				}
+				if p, _, err := mt.Project(view); err == nil {
+					ref := codegen.GoTypeRef(p, p.AllRequired(), 0, false)
+					ad.Result = qualify(ref, "domain")
+					ad.Response = qualify(ref, appPkg)
+					ad.RespMethod = resp.Name
+					if view != design.DefaultView {
+						ad.RespMethod += codegen.Goify(view, true)
+					}
+				}
+			}
+		}
+		s.Actions = append(s.Actions, ad)
+		return nil
+	})
+	if len(s.Actions) == 0 {
+		return nil
+	}
+	return s
+}
+
+// streams returns true if the action reads or writes a stream rather than a single request and
+// response body: websocket, streaming payload, server-sent events, chunked and subscription
+// actions. The contexts of these actions do not expose a response method that accepts a body so
+// they are not mapped to the application service.
+func streams(a *design.ActionDefinition) bool {
+	if a.WebSocket() || a.PayloadStreaming || a.Subscription != nil {
+		return true
+	}
+	for _, r := range a.Responses {
+		if r.Streaming || r.Chunked {
+			return true
+		}
+	}
+	return false
+}
+
+// qualify prefixes the name of the type referred to by ref with the given package name, e.g.
+// "*Bottle" becomes "*app.Bottle".
+func qualify(ref, pkg string) string {
+	name := strings.TrimLeft(ref, "*[]")
+	return ref[:len(ref)-len(name)] + pkg + "." + name
+}
+
+// port returns the port of the given host or 8080 if none.
+func port(hostport string) string {
+	_, p, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "8080"
+	}
+	return p
+}
+
+const domainT = `{{ range . }}// {{ .Description }}
+type {{ .Name }} {{ .Def }}
+
+{{ end }}`
+
+const portsT = `{{ range . }}{{ $res := .Resource }}// {{ .Name }}Service implements the use cases of the {{ .Resource }} resource.
+type {{ .Name }}Service interface {
+{{ range .Actions }}	// {{ .Name }} implements the {{ .Action }} action.
+	{{ .Name }}(ctx context.Context, in *{{ .Input }}) {{ if .Result }}({{ .Result }}, error){{ else }}error{{ end }}
+{{ end }}}
+
+{{ range .Actions }}// {{ .Input }} is the input of the {{ $res }} {{ .Action }} action.
+type {{ .Input }} struct {
+{{ range .Params }}	{{ .Name }} {{ .Type }}
+{{ end }}{{ if .Payload }}	Payload {{ .Payload }}
+{{ end }}}
+
+{{ end }}{{ end }}`
+
+const serviceT = `{{ $name := printf "%sService" (lower .Name) }}// {{ $name }} implements the {{ .Name }}Service application service.
+type {{ $name }} struct{}
+
+// New{{ .Name }}Service returns the {{ .Resource }} application service.
+func New{{ .Name }}Service() {{ .Name }}Service {
+	return &{{ $name }}{}
+}
+{{ range .Actions }}
+// {{ .Name }} implements the {{ .Action }} action.
+func (s *{{ $name }}) {{ .Name }}(ctx context.Context, in *{{ .Input }}) {{ if .Result }}({{ .Result }}, error){{ else }}error{{ end }} {
+	// Put your logic here
+	return {{ if .Result }}nil, {{ end }}errors.New("not implemented")
+}
+{{ end }}`
+
+const controllerT = `{{ $name := .Name }}// {{ .Name }}Controller implements the {{ .Resource }} resource by delegating to the
+// application service.
+type {{ .Name }}Controller struct {
+	*goa.Controller
+	svc application.{{ .Name }}Service
+}
+
+// New{{ .Name }}Controller creates a {{ .Resource }} controller.
+func New{{ .Name }}Controller(service *goa.Service, svc application.{{ .Name }}Service) *{{ .Name }}Controller {
+	return &{{ .Name }}Controller{
+		Controller: service.NewController("{{ .Name }}Controller"),
+		svc:        svc,
+	}
+}
+{{ range .Actions }}
+// {{ .Name }} runs the {{ .Action }} action.
+func (c *{{ $name }}Controller) {{ .Name }}(ctx *app.{{ .Context }}) error {
+	in := &application.{{ .Input }}{
+{{ range .Params }}{{ if not .Convert }}		{{ .Name }}: ctx.{{ .Name }},
+{{ end }}{{ end }}	}
+{{ range .Params }}{{ if .Convert }}	if err := convert(ctx.{{ .Name }}, &in.{{ .Name }}); err != nil {
+		return err
+	}
+{{ end }}{{ end }}{{ if .Payload }}	if err := convert(ctx.Payload, &in.Payload); err != nil {
+		return err
+	}
+{{ end }}{{ if .Result }}	res, err := c.svc.{{ .Name }}(ctx, in)
+	if err != nil {
+		return err
+	}
+	var resp {{ .Response }}
+	if err := convert(res, &resp); err != nil {
+		return err
+	}
+	return ctx.{{ .RespMethod }}(resp)
+{{ else }}	return c.svc.{{ .Name }}(ctx, in)
+{{ end }}}
+{{ end }}{{ range .Unsupported }}
+// {{ .Name }} runs the {{ .Action }} action. The action streams its request or response and is not
+// mapped to the application service, implement it here.
+func (c *{{ $name }}Controller) {{ .Name }}(ctx *app.{{ .Context }}) error {
+	return goa.ErrInternal("not implemented")
+}
+{{ end }}`
+
+const convertT = `// convert copies the value of src into dst, src and dst must have the same JSON representation.
+// convert is used to map the transport types to the domain types and back.
+func convert(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+`
+
+const mainT = `func main() {
+	// Create service
+	service := goa.New({{ printf "%q" .API.Name }})
+
+	// Mount middleware
+	service.Use(middleware.RequestID())
+	service.Use(middleware.LogRequest(true))
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+{{ range .Services }}
+	// Mount "{{ .Resource }}" controller
+	{{ $.AppPkg }}.Mount{{ .Name }}Controller(service, controller.New{{ .Name }}Controller(service, application.New{{ .Name }}Service()))
+{{ end }}
+	// Start service
+	if err := service.ListenAndServe(":{{ .Port }}"); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+`
@@ -0,0 +1,208 @@
+package genhexagonal_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_hexagonal"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("hexagonaltest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genhexagonal.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	read := func(elems ...string) string {
+		content, err := ioutil.ReadFile(filepath.Join(append([]string{testPkg.Abs()}, elems...)...))
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(content)
+	}
+
+	Context("with a resource", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Title("dummy API")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.TypeName("Bottle")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+					apidsl.Attribute("name", design.String)
+					apidsl.Attribute("bottled_on", design.Date)
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("bottled_on")
+				})
+			})
+			filter := apidsl.Type("Filter", func() {
+				apidsl.Attribute("status", design.String)
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.DefaultMedia(bottle)
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("filter", filter)
+					})
+					apidsl.Response(design.NoContent)
+				})
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", design.String)
+					})
+					apidsl.Response(design.Created)
+				})
+				apidsl.Action("watch", func() {
+					apidsl.Routing(apidsl.GET("/:id/watch"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.StreamingResponse(bottle)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the hexagonal skeleton", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(6))
+
+			types := read("domain", "types.go")
+			Ω(types).Should(ContainSubstring("type Bottle struct {"))
+			Ω(types).Should(ContainSubstring("type CreateBottlePayload struct {"))
+
+			ports := read("application", "ports.go")
+			Ω(ports).Should(ContainSubstring("Show(ctx context.Context, in *ShowBottleInput) (*domain.Bottle, error)"))
+			Ω(ports).Should(ContainSubstring("Create(ctx context.Context, in *CreateBottleInput) error"))
+			Ω(ports).Should(ContainSubstring("Payload *domain.CreateBottlePayload"))
+
+			service := read("application", "bottle.go")
+			Ω(service).Should(ContainSubstring("func NewBottleService() BottleService {"))
+
+			ctrl := read("adapter", "controller", "bottle.go")
+			Ω(ctrl).Should(ContainSubstring("func (c *BottleController) Show(ctx *app.ShowBottleContext) error {"))
+			Ω(ctrl).Should(ContainSubstring("ID: ctx.ID,"))
+			Ω(ctrl).Should(ContainSubstring("return ctx.OK(resp)"))
+
+			main := read("main.go")
+			Ω(main).Should(ContainSubstring("app.MountBottleController(service, controller.NewBottleController(service, application.NewBottleService()))"))
+		})
+
+		Context("with existing scaffolding", func() {
+			BeforeEach(func() {
+				Ω(os.MkdirAll(filepath.Join(testPkg.Abs(), "application"), 0755)).Should(Succeed())
+				Ω(ioutil.WriteFile(filepath.Join(testPkg.Abs(), "application", "bottle.go"), []byte("package application\n"), 0644)).Should(Succeed())
+			})
+
+			It("does not override it", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(HaveLen(5))
+				Ω(read("application", "bottle.go")).Should(Equal("package application\n"))
+			})
+		})
+
+		It("generates domain types that compile", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(read("domain", "types.go")).Should(ContainSubstring("BottledOn *goa.Date"))
+
+			// Build a copy of the domain package from within this module so that the goa
+			// import resolves.
+			dir, err := ioutil.TempDir(".", "domain")
+			Ω(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Ω(ioutil.WriteFile(filepath.Join(dir, "types.go"), []byte(read("domain", "types.go")), 0644)).Should(Succeed())
+			out, err := exec.Command("go", "build", "./"+filepath.Base(dir)).CombinedOutput()
+			Ω(err).ShouldNot(HaveOccurred(), string(out))
+		})
+
+		It("converts the deep object params to domain types", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(read("application", "ports.go")).Should(ContainSubstring("Filter *domain.Filter"))
+			ctrl := read("adapter", "controller", "bottle.go")
+			Ω(ctrl).ShouldNot(ContainSubstring("Filter: ctx.Filter,"))
+			Ω(ctrl).Should(ContainSubstring("if err := convert(ctx.Filter, &in.Filter); err != nil {"))
+		})
+
+		It("does not map the streaming actions to the application service", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(read("application", "ports.go")).ShouldNot(ContainSubstring("Watch"))
+			ctrl := read("adapter", "controller", "bottle.go")
+			Ω(ctrl).Should(ContainSubstring("func (c *BottleController) Watch(ctx *app.WatchBottleContext) error {"))
+			Ω(ctrl).ShouldNot(ContainSubstring("c.svc.Watch"))
+			Ω(ctrl).Should(ContainSubstring(`return goa.ErrInternal("not implemented")`))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genhexagonal.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genhexagonal.NewGenerator(
+				genhexagonal.API(args.api),
+				genhexagonal.OutDir(args.outDir),
+				genhexagonal.AppPkg("myapp"),
+				genhexagonal.Force(true),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.AppPkg).Should(Equal("myapp"))
+			Ω(generator.Force).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,22 @@
+/*
+Package genload provides a generator for load test scenarios derived from the design so that
+performance testing uses the same contract as the implementation. The generator writes either a
+k6 script (load/script.js) or vegeta targets and a run script (load/targets.json and load/run.sh).
+
+Each action with a route becomes a request built from the examples of the action parameters,
+required headers and payload. Actions are picked at random proportionally to their weight read
+from the "load:weight" action metadata, the default weight is 1 and a weight of 0 excludes the
+action. Websocket and streaming actions are always excluded:
+
+	Action("show", func() {
+		Routing(GET("/:id"))
+		Metadata("load:weight", "5")
+		SLO("250ms", 99.9)
+		Response(OK)
+	})
+
+The service level objectives defined with the SLO DSL are the pass/fail criteria of the run: the
+k6 script defines one latency and one server error rate threshold per action while the vegeta run
+script checks the aggregated results against the strictest objectives.
+*/
+package genload
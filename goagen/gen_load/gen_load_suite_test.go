@@ -0,0 +1,13 @@
+package genload_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenLoad(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Load Generator Suite")
+}
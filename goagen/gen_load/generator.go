@@ -0,0 +1,465 @@
+package genload
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+const (
+	// ToolK6 is the name of the k6 load testing tool.
+	ToolK6 = "k6"
+	// ToolVegeta is the name of the vegeta load testing tool.
+	ToolVegeta = "vegeta"
+)
+
+// NewGenerator returns an initialized instance of a load test Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the load test scenario generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Tool     string                // Load testing tool, one of ToolK6 or ToolVegeta
+	Host     string                // Base URL of the service under test
+	Rate     int                   // Number of requests per second
+	Duration string                // Duration of the test, e.g. "1m"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, tool, host, duration, ver string
+	var rate int
+	set := flag.NewFlagSet("load", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&tool, "tool", "", "")
+	set.StringVar(&host, "host", "", "")
+	set.IntVar(&rate, "rate", 0, "")
+	set.StringVar(&duration, "duration", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Tool: tool, Host: host, Rate: rate, Duration: duration, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the load test scenario.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Tool == "" {
+		g.Tool = ToolK6
+	}
+	if g.Tool != ToolK6 && g.Tool != ToolVegeta {
+		return nil, fmt.Errorf("invalid load testing tool %#v, must be %#v or %#v", g.Tool, ToolK6, ToolVegeta)
+	}
+	if g.Host == "" {
+		g.Host = g.defaultHost()
+	}
+	if g.Rate <= 0 {
+		g.Rate = 10
+	}
+	if g.Duration == "" {
+		g.Duration = "1m"
+	}
+	if _, err = time.ParseDuration(g.Duration); err != nil {
+		return nil, fmt.Errorf("invalid duration %#v: %s", g.Duration, err)
+	}
+
+	var reqs []*request
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			r, err := g.request(a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			if r != nil {
+				reqs = append(reqs, r)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "load")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+	if g.Tool == ToolVegeta {
+		err = g.generateVegeta(reqs)
+	} else {
+		err = g.generateK6(reqs)
+	}
+	if err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// request contains the data needed to render the request of a single action.
+type request struct {
+	Name         string            `json:"name"`
+	Weight       int               `json:"weight"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         interface{}       `json:"body,omitempty"`
+	Latency      time.Duration     `json:"-"`
+	Availability float64           `json:"-"`
+}
+
+// defaultHost returns the base URL built from the API scheme and host, http://localhost:8080 if
+// the design does not define a host.
+func (g *Generator) defaultHost() string {
+	if g.API.Host == "" {
+		return "http://localhost:8080"
+	}
+	scheme := "http"
+	if len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
+	}
+	return scheme + "://" + g.API.Host
+}
+
+// request builds the request of the given action using the first action route and the examples
+// of the parameters, required headers and payload. It returns nil if the action has no route,
+// streams its response or has a weight of 0.
+func (g *Generator) request(a *design.ActionDefinition) (*request, error) {
+	if len(a.Routes) == 0 || a.WebSocket() {
+		return nil, nil
+	}
+	for _, r := range a.Responses {
+		if r.Streaming {
+			return nil, nil
+		}
+	}
+	weight := 1
+	if vals, ok := a.Metadata["load:weight"]; ok && len(vals) > 0 {
+		w, err := strconv.Atoi(vals[0])
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid load:weight metadata %#v, must be a positive integer", vals[0])
+		}
+		weight = w
+	}
+	if weight == 0 {
+		return nil, nil
+	}
+
+	rand := g.API.RandomGenerator()
+	ro := a.Routes[0]
+	params := a.AllParams().Type.ToObject()
+	path := design.WildcardRegex.ReplaceAllStringFunc(ro.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		var val interface{}
+		if att, ok := params[name]; ok {
+			val = codegen.Example(att, rand)
+		}
+		if val == nil {
+			val = name
+		}
+		return "/" + url.PathEscape(fmt.Sprint(val))
+	})
+	if a.QueryParams != nil {
+		obj := a.QueryParams.Type.ToObject()
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			if a.QueryParams.IsRequired(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		query := url.Values{}
+		for _, name := range names {
+			if val := codegen.Example(obj[name], rand); val != nil {
+				query.Set(name, fmt.Sprint(val))
+			}
+		}
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+	}
+	r := &request{
+		Name:   codegen.SnakeCase(a.Parent.Name) + "_" + codegen.SnakeCase(a.Name),
+		Weight: weight,
+		Method: ro.Verb,
+		Path:   path,
+	}
+	headers := make(map[string]string)
+	a.IterateHeaders(func(name string, isRequired bool, h *design.AttributeDefinition) error {
+		if isRequired {
+			if val := codegen.Example(h, rand); val != nil {
+				headers[name] = fmt.Sprint(val)
+			}
+		}
+		return nil
+	})
+	if a.Payload != nil {
+		if body := codegen.Example(a.Payload.AttributeDefinition, rand); body != nil {
+			r.Body = body
+			headers["Content-Type"] = "application/json"
+		}
+	}
+	if len(headers) > 0 {
+		r.Headers = headers
+	}
+	if a.SLO != nil {
+		r.Latency = a.SLO.LatencyP99
+		r.Availability = a.SLO.Availability
+	}
+	return r, nil
+}
+
+// generateK6 writes the k6 script.
+func (g *Generator) generateK6(reqs []*request) error {
+	thresholds := make(map[string][]string)
+	for _, r := range reqs {
+		if r.Latency == 0 {
+			continue
+		}
+		thresholds[fmt.Sprintf("http_req_duration{action:%s}", r.Name)] = []string{
+			fmt.Sprintf("p(99)<%s", formatFloat(float64(r.Latency)/float64(time.Millisecond))),
+		}
+		thresholds[fmt.Sprintf("http_req_failed{action:%s}", r.Name)] = []string{
+			fmt.Sprintf("rate<%s", formatFloat(1-r.Availability/100)),
+		}
+	}
+	if reqs == nil {
+		reqs = []*request{}
+	}
+	js, err := marshalJS(reqs, "")
+	if err != nil {
+		return err
+	}
+	th, err := marshalJS(thresholds, "  ")
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"Service":    g.API.Name,
+		"Host":       g.Host,
+		"Rate":       g.Rate,
+		"Duration":   g.Duration,
+		"Requests":   js,
+		"Thresholds": th,
+	}
+	return g.write("script.js", k6Tmpl, data, 0644)
+}
+
+// generateVegeta writes the vegeta targets and the script that runs the attack and checks the
+// results against the strictest service level objectives.
+func (g *Generator) generateVegeta(reqs []*request) error {
+	var (
+		buf          bytes.Buffer
+		latency      time.Duration
+		availability float64
+	)
+	for _, r := range reqs {
+		t := map[string]interface{}{
+			"method": r.Method,
+			"url":    g.Host + r.Path,
+		}
+		if len(r.Headers) > 0 {
+			header := make(map[string][]string, len(r.Headers))
+			for k, v := range r.Headers {
+				header[k] = []string{v}
+			}
+			t["header"] = header
+		}
+		if r.Body != nil {
+			body, err := json.Marshal(r.Body)
+			if err != nil {
+				return err
+			}
+			t["body"] = body // encoded in base64 as expected by vegeta
+		}
+		line, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		// vegeta sends the targets in a round robin fashion, the weight is the number of
+		// occurrences of the target.
+		for i := 0; i < r.Weight; i++ {
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+		if r.Latency > 0 && (latency == 0 || r.Latency < latency) {
+			latency = r.Latency
+		}
+		if r.Availability > availability {
+			availability = r.Availability
+		}
+	}
+	targets := filepath.Join(g.OutDir, "targets.json")
+	if err := ioutil.WriteFile(targets, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, targets)
+	data := map[string]interface{}{
+		"Service":      g.API.Name,
+		"Rate":         g.Rate,
+		"Duration":     g.Duration,
+		"Latency":      int64(latency),
+		"Availability": formatFloat(availability / 100),
+		"HasSLO":       latency > 0,
+	}
+	return g.write("run.sh", vegetaTmpl, data, 0755)
+}
+
+// write renders the given template in the file with the given name.
+func (g *Generator) write(name string, tmpl *template.Template, data interface{}, perm os.FileMode) error {
+	path := filepath.Join(g.OutDir, name)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	g.genfiles = append(g.genfiles, path)
+	return tmpl.Execute(f, data)
+}
+
+// marshalJS returns the indented JSON encoding of v used as a JavaScript literal. Unlike
+// json.MarshalIndent it does not escape the HTML characters, e.g. "<" in the k6 thresholds.
+func marshalJS(v interface{}, prefix string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// formatFloat renders f with at most 6 decimals and no trailing zeros.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e6)/1e6, 'f', -1, 64)
+}
+
+var (
+	k6Tmpl     = template.Must(template.New("k6").Parse(k6T))
+	vegetaTmpl = template.Must(template.New("vegeta").Parse(vegetaT))
+)
+
+const k6T = `// Code generated by goagen, DO NOT EDIT.
+// Load test scenario of the {{ .Service }} API, run with:
+//
+//	k6 run -e BASE_URL={{ .Host }} script.js
+//
+import http from 'k6/http';
+import { check } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || '{{ .Host }}';
+
+// Only server errors count as failures, consistently with the service level objectives.
+http.setResponseCallback(http.expectedStatuses({ min: 200, max: 499 }));
+
+export const options = {
+  scenarios: {
+    api: {
+      executor: 'constant-arrival-rate',
+      rate: {{ .Rate }},
+      timeUnit: '1s',
+      duration: '{{ .Duration }}',
+      preAllocatedVUs: {{ .Rate }},
+    },
+  },
+  thresholds: {{ .Thresholds }},
+};
+
+const requests = {{ .Requests }};
+
+const totalWeight = requests.reduce((total, r) => total + r.weight, 0);
+
+// pick returns a request at random proportionally to the request weights.
+function pick() {
+  let n = Math.random() * totalWeight;
+  for (const r of requests) {
+    n -= r.weight;
+    if (n < 0) {
+      return r;
+    }
+  }
+  return requests[requests.length - 1];
+}
+
+export default function () {
+  if (requests.length === 0) {
+    return;
+  }
+  const r = pick();
+  const body = r.body === undefined ? null : JSON.stringify(r.body);
+  const res = http.request(r.method, BASE_URL + r.path, body, {
+    headers: r.headers,
+    tags: { action: r.name },
+  });
+  check(res, { 'no server error': (res) => res.status < 500 });
+}
+`
+
+const vegetaT = `#!/bin/sh
+# Code generated by goagen, DO NOT EDIT.
+# Load test scenario of the {{ .Service }} API, the targets are listed in targets.json.
+set -e
+cd "$(dirname "$0")"
+vegeta attack -format=json -targets=targets.json -rate={{ .Rate }} -duration={{ .Duration }} > results.bin
+vegeta report results.bin
+{{- if .HasSLO }}
+# The results must meet the strictest service level objectives.
+vegeta report -type=json results.bin |
+  jq -e '.latencies["99th"] <= {{ .Latency }} and .success >= {{ .Availability }}' > /dev/null ||
+  { echo "service level objectives not met" >&2; exit 1; }
+{{- end }}
+`
@@ -0,0 +1,167 @@
+package genload_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_load"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+	var tool string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("loadtest")
+		Ω(err).ShouldNot(HaveOccurred())
+		tool = "k6"
+	})
+
+	JustBeforeEach(func() {
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--tool=" + tool,
+			"--rate=20", "--duration=30s", "--version=" + version.String()}
+		files, genErr = genload.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a design", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Host("api.example.com")
+				apidsl.Scheme("https")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer, func() {
+							apidsl.Example(42)
+						})
+					})
+					apidsl.Metadata("load:weight", "3")
+					apidsl.SLO("250ms", 99.5)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", design.String, func() {
+							apidsl.Example("Chardonnay")
+						})
+						apidsl.Required("name")
+					})
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Metadata("load:weight", "0")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the k6 script", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(2))
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "load", "script.js"))
+			Ω(err).ShouldNot(HaveOccurred())
+			script := string(content)
+			Ω(script).Should(ContainSubstring("const BASE_URL = __ENV.BASE_URL || 'https://api.example.com';"))
+			Ω(script).Should(ContainSubstring("rate: 20,"))
+			Ω(script).Should(ContainSubstring("duration: '30s',"))
+			Ω(script).Should(ContainSubstring(`"http_req_duration{action:bottle_show}": [`))
+			Ω(script).Should(ContainSubstring(`"p(99)<250"`))
+			Ω(script).Should(ContainSubstring(`"rate<0.005"`))
+			Ω(script).Should(ContainSubstring(`"path": "/bottles/42"`))
+			Ω(script).Should(ContainSubstring(`"weight": 3`))
+			Ω(script).Should(ContainSubstring(`"name": "Chardonnay"`))
+			Ω(script).ShouldNot(ContainSubstring("bottle_delete"))
+		})
+
+		Context("using vegeta", func() {
+			BeforeEach(func() {
+				tool = "vegeta"
+			})
+
+			It("generates the targets and the run script", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(HaveLen(3))
+				content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "load", "targets.json"))
+				Ω(err).ShouldNot(HaveOccurred())
+				targets := string(content)
+				Ω(targets).Should(ContainSubstring(`"url":"https://api.example.com/bottles/42"`))
+				Ω(targets).Should(ContainSubstring(`"header":{"Content-Type":["application/json"]}`))
+				content, err = ioutil.ReadFile(filepath.Join(testPkg.Abs(), "load", "run.sh"))
+				Ω(err).ShouldNot(HaveOccurred())
+				run := string(content)
+				Ω(run).Should(ContainSubstring("-rate=20 -duration=30s"))
+				Ω(run).Should(ContainSubstring(`.latencies["99th"] <= 250000000 and .success >= 0.995`))
+			})
+		})
+
+		Context("using an unknown tool", func() {
+			BeforeEach(func() {
+				tool = "jmeter"
+			})
+
+			It("returns an error", func() {
+				Ω(genErr).Should(HaveOccurred())
+			})
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genload.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genload.NewGenerator(
+				genload.API(args.api),
+				genload.OutDir(args.outDir),
+				genload.Tool("vegeta"),
+				genload.Host("http://localhost:8080"),
+				genload.Rate(50),
+				genload.Duration("5m"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Tool).Should(Equal("vegeta"))
+			Ω(generator.Host).Should(Equal("http://localhost:8080"))
+			Ω(generator.Rate).Should(Equal(50))
+			Ω(generator.Duration).Should(Equal("5m"))
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package genload
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Tool Load testing tool, one of "k6" or "vegeta"
+func Tool(tool string) Option {
+	return func(g *Generator) {
+		g.Tool = tool
+	}
+}
+
+//Host Base URL of the service under test
+func Host(host string) Option {
+	return func(g *Generator) {
+		g.Host = host
+	}
+}
+
+//Rate Number of requests per second
+func Rate(rate int) Option {
+	return func(g *Generator) {
+		g.Rate = rate
+	}
+}
+
+//Duration Duration of the test
+func Duration(duration string) Option {
+	return func(g *Generator) {
+		g.Duration = duration
+	}
+}
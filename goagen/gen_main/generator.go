@@ -12,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -171,6 +172,10 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		codegen.SimpleImport(imp),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
+	clients := serviceClients(design.Design)
+	for _, c := range clients {
+		imports = append(imports, codegen.NewImport(c.Alias, c.Pkg))
+	}
 	for _, imp := range extractedImports {
 		// This may introduce duplicate imports of the defaults, but
 		// that'll get worked out by Format later.
@@ -184,7 +189,7 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		imports = append(imports, cgimp)
 	}
 
-	funcs := funcMap(pkgName, actionImpls)
+	funcs := funcMap(pkgName, actionImpls, clients)
 	if err = file.WriteHeader("", pkg, imports); err != nil {
 		return "", err
 	}
@@ -233,7 +238,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 		if err = os.MkdirAll(g.OutDir, 0755); err != nil {
 			return nil, err
 		}
-		if err = g.createMainFile(mainFile, funcMap(g.Target, nil)); err != nil {
+		if err = g.createMainFile(mainFile, funcMap(g.Target, nil, serviceClients(g.API))); err != nil {
 			return nil, err
 		}
 	}
@@ -293,6 +298,15 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
 		codegen.SimpleImport(appPkg),
 	}
+	if clients := serviceClients(g.API); len(clients) > 0 {
+		imports = append(imports,
+			codegen.SimpleImport("net/http"),
+			codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+		)
+		for _, c := range clients {
+			imports = append(imports, codegen.NewImport(c.Alias, c.Pkg))
+		}
+	}
 	file.Write([]byte("//go:generate goagen bootstrap -d " + g.DesignPkg + "\n\n"))
 	if err = file.WriteHeader("", "main", imports); err != nil {
 		return err
@@ -312,6 +326,46 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 	return
 }
 
+// serviceClient describes the client of a service used by the API that is injected in the
+// controllers.
+type serviceClient struct {
+	// Name is the name of the service.
+	Name string
+	// Pkg is the import path of the client package.
+	Pkg string
+	// Alias is the name used to import the client package.
+	Alias string
+	// VarName is the name of the variable holding the client in main.
+	VarName string
+	// FieldName is the name of the controller field holding the client.
+	FieldName string
+}
+
+// serviceClients returns the clients of the services used by the API sorted by service name,
+// see design.APIDefinition.ImportClients.
+func serviceClients(api *design.APIDefinition) []*serviceClient {
+	if api == nil {
+		return nil
+	}
+	names := make([]string, 0, len(api.ClientPkgs))
+	for name := range api.ClientPkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	clients := make([]*serviceClient, len(names))
+	for i, name := range names {
+		pkg := api.ClientPkgs[name]
+		clients[i] = &serviceClient{
+			Name:      name,
+			Pkg:       pkg,
+			Alias:     codegen.ImportAlias(pkg),
+			VarName:   codegen.Goify(name, false) + "Client",
+			FieldName: codegen.Goify(name, true) + "Client",
+		}
+	}
+	return clients
+}
+
 // tempCount is the counter used to create unique temporary variable names.
 var tempCount int
 
@@ -369,16 +423,19 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 		nameSuffix = codegen.Goify(view, true)
 	}
 	return map[string]interface{}{
-		"Name":    ok.Name + nameSuffix,
-		"GoType":  codegen.GoNativeType(pmt),
-		"TypeRef": typeref,
+		"Name":      ok.Name + nameSuffix,
+		"GoType":    codegen.GoNativeType(pmt),
+		"TypeRef":   typeref,
+		"Streaming": ok.Streaming,
+		"Chunked":   ok.Chunked,
 	}
 }
 
 // funcMap creates the funcMap used to render the controller code.
-func funcMap(appPkg string, actionImpls map[string]string) template.FuncMap {
+func funcMap(appPkg string, actionImpls map[string]string, clients []*serviceClient) template.FuncMap {
 	return template.FuncMap{
 		"tempvar":   tempvar,
+		"clients":   func() []*serviceClient { return clients },
 		"okResp":    okResp,
 		"targetPkg": func() string { return appPkg },
 		"actionBody": func(name string) string {
@@ -401,13 +458,20 @@ const defaultActionBody = `// Put your logic here`
 const ctrlT = `// {{ $ctrlName := printf "%s%s" (goify .Name true) "Controller" }}{{ $ctrlName }} implements the {{ .Name }} resource.
 type {{ $ctrlName }} struct {
 	*goa.Controller
-}
+{{ range clients }}	{{ .FieldName }} *{{ .Alias }}.Client
+{{ end }}}
 
 // New{{ $ctrlName }} creates a {{ .Name }} controller.
-func New{{ $ctrlName }}(service *goa.Service) *{{ $ctrlName }} {
+{{ if clients }}func New{{ $ctrlName }}(service *goa.Service{{ range clients }}, {{ .VarName }} *{{ .Alias }}.Client{{ end }}) *{{ $ctrlName }} {
+	return &{{ $ctrlName }}{
+		Controller: service.NewController("{{ $ctrlName }}"),
+{{ range clients }}		{{ .FieldName }}: {{ .VarName }},
+{{ end }}	}
+}
+{{ else }}func New{{ $ctrlName }}(service *goa.Service) *{{ $ctrlName }} {
 	return &{{ $ctrlName }}{Controller: service.NewController("{{ $ctrlName }}")}
 }
-`
+{{ end }}`
 
 const actionT = `
 {{- $ctrlName := printf "%s%s" (goify .Parent.Name true) "Controller" -}}
@@ -420,8 +484,15 @@ func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .
 
 	// {{ $actionDescr }}: end_implement
 {{ $ok := okResp . targetPkg }}{{ if $ok }} res := {{ $ok.TypeRef }}
-{{ end }} return {{ if $ok }}ctx.{{ $ok.Name }}(res){{ else }}nil{{ end }}
-}
+{{ end }}{{ if and $ok $ok.Chunked }} enc := goa.NewCollectionEncoder(ctx.{{ $ok.Name }}())
+	for _, r := range res {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+{{ else }} return {{ if $ok }}{{ if $ok.Streaming }}ctx.{{ $ok.Name }}().Send("", res){{ else }}ctx.{{ $ok.Name }}(res){{ end }}{{ else }}nil{{ end }}
+{{ end }}}
 `
 
 const actionWST = `
@@ -457,9 +528,12 @@ func main() {
 	service.Use(middleware.LogRequest(true))
 	service.Use(middleware.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
-{{ $api := .API }}
+{{ range clients }}
+	// Create "{{ .Name }}" client
+	{{ .VarName }} := {{ .Alias }}.New(goaclient.HTTPClientDoer(http.DefaultClient))
+{{ end }}{{ $api := .API }}
 {{ range $name, $res := $api.Resources }}{{ $name := goify $res.Name true }} // Mount "{{$res.Name}}" controller
-	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
+	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service{{ range clients }}, {{ .VarName }}{{ end }})
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
 {{ end }}
 
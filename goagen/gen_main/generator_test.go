@@ -112,6 +112,27 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(MatchRegexp(`// FirstController_Alpha: start_implement\s*// Put your logic here\s*// FirstController_Alpha: end_implement`))
 		})
 
+		Context("using other services", func() {
+			BeforeEach(func() {
+				design.Design.Uses = map[string][]string{"cellar": nil}
+				err := design.Design.ImportClients(map[string]string{"cellar": "services/cellar/client"})
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("injects the service clients in the controllers", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`cellarclient "services/cellar/client"`))
+				Ω(string(content)).Should(ContainSubstring("CellarClient *cellarclient.Client"))
+				Ω(string(content)).Should(ContainSubstring("func NewFirstController(service *goa.Service, cellarClient *cellarclient.Client) *FirstController {"))
+				content, err = ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("cellarClient := cellarclient.New(goaclient.HTTPClientDoer(http.DefaultClient))"))
+				Ω(string(content)).Should(MatchRegexp(`NewFirstController\(service, cellarClient\)`))
+			})
+		})
+
 		Context("regenerated with a new resource", func() {
 			BeforeEach(func() {
 				// Perform a first generation
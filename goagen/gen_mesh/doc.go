@@ -0,0 +1,28 @@
+/*
+Package genmesh provides a generator for service mesh traffic policies derived from the design.
+The generator writes the Istio VirtualService and DestinationRule to mesh/istio.yml and the
+Linkerd ServiceProfile to mesh/linkerd.yml.
+
+The policies are read from the following metadata keys, action metadata takes precedence over
+resource metadata which takes precedence over API metadata:
+
+	mesh:timeout			Request timeout, defaults to the SLO 99th percentile latency if any
+	mesh:retry:attempts		Maximum number of retries
+	mesh:retry:timeout		Timeout of each attempt (Istio only)
+	mesh:retry:on			Istio retry conditions, defaults to "5xx,reset,connect-failure,refused-stream"
+
+The following keys apply to the whole service and are read from the API metadata only:
+
+	mesh:retry:budget		Linkerd ratio of retries to original requests, defaults to 0.2
+	mesh:circuitbreaker:errors	Number of consecutive 5xx errors that eject a host (Istio only)
+	mesh:circuitbreaker:ejection	Minimum ejection duration, defaults to 30s (Istio only)
+
+Example:
+
+	Action("show", func() {
+		Routing(GET("/:id"))
+		Metadata("mesh:timeout", "2s")
+		Metadata("mesh:retry:attempts", "3")
+	})
+*/
+package genmesh
@@ -0,0 +1,13 @@
+package genmesh_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenMesh(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mesh Generator Suite")
+}
@@ -0,0 +1,365 @@
+package genmesh
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+// NewGenerator returns an initialized instance of a service mesh policy Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the service mesh policy generator.
+type Generator struct {
+	API       *design.APIDefinition // The API definition
+	OutDir    string                // Path to output directory
+	Service   string                // Name of the Kubernetes service
+	Namespace string                // Kubernetes namespace of the service
+	genfiles  []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, service, namespace, ver string
+	set := flag.NewFlagSet("mesh", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&service, "service", "", "")
+	set.StringVar(&namespace, "namespace", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Service: service, Namespace: namespace, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the Istio and Linkerd manifests.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Service == "" {
+		g.Service = codegen.Slug(g.API.Name)
+	}
+	if g.Namespace == "" {
+		g.Namespace = "default"
+	}
+
+	routes, err := g.routes()
+	if err != nil {
+		return
+	}
+	budget := 0.2
+	if v, ok := lookup("mesh:retry:budget", g.API.Metadata); ok {
+		if budget, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("invalid mesh:retry:budget metadata value %#v: %s", v, err)
+		}
+	}
+	var errors int
+	if v, ok := lookup("mesh:circuitbreaker:errors", g.API.Metadata); ok {
+		if errors, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid mesh:circuitbreaker:errors metadata value %#v: %s", v, err)
+		}
+	}
+	ejection := 30 * time.Second
+	if v, ok := lookup("mesh:circuitbreaker:ejection", g.API.Metadata); ok {
+		if ejection, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid mesh:circuitbreaker:ejection metadata value %#v: %s", v, err)
+		}
+	}
+	retryable := false
+	for _, r := range routes {
+		retryable = retryable || r.Attempts > 0
+	}
+	data := map[string]interface{}{
+		"Name":        g.API.Name,
+		"Service":     g.Service,
+		"Namespace":   g.Namespace,
+		"Routes":      routes,
+		"Retryable":   retryable,
+		"RetryBudget": strconv.FormatFloat(budget, 'f', -1, 64),
+		"Errors":      errors,
+		"Ejection":    ejection,
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "mesh")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+	if err = g.write("istio.yml", istioTmpl, data); err != nil {
+		return
+	}
+	if err = g.write("linkerd.yml", linkerdTmpl, data); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// write renders the given template into the file with the given name.
+func (g *Generator) write(name string, tmpl *template.Template, data interface{}) error {
+	path := filepath.Join(g.OutDir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	g.genfiles = append(g.genfiles, path)
+	return tmpl.Execute(f, data)
+}
+
+// routes builds the mesh routes of the API actions.
+func (g *Generator) routes() ([]*route, error) {
+	var routes []*route
+	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			mds := []dslengine.MetadataDefinition{a.Metadata, res.Metadata, g.API.Metadata}
+			policy, err := newPolicy(mds, a.SLO)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			for i, ro := range a.Routes {
+				r := &route{
+					Name:   codegen.Slug(res.Name) + "-" + codegen.Slug(a.Name),
+					Method: ro.Verb,
+					Path:   ro.FullPath(),
+					policy: policy,
+				}
+				if i > 0 {
+					r.Name = fmt.Sprintf("%s-%d", r.Name, i+1)
+				}
+				r.Regexps = append(r.Regexps, codegen.PathRegexp(r.Path))
+				for _, al := range ro.Aliases {
+					// Redirected and gone aliases are answered by the service
+					// without running the action, they get no action policy.
+					if al.Policy == design.AliasServe {
+						r.Regexps = append(r.Regexps, codegen.PathRegexp(al.FullPath()))
+					}
+				}
+				routes = append(routes, r)
+			}
+			return nil
+		})
+	})
+	return routes, err
+}
+
+type (
+	// route describes a mesh route.
+	route struct {
+		// Name is the route name.
+		Name string
+		// Method is the HTTP method matched by the route.
+		Method string
+		// Path is the route path.
+		Path string
+		// Regexps lists the anchored regular expressions matching the route and alias paths.
+		Regexps []string
+		*policy
+	}
+
+	// policy describes the timeout and retries of a route.
+	policy struct {
+		// Timeout is the request timeout if not zero.
+		Timeout time.Duration
+		// Attempts is the maximum number of retries.
+		Attempts int
+		// PerTryTimeout is the timeout of each attempt if not zero.
+		PerTryTimeout time.Duration
+		// RetryOn lists the Istio retry conditions.
+		RetryOn string
+	}
+)
+
+// newPolicy reads the route policy from the given metadata, the first definition that contains a
+// key wins.
+func newPolicy(mds []dslengine.MetadataDefinition, slo *design.SLODefinition) (*policy, error) {
+	p := &policy{RetryOn: "5xx,reset,connect-failure,refused-stream"}
+	var err error
+	if v, ok := lookup("mesh:timeout", mds...); ok {
+		if p.Timeout, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid mesh:timeout metadata value %#v: %s", v, err)
+		}
+	} else if slo != nil {
+		p.Timeout = slo.LatencyP99
+	}
+	if v, ok := lookup("mesh:retry:attempts", mds...); ok {
+		if p.Attempts, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid mesh:retry:attempts metadata value %#v: %s", v, err)
+		}
+	}
+	if v, ok := lookup("mesh:retry:timeout", mds...); ok {
+		if p.PerTryTimeout, err = time.ParseDuration(v); err != nil {
+			return nil, fmt.Errorf("invalid mesh:retry:timeout metadata value %#v: %s", v, err)
+		}
+	}
+	if v, ok := lookup("mesh:retry:on", mds...); ok {
+		p.RetryOn = v
+	}
+	return p, nil
+}
+
+// lookup returns the first value of the key in the first metadata definition that defines it.
+func lookup(key string, mds ...dslengine.MetadataDefinition) (string, bool) {
+	for _, md := range mds {
+		if vals, ok := md[key]; ok && len(vals) > 0 {
+			return vals[0], true
+		}
+	}
+	return "", false
+}
+
+// seconds formats the given duration as a protobuf duration.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// unanchor removes the anchors of the given regular expression, Linkerd path regular
+// expressions always match the entire path.
+func unanchor(re string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(re, "^"), "$")
+}
+
+var (
+	funcMap = template.FuncMap{
+		"seconds":  seconds,
+		"unanchor": unanchor,
+	}
+	istioTmpl   = template.Must(template.New("istio").Funcs(funcMap).Parse(istioT))
+	linkerdTmpl = template.Must(template.New("linkerd").Funcs(funcMap).Parse(linkerdT))
+)
+
+const istioT = `# Code generated by goagen, DO NOT EDIT.
+# Istio traffic policies of the {{ .Name }} API.
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: {{ .Service }}
+  namespace: {{ .Namespace }}
+spec:
+  hosts:
+  - {{ .Service }}
+  http:
+{{- range .Routes }}{{ $route := . }}
+  - name: {{ .Name }}
+    match:
+{{- range .Regexps }}
+    - method:
+        exact: {{ $route.Method }}
+      uri:
+        regex: {{ printf "%q" . }}
+{{- end }}
+    route:
+    - destination:
+        host: {{ $.Service }}
+{{- if .Timeout }}
+    timeout: {{ seconds .Timeout }}
+{{- end }}
+{{- if .Attempts }}
+    retries:
+      attempts: {{ .Attempts }}
+{{- if .PerTryTimeout }}
+      perTryTimeout: {{ seconds .PerTryTimeout }}
+{{- end }}
+      retryOn: {{ .RetryOn }}
+{{- end }}
+{{- end }}
+  - name: default
+    route:
+    - destination:
+        host: {{ .Service }}
+{{- if .Errors }}
+---
+apiVersion: networking.istio.io/v1beta1
+kind: DestinationRule
+metadata:
+  name: {{ .Service }}
+  namespace: {{ .Namespace }}
+spec:
+  host: {{ .Service }}
+  trafficPolicy:
+    outlierDetection:
+      consecutive5xxErrors: {{ .Errors }}
+      interval: 10s
+      baseEjectionTime: {{ seconds .Ejection }}
+{{- end }}
+`
+
+const linkerdT = `# Code generated by goagen, DO NOT EDIT.
+# Linkerd service profile of the {{ .Name }} API.
+apiVersion: linkerd.io/v1alpha2
+kind: ServiceProfile
+metadata:
+  name: {{ .Service }}.{{ .Namespace }}.svc.cluster.local
+  namespace: {{ .Namespace }}
+spec:
+  routes:{{ if not .Routes }} []{{ end }}
+{{- range .Routes }}{{ $route := . }}
+  - name: {{ printf "%q" (printf "%s %s" .Method .Path) }}
+    condition:
+{{- if eq (len .Regexps) 1 }}
+      method: {{ .Method }}
+      pathRegex: {{ printf "%q" (unanchor (index .Regexps 0)) }}
+{{- else }}
+      any:
+{{- range .Regexps }}
+      - method: {{ $route.Method }}
+        pathRegex: {{ printf "%q" (unanchor .) }}
+{{- end }}
+{{- end }}
+{{- if .Timeout }}
+    timeout: {{ .Timeout }}
+{{- end }}
+{{- if .Attempts }}
+    isRetryable: true
+{{- end }}
+{{- end }}
+{{- if .Retryable }}
+  retryBudget:
+    retryRatio: {{ .RetryBudget }}
+    minRetriesPerSecond: 10
+    ttl: 10s
+{{- end }}
+`
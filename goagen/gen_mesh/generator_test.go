@@ -0,0 +1,179 @@
+package genmesh_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_mesh"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("meshtest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--namespace=cellar", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genmesh.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with actions defining mesh policies", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Metadata("mesh:circuitbreaker:errors", "5")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Metadata("mesh:retry:attempts", "3")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id", func() {
+						apidsl.Alias("/legacy/:id")
+						apidsl.Alias("/old/:id", design.AliasRedirect)
+						apidsl.Alias("/v0/:id", design.AliasGone)
+					}))
+					apidsl.Metadata("mesh:retry:timeout", "500ms")
+					apidsl.SLO("250ms", 99.5)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Metadata("mesh:timeout", "2s")
+					apidsl.Metadata("mesh:retry:attempts", "0")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		read := func(name string) string {
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "mesh", name))
+			Ω(err).ShouldNot(HaveOccurred())
+			return string(content)
+		}
+
+		It("generates the Istio manifests", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(3))
+			istio := read("istio.yml")
+			Ω(istio).Should(ContainSubstring("  name: test-api\n  namespace: cellar\n"))
+			Ω(istio).Should(ContainSubstring(`  - name: bottle-show
+    match:
+    - method:
+        exact: GET
+      uri:
+        regex: "^/bottles/[^/]+$"
+    - method:
+        exact: GET
+      uri:
+        regex: "^/bottles/legacy/[^/]+$"
+    route:
+    - destination:
+        host: test-api
+    timeout: 0.25s
+    retries:
+      attempts: 3
+      perTryTimeout: 0.5s
+      retryOn: 5xx,reset,connect-failure,refused-stream
+`))
+			Ω(istio).Should(ContainSubstring("        host: test-api\n    timeout: 2s\n  - name: bottle-show\n"))
+			Ω(istio).ShouldNot(ContainSubstring("/bottles/old/"))
+			Ω(istio).ShouldNot(ContainSubstring("/bottles/v0/"))
+			Ω(istio).Should(ContainSubstring("      consecutive5xxErrors: 5\n      interval: 10s\n      baseEjectionTime: 30s\n"))
+		})
+
+		It("generates the Linkerd service profile", func() {
+			Ω(genErr).Should(BeNil())
+			linkerd := read("linkerd.yml")
+			Ω(linkerd).Should(ContainSubstring("  name: test-api.cellar.svc.cluster.local\n"))
+			Ω(linkerd).Should(ContainSubstring(`  - name: "POST /bottles"
+    condition:
+      method: POST
+      pathRegex: "/bottles"
+    timeout: 2s
+`))
+			Ω(linkerd).Should(ContainSubstring(`      any:
+      - method: GET
+        pathRegex: "/bottles/[^/]+"
+      - method: GET
+        pathRegex: "/bottles/legacy/[^/]+"
+    timeout: 250ms
+    isRetryable: true
+`))
+			Ω(linkerd).Should(ContainSubstring("  retryBudget:\n    retryRatio: 0.2\n"))
+		})
+	})
+
+	Context("with an invalid mesh metadata value", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Metadata("mesh:timeout", "soon")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring(`invalid mesh:timeout metadata value "soon"`))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genmesh.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genmesh.NewGenerator(
+				genmesh.API(args.api),
+				genmesh.OutDir(args.outDir),
+				genmesh.Service("cellar"),
+				genmesh.Namespace("prod"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Service).Should(Equal("cellar"))
+			Ω(generator.Namespace).Should(Equal("prod"))
+		})
+	})
+})
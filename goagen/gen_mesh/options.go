@@ -0,0 +1,34 @@
+package genmesh
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Service Name of the Kubernetes service
+func Service(service string) Option {
+	return func(g *Generator) {
+		g.Service = service
+	}
+}
+
+//Namespace Kubernetes namespace of the service
+func Namespace(namespace string) Option {
+	return func(g *Generator) {
+		g.Namespace = namespace
+	}
+}
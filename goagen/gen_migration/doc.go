@@ -0,0 +1,20 @@
+/*
+Package genmigration provides a generator for the SQL migrations of the database tables that store
+the design types. User and media types are persisted when they define the "sql:table" metadata,
+each attribute of a persisted type maps to a column of the table. The following attribute metadata
+customize the mapping:
+
+	sql:column   overrides the name of the column, defaults to the attribute name
+	sql:type     overrides the SQL type of the column
+	sql:primary  adds the column to the primary key, defaults to the "id" attribute
+	sql:index    creates an index on the column
+	sql:ignore   excludes the attribute from the table
+
+The first run writes the initial migration to migrations/0001_initial.up.sql and
+migrations/0001_initial.down.sql and records the persisted schema in migrations/schema.json.
+Subsequent runs compare the design with the recorded schema. If the persisted types changed the
+generator prints a warning describing each change and writes hints for the corresponding migration
+(ALTER TABLE statements etc.) to the next numbered migration files. The hints should be reviewed
+before being applied. The generated SQL targets PostgreSQL.
+*/
+package genmigration
@@ -0,0 +1,160 @@
+package genmigration
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a migration Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the database migration generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Warnings []string              // Changes made to the persisted types since the last migration
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, ver string
+	set := flag.NewFlagSet("migration", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design}
+
+	files, err = g.Generate()
+	for _, w := range g.Warnings {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", w)
+	}
+	return
+}
+
+// Generate writes the migration of the persisted types if there is none yet or the hints for
+// the migration of the changes made to the persisted types since the last migration otherwise.
+// It records the changes in the Warnings field.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	dir := filepath.Join(g.OutDir, "migrations")
+	schemaFile := filepath.Join(dir, "schema.json")
+	current, err := NewSchema(g.API)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := LoadSchema(schemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		changes []*Change
+		name    = "initial"
+		header  = "-- Initial migration of the tables storing the persisted design types."
+	)
+	if previous == nil {
+		if len(current.Tables) == 0 {
+			return nil, nil
+		}
+		previous = &Schema{}
+		changes = Diff(previous, current)
+	} else {
+		changes = Diff(previous, current)
+		if len(changes) == 0 {
+			return nil, nil
+		}
+		name = "design_changes"
+		header = "-- WARNING: the statements below are derived from the changes made to the persisted design\n" +
+			"-- types since the last migration, review them before applying."
+		for _, c := range changes {
+			g.Warnings = append(g.Warnings, "persisted types changed: "+c.Description)
+		}
+	}
+	current.Version = previous.Version + 1
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	var up, down bytes.Buffer
+	fmt.Fprintf(&up, "%s\n", header)
+	fmt.Fprintf(&down, "%s\n", header)
+	for i, c := range changes {
+		fmt.Fprintf(&up, "\n-- %s\n", c.Description)
+		for _, stmt := range c.Up {
+			fmt.Fprintf(&up, "%s;\n", stmt)
+		}
+		rc := changes[len(changes)-1-i]
+		fmt.Fprintf(&down, "\n-- %s\n", rc.Description)
+		for j := len(rc.Down) - 1; j >= 0; j-- {
+			fmt.Fprintf(&down, "%s;\n", rc.Down[j])
+		}
+	}
+	prefix := fmt.Sprintf("%04d_%s", current.Version, name)
+	if err = g.writeFile(filepath.Join(dir, prefix+".up.sql"), up.Bytes()); err != nil {
+		return
+	}
+	if err = g.writeFile(filepath.Join(dir, prefix+".down.sql"), down.Bytes()); err != nil {
+		return
+	}
+	js, err := json.MarshalIndent(current, "", "  ")
+	if err != nil {
+		return
+	}
+	if err = g.writeFile(schemaFile, append(js, '\n')); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// writeFile writes the given content to the file with the given name.
+func (g *Generator) writeFile(filename string, content []byte) error {
+	if err := ioutil.WriteFile(filename, content, 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, filename)
+	return nil
+}
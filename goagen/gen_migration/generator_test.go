@@ -0,0 +1,134 @@
+package genmigration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_migration"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var generator *genmigration.Generator
+	var files []string
+	var genErr error
+
+	read := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(outDir, "migrations", name))
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(content)
+	}
+
+	bottleDSL := func(extra func()) {
+		dslengine.Reset()
+		apidsl.API("test api", func() {
+			apidsl.Title("dummy API")
+		})
+		apidsl.Type("Bottle", func() {
+			apidsl.Metadata("sql:table", "bottles")
+			apidsl.Attribute("id", design.Integer)
+			apidsl.Attribute("name", design.String, func() {
+				apidsl.Metadata("sql:index")
+			})
+			apidsl.Attribute("vintage", design.Integer, func() {
+				apidsl.Metadata("sql:column", "year")
+			})
+			apidsl.Attribute("scratch", design.Any, func() {
+				apidsl.Metadata("sql:ignore")
+			})
+			apidsl.Required("name")
+			if extra != nil {
+				extra()
+			}
+		})
+		apidsl.Type("Transient", func() {
+			apidsl.Attribute("id", design.Integer)
+		})
+		dslengine.Run()
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "migration")
+		Ω(err).ShouldNot(HaveOccurred())
+		bottleDSL(nil)
+	})
+
+	JustBeforeEach(func() {
+		generator = genmigration.NewGenerator(genmigration.API(design.Design), genmigration.OutDir(outDir))
+		files, genErr = generator.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("generates the initial migration", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(3))
+		Ω(generator.Warnings).Should(BeEmpty())
+		Ω(read("0001_initial.up.sql")).Should(ContainSubstring(`CREATE TABLE bottles (
+	id BIGINT NOT NULL,
+	name TEXT NOT NULL,
+	year BIGINT,
+	PRIMARY KEY (id)
+);
+CREATE INDEX bottles_name_idx ON bottles (name);`))
+		Ω(read("0001_initial.down.sql")).Should(ContainSubstring("DROP TABLE bottles;"))
+		Ω(read("schema.json")).Should(ContainSubstring(`"version": 1`))
+	})
+
+	Context("with an existing migration", func() {
+		BeforeEach(func() {
+			_, err := genmigration.NewGenerator(genmigration.API(design.Design), genmigration.OutDir(outDir)).Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("does not generate anything if the design did not change", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(BeEmpty())
+			Ω(generator.Warnings).Should(BeEmpty())
+		})
+
+		Context("and changes to the persisted types", func() {
+			BeforeEach(func() {
+				bottleDSL(func() {
+					apidsl.Attribute("color", design.String)
+					apidsl.Required("vintage")
+				})
+			})
+
+			It("warns and generates migration hints", func() {
+				Ω(genErr).ShouldNot(HaveOccurred())
+				Ω(files).Should(HaveLen(3))
+				Ω(generator.Warnings).Should(ConsistOf(
+					`persisted types changed: column "bottles"."color" added`,
+					`persisted types changed: column "bottles"."year" is now required`,
+				))
+				up := read("0002_design_changes.up.sql")
+				Ω(up).Should(ContainSubstring("-- WARNING"))
+				Ω(up).Should(ContainSubstring("ALTER TABLE bottles ADD COLUMN color TEXT;"))
+				Ω(up).Should(ContainSubstring("ALTER TABLE bottles ALTER COLUMN year SET NOT NULL;"))
+				down := read("0002_design_changes.down.sql")
+				Ω(down).Should(ContainSubstring("ALTER TABLE bottles DROP COLUMN color;"))
+				Ω(read("schema.json")).Should(ContainSubstring(`"version": 2`))
+			})
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	It("sets the options", func() {
+		api := &design.APIDefinition{Name: "test api"}
+		g := genmigration.NewGenerator(genmigration.API(api), genmigration.OutDir("out_dir"))
+		Ω(g.API).Should(Equal(api))
+		Ω(g.OutDir).Should(Equal("out_dir"))
+	})
+})
@@ -0,0 +1,361 @@
+package genmigration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+)
+
+type (
+	// Schema describes the database tables that store the persisted design types.
+	Schema struct {
+		// Version is the number of the migration set that produced the schema.
+		Version int `json:"version"`
+		// Tables lists the tables sorted by name.
+		Tables []*Table `json:"tables"`
+	}
+
+	// Table describes the table storing a persisted type.
+	Table struct {
+		// Name is the name of the table.
+		Name string `json:"name"`
+		// Columns lists the table columns, primary key columns first.
+		Columns []*Column `json:"columns"`
+		// PrimaryKey lists the names of the primary key columns.
+		PrimaryKey []string `json:"primary_key,omitempty"`
+	}
+
+	// Column describes a table column.
+	Column struct {
+		// Name is the name of the column.
+		Name string `json:"name"`
+		// Type is the SQL type of the column.
+		Type string `json:"type"`
+		// Nullable is true if the column accepts NULL values.
+		Nullable bool `json:"nullable,omitempty"`
+		// Indexed is true if the column is indexed.
+		Indexed bool `json:"indexed,omitempty"`
+	}
+
+	// Change describes a difference between two schemas and the SQL statements that migrate
+	// the database from one to the other.
+	Change struct {
+		// Description describes the change.
+		Description string
+		// Up lists the statements that apply the change.
+		Up []string
+		// Down lists the statements that revert the change.
+		Down []string
+	}
+)
+
+// NewSchema returns the schema of the tables storing the user and media types of api that
+// define the "sql:table" metadata.
+func NewSchema(api *design.APIDefinition) (*Schema, error) {
+	s := &Schema{}
+	owners := make(map[string]string)
+	add := func(name string, att *design.AttributeDefinition) error {
+		tables, ok := att.Metadata["sql:table"]
+		if !ok {
+			return nil
+		}
+		table := name
+		if len(tables) > 0 && tables[0] != "" {
+			table = tables[0]
+		}
+		if owner, ok := owners[table]; ok {
+			return fmt.Errorf("types %s and %s are both stored in table %s", owner, name, table)
+		}
+		owners[table] = name
+		t, err := newTable(table, att)
+		if err != nil {
+			return fmt.Errorf("type %s: %s", name, err)
+		}
+		s.Tables = append(s.Tables, t)
+		return nil
+	}
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		return add(ut.TypeName, ut.AttributeDefinition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return add(mt.TypeName, mt.AttributeDefinition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(s.Tables, func(i, j int) bool { return s.Tables[i].Name < s.Tables[j].Name })
+	return s, nil
+}
+
+// LoadSchema reads the schema recorded in the given file, it returns nil if the file does not
+// exist.
+func LoadSchema(filename string) (*Schema, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var s Schema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %s", filename, err)
+	}
+	return &s, nil
+}
+
+// Table returns the table with the given name, nil if there is none.
+func (s *Schema) Table(name string) *Table {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Column returns the column with the given name, nil if there is none.
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Create returns the statements that create the table and its indexes.
+func (t *Table) Create() []string {
+	defs := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		defs[i] = "\t" + c.Definition()
+	}
+	if len(t.PrimaryKey) > 0 {
+		defs = append(defs, fmt.Sprintf("\tPRIMARY KEY (%s)", strings.Join(t.PrimaryKey, ", ")))
+	}
+	stmts := []string{fmt.Sprintf("CREATE TABLE %s (\n%s\n)", t.Name, strings.Join(defs, ",\n"))}
+	for _, c := range t.Columns {
+		if c.Indexed {
+			stmts = append(stmts, t.createIndex(c))
+		}
+	}
+	return stmts
+}
+
+// Drop returns the statement that drops the table.
+func (t *Table) Drop() []string {
+	return []string{"DROP TABLE " + t.Name}
+}
+
+// Definition returns the definition of the column used in CREATE TABLE and ADD COLUMN statements.
+func (c *Column) Definition() string {
+	def := c.Name + " " + c.Type
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// Diff returns the changes that migrate the tables described by from to the tables described by
+// to.
+func Diff(from, to *Schema) []*Change {
+	var changes []*Change
+	for _, t := range to.Tables {
+		old := from.Table(t.Name)
+		if old == nil {
+			changes = append(changes, &Change{
+				Description: fmt.Sprintf("table %q added", t.Name),
+				Up:          t.Create(),
+				Down:        t.Drop(),
+			})
+			continue
+		}
+		changes = append(changes, diffTable(old, t)...)
+	}
+	for _, t := range from.Tables {
+		if to.Table(t.Name) == nil {
+			changes = append(changes, &Change{
+				Description: fmt.Sprintf("table %q removed", t.Name),
+				Up:          t.Drop(),
+				Down:        t.Create(),
+			})
+		}
+	}
+	return changes
+}
+
+// diffTable returns the changes that migrate the from table to the to table.
+func diffTable(from, to *Table) []*Change {
+	var changes []*Change
+	alter := "ALTER TABLE " + to.Name + " "
+	for _, c := range to.Columns {
+		name := fmt.Sprintf("%q.%q", to.Name, c.Name)
+		old := from.Column(c.Name)
+		if old == nil {
+			up := []string{alter + "ADD COLUMN " + c.Definition()}
+			if c.Indexed {
+				up = append(up, to.createIndex(c))
+			}
+			changes = append(changes, &Change{
+				Description: fmt.Sprintf("column %s added", name),
+				Up:          up,
+				Down:        []string{alter + "DROP COLUMN " + c.Name},
+			})
+			continue
+		}
+		if old.Type != c.Type {
+			changes = append(changes, &Change{
+				Description: fmt.Sprintf("column %s type changed from %s to %s", name, old.Type, c.Type),
+				Up:          []string{fmt.Sprintf("%sALTER COLUMN %s TYPE %s", alter, c.Name, c.Type)},
+				Down:        []string{fmt.Sprintf("%sALTER COLUMN %s TYPE %s", alter, c.Name, old.Type)},
+			})
+		}
+		if old.Nullable != c.Nullable {
+			set := fmt.Sprintf("%sALTER COLUMN %s SET NOT NULL", alter, c.Name)
+			drop := fmt.Sprintf("%sALTER COLUMN %s DROP NOT NULL", alter, c.Name)
+			if c.Nullable {
+				changes = append(changes, &Change{
+					Description: fmt.Sprintf("column %s is now nullable", name),
+					Up:          []string{drop},
+					Down:        []string{set},
+				})
+			} else {
+				changes = append(changes, &Change{
+					Description: fmt.Sprintf("column %s is now required", name),
+					Up:          []string{set},
+					Down:        []string{drop},
+				})
+			}
+		}
+		if old.Indexed != c.Indexed {
+			if c.Indexed {
+				changes = append(changes, &Change{
+					Description: fmt.Sprintf("column %s index added", name),
+					Up:          []string{to.createIndex(c)},
+					Down:        []string{to.dropIndex(c)},
+				})
+			} else {
+				changes = append(changes, &Change{
+					Description: fmt.Sprintf("column %s index removed", name),
+					Up:          []string{to.dropIndex(c)},
+					Down:        []string{to.createIndex(c)},
+				})
+			}
+		}
+	}
+	for _, c := range from.Columns {
+		if to.Column(c.Name) == nil {
+			changes = append(changes, &Change{
+				Description: fmt.Sprintf("column %q.%q removed", to.Name, c.Name),
+				Up:          []string{alter + "DROP COLUMN " + c.Name},
+				Down:        []string{alter + "ADD COLUMN " + c.Definition()},
+			})
+		}
+	}
+	oldPK, newPK := strings.Join(from.PrimaryKey, ", "), strings.Join(to.PrimaryKey, ", ")
+	if oldPK != newPK {
+		change := &Change{Description: fmt.Sprintf("table %q primary key changed from (%s) to (%s)", to.Name, oldPK, newPK)}
+		if oldPK != "" {
+			change.Up = append(change.Up, alter+"DROP CONSTRAINT "+to.Name+"_pkey")
+		}
+		if newPK != "" {
+			change.Up = append(change.Up, alter+"ADD PRIMARY KEY ("+newPK+")")
+			change.Down = append(change.Down, alter+"DROP CONSTRAINT "+to.Name+"_pkey")
+		}
+		if oldPK != "" {
+			change.Down = append(change.Down, alter+"ADD PRIMARY KEY ("+oldPK+")")
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// createIndex returns the statement that creates the index of the given column.
+func (t *Table) createIndex(c *Column) string {
+	return fmt.Sprintf("CREATE INDEX %s_%s_idx ON %s (%s)", t.Name, c.Name, t.Name, c.Name)
+}
+
+// dropIndex returns the statement that drops the index of the given column.
+func (t *Table) dropIndex(c *Column) string {
+	return fmt.Sprintf("DROP INDEX %s_%s_idx", t.Name, c.Name)
+}
+
+// newTable returns the table with the given name storing the attributes of att.
+func newTable(name string, att *design.AttributeDefinition) (*Table, error) {
+	obj := att.Type.ToObject()
+	if obj == nil {
+		return nil, fmt.Errorf("only object types can be stored in a table")
+	}
+	t := &Table{Name: name}
+	var explicit bool
+	for _, a := range obj {
+		if _, ok := a.Metadata["sql:primary"]; ok {
+			explicit = true
+			break
+		}
+	}
+	var pks []*Column
+	for n, a := range obj {
+		if _, ok := a.Metadata["sql:ignore"]; ok {
+			continue
+		}
+		c := &Column{Name: n, Type: sqlType(a)}
+		if cols := a.Metadata["sql:column"]; len(cols) > 0 && cols[0] != "" {
+			c.Name = cols[0]
+		}
+		_, primary := a.Metadata["sql:primary"]
+		if !explicit {
+			primary = n == "id"
+		}
+		_, c.Indexed = a.Metadata["sql:index"]
+		c.Nullable = !primary && !att.IsRequired(n)
+		if primary {
+			pks = append(pks, c)
+		} else {
+			t.Columns = append(t.Columns, c)
+		}
+	}
+	sort.Slice(pks, func(i, j int) bool { return pks[i].Name < pks[j].Name })
+	sort.Slice(t.Columns, func(i, j int) bool { return t.Columns[i].Name < t.Columns[j].Name })
+	for _, c := range pks {
+		t.PrimaryKey = append(t.PrimaryKey, c.Name)
+	}
+	t.Columns = append(pks, t.Columns...)
+	return t, nil
+}
+
+// sqlType returns the SQL type of the column storing the given attribute.
+func sqlType(att *design.AttributeDefinition) string {
+	if t := att.Metadata["sql:type"]; len(t) > 0 && t[0] != "" {
+		return t[0]
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return "BOOLEAN"
+	case design.IntegerKind:
+		return "BIGINT"
+	case design.NumberKind:
+		return "DOUBLE PRECISION"
+	case design.StringKind:
+		return "TEXT"
+	case design.DateTimeKind:
+		return "TIMESTAMP WITH TIME ZONE"
+	case design.DateKind:
+		return "DATE"
+	case design.UUIDKind:
+		return "UUID"
+	default:
+		return "JSONB"
+	}
+}
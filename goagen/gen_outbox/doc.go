@@ -0,0 +1,8 @@
+/*
+Package genoutbox provides a generator for a transactional outbox that records the events declared
+with the Emits DSL. The generated outbox package defines the event names, the Outbox interface
+and a SQL reference implementation whose Add method takes the transaction used by the controller
+so that events are only recorded if the transaction commits. The generated Relay type polls the
+outbox and delivers pending events using a Publisher, e.g. a message broker client.
+*/
+package genoutbox
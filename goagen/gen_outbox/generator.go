@@ -0,0 +1,331 @@
+package genoutbox
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of an outbox Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the transactional outbox generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Table    string                // Name of the outbox database table
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, table, ver string
+	set := flag.NewFlagSet("outbox", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&table, "table", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Table: table, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the outbox package.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.Table == "" {
+		g.Table = "outbox"
+	}
+
+	emitters := make(map[string][]string)
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			for _, e := range a.Emits {
+				emitters[e] = append(emitters[e], fmt.Sprintf("%s %s", res.Name, a.Name))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return
+	}
+	events := make([]*event, 0, len(emitters))
+	for name, actions := range emitters {
+		events = append(events, &event{
+			Name:    name,
+			VarName: "Event" + codegen.Goify(name, true),
+			Actions: strings.Join(actions, ", "),
+		})
+	}
+	sort.Sort(byName(events))
+
+	g.OutDir = filepath.Join(g.OutDir, "outbox")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	data := map[string]interface{}{
+		"API":    g.API,
+		"Table":  g.Table,
+		"Events": events,
+	}
+	if err = g.writeFile("outbox.go", "Transactional Outbox", outboxT, data, []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("database/sql"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa/uuid"),
+	}); err != nil {
+		return
+	}
+	if err = g.writeFile("relay.go", "Outbox Relay", relayT, data, []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("time"),
+	}); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// writeFile renders the given template into the file with the given name.
+func (g *Generator) writeFile(name, title, tmpl string, data interface{}, imports []*codegen.ImportSpec) error {
+	filename := filepath.Join(g.OutDir, name)
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, filename)
+	title = fmt.Sprintf("%s: %s", g.API.Context(), title)
+	if err := file.WriteHeader(title, "outbox", imports); err != nil {
+		return err
+	}
+	if err := file.ExecuteTemplate(name, tmpl, nil, data); err != nil {
+		return err
+	}
+	return file.FormatCode()
+}
+
+// event contains the data needed to render a single event name constant.
+type event struct {
+	Name    string
+	VarName string
+	Actions string
+}
+
+// byName makes it possible to sort events by name.
+type byName []*event
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+const outboxT = `{{ if .Events }}// Names of the events emitted by the {{ .API.Name }} API actions.
+const (
+{{ range .Events }}	// {{ .VarName }} is emitted by: {{ .Actions }}.
+	{{ .VarName }} = {{ printf "%q" .Name }}
+{{ end }})
+
+{{ end }}// Schema is the SQL statement that creates the outbox table used by SQLOutbox.
+const Schema = ` + "`" + `CREATE TABLE IF NOT EXISTS {{ .Table }} (
+	id         VARCHAR(36) PRIMARY KEY,
+	name       VARCHAR(255) NOT NULL,
+	payload    BYTEA NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	sent_at    TIMESTAMP
+)` + "`" + `
+
+type (
+	// Event is an event recorded in the outbox.
+	Event struct {
+		// ID is the event unique identifier.
+		ID string
+		// Name is the event name.
+		Name string
+		// Payload is the JSON encoded event payload.
+		Payload []byte
+		// CreatedAt is the time the event was recorded.
+		CreatedAt time.Time
+	}
+
+	// Outbox records events and keeps track of their delivery.
+	Outbox interface {
+		// Add records the event using the given transaction. The event is only
+		// recorded if the transaction commits.
+		Add(ctx context.Context, tx *sql.Tx, event *Event) error
+		// Pending returns up to limit events that have not been delivered yet in the
+		// order they were recorded.
+		Pending(ctx context.Context, limit int) ([]*Event, error)
+		// MarkSent records that the event with the given ID was delivered.
+		MarkSent(ctx context.Context, id string) error
+	}
+
+	// Publisher delivers events, typically to a message broker.
+	Publisher interface {
+		// Publish delivers the event.
+		Publish(ctx context.Context, event *Event) error
+	}
+
+	// SQLOutbox is the reference implementation of Outbox which records events in a SQL
+	// database table created with Schema. Queries use PostgreSQL style placeholders.
+	SQLOutbox struct {
+		// DB is the database containing the outbox table.
+		DB *sql.DB
+		// Table is the name of the outbox table.
+		Table string
+	}
+)
+
+// NewEvent creates an event with the given name and JSON encodes the payload.
+func NewEvent(name string, payload interface{}) (*Event, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{ID: uuid.NewV4().String(), Name: name, Payload: body, CreatedAt: time.Now().UTC()}, nil
+}
+
+// NewSQLOutbox returns an outbox that records events in the {{ .Table }} table of db.
+func NewSQLOutbox(db *sql.DB) *SQLOutbox {
+	return &SQLOutbox{DB: db, Table: {{ printf "%q" .Table }}}
+}
+
+// Add records the event using the given transaction.
+func (o *SQLOutbox) Add(ctx context.Context, tx *sql.Tx, event *Event) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO "+o.Table+" (id, name, payload, created_at) VALUES ($1, $2, $3, $4)",
+		event.ID, event.Name, event.Payload, event.CreatedAt)
+	return err
+}
+
+// Pending returns up to limit events that have not been delivered yet.
+func (o *SQLOutbox) Pending(ctx context.Context, limit int) ([]*Event, error) {
+	rows, err := o.DB.QueryContext(ctx,
+		"SELECT id, name, payload, created_at FROM "+o.Table+" WHERE sent_at IS NULL ORDER BY created_at LIMIT $1",
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []*Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.Name, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// MarkSent records that the event with the given ID was delivered.
+func (o *SQLOutbox) MarkSent(ctx context.Context, id string) error {
+	_, err := o.DB.ExecContext(ctx, "UPDATE "+o.Table+" SET sent_at = $1 WHERE id = $2", time.Now().UTC(), id)
+	return err
+}
+`
+
+const relayT = `// Relay delivers the events recorded in an outbox. Events are delivered at least once: an event
+// whose delivery succeeds but that cannot be marked as sent is delivered again.
+type Relay struct {
+	// Outbox contains the events to deliver.
+	Outbox Outbox
+	// Publisher delivers the events.
+	Publisher Publisher
+	// Interval is the duration between two polls of the outbox, one second if not positive.
+	Interval time.Duration
+	// BatchSize is the maximum number of events delivered per poll, 100 if not positive.
+	BatchSize int
+	// OnError is called with the errors that occur while delivering events if not nil.
+	OnError func(error)
+}
+
+// NewRelay returns a relay that polls the outbox every second.
+func NewRelay(outbox Outbox, publisher Publisher) *Relay {
+	return &Relay{Outbox: outbox, Publisher: publisher, Interval: time.Second, BatchSize: 100}
+}
+
+// Run delivers events until ctx is canceled.
+func (r *Relay) Run(ctx context.Context) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Flush(ctx); err != nil && r.OnError != nil {
+			r.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush delivers one batch of pending events and returns the number of events delivered. It
+// stops at the first delivery failure so that events are delivered in order.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	size := r.BatchSize
+	if size <= 0 {
+		size = 100
+	}
+	events, err := r.Outbox.Pending(ctx, size)
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range events {
+		if err := r.Publisher.Publish(ctx, e); err != nil {
+			return i, err
+		}
+		if err := r.Outbox.MarkSent(ctx, e.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+`
@@ -0,0 +1,107 @@
+package genoutbox_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_outbox"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("outboxtest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--table=events", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genoutbox.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with actions emitting events", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Title("dummy API")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Emits("bottle.created")
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Emits("bottle.deleted")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the outbox and relay", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(3))
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "outbox", "outbox.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`EventBottleCreated = "bottle.created"`))
+			Ω(string(content)).Should(ContainSubstring(`EventBottleDeleted = "bottle.deleted"`))
+			Ω(string(content)).Should(ContainSubstring("CREATE TABLE IF NOT EXISTS events ("))
+			Ω(string(content)).Should(ContainSubstring("Add(ctx context.Context, tx *sql.Tx, event *Event) error"))
+			content, err = ioutil.ReadFile(filepath.Join(testPkg.Abs(), "outbox", "relay.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (r *Relay) Flush(ctx context.Context) (int, error) {"))
+			Ω(string(content)).Should(ContainSubstring("if interval <= 0 {\n\t\tinterval = time.Second"))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genoutbox.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genoutbox.NewGenerator(
+				genoutbox.API(args.api),
+				genoutbox.OutDir(args.outDir),
+				genoutbox.Table("events"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Table).Should(Equal("events"))
+		})
+	})
+})
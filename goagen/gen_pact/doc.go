@@ -0,0 +1,17 @@
+/*
+Package genpact provides a generator for consumer-driven contracts in the Pact specification
+version 2 format. The generator writes one interaction per action to
+pact/<consumer>-<provider>.json: the request is built from the examples of the action parameters,
+required headers and payload and the expected response is the first successful response of the
+action with a body built from the media type example. Response bodies are matched by type so that
+the contract can be verified against a service returning actual data, see the pact package.
+
+The provider state of an interaction is read from the "pact:state" action metadata if any:
+
+	Action("show", func() {
+		Routing(GET("/:id"))
+		Metadata("pact:state", "bottle 1 exists")
+		Response(OK)
+	})
+*/
+package genpact
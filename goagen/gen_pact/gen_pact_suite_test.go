@@ -0,0 +1,13 @@
+package genpact_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenPact(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pact Generator Suite")
+}
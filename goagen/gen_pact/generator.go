@@ -0,0 +1,227 @@
+package genpact
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/pact"
+)
+
+// NewGenerator returns an initialized instance of a Pact Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the Pact contract generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Consumer string                // Name of the pact consumer
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, consumer, ver string
+	set := flag.NewFlagSet("pact", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&consumer, "consumer", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Consumer: consumer, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the pact file.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	provider := codegen.Slug(g.API.Name)
+	if g.Consumer == "" {
+		g.Consumer = provider + "-client"
+	}
+	p := &pact.Pact{
+		Consumer: pact.Participant{Name: g.Consumer},
+		Provider: pact.Participant{Name: provider},
+		Metadata: map[string]interface{}{
+			"pactSpecification": map[string]string{"version": pact.SpecificationVersion},
+		},
+	}
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			i, err := g.interaction(a)
+			if err != nil {
+				return fmt.Errorf("%s: %s", a.Context(), err)
+			}
+			if i != nil {
+				p.Interactions = append(p.Interactions, i)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return
+	}
+	if p.Interactions == nil {
+		p.Interactions = []*pact.Interaction{}
+	}
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "pact")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+	path := filepath.Join(g.OutDir, fmt.Sprintf("%s-%s.json", g.Consumer, provider))
+	if err = ioutil.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, path)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// interaction builds the interaction of the given action. It returns nil if the action has no
+// route or no successful response.
+func (g *Generator) interaction(a *design.ActionDefinition) (*pact.Interaction, error) {
+	var resp *design.ResponseDefinition
+	for _, r := range a.Responses {
+		if r.Status >= 200 && r.Status < 300 && (resp == nil || r.Status < resp.Status) {
+			resp = r
+		}
+	}
+	if resp == nil || len(a.Routes) == 0 {
+		return nil, nil
+	}
+	rand := g.API.RandomGenerator()
+	i := &pact.Interaction{
+		Description: a.Name + " " + a.Parent.Name,
+		Request:     g.request(a, rand),
+		Response:    &pact.Response{Status: resp.Status},
+	}
+	if vals, ok := a.Metadata["pact:state"]; ok && len(vals) > 0 {
+		i.ProviderState = vals[0]
+	}
+	if resp.MediaType == "" {
+		return i, nil
+	}
+	i.Response.Headers = map[string]string{"Content-Type": resp.MediaType}
+	mt := g.API.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		return i, nil
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return nil, err
+	}
+	if body := codegen.Example(projected.AttributeDefinition, rand); body != nil {
+		i.Response.Body = body
+		i.Response.MatchingRules = map[string]map[string]interface{}{
+			"$.body": {"match": "type"},
+		}
+	}
+	return i, nil
+}
+
+// request builds the request of the given action using the first action route and the examples
+// of the parameters, required headers and payload.
+func (g *Generator) request(a *design.ActionDefinition, rand *design.RandomGenerator) *pact.Request {
+	ro := a.Routes[0]
+	params := a.AllParams().Type.ToObject()
+	path := design.WildcardRegex.ReplaceAllStringFunc(ro.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		var val interface{}
+		if att, ok := params[name]; ok {
+			val = codegen.Example(att, rand)
+		}
+		if val == nil {
+			val = name
+		}
+		return "/" + url.PathEscape(fmt.Sprint(val))
+	})
+	r := &pact.Request{Method: ro.Verb, Path: path}
+	if a.QueryParams != nil {
+		obj := a.QueryParams.Type.ToObject()
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			if a.QueryParams.IsRequired(name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		query := url.Values{}
+		for _, name := range names {
+			if val := codegen.Example(obj[name], rand); val != nil {
+				query.Set(name, fmt.Sprint(val))
+			}
+		}
+		r.Query = query.Encode()
+	}
+	headers := make(map[string]string)
+	a.IterateHeaders(func(name string, isRequired bool, h *design.AttributeDefinition) error {
+		if isRequired {
+			if val := codegen.Example(h, rand); val != nil {
+				headers[name] = fmt.Sprint(val)
+			}
+		}
+		return nil
+	})
+	if a.Payload != nil {
+		if body := codegen.Example(a.Payload.AttributeDefinition, rand); body != nil {
+			r.Body = body
+			headers["Content-Type"] = "application/json"
+		}
+	}
+	if len(headers) > 0 {
+		r.Headers = headers
+	}
+	return r
+}
@@ -0,0 +1,174 @@
+package genpact_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_pact"
+	"github.com/goadesign/goa/pact"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("pacttest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genpact.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a design defining examples", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.BasePath("/api")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer, func() {
+						apidsl.Example(1)
+					})
+					apidsl.Attribute("name", design.String, func() {
+						apidsl.Example("Number 8")
+					})
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer, func() {
+							apidsl.Example(42)
+						})
+					})
+					apidsl.Metadata("pact:state", "bottle 42 exists")
+					apidsl.Response(design.OK, bottle)
+					apidsl.Response(design.NotFound)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("name", design.String, func() {
+							apidsl.Example("Number 8")
+						})
+					})
+					apidsl.Response(design.Created)
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Response(design.BadRequest)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the pact", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(2))
+			path := filepath.Join(testPkg.Abs(), "pact", "test-api-client-test-api.json")
+			Ω(files[1]).Should(Equal(path))
+			content, err := ioutil.ReadFile(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"version": "` + pact.SpecificationVersion + `"`))
+
+			p, err := pact.LoadFile(path)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.Consumer.Name).Should(Equal("test-api-client"))
+			Ω(p.Provider.Name).Should(Equal("test-api"))
+			Ω(p.Interactions).Should(HaveLen(2))
+
+			create := p.Interactions[0]
+			Ω(create.Description).Should(Equal("create bottle"))
+			Ω(create.Request.Method).Should(Equal("POST"))
+			Ω(create.Request.Path).Should(Equal("/api/bottles"))
+			Ω(create.Request.Headers).Should(Equal(map[string]string{"Content-Type": "application/json"}))
+			Ω(create.Request.Body).Should(Equal(map[string]interface{}{"name": "Number 8"}))
+			Ω(create.Response.Status).Should(Equal(201))
+			Ω(create.Response.Body).Should(BeNil())
+
+			show := p.Interactions[1]
+			Ω(show.Description).Should(Equal("show bottle"))
+			Ω(show.ProviderState).Should(Equal("bottle 42 exists"))
+			Ω(show.Request.Path).Should(Equal("/api/bottles/42"))
+			Ω(show.Response.Status).Should(Equal(200))
+			Ω(show.Response.Headers).Should(Equal(map[string]string{"Content-Type": "application/vnd.bottle+json"}))
+			Ω(show.Response.Body).Should(Equal(map[string]interface{}{"id": 1.0, "name": "Number 8"}))
+			Ω(show.Response.MatchingRules).Should(HaveKey("$.body"))
+		})
+	})
+
+	Context("with a consumer name", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			os.Args = append(os.Args, "--consumer=cellar-ui")
+		})
+
+		It("names the pact after the consumer", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(2))
+			Ω(filepath.Base(files[1])).Should(Equal("cellar-ui-test-api.json"))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genpact.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genpact.NewGenerator(
+				genpact.API(args.api),
+				genpact.OutDir(args.outDir),
+				genpact.Consumer("cellar-ui"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Consumer).Should(Equal("cellar-ui"))
+		})
+	})
+})
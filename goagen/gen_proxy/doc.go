@@ -0,0 +1,35 @@
+/*
+Package genproxy provides a debugging proxy that sits in front of a running service and decodes
+the requests and responses it exchanges using the service design. It is meant for interactive
+debugging during development:
+
+	goagen debug-proxy -d github.com/goadesign/goa-cellar/design --target http://localhost:8080 --addr :8081
+
+The requests sent to the proxy address are forwarded to the target service. For each exchange the
+proxy prints the design action that handles the request, the typed values of its params and
+headers, the decoded payload and response body and the response media type together with the view
+that was negotiated. The request and response are validated against the design and the
+violations are printed alongside, for example:
+
+	--> POST /cellar/accounts/1/bottles bottle#create
+	    params:
+	      accountID: 1
+	    payload (CreateBottlePayload):
+	      {
+	        "name": "Number 8",
+	        "vintage": 1800
+	      }
+	    request: 1 error(s)
+	      - payload.vintage must be greater than or equal to 1900, got 1800
+	<-- 400 BadRequest in 1.2ms
+
+The view of a response is taken from the "view" parameter of its content type if present and
+otherwise inferred from the fields of the body. Only JSON bodies are decoded. The proxy removes the
+Accept-Encoding header from the requests so that the responses are not compressed. It runs until
+the goagen process is interrupted and does not generate any file.
+
+The --record flag appends a summary of each exchange to the given file, one JSON document per line
+(see Record). The "coverage" command reads these files to report the parts of the design that the
+recorded traffic exercised.
+*/
+package genproxy
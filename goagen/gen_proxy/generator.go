@@ -0,0 +1,153 @@
+package genproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+//NewGenerator returns an initialized instance of a debug proxy Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the debug proxy generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	Target   string                // URL of the proxied service
+	Addr     string                // Address the proxy listens on
+	Out      io.Writer             // Writer the decoded exchanges are printed to
+	Recorder io.Writer             // Writer the exchange records are written to if any
+
+	lock sync.Mutex // Serializes the writes to Out and Recorder
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var target, addr, record, ver string
+	set := flag.NewFlagSet("debug-proxy", flag.PanicOnError)
+	set.String("out", "", "")
+	set.StringVar(&target, "target", "http://localhost:8080", "")
+	set.StringVar(&addr, "addr", ":8081", "")
+	set.StringVar(&record, "record", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{API: design.Design, Target: target, Addr: addr, Out: os.Stdout}
+	if record != "" {
+		f, err := os.OpenFile(record, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		g.Recorder = f
+	}
+
+	return g.Generate()
+}
+
+// Generate runs the proxy until the process is interrupted. It does not produce any file.
+func (g *Generator) Generate() (_ []string, err error) {
+	h, err := g.Handler()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(g.out(), "%s debug proxy listening on %s, forwarding to %s\n", g.API.Name, g.Addr, g.Target)
+	return nil, http.ListenAndServe(g.Addr, h)
+}
+
+// Handler returns the HTTP handler that forwards the requests to the target service and prints
+// the decoded exchanges.
+func (g *Generator) Handler() (http.Handler, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	target, err := url.Parse(g.Target)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("invalid target URL %#v, use --target to specify the URL of the service", g.Target)
+	}
+	inspector := NewInspector(g.API)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body []byte
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+			req.Body.Close()
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		// Ask for uncompressed responses so that they can be decoded.
+		req.Header.Del("Accept-Encoding")
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		proxy.ServeHTTP(rec, req)
+		ex := inspector.Inspect(req, body, rec.status, rec.Header(), rec.body.Bytes())
+		ex.Duration = time.Since(start)
+
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		ex.Print(g.out())
+		if g.Recorder != nil {
+			if err := json.NewEncoder(g.Recorder).Encode(ex.Record()); err != nil {
+				fmt.Fprintf(g.out(), "failed to record exchange: %s\n", err)
+			}
+		}
+	}), nil
+}
+
+// out returns the writer the exchanges are printed to.
+func (g *Generator) out() io.Writer {
+	if g.Out == nil {
+		return os.Stdout
+	}
+	return g.Out
+}
+
+// recorder is a http.ResponseWriter that records the response status and body.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status and writes it to the underlying response writer.
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body chunk and writes it to the underlying response writer.
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so that streamed responses are forwarded as they are produced.
+func (r *recorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
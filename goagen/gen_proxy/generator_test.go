@@ -0,0 +1,180 @@
+package genproxy_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_proxy"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generator", func() {
+	var out, record bytes.Buffer
+	var status int
+	var respBody string
+	var service, proxy *httptest.Server
+
+	BeforeEach(func() {
+		out.Reset()
+		record.Reset()
+		status = http.StatusOK
+		respBody = `{"id":1,"name":"Number 8"}`
+		dslengine.Reset()
+		apidsl.API("cellar", func() {
+			apidsl.BasePath("/cellar")
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("name", design.String)
+				apidsl.Attribute("vintage", design.Integer)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+				apidsl.Attribute("vintage")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+			})
+		})
+		apidsl.Resource("bottle", func() {
+			apidsl.BasePath("/bottles")
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+				apidsl.Params(func() {
+					apidsl.Param("id", design.Integer, func() {
+						apidsl.Minimum(1)
+					})
+				})
+				apidsl.Response(design.OK, bottle)
+				apidsl.Response(design.NotFound)
+			})
+			apidsl.Action("create", func() {
+				apidsl.Routing(apidsl.POST(""))
+				apidsl.Payload(func() {
+					apidsl.Attribute("name", design.String)
+					apidsl.Attribute("vintage", design.Integer, func() {
+						apidsl.Minimum(1900)
+					})
+					apidsl.Required("name")
+				})
+				apidsl.Response(design.Created)
+				apidsl.Response(design.BadRequest)
+			})
+		})
+		dslengine.Run()
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+
+		service = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/vnd.bottle+json")
+			w.WriteHeader(status)
+			w.Write([]byte(respBody))
+		}))
+		g := genproxy.NewGenerator(
+			genproxy.API(design.Design),
+			genproxy.Target(service.URL),
+			genproxy.Out(&out),
+			genproxy.Recorder(&record),
+		)
+		h, err := g.Handler()
+		Ω(err).ShouldNot(HaveOccurred())
+		proxy = httptest.NewServer(h)
+	})
+
+	AfterEach(func() {
+		proxy.Close()
+		service.Close()
+	})
+
+	It("forwards the requests and prints the typed params and negotiated view", func() {
+		resp, err := http.Get(proxy.URL + "/cellar/bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(http.StatusOK))
+		Ω(out.String()).Should(ContainSubstring("--> GET /cellar/bottles/1 bottle#show"))
+		Ω(out.String()).Should(ContainSubstring("      id: 1\n"))
+		Ω(out.String()).Should(ContainSubstring("    request: valid\n"))
+		Ω(out.String()).Should(ContainSubstring("<-- 200 OK (application/vnd.bottle+json, view tiny)"))
+		Ω(out.String()).Should(ContainSubstring(`"name": "Number 8"`))
+		Ω(out.String()).Should(ContainSubstring("    response: valid\n"))
+	})
+
+	It("records the exchanges", func() {
+		_, err := http.Get(proxy.URL + "/cellar/bottles/1")
+		Ω(err).ShouldNot(HaveOccurred())
+		records, err := genproxy.ReadRecords(&record)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(records).Should(HaveLen(1))
+		Ω(*records[0]).Should(Equal(genproxy.Record{
+			Method:   "GET",
+			Path:     "/cellar/bottles/1",
+			Resource: "bottle",
+			Action:   "show",
+			Status:   200,
+			View:     "tiny",
+			Params:   map[string]interface{}{"id": 1.0},
+		}))
+	})
+
+	It("reports invalid params and undefined statuses", func() {
+		status = http.StatusInternalServerError
+		_, err := http.Get(proxy.URL + "/cellar/bottles/0")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(out.String()).Should(ContainSubstring("- id must be greater than or equal to 1, got 0"))
+		Ω(out.String()).Should(ContainSubstring("- status 500 is not defined in the design"))
+	})
+
+	It("validates the payload", func() {
+		status = http.StatusCreated
+		respBody = ""
+		_, err := http.Post(proxy.URL+"/cellar/bottles", "application/json", strings.NewReader(`{"vintage":1800}`))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(out.String()).Should(ContainSubstring("--> POST /cellar/bottles bottle#create"))
+		Ω(out.String()).Should(ContainSubstring("    request: 2 error(s)\n"))
+		Ω(out.String()).Should(ContainSubstring("- payload.name is required"))
+		Ω(out.String()).Should(ContainSubstring("- payload.vintage must be greater than or equal to 1900, got 1800"))
+	})
+
+	It("reports requests that do not match the design", func() {
+		_, err := http.Get(proxy.URL + "/cellar/wines")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(out.String()).Should(ContainSubstring("--> GET /cellar/wines (unknown action)"))
+		Ω(out.String()).Should(ContainSubstring("- no action of the design matches the request"))
+	})
+})
+
+var _ = Describe("InferView", func() {
+	var mt *design.MediaTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		mt = apidsl.MediaType("application/vnd.bottle+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("name", design.String)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("name")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("picks the smallest view that defines all the fields", func() {
+		Ω(genproxy.InferView(mt, map[string]interface{}{"id": 1.0})).Should(Equal("tiny"))
+		Ω(genproxy.InferView(mt, map[string]interface{}{"id": 1.0, "name": "a"})).Should(Equal("default"))
+		Ω(genproxy.InferView(mt, []interface{}{map[string]interface{}{"name": "a"}})).Should(Equal("default"))
+		Ω(genproxy.InferView(mt, map[string]interface{}{"foo": 1.0})).Should(BeEmpty())
+	})
+})
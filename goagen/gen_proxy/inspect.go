@@ -0,0 +1,482 @@
+package genproxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/design"
+)
+
+type (
+	// Inspector decodes the requests and responses exchanged with a service using the design
+	// of its API.
+	Inspector struct {
+		// API is the API definition.
+		API *design.APIDefinition
+
+		routes []*route
+	}
+
+	// Exchange describes a request and its response as decoded by an Inspector.
+	Exchange struct {
+		// Method is the request HTTP method.
+		Method string
+		// Path is the request path.
+		Path string
+		// Action is the design action that matches the request, nil if there is none.
+		Action *design.ActionDefinition
+		// Params contains the typed values of the request path and query string params.
+		Params map[string]interface{}
+		// Headers contains the typed values of the request headers defined in the design.
+		Headers map[string]interface{}
+		// Payload is the decoded request body.
+		Payload interface{}
+		// RequestErrors lists the violations of the design found in the request.
+		RequestErrors []string
+		// Status is the response HTTP status.
+		Status int
+		// Response is the design response that matches the response status if any.
+		Response *design.ResponseDefinition
+		// MediaType is the identifier of the response media type if any.
+		MediaType string
+		// View is the name of the negotiated view of the response media type if any.
+		View string
+		// Body is the decoded response body.
+		Body interface{}
+		// ResponseErrors lists the violations of the design found in the response.
+		ResponseErrors []string
+		// Duration is the time it took the service to respond.
+		Duration time.Duration
+	}
+
+	// route associates a design route with the regular expression that matches its path.
+	route struct {
+		route     *design.RouteDefinition
+		path      string
+		regexp    *regexp.Regexp
+		wildcards []string
+	}
+)
+
+// NewInspector returns an inspector for the given API.
+func NewInspector(api *design.APIDefinition) *Inspector {
+	i := &Inspector{API: api}
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			for _, r := range a.Routes {
+				p := r.FullPath()
+				i.routes = append(i.routes, &route{
+					route:     r,
+					path:      p,
+					regexp:    pathRegexp(p),
+					wildcards: design.ExtractWildcards(p),
+				})
+			}
+			return nil
+		})
+	})
+	// Match the routes with the fewest wildcards and the longest paths first so that
+	// "/bottles/count" takes precedence over "/bottles/:id".
+	sort.SliceStable(i.routes, func(a, b int) bool {
+		ra, rb := i.routes[a], i.routes[b]
+		if len(ra.wildcards) != len(rb.wildcards) {
+			return len(ra.wildcards) < len(rb.wildcards)
+		}
+		return len(ra.path) > len(rb.path)
+	})
+	return i
+}
+
+// Match returns the action whose route matches the given method and path together with the
+// values of the path wildcards, nil if there is none.
+func (i *Inspector) Match(method, path string) (*design.ActionDefinition, map[string]string) {
+	r, wildcards := i.match(method, path)
+	if r == nil {
+		return nil, nil
+	}
+	return r.Parent, wildcards
+}
+
+// Route returns the design route that matches the given method and path, nil if there is none.
+func (i *Inspector) Route(method, path string) *design.RouteDefinition {
+	r, _ := i.match(method, path)
+	return r
+}
+
+// match returns the first route that matches the given method and path and the values of its
+// wildcards.
+func (i *Inspector) match(method, path string) (*design.RouteDefinition, map[string]string) {
+	for _, r := range i.routes {
+		if r.route.Verb != method {
+			continue
+		}
+		matches := r.regexp.FindStringSubmatch(path)
+		if matches == nil {
+			continue
+		}
+		wildcards := make(map[string]string, len(r.wildcards))
+		for j, name := range r.wildcards {
+			wildcards[name] = matches[j+1]
+		}
+		return r.route, wildcards
+	}
+	return nil, nil
+}
+
+// Inspect decodes and validates the given request and response using the design.
+func (i *Inspector) Inspect(req *http.Request, body []byte, status int, header http.Header, respBody []byte) *Exchange {
+	ex := &Exchange{Method: req.Method, Path: req.URL.Path, Status: status}
+	action, wildcards := i.Match(req.Method, req.URL.Path)
+	if action == nil {
+		ex.RequestErrors = append(ex.RequestErrors, "no action of the design matches the request")
+		ex.Body, _ = decode(header.Get("Content-Type"), respBody)
+		return ex
+	}
+	ex.Action = action
+	i.inspectRequest(ex, action, wildcards, req, body)
+	i.inspectResponse(ex, action, header, respBody)
+	return ex
+}
+
+// inspectRequest decodes and validates the request params, headers and payload.
+func (i *Inspector) inspectRequest(ex *Exchange, action *design.ActionDefinition, wildcards map[string]string, req *http.Request, body []byte) {
+	params := action.AllParams()
+	query := req.URL.Query()
+	ex.Params = make(map[string]interface{})
+	for _, name := range sortedNames(params.Type.ToObject()) {
+		att := params.Type.ToObject()[name]
+		var raw []string
+		if w, ok := wildcards[name]; ok {
+			raw = []string{w}
+		} else {
+			raw = query[name]
+		}
+		if len(raw) == 0 {
+			if params.IsRequired(name) {
+				ex.RequestErrors = append(ex.RequestErrors, fmt.Sprintf("missing required param %#v", name))
+			}
+			continue
+		}
+		val, err := coerce(att, raw)
+		if err != nil {
+			ex.RequestErrors = append(ex.RequestErrors, fmt.Sprintf("invalid value %#v for param %#v: %s", strings.Join(raw, ","), name, err))
+			continue
+		}
+		ex.Params[name] = val
+		ex.RequestErrors = append(ex.RequestErrors, Validate(name, att, val)...)
+	}
+
+	ex.Headers = make(map[string]interface{})
+	action.IterateHeaders(func(name string, required bool, att *design.AttributeDefinition) error {
+		raw := req.Header[http.CanonicalHeaderKey(name)]
+		if len(raw) == 0 {
+			if required {
+				ex.RequestErrors = append(ex.RequestErrors, fmt.Sprintf("missing required header %#v", name))
+			}
+			return nil
+		}
+		val, err := coerce(att, raw)
+		if err != nil {
+			ex.RequestErrors = append(ex.RequestErrors, fmt.Sprintf("invalid value %#v for header %#v: %s", strings.Join(raw, ","), name, err))
+			return nil
+		}
+		ex.Headers[name] = val
+		ex.RequestErrors = append(ex.RequestErrors, Validate(name, att, val)...)
+		return nil
+	})
+
+	if action.Payload == nil {
+		return
+	}
+	if len(body) == 0 {
+		if !action.PayloadOptional {
+			ex.RequestErrors = append(ex.RequestErrors, "missing required payload")
+		}
+		return
+	}
+	payload, err := decode(req.Header.Get("Content-Type"), body)
+	ex.Payload = payload
+	if err != nil {
+		ex.RequestErrors = append(ex.RequestErrors, fmt.Sprintf("invalid payload: %s", err))
+		return
+	}
+	if _, ok := payload.(string); !ok {
+		ex.RequestErrors = append(ex.RequestErrors, Validate("payload", action.Payload.AttributeDefinition, payload)...)
+	}
+}
+
+// inspectResponse decodes and validates the response body and infers the view used to render
+// it.
+func (i *Inspector) inspectResponse(ex *Exchange, action *design.ActionDefinition, header http.Header, body []byte) {
+	for _, r := range action.Responses {
+		if r.Status == ex.Status {
+			ex.Response = r
+			break
+		}
+	}
+	contentType := header.Get("Content-Type")
+	decoded, err := decode(contentType, body)
+	ex.Body = decoded
+	if ex.Response == nil {
+		ex.ResponseErrors = append(ex.ResponseErrors, fmt.Sprintf("status %d is not defined in the design", ex.Status))
+		return
+	}
+	if err != nil {
+		ex.ResponseErrors = append(ex.ResponseErrors, fmt.Sprintf("invalid body: %s", err))
+		return
+	}
+
+	mt, _ := ex.Response.Type.(*design.MediaTypeDefinition)
+	if mt == nil && ex.Response.MediaType != "" {
+		mt = i.API.MediaTypeWithIdentifier(ex.Response.MediaType)
+	}
+	if mt == nil {
+		if ex.Response.Type != nil && decoded != nil {
+			att := &design.AttributeDefinition{Type: ex.Response.Type}
+			ex.ResponseErrors = append(ex.ResponseErrors, Validate("body", att, decoded)...)
+		}
+		return
+	}
+	ex.MediaType = mt.Identifier
+	if decoded == nil {
+		return
+	}
+	if _, ok := decoded.(string); ok {
+		return
+	}
+	ex.View = ex.Response.ViewName
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["view"] != "" {
+		ex.View = params["view"]
+	}
+	if ex.View == "" {
+		ex.View = InferView(mt, decoded)
+	}
+	if ex.View == "" {
+		ex.ResponseErrors = append(ex.ResponseErrors, fmt.Sprintf("body does not match any view of %s", mt.Identifier))
+		return
+	}
+	projected, _, err := mt.Project(ex.View)
+	if err != nil {
+		ex.ResponseErrors = append(ex.ResponseErrors, err.Error())
+		return
+	}
+	ex.ResponseErrors = append(ex.ResponseErrors, Validate("body", projected.AttributeDefinition, decoded)...)
+}
+
+// InferView returns the name of the view of mt with the fewest attributes that defines all the
+// fields of body, the empty string if there is none. The first element is used if body is an
+// array.
+func InferView(mt *design.MediaTypeDefinition, body interface{}) string {
+	if a, ok := body.([]interface{}); ok {
+		if len(a) == 0 {
+			if _, ok := mt.ComputeViews()["default"]; ok {
+				return "default"
+			}
+			return ""
+		}
+		body = a[0]
+	}
+	obj, ok := body.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	views := mt.ComputeViews()
+	names := make([]string, 0, len(views))
+	for n := range views {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var view string
+	size := -1
+	for _, n := range names {
+		atts := views[n].Type.ToObject()
+		matches := true
+		for field := range obj {
+			if _, ok := atts[field]; !ok {
+				matches = false
+				break
+			}
+		}
+		if matches && (size == -1 || len(atts) < size || (len(atts) == size && n == "default")) {
+			view, size = n, len(atts)
+		}
+	}
+	return view
+}
+
+// Print writes a human friendly description of the exchange to w.
+func (ex *Exchange) Print(w io.Writer) {
+	action := "(unknown action)"
+	if ex.Action != nil {
+		action = fmt.Sprintf("%s#%s", ex.Action.Parent.Name, ex.Action.Name)
+	}
+	fmt.Fprintf(w, "--> %s %s %s\n", ex.Method, ex.Path, action)
+	printValues(w, "params", ex.Params)
+	printValues(w, "headers", ex.Headers)
+	if ex.Payload != nil {
+		name := "payload"
+		if ex.Action.Payload.TypeName != "" {
+			name = fmt.Sprintf("payload (%s)", ex.Action.Payload.TypeName)
+		}
+		printValue(w, name, ex.Payload)
+	}
+	printErrors(w, "request", ex.RequestErrors)
+
+	name := http.StatusText(ex.Status)
+	if ex.Response != nil {
+		name = ex.Response.Name
+	}
+	fmt.Fprintf(w, "<-- %d %s", ex.Status, name)
+	if ex.MediaType != "" {
+		fmt.Fprintf(w, " (%s", ex.MediaType)
+		if ex.View != "" {
+			fmt.Fprintf(w, ", view %s", ex.View)
+		}
+		fmt.Fprint(w, ")")
+	}
+	fmt.Fprintf(w, " in %s\n", ex.Duration)
+	if ex.Body != nil {
+		printValue(w, "body", ex.Body)
+	}
+	if ex.Action != nil {
+		printErrors(w, "response", ex.ResponseErrors)
+	}
+	fmt.Fprintln(w)
+}
+
+// printValues writes the given named values sorted by name.
+func printValues(w io.Writer, title string, vals map[string]interface{}) {
+	if len(vals) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "    %s:\n", title)
+	names := make([]string, 0, len(vals))
+	for n := range vals {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(w, "      %s: %s\n", n, format(vals[n]))
+	}
+}
+
+// printValue writes the indented JSON representation of val.
+func printValue(w io.Writer, title string, val interface{}) {
+	fmt.Fprintf(w, "    %s:\n", title)
+	for _, line := range strings.Split(format(val), "\n") {
+		fmt.Fprintf(w, "      %s\n", line)
+	}
+}
+
+// printErrors writes the validation results.
+func printErrors(w io.Writer, title string, errs []string) {
+	if len(errs) == 0 {
+		fmt.Fprintf(w, "    %s: valid\n", title)
+		return
+	}
+	fmt.Fprintf(w, "    %s: %d error(s)\n", title, len(errs))
+	for _, err := range errs {
+		fmt.Fprintf(w, "      - %s\n", err)
+	}
+}
+
+// format returns the indented JSON representation of val or val itself if it is a string that is
+// not JSON.
+func format(val interface{}) string {
+	if s, ok := val.(string); ok {
+		return s
+	}
+	b, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+	return string(b)
+}
+
+// decode decodes body if its content type is JSON. The body is returned as a string if the
+// content type is not JSON.
+func decode(contentType string, body []byte) (interface{}, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil && !strings.HasSuffix(mediaType, "json") {
+			return string(body), nil
+		}
+	}
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		return string(body), err
+	}
+	return val, nil
+}
+
+// coerce converts the raw values of a param or header to the type of att. Numbers are returned
+// as float64 like values decoded from JSON.
+func coerce(att *design.AttributeDefinition, raw []string) (interface{}, error) {
+	if att.Type.IsArray() {
+		elem := att.Type.ToArray().ElemType
+		vals := make([]interface{}, len(raw))
+		for i, r := range raw {
+			val, err := coerce(elem, []string{r})
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = val
+		}
+		return vals, nil
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return strconv.ParseBool(raw[0])
+	case design.IntegerKind:
+		i, err := strconv.ParseInt(raw[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return float64(i), nil
+	case design.NumberKind:
+		f, err := strconv.ParseFloat(raw[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return f, nil
+	}
+	return raw[0], nil
+}
+
+// pathRegexp returns the regular expression that matches the given route path.
+func pathRegexp(path string) *regexp.Regexp {
+	pattern := "^"
+	last := 0
+	for _, m := range design.WildcardRegex.FindAllStringIndex(path, -1) {
+		pattern += regexp.QuoteMeta(path[last:m[0]]) + "/"
+		if path[m[0]+1] == '*' {
+			pattern += "(.*)"
+		} else {
+			pattern += "([^/]+)"
+		}
+		last = m[1]
+	}
+	pattern += regexp.QuoteMeta(strings.TrimSuffix(path[last:], "/")) + "/?$"
+	return regexp.MustCompile(pattern)
+}
+
+// sortedNames returns the names of the object attributes in alphabetical order.
+func sortedNames(o design.Object) []string {
+	names := make([]string, 0, len(o))
+	for n := range o {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
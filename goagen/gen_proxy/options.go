@@ -0,0 +1,45 @@
+package genproxy
+
+import (
+	"io"
+
+	"github.com/goadesign/goa/design"
+)
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//Target URL of the proxied service
+func Target(target string) Option {
+	return func(g *Generator) {
+		g.Target = target
+	}
+}
+
+//Addr Address the proxy listens on
+func Addr(addr string) Option {
+	return func(g *Generator) {
+		g.Addr = addr
+	}
+}
+
+//Out Writer the decoded exchanges are printed to
+func Out(out io.Writer) Option {
+	return func(g *Generator) {
+		g.Out = out
+	}
+}
+
+//Recorder Writer the exchange records are written to
+func Recorder(recorder io.Writer) Option {
+	return func(g *Generator) {
+		g.Recorder = recorder
+	}
+}
@@ -0,0 +1,64 @@
+package genproxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// Record is the summary of an exchange written by the proxy to the record file given with
+// --record, one JSON document per line. The records can be used to compute the design coverage
+// of the traffic, see the "coverage" command.
+type Record struct {
+	// Method is the request HTTP method.
+	Method string `json:"method"`
+	// Path is the request path.
+	Path string `json:"path"`
+	// Resource is the name of the resource of the matching design action if any.
+	Resource string `json:"resource,omitempty"`
+	// Action is the name of the matching design action if any.
+	Action string `json:"action,omitempty"`
+	// Status is the response HTTP status.
+	Status int `json:"status"`
+	// View is the name of the negotiated view of the response media type if any.
+	View string `json:"view,omitempty"`
+	// Params contains the request path and query string params values.
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Payload is the decoded request body.
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// Record returns the record that summarizes the exchange.
+func (ex *Exchange) Record() *Record {
+	r := &Record{
+		Method:  ex.Method,
+		Path:    ex.Path,
+		Status:  ex.Status,
+		View:    ex.View,
+		Params:  ex.Params,
+		Payload: ex.Payload,
+	}
+	if ex.Action != nil {
+		r.Resource = ex.Action.Parent.Name
+		r.Action = ex.Action.Name
+	}
+	return r
+}
+
+// ReadRecords reads the records written by the proxy.
+func ReadRecords(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, err
+		}
+		records = append(records, &rec)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,167 @@
+package genproxy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/design"
+)
+
+// Validate checks that val, a value decoded from JSON, is compatible with the type of att and
+// satisfies its validations. It returns a description of each violation, ctx is the name of the
+// value used in the descriptions, e.g. "payload".
+func Validate(ctx string, att *design.AttributeDefinition, val interface{}) []string {
+	if val == nil {
+		return nil
+	}
+	switch t := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		return append(Validate(ctx, t.AttributeDefinition, val), validateValue(ctx, att, val)...)
+	case *design.UserTypeDefinition:
+		return append(Validate(ctx, t.AttributeDefinition, val), validateValue(ctx, att, val)...)
+	}
+
+	var errs []string
+	invalid := func() []string {
+		return []string{fmt.Sprintf("%s must be a %s, got %s", ctx, att.Type.Name(), format(val))}
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		if _, ok := val.(bool); !ok {
+			return invalid()
+		}
+	case design.IntegerKind:
+		if f, ok := val.(float64); !ok || f != math.Trunc(f) {
+			return invalid()
+		}
+	case design.NumberKind:
+		if _, ok := val.(float64); !ok {
+			return invalid()
+		}
+	case design.StringKind:
+		if _, ok := val.(string); !ok {
+			return invalid()
+		}
+	case design.DateTimeKind:
+		s, ok := val.(string)
+		if !ok {
+			return invalid()
+		}
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return invalid()
+		}
+	case design.DateKind:
+		s, ok := val.(string)
+		if !ok {
+			return invalid()
+		}
+		if _, err := goa.ParseDate(s); err != nil {
+			return invalid()
+		}
+	case design.UUIDKind:
+		s, ok := val.(string)
+		if !ok || goa.ValidateFormat(goa.FormatUUID, s) != nil {
+			return invalid()
+		}
+	case design.ArrayKind:
+		a, ok := val.([]interface{})
+		if !ok {
+			return invalid()
+		}
+		elem := att.Type.ToArray().ElemType
+		for i, e := range a {
+			errs = append(errs, Validate(fmt.Sprintf("%s[%d]", ctx, i), elem, e)...)
+		}
+	case design.HashKind:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return invalid()
+		}
+		elem := att.Type.ToHash().ElemType
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			errs = append(errs, Validate(fmt.Sprintf("%s[%q]", ctx, k), elem, m[k])...)
+		}
+	case design.ObjectKind:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return invalid()
+		}
+		obj := att.Type.ToObject()
+		for _, n := range sortedNames(obj) {
+			v, ok := m[n]
+			if !ok {
+				if att.IsRequired(n) {
+					errs = append(errs, fmt.Sprintf("%s.%s is required", ctx, n))
+				}
+				continue
+			}
+			errs = append(errs, Validate(ctx+"."+n, obj[n], v)...)
+		}
+	}
+	return append(errs, validateValue(ctx, att, val)...)
+}
+
+// validateValue checks val against the enum, format, pattern, range and length validations of
+// att.
+func validateValue(ctx string, att *design.AttributeDefinition, val interface{}) []string {
+	v := att.Validation
+	if v == nil {
+		return nil
+	}
+	var errs []string
+	if len(v.Values) > 0 {
+		found := false
+		for _, e := range v.Values {
+			if fmt.Sprint(e) == fmt.Sprint(val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s must be one of %v, got %s", ctx, v.Values, format(val)))
+		}
+	}
+	if s, ok := val.(string); ok {
+		if v.Format != "" {
+			if err := goa.ValidateFormat(goa.Format(v.Format), s); err != nil {
+				errs = append(errs, fmt.Sprintf("%s must be formatted as %s: %s", ctx, v.Format, err))
+			}
+		}
+		if v.Pattern != "" && !goa.ValidatePattern(v.Pattern, s) {
+			errs = append(errs, fmt.Sprintf("%s must match the pattern %#v, got %#v", ctx, v.Pattern, s))
+		}
+	}
+	if f, ok := val.(float64); ok {
+		if v.Minimum != nil && f < *v.Minimum {
+			errs = append(errs, fmt.Sprintf("%s must be greater than or equal to %v, got %v", ctx, *v.Minimum, f))
+		}
+		if v.Maximum != nil && f > *v.Maximum {
+			errs = append(errs, fmt.Sprintf("%s must be lower than or equal to %v, got %v", ctx, *v.Maximum, f))
+		}
+	}
+	length := -1
+	switch actual := val.(type) {
+	case string:
+		length = utf8.RuneCountInString(actual)
+	case []interface{}:
+		length = len(actual)
+	}
+	if length >= 0 {
+		if v.MinLength != nil && length < *v.MinLength {
+			errs = append(errs, fmt.Sprintf("%s must have a length greater than or equal to %d, got %d", ctx, *v.MinLength, length))
+		}
+		if v.MaxLength != nil && length > *v.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s must have a length lower than or equal to %d, got %d", ctx, *v.MaxLength, length))
+		}
+	}
+	return errs
+}
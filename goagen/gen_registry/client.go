@@ -0,0 +1,253 @@
+package genregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+// Compatibility is a schema compatibility level.
+type Compatibility string
+
+const (
+	// None disables the compatibility checks.
+	None Compatibility = "NONE"
+	// Backward requires data produced with the previous schema to be readable with the new one.
+	Backward Compatibility = "BACKWARD"
+	// Forward requires data produced with the new schema to be readable with the previous one.
+	Forward Compatibility = "FORWARD"
+	// Full requires both backward and forward compatibility.
+	Full Compatibility = "FULL"
+)
+
+// SchemaType is the type of a published schema, the values match the schema types of the
+// Confluent schema registry API.
+type SchemaType string
+
+const (
+	// SchemaJSON is the type of JSON schemas.
+	SchemaJSON SchemaType = "JSON"
+	// SchemaProtobuf is the type of protobuf (proto3) definitions.
+	SchemaProtobuf SchemaType = "PROTOBUF"
+)
+
+type (
+	// Client is implemented by the schema registry clients.
+	Client interface {
+		// Check returns the reasons why the schema is not compatible with the latest version
+		// registered under the subject. It returns nil if the schema is compatible or if
+		// the subject does not exist yet.
+		Check(subject string, schema *Schema) ([]string, error)
+		// Publish registers the schema as the new version of the subject. It returns an
+		// error if the schema is not compatible with the latest version.
+		Publish(subject string, schema *Schema) error
+	}
+
+	// Schema is a schema published to a registry.
+	Schema struct {
+		// Type is the schema type.
+		Type SchemaType
+		// Content is the schema definition.
+		Content []byte
+	}
+
+	// ConfluentClient is the client of Confluent-compatible schema registries.
+	ConfluentClient struct {
+		// URL is the registry base URL.
+		URL string
+		// Compatibility is the compatibility level set on the subjects before publishing.
+		Compatibility Compatibility
+		// Client is the HTTP client used to make requests, http.DefaultClient if nil.
+		Client *http.Client
+	}
+
+	// StoreClient is the client of HTTP artifact stores. The latest version of a subject is
+	// read with GET <URL>/<subject>/latest and new versions are created with
+	// POST <URL>/<subject>.
+	StoreClient struct {
+		// URL is the store base URL.
+		URL string
+		// Compatibility is the compatibility level enforced by the client.
+		Compatibility Compatibility
+		// Client is the HTTP client used to make requests, http.DefaultClient if nil.
+		Client *http.Client
+	}
+)
+
+// NewClient returns the client for the given kind of registry, either "confluent" or "http".
+func NewClient(kind, registryURL string, compat Compatibility) (Client, error) {
+	if registryURL == "" {
+		return nil, fmt.Errorf("missing registry URL, use --registry to specify it")
+	}
+	switch compat {
+	case None, Backward, Forward, Full:
+	default:
+		return nil, fmt.Errorf("invalid compatibility level %#v, must be one of NONE, BACKWARD, FORWARD or FULL", compat)
+	}
+	switch kind {
+	case "confluent":
+		return &ConfluentClient{URL: registryURL, Compatibility: compat}, nil
+	case "http":
+		return &StoreClient{URL: registryURL, Compatibility: compat}, nil
+	default:
+		return nil, fmt.Errorf("invalid registry kind %#v, must be one of confluent or http", kind)
+	}
+}
+
+// confluentContentType is the content type of the Confluent schema registry API.
+const confluentContentType = "application/vnd.schemaregistry.v1+json"
+
+// Check uses the registry compatibility API with the compatibility level configured for the
+// subject.
+func (c *ConfluentClient) Check(subject string, schema *Schema) ([]string, error) {
+	var res struct {
+		IsCompatible bool     `json:"is_compatible"`
+		Messages     []string `json:"messages"`
+	}
+	path := "/compatibility/subjects/" + url.PathEscape(subject) + "/versions/latest?verbose=true"
+	found, err := c.do("POST", path, schemaBody(schema), &res)
+	if err != nil || !found || res.IsCompatible {
+		return nil, err
+	}
+	if len(res.Messages) == 0 {
+		return []string{fmt.Sprintf("schema is not %s compatible", c.Compatibility)}, nil
+	}
+	return res.Messages, nil
+}
+
+// Publish sets the subject compatibility level, checks the schema and registers it.
+func (c *ConfluentClient) Publish(subject string, schema *Schema) error {
+	config := map[string]string{"compatibility": string(c.Compatibility)}
+	if _, err := c.do("PUT", "/config/"+url.PathEscape(subject), config, nil); err != nil {
+		return err
+	}
+	msgs, err := c.Check(subject, schema)
+	if err != nil {
+		return err
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("incompatible schema: %s", strings.Join(msgs, ", "))
+	}
+	_, err = c.do("POST", "/subjects/"+url.PathEscape(subject)+"/versions", schemaBody(schema), nil)
+	return err
+}
+
+// do makes a request to the registry API and decodes the response into res if not nil. It
+// returns false if the registry responds with 404 Not Found.
+func (c *ConfluentClient) do(method, path string, body, res interface{}) (bool, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.URL, "/")+path, bytes.NewReader(b))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", confluentContentType)
+	req.Header.Set("Accept", confluentContentType)
+	resp, err := client(c.Client).Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		if e.Message == "" {
+			e.Message = resp.Status
+		}
+		return true, fmt.Errorf("%s %s: %s", method, path, e.Message)
+	}
+	if res != nil {
+		return true, json.NewDecoder(resp.Body).Decode(res)
+	}
+	return true, nil
+}
+
+// schemaBody returns the body of the Confluent API requests that carry a schema.
+func schemaBody(schema *Schema) interface{} {
+	return map[string]string{"schemaType": string(schema.Type), "schema": string(schema.Content)}
+}
+
+// Check reads the latest version of the subject and compares it with schema.
+func (c *StoreClient) Check(subject string, schema *Schema) ([]string, error) {
+	if c.Compatibility == None {
+		return nil, nil
+	}
+	resp, err := client(c.Client).Get(c.subjectURL(subject) + "/latest")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s/latest: %s", subject, resp.Status)
+	}
+	if schema.Type == SchemaProtobuf {
+		latest, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return CompareProto(latest, schema.Content), nil
+	}
+	var latest, s genschema.JSONSchema
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, fmt.Errorf("failed to decode latest version: %s", err)
+	}
+	if err := json.Unmarshal(schema.Content, &s); err != nil {
+		return nil, err
+	}
+	return Compare(&latest, &s, c.Compatibility), nil
+}
+
+// Publish checks the schema and creates a new version of the subject.
+func (c *StoreClient) Publish(subject string, schema *Schema) error {
+	msgs, err := c.Check(subject, schema)
+	if err != nil {
+		return err
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("incompatible schema: %s", strings.Join(msgs, ", "))
+	}
+	contentType := "application/schema+json"
+	if schema.Type == SchemaProtobuf {
+		contentType = "text/x-protobuf"
+	}
+	resp, err := client(c.Client).Post(c.subjectURL(subject), contentType, bytes.NewReader(schema.Content))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: %s", subject, resp.Status)
+	}
+	return nil
+}
+
+// subjectURL returns the URL of the given subject.
+func (c *StoreClient) subjectURL(subject string) string {
+	return strings.TrimSuffix(c.URL, "/") + "/" + url.PathEscape(subject)
+}
+
+// client returns c or http.DefaultClient if c is nil.
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
@@ -0,0 +1,153 @@
+package genregistry
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+// Compare returns the reasons why the next version of a schema is not compatible with the
+// previous version given the compatibility level. The checks cover type changes, new required
+// properties and enum values that are no longer accepted.
+func Compare(previous, next *genschema.JSONSchema, compat Compatibility) []string {
+	var msgs []string
+	if compat == Backward || compat == Full {
+		c := &comparer{writerDefs: previous.Definitions, readerDefs: next.Definitions, seen: make(map[string]bool)}
+		msgs = append(msgs, c.compare("$", previous, next)...)
+	}
+	if compat == Forward || compat == Full {
+		c := &comparer{writerDefs: next.Definitions, readerDefs: previous.Definitions, seen: make(map[string]bool)}
+		for _, msg := range c.compare("$", next, previous) {
+			msgs = append(msgs, "previous version: "+msg)
+		}
+	}
+	return msgs
+}
+
+// comparer checks that data valid against a writer schema is valid against a reader schema.
+type comparer struct {
+	writerDefs, readerDefs map[string]*genschema.JSONSchema
+	seen                   map[string]bool
+}
+
+// compare returns the incompatibilities found at the given path.
+func (c *comparer) compare(path string, writer, reader *genschema.JSONSchema) []string {
+	if writer.Ref != "" || reader.Ref != "" {
+		key := writer.Ref + " " + reader.Ref
+		if c.seen[key] {
+			return nil
+		}
+		c.seen[key] = true
+		writer, reader = resolve(writer, c.writerDefs), resolve(reader, c.readerDefs)
+		if writer == nil || reader == nil {
+			return nil
+		}
+	}
+	if writer.Type != "" && reader.Type != "" && writer.Type != reader.Type &&
+		!(writer.Type == genschema.JSONInteger && reader.Type == genschema.JSONNumber) {
+		return []string{fmt.Sprintf("%s: type changed from %s to %s", path, writer.Type, reader.Type)}
+	}
+	var msgs []string
+	if len(reader.Enum) > 0 {
+		if len(writer.Enum) == 0 {
+			msgs = append(msgs, fmt.Sprintf("%s: values restricted to an enum", path))
+		}
+		for _, v := range writer.Enum {
+			if !contains(reader.Enum, v) {
+				msgs = append(msgs, fmt.Sprintf("%s: value %#v no longer accepted", path, v))
+			}
+		}
+	}
+	for _, r := range reader.Required {
+		if !contains(toInterfaces(writer.Required), r) {
+			msgs = append(msgs, fmt.Sprintf("%s.%s: property is now required", path, r))
+		}
+	}
+	names := make([]string, 0, len(reader.Properties))
+	for n := range reader.Properties {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if wp, ok := writer.Properties[n]; ok {
+			msgs = append(msgs, c.compare(path+"."+n, wp, reader.Properties[n])...)
+		}
+	}
+	if writer.Items != nil && reader.Items != nil {
+		msgs = append(msgs, c.compare(path+"[]", writer.Items, reader.Items)...)
+	}
+	return msgs
+}
+
+// CompareProto returns the reasons why the next version of a protobuf definition is not compatible
+// with the previous version. Protobuf compatibility is symmetric: the checks report the field
+// numbers that are reused with a different type or name regardless of the compatibility level.
+func CompareProto(previous, next []byte) []string {
+	prev := protoFields(previous)
+	fields := protoFields(next)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var msgs []string
+	for _, k := range keys {
+		if p, ok := prev[k]; ok && p != fields[k] {
+			msgs = append(msgs, fmt.Sprintf("%s: field changed from %q to %q", k, p, fields[k]))
+		}
+	}
+	return msgs
+}
+
+// protoFields returns the type and name of the fields of the messages defined in the given
+// protobuf definition indexed by qualified message name and field number, e.g. "Bottle#1".
+func protoFields(def []byte) map[string]string {
+	fields := make(map[string]string)
+	var scope []string
+	for _, line := range strings.Split(string(def), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "message ") && strings.HasSuffix(line, "{"):
+			scope = append(scope, strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "message "), "{")))
+		case line == "}":
+			if len(scope) > 0 {
+				scope = scope[:len(scope)-1]
+			}
+		case len(scope) > 0 && strings.HasSuffix(line, ";") && strings.Contains(line, "="):
+			elems := strings.SplitN(strings.TrimSuffix(line, ";"), "=", 2)
+			key := strings.Join(scope, ".") + "#" + strings.TrimSpace(elems[1])
+			fields[key] = strings.Join(strings.Fields(elems[0]), " ")
+		}
+	}
+	return fields
+}
+
+// resolve returns the definition referenced by s if any, s otherwise.
+func resolve(s *genschema.JSONSchema, defs map[string]*genschema.JSONSchema) *genschema.JSONSchema {
+	if s.Ref == "" {
+		return s
+	}
+	return defs[refName(s.Ref)]
+}
+
+// contains returns true if vals contains v.
+func contains(vals []interface{}, v interface{}) bool {
+	for _, val := range vals {
+		if reflect.DeepEqual(val, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInterfaces converts the given strings to a slice of empty interfaces.
+func toInterfaces(vals []string) []interface{} {
+	res := make([]interface{}, len(vals))
+	for i, v := range vals {
+		res[i] = v
+	}
+	return res
+}
@@ -0,0 +1,201 @@
+package genregistry
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+//NewGenerator returns an initialized instance of a schema registry publication Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the schema registry publication generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	Registry Client                // The registry the schemas are published to
+	Prefix   string                // Prefix of the subject names
+	Format   SchemaType            // Type of the published schemas, JSON if empty
+	DryRun   bool                  // Check the compatibility of the schemas without publishing them
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var registry, kind, compatibility, prefix, format, ver string
+	var dryRun bool
+	set := flag.NewFlagSet("registry", flag.PanicOnError)
+	set.String("out", "", "")
+	set.StringVar(&registry, "registry", "", "")
+	set.StringVar(&kind, "kind", "confluent", "")
+	set.StringVar(&compatibility, "compatibility", string(Backward), "")
+	set.StringVar(&prefix, "prefix", "", "")
+	set.StringVar(&format, "format", "json", "")
+	set.BoolVar(&dryRun, "dry-run", false, "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	client, err := NewClient(kind, registry, Compatibility(strings.ToUpper(compatibility)))
+	if err != nil {
+		return nil, err
+	}
+	var schemaType SchemaType
+	switch strings.ToLower(format) {
+	case "json":
+		schemaType = SchemaJSON
+	case "protobuf":
+		schemaType = SchemaProtobuf
+	default:
+		return nil, fmt.Errorf("invalid schema format %#v, must be one of json or protobuf", format)
+	}
+	g := &Generator{API: design.Design, Registry: client, Prefix: prefix, Format: schemaType, DryRun: dryRun}
+
+	return g.Generate()
+}
+
+// Generate checks the compatibility of the design type schemas with the versions registered in
+// the registry and publishes them unless DryRun is true. The schemas are published only if they
+// are all compatible. It does not produce any file.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if g.Registry == nil {
+		return nil, fmt.Errorf("missing registry, use --registry to specify the registry URL")
+	}
+	if g.Prefix == "" {
+		g.Prefix = strings.Join(strings.Fields(codegen.KebabCase(g.API.Name)), "-")
+	}
+
+	schemas, err := g.schemas()
+	if err != nil {
+		return nil, err
+	}
+	subjects := make([]string, 0, len(schemas))
+	for s := range schemas {
+		subjects = append(subjects, s)
+	}
+	sort.Strings(subjects)
+	var incompatible []string
+	for _, subject := range subjects {
+		msgs, err := g.Registry.Check(subject, schemas[subject])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", subject, err)
+		}
+		for _, msg := range msgs {
+			incompatible = append(incompatible, subject+": "+msg)
+		}
+	}
+	if len(incompatible) > 0 {
+		return nil, fmt.Errorf("incompatible schemas:\n%s", strings.Join(incompatible, "\n"))
+	}
+	if g.DryRun {
+		return nil, nil
+	}
+	for _, subject := range subjects {
+		if err := g.Registry.Publish(subject, schemas[subject]); err != nil {
+			return nil, fmt.Errorf("%s: %s", subject, err)
+		}
+	}
+
+	return nil, nil
+}
+
+// schemas returns the schemas to publish indexed by subject.
+func (g *Generator) schemas() (map[string]*Schema, error) {
+	res := make(map[string]*Schema)
+	if g.Format == SchemaProtobuf {
+		defs, err := ProtoSchemas(g.API)
+		if err != nil {
+			return nil, err
+		}
+		for n, def := range defs {
+			res[g.Prefix+"."+n] = &Schema{Type: SchemaProtobuf, Content: def}
+		}
+		return res, nil
+	}
+	for n, s := range Schemas(g.API) {
+		js, err := s.JSON()
+		if err != nil {
+			return nil, err
+		}
+		res[g.Prefix+"."+n] = &Schema{Type: SchemaJSON, Content: js}
+	}
+	return res, nil
+}
+
+// Schemas returns the self-contained JSON schemas of the API media type views and user types
+// indexed by type name.
+func Schemas(api *design.APIDefinition) map[string]*genschema.JSONSchema {
+	genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	var names []string
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			names = append(names, refName(genschema.MediaTypeRef(api, mt, v.Name)))
+			return nil
+		})
+	})
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		names = append(names, refName(genschema.TypeRef(api, ut)))
+		return nil
+	})
+	schemas := make(map[string]*genschema.JSONSchema, len(names))
+	for _, n := range names {
+		s := *genschema.Definitions[n]
+		defs := make(map[string]*genschema.JSONSchema)
+		collect(&s, defs)
+		s.Definitions = defs
+		schemas[n] = &s
+	}
+	return schemas
+}
+
+// collect adds the definitions referenced by s and its children recursively to defs.
+func collect(s *genschema.JSONSchema, defs map[string]*genschema.JSONSchema) {
+	if s == nil {
+		return
+	}
+	if s.Ref != "" {
+		n := refName(s.Ref)
+		if _, ok := defs[n]; ok {
+			return
+		}
+		if d, ok := genschema.Definitions[n]; ok {
+			defs[n] = d
+			collect(d, defs)
+		}
+		return
+	}
+	collect(s.Items, defs)
+	for _, p := range s.Properties {
+		collect(p, defs)
+	}
+	for _, a := range s.AnyOf {
+		collect(a, defs)
+	}
+	for _, o := range s.OneOf {
+		collect(o, defs)
+	}
+}
+
+// refName returns the name of the definition referenced by the given JSON reference.
+func refName(ref string) string {
+	return strings.TrimPrefix(ref, "#/definitions/")
+}
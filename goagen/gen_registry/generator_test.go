@@ -0,0 +1,255 @@
+package genregistry_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_registry"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// recorder is a fake registry that records the requests it receives.
+type recorder struct {
+	sync.Mutex
+	requests []string
+	bodies   map[string]string
+	latest   map[string]string
+	messages []string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+	body, _ := ioutil.ReadAll(req.Body)
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+	r.bodies[req.Method+" "+req.URL.Path] = string(body)
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/compatibility/"):
+		if r.messages == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"is_compatible": len(r.messages) == 0, "messages": r.messages})
+	case req.Method == "GET":
+		latest, ok := r.latest[strings.TrimSuffix(req.URL.Path, "/latest")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(latest))
+	default:
+		w.Write([]byte(`{"id": 1}`))
+	}
+}
+
+var _ = Describe("Generate", func() {
+	var rec *recorder
+	var srv *httptest.Server
+	var genErr error
+
+	BeforeEach(func() {
+		rec = &recorder{bodies: make(map[string]string), latest: make(map[string]string)}
+		srv = httptest.NewServer(rec)
+		dslengine.Reset()
+		apidsl.API("test api", nil)
+		account := apidsl.Type("Account", func() {
+			apidsl.Attribute("name", design.String)
+		})
+		apidsl.MediaType("application/vnd.bottle+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+				apidsl.Attribute("account", account)
+				apidsl.Required("id")
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+				apidsl.Attribute("account")
+			})
+			apidsl.View("tiny", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		dslengine.Run()
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	Context("with a Confluent registry", func() {
+		BeforeEach(func() {
+			os.Args = []string{"goagen", "--out=.", "--design=foo", "--registry=" + srv.URL, "--compatibility=full", "--version=" + version.String()}
+		})
+
+		JustBeforeEach(func() {
+			_, genErr = genregistry.Generate()
+		})
+
+		It("publishes the media type views and user types", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(rec.requests).Should(ContainElement("PUT /config/test-api.Account"))
+			Ω(rec.requests).Should(ContainElement("POST /subjects/test-api.Bottle/versions"))
+			Ω(rec.requests).Should(ContainElement("POST /subjects/test-api.BottleTiny/versions"))
+			Ω(rec.bodies["PUT /config/test-api.Bottle"]).Should(Equal(`{"compatibility":"FULL"}`))
+			var body map[string]string
+			Ω(json.Unmarshal([]byte(rec.bodies["POST /subjects/test-api.Bottle/versions"]), &body)).Should(Succeed())
+			Ω(body["schemaType"]).Should(Equal("JSON"))
+			Ω(body["schema"]).Should(ContainSubstring(`"definitions":{"Account":`))
+		})
+
+		Context("with incompatible schemas", func() {
+			BeforeEach(func() {
+				rec.messages = []string{"property removed"}
+			})
+
+			It("fails without registering the schemas", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring("test-api.Bottle: property removed"))
+				Ω(rec.requests).ShouldNot(ContainElement(HavePrefix("POST /subjects/")))
+				Ω(rec.requests).ShouldNot(ContainElement(HavePrefix("PUT /config/")))
+			})
+		})
+
+		Context("with the protobuf format", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--format=protobuf")
+			})
+
+			It("publishes the protobuf definitions", func() {
+				Ω(genErr).ShouldNot(HaveOccurred())
+				Ω(rec.requests).Should(ContainElement("POST /subjects/test-api.Bottle/versions"))
+				var body map[string]string
+				Ω(json.Unmarshal([]byte(rec.bodies["POST /subjects/test-api.Bottle/versions"]), &body)).Should(Succeed())
+				Ω(body["schemaType"]).Should(Equal("PROTOBUF"))
+				Ω(body["schema"]).Should(Equal(`syntax = "proto3";
+
+package test_api;
+
+message Bottle {
+  Account account = 1;
+  int64 id = 2;
+}
+
+message Account {
+  string name = 1;
+}
+`))
+			})
+		})
+	})
+
+	Context("with a HTTP artifact store", func() {
+		var generator *genregistry.Generator
+
+		BeforeEach(func() {
+			rec.latest["/test-api.BottleTiny"] = `{"type": "object", "properties": {"id": {"type": "string"}}}`
+			rec.latest["/test-api.Account"] = `{"type": "object", "properties": {"name": {"type": "string"}}}`
+			generator = genregistry.NewGenerator(
+				genregistry.API(design.Design),
+				genregistry.Registry(&genregistry.StoreClient{URL: srv.URL, Compatibility: genregistry.Backward}),
+				genregistry.DryRun(true),
+			)
+		})
+
+		JustBeforeEach(func() {
+			_, genErr = generator.Generate()
+		})
+
+		It("reports the incompatible schemas without publishing", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(Equal("incompatible schemas:\ntest-api.BottleTiny: $.id: property is now required\ntest-api.BottleTiny: $.id: type changed from string to integer"))
+			for _, r := range rec.requests {
+				Ω(r).Should(HavePrefix("GET "))
+			}
+		})
+
+		Context("when publishing", func() {
+			BeforeEach(func() {
+				generator.DryRun = false
+			})
+
+			It("does not publish any schema", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(rec.requests).Should(ContainElement("GET /test-api.Account/latest"))
+				for _, r := range rec.requests {
+					Ω(r).Should(HavePrefix("GET "))
+				}
+			})
+		})
+	})
+})
+
+var _ = Describe("CompareProto", func() {
+	It("reports the field numbers that are reused", func() {
+		previous := "message Bottle {\n  int64 id = 1;\n  string name = 2;\n}\n"
+		next := "message Bottle {\n  string color = 1;\n  int64 id = 2;\n  string name = 3;\n}\n"
+		Ω(genregistry.CompareProto([]byte(previous), []byte(next))).Should(Equal([]string{
+			`Bottle#1: field changed from "int64 id" to "string color"`,
+			`Bottle#2: field changed from "string name" to "int64 id"`,
+		}))
+	})
+})
+
+var _ = Describe("Compare", func() {
+	var previous, next string
+	var compat genregistry.Compatibility
+	var msgs []string
+
+	JustBeforeEach(func() {
+		var p, n genschema.JSONSchema
+		Ω(json.Unmarshal([]byte(previous), &p)).Should(Succeed())
+		Ω(json.Unmarshal([]byte(next), &n)).Should(Succeed())
+		msgs = genregistry.Compare(&p, &n, compat)
+	})
+
+	Context("with an enum that is extended", func() {
+		BeforeEach(func() {
+			previous = `{"properties": {"color": {"type": "string", "enum": ["red"]}}}`
+			next = `{"properties": {"color": {"type": "string", "enum": ["red", "white"]}}}`
+		})
+
+		Context("with backward compatibility", func() {
+			BeforeEach(func() {
+				compat = genregistry.Backward
+			})
+
+			It("is compatible", func() {
+				Ω(msgs).Should(BeEmpty())
+			})
+		})
+
+		Context("with forward compatibility", func() {
+			BeforeEach(func() {
+				compat = genregistry.Forward
+			})
+
+			It("is not compatible", func() {
+				Ω(msgs).Should(Equal([]string{`previous version: $.color: value "white" no longer accepted`}))
+			})
+		})
+	})
+
+	Context("with referenced definitions", func() {
+		BeforeEach(func() {
+			compat = genregistry.Full
+			previous = `{"$ref": "#/definitions/A", "definitions": {"A": {"properties": {"n": {"type": "integer"}}}}}`
+			next = `{"$ref": "#/definitions/A", "definitions": {"A": {"properties": {"n": {"type": "number"}}}}}`
+		})
+
+		It("compares the definitions", func() {
+			Ω(msgs).Should(Equal([]string{"previous version: $.n: type changed from number to integer"}))
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package genregistry
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//Registry The registry the schemas are published to
+func Registry(registry Client) Option {
+	return func(g *Generator) {
+		g.Registry = registry
+	}
+}
+
+//Prefix Prefix of the subject names
+func Prefix(prefix string) Option {
+	return func(g *Generator) {
+		g.Prefix = prefix
+	}
+}
+
+//Format Type of the published schemas
+func Format(format SchemaType) Option {
+	return func(g *Generator) {
+		g.Format = format
+	}
+}
+
+//DryRun Check the compatibility of the schemas without publishing them
+func DryRun(dryRun bool) Option {
+	return func(g *Generator) {
+		g.DryRun = dryRun
+	}
+}
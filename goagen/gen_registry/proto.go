@@ -0,0 +1,164 @@
+package genregistry
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// ProtoSchemas returns the self-contained protobuf (proto3) definitions of the API media type
+// views and user types indexed by type name. Each definition contains the message describing
+// the type and the messages of the types it references. Fields are numbered in the alphabetical
+// order of the attribute names so that the registry compatibility checks report renumbered
+// fields.
+func ProtoSchemas(api *design.APIDefinition) (map[string][]byte, error) {
+	roots := make(map[string]*design.UserTypeDefinition)
+	err := api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		return mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			roots[p.TypeName] = p.UserTypeDefinition
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		roots[ut.TypeName] = ut
+		return nil
+	})
+	pkg := protoIdentifier(codegen.SnakeCase(api.Name))
+	schemas := make(map[string][]byte, len(roots))
+	for n, ut := range roots {
+		w := &protoWriter{seen: map[string]bool{ut.TypeName: true}, queue: []*design.UserTypeDefinition{ut}}
+		fmt.Fprintf(&w.buf, "syntax = \"proto3\";\n\npackage %s;\n", pkg)
+		for len(w.queue) > 0 {
+			t := w.queue[0]
+			w.queue = w.queue[1:]
+			w.message(protoMessageName(t.TypeName), t.AttributeDefinition, "")
+		}
+		schemas[n] = w.buf.Bytes()
+	}
+	return schemas, nil
+}
+
+// protoWriter renders the messages of a protobuf definition.
+type protoWriter struct {
+	buf   bytes.Buffer
+	seen  map[string]bool
+	queue []*design.UserTypeDefinition
+}
+
+// message writes the message with the given name describing att. Attributes that are not
+// objects are described by a message with a single "value" field.
+func (w *protoWriter) message(name string, att *design.AttributeDefinition, indent string) {
+	fmt.Fprintf(&w.buf, "\n%smessage %s {\n", indent, name)
+	obj := att.Type.ToObject()
+	if obj == nil {
+		obj = design.Object{"value": att}
+	}
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for i, n := range names {
+		typ := w.fieldType(n, obj[n], indent+"  ")
+		fmt.Fprintf(&w.buf, "%s  %s %s = %d;\n", indent, typ, protoIdentifier(codegen.SnakeCase(n)), i+1)
+	}
+	fmt.Fprintf(&w.buf, "%s}\n", indent)
+}
+
+// fieldType returns the protobuf type of the field with the given name. It writes the nested
+// messages of inline objects and queues the messages of the referenced user types.
+func (w *protoWriter) fieldType(name string, att *design.AttributeDefinition, indent string) string {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return protoScalar(actual.Kind())
+	case *design.Array:
+		elem := w.fieldType(name, actual.ElemType, indent)
+		if strings.HasPrefix(elem, "repeated ") || strings.HasPrefix(elem, "map<") {
+			elem = "bytes"
+		}
+		return "repeated " + elem
+	case *design.Hash:
+		key := "string"
+		if p, ok := actual.KeyType.Type.(design.Primitive); ok {
+			switch p.Kind() {
+			case design.BooleanKind, design.IntegerKind:
+				key = protoScalar(p.Kind())
+			}
+		}
+		elem := w.fieldType(name, actual.ElemType, indent)
+		if strings.HasPrefix(elem, "repeated ") || strings.HasPrefix(elem, "map<") {
+			elem = "bytes"
+		}
+		return fmt.Sprintf("map<%s, %s>", key, elem)
+	case design.Object:
+		msg := protoMessageName(name)
+		w.message(msg, att, indent)
+		return msg
+	case *design.MediaTypeDefinition:
+		return w.userType(actual.UserTypeDefinition, name, indent)
+	case *design.UserTypeDefinition:
+		return w.userType(actual, name, indent)
+	}
+	return "bytes"
+}
+
+// userType returns the protobuf type of a field whose type is the given user type.
+func (w *protoWriter) userType(ut *design.UserTypeDefinition, name, indent string) string {
+	if !ut.Type.IsObject() {
+		return w.fieldType(name, ut.AttributeDefinition, indent)
+	}
+	if !w.seen[ut.TypeName] {
+		w.seen[ut.TypeName] = true
+		w.queue = append(w.queue, ut)
+	}
+	return protoMessageName(ut.TypeName)
+}
+
+// protoScalar returns the protobuf scalar type of the given primitive kind.
+func protoScalar(kind design.Kind) string {
+	switch kind {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int64"
+	case design.NumberKind:
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.DateKind, design.UUIDKind:
+		return "string"
+	}
+	return "bytes"
+}
+
+// protoMessageName returns a valid message name for the given type or attribute name.
+func protoMessageName(name string) string {
+	return protoIdentifier(codegen.Goify(name, true))
+}
+
+// protoIdentifier replaces the characters that are not valid in protobuf identifiers with
+// underscores.
+func protoIdentifier(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				return protoIdentifier("_" + name)
+			}
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,8 @@
+/*
+Package genrepo provides a generator for the storage adapters of the API resources. For each
+resource whose canonical media type defines an "id" attribute the generated repository package
+contains a Repository interface exposing the CRUD operations typed with the resource media type,
+the filter and list option structs used to list resources and two reference implementations: one
+that keeps the resources in memory and one that stores them in a SQL database table.
+*/
+package genrepo
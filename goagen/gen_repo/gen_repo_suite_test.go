@@ -0,0 +1,13 @@
+package genrepo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenRepo(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Repository Generator Suite")
+}
@@ -0,0 +1,657 @@
+package genrepo
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a repository Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the storage adapter generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	AppPkg   string                // Name of generated "app" package
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, appPkg, ver string
+	set := flag.NewFlagSet("repository", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, AppPkg: appPkg, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the repository package.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+	elems := strings.Split(g.AppPkg, "/")
+	pkgName := elems[len(elems)-1]
+	appImport := g.AppPkg
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err != nil {
+		appImport, err = codegen.PackagePath(g.OutDir)
+		if err != nil {
+			return nil, err
+		}
+		appImport = path.Join(filepath.ToSlash(appImport), g.AppPkg)
+	}
+
+	var resources []*resourceData
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		if res := newResourceData(g.API, r, pkgName); res != nil {
+			resources = append(resources, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "repository")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+
+	if err = g.writeFile("repository.go", "Repository Helpers", repositoryT, nil, []*codegen.ImportSpec{
+		codegen.SimpleImport("database/sql"),
+		codegen.SimpleImport("errors"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+	}); err != nil {
+		return
+	}
+	for _, res := range resources {
+		name := codegen.SnakeCase(res.Name) + ".go"
+		title := fmt.Sprintf("%s Repository", res.Name)
+		if err = g.writeFile(name, title, resourceT, res, []*codegen.ImportSpec{
+			codegen.SimpleImport("context"),
+			codegen.SimpleImport("database/sql"),
+			codegen.SimpleImport("fmt"),
+			codegen.SimpleImport("sort"),
+			codegen.SimpleImport("strings"),
+			codegen.SimpleImport("sync"),
+			codegen.SimpleImport("time"),
+			codegen.SimpleImport("github.com/goadesign/goa"),
+			codegen.NewImport("uuid", "github.com/satori/go.uuid"),
+			codegen.SimpleImport(appImport),
+		}); err != nil {
+			return
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// writeFile renders the given template into the file with the given name.
+func (g *Generator) writeFile(name, title, tmpl string, data interface{}, imports []*codegen.ImportSpec) error {
+	filename := filepath.Join(g.OutDir, name)
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, filename)
+	title = fmt.Sprintf("%s: %s", g.API.Context(), title)
+	if err := file.WriteHeader(title, "repository", imports); err != nil {
+		return err
+	}
+	funcs := template.FuncMap{
+		"columns": columns,
+		"values":  values,
+		"lower":   func(s string) string { return codegen.Goify(s, false) },
+		"inc":     func(i int) int { return i + 1 },
+	}
+	if err := file.ExecuteTemplate(name, tmpl, funcs, data); err != nil {
+		return err
+	}
+	return file.FormatCode()
+}
+
+type (
+	// resourceData contains the data needed to render the repository of a resource.
+	resourceData struct {
+		Name     string   // Go name of the resource, e.g. "Bottle"
+		Resource string   // Design name of the resource, e.g. "bottle"
+		Type     string   // Go type of the resource media type, e.g. "app.GoaExampleBottle"
+		ID       *field   // Identifier field
+		Fields   []*field // Stored fields including the identifier sorted by name
+	}
+
+	// field describes a stored attribute.
+	field struct {
+		Name    string // Attribute name, also used as column name
+		Field   string // Go struct field name
+		Type    string // Go type
+		Pointer bool   // Whether the struct field is a pointer
+		Null    string // Name of the value field of nullable attributes, e.g. "String"
+	}
+)
+
+// newResourceData returns the data needed to render the repository of the given resource or nil
+// if the resource has no canonical media type or if the media type has no primitive non nullable
+// "id" attribute. Only the primitive attributes of the default view are stored.
+func newResourceData(api *design.APIDefinition, r *design.ResourceDefinition, pkgName string) *resourceData {
+	if r.MediaType == "" {
+		return nil
+	}
+	mt := api.MediaTypeWithIdentifier(r.MediaType)
+	if mt == nil || mt.IsArray() {
+		return nil
+	}
+	p, _, err := mt.Project(design.DefaultView)
+	if err != nil {
+		return nil
+	}
+	obj := p.Type.ToObject()
+	if obj == nil {
+		return nil
+	}
+	res := &resourceData{
+		Name:     codegen.Goify(r.Name, true),
+		Resource: r.Name,
+		Type:     pkgName + "." + codegen.GoTypeName(p, p.AllRequired(), 0, false),
+	}
+	for n, att := range obj {
+		prim, ok := att.Type.(design.Primitive)
+		if !ok || prim.Kind() == design.AnyKind {
+			continue
+		}
+		f := &field{
+			Name:    n,
+			Field:   codegen.GoifyAtt(att, n, true),
+			Type:    codegen.GoNativeType(prim),
+			Pointer: p.IsPrimitivePointer(n),
+		}
+		if att.Nullable {
+			f.Null = codegen.NullValueField(prim)
+		}
+		if n == "id" && !att.Nullable {
+			res.ID = f
+		}
+		res.Fields = append(res.Fields, f)
+	}
+	if res.ID == nil {
+		return nil
+	}
+	sort.Sort(byName(res.Fields))
+	return res
+}
+
+// columns returns the comma separated list of the columns storing the given fields.
+func columns(fields []*field) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// values returns the given fields excluding the identifier.
+func values(fields []*field, id *field) []*field {
+	var res []*field
+	for _, f := range fields {
+		if f != id {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// byName makes it possible to sort fields by name.
+type byName []*field
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+const repositoryT = `var (
+	// ErrNotFound is the error returned by the repositories when a resource does not exist.
+	ErrNotFound = errors.New("resource not found")
+	// ErrAlreadyExists is the error returned by the repositories when creating a resource whose
+	// identifier is already used.
+	ErrAlreadyExists = errors.New("resource already exists")
+	// ErrMissingID is the error returned by the repositories when creating a resource whose
+	// identifier is not set.
+	ErrMissingID = errors.New("resource identifier is not set")
+)
+
+// Sort describes the order of listed resources.
+type Sort struct {
+	// Field is the name of the attribute used to sort the resources.
+	Field string
+	// Desc is true if resources are sorted in descending order.
+	Desc bool
+}
+
+// validateSort returns an error if a sort field is not one of fields.
+func validateSort(sorts []Sort, fields []string) error {
+	for _, s := range sorts {
+		found := false
+		for _, f := range fields {
+			if s.Field == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid sort field %#v, must be one of %s", s.Field, strings.Join(fields, ", "))
+		}
+	}
+	return nil
+}
+
+// checkAffected returns ErrNotFound if the statement that produced res did not affect any row.
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// compare returns -1, 0 or 1 if a is respectively lower than, equal to or greater than b. nil is
+// lower than any other value.
+func compare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+	case int:
+		bv := b.(int)
+		if av == bv {
+			return 0
+		}
+		if av < bv {
+			return -1
+		}
+	case float64:
+		bv := b.(float64)
+		if av == bv {
+			return 0
+		}
+		if av < bv {
+			return -1
+		}
+	case string:
+		return strings.Compare(av, b.(string))
+	case time.Time:
+		bv := b.(time.Time)
+		if av.Equal(bv) {
+			return 0
+		}
+		if av.Before(bv) {
+			return -1
+		}
+	case uuid.UUID:
+		return strings.Compare(av.String(), b.(uuid.UUID).String())
+	}
+	return 1
+}
+`
+
+const resourceT = `{{ $id := .ID }}{{ $name := .Name }}type (
+	// {{ .Name }}Repository stores {{ .Resource }} resources.
+	{{ .Name }}Repository interface {
+		// Create stores a new {{ .Resource }}. The {{ .Resource }} identifier must be set.
+		Create(ctx context.Context, m *{{ .Type }}) error
+		// Get returns the {{ .Resource }} with the given identifier or ErrNotFound.
+		Get(ctx context.Context, id {{ .ID.Type }}) (*{{ .Type }}, error)
+		// List returns the {{ .Resource }} resources that match the given options.
+		List(ctx context.Context, opts *{{ .Name }}ListOptions) ([]*{{ .Type }}, error)
+		// Update replaces the {{ .Resource }} with the same identifier or returns ErrNotFound.
+		Update(ctx context.Context, m *{{ .Type }}) error
+		// Delete removes the {{ .Resource }} with the given identifier or returns ErrNotFound.
+		Delete(ctx context.Context, id {{ .ID.Type }}) error
+	}
+
+	// {{ .Name }}Filter lists the values of the attributes that listed {{ .Resource }} resources must
+	// match, nil fields match any value.
+	{{ .Name }}Filter struct {
+{{ range .Fields }}		{{ .Field }} *{{ .Type }}
+{{ end }}	}
+
+	// {{ .Name }}ListOptions contains the options used to list {{ .Resource }} resources.
+	{{ .Name }}ListOptions struct {
+		// Filter restricts the listed resources.
+		Filter {{ .Name }}Filter
+		// Sort lists the sort criteria in order of precedence. Resources are sorted by
+		// identifier by default.
+		Sort []Sort
+		// Offset is the number of resources to skip.
+		Offset int
+		// Limit is the maximum number of resources to return, 0 means no limit.
+		Limit int
+	}
+
+	// Memory{{ .Name }}Repository is a {{ .Name }}Repository that keeps {{ .Resource }} resources in memory.
+	Memory{{ .Name }}Repository struct {
+		sync.Mutex
+		items map[{{ .ID.Type }}]*{{ .Type }}
+	}
+
+	// SQL{{ .Name }}Repository is a {{ .Name }}Repository that stores {{ .Resource }} resources in a SQL
+	// table with one column per attribute named after the attribute. Queries use PostgreSQL style
+	// placeholders.
+	SQL{{ .Name }}Repository struct {
+		// DB is the database containing the table.
+		DB *sql.DB
+		// Table is the name of the table.
+		Table string
+	}
+
+	// by{{ .Name }} makes it possible to sort {{ .Resource }} resources.
+	by{{ .Name }} struct {
+		items []*{{ .Type }}
+		sort  []Sort
+	}
+)
+
+// {{ .Name }}SortFields lists the names of the attributes that can be used to sort {{ .Resource }} resources.
+var {{ .Name }}SortFields = []string{ {{ range $i, $f := .Fields }}{{ if $i }}, {{ end }}{{ printf "%q" $f.Name }}{{ end }} }
+
+// {{ .Name }}Columns lists the columns of the {{ .Resource }} table.
+const {{ .Name }}Columns = {{ printf "%q" (columns .Fields) }}
+
+// NewMemory{{ .Name }}Repository returns an empty in-memory {{ .Resource }} repository.
+func NewMemory{{ .Name }}Repository() *Memory{{ .Name }}Repository {
+	return &Memory{{ .Name }}Repository{items: make(map[{{ .ID.Type }}]*{{ .Type }})}
+}
+
+// NewSQL{{ .Name }}Repository returns a {{ .Resource }} repository that stores resources in the
+// {{ .Resource }} table of db.
+func NewSQL{{ .Name }}Repository(db *sql.DB) *SQL{{ .Name }}Repository {
+	return &SQL{{ .Name }}Repository{DB: db, Table: {{ printf "%q" .Resource }}}
+}
+
+// {{ lower .Name }}Value returns the value of the attribute with the given name or nil if not set.
+func {{ lower .Name }}Value(m *{{ .Type }}, name string) interface{} {
+	switch name {
+{{ range .Fields }}	case {{ printf "%q" .Name }}:
+{{ if .Null }}		if !m.{{ .Field }}.Valid {
+			return nil
+		}
+		return m.{{ .Field }}.{{ .Null }}
+{{ else if .Pointer }}		if m.{{ .Field }} == nil {
+			return nil
+		}
+		return *m.{{ .Field }}
+{{ else }}		return m.{{ .Field }}
+{{ end }}{{ end }}	}
+	return nil
+}
+
+// match{{ .Name }} returns true if the {{ .Resource }} matches the filter.
+func match{{ .Name }}(m *{{ .Type }}, f *{{ .Name }}Filter) bool {
+{{ range .Fields }}	if f.{{ .Field }} != nil && compare({{ lower $name }}Value(m, {{ printf "%q" .Name }}), *f.{{ .Field }}) != 0 {
+		return false
+	}
+{{ end }}	return true
+}
+
+func (b *by{{ .Name }}) Len() int      { return len(b.items) }
+func (b *by{{ .Name }}) Swap(i, j int) { b.items[i], b.items[j] = b.items[j], b.items[i] }
+func (b *by{{ .Name }}) Less(i, j int) bool {
+	for _, s := range b.sort {
+		c := compare({{ lower .Name }}Value(b.items[i], s.Field), {{ lower .Name }}Value(b.items[j], s.Field))
+		if c != 0 {
+			return (c < 0) != s.Desc
+		}
+	}
+	return compare({{ lower .Name }}Value(b.items[i], "id"), {{ lower .Name }}Value(b.items[j], "id")) < 0
+}
+
+// Create stores a new {{ .Resource }}.
+func (r *Memory{{ .Name }}Repository) Create(ctx context.Context, m *{{ .Type }}) error {
+{{ if .ID.Pointer }}	if m.{{ .ID.Field }} == nil {
+		return ErrMissingID
+	}
+{{ end }}	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.items[{{ if .ID.Pointer }}*{{ end }}m.{{ .ID.Field }}]; ok {
+		return ErrAlreadyExists
+	}
+	c := *m
+	r.items[{{ if .ID.Pointer }}*{{ end }}m.{{ .ID.Field }}] = &c
+	return nil
+}
+
+// Get returns the {{ .Resource }} with the given identifier.
+func (r *Memory{{ .Name }}Repository) Get(ctx context.Context, id {{ .ID.Type }}) (*{{ .Type }}, error) {
+	r.Lock()
+	defer r.Unlock()
+	m, ok := r.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *m
+	return &c, nil
+}
+
+// List returns the {{ .Resource }} resources that match the given options.
+func (r *Memory{{ .Name }}Repository) List(ctx context.Context, opts *{{ .Name }}ListOptions) ([]*{{ .Type }}, error) {
+	if opts == nil {
+		opts = &{{ .Name }}ListOptions{}
+	}
+	if err := validateSort(opts.Sort, {{ .Name }}SortFields); err != nil {
+		return nil, err
+	}
+	r.Lock()
+	var res []*{{ .Type }}
+	for _, m := range r.items {
+		if match{{ .Name }}(m, &opts.Filter) {
+			c := *m
+			res = append(res, &c)
+		}
+	}
+	r.Unlock()
+	sort.Sort(&by{{ .Name }}{items: res, sort: opts.Sort})
+	if opts.Offset >= len(res) {
+		return nil, nil
+	}
+	res = res[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(res) {
+		res = res[:opts.Limit]
+	}
+	return res, nil
+}
+
+// Update replaces the {{ .Resource }} with the same identifier.
+func (r *Memory{{ .Name }}Repository) Update(ctx context.Context, m *{{ .Type }}) error {
+{{ if .ID.Pointer }}	if m.{{ .ID.Field }} == nil {
+		return ErrNotFound
+	}
+{{ end }}	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.items[{{ if .ID.Pointer }}*{{ end }}m.{{ .ID.Field }}]; !ok {
+		return ErrNotFound
+	}
+	c := *m
+	r.items[{{ if .ID.Pointer }}*{{ end }}m.{{ .ID.Field }}] = &c
+	return nil
+}
+
+// Delete removes the {{ .Resource }} with the given identifier.
+func (r *Memory{{ .Name }}Repository) Delete(ctx context.Context, id {{ .ID.Type }}) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.items[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.items, id)
+	return nil
+}
+
+// Create stores a new {{ .Resource }}.
+func (r *SQL{{ .Name }}Repository) Create(ctx context.Context, m *{{ .Type }}) error {
+{{ if .ID.Pointer }}	if m.{{ .ID.Field }} == nil {
+		return ErrMissingID
+	}
+{{ end }}	_, err := r.DB.ExecContext(ctx,
+		"INSERT INTO "+r.Table+" ("+{{ .Name }}Columns+") VALUES ({{ range $i, $f := .Fields }}{{ if $i }}, {{ end }}${{ inc $i }}{{ end }})",
+		{{ range $i, $f := .Fields }}{{ if $i }}, {{ end }}m.{{ $f.Field }}{{ end }})
+	return err
+}
+
+// Get returns the {{ .Resource }} with the given identifier.
+func (r *SQL{{ .Name }}Repository) Get(ctx context.Context, id {{ .ID.Type }}) (*{{ .Type }}, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+{{ .Name }}Columns+" FROM "+r.Table+" WHERE {{ .ID.Name }} = $1", id)
+	var m {{ .Type }}
+	if err := row.Scan({{ range $i, $f := .Fields }}{{ if $i }}, {{ end }}&m.{{ $f.Field }}{{ end }}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// List returns the {{ .Resource }} resources that match the given options.
+func (r *SQL{{ .Name }}Repository) List(ctx context.Context, opts *{{ .Name }}ListOptions) ([]*{{ .Type }}, error) {
+	if opts == nil {
+		opts = &{{ .Name }}ListOptions{}
+	}
+	if err := validateSort(opts.Sort, {{ .Name }}SortFields); err != nil {
+		return nil, err
+	}
+	var (
+		where []string
+		args  []interface{}
+	)
+{{ range .Fields }}	if opts.Filter.{{ .Field }} != nil {
+		args = append(args, *opts.Filter.{{ .Field }})
+		where = append(where, fmt.Sprintf("{{ .Name }} = $%d", len(args)))
+	}
+{{ end }}	query := "SELECT " + {{ .Name }}Columns + " FROM " + r.Table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	order := make([]string, 0, len(opts.Sort)+1)
+	for _, s := range opts.Sort {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		order = append(order, s.Field+" "+dir)
+	}
+	query += " ORDER BY " + strings.Join(append(order, "{{ .ID.Name }} ASC"), ", ")
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if opts.Offset > 0 {
+		args = append(args, opts.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []*{{ .Type }}
+	for rows.Next() {
+		var m {{ .Type }}
+		if err := rows.Scan({{ range $i, $f := .Fields }}{{ if $i }}, {{ end }}&m.{{ $f.Field }}{{ end }}); err != nil {
+			return nil, err
+		}
+		res = append(res, &m)
+	}
+	return res, rows.Err()
+}
+
+// Update replaces the {{ .Resource }} with the same identifier.
+func (r *SQL{{ .Name }}Repository) Update(ctx context.Context, m *{{ .Type }}) error {
+{{ if .ID.Pointer }}	if m.{{ .ID.Field }} == nil {
+		return ErrNotFound
+	}
+{{ end }}{{ $values := values .Fields .ID }}{{ if $values }}	res, err := r.DB.ExecContext(ctx,
+		"UPDATE "+r.Table+" SET {{ range $i, $f := $values }}{{ if $i }}, {{ end }}{{ $f.Name }} = ${{ inc $i }}{{ end }} WHERE {{ .ID.Name }} = ${{ inc (len $values) }}",
+		{{ range $values }}m.{{ .Field }}, {{ end }}m.{{ .ID.Field }})
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+{{ else }}	_, err := r.Get(ctx, {{ if .ID.Pointer }}*{{ end }}m.{{ .ID.Field }})
+	return err
+{{ end }}}
+
+// Delete removes the {{ .Resource }} with the given identifier.
+func (r *SQL{{ .Name }}Repository) Delete(ctx context.Context, id {{ .ID.Type }}) error {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM "+r.Table+" WHERE {{ .ID.Name }} = $1", id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+`
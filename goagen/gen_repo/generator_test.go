@@ -0,0 +1,124 @@
+package genrepo_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_repo"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("repotest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genrepo.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a resource whose media type defines an id", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Title("dummy API")
+			})
+			bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+				apidsl.TypeName("Bottle")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+					apidsl.Attribute("name", design.String)
+					apidsl.Attribute("bottled_on", design.Date)
+					apidsl.Required("id")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("bottled_on")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.DefaultMedia(bottle)
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Response(design.OK)
+				})
+			})
+			apidsl.Resource("health", func() {
+				apidsl.Action("check", func() {
+					apidsl.Routing(apidsl.GET("/health"))
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the repository of the resource", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(3))
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "repository", "bottle.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("Get(ctx context.Context, id int) (*app.Bottle, error)"))
+			Ω(string(content)).Should(ContainSubstring("Name      *string"))
+			Ω(string(content)).Should(ContainSubstring("BottledOn *goa.Date"))
+			Ω(string(content)).Should(ContainSubstring(`"github.com/goadesign/goa"`))
+			Ω(string(content)).Should(ContainSubstring(`BottleColumns = "bottled_on, id, name"`))
+			Ω(string(content)).Should(ContainSubstring("func NewMemoryBottleRepository() *MemoryBottleRepository {"))
+			Ω(string(content)).Should(ContainSubstring(`"UPDATE "+r.Table+" SET bottled_on = $1, name = $2 WHERE id = $3"`))
+			_, err = os.Stat(filepath.Join(testPkg.Abs(), "repository", "health.go"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genrepo.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genrepo.NewGenerator(
+				genrepo.API(args.api),
+				genrepo.OutDir(args.outDir),
+				genrepo.AppPkg("myapp"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.AppPkg).Should(Equal("myapp"))
+		})
+	})
+})
@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 )
 
 type (
@@ -47,6 +49,10 @@ type (
 
 		// Union
 		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+		OneOf []*JSONSchema `json:"oneOf,omitempty"`
+
+		// Extensions
+		Nullable bool `json:"x-nullable,omitempty"`
 	}
 
 	// JSONType is the JSON type enum.
@@ -69,6 +75,9 @@ type (
 		TargetSchema *JSONSchema `json:"targetSchema,omitempty"`
 		MediaType    string      `json:"mediaType,omitempty"`
 		EncType      string      `json:"encType,omitempty"`
+
+		// Extensions
+		Params *JSONSchema `json:"x-params,omitempty"`
 	}
 )
 
@@ -181,14 +190,28 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 			requestSchema = TypeSchema(api, a.Payload)
 			requestSchema.Description = a.Name + " payload"
 		}
+		var paramsSchema *JSONSchema
 		if a.Params != nil {
 			params := design.DupAtt(a.Params)
 			// We don't want to keep the path params, these are defined inline in the href
+			skip := make(map[string]bool)
 			for _, r := range a.Routes {
 				for _, p := range r.Params() {
 					delete(params.Type.ToObject(), p)
+					skip[p] = true
 				}
 			}
+			if len(params.Type.ToObject()) > 0 {
+				paramsSchema = buildAttributeSchema(api, NewJSONSchema(), params)
+				var required []string
+				for _, n := range paramsSchema.Required {
+					if !skip[n] {
+						required = append(required, n)
+						skip[n] = true
+					}
+				}
+				paramsSchema.Required = required
+			}
 		}
 		var targetSchema *JSONSchema
 		var identifier string
@@ -219,6 +242,7 @@ func GenerateResourceDefinition(api *design.APIDefinition, r *design.ResourceDef
 				Schema:       requestSchema,
 				TargetSchema: targetSchema,
 				MediaType:    identifier,
+				Params:       paramsSchema,
 			}
 			if i == 0 {
 				if ca := a.Parent.CanonicalAction(); ca != nil {
@@ -274,7 +298,47 @@ func GenerateTypeDefinition(api *design.APIDefinition, ut *design.UserTypeDefini
 	s := NewJSONSchema()
 	s.Title = ut.TypeName
 	Definitions[ut.TypeName] = s
-	buildAttributeSchema(api, s, ut.AttributeDefinition)
+	att := ut.AttributeDefinition
+	if ut.Flags != nil && api.FlagsWireFormat() == design.FlagsArray {
+		// Flags values are encoded as the arrays of the names of the flags that are set
+		names := make([]interface{}, len(ut.Flags))
+		for i, f := range ut.Flags {
+			names[i] = f
+		}
+		att = &design.AttributeDefinition{
+			Type: &design.Array{ElemType: &design.AttributeDefinition{
+				Type:       design.String,
+				Validation: &dslengine.ValidationDefinition{Values: names},
+			}},
+			Description: ut.Description,
+		}
+	}
+	if ut == design.Money && api.MoneyWireFormat() == design.MoneyDecimal {
+		// Amounts are encoded as decimal strings
+		o := design.Object{"amount": &design.AttributeDefinition{
+			Type:        design.String,
+			Description: "Decimal amount",
+			Validation:  &dslengine.ValidationDefinition{Pattern: `^-?[0-9]+(\.[0-9]+)?$`},
+		}}
+		o["currency"] = ut.Type.ToObject()["currency"]
+		att = design.DupAtt(ut.AttributeDefinition)
+		att.Type = o
+	}
+	buildAttributeSchema(api, s, att)
+	for _, m := range ut.OneOf {
+		// Values are values of exactly one of the member types
+		member := NewJSONSchema()
+		member.Ref = TypeRef(api, m)
+		s.OneOf = append(s.OneOf, member)
+	}
+}
+
+// Describe returns the given description followed by the markdown documentation of docs if any.
+func Describe(description string, docs *design.DocsDefinition) string {
+	if docs == nil || docs.Markdown == "" {
+		return description
+	}
+	return strings.TrimSpace(description + "\n\n" + docs.Markdown)
 }
 
 // TypeSchema produces the JSON schema corresponding to the given data type.
@@ -282,14 +346,19 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	s := NewJSONSchema()
 	switch actual := t.(type) {
 	case design.Primitive:
-		if name := actual.Name(); name != "any" {
+		if name := actual.Name(); name != "any" && name != "file" {
 			s.Type = JSONType(actual.Name())
 		}
 		switch actual.Kind() {
+		case design.FileKind:
+			s.Type = JSONString
+			s.Format = "binary"
 		case design.UUIDKind:
 			s.Format = "uuid"
 		case design.DateTimeKind:
 			s.Format = "date-time"
+		case design.DateKind:
+			s.Format = "date"
 		case design.NumberKind:
 			s.Format = "double"
 		case design.IntegerKind:
@@ -304,7 +373,7 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 		for n, at := range actual {
 			prop := NewJSONSchema()
 			buildAttributeSchema(api, prop, at)
-			s.Properties[n] = prop
+			s.Properties[api.WireName(n)] = prop
 		}
 	case *design.Hash:
 		s.Type = JSONObject
@@ -417,6 +486,7 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		MaxLength:            s.MaxLength,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
+		Nullable:             s.Nullable,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -443,9 +513,17 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 		// Ref is exclusive with other fields
 		return s
 	}
-	s.DefaultValue = toStringMap(at.DefaultValue)
-	s.Description = at.Description
-	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
+	s.DefaultValue = wireValue(api, at.Type, toStringMap(at.DefaultValue))
+	s.Description = Describe(at.Description, at.Docs)
+	s.ReadOnly = at.ComputedFrom != nil || at.ReadOnly
+	s.Nullable = at.Nullable
+	if at.Transitions != nil {
+		s.Description = strings.TrimSpace(s.Description + "\n\n" + transitionsDiagram(at.Transitions))
+	}
+	if at.VisibleTo != nil {
+		s.Description = strings.TrimSpace(s.Description + "\n\nOnly visible to callers granted: " + strings.Join(at.VisibleTo, ", ") + ".")
+	}
+	s.Example = wireValue(api, at.Type, at.GenerateExample(api.RandomGenerator(), nil))
 	val := at.Validation
 	if val == nil {
 		return s
@@ -466,9 +544,67 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 		s.MaxLength = val.MaxLength
 	}
 	s.Required = val.Required
+	if api.WireNaming != "" && len(val.Required) > 0 {
+		s.Required = make([]string, len(val.Required))
+		for i, r := range val.Required {
+			s.Required[i] = api.WireName(r)
+		}
+	}
 	return s
 }
 
+// wireValue converts the names of the attributes of the objects contained in val, a value of type
+// t such as an example, into their wire names, see design.APIDefinition.WireName.
+func wireValue(api *design.APIDefinition, t design.DataType, val interface{}) interface{} {
+	if api.WireNaming == "" || val == nil {
+		return val
+	}
+	switch {
+	case t.IsObject():
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return val
+		}
+		o := t.ToObject()
+		res := make(map[string]interface{}, len(m))
+		for n, v := range m {
+			if att, ok := o[n]; ok {
+				v = wireValue(api, att.Type, v)
+			}
+			res[api.WireName(n)] = v
+		}
+		return res
+	case t.IsArray():
+		v := reflect.ValueOf(val)
+		if v.Kind() != reflect.Slice {
+			return val
+		}
+		res := make([]interface{}, v.Len())
+		for i := range res {
+			res[i] = wireValue(api, t.ToArray().ElemType.Type, v.Index(i).Interface())
+		}
+		return res
+	}
+	return val
+}
+
+// transitionsDiagram renders the given state transitions as a Mermaid state diagram.
+func transitionsDiagram(transitions map[string][]string) string {
+	froms := make([]string, 0, len(transitions))
+	for from := range transitions {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+	lines := []string{"Allowed state transitions:", "", "```mermaid", "stateDiagram-v2"}
+	for _, from := range froms {
+		for _, to := range transitions[from] {
+			lines = append(lines, fmt.Sprintf("    %s --> %s", from, to))
+		}
+	}
+	lines = append(lines, "```")
+	return strings.Join(lines, "\n")
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
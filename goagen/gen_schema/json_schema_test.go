@@ -46,6 +46,279 @@ var _ = Describe("TypeSchema", func() {
 		})
 	})
 
+	Context("with a type defining state transitions", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("Order", func() {
+				Attribute("status", design.String, "The order status", func() {
+					Enum("pending", "paid", "shipped")
+					Transition("pending", "paid")
+					Transition("paid", "shipped")
+				})
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Order"]
+		})
+
+		It("renders the state diagram in the attribute description", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Order"]
+			Ω(def).ShouldNot(BeNil())
+			desc := def.Properties["status"].Description
+			Ω(desc).Should(HavePrefix("The order status\n\nAllowed state transitions:"))
+			Ω(desc).Should(ContainSubstring("    paid --> shipped\n    pending --> paid"))
+		})
+	})
+
+	Context("with a type defining a computed attribute", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("User", func() {
+				Attribute("first", design.String)
+				Attribute("last", design.String)
+				Computed("full_name", From("first", "last"))
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["User"]
+		})
+
+		It("marks the computed attribute as read-only", func() {
+			def := genschema.Definitions["User"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["full_name"].ReadOnly).Should(BeTrue())
+			Ω(def.Properties["first"].ReadOnly).Should(BeFalse())
+		})
+	})
+
+	Context("with a type defining a read-only attribute", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("User", func() {
+				Attribute("id", design.Integer, func() {
+					ReadOnly()
+				})
+				Attribute("name", design.String)
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["User"]
+		})
+
+		It("marks the attribute as read-only", func() {
+			def := genschema.Definitions["User"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["id"].ReadOnly).Should(BeTrue())
+			Ω(def.Properties["name"].ReadOnly).Should(BeFalse())
+		})
+	})
+
+	Context("with a type defining an attribute with restricted visibility", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("User", func() {
+				Attribute("name", design.String)
+				Attribute("email", design.String, "User email", func() {
+					VisibleTo("admin", "user:read")
+				})
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["User"]
+		})
+
+		It("documents the visibility constraint", func() {
+			def := genschema.Definitions["User"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["email"].Description).Should(Equal("User email\n\nOnly visible to callers granted: admin, user:read."))
+			Ω(def.Properties["name"].Description).Should(BeEmpty())
+		})
+	})
+
+	Context("with a wire naming convention", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			API("test", func() {
+				WireNaming(design.CamelCase)
+			})
+			Type("User", func() {
+				Attribute("first_name", design.String)
+				Attribute("home_address", func() {
+					Attribute("zip_code", design.String)
+				})
+				Required("first_name")
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["User"]
+		})
+
+		It("uses the wire names", func() {
+			def := genschema.Definitions["User"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties).Should(HaveKey("firstName"))
+			Ω(def.Properties).Should(HaveKey("homeAddress"))
+			Ω(def.Properties["homeAddress"].Properties).Should(HaveKey("zipCode"))
+			Ω(def.Required).Should(Equal([]string{"firstName"}))
+			Ω(def.Properties["homeAddress"].Example).Should(HaveKey("zipCode"))
+		})
+	})
+
+	Context("with date and time attributes", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("Event", func() {
+				Attribute("at", design.DateTime)
+				Attribute("on", design.Date)
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Event"]
+		})
+
+		It("sets the formats", func() {
+			def := genschema.Definitions["Event"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["at"].Format).Should(Equal("date-time"))
+			Ω(def.Properties["on"].Type).Should(BeEquivalentTo("string"))
+			Ω(def.Properties["on"].Format).Should(Equal("date"))
+		})
+	})
+
+	Context("with a flags type", func() {
+		var format design.FlagsFormat
+
+		BeforeEach(func() {
+			format = ""
+		})
+
+		JustBeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			API("test", func() {
+				FlagsEncoding(format)
+			})
+			Flags("Permissions", "read", "write")
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			genschema.GenerateTypeDefinition(design.Design, design.Design.Types["Permissions"])
+		})
+
+		It("describes arrays of flag names", func() {
+			def := genschema.Definitions["Permissions"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Type).Should(BeEquivalentTo("array"))
+			Ω(def.Items.Enum).Should(Equal([]interface{}{"read", "write"}))
+		})
+
+		Context("encoded as integers", func() {
+			BeforeEach(func() {
+				format = design.FlagsInteger
+			})
+
+			It("describes integers", func() {
+				def := genschema.Definitions["Permissions"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.Type).Should(BeEquivalentTo("integer"))
+				Ω(*def.Maximum).Should(Equal(3.0))
+			})
+		})
+	})
+
+	Context("with the money type", func() {
+		var format design.MoneyFormat
+
+		BeforeEach(func() {
+			format = ""
+		})
+
+		JustBeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			API("test", func() {
+				MoneyEncoding(format)
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			genschema.GenerateTypeDefinition(design.Design, design.Money)
+		})
+
+		It("describes amounts in minor units", func() {
+			def := genschema.Definitions["Money"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["amount"].Type).Should(BeEquivalentTo("integer"))
+			Ω(def.Properties["currency"].Format).Should(Equal("currency"))
+			Ω(def.Required).Should(ConsistOf("amount", "currency"))
+		})
+
+		Context("encoded as decimal strings", func() {
+			BeforeEach(func() {
+				format = design.MoneyDecimal
+			})
+
+			It("describes decimal amounts", func() {
+				def := genschema.Definitions["Money"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.Properties["amount"].Type).Should(BeEquivalentTo("string"))
+				Ω(def.Properties["amount"].Pattern).ShouldNot(BeEmpty())
+				Ω(def.Properties["currency"].Format).Should(Equal("currency"))
+			})
+		})
+	})
+
+	Context("with a one of type", func() {
+		JustBeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			member := func(value string) func() {
+				return func() {
+					Attribute("kind", design.String, func() {
+						Enum(value)
+					})
+					Required("kind")
+				}
+			}
+			Type("Cat", member("cat"))
+			Type("Dog", member("dog"))
+			OneOf("Pet", "Cat", "Dog", func() {
+				Discriminator("kind")
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			genschema.GenerateTypeDefinition(design.Design, design.Design.Types["Pet"])
+		})
+
+		It("lists the member types", func() {
+			def := genschema.Definitions["Pet"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.OneOf).Should(HaveLen(2))
+			Ω(def.OneOf[0].Ref).Should(Equal("#/definitions/Cat"))
+			Ω(def.OneOf[1].Ref).Should(Equal("#/definitions/Dog"))
+			Ω(genschema.Definitions).Should(HaveKey("Dog"))
+			Ω(def.Properties["kind"].Enum).Should(Equal([]interface{}{"cat", "dog"}))
+			Ω(def.Required).Should(ConsistOf("kind"))
+		})
+	})
+
+	Context("with a nullable attribute", func() {
+		JustBeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("Patch", func() {
+				Attribute("nickname", design.String, func() {
+					Nullable()
+				})
+				Attribute("age", design.Integer)
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			genschema.GenerateTypeDefinition(design.Design, design.Design.Types["Patch"])
+		})
+
+		It("flags the nullable properties", func() {
+			def := genschema.Definitions["Patch"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["nickname"].Nullable).Should(BeTrue())
+			Ω(def.Properties["age"].Nullable).Should(BeFalse())
+			b, err := def.Properties["nickname"].JSON()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"x-nullable":true`))
+		})
+	})
+
 	Context("with a media type with self-referencing attributes", func() {
 		BeforeEach(func() {
 			MediaType("application/vnd.menu+json", func() {
@@ -79,3 +352,59 @@ var _ = Describe("TypeSchema", func() {
 
 	})
 })
+
+var _ = Describe("GenerateResourceDefinition", func() {
+	var links []*genschema.JSONLink
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET("/accounts/:accountID/bottles"))
+				Params(func() {
+					Param("accountID", design.Integer)
+					Param("year", design.Integer, func() {
+						Minimum(1900)
+					})
+					Param("sort", design.String, func() {
+						Pattern("^(name|year)$")
+					})
+					Required("accountID", "sort")
+				})
+				Response(design.NoContent)
+			})
+			Action("delete", func() {
+				Routing(DELETE("/bottles/:bottleID"))
+				Response(design.NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		genschema.GenerateResourceDefinition(design.Design, design.Design.Resources["bottle"])
+		links = genschema.Definitions["bottle"].Links
+	})
+
+	It("describes the query string params of the actions", func() {
+		Ω(links).Should(HaveLen(2))
+		var list, del *genschema.JSONLink
+		for _, l := range links {
+			switch l.Title {
+			case "list":
+				list = l
+			case "delete":
+				del = l
+			}
+		}
+		Ω(list).ShouldNot(BeNil())
+		Ω(list.Href).Should(Equal("/accounts/{accountID}/bottles"))
+		params := list.Params
+		Ω(params).ShouldNot(BeNil())
+		Ω(params.Properties).Should(HaveLen(2))
+		Ω(params.Properties).Should(HaveKey("year"))
+		Ω(*params.Properties["year"].Minimum).Should(Equal(1900.0))
+		Ω(params.Properties["sort"].Pattern).Should(Equal("^(name|year)$"))
+		Ω(params.Required).Should(Equal([]string{"sort"}))
+		Ω(del).ShouldNot(BeNil())
+		Ω(del.Params).Should(BeNil())
+	})
+})
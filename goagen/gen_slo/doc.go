@@ -0,0 +1,7 @@
+/*
+Package genslo provides a generator for Prometheus recording and alerting rules derived from the
+service level objectives defined in the design using the SLO DSL. The rules are written to
+slo/rules.yml and assume that the service exposes a latency histogram and a request counter both
+labeled with the service, resource and action names.
+*/
+package genslo
@@ -0,0 +1,13 @@
+package genslo_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenSLO(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SLO Generator Suite")
+}
@@ -0,0 +1,201 @@
+package genslo
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a SLO rules Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the SLO rules generator.
+type Generator struct {
+	API            *design.APIDefinition // The API definition
+	OutDir         string                // Path to output directory
+	LatencyMetric  string                // Name of request latency histogram
+	RequestsMetric string                // Name of request counter
+	genfiles       []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, latency, requests, ver string
+	set := flag.NewFlagSet("slo", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&latency, "latency-metric", "", "")
+	set.StringVar(&requests, "requests-metric", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, LatencyMetric: latency, RequestsMetric: requests, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the Prometheus rules file.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.LatencyMetric == "" {
+		g.LatencyMetric = "http_request_duration_seconds"
+	}
+	if g.RequestsMetric == "" {
+		g.RequestsMetric = "http_requests_total"
+	}
+
+	var objectives []*objective
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.SLO != nil {
+				selector := fmt.Sprintf("service=%s,resource=%s,action=%s",
+					strconv.Quote(g.API.Name), strconv.Quote(res.Name), strconv.Quote(a.Name))
+				objectives = append(objectives, &objective{
+					Name:         ruleName(codegen.SnakeCase(res.Name) + "_" + codegen.SnakeCase(a.Name)),
+					AlertName:    codegen.Goify(res.Name, true) + codegen.Goify(a.Name, true),
+					Resource:     res.Name,
+					Action:       a.Name,
+					Selector:     selector,
+					Latency:      a.SLO.LatencyP99.Seconds(),
+					Availability: a.SLO.Availability / 100,
+				})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return
+	}
+
+	g.OutDir = filepath.Join(g.OutDir, "slo")
+	os.RemoveAll(g.OutDir)
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, g.OutDir)
+	rulesFile := filepath.Join(g.OutDir, "rules.yml")
+	f, err := os.Create(rulesFile)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	g.genfiles = append(g.genfiles, rulesFile)
+	data := map[string]interface{}{
+		"Service":        g.API.Name,
+		"Group":          ruleName(strings.Join(strings.Fields(codegen.SnakeCase(g.API.Name)), "_")),
+		"LatencyMetric":  g.LatencyMetric,
+		"RequestsMetric": g.RequestsMetric,
+		"Objectives":     objectives,
+	}
+	if err = rulesTmpl.Execute(f, data); err != nil {
+		return
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// ruleName returns a valid Prometheus rule name built from name by replacing all the characters
+// outside of [a-zA-Z0-9_:] with underscores. Names cannot start with a digit.
+func ruleName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				return ruleName("_" + name)
+			}
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// yamlString returns s as a YAML flow scalar: single quoted unless s contains characters that
+// cannot be represented in single quoted scalars, double quoted otherwise.
+func yamlString(s string) string {
+	for _, c := range s {
+		if c < ' ' || c == 0x7f {
+			b, _ := json.Marshal(s) // JSON strings are valid YAML double quoted scalars
+			return string(b)
+		}
+	}
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// objective contains the data needed to render the rules of a single action.
+type objective struct {
+	Name         string
+	AlertName    string
+	Resource     string
+	Action       string
+	Selector     string // PromQL label matchers of the action metrics
+	Latency      float64
+	Availability float64
+}
+
+var rulesTmpl = template.Must(template.New("rules").Funcs(template.FuncMap{"yaml": yamlString}).Parse(rulesT))
+
+const rulesT = `# Code generated by goagen, DO NOT EDIT.
+# Service level objectives of the {{ yaml .Service }} API.
+groups:
+- name: {{ .Group }}_slo
+  rules:{{ if not .Objectives }} []{{ end }}
+{{- range .Objectives }}
+  - record: {{ $.Group }}:{{ .Name }}:latency_p99
+    expr: {{ yaml (printf "histogram_quantile(0.99, sum(rate(%s_bucket{%s}[5m])) by (le))" $.LatencyMetric .Selector) }}
+  - alert: {{ .AlertName }}LatencySLO
+    expr: {{ $.Group }}:{{ .Name }}:latency_p99 > {{ .Latency }}
+    for: 5m
+    annotations:
+      summary: {{ yaml (printf "99th percentile latency of %s %s is above %vs" .Resource .Action .Latency) }}
+  - record: {{ $.Group }}:{{ .Name }}:availability
+    expr: {{ yaml (printf "1 - (sum(rate(%s{%s,code=~\"5..\"}[5m])) / sum(rate(%s{%s}[5m])))" $.RequestsMetric .Selector $.RequestsMetric .Selector) }}
+  - alert: {{ .AlertName }}AvailabilitySLO
+    expr: {{ $.Group }}:{{ .Name }}:availability < {{ .Availability }}
+    for: 5m
+    annotations:
+      summary: {{ yaml (printf "availability of %s %s is below %v" .Resource .Action .Availability) }}
+{{- end }}
+`
@@ -0,0 +1,168 @@
+package genslo_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_slo"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("slotest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genslo.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with an action defining a SLO", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Title("dummy API")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.SLO("250ms", 99.5)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the rules", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(2))
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "slo", "rules.yml"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("- record: test_api:bottle_show:latency_p99"))
+			Ω(string(content)).Should(ContainSubstring("expr: test_api:bottle_show:latency_p99 > 0.25"))
+			Ω(string(content)).Should(ContainSubstring("expr: test_api:bottle_show:availability < 0.995"))
+			Ω(string(content)).Should(ContainSubstring(`http_requests_total{service="test api",resource="bottle",action="show",code=~"5.."}`))
+			Ω(string(content)).ShouldNot(ContainSubstring("bottle_list"))
+		})
+	})
+
+	Context("with an API name containing invalid rule name characters", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("cellar-api.v2", func() {
+				apidsl.Title("dummy API")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.SLO("250ms", 99.5)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("sanitizes the rule and group names", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "slo", "rules.yml"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(MatchRegexp(`- name: [a-zA-Z0-9_:]+_slo\n`))
+			Ω(string(content)).Should(MatchRegexp(`- record: [a-zA-Z0-9_:]+:bottle_show:latency_p99\n`))
+			Ω(string(content)).Should(ContainSubstring(`service="cellar-api.v2"`))
+		})
+	})
+
+	Context("with names that require quoting in YAML", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API(`cellar: "prod" #1's`, func() {
+				apidsl.Title("dummy API")
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.SLO("250ms", 99.5)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+
+		It("generates a valid rules file", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(testPkg.Abs(), "slo", "rules.yml"))
+			Ω(err).ShouldNot(HaveOccurred())
+			var rules struct {
+				Groups []struct {
+					Rules []struct {
+						Expr        string
+						Annotations map[string]string
+					}
+				}
+			}
+			Ω(yaml.Unmarshal(content, &rules)).Should(Succeed())
+			Ω(rules.Groups).Should(HaveLen(1))
+			Ω(rules.Groups[0].Rules).Should(HaveLen(4))
+			Ω(rules.Groups[0].Rules[0].Expr).Should(ContainSubstring(`service="cellar: \"prod\" #1's"`))
+			Ω(rules.Groups[0].Rules[1].Annotations["summary"]).Should(Equal("99th percentile latency of bottle show is above 0.25s"))
+		})
+	})
+})
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genslo.Generator
+
+	var args = struct {
+		api    *design.APIDefinition
+		outDir string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir: "out_dir",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genslo.NewGenerator(
+				genslo.API(args.api),
+				genslo.OutDir(args.outDir),
+				genslo.LatencyMetric("latency"),
+				genslo.RequestsMetric("requests"),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.LatencyMetric).Should(Equal("latency"))
+			Ω(generator.RequestsMetric).Should(Equal("requests"))
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package genslo
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//LatencyMetric Name of the request latency histogram
+func LatencyMetric(name string) Option {
+	return func(g *Generator) {
+		g.LatencyMetric = name
+	}
+}
+
+//RequestsMetric Name of the request counter
+func RequestsMetric(name string) Option {
+	return func(g *Generator) {
+		g.RequestsMetric = name
+	}
+}
@@ -5,5 +5,9 @@ This JSON swagger can be used to generate API documentation, ruby and Go API cli
 See the blog post (https://blog.heroku.com/archives/2014/1/8/json_swagger_for_heroku_platform_api)
 describing how Heroku leverages the JSON Hyper-swagger standard (http://json-swagger.org/latest/json-swagger-hypermedia.html)
 for more information.
+
+The generator also produces a "swagger" Go package that compiles the specifications into the
+service binary. The package Mount function serves them from memory with pre-compressed content,
+strong ETags and cache headers so that specification endpoints add negligible overhead.
 */
 package genswagger
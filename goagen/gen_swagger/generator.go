@@ -10,6 +10,7 @@ import (
 
 	"gopkg.in/yaml.v2"
 
+	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/utils"
@@ -78,6 +79,11 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
+	fingerprint := g.API.Fingerprint()
+	if s.Info.Extensions == nil {
+		s.Info.Extensions = make(map[string]interface{})
+	}
+	s.Info.Extensions[goa.FingerprintExtension] = fingerprint
 
 	swaggerDir := filepath.Join(g.OutDir, "swagger")
 	os.RemoveAll(swaggerDir)
@@ -113,9 +119,62 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
 
+	// Go package embedding the specifications
+	if err = g.generateContent(swaggerDir, fingerprint, rawJSON, rawYAML); err != nil {
+		return nil, err
+	}
+
 	return g.genfiles, nil
 }
 
+// generateContent writes the Go package that compiles the specifications into the service binary
+// so they can be served from memory with pre-compressed content and strong ETags. The package also
+// exposes the design fingerprint so that services can check that it matches the application.
+func (g *Generator) generateContent(swaggerDir, fingerprint string, rawJSON, rawYAML []byte) (err error) {
+	contentFile := filepath.Join(swaggerDir, "swagger.go")
+	file, err := codegen.SourceFileFor(contentFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Swagger specification", g.API.Context())
+	imports := []*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa")}
+	if err = file.WriteHeader(title, "swagger", imports); err != nil {
+		return err
+	}
+	data := map[string]string{"Fingerprint": fingerprint, "JSON": string(rawJSON), "YAML": string(rawYAML)}
+	if err = file.ExecuteTemplate("content", contentT, nil, data); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, contentFile)
+	return nil
+}
+
+// contentT generates the Go package embedding the specifications.
+// template input: map[string]string
+const contentT = `// DesignFingerprint is the fingerprint of the design the specifications were generated from.
+const DesignFingerprint = {{ printf "%q" .Fingerprint }}
+
+var (
+	// JSON is the Swagger specification in JSON format.
+	JSON = goa.NewStaticContent("swagger.json", "application/json", []byte({{ printf "%q" .JSON }}))
+
+	// YAML is the Swagger specification in YAML format.
+	YAML = goa.NewStaticContent("swagger.yaml", "application/x-yaml", []byte({{ printf "%q" .YAML }}))
+)
+
+// Mount serves the Swagger specifications under /swagger.json and /swagger.yaml.
+func Mount(service *goa.Service) {
+	service.ServeContent("/swagger.json", JSON)
+	service.ServeContent("/swagger.yaml", YAML)
+}
+`
+
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
 func (g *Generator) Cleanup() {
 	for _, f := range g.genfiles {
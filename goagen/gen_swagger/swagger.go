@@ -391,7 +391,7 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 		Swagger: "2.0",
 		Info: &Info{
 			Title:          api.Title,
-			Description:    api.Description,
+			Description:    genschema.Describe(api.Description, api.Docs),
 			TermsOfService: api.TermsOfService,
 			Contact:        api.Contact,
 			License:        api.License,
@@ -409,6 +409,18 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 		ExternalDocs:        docsFromDefinition(api.Docs),
 		SecurityDefinitions: securityDefsFromDefinition(api.SecuritySchemes),
 	}
+	if api.RateLimit != nil {
+		if s.Info.Extensions == nil {
+			s.Info.Extensions = make(map[string]interface{})
+		}
+		s.Info.Extensions["x-ratelimit"] = rateLimitExtension(api.RateLimit)
+	}
+	if len(api.Versions) > 0 {
+		if s.Info.Extensions == nil {
+			s.Info.Extensions = make(map[string]interface{})
+		}
+		s.Info.Extensions["x-versions"] = versionsExtension(api)
+	}
 
 	err = api.IterateResponses(func(r *design.ResponseDefinition) error {
 		res, err := responseSpecFromDefinition(s, api, r)
@@ -458,6 +470,7 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			// sad but swagger doesn't support these
 			d.Media = nil
 			d.Links = nil
+			d.OneOf = nil
 			s.Definitions[n] = d
 		}
 	}
@@ -624,6 +637,12 @@ func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) s
 	return name
 }
 
+// rateLimitExtension returns the value of the x-ratelimit extension that documents the given rate
+// limit.
+func rateLimitExtension(rl *design.RateLimitDefinition) map[string]interface{} {
+	return map[string]interface{}{"requests": rl.Requests, "period": rl.Period.String()}
+}
+
 func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
 	extensions := make(map[string]interface{})
 	for key, value := range mdata {
@@ -659,8 +678,7 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 	if obj == nil {
 		return nil, fmt.Errorf("invalid parameters definition, not an object")
 	}
-	res := make([]*Parameter, len(obj))
-	i := 0
+	var res []*Parameter
 	wildcards := design.ExtractWildcards(path)
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
 		in := "query"
@@ -672,14 +690,73 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 				break
 			}
 		}
-		param := paramFor(at, n, in, required)
-		res[i] = param
-		i++
+		if in == "query" && at.IsDeepObject() {
+			res = append(res, deepObjectParams(at, n, required)...)
+			return nil
+		}
+		res = append(res, paramFor(at, n, in, required))
 		return nil
 	})
 	return res, nil
 }
 
+// routeParams returns the params of the action that apply to the given route: the params captured
+// by an optional path segment are omitted from the routes that do not include the segment.
+func routeParams(action *design.ActionDefinition, route *design.RouteDefinition) *design.AttributeDefinition {
+	params := action.AllParams()
+	optional := action.OptionalPathParams()
+	if len(optional) == 0 {
+		return params
+	}
+	params.Type = design.Dup(params.Type)
+	wcs := route.Params()
+	for _, n := range optional {
+		found := false
+		for _, wc := range wcs {
+			if wc == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			delete(params.Type.ToObject(), n)
+		}
+	}
+	return params
+}
+
+// formDataParams returns the form parameters that describe the attributes of the given multipart
+// payload.
+func formDataParams(payload *design.UserTypeDefinition) []*Parameter {
+	var res []*Parameter
+	payload.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		name := n
+		if fn := at.Metadata["form:name"]; len(fn) > 0 && fn[0] != "" {
+			name = fn[0]
+		}
+		res = append(res, paramFor(at, name, "formData", payload.IsRequired(n)))
+		return nil
+	})
+	return res
+}
+
+// deepObjectParams returns the query string parameters that encode the properties of the given
+// deep object parameter using the "name[property]" notation.
+func deepObjectParams(at *design.AttributeDefinition, name string, required bool) []*Parameter {
+	var res []*Parameter
+	ut := at.Type.(*design.UserTypeDefinition)
+	ut.Type.ToObject().IterateAttributes(func(n string, pat *design.AttributeDefinition) error {
+		p := paramFor(pat, fmt.Sprintf("%s[%s]", name, n), "query", required && ut.IsRequired(n))
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions["x-style"] = "deepObject"
+		res = append(res, p)
+		return nil
+	})
+	return res
+}
+
 func paramsFromHeaders(action *design.ActionDefinition) []*Parameter {
 	params := []*Parameter{}
 	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
@@ -695,19 +772,35 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 		In:          in,
 		Name:        name,
 		Default:     toStringMap(at.DefaultValue),
-		Description: at.Description,
+		Description: genschema.Describe(at.Description, at.Docs),
 		Required:    required,
 		Type:        at.Type.Name(),
+		Format:      primitiveFormat(at.Type),
 	}
 	if at.Type.IsArray() {
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
-		p.CollectionFormat = "multi"
+		p.CollectionFormat = design.CollectionFormatMulti
+		if at.CollectionFormat != "" {
+			p.CollectionFormat = at.CollectionFormat
+		}
 	}
 	p.Extensions = extensionsFromDefinition(at.Metadata)
 	initValidations(at, p)
 	return p
 }
 
+// primitiveFormat returns the format of the values of the date and time primitive types, the
+// empty string for other types. The Format validation overrides it.
+func primitiveFormat(t design.DataType) string {
+	switch t.Kind() {
+	case design.DateTimeKind:
+		return "date-time"
+	case design.DateKind:
+		return "date"
+	}
+	return ""
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
@@ -745,7 +838,7 @@ func toString(val interface{}) string {
 }
 
 func itemsFromDefinition(at *design.AttributeDefinition) *Items {
-	items := &Items{Type: at.Type.Name()}
+	items := &Items{Type: at.Type.Name(), Format: primitiveFormat(at.Type)}
 	initValidations(at, items)
 	if at.Type.IsArray() {
 		items.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
@@ -769,11 +862,35 @@ func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design
 	if err != nil {
 		return nil, err
 	}
+	if r.Filename != "" {
+		schema = genschema.NewJSONSchema()
+		schema.Type = genschema.JSONType("file")
+		if headers == nil {
+			headers = make(map[string]*Header)
+		}
+		headers["Content-Disposition"] = &Header{
+			Description: fmt.Sprintf("Attachment disposition with file name %q", r.Filename),
+			Type:        "string",
+		}
+	}
+	extensions := extensionsFromDefinition(r.Metadata)
+	if r.Streaming {
+		// The schema describes the data of the events.
+		if extensions == nil {
+			extensions = make(map[string]interface{})
+		}
+		extensions["x-event-stream"] = true
+		if r.Deltas {
+			// The events data are delta envelopes whose "data" field is described by the
+			// schema.
+			extensions["x-deltas"] = true
+		}
+	}
 	return &Response{
 		Description: r.Description,
 		Schema:      schema,
 		Headers:     headers,
-		Extensions:  extensionsFromDefinition(r.Metadata),
+		Extensions:  extensions,
 	}, nil
 }
 
@@ -813,7 +930,7 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
 		header := &Header{
 			Default:     at.DefaultValue,
-			Description: at.Description,
+			Description: genschema.Describe(at.Description, at.Docs),
 			Type:        at.Type.Name(),
 		}
 		initValidations(at, header)
@@ -851,7 +968,7 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 	schemes := api.Schemes
 
 	operation := &Operation{
-		Description:  fs.Description,
+		Description:  genschema.Describe(fs.Description, fs.Docs),
 		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
 		ExternalDocs: docsFromDefinition(fs.Docs),
 		OperationID:  operationID,
@@ -892,7 +1009,7 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		// By default tag with resource name
 		tagNames = []string{route.Parent.Parent.Name}
 	}
-	params, err := paramsFromDefinition(action.AllParams(), route.FullPath())
+	params, err := paramsFromDefinition(routeParams(action, route), route.FullPath())
 	if err != nil {
 		return err
 	}
@@ -908,12 +1025,14 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		responses[strconv.Itoa(r.Status)] = resp
 	}
 
-	if action.Payload != nil {
+	if action.Payload != nil && action.PayloadMultipart {
+		params = append(params, formDataParams(action.Payload)...)
+	} else if action.Payload != nil {
 		payloadSchema := genschema.TypeSchema(api, action.Payload)
 		pp := &Parameter{
 			Name:        "payload",
 			In:          "body",
-			Description: action.Payload.Description,
+			Description: genschema.Describe(action.Payload.Description, action.Payload.Docs),
 			Required:    !action.PayloadOptional,
 			Schema:      payloadSchema,
 		}
@@ -937,40 +1056,96 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		schemes = api.Schemes
 	}
 
+	deprecated, _ := action.Deprecation()
 	operation := &Operation{
 		Tags:         tagNames,
-		Description:  action.Description,
+		Description:  genschema.Describe(action.Description, action.Docs),
 		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
 		ExternalDocs: docsFromDefinition(action.Docs),
 		OperationID:  operationID,
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
-		Deprecated:   false,
+		Deprecated:   deprecated,
 		Extensions:   extensionsFromDefinition(route.Metadata),
 	}
 
+	if action.PayloadMultipart {
+		operation.Consumes = []string{"multipart/form-data"}
+	}
 	computeProduces(operation, s, action)
 	applySecurity(operation, action.Security)
 
-	key := design.WildcardRegex.ReplaceAllStringFunc(
-		route.FullPath(),
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
-	)
-	bp := design.WildcardRegex.ReplaceAllStringFunc(
-		basePath,
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
-	)
-	if bp != "/" {
-		key = strings.TrimPrefix(key, bp)
+	key := pathKey(route.FullPath(), basePath)
+	if len(route.Aliases) > 0 {
+		aliases := make([]map[string]string, len(route.Aliases))
+		for i, al := range route.Aliases {
+			aliases[i] = map[string]string{"path": pathKey(al.FullPath(), basePath), "policy": string(al.Policy)}
+		}
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions["x-aliases"] = aliases
+	}
+	if len(action.Enrichments) > 0 {
+		enrichments := make([]map[string]string, len(action.Enrichments))
+		for i, e := range action.Enrichments {
+			enrichments[i] = map[string]string{"name": e.Name, "param": e.Param}
+			if e.Description != "" {
+				enrichments[i]["description"] = e.Description
+			}
+		}
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions["x-enrichments"] = enrichments
 	}
-	if key == "" {
-		key = "/"
+	if c := action.Concurrency; c != nil {
+		concurrency := map[string]interface{}{"max_in_flight": c.MaxInFlight}
+		if c.QueueSize > 0 {
+			concurrency["queue_size"] = c.QueueSize
+		}
+		if c.QueueTimeout > 0 {
+			concurrency["queue_timeout"] = c.QueueTimeout.String()
+		}
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions["x-concurrency"] = concurrency
+	}
+	if rl := action.EffectiveRateLimit(); rl != nil {
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions["x-ratelimit"] = rateLimitExtension(rl)
 	}
+	if len(api.Versions) > 0 {
+		var versions []string
+		api.IterateVersions(func(v *design.VersionDefinition) error {
+			if action.Parent.SupportsVersion(v.Name) {
+				versions = append(versions, v.Name)
+			}
+			return nil
+		})
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions["x-versions"] = versions
+	}
+	addOperation(s, key, route, operation)
+	return api.IterateVersions(func(v *design.VersionDefinition) error {
+		if v.Scheme != design.VersionPath || !action.Parent.SupportsVersion(v.Name) {
+			return nil
+		}
+		versioned := *operation
+		versioned.OperationID = fmt.Sprintf("%s@%s", operationID, v.Name)
+		addOperation(s, pathKey(v.RoutePath(route.FullPath()), basePath), route, &versioned)
+		return nil
+	})
+}
+
+// addOperation sets the operation of the route verb on the path with the given key.
+func addOperation(s *Swagger, key string, route *design.RouteDefinition, operation *Operation) {
 	var path interface{}
 	var ok bool
 	if path, ok = s.Paths[key]; !ok {
@@ -995,17 +1170,59 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		p.Patch = operation
 	}
 	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
-	return nil
+}
+
+// versionsExtension returns the "x-versions" extension describing the API versions and how
+// requests select them.
+func versionsExtension(api *design.APIDefinition) []map[string]string {
+	var versions []map[string]string
+	api.IterateVersions(func(v *design.VersionDefinition) error {
+		version := map[string]string{"name": v.Name, "scheme": string(v.Scheme), "key": v.Key}
+		if v.Description != "" {
+			version["description"] = v.Description
+		}
+		versions = append(versions, version)
+		return nil
+	})
+	return versions
+}
+
+// pathKey returns the swagger path for the given route path relative to the given base path.
+func pathKey(path, basePath string) string {
+	key := design.WildcardRegex.ReplaceAllStringFunc(
+		path,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	bp := design.WildcardRegex.ReplaceAllStringFunc(
+		basePath,
+		func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		},
+	)
+	if bp != "/" {
+		key = strings.TrimPrefix(key, bp)
+	}
+	if key == "" {
+		key = "/"
+	}
+	return key
 }
 
 func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
-		if resp.MediaType != "" {
+		if resp.Streaming {
+			produces["text/event-stream"] = struct{}{}
+		} else if resp.MediaType != "" {
 			produces[resp.MediaType] = struct{}{}
 		}
 		return nil
 	})
+	if action.RenderHTML {
+		produces["text/html"] = struct{}{}
+	}
 	subset := true
 	for p := range produces {
 		found := false
@@ -1059,7 +1276,7 @@ func scopesList(scopes []string) string {
 }
 
 func docsFromDefinition(docs *design.DocsDefinition) *ExternalDocs {
-	if docs == nil {
+	if docs == nil || docs.Description == "" && docs.URL == "" {
 		return nil
 	}
 	return &ExternalDocs{
@@ -1165,7 +1382,9 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 		return
 	}
 	initEnumValidation(def, val.Values)
-	initFormatValidation(def, val.Format)
+	if val.Format != "" {
+		initFormatValidation(def, val.Format)
+	}
 	initPatternValidation(def, val.Pattern)
 	if val.Minimum != nil {
 		initMinimumValidation(def, val.Minimum)
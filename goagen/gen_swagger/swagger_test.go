@@ -3,6 +3,7 @@ package genswagger_test
 import (
 	"bytes"
 	"encoding/json"
+	"time"
 
 	"github.com/go-openapi/loads"
 	_ "github.com/goadesign/goa-cellar/design"
@@ -685,4 +686,506 @@ var _ = Describe("New", func() {
 
 		})
 	})
+
+	Context("with route aliases", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				BasePath("/base")
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/:id", func() {
+						Alias("/legacy/:id", AliasRedirect)
+						Alias("/v0", AliasGone)
+					}))
+					Params(func() {
+						Param("id")
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("lists the aliases in the operation extensions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/{id}"].(*genswagger.Path)
+			Ω(p.Get.Extensions["x-aliases"]).Should(Equal([]map[string]string{
+				{"path": "/legacy/{id}", "policy": "redirect"},
+				{"path": "/v0", "policy": "gone"},
+			}))
+		})
+	})
+
+	Context("with API versions", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				BasePath("/base")
+				Version("v1", func() {
+					Description("Initial version")
+					Header("X-Api-Version")
+				})
+				Version("v2", func() {
+					BasePath("/v2")
+				})
+			})
+			Resource("res", func() {
+				Version("v2")
+				Action("act", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id")
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("documents the versions and adds the paths of the versions selected with a prefix", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Info.Extensions).Should(HaveKeyWithValue("x-versions", []map[string]string{
+				{"name": "v1", "scheme": "header", "key": "X-Api-Version", "description": "Initial version"},
+				{"name": "v2", "scheme": "path", "key": "/v2"},
+			}))
+			p := swagger.Paths["/{id}"].(*genswagger.Path)
+			Ω(p.Get.OperationID).Should(Equal("res#act"))
+			Ω(p.Get.Extensions["x-versions"]).Should(Equal([]string{"v2"}))
+			Ω(swagger.Paths).Should(HaveKey("/v2/{id}"))
+			vp := swagger.Paths["/v2/{id}"].(*genswagger.Path)
+			Ω(vp.Get.OperationID).Should(Equal("res#act@v2"))
+		})
+	})
+
+	Context("with enrichments", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+						Param("ownerID", Integer)
+					})
+					Enrich("owner", "ownerID", func() {
+						Description("The owner account")
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("lists the derived values in the operation extensions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/{id}"].(*genswagger.Path)
+			Ω(p.Get.Extensions["x-enrichments"]).Should(Equal([]map[string]string{
+				{"name": "owner", "param": "ownerID", "description": "The owner account"},
+			}))
+		})
+	})
+
+	Context("with a deep object param", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			filter := Type("Filter", func() {
+				Attribute("status", String)
+				Attribute("tags", ArrayOf(String))
+				Required("status")
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+					Params(func() {
+						Param("filter", filter)
+						Required("filter")
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("lists one query string param per property using the deepObject style", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			params := swagger.Paths["/"].(*genswagger.Path).Get.Parameters
+			Ω(params).Should(HaveLen(2))
+			Ω(params[0].Name).Should(Equal("filter[status]"))
+			Ω(params[0].In).Should(Equal("query"))
+			Ω(params[0].Type).Should(Equal("string"))
+			Ω(params[0].Required).Should(BeTrue())
+			Ω(params[0].Extensions["x-style"]).Should(Equal("deepObject"))
+			Ω(params[1].Name).Should(Equal("filter[tags]"))
+			Ω(params[1].Type).Should(Equal("array"))
+			Ω(params[1].Required).Should(BeFalse())
+		})
+	})
+
+	Context("with markdown docs", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Description("Lists bottles")
+					Docs("Bottles are listed **by vintage**.")
+					Routing(GET("/"))
+					Params(func() {
+						Param("vintage", Integer, "Vintage year", func() {
+							Docs("Defaults to the *current* year.")
+						})
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("appends the markdown to the descriptions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			op := swagger.Paths["/"].(*genswagger.Path).Get
+			Ω(op.Description).Should(Equal("Lists bottles\n\nBottles are listed **by vintage**."))
+			Ω(op.ExternalDocs).Should(BeNil())
+			Ω(op.Parameters).Should(HaveLen(1))
+			Ω(op.Parameters[0].Description).Should(Equal("Vintage year\n\nDefaults to the *current* year."))
+		})
+	})
+
+	Context("with an action rendering HTML", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			mt := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+					RenderHTML()
+					Response(OK, mt)
+				})
+			})
+		})
+
+		It("lists text/html in the operation produces", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/"].(*genswagger.Path)
+			Ω(p.Get.Produces).Should(Equal([]string{"application/vnd.bottle", "text/html"}))
+		})
+	})
+
+	Context("with a file download response", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+						Required("id")
+					})
+					Response(OK, func() {
+						Media("application/pdf")
+						Download("invoice-{id}.pdf")
+					})
+				})
+			})
+		})
+
+		It("documents a file response with a Content-Disposition header", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/{id}"].(*genswagger.Path)
+			Ω(p.Get.Produces).Should(Equal([]string{"application/pdf"}))
+			resp := p.Get.Responses["200"]
+			Ω(resp.Schema).ShouldNot(BeNil())
+			Ω(resp.Schema.Type).Should(BeEquivalentTo("file"))
+			Ω(resp.Headers).Should(HaveKey("Content-Disposition"))
+			Ω(resp.Headers["Content-Disposition"].Type).Should(Equal("string"))
+		})
+	})
+
+	Context("with a streaming response", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			mt := MediaType("application/vnd.event", func() {
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("res", func() {
+				Action("watch", func() {
+					Routing(GET("/"))
+					StreamingResponse(mt)
+				})
+			})
+		})
+
+		It("documents an event stream of the media type", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/"].(*genswagger.Path)
+			Ω(p.Get.Produces).Should(Equal([]string{"text/event-stream"}))
+			resp := p.Get.Responses["200"]
+			Ω(resp.Schema).ShouldNot(BeNil())
+			Ω(resp.Schema.Ref).Should(Equal("#/definitions/Event"))
+			Ω(resp.Extensions).Should(HaveKeyWithValue("x-event-stream", true))
+		})
+	})
+
+	Context("with a subscription", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			mt := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("res", func() {
+				Action("list", func() {
+					Routing(GET("/"))
+					Response(OK, CollectionOf(mt))
+					Subscription("watch", func() {
+						Routing(GET("/changes"))
+					})
+				})
+			})
+		})
+
+		It("documents a delta stream of the collection elements", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/changes"].(*genswagger.Path)
+			Ω(p.Get.Produces).Should(Equal([]string{"text/event-stream"}))
+			resp := p.Get.Responses["200"]
+			Ω(resp.Schema.Ref).Should(Equal("#/definitions/Bottle"))
+			Ω(resp.Extensions).Should(HaveKeyWithValue("x-event-stream", true))
+			Ω(resp.Extensions).Should(HaveKeyWithValue("x-deltas", true))
+		})
+	})
+
+	Context("with a multipart form payload", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(POST("/"))
+					Payload(func() {
+						Attribute("title", String)
+						Attribute("document", File)
+						Required("document")
+					})
+					MultipartForm()
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("documents the payload attributes as form parameters", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/"].(*genswagger.Path)
+			Ω(p.Post.Consumes).Should(Equal([]string{"multipart/form-data"}))
+			Ω(p.Post.Parameters).Should(HaveLen(2))
+			Ω(p.Post.Parameters[0].Name).Should(Equal("document"))
+			Ω(p.Post.Parameters[0].In).Should(Equal("formData"))
+			Ω(p.Post.Parameters[0].Type).Should(Equal("file"))
+			Ω(p.Post.Parameters[0].Required).Should(BeTrue())
+			Ω(p.Post.Parameters[1].Name).Should(Equal("title"))
+			Ω(p.Post.Parameters[1].Type).Should(Equal("string"))
+		})
+	})
+
+	Context("with rate limits", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				RateLimit(1000, time.Minute)
+			})
+			Resource("res", func() {
+				RateLimit(100, time.Minute)
+				Action("act", func() {
+					Routing(POST("/"))
+					RateLimit(5, time.Hour)
+				})
+				Action("show", func() {
+					Routing(GET("/"))
+				})
+			})
+		})
+
+		It("documents the limits in the info and operation extensions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Info.Extensions).Should(HaveKeyWithValue("x-ratelimit", map[string]interface{}{
+				"requests": 1000, "period": "1m0s",
+			}))
+			p := swagger.Paths["/"].(*genswagger.Path)
+			Ω(p.Post.Extensions).Should(HaveKeyWithValue("x-ratelimit", map[string]interface{}{
+				"requests": 5, "period": "1h0m0s",
+			}))
+			Ω(p.Get.Extensions).Should(HaveKeyWithValue("x-ratelimit", map[string]interface{}{
+				"requests": 100, "period": "1m0s",
+			}))
+		})
+	})
+
+	Context("with an action with a maximum concurrency", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(POST("/"))
+					MaxConcurrency(4)
+					ConcurrencyQueue(20, "10s")
+				})
+			})
+		})
+
+		It("documents the limit in the operation extensions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/"].(*genswagger.Path)
+			Ω(p.Post.Extensions).Should(HaveKeyWithValue("x-concurrency", map[string]interface{}{
+				"max_in_flight": 4,
+				"queue_size":    20,
+				"queue_timeout": "10s",
+			}))
+		})
+	})
+
+	Context("with a deprecated action", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Metadata("deprecated", "2027-01-31")
+					Routing(GET("/"))
+				})
+			})
+		})
+
+		It("flags the operation as deprecated", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/"].(*genswagger.Path)
+			Ω(p.Get.Deprecated).Should(BeTrue())
+		})
+	})
+
+	Context("with a redirect response", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/v1/users/:id"))
+					Params(func() {
+						Param("id", Integer)
+						Required("id")
+					})
+					Redirect("/v2/users/:id", 302)
+				})
+			})
+		})
+
+		It("documents the Location header", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/v1/users/{id}"].(*genswagger.Path)
+			Ω(p.Get.Responses).Should(HaveKey("302"))
+			Ω(p.Get.Responses["302"].Headers).Should(HaveKey("Location"))
+		})
+	})
+
+	Context("with an optional trailing path segment", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("items", func() {
+				Action("show", func() {
+					Routing(GET("/items/:id(/versions/:version)?"))
+					Params(func() {
+						Param("id", Integer)
+						Param("version", Integer)
+						Required("id")
+					})
+				})
+			})
+		})
+
+		It("documents the segment params on the route including the segment only", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			short := swagger.Paths["/items/{id}"].(*genswagger.Path)
+			Ω(short.Get.Parameters).Should(HaveLen(1))
+			Ω(short.Get.Parameters[0].Name).Should(Equal("id"))
+			full := swagger.Paths["/items/{id}/versions/{version}"].(*genswagger.Path)
+			Ω(full.Get.Parameters).Should(HaveLen(2))
+			Ω(full.Get.Parameters[1].Name).Should(Equal("version"))
+			Ω(full.Get.Parameters[1].In).Should(Equal("path"))
+		})
+	})
+
+	Context("with an array param using the pipes collection format", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			Resource("items", func() {
+				Action("list", func() {
+					Routing(GET("/items"))
+					Params(func() {
+						Param("tags", ArrayOf(String), func() {
+							CollectionFormat("pipes")
+						})
+					})
+				})
+			})
+		})
+
+		It("sets the collection format of the parameter", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/items"].(*genswagger.Path)
+			Ω(p.Get.Parameters).Should(HaveLen(1))
+			Ω(p.Get.Parameters[0].CollectionFormat).Should(Equal("pipes"))
+		})
+	})
+
+	Context("with a response declaring embeddable relations", func() {
+		BeforeEach(func() {
+			API("test", nil)
+			account := MediaType("application/vnd.account+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+			bottle := MediaType("application/vnd.bottle+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("account", account)
+				})
+				Embeddable("account")
+				View("default", func() {
+					Attribute("id")
+					Attribute("account")
+				})
+			})
+			Resource("bottles", func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Response(OK, bottle)
+				})
+			})
+		})
+
+		It("documents the expand parameter", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			p := swagger.Paths["/bottles/{id}"].(*genswagger.Path)
+			var expand *genswagger.Parameter
+			for _, param := range p.Get.Parameters {
+				if param.Name == "expand" {
+					expand = param
+				}
+			}
+			Ω(expand).ShouldNot(BeNil())
+			Ω(expand.In).Should(Equal("query"))
+			Ω(expand.Type).Should(Equal("array"))
+			Ω(expand.CollectionFormat).Should(Equal("csv"))
+			Ω(expand.Items.Enum).Should(Equal([]interface{}{"account"}))
+		})
+	})
 })
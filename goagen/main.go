@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/meta"
+	"github.com/goadesign/goa/goagen/plan"
 	"github.com/goadesign/goa/goagen/utils"
+	"github.com/goadesign/goa/goagen/workspace"
 	"github.com/goadesign/goa/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -48,6 +51,7 @@ package and tool and the Swagger specification for the API.
 	rootCmd.PersistentFlags().StringP("out", "o", ".", "output directory")
 	rootCmd.PersistentFlags().StringVarP(&designPkg, "design", "d", "", "design package import path")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode, does not cleanup temporary files.")
+	rootCmd.PersistentFlags().String("env", "", "name of the design `overlay` merged into the design before generating")
 
 	// versionCmd implements the "version" command
 	versionCmd := &cobra.Command{
@@ -135,6 +139,158 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(schemaCmd)
 
+	// sloCmd implements the "slo" command.
+	var latencyMetric, requestsMetric string
+	sloCmd := &cobra.Command{
+		Use:   "slo",
+		Short: "Generate Prometheus rules for the service level objectives",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genslo", c) },
+	}
+	sloCmd.Flags().StringVar(&latencyMetric, "latency-metric", "http_request_duration_seconds", "name of the request latency histogram `metric`")
+	sloCmd.Flags().StringVar(&requestsMetric, "requests-metric", "http_requests_total", "name of the request counter `metric`")
+	rootCmd.AddCommand(sloCmd)
+
+	// loadCmd implements the "load" command.
+	var (
+		loadTool, loadHost, loadDuration string
+		loadRate                         int
+	)
+	loadCmd := &cobra.Command{
+		Use:   "load",
+		Short: "Generate k6 or vegeta load test scenarios",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genload", c) },
+	}
+	loadCmd.Flags().StringVar(&loadTool, "tool", "k6", "load testing `tool`, one of \"k6\" or \"vegeta\"")
+	loadCmd.Flags().StringVar(&loadHost, "host", "", "base `URL` of the service under test, defaults to the API scheme and host")
+	loadCmd.Flags().IntVar(&loadRate, "rate", 10, "number of requests per second")
+	loadCmd.Flags().StringVar(&loadDuration, "duration", "1m", "`duration` of the test")
+	rootCmd.AddCommand(loadCmd)
+
+	// outboxCmd implements the "outbox" command.
+	var outboxTable string
+	outboxCmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Generate transactional outbox for the events emitted by actions",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genoutbox", c) },
+	}
+	outboxCmd.Flags().StringVar(&outboxTable, "table", "outbox", "name of the outbox database `table`")
+	rootCmd.AddCommand(outboxCmd)
+
+	// gatewayCmd implements the "gateway" command.
+	var upstream, authURL string
+	gatewayCmd := &cobra.Command{
+		Use:   "gateway",
+		Short: "Generate Kong, Envoy and Traefik gateway configurations",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gengateway", c) },
+	}
+	gatewayCmd.Flags().StringVar(&upstream, "upstream", "http://localhost:8080", "`URL` of the service the gateways forward requests to")
+	gatewayCmd.Flags().StringVar(&authURL, "auth-url", "", "`URL` of the authentication service used by Traefik to authenticate requests")
+	rootCmd.AddCommand(gatewayCmd)
+
+	// meshCmd implements the "mesh" command.
+	var meshService, meshNamespace string
+	meshCmd := &cobra.Command{
+		Use:   "mesh",
+		Short: "Generate Istio and Linkerd timeout, retry and circuit breaking policies",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genmesh", c) },
+	}
+	meshCmd.Flags().StringVar(&meshService, "service", "", "name of the Kubernetes `service`, defaults to the API name")
+	meshCmd.Flags().StringVar(&meshNamespace, "namespace", "default", "Kubernetes `namespace` of the service")
+	rootCmd.AddCommand(meshCmd)
+
+	// pactCmd implements the "pact" command.
+	var consumer string
+	pactCmd := &cobra.Command{
+		Use:   "pact",
+		Short: "Generate consumer-driven contracts in the Pact format",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genpact", c) },
+	}
+	pactCmd.Flags().StringVar(&consumer, "consumer", "", "name of the pact `consumer`, defaults to the API name followed by \"-client\"")
+	rootCmd.AddCommand(pactCmd)
+
+	// docsCmd implements the "docs" command.
+	docsCmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate documentation site with code samples for the generated clients",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gendocs", c) },
+	}
+	docsCmd.Flags().StringVar(&pkg, "pkg", "client", "Name of generated client Go package")
+	docsCmd.Flags().StringVar(&tool, "tool", "[API-name]-cli", "Name of generated tool")
+	rootCmd.AddCommand(docsCmd)
+
+	// changelogCmd implements the "changelog" command.
+	var release, date string
+	changelogCmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate the changelog section of a release by comparing the design to the previous release",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genchangelog", c) },
+	}
+	changelogCmd.Flags().StringVar(&release, "release", "Unreleased", "name of the `release`, e.g. v1.2.0")
+	changelogCmd.Flags().StringVar(&date, "date", "", "`date` of the release using the YYYY-MM-DD format, defaults to today")
+	rootCmd.AddCommand(changelogCmd)
+
+	// registryCmd implements the "registry" command.
+	var (
+		registryURL, kind, compatibility, prefix, format string
+		dryRun                                           bool
+	)
+	registryCmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Publish the JSON or protobuf schemas of the design types to a schema registry",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genregistry", c) },
+	}
+	registryCmd.Flags().StringVar(&registryURL, "registry", "", "`URL` of the schema registry")
+	registryCmd.Flags().StringVar(&kind, "kind", "confluent", "kind of registry, one of \"confluent\" or \"http\"")
+	registryCmd.Flags().StringVar(&compatibility, "compatibility", "BACKWARD", "compatibility `level` enforced on new schema versions, one of NONE, BACKWARD, FORWARD or FULL")
+	registryCmd.Flags().StringVar(&prefix, "prefix", "", "`prefix` of the subject names, defaults to the API name")
+	registryCmd.Flags().StringVar(&format, "format", "json", "`format` of the published schemas, one of \"json\" or \"protobuf\"")
+	registryCmd.Flags().BoolVar(&dryRun, "dry-run", false, "check the compatibility of the schemas without publishing them")
+	rootCmd.AddCommand(registryCmd)
+
+	// debugProxyCmd implements the "debug-proxy" command.
+	var target, addr, record string
+	debugProxyCmd := &cobra.Command{
+		Use:   "debug-proxy",
+		Short: "Run a proxy that decodes and validates the requests and responses of a service",
+		Long: `The debug-proxy command runs a reverse proxy in front of a service that prints the requests
+and responses it forwards decoded using the design: the action handling the request, the typed
+values of its params, headers and payload, the response media type and negotiated view and the
+result of validating both against the design. The proxy runs until interrupted.`,
+		Annotations: map[string]string{"interactive": "true"},
+		Run:         func(c *cobra.Command, _ []string) { files, err = run("genproxy", c) },
+	}
+	debugProxyCmd.Flags().StringVar(&target, "target", "http://localhost:8080", "`URL` of the service the requests are forwarded to")
+	debugProxyCmd.Flags().StringVar(&addr, "addr", ":8081", "`address` the proxy listens on")
+	debugProxyCmd.Flags().StringVar(&record, "record", "", "`file` the exchanges are recorded to as JSON lines")
+	rootCmd.AddCommand(debugProxyCmd)
+
+	// coverageCmd implements the "coverage" command.
+	var accessLogs, records string
+	coverageCmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Report the parts of the design exercised by the traffic of a service",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("gencoverage", c) },
+	}
+	coverageCmd.Flags().StringVar(&accessLogs, "log", "", "comma separated list of access log `files` written by the LogRequest middleware")
+	coverageCmd.Flags().StringVar(&records, "records", "", "comma separated list of record `files` written by the debug-proxy command")
+	rootCmd.AddCommand(coverageCmd)
+
+	// workspaceCmd implements the "workspace" command.
+	var root string
+	workspaceCmd := &cobra.Command{
+		Use:   "workspace [commands]",
+		Short: "Generate the artifacts of all the services of a workspace",
+		Long: `The workspace command discovers the design packages under the root directory, validates
+the references between the services declared with the Uses DSL and runs the given commands
+(defaults to "app") on each service in dependency order. The artifacts of each service are
+generated in a sub-directory of the output directory named after the service. Media types that
+are defined identically by multiple services are generated once and aliased by the other services.
+The dependency graph is written to workspace.json and workspace.dot in the output directory.`,
+		Run: func(c *cobra.Command, args []string) { files, err = runWorkspace(c, args) },
+	}
+	workspaceCmd.Flags().StringVar(&root, "root", ".", "root `directory` of the workspace")
+	rootCmd.AddCommand(workspaceCmd)
+
 	// genCmd implements the "gen" command.
 	var (
 		pkgPath string
@@ -151,38 +307,98 @@ package and tool and the Swagger specification for the API.
 	rootCmd.AddCommand(genCmd)
 
 	// boostrapCmd implements the "bootstrap" command.
+	var profile string
 	bootCmd := &cobra.Command{
 		Use:   "bootstrap",
 		Short: `Equivalent to running the "app", "main", "client" and "swagger" commands.`,
-		Run: func(c *cobra.Command, a []string) {
-			appCmd.Run(c, a)
-			if err != nil {
-				return
-			}
-			prev := files
+		Long: `The bootstrap command runs the commands selected by the generation profile:
 
-			mainCmd.Run(c, a)
-			if err != nil {
-				return
-			}
-			prev = append(prev, files...)
+    full     "app", "main", "client" and "swagger" (default)
+    server   "app", "main" and "swagger"
+    client   "client"
+    minimal  "app" and "main", without test helpers
 
-			clientCmd.Run(c, a)
-			if err != nil {
+The server and minimal profiles leave out the code that a service binary does not need. Services
+deployed in constrained environments (embedded devices, serverless functions) may further reduce
+the size of their binaries by building with the goa_nometrics tag which replaces the metrics
+support of the goa package with no-ops.`,
+		Run: func(c *cobra.Command, a []string) {
+			var cmds []*cobra.Command
+			switch profile {
+			case "full":
+				cmds = []*cobra.Command{appCmd, mainCmd, clientCmd, swaggerCmd}
+			case "server":
+				cmds = []*cobra.Command{appCmd, mainCmd, swaggerCmd}
+			case "client":
+				cmds = []*cobra.Command{clientCmd}
+			case "minimal":
+				cmds = []*cobra.Command{appCmd, mainCmd}
+				if err = c.Flags().Set("notest", "true"); err != nil {
+					return
+				}
+			default:
+				err = fmt.Errorf("unknown profile %q, must be one of full, server, client or minimal", profile)
 				return
 			}
-			prev = append(prev, files...)
-
-			swaggerCmd.Run(c, a)
-			files = append(prev, files...)
+			var prev []string
+			for _, cmd := range cmds {
+				cmd.Run(c, a)
+				prev = append(prev, files...)
+				if err != nil {
+					break
+				}
+			}
+			files = prev
 		},
 	}
 	bootCmd.Flags().AddFlagSet(appCmd.Flags())
 	bootCmd.Flags().AddFlagSet(mainCmd.Flags())
 	bootCmd.Flags().AddFlagSet(clientCmd.Flags())
 	bootCmd.Flags().AddFlagSet(swaggerCmd.Flags())
+	bootCmd.Flags().StringVar(&profile, "profile", "full", "generation `profile`, one of \"full\", \"server\", \"client\" or \"minimal\"")
 	rootCmd.AddCommand(bootCmd)
 
+	// planCmd implements the "plan" command.
+	planCmd := &cobra.Command{
+		Use:   "plan [command]",
+		Short: "Summarize what a generation command would create, update and delete",
+		Long: `The plan command runs the given generation command (one of "app", "main", "client",
+"swagger" or "bootstrap", defaults to "bootstrap") on a copy of the output directory and lists
+the files it would create, update and delete. The changes made to Go files are detailed at the
+symbol level (functions, types, variables, constants and mounted routes). The output directory
+is left untouched.`,
+		Run: func(c *cobra.Command, args []string) {
+			cmd := bootCmd
+			if len(args) > 0 {
+				switch args[0] {
+				case "app":
+					cmd = appCmd
+				case "main":
+					cmd = mainCmd
+				case "client":
+					cmd = clientCmd
+				case "swagger":
+					cmd = swaggerCmd
+				case "bootstrap":
+				default:
+					err = fmt.Errorf("cannot plan command %q, must be one of app, main, client, swagger or bootstrap", args[0])
+					return
+				}
+			}
+			var p *plan.Plan
+			p, err = runPlan(c, func() error {
+				cmd.Run(c, nil)
+				return err
+			})
+			files = nil // nothing is written to the output directory
+			if err == nil {
+				err = p.Render(os.Stdout)
+			}
+		},
+	}
+	planCmd.Flags().AddFlagSet(bootCmd.Flags())
+	rootCmd.AddCommand(planCmd)
+
 	// controllerCmd implements the "controller" command.
 	var (
 		res, appPkg string
@@ -199,6 +415,46 @@ package and tool and the Swagger specification for the API.
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
 	rootCmd.AddCommand(controllerCmd)
 
+	// repositoryCmd implements the "repository" command.
+	repositoryCmd := &cobra.Command{
+		Use:   "repository",
+		Short: "Generate storage adapters for the resources",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genrepo", c) },
+	}
+	repositoryCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	rootCmd.AddCommand(repositoryCmd)
+
+	// migrationCmd implements the "migration" command.
+	migrationCmd := &cobra.Command{
+		Use:   "migration",
+		Short: "Generate SQL migrations for the persisted types",
+		Long: `The migration command generates the SQL migration creating the tables that store the types
+defining the "sql:table" metadata. Once the initial migration exists the command warns about the
+changes made to the persisted types and writes hints for the migration of these changes.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genmigration", c) },
+	}
+	rootCmd.AddCommand(migrationCmd)
+
+	// hexagonalCmd implements the "hexagonal" command.
+	hexagonalCmd := &cobra.Command{
+		Use:   "hexagonal",
+		Short: "Generate a hexagonal architecture service skeleton",
+		Long: `The hexagonal command runs the "app" command and lays out the domain types, the application
+service interfaces and stubs, the controller adapters and the main function of the service.`,
+		Run: func(c *cobra.Command, a []string) {
+			appCmd.Run(c, a)
+			if err != nil {
+				return
+			}
+			prev := files
+			files, err = run("genhexagonal", c)
+			files = append(prev, files...)
+		},
+	}
+	hexagonalCmd.Flags().AddFlagSet(appCmd.Flags())
+	hexagonalCmd.Flags().BoolVar(&force, "force", false, "overwrite existing scaffolding files")
+	rootCmd.AddCommand(hexagonalCmd)
+
 	// cmdsCmd implements the commands command
 	// It lists all the commands and flags in JSON to enable shell integrations.
 	cmdsCmd := &cobra.Command{
@@ -274,7 +530,7 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
-		if f.Name != "pkg-path" {
+		if f.Name != "pkg-path" && f.Name != "profile" {
 			m[f.Name] = f.Value.String()
 		}
 	})
@@ -297,9 +553,80 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	if err != nil {
 		return nil, err
 	}
+	gen.Interactive = c.Annotations["interactive"] == "true"
 	return gen.Generate()
 }
 
+// runPlan runs generate on a copy of the output directory and compares the result with the
+// output directory.
+func runPlan(c *cobra.Command, generate func() error) (*plan.Plan, error) {
+	out, err := filepath.Abs(c.Flag("out").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	// Generate next to the output directory so that the copy lives in the same GOPATH.
+	tmp, err := ioutil.TempDir(filepath.Dir(out), ".goagen-plan")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	if err := plan.Copy(out, tmp); err != nil {
+		return nil, err
+	}
+	if err := c.Flags().Set("out", tmp); err != nil {
+		return nil, err
+	}
+	defer c.Flags().Set("out", out)
+	if err := generate(); err != nil {
+		return nil, err
+	}
+	// Account for the import paths and absolute paths written by the generators.
+	replace := []string{tmp, out}
+	if tmpPkg, err := codegen.PackagePath(tmp); err == nil {
+		if outPkg, err := codegen.PackagePath(out); err == nil {
+			replace = append([]string{tmpPkg, outPkg}, replace...)
+		}
+	}
+	return plan.Compare(out, tmp, replace...)
+}
+
+func runWorkspace(c *cobra.Command, commands []string) ([]string, error) {
+	if len(commands) == 0 {
+		commands = []string{"app"}
+	}
+	out, err := filepath.Abs(c.Flag("out").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]string)
+	c.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "debug" || f.Name == "env" {
+			flags[f.Name] = f.Value.String()
+		}
+	})
+	pkgs, err := workspace.Discover(c.Flag("root").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no design package found under %s", c.Flag("root").Value.String())
+	}
+	manifests, err := workspace.Load(pkgs, flags)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := workspace.NewGraph(manifests)
+	if err != nil {
+		return nil, err
+	}
+	files, err := graph.Write(out)
+	if err != nil {
+		return nil, err
+	}
+	genfiles, err := graph.Generate(out, commands, flags)
+	return append(files, genfiles...), err
+}
+
 type (
 	rootCommand struct {
 		Name     string     `json:"name"`
@@ -43,6 +43,23 @@ type Generator struct {
 	// DesignPkgPath is the Go import path to the design package.
 	DesignPkgPath string
 
+	// Env is the name of the design overlay applied before running the generator if any.
+	Env string
+
+	// MediaTypePkgs lists the import paths of the Go packages that define the types of
+	// the design media types indexed by media type identifier, see
+	// design.APIDefinition.ImportMediaTypes.
+	MediaTypePkgs map[string]string
+
+	// ClientPkgs lists the import paths of the client packages of the services used by the
+	// API indexed by API name, see design.APIDefinition.ImportClients.
+	ClientPkgs map[string]string
+
+	// Interactive connects the standard input and outputs of the generator process to the
+	// ones of goagen instead of collecting the generated filenames from its output. It is
+	// used by the commands that run until interrupted such as "debug-proxy".
+	Interactive bool
+
 	debug bool
 }
 
@@ -50,8 +67,8 @@ type Generator struct {
 // given its factory method and command line flags.
 func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[string]string, customflags []string) (*Generator, error) {
 	var (
-		outDir, designPkgPath string
-		debug                 bool
+		outDir, designPkgPath, env string
+		debug                      bool
 	)
 
 	if o, ok := flags["out"]; ok {
@@ -60,6 +77,9 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 	if d, ok := flags["design"]; ok {
 		designPkgPath = d
 	}
+	if e, ok := flags["env"]; ok {
+		env = e
+	}
 	if d, ok := flags["debug"]; ok {
 		var err error
 		debug, err = strconv.ParseBool(d)
@@ -75,6 +95,7 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 		CustomFlags:   customflags,
 		OutDir:        outDir,
 		DesignPkgPath: designPkgPath,
+		Env:           env,
 		debug:         debug,
 	}, nil
 }
@@ -158,6 +179,9 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 		codegen.SimpleImport("github.com/goadesign/goa/dslengine"),
 		codegen.NewImport("_", filepath.ToSlash(m.DesignPkgPath)),
 	)
+	if m.Env != "" || len(m.MediaTypePkgs) > 0 || len(m.ClientPkgs) > 0 {
+		imports = append(imports, codegen.SimpleImport("github.com/goadesign/goa/design"))
+	}
 	file.WriteHeader("Code Generator", "main", imports)
 	tmpl, err := template.New("generator").Parse(mainTmpl)
 	if err != nil {
@@ -167,10 +191,13 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 	if err != nil {
 		panic(err)
 	}
-	context := map[string]string{
+	context := map[string]interface{}{
 		"Genfunc":       m.Genfunc,
 		"DesignPackage": m.DesignPkgPath,
 		"PkgName":       pkgName,
+		"Env":           m.Env,
+		"MediaTypePkgs": m.MediaTypePkgs,
+		"ClientPkgs":    m.ClientPkgs,
 	}
 	if err := tmpl.Execute(file, context); err != nil {
 		panic(err) // bug
@@ -182,7 +209,7 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 func (m *Generator) spawn(genbin string) ([]string, error) {
 	var args []string
 	for k, v := range m.Flags {
-		if k == "debug" {
+		if k == "debug" || k == "env" {
 			continue
 		}
 		args = append(args, fmt.Sprintf("--%s=%s", k, v))
@@ -191,6 +218,10 @@ func (m *Generator) spawn(genbin string) ([]string, error) {
 	args = append(args, "--version="+version.String())
 	args = append(args, m.CustomFlags...)
 	cmd := exec.Command(genbin, args...)
+	if m.Interactive {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return nil, cmd.Run()
+	}
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("%s\n%s", err, string(out))
@@ -209,7 +240,20 @@ func main() {
 
 	// Now run the secondary DSLs
 	dslengine.FailOnError(dslengine.Run())
-
+{{ if .Env }}
+	// Merge the environment overlay into the design
+	dslengine.FailOnError(design.Design.ApplyOverlay({{ printf "%q" .Env }}))
+{{ end }}{{ if .MediaTypePkgs }}
+	// Reuse the media types generated in other packages
+	dslengine.FailOnError(design.Design.ImportMediaTypes(map[string]string{
+{{ range $id, $pkg := .MediaTypePkgs }}		{{ printf "%q" $id }}: {{ printf "%q" $pkg }},
+{{ end }}	}))
+{{ end }}{{ if .ClientPkgs }}
+	// Inject the clients of the services used by the API
+	dslengine.FailOnError(design.Design.ImportClients(map[string]string{
+{{ range $name, $pkg := .ClientPkgs }}		{{ printf "%q" $name }}: {{ printf "%q" $pkg }},
+{{ end }}	}))
+{{ end }}
 	files, err := {{.Genfunc}}()
 	dslengine.FailOnError(err)
 
@@ -0,0 +1,21 @@
+/*
+Package plan implements the goagen plan mode which summarizes what a generation command would
+create, update and delete without touching the output directory.
+
+The output directory is copied to a temporary directory, the command generates its artifacts in
+the copy and the two trees are compared. Comparing with a copy rather than an empty directory
+makes the plan exact: the scaffolding that the generators leave alone when it already exists is
+not reported and the generated files that the generators remove are reported as deleted.
+
+The changes made to Go files are detailed at the symbol level: the plan lists the functions,
+methods, types, variables, constants and routes mounted on the service mux that are added or
+removed by the generation:
+
+	~ app/controllers.go
+	    + func MountBottleController
+	    + route GET /bottles/:bottleID
+	- app/old_types.go
+
+	Plan: 0 to create, 1 to update, 1 to delete, 12 unchanged.
+*/
+package plan
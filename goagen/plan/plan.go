@@ -0,0 +1,335 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Plan lists the changes a generation command makes to the output directory.
+	Plan struct {
+		// Changes lists the created, updated and deleted files sorted by path.
+		Changes []*Change
+		// Unchanged is the number of files left unchanged.
+		Unchanged int
+	}
+
+	// Change describes the change made to a file.
+	Change struct {
+		// Action is the change action.
+		Action Action
+		// Path is the path of the file relative to the output directory.
+		Path string
+		// Added lists the symbols added to the file if it is a Go file.
+		Added []string
+		// Removed lists the symbols removed from the file if it is a Go file.
+		Removed []string
+	}
+
+	// Action is the kind of change made to a file.
+	Action string
+)
+
+const (
+	// Create indicates a file that does not exist yet.
+	Create Action = "create"
+	// Update indicates a file whose content changes.
+	Update Action = "update"
+	// Delete indicates a file that is removed.
+	Delete Action = "delete"
+)
+
+// Copy copies the content of the src directory to the dst directory. The hidden directories and
+// the vendor directories are skipped as generation never writes to them. Copy does nothing if
+// src does not exist.
+func Copy(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			if path != src && skipped(info.Name()) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, info.Mode()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, content, info.Mode())
+	})
+}
+
+// Compare returns the plan that turns the current directory into the generated directory. The
+// optional replace argument lists old and new string pairs replaced in the generated content
+// before it is compared, it makes it possible to account for the import path of the generated
+// directory.
+func Compare(current, generated string, replace ...string) (*Plan, error) {
+	cur, err := list(current)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := list(generated)
+	if err != nil {
+		return nil, err
+	}
+	replacer := strings.NewReplacer(replace...)
+	p := new(Plan)
+	for _, rel := range sortedPaths(gen) {
+		content, err := ioutil.ReadFile(filepath.Join(generated, rel))
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(replacer.Replace(string(content)))
+		if !cur[rel] {
+			p.Changes = append(p.Changes, change(Create, rel, nil, content))
+			continue
+		}
+		prev, err := ioutil.ReadFile(filepath.Join(current, rel))
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(prev, content) {
+			p.Unchanged++
+			continue
+		}
+		p.Changes = append(p.Changes, change(Update, rel, prev, content))
+	}
+	for _, rel := range sortedPaths(cur) {
+		if gen[rel] {
+			continue
+		}
+		prev, err := ioutil.ReadFile(filepath.Join(current, rel))
+		if err != nil {
+			return nil, err
+		}
+		p.Changes = append(p.Changes, change(Delete, rel, prev, nil))
+	}
+	sort.Slice(p.Changes, func(i, j int) bool { return p.Changes[i].Path < p.Changes[j].Path })
+	return p, nil
+}
+
+// IsEmpty returns true if the plan does not change any file.
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the given action.
+func (p *Plan) Count(action Action) int {
+	var count int
+	for _, c := range p.Changes {
+		if c.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Render writes the human readable summary of the plan to w.
+func (p *Plan) Render(w io.Writer) error {
+	if p.IsEmpty() {
+		_, err := fmt.Fprintf(w, "No changes, the %d generated files are up-to-date.\n", p.Unchanged)
+		return err
+	}
+	var b bytes.Buffer
+	b.WriteString("goagen will perform the following actions:\n\n")
+	for _, c := range p.Changes {
+		fmt.Fprintf(&b, "  %s %s\n", sign(c.Action), filepath.ToSlash(c.Path))
+		for _, s := range c.Added {
+			fmt.Fprintf(&b, "      + %s\n", s)
+		}
+		for _, s := range c.Removed {
+			fmt.Fprintf(&b, "      - %s\n", s)
+		}
+	}
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete, %d unchanged.\n",
+		p.Count(Create), p.Count(Update), p.Count(Delete), p.Unchanged)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// change builds the change made to the file with the given previous and next content.
+func change(action Action, rel string, prev, next []byte) *Change {
+	c := &Change{Action: action, Path: rel}
+	if filepath.Ext(rel) != ".go" {
+		return c
+	}
+	before, after := symbols(prev), symbols(next)
+	for _, s := range sortedPaths(after) {
+		if !before[s] {
+			c.Added = append(c.Added, s)
+		}
+	}
+	for _, s := range sortedPaths(before) {
+		if !after[s] {
+			c.Removed = append(c.Removed, s)
+		}
+	}
+	return c
+}
+
+// symbols returns the top level symbols declared by the given Go source and the routes it
+// mounts on the service mux. It returns no symbol if the source cannot be parsed.
+func symbols(src []byte) map[string]bool {
+	syms := make(map[string]bool)
+	if src == nil {
+		return syms
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return syms
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiver(d.Recv.List[0].Type) + "." + name
+			}
+			syms["func "+name] = true
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					syms["type "+s.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.Name != "_" {
+							syms[d.Tok.String()+" "+n.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if route, ok := route(n); ok {
+			syms["route "+route] = true
+		}
+		return true
+	})
+	return syms
+}
+
+// route returns the verb and path of the route if n mounts a route on the service mux, e.g.
+// service.Mux.Handle("GET", "/bottles", h).
+func route(n ast.Node) (string, bool) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok || len(call.Args) != 3 {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Handle" {
+		return "", false
+	}
+	if mux, ok := sel.X.(*ast.SelectorExpr); !ok || mux.Sel.Name != "Mux" {
+		return "", false
+	}
+	verb, ok := stringLit(call.Args[0])
+	if !ok {
+		return "", false
+	}
+	path, ok := stringLit(call.Args[1])
+	if !ok {
+		return "", false
+	}
+	return verb + " " + path, true
+}
+
+// stringLit returns the value of e if it is a string literal.
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// receiver returns the name of the type of a method receiver.
+func receiver(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return receiver(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return "?"
+}
+
+// list returns the paths of the files contained in dir relative to dir.
+func list(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && skipped(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = true
+		return nil
+	})
+	return files, err
+}
+
+// skipped returns true if the directory with the given name is not copied nor compared.
+func skipped(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor"
+}
+
+// sign returns the sign used to render the given action.
+func sign(action Action) string {
+	switch action {
+	case Create:
+		return "+"
+	case Delete:
+		return "-"
+	}
+	return "~"
+}
+
+// sortedPaths returns the sorted keys of m.
+func sortedPaths(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,159 @@
+package plan_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/plan"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Plan", func() {
+	var current, generated string
+
+	write := func(dir, rel, content string) {
+		path := filepath.Join(dir, rel)
+		Ω(os.MkdirAll(filepath.Dir(path), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(path, []byte(content), 0644)).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		current, err = ioutil.TempDir("", "current")
+		Ω(err).ShouldNot(HaveOccurred())
+		generated, err = ioutil.TempDir("", "generated")
+		Ω(err).ShouldNot(HaveOccurred())
+		write(current, "main.go", "package main\n\nfunc main() {}\n")
+		write(current, "app/controllers.go", controllersV1)
+		write(current, "app/old.go", "package app\n\ntype Old struct{}\n")
+		write(current, "swagger/swagger.json", `{"swagger":"2.0"}`)
+		write(current, ".git/HEAD", "ref: refs/heads/master\n")
+		write(current, "vendor/github.com/foo/foo.go", "package foo\n")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(current)
+		os.RemoveAll(generated)
+	})
+
+	Describe("Copy", func() {
+		It("copies the files except the hidden and vendor directories", func() {
+			Ω(plan.Copy(current, generated)).Should(Succeed())
+			p, err := plan.Compare(current, generated)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.IsEmpty()).Should(BeTrue())
+			Ω(p.Unchanged).Should(Equal(4))
+			_, err = os.Stat(filepath.Join(generated, ".git"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+			_, err = os.Stat(filepath.Join(generated, "vendor"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+		})
+
+		It("does nothing if the source does not exist", func() {
+			Ω(plan.Copy(filepath.Join(current, "missing"), generated)).Should(Succeed())
+		})
+	})
+
+	Describe("Compare", func() {
+		BeforeEach(func() {
+			Ω(plan.Copy(current, generated)).Should(Succeed())
+			write(generated, "app/controllers.go", controllersV2)
+			Ω(os.Remove(filepath.Join(generated, "app", "old.go"))).Should(Succeed())
+			write(generated, "app/new.go", "package app\n\n// import \"tmp/app\"\nconst Version = 2\n")
+			write(generated, "swagger/swagger.json", `{"swagger":"2.0","paths":{}}`)
+		})
+
+		It("lists the created, updated and deleted files and symbols", func() {
+			p, err := plan.Compare(current, generated, "tmp/app", "acme/app")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.Unchanged).Should(Equal(1))
+			Ω(p.Changes).Should(Equal([]*plan.Change{
+				{Action: plan.Update, Path: filepath.Join("app", "controllers.go"),
+					Added:   []string{"func BottleController.Delete", "route DELETE /bottles/:id", "type DeleteBottleContext"},
+					Removed: []string{"var defaultPageSize"}},
+				{Action: plan.Create, Path: filepath.Join("app", "new.go"), Added: []string{"const Version"}},
+				{Action: plan.Delete, Path: filepath.Join("app", "old.go"), Removed: []string{"type Old"}},
+				{Action: plan.Update, Path: filepath.Join("swagger", "swagger.json")},
+			}))
+		})
+
+		It("renders the plan", func() {
+			p, err := plan.Compare(current, generated)
+			Ω(err).ShouldNot(HaveOccurred())
+			var b bytes.Buffer
+			Ω(p.Render(&b)).Should(Succeed())
+			Ω(b.String()).Should(Equal(rendered))
+		})
+
+		It("applies the replacements to the generated content", func() {
+			write(current, "app/new.go", "package app\n\n// import \"acme/app\"\nconst Version = 2\n")
+			p, err := plan.Compare(current, generated, "tmp/app", "acme/app")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(p.Count(plan.Create)).Should(Equal(0))
+			Ω(p.Unchanged).Should(Equal(2))
+		})
+	})
+
+	Context("with no change", func() {
+		It("says so", func() {
+			Ω(plan.Copy(current, generated)).Should(Succeed())
+			p, err := plan.Compare(current, generated)
+			Ω(err).ShouldNot(HaveOccurred())
+			var b bytes.Buffer
+			Ω(p.Render(&b)).Should(Succeed())
+			Ω(b.String()).Should(Equal("No changes, the 4 generated files are up-to-date.\n"))
+		})
+	})
+})
+
+const controllersV1 = `package app
+
+var defaultPageSize = 20
+
+type BottleController interface {
+	Show(*ShowBottleContext) error
+}
+
+type ShowBottleContext struct{}
+
+func MountBottleController(service *goa.Service, ctrl BottleController) {
+	service.Mux.Handle("GET", "/bottles/:id", ctrl.MuxHandler("show", h, nil))
+}
+`
+
+const controllersV2 = `package app
+
+type BottleController interface {
+	Show(*ShowBottleContext) error
+}
+
+type ShowBottleContext struct{}
+
+type DeleteBottleContext struct{}
+
+func (c BottleController) Delete() {}
+
+func MountBottleController(service *goa.Service, ctrl BottleController) {
+	service.Mux.Handle("GET", "/bottles/:id", ctrl.MuxHandler("show", h, nil))
+	service.Mux.Handle("DELETE", "/bottles/:id", ctrl.MuxHandler("delete", h, nil))
+}
+`
+
+const rendered = `goagen will perform the following actions:
+
+  ~ app/controllers.go
+      + func BottleController.Delete
+      + route DELETE /bottles/:id
+      + type DeleteBottleContext
+      - var defaultPageSize
+  + app/new.go
+      + const Version
+  - app/old.go
+      - type Old
+  ~ swagger/swagger.json
+
+Plan: 1 to create, 2 to update, 1 to delete, 1 unchanged.
+`
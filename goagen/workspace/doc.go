@@ -0,0 +1,22 @@
+/*
+Package workspace implements the goagen workspace mode which generates the artifacts of all the
+services of a monorepo in one go.
+
+The services are discovered by looking for the design packages under a root directory: a design
+package is a Go package that calls the API DSL. The designs are loaded one at a time (goa
+supports a single API definition per process) to build a manifest of each service which lists the
+resources and media types it defines and the services it calls as declared with the Uses DSL.
+
+The manifests are used to validate the cross-service references: the services used must exist in
+the workspace and define the resources listed in Uses, and media types defined by both a service
+and a service it uses must be identical. They are also used to deduplicate the media types that
+are defined identically by multiple services: the types are generated in the application package
+of the first service in dependency order and the other services alias them. When the clients
+are generated as well the controllers of a service get a field holding the client of each service
+it uses, the clients are created and injected by the generated main.
+
+The services are generated in dependency order in a sub-directory of the output directory named
+after the service. The dependency graph is written to workspace.json and workspace.dot (in the
+Graphviz DOT format) at the root of the output directory.
+*/
+package workspace
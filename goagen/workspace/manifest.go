@@ -0,0 +1,143 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// Manifest describes the service defined by a design package.
+	Manifest struct {
+		// Name is the API name.
+		Name string `json:"name"`
+		// DesignPkg is the import path of the design package.
+		DesignPkg string `json:"design"`
+		// Uses lists the resources of the other services called by the service indexed by
+		// API name.
+		Uses map[string][]string `json:"uses,omitempty"`
+		// Resources lists the names of the API resources.
+		Resources []string `json:"resources"`
+		// MediaTypes lists the media types generated for the API.
+		MediaTypes []*MediaTypeManifest `json:"mediaTypes"`
+	}
+
+	// MediaTypeManifest describes a media type.
+	MediaTypeManifest struct {
+		// Identifier is the media type identifier.
+		Identifier string `json:"identifier"`
+		// TypeName is the name of the generated Go type.
+		TypeName string `json:"typeName"`
+		// Digest is the digest of the Go types generated for the media type views, media
+		// types with the same identifier and digest generate the same code.
+		Digest string `json:"digest"`
+		// Refs lists the identifiers of the media types referenced by the media type
+		// attributes.
+		Refs []string `json:"refs,omitempty"`
+		// Local is true if the media type attributes use user types, such media types
+		// cannot be shared with other services.
+		Local bool `json:"local,omitempty"`
+	}
+)
+
+// GenerateManifest is the generator entry point called by the meta generator. It writes the
+// manifest of the design to manifest.json in the output directory.
+func GenerateManifest() ([]string, error) {
+	var outDir, designPkg, ver string
+	set := flag.NewFlagSet("manifest", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&designPkg, "design", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	m, err := NewManifest(design.Design)
+	if err != nil {
+		return nil, err
+	}
+	m.DesignPkg = designPkg
+	js, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(outDir, "manifest.json")
+	if err := ioutil.WriteFile(path, js, 0644); err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
+
+// NewManifest builds the manifest of the given API.
+func NewManifest(api *design.APIDefinition) (*Manifest, error) {
+	m := &Manifest{Name: api.Name, Uses: api.Uses, Resources: []string{}, MediaTypes: []*MediaTypeManifest{}}
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		m.Resources = append(m.Resources, r.Name)
+		return nil
+	})
+	err := api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || !(mt.Type.IsObject() || mt.Type.IsArray()) {
+			return nil
+		}
+		h := sha256.New()
+		err := mt.IterateViews(func(v *design.ViewDefinition) error {
+			p, _, err := mt.Project(v.Name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "%s\n%s\n", v.Name, codegen.GoTypeDef(p, 0, true, false))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		mm := &MediaTypeManifest{
+			Identifier: mt.Identifier,
+			TypeName:   codegen.GoTypeName(mt, nil, 0, false),
+			Digest:     hex.EncodeToString(h.Sum(nil)),
+		}
+		refs := make(map[string]bool)
+		mm.Local = collectRefs(mt.AttributeDefinition, refs)
+		delete(refs, mt.Identifier)
+		for id := range refs {
+			mm.Refs = append(mm.Refs, id)
+		}
+		sort.Strings(mm.Refs)
+		m.MediaTypes = append(m.MediaTypes, mm)
+		return nil
+	})
+	return m, err
+}
+
+// collectRefs adds the identifiers of the media types used by the given attribute to refs. It
+// returns true if the attribute uses user types.
+func collectRefs(att *design.AttributeDefinition, refs map[string]bool) bool {
+	local := false
+	switch actual := att.Type.(type) {
+	case *design.MediaTypeDefinition:
+		refs[actual.Identifier] = true
+	case *design.UserTypeDefinition:
+		local = true
+	case design.Object:
+		for _, a := range actual {
+			local = collectRefs(a, refs) || local
+		}
+	case *design.Array:
+		local = collectRefs(actual.ElemType, refs)
+	case *design.Hash:
+		local = collectRefs(actual.KeyType, refs)
+		local = collectRefs(actual.ElemType, refs) || local
+	}
+	return local
+}
@@ -0,0 +1,516 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/meta"
+)
+
+type (
+	// Graph is the dependency graph of the workspace services.
+	Graph struct {
+		// Services lists the services in dependency order: services come after the
+		// services they use unless there is a cycle.
+		Services []*Service `json:"services"`
+		// SharedMediaTypes lists the media types defined identically by multiple services.
+		SharedMediaTypes []*SharedMediaType `json:"sharedMediaTypes,omitempty"`
+	}
+
+	// Service is a node of the dependency graph.
+	Service struct {
+		// Name is the API name.
+		Name string `json:"name"`
+		// Dir is the name of the output sub-directory of the service.
+		Dir string `json:"dir"`
+		// DesignPkg is the import path of the design package.
+		DesignPkg string `json:"design"`
+		// Uses lists the resources of the other services called by the service indexed by
+		// service name.
+		Uses map[string][]string `json:"uses,omitempty"`
+	}
+
+	// SharedMediaType is a media type defined identically by multiple services.
+	SharedMediaType struct {
+		// Identifier is the media type identifier.
+		Identifier string `json:"identifier"`
+		// TypeName is the name of the generated Go type.
+		TypeName string `json:"typeName"`
+		// Owner is the name of the service whose application package defines the type.
+		Owner string `json:"owner"`
+		// Users lists the names of the services that alias the type.
+		Users []string `json:"users"`
+	}
+)
+
+// apidslPath is the import path of the design language package.
+const apidslPath = "github.com/goadesign/goa/design/apidsl"
+
+// Discover returns the import paths of the design packages found under root sorted
+// alphabetically. The vendor and testdata directories and the directories whose name start with
+// "." or "_" are skipped.
+func Discover(root string) ([]string, error) {
+	var pkgs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		ok, err := isDesign(path)
+		if err != nil || !ok {
+			return err
+		}
+		pkg, err := codegen.PackagePath(path)
+		if err != nil {
+			return err
+		}
+		pkgs = append(pkgs, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pkgs)
+	return pkgs, nil
+}
+
+// isDesign returns true if the Go package in the given directory calls the API DSL.
+func isDesign(dir string) (bool, error) {
+	fset := token.NewFileSet()
+	filter := func(info os.FileInfo) bool { return !strings.HasSuffix(info.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return false, err
+	}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Files {
+			if callsAPI(f) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// callsAPI returns true if the given file imports the design language and calls API.
+func callsAPI(f *ast.File) bool {
+	var name string
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == apidslPath {
+			name = "apidsl"
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+		}
+	}
+	if name == "" {
+		return false
+	}
+	found := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			found = name == "." && fun.Name == "API"
+		case *ast.SelectorExpr:
+			x, ok := fun.X.(*ast.Ident)
+			found = ok && x.Name == name && fun.Sel.Name == "API"
+		}
+		return !found
+	})
+	return found
+}
+
+// Load builds the manifests of the given design packages. flags contains the goagen flags
+// that apply to all services such as "env" or "debug".
+func Load(pkgs []string, flags map[string]string) ([]*Manifest, error) {
+	var manifests []*Manifest
+	for _, pkg := range pkgs {
+		tmpDir, err := ioutil.TempDir("", "goagen-manifest")
+		if err != nil {
+			return nil, err
+		}
+		m, err := loadManifest(pkg, tmpDir, flags)
+		os.RemoveAll(tmpDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", pkg, err)
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// loadManifest runs the manifest generator on the given design package.
+func loadManifest(pkg, outDir string, flags map[string]string) (*Manifest, error) {
+	gen, err := meta.NewGenerator(
+		"workspace.GenerateManifest",
+		[]*codegen.ImportSpec{codegen.SimpleImport("github.com/goadesign/goa/goagen/workspace")},
+		serviceFlags(flags, pkg, outDir),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := gen.Generate(); err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(filepath.Join(outDir, "manifest.json"))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// NewGraph validates the cross-service references of the given manifests and builds the
+// dependency graph.
+func NewGraph(manifests []*Manifest) (*Graph, error) {
+	byName := make(map[string]*Manifest, len(manifests))
+	for _, m := range manifests {
+		if prev, ok := byName[m.Name]; ok {
+			return nil, fmt.Errorf("API %#v is defined by both %s and %s", m.Name, prev.DesignPkg, m.DesignPkg)
+		}
+		byName[m.Name] = m
+	}
+	var errs []string
+	for _, m := range manifests {
+		for name, resources := range m.Uses {
+			used, ok := byName[name]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("service %#v uses unknown service %#v", m.Name, name))
+				continue
+			}
+			if used == m {
+				errs = append(errs, fmt.Sprintf("service %#v cannot use itself", m.Name))
+				continue
+			}
+			for _, r := range resources {
+				if !contains(used.Resources, r) {
+					errs = append(errs, fmt.Sprintf("service %#v uses unknown resource %#v of service %#v", m.Name, r, name))
+				}
+			}
+			for _, mt := range m.MediaTypes {
+				if other := used.mediaType(mt.Identifier); other != nil && other.Digest != mt.Digest {
+					errs = append(errs, fmt.Sprintf("media type %#v is defined differently by services %#v and %#v", mt.Identifier, m.Name, name))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("invalid workspace:\n%s", strings.Join(errs, "\n"))
+	}
+
+	g := &Graph{}
+	ordered := order(manifests)
+	for _, m := range ordered {
+		g.Services = append(g.Services, &Service{
+			Name:      m.Name,
+			Dir:       strings.Join(strings.Fields(codegen.KebabCase(m.Name)), "-"),
+			DesignPkg: m.DesignPkg,
+			Uses:      m.Uses,
+		})
+	}
+	g.SharedMediaTypes = share(ordered)
+	return g, nil
+}
+
+// order sorts the manifests so that services come after the services they use. Services that
+// are part of a cycle are sorted alphabetically after the others.
+func order(manifests []*Manifest) []*Manifest {
+	sorted := make([]*Manifest, len(manifests))
+	copy(sorted, manifests)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	var res []*Manifest
+	done := make(map[string]bool)
+	for len(res) < len(sorted) {
+		progress := false
+		for _, m := range sorted {
+			if done[m.Name] {
+				continue
+			}
+			ready := true
+			for name := range m.Uses {
+				if !done[name] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				res = append(res, m)
+				done[m.Name] = true
+				progress = true
+			}
+		}
+		if !progress {
+			for _, m := range sorted {
+				if !done[m.Name] {
+					res = append(res, m)
+					done[m.Name] = true
+				}
+			}
+		}
+	}
+	return res
+}
+
+// share computes the media types that can be shared between services. The owner of a shared
+// media type is the first service that defines it in dependency order. A service may only alias
+// a media type if all the media types it references are also aliased from the same owner.
+func share(manifests []*Manifest) []*SharedMediaType {
+	var (
+		shared []*SharedMediaType
+		byID   = make(map[string]*SharedMediaType)
+		defs   = make(map[string]*MediaTypeManifest)
+	)
+	for _, m := range manifests {
+		for _, mt := range m.MediaTypes {
+			s, ok := byID[mt.Identifier]
+			if !ok {
+				s = &SharedMediaType{Identifier: mt.Identifier, TypeName: mt.TypeName, Owner: m.Name}
+				byID[mt.Identifier] = s
+				defs[mt.Identifier] = mt
+				shared = append(shared, s)
+				continue
+			}
+			if mt.Digest == defs[mt.Identifier].Digest && !mt.Local {
+				s.Users = append(s.Users, m.Name)
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, s := range shared {
+			var users []string
+			for _, u := range s.Users {
+				valid := true
+				for _, ref := range defs[s.Identifier].Refs {
+					r, ok := byID[ref]
+					if !ok || r.Owner != s.Owner || !contains(r.Users, u) {
+						valid = false
+						break
+					}
+				}
+				if valid {
+					users = append(users, u)
+				}
+			}
+			if len(users) != len(s.Users) {
+				s.Users = users
+				changed = true
+			}
+		}
+	}
+	var res []*SharedMediaType
+	for _, s := range shared {
+		if len(s.Users) > 0 {
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Identifier < res[j].Identifier })
+	return res
+}
+
+// Write writes the dependency graph to workspace.json and workspace.dot in the given
+// directory and returns the paths of the files.
+func (g *Graph) Write(outDir string) ([]string, error) {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	js, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	jsPath := filepath.Join(outDir, "workspace.json")
+	if err := ioutil.WriteFile(jsPath, append(js, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	dotPath := filepath.Join(outDir, "workspace.dot")
+	f, err := os.Create(dotPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := g.WriteDOT(f); err != nil {
+		return nil, err
+	}
+	return []string{jsPath, dotPath}, nil
+}
+
+// WriteDOT writes the dependency graph in the Graphviz DOT format. Edges are labeled with the
+// names of the resources used.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph workspace {"); err != nil {
+		return err
+	}
+	for _, s := range g.Services {
+		fmt.Fprintf(w, "\t%q;\n", s.Name)
+	}
+	for _, s := range g.Services {
+		names := make([]string, 0, len(s.Uses))
+		for n := range s.Uses {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if len(s.Uses[n]) == 0 {
+				fmt.Fprintf(w, "\t%q -> %q;\n", s.Name, n)
+				continue
+			}
+			fmt.Fprintf(w, "\t%q -> %q [label=%q];\n", s.Name, n, strings.Join(s.Uses[n], ", "))
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// Generate runs the generators of the given goagen commands, e.g. "app" or "swagger", on each
+// service in dependency order. The artifacts of each service are generated in the service
+// sub-directory of outDir. flags contains the goagen flags that apply to all services such as
+// "env" or "debug". If commands include "client" the clients of the services used by a service
+// are injected in its controllers.
+func (g *Graph) Generate(outDir string, commands []string, flags map[string]string) ([]string, error) {
+	var files []string
+	for _, s := range g.Services {
+		svcDir := filepath.Join(outDir, s.Dir)
+		pkgs, err := g.mediaTypePkgs(s, outDir)
+		if err != nil {
+			return files, err
+		}
+		var clients map[string]string
+		if contains(commands, "client") {
+			if clients, err = g.clientPkgs(s, outDir); err != nil {
+				return files, err
+			}
+		}
+		for _, cmd := range commands {
+			pkgPath := "github.com/goadesign/goa/goagen/gen_" + cmd
+			pkgSrcPath, err := codegen.PackageSourcePath(pkgPath)
+			if err != nil {
+				return files, fmt.Errorf("unknown command %#v: %s", cmd, err)
+			}
+			pkgName, err := codegen.PackageName(pkgSrcPath)
+			if err != nil {
+				return files, err
+			}
+			gen, err := meta.NewGenerator(
+				pkgName+".Generate",
+				[]*codegen.ImportSpec{codegen.SimpleImport(pkgPath)},
+				serviceFlags(flags, s.DesignPkg, svcDir),
+				nil,
+			)
+			if err != nil {
+				return files, err
+			}
+			gen.MediaTypePkgs = pkgs
+			gen.ClientPkgs = clients
+			fs, err := gen.Generate()
+			if err != nil {
+				return files, fmt.Errorf("%s: %s", s.Name, err)
+			}
+			files = append(files, fs...)
+		}
+	}
+	return files, nil
+}
+
+// mediaTypePkgs returns the import paths of the application packages that define the media
+// types aliased by the given service indexed by media type identifier.
+func (g *Graph) mediaTypePkgs(s *Service, outDir string) (map[string]string, error) {
+	pkgs := make(map[string]string)
+	for _, mt := range g.SharedMediaTypes {
+		if !contains(mt.Users, s.Name) {
+			continue
+		}
+		owner := g.service(mt.Owner)
+		pkg, err := codegen.PackagePath(filepath.Join(outDir, owner.Dir, "app"))
+		if err != nil {
+			return nil, err
+		}
+		pkgs[mt.Identifier] = pkg
+	}
+	return pkgs, nil
+}
+
+// clientPkgs returns the import paths of the client packages of the services used by the given
+// service indexed by service name.
+func (g *Graph) clientPkgs(s *Service, outDir string) (map[string]string, error) {
+	pkgs := make(map[string]string)
+	for name := range s.Uses {
+		used := g.service(name)
+		if used == nil {
+			continue
+		}
+		pkg, err := codegen.PackagePath(filepath.Join(outDir, used.Dir, "client"))
+		if err != nil {
+			return nil, err
+		}
+		pkgs[name] = pkg
+	}
+	return pkgs, nil
+}
+
+// service returns the service with the given name.
+func (g *Graph) service(name string) *Service {
+	for _, s := range g.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// mediaType returns the manifest of the media type with the given identifier, nil if the
+// service does not define it.
+func (m *Manifest) mediaType(id string) *MediaTypeManifest {
+	for _, mt := range m.MediaTypes {
+		if mt.Identifier == id {
+			return mt
+		}
+	}
+	return nil
+}
+
+// serviceFlags returns the flags given to the generators of a service.
+func serviceFlags(flags map[string]string, designPkg, outDir string) map[string]string {
+	res := map[string]string{"design": designPkg, "out": outDir}
+	for _, n := range []string{"debug", "env"} {
+		if v, ok := flags[n]; ok {
+			res[n] = v
+		}
+	}
+	return res
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
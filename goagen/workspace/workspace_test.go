@@ -0,0 +1,188 @@
+package workspace_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/workspace"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Discover", func() {
+	var ws *codegen.Workspace
+	var root string
+
+	BeforeEach(func() {
+		var err error
+		ws, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		root = filepath.Join(ws.Path, "src", "services")
+		write := func(pkg, content string) {
+			pk, err := ws.NewPackage(filepath.Join("services", pkg))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(filepath.Join(pk.Abs(), "design.go"), []byte(content), 0644)).Should(Succeed())
+		}
+		write("cellar/design", `package design
+
+import . "github.com/goadesign/goa/design/apidsl"
+
+var _ = API("cellar", nil)
+`)
+		write("orders/design", `package design
+
+import "github.com/goadesign/goa/design/apidsl"
+
+var _ = apidsl.API("orders", nil)
+`)
+		write("types", `package types
+
+import . "github.com/goadesign/goa/design/apidsl"
+
+var Bottle = MediaType("application/vnd.bottle+json", nil)
+`)
+		write("vendor/other/design", `package design
+
+import . "github.com/goadesign/goa/design/apidsl"
+
+var _ = API("other", nil)
+`)
+	})
+
+	AfterEach(func() {
+		ws.Delete()
+	})
+
+	It("returns the design packages", func() {
+		pkgs, err := workspace.Discover(root)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(pkgs).Should(Equal([]string{"services/cellar/design", "services/orders/design"}))
+	})
+})
+
+var _ = Describe("NewManifest", func() {
+	var manifest *workspace.Manifest
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		apidsl.API("orders", func() {
+			apidsl.Uses("cellar", "bottle")
+		})
+		account := apidsl.Type("Account", func() {
+			apidsl.Attribute("name")
+		})
+		bottle := apidsl.MediaType("application/vnd.bottle+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("id", design.Integer)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("id")
+			})
+		})
+		apidsl.MediaType("application/vnd.order+json", func() {
+			apidsl.Attributes(func() {
+				apidsl.Attribute("bottle", bottle)
+				apidsl.Attribute("account", account)
+			})
+			apidsl.View("default", func() {
+				apidsl.Attribute("bottle")
+				apidsl.Attribute("account")
+			})
+		})
+		apidsl.Resource("order", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/:id"))
+			})
+		})
+		dslengine.Run()
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		var err error
+		manifest, err = workspace.NewManifest(design.Design)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("describes the service", func() {
+		Ω(manifest.Name).Should(Equal("orders"))
+		Ω(manifest.Uses).Should(Equal(map[string][]string{"cellar": {"bottle"}}))
+		Ω(manifest.Resources).Should(Equal([]string{"order"}))
+		Ω(manifest.MediaTypes).Should(HaveLen(2))
+		bottle, order := manifest.MediaTypes[0], manifest.MediaTypes[1]
+		Ω(bottle.TypeName).Should(Equal("Bottle"))
+		Ω(bottle.Digest).ShouldNot(BeEmpty())
+		Ω(bottle.Local).Should(BeFalse())
+		Ω(order.Refs).Should(Equal([]string{"application/vnd.bottle+json"}))
+		Ω(order.Local).Should(BeTrue())
+	})
+})
+
+var _ = Describe("NewGraph", func() {
+	var manifests []*workspace.Manifest
+	var graph *workspace.Graph
+	var graphErr error
+
+	BeforeEach(func() {
+		bottle := func() *workspace.MediaTypeManifest {
+			return &workspace.MediaTypeManifest{Identifier: "application/vnd.bottle+json", TypeName: "Bottle", Digest: "b"}
+		}
+		order := &workspace.MediaTypeManifest{Identifier: "application/vnd.order+json", TypeName: "Order", Digest: "o", Refs: []string{"application/vnd.bottle+json"}}
+		manifests = []*workspace.Manifest{
+			{Name: "orders", DesignPkg: "services/orders/design", Uses: map[string][]string{"cellar": {"bottle"}}, Resources: []string{"order"}, MediaTypes: []*workspace.MediaTypeManifest{bottle(), order}},
+			{Name: "cellar", DesignPkg: "services/cellar/design", Resources: []string{"bottle"}, MediaTypes: []*workspace.MediaTypeManifest{bottle()}},
+			{Name: "billing", DesignPkg: "services/billing/design", Uses: map[string][]string{"orders": nil}, Resources: []string{"invoice"}, MediaTypes: []*workspace.MediaTypeManifest{order}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		graph, graphErr = workspace.NewGraph(manifests)
+	})
+
+	It("sorts the services in dependency order", func() {
+		Ω(graphErr).ShouldNot(HaveOccurred())
+		var names []string
+		for _, s := range graph.Services {
+			names = append(names, s.Name)
+		}
+		Ω(names).Should(Equal([]string{"cellar", "orders", "billing"}))
+	})
+
+	It("shares the identical media types", func() {
+		Ω(graphErr).ShouldNot(HaveOccurred())
+		Ω(graph.SharedMediaTypes).Should(Equal([]*workspace.SharedMediaType{
+			{Identifier: "application/vnd.bottle+json", TypeName: "Bottle", Owner: "cellar", Users: []string{"orders"}},
+		}))
+	})
+
+	It("writes the DOT graph", func() {
+		Ω(graphErr).ShouldNot(HaveOccurred())
+		var b bytes.Buffer
+		Ω(graph.WriteDOT(&b)).Should(Succeed())
+		Ω(b.String()).Should(Equal(`digraph workspace {
+	"cellar";
+	"orders";
+	"billing";
+	"orders" -> "cellar" [label="bottle"];
+	"billing" -> "orders";
+}
+`))
+	})
+
+	Context("with invalid references", func() {
+		BeforeEach(func() {
+			manifests[0].Uses = map[string][]string{"cellar": {"wine"}, "shipping": nil}
+			manifests[1].MediaTypes[0].Digest = "changed"
+		})
+
+		It("returns an error", func() {
+			Ω(graphErr).Should(HaveOccurred())
+			Ω(graphErr.Error()).Should(Equal(`invalid workspace:
+media type "application/vnd.bottle+json" is defined differently by services "orders" and "cellar"
+service "orders" uses unknown resource "wine" of service "cellar"
+service "orders" uses unknown service "shipping"`))
+		})
+	})
+})
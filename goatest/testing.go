@@ -38,3 +38,25 @@ func Service(logBuf *bytes.Buffer, respSetter ResponseSetterFunc) *goa.Service {
 	s.Encoder.Register(newEncoder, "*/*")
 	return s
 }
+
+// NewTestService returns a service suitable for end-to-end tests. The service logs to logBuf
+// instead of stderr, decodes and encodes JSON and renders errors with the ErrorHandler middleware.
+// It does not listen on any address: mount the controllers and serve it with a httptest.Server.
+//
+//	var logBuf bytes.Buffer
+//	service := goatest.NewTestService("cellar", &logBuf)
+//	app.MountBottleController(service, NewBottleController(service))
+//	srv := httptest.NewServer(service)
+//	defer srv.Close()
+//	resp, err := http.Get(srv.URL + "/bottles/1")
+func NewTestService(name string, logBuf io.Writer) *goa.Service {
+	s := goa.New(name)
+	logger := log.New(logBuf, "", log.Ltime)
+	s.WithLogger(goa.NewLogger(logger))
+	s.Use(middleware.RequestID())
+	s.Use(middleware.LogRequest(true))
+	s.Use(middleware.ErrorHandler(s, true))
+	s.Decoder.Register(goa.NewJSONDecoder, "*/*")
+	s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+	return s
+}
@@ -0,0 +1,47 @@
+package goatest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goadesign/goa"
+)
+
+func TestNewTestService(t *testing.T) {
+	var logBuf bytes.Buffer
+	service := NewTestService("test", &logBuf)
+	ctrl := service.NewController("test")
+	service.Mux.Handle("GET", "/fail", ctrl.MuxHandler("fail", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return goa.ErrBadRequest("oops")
+	}, nil))
+	service.Compile()
+
+	srv := httptest.NewServer(service)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/fail")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("got status %d, expected %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != goa.ErrorMediaIdentifier {
+		t.Errorf("got content type %q, expected %q", ct, goa.ErrorMediaIdentifier)
+	}
+	var body goa.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error response: %s", err)
+	}
+	if body.Detail != "oops" {
+		t.Errorf("got detail %q, expected %q", body.Detail, "oops")
+	}
+	if logBuf.Len() == 0 {
+		t.Errorf("expected requests to be logged to the log buffer")
+	}
+}
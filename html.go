@@ -0,0 +1,106 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"io/ioutil"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// htmlTemplateKey identifies the template used to render a media type view.
+type htmlTemplateKey struct {
+	mediaType string
+	view      string
+}
+
+// HTMLTemplate registers the template used to render the responses of the actions that render
+// HTML (see the RenderHTML DSL) whose media type and view match the given values. The media type
+// is the identifier given to the media type in the design, e.g. "application/vnd.bottle" or
+// "application/vnd.bottle; type=collection" for collections. The template is executed with the
+// response body as data, e.g. the *app.Bottle given to the OK method of the action context.
+// Example:
+//
+//	t := template.Must(template.ParseFiles("templates/bottle.html"))
+//	service.HTMLTemplate("application/vnd.bottle", "default", t)
+//
+func (service *Service) HTMLTemplate(mediaType, view string, t *template.Template) {
+	if service.htmlTemplates == nil {
+		service.htmlTemplates = make(map[htmlTemplateKey]*template.Template)
+	}
+	service.htmlTemplates[htmlTemplateKey{mediaType, view}] = t
+}
+
+// compileHTMLTemplates runs the contextual escaping of the registered templates which
+// html/template otherwise performs on their first execution.
+func (service *Service) compileHTMLTemplates() {
+	for _, t := range service.htmlTemplates {
+		// Executing the template escapes it, the execution error due to the missing data is
+		// irrelevant.
+		t.Execute(ioutil.Discard, nil)
+	}
+}
+
+// SendHTML renders the response body using the template registered for the given media type and
+// view if the request Accept header prefers HTML, see PrefersHTML. It behaves like Send if it
+// doesn't or if there is no such template so that the same action serves both API clients and
+// browsers. The output stages run prior to rendering the template. Responses rendered for a media
+// type and view that have a template set the "Vary: Accept" header so that caches keep the HTML and
+// encoded representations apart.
+func (service *Service) SendHTML(ctx context.Context, code int, mediaType, view string, body interface{}) error {
+	t := service.htmlTemplates[htmlTemplateKey{mediaType, view}]
+	if resp := ContextResponse(ctx); t != nil && resp != nil {
+		resp.Header().Add("Vary", "Accept")
+	}
+	req := ContextRequest(ctx)
+	if t == nil || req == nil || req.Request == nil || !PrefersHTML(req.Header.Get("Accept")) {
+		return service.Send(ctx, code, body)
+	}
+	body, err := service.transform(ctx, body)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, body); err != nil {
+		return err
+	}
+	resp := ContextResponse(ctx)
+	resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	resp.WriteHeader(code)
+	_, err = buf.WriteTo(resp)
+	return err
+}
+
+// PrefersHTML returns true if the given Accept header value gives text/html (or
+// application/xhtml+xml) a quality greater than or equal to the quality of any other specific
+// media type. Wildcards are ignored so that requests with no Accept header or accepting "*/*"
+// do not prefer HTML.
+func PrefersHTML(accept string) bool {
+	var html, other float64
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch {
+		case mt == "text/html" || mt == "application/xhtml+xml":
+			if q > html {
+				html = q
+			}
+		case strings.HasSuffix(mt, "/*"):
+		default:
+			if q > other {
+				other = q
+			}
+		}
+	}
+	return html > 0 && html >= other
+}
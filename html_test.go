@@ -0,0 +1,99 @@
+package goa_test
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SendHTML", func() {
+	var s *goa.Service
+	var accept string
+	var rw *httptest.ResponseRecorder
+	var sendErr error
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		s.HTMLTemplate("application/vnd.bottle", "default",
+			template.Must(template.New("bottle").Parse(`<h1>{{ .Name }}</h1>`)))
+		accept = ""
+	})
+
+	JustBeforeEach(func() {
+		ctrl := s.NewController("BottleController")
+		handler := func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+			goa.ContextResponse(ctx).Header().Set("Content-Type", "application/vnd.bottle+json")
+			sendErr = s.SendHTML(ctx, 200, "application/vnd.bottle", "default", &transformedBottle{Name: "<merlot>"})
+			return sendErr
+		}
+		req, err := http.NewRequest("GET", "/bottles/1", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rw = httptest.NewRecorder()
+		ctrl.MuxHandler("show", handler, nil)(rw, req, nil)
+	})
+
+	Context("with a request preferring HTML", func() {
+		BeforeEach(func() {
+			accept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+		})
+
+		It("renders the template", func() {
+			Ω(sendErr).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("text/html; charset=utf-8"))
+			Ω(rw.Body.String()).Should(Equal("<h1>&lt;merlot&gt;</h1>"))
+			Ω(rw.Header().Get("Vary")).Should(Equal("Accept"))
+		})
+	})
+
+	Context("with a request preferring JSON", func() {
+		BeforeEach(func() {
+			accept = "application/json"
+		})
+
+		It("encodes the response", func() {
+			Ω(sendErr).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(MatchJSON(`{"name":"<merlot>"}`))
+			Ω(rw.Header().Get("Vary")).Should(Equal("Accept"))
+		})
+	})
+
+	Context("with no template for the view", func() {
+		BeforeEach(func() {
+			accept = "text/html"
+			s = goa.New("test")
+			s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		})
+
+		It("encodes the response", func() {
+			Ω(sendErr).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(MatchJSON(`{"name":"<merlot>"}`))
+		})
+	})
+})
+
+var _ = Describe("PrefersHTML", func() {
+	It("returns false when no specific media type is accepted", func() {
+		Ω(goa.PrefersHTML("")).Should(BeFalse())
+		Ω(goa.PrefersHTML("*/*")).Should(BeFalse())
+	})
+
+	It("returns true for browser requests", func() {
+		Ω(goa.PrefersHTML("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")).Should(BeTrue())
+	})
+
+	It("compares the quality of HTML with the quality of the other media types", func() {
+		Ω(goa.PrefersHTML("application/json")).Should(BeFalse())
+		Ω(goa.PrefersHTML("application/json,text/html;q=0.5")).Should(BeFalse())
+		Ω(goa.PrefersHTML("application/json;q=0.5,text/html")).Should(BeTrue())
+	})
+})
@@ -0,0 +1,74 @@
+package goa
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// LogLevel is the minimum severity of the messages written by a LeveledLogger.
+type LogLevel int32
+
+const (
+	// LogLevelInfo writes both informational and error messages.
+	LogLevelInfo LogLevel = iota
+	// LogLevelError only writes error messages.
+	LogLevelError
+)
+
+// LeveledLogger is a LogAdapter that discards the messages whose severity is below a level which
+// can be changed at runtime. The loggers created with New share the level of their parent so that
+// changing the level of the service logger also affects the request loggers derived from it.
+type LeveledLogger struct {
+	LogAdapter
+	level *int32
+}
+
+// String returns the name of the level, "info" or "error".
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelInfo:
+		return "info"
+	case LogLevelError:
+		return "error"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int32(l))
+}
+
+// ParseLogLevel returns the level with the given name.
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch name {
+	case "info":
+		return LogLevelInfo, nil
+	case "error":
+		return LogLevelError, nil
+	}
+	return 0, fmt.Errorf("invalid log level %#v, must be one of \"info\" or \"error\"", name)
+}
+
+// NewLeveledLogger wraps the given logger so that only the messages at or above level are written.
+func NewLeveledLogger(logger LogAdapter, level LogLevel) *LeveledLogger {
+	l := int32(level)
+	return &LeveledLogger{LogAdapter: logger, level: &l}
+}
+
+// Level returns the current level.
+func (l *LeveledLogger) Level() LogLevel {
+	return LogLevel(atomic.LoadInt32(l.level))
+}
+
+// SetLevel changes the level of the logger and of all the loggers created from it.
+func (l *LeveledLogger) SetLevel(level LogLevel) {
+	atomic.StoreInt32(l.level, int32(level))
+}
+
+// Info logs an informational message if the level is LogLevelInfo.
+func (l *LeveledLogger) Info(msg string, keyvals ...interface{}) {
+	if l.Level() <= LogLevelInfo {
+		l.LogAdapter.Info(msg, keyvals...)
+	}
+}
+
+// New appends to the logger context and returns a logger that shares the level of l.
+func (l *LeveledLogger) New(keyvals ...interface{}) LogAdapter {
+	return &LeveledLogger{LogAdapter: l.LogAdapter.New(keyvals...), level: l.level}
+}
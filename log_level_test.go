@@ -0,0 +1,51 @@
+package goa_test
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LeveledLogger", func() {
+	var buf bytes.Buffer
+	var logger *goa.LeveledLogger
+
+	BeforeEach(func() {
+		buf.Reset()
+		logger = goa.NewLeveledLogger(goa.NewLogger(log.New(&buf, "", 0)), goa.LogLevelInfo)
+	})
+
+	It("logs informational messages at the info level", func() {
+		logger.Info("hello")
+		Ω(buf.String()).Should(Equal("[INFO] hello\n"))
+	})
+
+	It("discards informational messages at the error level", func() {
+		logger.SetLevel(goa.LogLevelError)
+		logger.Info("hello")
+		logger.Error("boom")
+		Ω(buf.String()).Should(Equal("[EROR] boom\n"))
+	})
+
+	It("shares the level with derived loggers", func() {
+		child := logger.New("req_id", "abc")
+		logger.SetLevel(goa.LogLevelError)
+		child.Info("hello")
+		Ω(buf.String()).Should(BeEmpty())
+		logger.SetLevel(goa.LogLevelInfo)
+		child.Info("hello")
+		Ω(buf.String()).Should(Equal("[INFO] hello req_id=abc\n"))
+	})
+
+	It("parses level names", func() {
+		level, err := goa.ParseLogLevel("error")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(level).Should(Equal(goa.LogLevelError))
+		Ω(level.String()).Should(Equal("error"))
+		_, err = goa.ParseLogLevel("debug")
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -1,4 +1,4 @@
-// +build !js,!appengine
+// +build !js,!appengine,!goa_nometrics
 
 package goa
 
@@ -15,3 +15,8 @@ func IncrCounter(key []string, val float32) {
 func MeasureSince(key []string, start time.Time) {
 	// Do nothing
 }
+
+// Not supported in Google App Engine
+func SetGauge(key []string, val float32) {
+	// Do nothing
+}
@@ -0,0 +1,35 @@
+// +build goa_nometrics,!js,!appengine
+
+package goa
+
+import (
+	"time"
+)
+
+// Metrics are disabled when building with the goa_nometrics tag, this removes the dependency on
+// the metrics package from the service binary.
+
+// AddSample does nothing when metrics are disabled.
+func AddSample(key []string, val float32) {
+	// Do nothing
+}
+
+// EmitKey does nothing when metrics are disabled.
+func EmitKey(key []string, val float32) {
+	// Do nothing
+}
+
+// IncrCounter does nothing when metrics are disabled.
+func IncrCounter(key []string, val float32) {
+	// Do nothing
+}
+
+// MeasureSince does nothing when metrics are disabled.
+func MeasureSince(key []string, start time.Time) {
+	// Do nothing
+}
+
+// SetGauge does nothing when metrics are disabled.
+func SetGauge(key []string, val float32) {
+	// Do nothing
+}
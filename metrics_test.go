@@ -1,3 +1,5 @@
+// +build !goa_nometrics
+
 package goa_test
 
 import (
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"context"
 
@@ -23,13 +24,20 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 				return nil
 			}
 			cause := cause(e)
+			if merr, ok := cause.(goa.MultiError); ok && len(merr) > 0 {
+				cause = merr.Response()
+			}
 			status := http.StatusInternalServerError
 			var respBody interface{}
 			if err, ok := cause.(goa.ServiceError); ok {
 				status = err.ResponseStatus()
 				respBody = err
 				goa.ContextResponse(ctx).ErrorCode = err.Token()
+				goa.ContextResponse(ctx).ErrorDesign = goa.ErrorDesignLocation(err)
 				rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
+				if resp, ok := err.(*goa.ErrorResponse); ok && resp.RetryAfter > 0 {
+					rw.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfter))
+				}
 			} else {
 				respBody = e.Error()
 				rw.Header().Set("Content-Type", "text/plain")
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	pErrors "github.com/pkg/errors"
 
@@ -163,6 +164,39 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with a handler returning a multi error", func() {
+		BeforeEach(func() {
+			service = newService(nil)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return goa.NewMultiError(goa.MissingParamError("id"), goa.MissingHeaderError("X-Key"))
+			}
+		})
+
+		It("renders the merged error response", func() {
+			var decoded map[string]interface{}
+			Ω(rw.Status).Should(Equal(400))
+			Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ErrorMediaIdentifier}))
+			err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded["detail"]).Should(Equal(`missing required parameter "id"; missing required HTTP header "X-Key"`))
+			Ω(decoded["meta"]).Should(HaveKeyWithValue("errors", HaveLen(2)))
+		})
+	})
+
+	Context("with a handler returning a rate limit error", func() {
+		BeforeEach(func() {
+			service = newService(nil)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return goa.RateLimitError("slow down", 30*time.Second)
+			}
+		})
+
+		It("sets the Retry-After header", func() {
+			Ω(rw.Status).Should(Equal(429))
+			Ω(rw.ParentHeader["Retry-After"]).Should(Equal([]string{"30"}))
+		})
+	})
+
 	Context("with a handler returning a pkg errors wrapped error", func() {
 		var wrappedError error
 		var logger *testLogger
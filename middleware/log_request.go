@@ -20,6 +20,18 @@ import (
 // request ID for logging.
 // If verbose is true then the middlware logs the request and response bodies.
 func LogRequest(verbose bool) goa.Middleware {
+	return logRequest(verbose, nil)
+}
+
+// LogSampledRequest creates a request logger middleware that only logs the requests selected by
+// the given sampling configuration based on the response status. Both the "started" and
+// "completed" entries of selected requests are written once the request has been handled.
+func LogSampledRequest(verbose bool, sampling *AccessLogSampling) goa.Middleware {
+	return logRequest(verbose, sampling)
+}
+
+// logRequest implements LogRequest and LogSampledRequest, sampling may be nil.
+func logRequest(verbose bool, sampling *AccessLogSampling) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			reqID := ctx.Value(reqIDKey)
@@ -29,52 +41,67 @@ func LogRequest(verbose bool) goa.Middleware {
 			ctx = goa.WithLogContext(ctx, "req_id", reqID)
 			startedAt := time.Now()
 			r := goa.ContextRequest(ctx)
-			goa.LogInfo(ctx, "started", r.Method, r.URL.String(), "from", from(req),
-				"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
-			if verbose {
-				if len(r.Header) > 0 {
-					logCtx := make([]interface{}, 2*len(r.Header))
-					i := 0
-					for k, v := range r.Header {
-						logCtx[i] = k
-						logCtx[i+1] = interface{}(strings.Join(v, ", "))
-						i = i + 2
-					}
-					goa.LogInfo(ctx, "headers", logCtx...)
-				}
-				if len(r.Params) > 0 {
-					logCtx := make([]interface{}, 2*len(r.Params))
-					i := 0
-					for k, v := range r.Params {
-						logCtx[i] = k
-						logCtx[i+1] = interface{}(strings.Join(v, ", "))
-						i = i + 2
+			logStarted := func() {
+				goa.LogInfo(ctx, "started", r.Method, r.URL.String(), "from", from(req),
+					"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
+				if verbose {
+					if len(r.Header) > 0 {
+						logCtx := make([]interface{}, 2*len(r.Header))
+						i := 0
+						for k, v := range r.Header {
+							logCtx[i] = k
+							logCtx[i+1] = interface{}(strings.Join(v, ", "))
+							i = i + 2
+						}
+						goa.LogInfo(ctx, "headers", logCtx...)
 					}
-					goa.LogInfo(ctx, "params", logCtx...)
-				}
-				if r.ContentLength > 0 {
-					if mp, ok := r.Payload.(map[string]interface{}); ok {
-						logCtx := make([]interface{}, 2*len(mp))
+					if len(r.Params) > 0 {
+						logCtx := make([]interface{}, 2*len(r.Params))
 						i := 0
-						for k, v := range mp {
+						for k, v := range r.Params {
 							logCtx[i] = k
-							logCtx[i+1] = interface{}(v)
+							logCtx[i+1] = interface{}(strings.Join(v, ", "))
 							i = i + 2
 						}
-						goa.LogInfo(ctx, "payload", logCtx...)
-					} else {
-						// Not the most efficient but this is used for debugging
-						js, err := json.Marshal(r.Payload)
-						if err != nil {
-							js = []byte("<invalid JSON>")
+						goa.LogInfo(ctx, "params", logCtx...)
+					}
+					if r.ContentLength > 0 {
+						if mp, ok := r.Payload.(map[string]interface{}); ok {
+							logCtx := make([]interface{}, 2*len(mp))
+							i := 0
+							for k, v := range mp {
+								logCtx[i] = k
+								logCtx[i+1] = interface{}(v)
+								i = i + 2
+							}
+							goa.LogInfo(ctx, "payload", logCtx...)
+						} else {
+							// Not the most efficient but this is used for debugging
+							js, err := json.Marshal(r.Payload)
+							if err != nil {
+								js = []byte("<invalid JSON>")
+							}
+							goa.LogInfo(ctx, "payload", "raw", string(js))
 						}
-						goa.LogInfo(ctx, "payload", "raw", string(js))
 					}
 				}
 			}
+			if sampling == nil {
+				logStarted()
+			}
 			err := h(ctx, rw, req)
 			resp := goa.ContextResponse(ctx)
-			if code := resp.ErrorCode; code != "" {
+			if sampling != nil {
+				if !sampling.Sample(resp.Status) {
+					return err
+				}
+				logStarted()
+			}
+			if code := resp.ErrorCode; code != "" && resp.ErrorDesign != "" {
+				goa.LogInfo(ctx, "completed", "status", resp.Status, "error", code,
+					"design", resp.ErrorDesign, "bytes", resp.Length, "time", time.Since(startedAt).String(),
+					"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
+			} else if code != "" {
 				goa.LogInfo(ctx, "completed", "status", resp.Status, "error", code,
 					"bytes", resp.Length, "time", time.Since(startedAt).String(),
 					"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
@@ -96,11 +96,24 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[1].Data[4]).Should(Equal("error"))
 		Ω(logger.InfoEntries[1].Data[5]).Should(HaveLen(8)) // Error ID
 		Ω(logger.InfoEntries[1].Data[6]).Should(Equal("bytes"))
-		Ω(logger.InfoEntries[1].Data[7]).Should(Equal(124))
+		Ω(logger.InfoEntries[1].Data[7]).Should(Equal(147))
 		Ω(logger.InfoEntries[1].Data[8]).Should(Equal("time"))
 		Ω(logger.InfoEntries[1].Data[10]).Should(Equal("ctrl"))
 		Ω(logger.InfoEntries[1].Data[11]).Should(Equal("test"))
 		Ω(logger.InfoEntries[1].Data[12]).Should(Equal("action"))
 		Ω(logger.InfoEntries[1].Data[13]).Should(Equal("<unknown>"))
 	})
+
+	It("logs the design location of errors", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goa.WithDesignLocation(goa.MissingAttributeError("raw", "name"), "test", "goo")
+		}
+		rw.ParentHeader = make(http.Header)
+		lg := middleware.LogRequest(false)(middleware.ErrorHandler(service, false)(h))
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(2))
+		Ω(logger.InfoEntries[1].Data).Should(HaveLen(16))
+		Ω(logger.InfoEntries[1].Data[6]).Should(Equal("design"))
+		Ω(logger.InfoEntries[1].Data[7]).Should(Equal("test#goo.payload.name"))
+	})
 })
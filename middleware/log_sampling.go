@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+
+	"github.com/goadesign/goa"
+)
+
+// statusClasses lists the response status classes that may be given sampling rates.
+var statusClasses = map[string]bool{"1xx": true, "2xx": true, "3xx": true, "4xx": true, "5xx": true}
+
+// AccessLogSampling holds the fraction of requests logged by LogSampledRequest for each class of
+// response status ("2xx", "4xx" etc.). Requests whose status class has no rate are always
+// logged. The rates can be changed at runtime, see LogAdminHandler.
+type AccessLogSampling struct {
+	lock  sync.RWMutex
+	rates map[string]float64
+}
+
+// NewAccessLogSampling creates a sampling configuration with the given rates indexed by status
+// class, e.g. to log 1% of successful requests and all errors:
+//
+//	sampling, err := middleware.NewAccessLogSampling(map[string]float64{"2xx": 0.01})
+func NewAccessLogSampling(rates map[string]float64) (*AccessLogSampling, error) {
+	s := &AccessLogSampling{rates: make(map[string]float64)}
+	if err := s.SetRates(rates); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetRates updates the rates of the given status classes. Rates must be between 0 and 1.
+func (s *AccessLogSampling) SetRates(rates map[string]float64) error {
+	for class, rate := range rates {
+		if !statusClasses[class] {
+			return fmt.Errorf("invalid status class %#v, must be one of 1xx, 2xx, 3xx, 4xx or 5xx", class)
+		}
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("invalid sampling rate %v for %s, must be between 0 and 1", rate, class)
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for class, rate := range rates {
+		s.rates[class] = rate
+	}
+	return nil
+}
+
+// Rates returns a copy of the rates indexed by status class.
+func (s *AccessLogSampling) Rates() map[string]float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	rates := make(map[string]float64, len(s.rates))
+	for class, rate := range s.rates {
+		rates[class] = rate
+	}
+	return rates
+}
+
+// Sample returns true if a request that produced a response with the given status should be
+// logged.
+func (s *AccessLogSampling) Sample(status int) bool {
+	s.lock.RLock()
+	rate, ok := s.rates[fmt.Sprintf("%dxx", status/100)]
+	s.lock.RUnlock()
+	if !ok || rate >= 1 {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// logSettings is the representation of the logging settings used by LogAdminHandler.
+type logSettings struct {
+	Level    string             `json:"level,omitempty"`
+	Sampling map[string]float64 `json:"sampling,omitempty"`
+}
+
+// LogAdminHandler returns a handler that reads and updates the level of logger and the access log
+// sampling rates at runtime. GET requests return the current settings and PUT requests update
+// them, for example:
+//
+//	{"level": "error", "sampling": {"2xx": 0.01}}
+//
+// Either logger or sampling may be nil in which case the corresponding settings are not exposed.
+// The handler changes the behavior of the whole service and should only be mounted on routes
+// restricted to operators:
+//
+//	ctrl := service.NewController("admin")
+//	service.Mux.Handle("GET", "/admin/log", ctrl.MuxHandler("log", h, nil))
+//	service.Mux.Handle("PUT", "/admin/log", ctrl.MuxHandler("log", h, nil))
+func LogAdminHandler(logger *goa.LeveledLogger, sampling *AccessLogSampling) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if req.Method == "PUT" {
+			var settings logSettings
+			if err := json.NewDecoder(req.Body).Decode(&settings); err != nil {
+				return goa.ErrBadRequest(err)
+			}
+			if settings.Level != "" {
+				if logger == nil {
+					return goa.ErrBadRequest("log level cannot be changed")
+				}
+				level, err := goa.ParseLogLevel(settings.Level)
+				if err != nil {
+					return goa.ErrBadRequest(err)
+				}
+				logger.SetLevel(level)
+			}
+			if settings.Sampling != nil {
+				if sampling == nil {
+					return goa.ErrBadRequest("access log sampling cannot be changed")
+				}
+				if err := sampling.SetRates(settings.Sampling); err != nil {
+					return goa.ErrBadRequest(err)
+				}
+			}
+			goa.LogInfo(ctx, "log settings updated", "level", settings.Level, "sampling", settings.Sampling)
+		}
+		var settings logSettings
+		if logger != nil {
+			settings.Level = logger.Level().String()
+		}
+		if sampling != nil {
+			settings.Sampling = sampling.Rates()
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(rw).Encode(&settings)
+	}
+}
@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogSampledRequest", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var logger *testLogger
+	var service *goa.Service
+	var sampling *middleware.AccessLogSampling
+	var status int
+
+	BeforeEach(func() {
+		logger = new(testLogger)
+		service = newService(logger)
+		req, _ = http.NewRequest("GET", "/goo", nil)
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		var err error
+		sampling, err = middleware.NewAccessLogSampling(map[string]float64{"2xx": 0})
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, status, "ok")
+		}
+		Ω(middleware.LogSampledRequest(false, sampling)(h)(ctx, rw, req)).Should(Succeed())
+	})
+
+	Context("with a status that is not sampled", func() {
+		BeforeEach(func() {
+			status = 200
+		})
+
+		It("does not log the request", func() {
+			Ω(logger.InfoEntries).Should(BeEmpty())
+		})
+	})
+
+	Context("with a status that has no sampling rate", func() {
+		BeforeEach(func() {
+			status = 500
+		})
+
+		It("logs the request", func() {
+			Ω(logger.InfoEntries).Should(HaveLen(2))
+			Ω(logger.InfoEntries[0].Msg).Should(Equal("started"))
+			Ω(logger.InfoEntries[1].Msg).Should(Equal("completed"))
+			Ω(logger.InfoEntries[1].Data[3]).Should(Equal(500))
+		})
+	})
+})
+
+var _ = Describe("AccessLogSampling", func() {
+	It("rejects invalid status classes", func() {
+		_, err := middleware.NewAccessLogSampling(map[string]float64{"200": 0.5})
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("rejects invalid rates", func() {
+		_, err := middleware.NewAccessLogSampling(map[string]float64{"2xx": 2})
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("samples according to the rates", func() {
+		s, err := middleware.NewAccessLogSampling(map[string]float64{"2xx": 0, "4xx": 1})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(s.Sample(204)).Should(BeFalse())
+		Ω(s.Sample(404)).Should(BeTrue())
+		Ω(s.Sample(503)).Should(BeTrue())
+	})
+})
+
+var _ = Describe("LogAdminHandler", func() {
+	var logger *goa.LeveledLogger
+	var sampling *middleware.AccessLogSampling
+	var req *http.Request
+	var rw *httptest.ResponseRecorder
+	var err error
+
+	BeforeEach(func() {
+		logger = goa.NewLeveledLogger(new(testLogger), goa.LogLevelInfo)
+		sampling, _ = middleware.NewAccessLogSampling(map[string]float64{"2xx": 0.5})
+		req, _ = http.NewRequest("GET", "/admin/log", nil)
+		rw = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		err = middleware.LogAdminHandler(logger, sampling)(context.Background(), rw, req)
+	})
+
+	It("returns the current settings", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		var settings map[string]interface{}
+		Ω(json.Unmarshal(rw.Body.Bytes(), &settings)).Should(Succeed())
+		Ω(settings).Should(Equal(map[string]interface{}{
+			"level":    "info",
+			"sampling": map[string]interface{}{"2xx": 0.5},
+		}))
+	})
+
+	Context("with new settings", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("PUT", "/admin/log", strings.NewReader(`{"level":"error","sampling":{"2xx":0.01}}`))
+		})
+
+		It("updates the logger and the sampling", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(logger.Level()).Should(Equal(goa.LogLevelError))
+			Ω(sampling.Rates()).Should(Equal(map[string]float64{"2xx": 0.01}))
+		})
+	})
+
+	Context("with invalid settings", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("PUT", "/admin/log", strings.NewReader(`{"level":"debug"}`))
+		})
+
+		It("returns a bad request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+			Ω(logger.Level()).Should(Equal(goa.LogLevelInfo))
+		})
+	})
+})
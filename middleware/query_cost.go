@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// ErrQueryTooCostly is the error returned by the QueryCostLimit middleware when the cost of a
+// request exceeds the budget.
+var ErrQueryTooCostly = goa.NewErrorClass("query_too_costly", 400)
+
+// QueryCostLimit returns a middleware that rejects the requests whose cost exceeds budget. The cost
+// of a request is computed from the cost model of the action, as recorded in the request context
+// by the generated code, and the relations listed in the "expand" query string parameter. Requests
+// to actions that have no cost model are not checked.
+//
+// The middleware is mounted on the actions whose responses declare embeddable relations using the
+// generated UseQueryCostMiddleware function.
+func QueryCostLimit(budget int) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			model := goa.ContextQueryCost(ctx)
+			if model == nil {
+				return h(ctx, rw, req)
+			}
+			allowed := make([]string, 0, len(model.Relations))
+			for rel := range model.Relations {
+				allowed = append(allowed, rel)
+			}
+			sort.Strings(allowed)
+			expand, err := goa.ParseExpand(req.URL.Query()[goa.ExpandParam], allowed...)
+			if err != nil {
+				return err
+			}
+			if cost := model.Compute(expand); cost > budget {
+				msg := fmt.Sprintf("query cost %d exceeds budget of %d", cost, budget)
+				return ErrQueryTooCostly(msg, "cost", cost, "budget", budget, "expand", expand)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
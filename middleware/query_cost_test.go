@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueryCostLimit", func() {
+	var ctx context.Context
+	var rw http.ResponseWriter
+	var h goa.Handler
+	var called bool
+
+	BeforeEach(func() {
+		rw = new(testResponseWriter)
+		ctx = goa.WithQueryCost(context.Background(), &goa.QueryCost{
+			Base:      5,
+			Relations: map[string]int{"account": 10, "bottles": 50},
+		})
+		called = false
+		h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		h = middleware.QueryCostLimit(20)(h)
+	})
+
+	It("accepts requests within budget", func() {
+		req, err := http.NewRequest("GET", "/bottles/1?expand=account", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(h(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("rejects requests over budget", func() {
+		req, err := http.NewRequest("GET", "/bottles/1?expand=account,bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		err = h(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("query cost 65 exceeds budget of 20"))
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("ignores actions with no cost model", func() {
+		req, err := http.NewRequest("GET", "/bottles/1?expand=account,bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(h(context.Background(), rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+})
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// NonceHeader is the name of the header that contains the request nonce.
+	NonceHeader = "X-Request-Nonce"
+
+	// TimestampHeader is the name of the header that contains the request timestamp expressed
+	// as the number of seconds since the Unix epoch.
+	TimestampHeader = "X-Request-Timestamp"
+)
+
+// ErrReplayedRequest is the error returned by the ReplayProtection middleware when a request
+// nonce has already been used.
+var ErrReplayedRequest = goa.NewErrorClass("replayed_request", 409)
+
+type (
+	// NonceStore records the nonces of the requests handled by the ReplayProtection
+	// middleware.
+	NonceStore interface {
+		// Add records the nonce for the duration of ttl. It returns false if the nonce
+		// has already been recorded and has not expired yet.
+		Add(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+	}
+
+	// memoryNonceStore is a NonceStore that keeps nonces in memory indexed by nonce value.
+	// Expired nonces are swept at most once per ttl.
+	memoryNonceStore struct {
+		sync.Mutex
+		nonces    map[string]time.Time
+		nextSweep time.Time
+	}
+)
+
+// NewMemoryNonceStore returns a NonceStore that keeps nonces in memory. It is only suitable for
+// services that run as a single process.
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{nonces: make(map[string]time.Time)}
+}
+
+// ReplayProtection returns a middleware that rejects requests whose nonce has already been used.
+// Requests must set the X-Request-Nonce and X-Request-Timestamp headers, the timestamp must be
+// within window of the current time. Requests missing either header or with a timestamp outside
+// of the window are rejected with a 401 response, replayed requests are rejected with a 409
+// response. Nonces are kept in the store for twice the window duration so that a request cannot
+// be replayed while its timestamp is still valid.
+//
+// The middleware is mounted on the actions flagged with ReplayProtected in the design using the
+// generated UseReplayProtectionMiddleware function.
+func ReplayProtection(store NonceStore, window time.Duration) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			nonce := req.Header.Get(NonceHeader)
+			if nonce == "" {
+				return goa.ErrUnauthorized("missing nonce", "header", NonceHeader)
+			}
+			ts := req.Header.Get(TimestampHeader)
+			secs, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil {
+				return goa.ErrUnauthorized("invalid timestamp", "header", TimestampHeader, "value", ts)
+			}
+			skew := time.Since(time.Unix(secs, 0))
+			if skew < -window || skew > window {
+				return goa.ErrUnauthorized("timestamp outside of accepted window", "header", TimestampHeader, "value", ts)
+			}
+			ok, err := store.Add(ctx, nonce, 2*window)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrReplayedRequest("request has already been processed", "nonce", nonce)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// Add records the nonce. Expired nonces are evicted periodically so that the cost of a call does
+// not grow with the number of recorded nonces.
+func (s *memoryNonceStore) Add(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	if now.After(s.nextSweep) {
+		for n, exp := range s.nonces {
+			if now.After(exp) {
+				delete(s.nonces, n)
+			}
+		}
+		s.nextSweep = now.Add(ttl)
+	}
+	if exp, ok := s.nonces[nonce]; ok && !now.After(exp) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
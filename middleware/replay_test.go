@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplayProtection", func() {
+	var ctx context.Context
+	var req *http.Request
+	var rw http.ResponseWriter
+	var h goa.Handler
+	var called bool
+
+	BeforeEach(func() {
+		var err error
+		service := newService(nil)
+		req, err = http.NewRequest("POST", "/foo", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set(middleware.NonceHeader, "nonce")
+		req.Header.Set(middleware.TimestampHeader, strconv.FormatInt(time.Now().Unix(), 10))
+		rw = new(testResponseWriter)
+		ctx = newContext(service, rw, req, nil)
+		called = false
+		h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		h = middleware.ReplayProtection(middleware.NewMemoryNonceStore(), time.Minute)(h)
+	})
+
+	It("accepts the first request and rejects replays", func() {
+		Ω(h(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+		called = false
+		err := h(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(409))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("rejects requests with no nonce", func() {
+		req.Header.Del(middleware.NonceHeader)
+		err := h(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+	})
+
+	It("rejects requests with a stale timestamp", func() {
+		req.Header.Set(middleware.TimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+		err := h(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		Ω(called).Should(BeFalse())
+	})
+})
+
+var _ = Describe("MemoryNonceStore", func() {
+	It("accepts nonces again once they have expired", func() {
+		store := middleware.NewMemoryNonceStore()
+		ok, err := store.Add(context.Background(), "nonce", 10*time.Millisecond)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ok).Should(BeTrue())
+		ok, _ = store.Add(context.Background(), "nonce", 10*time.Millisecond)
+		Ω(ok).Should(BeFalse())
+		time.Sleep(20 * time.Millisecond)
+		ok, _ = store.Add(context.Background(), "nonce", 10*time.Millisecond)
+		Ω(ok).Should(BeTrue())
+	})
+})
@@ -126,6 +126,7 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 			}
 
 			ctx = WithJWT(ctx, token)
+			ctx = goa.WithGrantedScopes(ctx, scopesInClaimList)
 			if validationFunc != nil {
 				nextHandler = validationFunc(nextHandler)
 			}
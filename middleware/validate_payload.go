@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// ValidatePayload returns a net/http middleware that validates request bodies with the given
+// function before calling the next handler. It makes it possible to reuse the payload validation
+// functions generated by goagen (e.g. app.ValidateCreateBottlePayload) in plain net/http, chi or
+// echo handlers that are not mounted on a goa service:
+//
+//	r.With(middleware.ValidatePayload(app.ValidateCreateBottlePayload)).Post("/bottles", create)
+//
+// Requests whose body fails validation are rejected with the goa error response produced by the
+// validation function, errors that are not goa.ServiceError produce 400 bad request responses.
+// The next handler reads the same body as the one that was validated.
+func ValidatePayload(validate func(body []byte) error) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			var body []byte
+			if req.Body != nil {
+				b, err := ioutil.ReadAll(req.Body)
+				req.Body.Close()
+				if err != nil {
+					writeError(rw, goa.ErrBadRequest(err))
+					return
+				}
+				body = b
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+			if err := validate(body); err != nil {
+				writeError(rw, err)
+				return
+			}
+			h.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// writeError writes the goa error response corresponding to err.
+func writeError(rw http.ResponseWriter, err error) {
+	if merr, ok := err.(goa.MultiError); ok && len(merr) > 0 {
+		err = merr.Response()
+	}
+	serr, ok := err.(goa.ServiceError)
+	if !ok {
+		serr = goa.ErrBadRequest(err).(goa.ServiceError)
+	}
+	rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
+	rw.WriteHeader(serr.ResponseStatus())
+	json.NewEncoder(rw).Encode(serr)
+}
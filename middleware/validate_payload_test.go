@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidatePayload", func() {
+	var validate func([]byte) error
+	var body string
+	var rw *httptest.ResponseRecorder
+	var received string
+
+	BeforeEach(func() {
+		body = `{"name":"bottle"}`
+		received = ""
+		validate = func(b []byte) error {
+			var payload map[string]interface{}
+			if err := json.Unmarshal(b, &payload); err != nil {
+				return goa.ErrInvalidEncoding(err)
+			}
+			if _, ok := payload["name"]; !ok {
+				return goa.MissingAttributeError("payload", "name")
+			}
+			return nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		req, err := http.NewRequest("POST", "/bottles", strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = httptest.NewRecorder()
+		h := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			b, _ := ioutil.ReadAll(req.Body)
+			received = string(b)
+			rw.WriteHeader(http.StatusCreated)
+		})
+		middleware.ValidatePayload(validate)(h).ServeHTTP(rw, req)
+	})
+
+	It("calls the handler with the validated body", func() {
+		Ω(rw.Code).Should(Equal(http.StatusCreated))
+		Ω(received).Should(Equal(body))
+	})
+
+	Context("with an invalid payload", func() {
+		BeforeEach(func() {
+			body = `{"vintage":1978}`
+		})
+
+		It("responds with the validation error", func() {
+			Ω(received).Should(BeEmpty())
+			Ω(rw.Code).Should(Equal(http.StatusBadRequest))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier))
+			var resp goa.ErrorResponse
+			Ω(json.Unmarshal(rw.Body.Bytes(), &resp)).Should(Succeed())
+			Ω(resp.Code).Should(Equal("invalid_request"))
+			Ω(resp.Detail).Should(ContainSubstring(`attribute "name" of payload is missing and required`))
+		})
+	})
+
+	Context("with a validation function returning a plain error", func() {
+		BeforeEach(func() {
+			validate = func([]byte) error { return errors.New("boom") }
+		})
+
+		It("responds with a bad request error", func() {
+			Ω(rw.Code).Should(Equal(http.StatusBadRequest))
+			var resp goa.ErrorResponse
+			Ω(json.Unmarshal(rw.Body.Bytes(), &resp)).Should(Succeed())
+			Ω(resp.Code).Should(Equal("bad_request"))
+			Ω(resp.Detail).Should(Equal("boom"))
+		})
+	})
+})
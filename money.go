@@ -0,0 +1,195 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is a monetary amount expressed in the minor units of its ISO 4217 currency, e.g. 1234 with
+// currency "USD" for 12.34 US dollars. Using integer minor units makes the arithmetic exact. Money
+// values are encoded as {"amount": 1234, "currency": "USD"}, see DecimalMoney for values encoded
+// with decimal amounts. The generated code uses Money for the attributes of type design.Money.
+type Money struct {
+	// Amount is the amount in minor units of the currency.
+	Amount int64 `form:"amount" json:"amount" xml:"amount"`
+	// Currency is the ISO 4217 currency code, e.g. "USD".
+	Currency string `form:"currency" json:"currency" xml:"currency"`
+}
+
+// DecimalMoney is a Money value encoded with a decimal amount, e.g.
+// {"amount": "12.34", "currency": "USD"}. The amount may not use more decimals than the minor
+// units of the currency.
+type DecimalMoney struct {
+	Money
+}
+
+// currencyExponents lists the number of decimals of the minor units of the ISO 4217 currencies
+// indexed by currency code.
+var currencyExponents = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2,
+	"AWG": 2, "AZN": 2, "BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0,
+	"BMD": 2, "BND": 2, "BOB": 2, "BOV": 2, "BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2,
+	"BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2, "CHW": 2, "CLF": 4,
+	"CLP": 0, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2,
+	"DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2,
+	"FJD": 2, "FKP": 2, "GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0,
+	"GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2,
+	"INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3, "JPY": 0, "KES": 2,
+	"KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2,
+	"LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2,
+	"MGA": 2, "MKD": 2, "MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2,
+	"MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2, "MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2,
+	"NOK": 2, "NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2, "PGK": 2, "PHP": 2,
+	"PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "RWF": 0,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2,
+	"SOS": 2, "SRD": 2, "SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2,
+	"TJS": 2, "TMT": 2, "TND": 3, "TOP": 2, "TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2,
+	"UAH": 2, "UGX": 0, "USD": 2, "USN": 2, "UYI": 0, "UYU": 2, "UYW": 4, "UZS": 2,
+	"VED": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2, "XAF": 0, "XCD": 2, "XCG": 2,
+	"XOF": 0, "XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2, "ZWL": 2,
+}
+
+// CurrencyExponent returns the number of decimals of the minor units of the given ISO 4217
+// currency, e.g. 2 for "USD" or 0 for "JPY". It returns false if code is not a currency code.
+func CurrencyExponent(code string) (int, bool) {
+	e, ok := currencyExponents[code]
+	return e, ok
+}
+
+// Validate checks that the currency is an ISO 4217 currency code. The errors are reported using
+// the "type" context, see ValidateAt.
+func (m *Money) Validate() error {
+	return m.ValidateAt("type")
+}
+
+// ValidateAt validates the amount like Validate and reports the errors using ctx as the path of the
+// money attribute, e.g. "raw.price".
+func (m *Money) ValidateAt(ctx string) (err error) {
+	if err2 := ValidateFormat(FormatCurrency, m.Currency); err2 != nil {
+		err = MergeErrors(err, InvalidFormatError(ctx+".currency", m.Currency, FormatCurrency, err2))
+	}
+	return
+}
+
+// Publicize returns m, it makes it possible to use Money in the generated private types.
+func (m *Money) Publicize() *Money {
+	return m
+}
+
+// Add returns the sum of m and other. It returns an error if the currencies differ or if the sum
+// overflows.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("cannot add %s amount to %s amount", other.Currency, m.Currency)
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, fmt.Errorf("%s amount overflow", m.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of m and other. It returns an error if the currencies differ or if
+// the difference overflows.
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%s amount overflow", m.Currency)
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+// IsZero returns true if the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Decimal returns the amount as a decimal string, e.g. "12.34" for 1234 US dollar cents.
+func (m Money) Decimal() string {
+	exp, ok := CurrencyExponent(m.Currency)
+	if !ok || exp == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+	sign, digits := "", strconv.FormatUint(uint64(m.Amount), 10)
+	if m.Amount < 0 {
+		sign, digits = "-", strconv.FormatUint(uint64(-(m.Amount+1))+1, 10)
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String returns the decimal amount followed by the currency, e.g. "12.34 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// ParseMoney returns the Money value for the given decimal amount, e.g. "12.34", and ISO 4217
+// currency code. It returns an error if the currency is unknown, if the amount is not a decimal
+// number, if it uses more decimals than the minor units of the currency or if it overflows.
+func ParseMoney(amount, currency string) (Money, error) {
+	exp, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("unknown currency %#v", currency)
+	}
+	digits := strings.TrimPrefix(amount, "-")
+	intPart, frac := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intPart, frac = digits[:i], digits[i+1:]
+		if frac == "" {
+			return Money{}, fmt.Errorf("invalid amount %#v", amount)
+		}
+	}
+	if len(frac) > exp {
+		return Money{}, fmt.Errorf("amount %#v has more than %d decimals", amount, exp)
+	}
+	if intPart == "" || strings.Trim(intPart+frac, "0123456789") != "" {
+		return Money{}, fmt.Errorf("invalid amount %#v", amount)
+	}
+	v, err := strconv.ParseInt(intPart+frac+strings.Repeat("0", exp-len(frac)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %#v, %s", amount, err)
+	}
+	if len(digits) < len(amount) {
+		v = -v
+	}
+	return Money{Amount: v, Currency: currency}, nil
+}
+
+// Publicize returns m, it makes it possible to use DecimalMoney in the generated private types.
+func (m *DecimalMoney) Publicize() *DecimalMoney {
+	return m
+}
+
+// decimalMoney is the JSON representation of DecimalMoney.
+type decimalMoney struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string.
+func (m DecimalMoney) MarshalJSON() ([]byte, error) {
+	return json.Marshal(decimalMoney{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes values whose amount is a decimal string.
+func (m *DecimalMoney) UnmarshalJSON(data []byte) error {
+	var d decimalMoney
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	if _, ok := CurrencyExponent(d.Currency); !ok {
+		// Let Validate report the invalid currency
+		m.Amount, m.Currency = 0, d.Currency
+		return nil
+	}
+	v, err := ParseMoney(d.Amount, d.Currency)
+	if err != nil {
+		return err
+	}
+	m.Money = v
+	return nil
+}
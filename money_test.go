@@ -0,0 +1,84 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Money", func() {
+	usd := func(amount int64) goa.Money { return goa.Money{Amount: amount, Currency: "USD"} }
+
+	It("adds and subtracts amounts of the same currency", func() {
+		sum, err := usd(1250).Add(usd(99))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(sum).Should(Equal(usd(1349)))
+		diff, err := usd(1250).Sub(usd(1300))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(diff).Should(Equal(usd(-50)))
+	})
+
+	It("rejects arithmetic on different currencies", func() {
+		_, err := usd(1).Add(goa.Money{Amount: 1, Currency: "EUR"})
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("cannot add EUR amount to USD amount"))
+	})
+
+	It("detects overflows", func() {
+		_, err := usd(math.MaxInt64).Add(usd(1))
+		Ω(err).Should(HaveOccurred())
+		_, err = usd(math.MinInt64).Sub(usd(1))
+		Ω(err).Should(HaveOccurred())
+		_, err = usd(0).Sub(usd(math.MinInt64))
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("formats amounts using the currency minor units", func() {
+		Ω(usd(1234).String()).Should(Equal("12.34 USD"))
+		Ω(usd(-5).Decimal()).Should(Equal("-0.05"))
+		Ω(goa.Money{Amount: 500, Currency: "JPY"}.Decimal()).Should(Equal("500"))
+		Ω(goa.Money{Amount: 1500, Currency: "BHD"}.Decimal()).Should(Equal("1.500"))
+	})
+
+	It("parses decimal amounts", func() {
+		m, err := goa.ParseMoney("12.3", "USD")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(m).Should(Equal(usd(1230)))
+		m, err = goa.ParseMoney("-7", "USD")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(m).Should(Equal(usd(-700)))
+	})
+
+	It("rejects invalid amounts", func() {
+		for _, a := range []string{"", "1.", ".5", "1.234", "1e3", "12.3.4"} {
+			_, err := goa.ParseMoney(a, "USD")
+			Ω(err).Should(HaveOccurred(), a)
+		}
+		_, err := goa.ParseMoney("1", "XYZ")
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("validates the currency code", func() {
+		m := usd(1)
+		Ω(m.Validate()).ShouldNot(HaveOccurred())
+		m.Currency = "usd"
+		Ω(m.Validate()).Should(HaveOccurred())
+		err := m.ValidateAt("raw.price")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("raw.price.currency"))
+		Ω(goa.ValidateFormat(goa.FormatCurrency, "EUR")).ShouldNot(HaveOccurred())
+		Ω(goa.ValidateFormat(goa.FormatCurrency, "EURO")).Should(HaveOccurred())
+	})
+
+	It("encodes decimal money amounts as strings", func() {
+		b, err := json.Marshal(goa.DecimalMoney{Money: usd(1999)})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"amount":"19.99","currency":"USD"}`))
+		var m goa.DecimalMoney
+		Ω(json.Unmarshal(b, &m)).Should(Succeed())
+		Ω(m.Money).Should(Equal(usd(1999)))
+	})
+})
@@ -0,0 +1,159 @@
+package goa
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MultiError is a list of errors that implements ServiceMergeableError: merging an error into a
+// MultiError appends it to the list instead of combining the details of both errors so that each
+// error is kept intact. Controllers and middleware use it to collect errors and to filter, group
+// and render them. The ErrorHandler middleware renders a MultiError as the error response
+// returned by its Response method.
+type MultiError []error
+
+// NewMultiError returns a MultiError that contains the given errors. nil errors are skipped and
+// the errors of MultiError arguments are added individually.
+func NewMultiError(errs ...error) MultiError {
+	var m MultiError
+	for _, err := range errs {
+		m = m.add(err)
+	}
+	return m
+}
+
+// Error returns the messages of the errors separated by semi-colons.
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ResponseStatus is the status used to build responses, see Status.
+func (m MultiError) ResponseStatus() int { return m.Status() }
+
+// Token is the unique error occurrence identifier of the first error.
+func (m MultiError) Token() string {
+	for _, err := range m {
+		if se, ok := err.(ServiceError); ok {
+			return se.Token()
+		}
+	}
+	return ""
+}
+
+// Merge returns a new MultiError made of the errors of the list followed by other. The list itself
+// is not modified.
+func (m MultiError) Merge(other error) error {
+	return m.add(other)
+}
+
+// Filter returns the errors whose kind is one of the given kinds.
+func (m MultiError) Filter(kinds ...ErrorKind) MultiError {
+	var res MultiError
+	for _, err := range m {
+		k := ErrorKindUnknown
+		if e, ok := err.(*ErrorResponse); ok {
+			k = e.ErrorKind()
+		}
+		for _, kind := range kinds {
+			if k == kind {
+				res = append(res, err)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// GroupByField groups the errors by the path of the parameter or attribute that caused them, e.g.
+// "payload.address.city". The errors that do not relate to a parameter or an attribute are grouped
+// under the empty path.
+func (m MultiError) GroupByField() map[string]MultiError {
+	groups := make(map[string]MultiError)
+	for _, err := range m {
+		var path string
+		if e, ok := err.(*ErrorResponse); ok {
+			path = errorAttributePath(e)
+		}
+		groups[path] = append(groups[path], err)
+	}
+	return groups
+}
+
+// Status computes the HTTP status of the response that carries the errors using the same rules
+// as MergeErrors: the status is 500 if any error is an internal error (an error that is not a
+// ServiceError or whose status is 500 or above), it is the status shared by all the errors if
+// there is one and 400 otherwise. The status of an empty list is 200.
+func (m MultiError) Status() int {
+	if len(m) == 0 {
+		return http.StatusOK
+	}
+	status := 0
+	for _, err := range m {
+		s := http.StatusInternalServerError
+		if se, ok := err.(ServiceError); ok {
+			s = se.ResponseStatus()
+		}
+		switch {
+		case s >= 500:
+			return http.StatusInternalServerError
+		case status == 0:
+			status = s
+		case status != s:
+			status = http.StatusBadRequest
+		}
+	}
+	return status
+}
+
+// Response converts the errors into a single error response. The response is the merge of the
+// errors as computed by MergeErrors, its status is computed by Status and its "errors" metadata
+// key lists the individual errors when there is more than one. Response returns nil if the list is
+// empty.
+func (m MultiError) Response() *ErrorResponse {
+	if len(m) == 0 {
+		return nil
+	}
+	resps := make([]*ErrorResponse, len(m))
+	for i, err := range m {
+		e := *asErrorResponse(err)
+		resps[i] = &e
+	}
+	if len(resps) == 1 {
+		return resps[0]
+	}
+	res := *resps[0]
+	res.Meta = make(map[string]interface{}, len(resps[0].Meta))
+	for k, v := range resps[0].Meta {
+		res.Meta[k] = v
+	}
+	var merged error = &res
+	for _, e := range resps[1:] {
+		merged = MergeErrors(merged, e)
+	}
+	resp := merged.(*ErrorResponse)
+	resp.Status = m.Status()
+	resp.Meta["errors"] = resps
+	return resp
+}
+
+// add returns a new list made of the errors of m followed by err, err is flattened if it is itself
+// a MultiError. m is copied so that the lists built by adding different errors to the same list
+// do not share their backing array.
+func (m MultiError) add(err error) MultiError {
+	var errs []error
+	switch actual := err.(type) {
+	case nil:
+		return m
+	case MultiError:
+		errs = actual
+	default:
+		errs = []error{err}
+	}
+	res := make(MultiError, len(m), len(m)+len(errs))
+	copy(res, m)
+	return append(res, errs...)
+}
@@ -0,0 +1,104 @@
+package goa
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MultiError", func() {
+	var merr MultiError
+
+	BeforeEach(func() {
+		merr = NewMultiError(
+			MissingAttributeError("raw", "name"),
+			InvalidRangeError("raw.age", 12, 18, true),
+			nil,
+			NewMultiError(InvalidLengthError("raw.name", "a", 1, 2, true)),
+			MissingHeaderError("X-Key"),
+		)
+	})
+
+	It("flattens the errors and skips nil errors", func() {
+		Ω(merr).Should(HaveLen(4))
+	})
+
+	It("appends merged errors", func() {
+		err := MergeErrors(merr, MissingParamError("id"))
+		Ω(err).Should(BeAssignableToTypeOf(MultiError{}))
+		Ω(err.(MultiError)).Should(HaveLen(5))
+		Ω(err.Error()).Should(ContainSubstring(`missing required parameter "id"`))
+	})
+
+	It("does not modify the list when merging errors", func() {
+		base := make(MultiError, 1, 4)
+		base[0] = MissingParamError("id")
+		first := base.Merge(MissingParamError("first")).(MultiError)
+		second := base.Merge(MissingParamError("second")).(MultiError)
+		Ω(base).Should(HaveLen(1))
+		Ω(first[1].Error()).Should(ContainSubstring(`"first"`))
+		Ω(second[1].Error()).Should(ContainSubstring(`"second"`))
+	})
+
+	It("filters errors by kind", func() {
+		filtered := merr.Filter(ErrorKindInvalidRange, ErrorKindInvalidLength)
+		Ω(filtered).Should(HaveLen(2))
+		Ω(filtered[0].(*ErrorResponse).Kind).Should(Equal("invalid_range"))
+		Ω(filtered[1].(*ErrorResponse).Kind).Should(Equal("invalid_length"))
+		Ω(merr.Filter(ErrorKindInvalidFormat)).Should(BeEmpty())
+	})
+
+	It("groups errors by field", func() {
+		groups := merr.GroupByField()
+		Ω(groups).Should(HaveLen(3))
+		Ω(groups["payload.name"]).Should(HaveLen(2))
+		Ω(groups["payload.age"]).Should(HaveLen(1))
+		Ω(groups[""]).Should(HaveLen(1))
+	})
+
+	Context("Status", func() {
+		It("is the status shared by the errors", func() {
+			Ω(merr.Status()).Should(Equal(400))
+		})
+
+		It("is 400 when the errors have different statuses", func() {
+			Ω(NewMultiError(ErrNotFound("nope"), ErrUnauthorized("who?")).Status()).Should(Equal(400))
+		})
+
+		It("is 500 when any error is internal", func() {
+			Ω(NewMultiError(ErrNotFound("nope"), errors.New("boom")).Status()).Should(Equal(500))
+		})
+
+		It("is 200 when there are no errors", func() {
+			Ω(MultiError(nil).Status()).Should(Equal(200))
+		})
+	})
+
+	Context("Response", func() {
+		It("merges the errors and lists them", func() {
+			resp := merr.Response()
+			Ω(resp.Status).Should(Equal(400))
+			Ω(resp.Code).Should(Equal("invalid_request"))
+			Ω(resp.Kind).Should(BeEmpty())
+			Ω(resp.Detail).Should(HavePrefix(`attribute "name" of raw is missing and required; `))
+			Ω(resp.Meta["errors"]).Should(HaveLen(4))
+		})
+
+		It("does not modify the errors", func() {
+			merr.Response()
+			Ω(merr[0].(*ErrorResponse).Detail).Should(Equal(`attribute "name" of raw is missing and required`))
+			Ω(merr[0].(*ErrorResponse).Meta).ShouldNot(HaveKey("errors"))
+		})
+
+		It("returns the single error", func() {
+			resp := NewMultiError(MissingParamError("id")).Response()
+			Ω(resp.Kind).Should(Equal("missing_param"))
+			Ω(resp.Meta).ShouldNot(HaveKey("errors"))
+		})
+
+		It("returns nil when there are no errors", func() {
+			Ω(MultiError(nil).Response()).Should(BeNil())
+		})
+	})
+})
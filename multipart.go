@@ -0,0 +1,351 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxFormValuesLength is the maximum total length of the values of a multipart form.
+const maxFormValuesLength = 10 << 20 // 10 MB
+
+// FormFile is a file uploaded in a multipart form. It is the Go type of the payload attributes
+// of type File, see the apidsl package MultipartForm function.
+type FormFile struct {
+	// Filename is the name of the file as given by the client.
+	Filename string
+	// Header is the MIME header of the form part holding the file.
+	Header textproto.MIMEHeader
+	// Size is the size of the file content in bytes.
+	Size int64
+
+	content   []byte // File content if held in memory
+	path      string // Path to the file holding the content otherwise
+	temporary bool   // Whether path is a temporary file created while decoding
+}
+
+var (
+	formFileType         = reflect.TypeOf(FormFile{})
+	formFilePtrType      = reflect.TypeOf(&FormFile{})
+	formFileSliceType    = reflect.TypeOf([]FormFile{})
+	formFilePtrSliceType = reflect.TypeOf([]*FormFile{})
+)
+
+// NewFormFile returns a form file with the given name and content. Clients use it to initialize
+// the File attributes of multipart payloads.
+func NewFormFile(filename string, content []byte) *FormFile {
+	return &FormFile{Filename: filename, Size: int64(len(content)), content: content}
+}
+
+// OpenFormFile returns a form file whose content is read from the file with the given path when
+// the form is encoded. Clients use it to initialize the File attributes of multipart payloads.
+func OpenFormFile(path string) (*FormFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	return &FormFile{Filename: info.Name(), Size: info.Size(), path: path}, nil
+}
+
+// Open returns a reader of the file content.
+func (f *FormFile) Open() (multipart.File, error) {
+	if f.path != "" {
+		return os.Open(f.path)
+	}
+	return sectionReadCloser{io.NewSectionReader(bytes.NewReader(f.content), 0, int64(len(f.content)))}, nil
+}
+
+// sectionReadCloser implements multipart.File for files held in memory.
+type sectionReadCloser struct {
+	*io.SectionReader
+}
+
+// Close does nothing.
+func (sectionReadCloser) Close() error { return nil }
+
+// DecodeMultipartForm decodes the "multipart/form-data" body of req into v which must be a
+// pointer to a struct. The form parts are decoded as they are read from the body: the values
+// are decoded into the struct fields whose "form" tags match the part names using the same
+// conventions as NewFormDecoder and the files are set in the fields of type FormFile, *FormFile,
+// []FormFile or []*FormFile. limits maps part names to the maximum size of their files in bytes,
+// decoding stops with a ErrRequestBodyTooLarge error as soon as a file exceeds its limit.
+//
+// The content of the files is held in memory up to MaxMultipartMemory bytes in total, the
+// content of the files that do not fit is written to temporary files. The temporary files are
+// removed once the request completes.
+func (service *Service) DecodeMultipartForm(ctx context.Context, req *http.Request, v interface{}, limits map[string]int64) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("multipart form must be decoded into a non nil pointer to a struct, got %T", v)
+	}
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return err
+	}
+	var (
+		values    = make(url.Values)
+		files     = make(map[string][]*FormFile)
+		memory    = service.MaxMultipartMemory
+		remaining = int64(maxFormValuesLength)
+		all       []*FormFile
+	)
+	defer func() {
+		if err != nil {
+			removeFormFiles(all)
+		} else if rd := ContextRequest(ctx); rd != nil {
+			rd.formFiles = append(rd.formFiles, all...)
+		}
+	}()
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+		if part.FileName() == "" {
+			var b bytes.Buffer
+			n, err := io.CopyN(&b, part, remaining+1)
+			if err != nil && err != io.EOF {
+				return err
+			}
+			if remaining -= n; remaining < 0 {
+				return ErrRequestBodyTooLarge(fmt.Sprintf("multipart form values exceed %d bytes", maxFormValuesLength))
+			}
+			values.Add(name, b.String())
+			continue
+		}
+		limit, ok := limits[name]
+		if !ok {
+			limit = -1
+		}
+		f, err := readFormFile(part, limit, &memory)
+		if f != nil {
+			all = append(all, f)
+		}
+		if err != nil {
+			return err
+		}
+		if limit >= 0 && f.Size > limit {
+			msg := fmt.Sprintf("file %#v exceeds the maximum size of %d bytes", name, limit)
+			return ErrRequestBodyTooLarge(msg, "attribute", name, "max", limit)
+		}
+		files[name] = append(files[name], f)
+	}
+	target := rv.Elem()
+	if err := decodeForm(values, "", target); err != nil {
+		return err
+	}
+	t := target.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := formFieldName(t.Field(i))
+		ffs, ok := files[name]
+		if name == "" || !ok {
+			continue
+		}
+		fv := target.Field(i)
+		switch fv.Type() {
+		case formFileType:
+			fv.Set(reflect.ValueOf(*ffs[0]))
+		case formFilePtrType:
+			fv.Set(reflect.ValueOf(ffs[0]))
+		case formFileSliceType:
+			s := make([]FormFile, len(ffs))
+			for j, f := range ffs {
+				s[j] = *f
+			}
+			fv.Set(reflect.ValueOf(s))
+		case formFilePtrSliceType:
+			fv.Set(reflect.ValueOf(ffs))
+		default:
+			return fmt.Errorf("invalid value for %s, expected %s got a file", name, fv.Type())
+		}
+	}
+	return nil
+}
+
+// readFormFile reads the file held in the given part. It reads at most limit+1 bytes if limit
+// is positive so that callers may detect files that are too large without reading them
+// entirely. It holds the content in memory if it fits in the remaining memory and writes it to
+// a temporary file otherwise.
+func readFormFile(part *multipart.Part, limit int64, memory *int64) (*FormFile, error) {
+	f := &FormFile{Filename: part.FileName(), Header: part.Header}
+	var r io.Reader = part
+	if limit >= 0 {
+		r = io.LimitReader(part, limit+1)
+	}
+	var b bytes.Buffer
+	n, err := io.CopyN(&b, r, *memory+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n <= *memory {
+		*memory -= n
+		f.content = b.Bytes()
+		f.Size = n
+		return f, nil
+	}
+	tmp, err := ioutil.TempFile("", "goa-multipart-")
+	if err != nil {
+		return nil, err
+	}
+	f.path = tmp.Name()
+	f.temporary = true
+	size, err := io.Copy(tmp, io.MultiReader(&b, r))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	f.Size = size
+	return f, err
+}
+
+// removeFormFiles removes the temporary files created while decoding multipart forms.
+func (r *RequestData) removeFormFiles() {
+	if r == nil {
+		return
+	}
+	removeFormFiles(r.formFiles)
+	r.formFiles = nil
+}
+
+// removeFormFiles removes the temporary files among the given form files.
+func removeFormFiles(files []*FormFile) {
+	for _, f := range files {
+		if f.temporary {
+			os.Remove(f.path)
+		}
+	}
+}
+
+// EncodeMultipartForm writes the "multipart/form-data" encoding of v to w and returns the
+// corresponding content type. v must be a struct or a pointer to a struct whose fields are
+// encoded using the names given by their "form" tags. The fields of type FormFile, *FormFile,
+// []FormFile or []*FormFile are encoded as files, the other fields are encoded as form values
+// using the same conventions as NewFormEncoder. The values are written before the files.
+func EncodeMultipartForm(w io.Writer, v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", fmt.Errorf("multipart form cannot encode nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("multipart form cannot encode %s", rv.Type())
+	}
+	var (
+		values = make(url.Values)
+		names  []string
+		files  = make(map[string][]*FormFile)
+	)
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := formFieldName(f)
+		if name == "" {
+			continue
+		}
+		fv := rv.Field(i)
+		var ffs []*FormFile
+		switch fv.Type() {
+		case formFileType:
+			ff := fv.Interface().(FormFile)
+			ffs = []*FormFile{&ff}
+		case formFilePtrType:
+			if !fv.IsNil() {
+				ffs = []*FormFile{fv.Interface().(*FormFile)}
+			}
+		case formFileSliceType:
+			for _, ff := range fv.Interface().([]FormFile) {
+				ff := ff
+				ffs = append(ffs, &ff)
+			}
+		case formFilePtrSliceType:
+			ffs = fv.Interface().([]*FormFile)
+		default:
+			if strings.Contains(f.Tag.Get("form"), ",omitempty") && isEmptyValue(fv) {
+				continue
+			}
+			if err := encodeFormValue(values, name, fv); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if len(ffs) > 0 {
+			names = append(names, name)
+			files[name] = ffs
+		}
+	}
+	mw := multipart.NewWriter(w)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, val := range values[k] {
+			if err := mw.WriteField(k, val); err != nil {
+				return "", err
+			}
+		}
+	}
+	for _, name := range names {
+		for _, f := range files[name] {
+			if err := writeFormFile(mw, name, f); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return mw.FormDataContentType(), nil
+}
+
+// writeFormFile writes a part holding the given file.
+func writeFormFile(mw *multipart.Writer, name string, f *FormFile) error {
+	contentType := "application/octet-stream"
+	if ct := f.Header.Get("Content-Type"); ct != "" {
+		contentType = ct
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(name), escapeQuotes(f.Filename)))
+	h.Set("Content-Type", contentType)
+	w, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// quoteEscaper escapes the quotes and backslashes of Content-Disposition parameters.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes the quotes and backslashes of s.
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,130 @@
+package goa_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multipart forms", func() {
+	type upload struct {
+		Title    *string         `form:"title,omitempty"`
+		Tags     []string        `form:"tags,omitempty"`
+		Document *goa.FormFile   `form:"document,omitempty"`
+		Extras   []*goa.FormFile `form:"extras,omitempty"`
+	}
+
+	var (
+		service *goa.Service
+		payload *upload
+		limits  map[string]int64
+		req     *http.Request
+	)
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		title := "report"
+		payload = &upload{
+			Title:    &title,
+			Tags:     []string{"a", "b"},
+			Document: goa.NewFormFile("report.pdf", []byte("%PDF-1.4")),
+			Extras:   []*goa.FormFile{goa.NewFormFile("a.txt", []byte("aaa")), goa.NewFormFile("b.txt", []byte("bbbb"))},
+		}
+		limits = nil
+	})
+
+	JustBeforeEach(func() {
+		var body bytes.Buffer
+		contentType, err := goa.EncodeMultipartForm(&body, payload)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(contentType).Should(HavePrefix("multipart/form-data; boundary="))
+		req, err = http.NewRequest("POST", "/uploads", &body)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Content-Type", contentType)
+	})
+
+	read := func(f *goa.FormFile) string {
+		r, err := f.Open()
+		Ω(err).ShouldNot(HaveOccurred())
+		defer r.Close()
+		b, err := ioutil.ReadAll(r)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("round trips values and files", func() {
+		var decoded upload
+		Ω(service.DecodeMultipartForm(context.Background(), req, &decoded, nil)).Should(Succeed())
+		Ω(*decoded.Title).Should(Equal("report"))
+		Ω(decoded.Tags).Should(Equal([]string{"a", "b"}))
+		Ω(decoded.Document.Filename).Should(Equal("report.pdf"))
+		Ω(decoded.Document.Size).Should(BeEquivalentTo(8))
+		Ω(read(decoded.Document)).Should(Equal("%PDF-1.4"))
+		Ω(decoded.Extras).Should(HaveLen(2))
+		Ω(read(decoded.Extras[1])).Should(Equal("bbbb"))
+	})
+
+	It("rejects targets that are not pointers to structs", func() {
+		var decoded map[string]interface{}
+		Ω(service.DecodeMultipartForm(context.Background(), req, &decoded, nil)).ShouldNot(Succeed())
+	})
+
+	Context("with files larger than their limit", func() {
+		BeforeEach(func() {
+			limits = map[string]int64{"extras": 3}
+		})
+
+		It("returns a request too large error", func() {
+			var decoded upload
+			err := service.DecodeMultipartForm(context.Background(), req, &decoded, limits)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).Status).Should(Equal(413))
+			Ω(err.(*goa.ErrorResponse).Meta).Should(HaveKeyWithValue("attribute", "extras"))
+		})
+	})
+
+	Context("with files that do not fit in memory", func() {
+		BeforeEach(func() {
+			service.MaxMultipartMemory = 4
+		})
+
+		It("writes them to temporary files removed once the request completes", func() {
+			var path string
+			ctrl := service.NewController("test")
+			unmarshal := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var decoded upload
+				if err := service.DecodeMultipartForm(ctx, req, &decoded, nil); err != nil {
+					return err
+				}
+				goa.ContextRequest(ctx).Payload = &decoded
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				decoded := goa.ContextRequest(ctx).Payload.(*upload)
+				r, err := decoded.Document.Open()
+				Ω(err).ShouldNot(HaveOccurred())
+				defer r.Close()
+				f, ok := r.(*os.File)
+				Ω(ok).Should(BeTrue())
+				path = f.Name()
+				Ω(read(decoded.Document)).Should(Equal("%PDF-1.4"))
+				Ω(read(decoded.Extras[0])).Should(Equal("aaa"))
+				return service.Send(ctx, 204, nil)
+			}
+			rw := httptest.NewRecorder()
+			ctrl.MuxHandler("upload", handler, unmarshal)(rw, req, url.Values{})
+			Ω(rw.Code).Should(Equal(204))
+			Ω(path).ShouldNot(BeEmpty())
+			_, err := os.Stat(path)
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+		})
+	})
+})
@@ -3,6 +3,8 @@ package goa
 import (
 	"net/http"
 	"net/url"
+	"strings"
+	"sync/atomic"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -46,6 +48,14 @@ type (
 	mux struct {
 		router  *httptreemux.TreeMux
 		handles map[string]MuxHandler
+		statics map[string]httptreemux.HandlerFunc // Handlers of the routes without wildcards
+		table   atomic.Value                       // Compiled statics, see compileRoutes
+	}
+
+	// routeCompiler is implemented by the muxes that prepare their routes when the service is
+	// compiled.
+	routeCompiler interface {
+		compileRoutes()
 	}
 )
 
@@ -56,6 +66,7 @@ func NewMux() ServeMux {
 	return &mux{
 		router:  r,
 		handles: make(map[string]MuxHandler),
+		statics: make(map[string]httptreemux.HandlerFunc),
 	}
 }
 
@@ -70,6 +81,21 @@ func (m *mux) Handle(method, path string, handle MuxHandler) {
 	}
 	m.handles[method+path] = handle
 	m.router.Handle(method, path, hthandle)
+	if !strings.ContainsAny(path, ":*") {
+		m.statics[method+" "+path] = hthandle
+		// Handlers mounted after the service was compiled invalidate the compiled table.
+		m.table.Store(map[string]httptreemux.HandlerFunc(nil))
+	}
+}
+
+// compileRoutes builds the table of the routes that do not define wildcards so that requests
+// made to these routes skip the trie search.
+func (m *mux) compileRoutes() {
+	table := make(map[string]httptreemux.HandlerFunc, len(m.statics))
+	for k, h := range m.statics {
+		table[k] = h
+	}
+	m.table.Store(table)
 }
 
 // HandleNotFound sets the MuxHandler invoked for requests that don't match any
@@ -97,5 +123,11 @@ func (m *mux) Lookup(method, path string) MuxHandler {
 
 // ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
 func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if table, _ := m.table.Load().(map[string]httptreemux.HandlerFunc); table != nil && req.URL.RawPath == "" {
+		if h, ok := table[req.Method+" "+req.URL.Path]; ok {
+			h(rw, req, nil)
+			return
+		}
+	}
 	m.router.ServeHTTP(rw, req)
 }
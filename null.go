@@ -0,0 +1,498 @@
+package goa
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The Null types hold the values of the attributes defined with the Nullable DSL. They distinguish
+// an attribute that is absent (Set is false) from an attribute explicitly set to null (Set is true
+// and Valid is false). The zero value is an absent attribute: the generated struct fields use the
+// "omitzero" JSON tag option and the generated types encode themselves with MarshalNullable so
+// that absent attributes are omitted when encoded while null attributes are encoded as null. The Null types also implement the database/sql Scanner and
+// driver Valuer interfaces so that they can be stored in nullable columns.
+type (
+	// NullString is a string that may be null.
+	NullString struct {
+		// String is the value, meaningful only when Valid is true.
+		String string
+		// Valid is true if the value is not null.
+		Valid bool
+		// Set is true if the value was set, possibly to null.
+		Set bool
+	}
+
+	// NullInt is an integer that may be null.
+	NullInt struct {
+		// Int is the value, meaningful only when Valid is true.
+		Int int
+		// Valid is true if the value is not null.
+		Valid bool
+		// Set is true if the value was set, possibly to null.
+		Set bool
+	}
+
+	// NullFloat64 is a number that may be null.
+	NullFloat64 struct {
+		// Float64 is the value, meaningful only when Valid is true.
+		Float64 float64
+		// Valid is true if the value is not null.
+		Valid bool
+		// Set is true if the value was set, possibly to null.
+		Set bool
+	}
+
+	// NullBool is a boolean that may be null.
+	NullBool struct {
+		// Bool is the value, meaningful only when Valid is true.
+		Bool bool
+		// Valid is true if the value is not null.
+		Valid bool
+		// Set is true if the value was set, possibly to null.
+		Set bool
+	}
+
+	// NullTime is a date time that may be null.
+	NullTime struct {
+		// Time is the value, meaningful only when Valid is true.
+		Time time.Time
+		// Valid is true if the value is not null.
+		Valid bool
+		// Set is true if the value was set, possibly to null.
+		Set bool
+	}
+)
+
+// nullJSON is the JSON encoding of null.
+var nullJSON = []byte("null")
+
+// NewNullString returns a NullString set to s.
+func NewNullString(s string) NullString {
+	return NullString{String: s, Valid: true, Set: true}
+}
+
+// NewNullInt returns a NullInt set to i.
+func NewNullInt(i int) NullInt {
+	return NullInt{Int: i, Valid: true, Set: true}
+}
+
+// NewNullFloat64 returns a NullFloat64 set to f.
+func NewNullFloat64(f float64) NullFloat64 {
+	return NullFloat64{Float64: f, Valid: true, Set: true}
+}
+
+// NewNullBool returns a NullBool set to b.
+func NewNullBool(b bool) NullBool {
+	return NullBool{Bool: b, Valid: true, Set: true}
+}
+
+// NewNullTime returns a NullTime set to t.
+func NewNullTime(t time.Time) NullTime {
+	return NullTime{Time: t, Valid: true, Set: true}
+}
+
+// Scan implements the database/sql Scanner interface.
+func (n *NullString) Scan(value interface{}) error {
+	var s sql.NullString
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	*n = NullString{String: s.String, Valid: s.Valid, Set: true}
+	return nil
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (n NullString) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.String, nil
+}
+
+// IsZero returns true if the value is absent.
+func (n NullString) IsZero() bool { return !n.Set && !n.Valid }
+
+// IsNull returns true if the value was explicitly set to null.
+func (n NullString) IsNull() bool { return n.Set && !n.Valid }
+
+// MarshalJSON encodes the value or null.
+func (n NullString) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return nullJSON, nil
+	}
+	return json.Marshal(n.String)
+}
+
+// UnmarshalJSON decodes the value or null and records that it was set.
+func (n *NullString) UnmarshalJSON(data []byte) error {
+	*n = NullString{Set: true}
+	if bytes.Equal(data, nullJSON) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.String); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (n *NullInt) Scan(value interface{}) error {
+	var s sql.NullInt64
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	*n = NullInt{Int: int(s.Int64), Valid: s.Valid, Set: true}
+	return nil
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (n NullInt) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return int64(n.Int), nil
+}
+
+// IsZero returns true if the value is absent.
+func (n NullInt) IsZero() bool { return !n.Set && !n.Valid }
+
+// IsNull returns true if the value was explicitly set to null.
+func (n NullInt) IsNull() bool { return n.Set && !n.Valid }
+
+// MarshalJSON encodes the value or null.
+func (n NullInt) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return nullJSON, nil
+	}
+	return json.Marshal(n.Int)
+}
+
+// UnmarshalJSON decodes the value or null and records that it was set.
+func (n *NullInt) UnmarshalJSON(data []byte) error {
+	*n = NullInt{Set: true}
+	if bytes.Equal(data, nullJSON) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Int); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (n *NullFloat64) Scan(value interface{}) error {
+	var s sql.NullFloat64
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	*n = NullFloat64{Float64: s.Float64, Valid: s.Valid, Set: true}
+	return nil
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (n NullFloat64) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Float64, nil
+}
+
+// IsZero returns true if the value is absent.
+func (n NullFloat64) IsZero() bool { return !n.Set && !n.Valid }
+
+// IsNull returns true if the value was explicitly set to null.
+func (n NullFloat64) IsNull() bool { return n.Set && !n.Valid }
+
+// MarshalJSON encodes the value or null.
+func (n NullFloat64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return nullJSON, nil
+	}
+	return json.Marshal(n.Float64)
+}
+
+// UnmarshalJSON decodes the value or null and records that it was set.
+func (n *NullFloat64) UnmarshalJSON(data []byte) error {
+	*n = NullFloat64{Set: true}
+	if bytes.Equal(data, nullJSON) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Float64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (n *NullBool) Scan(value interface{}) error {
+	var s sql.NullBool
+	if err := s.Scan(value); err != nil {
+		return err
+	}
+	*n = NullBool{Bool: s.Bool, Valid: s.Valid, Set: true}
+	return nil
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (n NullBool) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Bool, nil
+}
+
+// IsZero returns true if the value is absent.
+func (n NullBool) IsZero() bool { return !n.Set && !n.Valid }
+
+// IsNull returns true if the value was explicitly set to null.
+func (n NullBool) IsNull() bool { return n.Set && !n.Valid }
+
+// MarshalJSON encodes the value or null.
+func (n NullBool) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return nullJSON, nil
+	}
+	return json.Marshal(n.Bool)
+}
+
+// UnmarshalJSON decodes the value or null and records that it was set.
+func (n *NullBool) UnmarshalJSON(data []byte) error {
+	*n = NullBool{Set: true}
+	if bytes.Equal(data, nullJSON) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Bool); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Scan implements the database/sql Scanner interface.
+func (n *NullTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*n = NullTime{Set: true}
+	case time.Time:
+		*n = NewNullTime(v)
+	default:
+		return fmt.Errorf("cannot scan %T into NullTime", value)
+	}
+	return nil
+}
+
+// Value implements the database/sql/driver Valuer interface.
+func (n NullTime) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Time, nil
+}
+
+// IsZero returns true if the value is absent.
+func (n NullTime) IsZero() bool { return !n.Set && !n.Valid }
+
+// IsNull returns true if the value was explicitly set to null.
+func (n NullTime) IsNull() bool { return n.Set && !n.Valid }
+
+// MarshalJSON encodes the value or null.
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return nullJSON, nil
+	}
+	return json.Marshal(n.Time)
+}
+
+// UnmarshalJSON decodes the value or null and records that it was set.
+func (n *NullTime) UnmarshalJSON(data []byte) error {
+	*n = NullTime{Set: true}
+	if bytes.Equal(data, nullJSON) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &n.Time); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// MarshalNullable encodes v to JSON like json.Marshal but omits the struct fields whose JSON tag
+// uses the "omitzero" option and whose value is zero, e.g. the Null values that are absent. The
+// encoding/json package only implements the "omitzero" option as of Go 1.24, the MarshalJSON
+// methods generated for the types that define nullable attributes call MarshalNullable so that
+// absent attributes are omitted with all Go versions. The MarshalJSON methods pass a pointer to a
+// type defined from the receiver type so that MarshalNullable does not call them recursively. The
+// values nested in v that implement json.Marshaler are encoded with their MarshalJSON method and
+// the fields of embedded structs are encoded as regular fields.
+func MarshalNullable(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := marshalNullable(&buf, reflect.ValueOf(v), true); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalNullable writes the JSON encoding of v to buf. top is true for the value given to
+// MarshalNullable which is encoded even if it implements json.Marshaler.
+func marshalNullable(buf *bytes.Buffer, v reflect.Value, top bool) error {
+	if !v.IsValid() {
+		buf.Write(nullJSON)
+		return nil
+	}
+	if !top && implementsMarshaler(v) {
+		return writeJSON(buf, v)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.Write(nullJSON)
+			return nil
+		}
+		return marshalNullable(buf, v.Elem(), top && v.Kind() == reflect.Ptr)
+	case reflect.Struct:
+		return marshalNullableStruct(buf, v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return writeJSON(buf, v)
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := marshalNullable(buf, v.Index(i), false); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String || implementsMarshaler(reflect.Zero(v.Type().Key())) {
+			return writeJSON(buf, v)
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := marshalNullable(buf, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())), false); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	return writeJSON(buf, v)
+}
+
+// marshalNullableStruct writes the JSON object that encodes the exported fields of the struct v.
+func marshalNullableStruct(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteByte('{')
+	first := true
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+		if name == "" {
+			name = f.Name
+		}
+		fv := v.Field(i)
+		if (hasTagOption(opts, "omitzero") && isZeroValue(fv)) ||
+			(hasTagOption(opts, "omitempty") && isEmptyValue(fv)) {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := marshalNullable(buf, fv, false); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// implementsMarshaler returns true if v or a pointer to v encodes itself to JSON.
+func implementsMarshaler(v reflect.Value) bool {
+	t := v.Type()
+	if v.CanAddr() {
+		t = reflect.PtrTo(t)
+	}
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// writeJSON writes the encoding of v produced by json.Marshal to buf.
+func writeJSON(buf *bytes.Buffer, v reflect.Value) error {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// isZeroValue returns true if v is the zero value of its type or if it implements IsZero and the
+// method returns true, the semantic of the "omitzero" JSON tag option.
+func isZeroValue(v reflect.Value) bool {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if z, ok := v.Interface().(interface {
+		IsZero() bool
+	}); ok {
+		return z.IsZero()
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// hasTagOption returns true if the options of a struct tag include opt.
+func hasTagOption(opts []string, opt string) bool {
+	for _, o := range opts[1:] {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
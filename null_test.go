@@ -0,0 +1,95 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Null types", func() {
+	type payload struct {
+		Name  goa.NullString  `json:"name,omitzero"`
+		Count goa.NullInt     `json:"count,omitzero"`
+		Ratio goa.NullFloat64 `json:"ratio,omitzero"`
+		Done  goa.NullBool    `json:"done,omitzero"`
+		At    goa.NullTime    `json:"at,omitzero"`
+	}
+
+	It("distinguishes absent values from null values", func() {
+		var p payload
+		Ω(json.Unmarshal([]byte(`{"name":null,"count":3}`), &p)).ShouldNot(HaveOccurred())
+		Ω(p.Name.Set).Should(BeTrue())
+		Ω(p.Name.Valid).Should(BeFalse())
+		Ω(p.Name.IsNull()).Should(BeTrue())
+		Ω(p.Count).Should(Equal(goa.NewNullInt(3)))
+		Ω(p.Ratio.IsZero()).Should(BeTrue())
+		Ω(p.Ratio.IsNull()).Should(BeFalse())
+		Ω(p.Done.Set).Should(BeFalse())
+		Ω(p.At.Set).Should(BeFalse())
+	})
+
+	It("decodes values", func() {
+		var p payload
+		data := `{"name":"n","ratio":0.5,"done":false,"at":"2016-01-02T15:04:05Z"}`
+		Ω(json.Unmarshal([]byte(data), &p)).ShouldNot(HaveOccurred())
+		Ω(p.Name).Should(Equal(goa.NewNullString("n")))
+		Ω(p.Ratio).Should(Equal(goa.NewNullFloat64(0.5)))
+		Ω(p.Done).Should(Equal(goa.NewNullBool(false)))
+		Ω(p.At.Valid).Should(BeTrue())
+		Ω(p.At.Time.Equal(time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC))).Should(BeTrue())
+	})
+
+	It("rejects values of the wrong type", func() {
+		var p payload
+		Ω(json.Unmarshal([]byte(`{"count":"3"}`), &p)).Should(HaveOccurred())
+	})
+
+	It("scans and stores database values", func() {
+		var s goa.NullString
+		Ω(s.Scan(nil)).ShouldNot(HaveOccurred())
+		Ω(s.IsNull()).Should(BeTrue())
+		v, err := s.Value()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(BeNil())
+		var i goa.NullInt
+		Ω(i.Scan(int64(42))).ShouldNot(HaveOccurred())
+		Ω(i).Should(Equal(goa.NewNullInt(42)))
+		v, err = i.Value()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v).Should(Equal(int64(42)))
+		var t goa.NullTime
+		Ω(t.Scan("2016-01-02")).Should(HaveOccurred())
+	})
+
+	It("encodes values and null values", func() {
+		p := payload{Name: goa.NewNullString("n"), Count: goa.NullInt{Set: true}}
+		b, err := goa.MarshalNullable(&p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"name":"n","count":null}`))
+	})
+
+	It("omits the absent values of nested structs", func() {
+		type parent struct {
+			ID       int                `json:"id"`
+			Note     *string            `json:"note,omitempty"`
+			Child    *payload           `json:"child,omitempty"`
+			Children []*payload         `json:"children"`
+			Index    map[string]payload `json:"index,omitempty"`
+			At       time.Time          `json:"at"`
+		}
+		at := time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC)
+		p := parent{
+			ID:       1,
+			Child:    &payload{Done: goa.NewNullBool(true)},
+			Children: []*payload{{}, {Ratio: goa.NullFloat64{Set: true}}},
+			Index:    map[string]payload{"b": {Count: goa.NewNullInt(2)}, "a": {}},
+			At:       at,
+		}
+		b, err := goa.MarshalNullable(&p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"id":1,"child":{"done":true},"children":[{},{"ratio":null}],"index":{"a":{},"b":{"count":2}},"at":"2016-01-02T15:04:05Z"}`))
+	})
+})
@@ -0,0 +1,25 @@
+package goa
+
+import "encoding/json"
+
+// DecodeDiscriminator returns the value of the attribute name of the JSON object data. The code
+// generated for the types defined with the OneOf DSL uses it to identify the member type of the
+// values it decodes. It returns a validation error built with the context ctx, e.g. "request", if
+// data is not a JSON object or if the attribute is missing or is not a string. The generated code
+// reports these errors when validating the decoded value so that their context reflects the path
+// of the value in the request.
+func DecodeDiscriminator(data []byte, ctx, name string) (string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return "", InvalidAttributeTypeError(ctx, string(data), "object")
+	}
+	raw, ok := obj[name]
+	if !ok {
+		return "", MissingAttributeError(ctx, name)
+	}
+	var val string
+	if err := json.Unmarshal(raw, &val); err != nil {
+		return "", InvalidAttributeTypeError(ctx+"."+name, string(raw), "string")
+	}
+	return val, nil
+}
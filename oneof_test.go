@@ -0,0 +1,39 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecodeDiscriminator", func() {
+	It("returns the discriminator value", func() {
+		d, err := goa.DecodeDiscriminator([]byte(`{"kind":"cat","name":"Tom"}`), "request", "kind")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(d).Should(Equal("cat"))
+	})
+
+	It("rejects missing discriminators", func() {
+		_, err := goa.DecodeDiscriminator([]byte(`{"name":"Tom"}`), "request", "kind")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring(`attribute "kind" of request is missing and required`))
+	})
+
+	It("rejects discriminators that are not strings", func() {
+		_, err := goa.DecodeDiscriminator([]byte(`{"kind":1}`), "request", "kind")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("request.kind must be string"))
+	})
+
+	It("rejects values that are not objects", func() {
+		_, err := goa.DecodeDiscriminator([]byte(`[]`), "request", "kind")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("type of request must be object"))
+	})
+
+	It("reports errors in the context of the value once rewritten", func() {
+		_, err := goa.DecodeDiscriminator([]byte(`{"name":"Tom"}`), "request", "kind")
+		err = goa.WithErrorContext(err, "raw.pet")
+		Ω(err.(*goa.ErrorResponse).Meta["parent"]).Should(Equal("raw.pet"))
+	})
+})
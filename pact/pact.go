@@ -0,0 +1,359 @@
+/*
+Package pact implements consumer-driven contracts using the Pact specification version 2. The
+contracts are generated from the design examples with "goagen pact" and describe the requests
+made by the generated clients together with the responses they expect.
+
+The Verifier replays the interactions of a pact against a running service and reports the
+responses that do not satisfy the contract, e.g. in a provider test:
+
+	p, err := pact.LoadFile("pact/cellar-client-cellar.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &pact.Verifier{BaseURL: server.URL, StateHandler: setupState}
+	if err := v.Verify(context.Background(), p); err != nil {
+		t.Error(err)
+	}
+*/
+package pact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"context"
+)
+
+// SpecificationVersion is the version of the Pact specification implemented by this package.
+const SpecificationVersion = "2.0.0"
+
+type (
+	// Pact is a contract between a consumer and a provider.
+	Pact struct {
+		// Consumer is the consumer of the provider API.
+		Consumer Participant `json:"consumer"`
+		// Provider is the provider of the API.
+		Provider Participant `json:"provider"`
+		// Interactions lists the requests made by the consumer and the expected responses.
+		Interactions []*Interaction `json:"interactions"`
+		// Metadata contains the pact specification version.
+		Metadata map[string]interface{} `json:"metadata,omitempty"`
+	}
+
+	// Participant identifies a consumer or a provider.
+	Participant struct {
+		// Name of participant
+		Name string `json:"name"`
+	}
+
+	// Interaction is a request and the response expected by the consumer.
+	Interaction struct {
+		// Description describes the interaction.
+		Description string `json:"description"`
+		// ProviderState is the state the provider must be in before the request is made
+		// if any.
+		ProviderState string `json:"providerState,omitempty"`
+		// Request is the request made by the consumer.
+		Request *Request `json:"request"`
+		// Response is the response expected by the consumer.
+		Response *Response `json:"response"`
+	}
+
+	// Request describes a HTTP request.
+	Request struct {
+		// Method is the HTTP method.
+		Method string `json:"method"`
+		// Path is the request path.
+		Path string `json:"path"`
+		// Query is the encoded query string if any.
+		Query string `json:"query,omitempty"`
+		// Headers lists the request headers.
+		Headers map[string]string `json:"headers,omitempty"`
+		// Body is the request body if any.
+		Body interface{} `json:"body,omitempty"`
+	}
+
+	// Response describes the expected HTTP response.
+	Response struct {
+		// Status is the response status code.
+		Status int `json:"status"`
+		// Headers lists the expected response headers.
+		Headers map[string]string `json:"headers,omitempty"`
+		// Body is the expected response body if any.
+		Body interface{} `json:"body,omitempty"`
+		// MatchingRules lists the rules used to match the response indexed by path
+		// expression. The only supported rule is {"match": "type"} which, when applied to
+		// "$.body", matches the body and all its children by type rather than by value.
+		MatchingRules map[string]map[string]interface{} `json:"matchingRules,omitempty"`
+	}
+
+	// Verifier replays pact interactions against a running provider.
+	Verifier struct {
+		// BaseURL is the provider URL, e.g. "http://localhost:8080".
+		BaseURL string
+		// Client is the HTTP client used to make requests, http.DefaultClient if nil.
+		Client *http.Client
+		// StateHandler is called before the request of an interaction that defines a
+		// provider state is made. Interactions that define a provider state fail if
+		// StateHandler is nil.
+		StateHandler func(ctx context.Context, state string) error
+	}
+
+	// VerificationError is the error returned by Verify when interactions fail.
+	VerificationError struct {
+		// Failures lists the mismatches indexed by interaction description.
+		Failures map[string][]string
+	}
+)
+
+// Load reads a pact from r.
+func Load(r io.Reader) (*Pact, error) {
+	var p Pact
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LoadFile reads a pact from the file with the given path.
+func LoadFile(path string) (*Pact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Verify makes the request of each interaction and checks that the responses satisfy the pact.
+// It returns a *VerificationError if any interaction fails.
+func (v *Verifier) Verify(ctx context.Context, p *Pact) error {
+	failures := make(map[string][]string)
+	for _, i := range p.Interactions {
+		if msgs := v.verify(ctx, i); len(msgs) > 0 {
+			failures[i.Description] = msgs
+		}
+	}
+	if len(failures) > 0 {
+		return &VerificationError{Failures: failures}
+	}
+	return nil
+}
+
+// verify replays a single interaction and returns the list of mismatches.
+func (v *Verifier) verify(ctx context.Context, i *Interaction) []string {
+	if i.ProviderState != "" {
+		if v.StateHandler == nil {
+			return []string{fmt.Sprintf("no state handler for provider state %#v", i.ProviderState)}
+		}
+		if err := v.StateHandler(ctx, i.ProviderState); err != nil {
+			return []string{fmt.Sprintf("failed to set up provider state %#v: %s", i.ProviderState, err)}
+		}
+	}
+	req, err := i.Request.httpRequest(v.BaseURL)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return i.Response.Match(resp.StatusCode, resp.Header, body)
+}
+
+// httpRequest builds the HTTP request described by r.
+func (r *Request) httpRequest(baseURL string) (*http.Request, error) {
+	u := strings.TrimSuffix(baseURL, "/") + r.Path
+	if r.Query != "" {
+		u += "?" + r.Query
+	}
+	var body io.Reader
+	if r.Body != nil {
+		b, err := json.Marshal(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(r.Method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for n, val := range r.Headers {
+		req.Header.Set(n, val)
+	}
+	return req, nil
+}
+
+// Match returns the list of mismatches between the expected response and the given status,
+// headers and body. It returns nil if the response satisfies the pact.
+func (r *Response) Match(status int, header http.Header, body []byte) []string {
+	var msgs []string
+	if status != r.Status {
+		msgs = append(msgs, fmt.Sprintf("expected status %d, got %d", r.Status, status))
+	}
+	names := make([]string, 0, len(r.Headers))
+	for n := range r.Headers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		expected, actual := r.Headers[n], header.Get(n)
+		if http.CanonicalHeaderKey(n) == "Content-Type" {
+			expected, actual = mediaType(expected), mediaType(actual)
+		}
+		if expected != actual {
+			msgs = append(msgs, fmt.Sprintf("expected header %s to be %#v, got %#v", n, r.Headers[n], header.Get(n)))
+		}
+	}
+	if r.Body == nil {
+		return msgs
+	}
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return append(msgs, fmt.Sprintf("failed to decode body: %s", err))
+	}
+	byType := false
+	if rule, ok := r.MatchingRules["$.body"]; ok && rule["match"] == "type" {
+		byType = true
+	}
+	return append(msgs, match("$.body", normalize(r.Body), actual, byType)...)
+}
+
+// Error returns the list of failures.
+func (e *VerificationError) Error() string {
+	descs := make([]string, 0, len(e.Failures))
+	for d := range e.Failures {
+		descs = append(descs, d)
+	}
+	sort.Strings(descs)
+	var b bytes.Buffer
+	b.WriteString("pact verification failed:")
+	for _, d := range descs {
+		fmt.Fprintf(&b, "\n  %s:", d)
+		for _, msg := range e.Failures[d] {
+			fmt.Fprintf(&b, "\n    %s", msg)
+		}
+	}
+	return b.String()
+}
+
+// match compares the expected and actual values. Objects may contain keys that are not in the
+// expected value. If byType is true the values are compared by JSON type only and the elements of
+// actual arrays are all compared with the first expected element.
+func match(path string, expected, actual interface{}, byType bool) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %s", path, jsonType(actual))}
+		}
+		keys := make([]string, 0, len(e))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var msgs []string
+		for _, k := range keys {
+			av, ok := a[k]
+			if !ok {
+				msgs = append(msgs, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			msgs = append(msgs, match(path+"."+k, e[k], av, byType)...)
+		}
+		return msgs
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %s", path, jsonType(actual))}
+		}
+		var msgs []string
+		if byType {
+			if len(e) == 0 {
+				return nil
+			}
+			for i, av := range a {
+				msgs = append(msgs, match(fmt.Sprintf("%s[%d]", path, i), e[0], av, true)...)
+			}
+			return msgs
+		}
+		if len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: expected %d elements, got %d", path, len(e), len(a))}
+		}
+		for i := range e {
+			msgs = append(msgs, match(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], false)...)
+		}
+		return msgs
+	}
+	if byType {
+		if jsonType(expected) != jsonType(actual) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, jsonType(expected), jsonType(actual))}
+		}
+		return nil
+	}
+	if fmt.Sprint(expected) != fmt.Sprint(actual) || jsonType(expected) != jsonType(actual) {
+		return []string{fmt.Sprintf("%s: expected %#v, got %#v", path, expected, actual)}
+	}
+	return nil
+}
+
+// normalize converts v into the generic form produced by encoding/json so that it can be
+// compared with decoded values.
+func normalize(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var n interface{}
+	if err := json.Unmarshal(b, &n); err != nil {
+		return v
+	}
+	return n
+}
+
+// jsonType returns the name of the JSON type of the given decoded value.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// mediaType returns the media type of the given Content-Type header value without parameters.
+func mediaType(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ct
+	}
+	return mt
+}
@@ -0,0 +1,97 @@
+package pact_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"context"
+
+	"github.com/goadesign/goa/pact"
+)
+
+const contract = `{
+  "consumer": {"name": "cellar-client"},
+  "provider": {"name": "cellar"},
+  "interactions": [{
+    "description": "show bottle",
+    "providerState": "bottle 1 exists",
+    "request": {"method": "GET", "path": "/bottles/1"},
+    "response": {
+      "status": 200,
+      "headers": {"Content-Type": "application/vnd.bottle+json"},
+      "body": {"id": 42, "name": "example", "tags": ["red"]},
+      "matchingRules": {"$.body": {"match": "type"}}
+    }
+  }]
+}`
+
+func provider(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/bottles/1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.bottle+json; charset=utf-8")
+		w.Write([]byte(body))
+	}))
+}
+
+func TestVerify(t *testing.T) {
+	p, err := pact.Load(strings.NewReader(contract))
+	if err != nil {
+		t.Fatalf("failed to load pact: %s", err)
+	}
+	srv := provider(`{"id": 1, "name": "Number 8", "tags": ["red", "dry"], "vintage": 2012}`)
+	defer srv.Close()
+	var states []string
+	v := &pact.Verifier{
+		BaseURL: srv.URL,
+		StateHandler: func(_ context.Context, state string) error {
+			states = append(states, state)
+			return nil
+		},
+	}
+	if err := v.Verify(context.Background(), p); err != nil {
+		t.Errorf("unexpected error %s", err)
+	}
+	if len(states) != 1 || states[0] != "bottle 1 exists" {
+		t.Errorf("got provider states %v", states)
+	}
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	p, err := pact.Load(strings.NewReader(contract))
+	if err != nil {
+		t.Fatalf("failed to load pact: %s", err)
+	}
+	srv := provider(`{"id": "1", "tags": [1]}`)
+	defer srv.Close()
+	v := &pact.Verifier{BaseURL: srv.URL, StateHandler: func(context.Context, string) error { return nil }}
+	err = v.Verify(context.Background(), p)
+	verr, ok := err.(*pact.VerificationError)
+	if !ok {
+		t.Fatalf("got error %v, expected a *pact.VerificationError", err)
+	}
+	expected := []string{
+		"$.body.id: expected number, got string",
+		"$.body.name: missing",
+		"$.body.tags[0]: expected string, got number",
+	}
+	failures := verr.Failures["show bottle"]
+	if strings.Join(failures, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got failures %v, expected %v", failures, expected)
+	}
+}
+
+func TestMatchExact(t *testing.T) {
+	resp := &pact.Response{Status: 201, Body: map[string]interface{}{"id": 1}}
+	if msgs := resp.Match(201, http.Header{}, []byte(`{"id": 1, "href": "/bottles/1"}`)); len(msgs) != 0 {
+		t.Errorf("unexpected mismatches %v", msgs)
+	}
+	msgs := resp.Match(200, http.Header{}, []byte(`{"id": 2}`))
+	if len(msgs) != 2 {
+		t.Errorf("got mismatches %v, expected status and id mismatches", msgs)
+	}
+}
@@ -0,0 +1,57 @@
+package goa
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageLinks returns the value of the Link header (RFC 8288) of a response to a page paginated
+// request made to u. The header links to the first, previous, next and last pages given the
+// current page number (starting at 1), the number of results per page and the total number of
+// results. The other query string params of u are preserved.
+func PageLinks(u *url.URL, page, perPage, total int) string {
+	if perPage <= 0 {
+		return ""
+	}
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+	link := func(p int) string {
+		return pageLink(u, map[string]string{"page": strconv.Itoa(p), "per_page": strconv.Itoa(perPage)})
+	}
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, link(1))}
+	if page > 1 && page <= last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, link(page-1)))
+	}
+	if page < last {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, link(page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, link(last)))
+	return strings.Join(links, ", ")
+}
+
+// CursorLinks returns the value of the Link header (RFC 8288) of a response to a cursor
+// paginated request made to u. The header links to the next page identified by the given cursor,
+// it is empty if next is empty, i.e. if the response contains the last page. The other query
+// string params of u are preserved.
+func CursorLinks(u *url.URL, next string) string {
+	if next == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<%s>; rel="next"`, pageLink(u, map[string]string{"cursor": next}))
+}
+
+// pageLink returns the URL of the page obtained by overriding the query string params of u with
+// the given values.
+func pageLink(u *url.URL, params map[string]string) string {
+	l := *u
+	q := l.Query()
+	for n, v := range params {
+		q.Set(n, v)
+	}
+	l.RawQuery = q.Encode()
+	return l.String()
+}
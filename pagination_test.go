@@ -0,0 +1,47 @@
+package goa_test
+
+import (
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageLinks", func() {
+	var u *url.URL
+
+	BeforeEach(func() {
+		var err error
+		u, err = url.Parse("/bottles?page=2&per_page=10&sort=name")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("links to the neighbouring pages", func() {
+		Ω(goa.PageLinks(u, 2, 10, 35)).Should(Equal(
+			`</bottles?page=1&per_page=10&sort=name>; rel="first", ` +
+				`</bottles?page=1&per_page=10&sort=name>; rel="prev", ` +
+				`</bottles?page=3&per_page=10&sort=name>; rel="next", ` +
+				`</bottles?page=4&per_page=10&sort=name>; rel="last"`))
+	})
+
+	It("omits the previous page of the first page and the next page of the last page", func() {
+		Ω(goa.PageLinks(u, 1, 10, 5)).Should(Equal(
+			`</bottles?page=1&per_page=10&sort=name>; rel="first", ` +
+				`</bottles?page=1&per_page=10&sort=name>; rel="last"`))
+	})
+
+	It("does not modify the request URL", func() {
+		goa.PageLinks(u, 2, 10, 35)
+		Ω(u.String()).Should(Equal("/bottles?page=2&per_page=10&sort=name"))
+	})
+})
+
+var _ = Describe("CursorLinks", func() {
+	It("links to the next page", func() {
+		u, err := url.Parse("/bottles?per_page=10")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(goa.CursorLinks(u, "abc")).Should(Equal(`</bottles?cursor=abc&per_page=10>; rel="next"`))
+		Ω(goa.CursorLinks(u, "")).Should(BeEmpty())
+	})
+})
@@ -0,0 +1,109 @@
+package goa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// phoneRegion describes how the phone numbers of a region are written in national format.
+type phoneRegion struct {
+	// code is the country calling code.
+	code string
+	// trunk is the national prefix dropped when converting to the international format.
+	trunk string
+}
+
+// phoneRegions lists the country calling codes and national trunk prefixes of the supported
+// regions indexed by ISO 3166-1 alpha-2 region code.
+var phoneRegions = map[string]phoneRegion{
+	"AE": {"971", "0"}, "AR": {"54", "0"}, "AT": {"43", "0"}, "AU": {"61", "0"},
+	"BE": {"32", "0"}, "BG": {"359", "0"}, "BR": {"55", "0"}, "CA": {"1", "1"},
+	"CH": {"41", "0"}, "CL": {"56", ""}, "CN": {"86", "0"}, "CO": {"57", ""},
+	"CZ": {"420", ""}, "DE": {"49", "0"}, "DK": {"45", ""}, "EG": {"20", "0"},
+	"ES": {"34", ""}, "FI": {"358", "0"}, "FR": {"33", "0"}, "GB": {"44", "0"},
+	"GR": {"30", ""}, "HK": {"852", ""}, "HR": {"385", "0"}, "HU": {"36", "06"},
+	"ID": {"62", "0"}, "IE": {"353", "0"}, "IL": {"972", "0"}, "IN": {"91", "0"},
+	"IT": {"39", ""}, "JP": {"81", "0"}, "KE": {"254", "0"}, "KR": {"82", "0"},
+	"LU": {"352", ""}, "MA": {"212", "0"}, "MX": {"52", ""}, "MY": {"60", "0"},
+	"NG": {"234", "0"}, "NL": {"31", "0"}, "NO": {"47", ""}, "NZ": {"64", "0"},
+	"PE": {"51", "0"}, "PH": {"63", "0"}, "PK": {"92", "0"}, "PL": {"48", ""},
+	"PT": {"351", ""}, "RO": {"40", "0"}, "RS": {"381", "0"}, "RU": {"7", "8"},
+	"SA": {"966", "0"}, "SE": {"46", "0"}, "SG": {"65", ""}, "SK": {"421", "0"},
+	"TH": {"66", "0"}, "TR": {"90", "0"}, "TW": {"886", "0"}, "UA": {"380", "0"},
+	"US": {"1", "1"}, "VN": {"84", "0"}, "ZA": {"27", "0"},
+}
+
+// PhoneCallingCode returns the country calling code of the given ISO 3166-1 alpha-2 region, e.g.
+// "33" for "FR". It returns false if the region is not supported.
+func PhoneCallingCode(region string) (string, bool) {
+	r, ok := phoneRegions[region]
+	return r.code, ok
+}
+
+// NormalizePhoneNumber returns the E.164 representation of the phone number val, e.g.
+// "+33612345678" for "06 12 34 56 78" in region "FR". Spaces, dashes, dots, slashes and
+// parentheses are ignored. Numbers that start with "+" are in international format, the other
+// numbers are in the national format of region. The error is produced by InvalidPhoneNumberError
+// or by UnsupportedPhoneRegionError if val is in national format and region is empty or not
+// supported. ctx is used to build the error.
+func NormalizePhoneNumber(ctx, val, region string) (string, error) {
+	n, unsupported, err := normalizePhoneNumber(val, region)
+	if unsupported {
+		return "", UnsupportedPhoneRegionError(ctx, val, region)
+	}
+	if err != nil {
+		return "", InvalidPhoneNumberError(ctx, val, region, err)
+	}
+	return n, nil
+}
+
+// ValidatePhoneNumber returns an error if val is not a phone number in international format or in
+// the national format of region, see NormalizePhoneNumber.
+func ValidatePhoneNumber(ctx, val, region string) error {
+	_, err := NormalizePhoneNumber(ctx, val, region)
+	return err
+}
+
+// normalizePhoneNumber implements NormalizePhoneNumber, unsupported is true if val is in national
+// format and region is not supported.
+func normalizePhoneNumber(val, region string) (n string, unsupported bool, err error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '/', '(', ')':
+			return -1
+		}
+		return r
+	}, val)
+	international := strings.HasPrefix(digits, "+")
+	if international {
+		digits = digits[1:]
+	}
+	if digits == "" {
+		return "", false, fmt.Errorf("%q contains no digits", val)
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", false, fmt.Errorf("%q contains invalid character %q", val, r)
+		}
+	}
+	if !international {
+		if region == "" {
+			return "", false, fmt.Errorf("%q is not in international format", val)
+		}
+		r, ok := phoneRegions[region]
+		if !ok {
+			return "", true, fmt.Errorf("region %q is not supported", region)
+		}
+		if r.trunk != "" && strings.HasPrefix(digits, r.trunk) {
+			digits = digits[len(r.trunk):]
+		}
+		digits = r.code + digits
+	}
+	if digits[0] == '0' {
+		return "", false, fmt.Errorf("%q has an invalid country calling code", val)
+	}
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", false, fmt.Errorf("%q must have between 8 and 15 digits including the country calling code", val)
+	}
+	return "+" + digits, false, nil
+}
@@ -0,0 +1,104 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NormalizePhoneNumber", func() {
+	var val, region string
+	var n string
+	var err error
+
+	JustBeforeEach(func() {
+		n, err = goa.NormalizePhoneNumber("payload.phone", val, region)
+	})
+
+	Context("with a number in international format", func() {
+		BeforeEach(func() {
+			val = "+1 (415) 555-2671"
+			region = ""
+		})
+
+		It("normalizes the number", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(n).Should(Equal("+14155552671"))
+			Ω(goa.ValidateFormat(goa.FormatPhone, val)).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with a number in national format", func() {
+		BeforeEach(func() {
+			val = "06 12 34 56 78"
+			region = "FR"
+		})
+
+		It("uses the region calling code and drops the trunk prefix", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(n).Should(Equal("+33612345678"))
+			Ω(goa.ValidateFormat(goa.FormatPhone, val)).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a region with no trunk prefix", func() {
+		BeforeEach(func() {
+			val = "06 1234 5678"
+			region = "IT"
+		})
+
+		It("keeps the leading zero", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(n).Should(Equal("+390612345678"))
+		})
+	})
+
+	Context("with an invalid number", func() {
+		BeforeEach(func() {
+			val = "+1 555 CALL"
+			region = "US"
+		})
+
+		It("returns an invalid phone number error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).ErrorKind()).Should(Equal(goa.ErrorKindInvalidPhoneNumber))
+			Ω(err.Error()).Should(ContainSubstring("payload.phone"))
+		})
+	})
+
+	Context("with a number that is too long", func() {
+		BeforeEach(func() {
+			val = "+1234567890123456"
+			region = ""
+		})
+
+		It("returns an invalid phone number error", func() {
+			Ω(err.(*goa.ErrorResponse).ErrorKind()).Should(Equal(goa.ErrorKindInvalidPhoneNumber))
+		})
+	})
+
+	Context("with a national number and an unsupported region", func() {
+		BeforeEach(func() {
+			val = "0612345678"
+			region = "ZZ"
+		})
+
+		It("returns an unsupported region error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(*goa.ErrorResponse).ErrorKind()).Should(Equal(goa.ErrorKindUnsupportedPhoneRegion))
+			Ω(err.Error()).Should(ContainSubstring(`"ZZ"`))
+		})
+	})
+
+	Context("with an international number and an unsupported region", func() {
+		BeforeEach(func() {
+			val = "+33612345678"
+			region = "ZZ"
+		})
+
+		It("ignores the region", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(n).Should(Equal(val))
+		})
+	})
+})
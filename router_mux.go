@@ -0,0 +1,164 @@
+package goa
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type (
+	// RouterHandle is the request handler that RouterMux registers on the underlying router.
+	// The param function returns the value of the path parameter captured by the router under
+	// the given key.
+	RouterHandle func(rw http.ResponseWriter, req *http.Request, param func(key string) string)
+
+	// RouterRegistrar registers a RouterHandle on the underlying router for the given HTTP
+	// method and path. The path is expressed with the router syntax.
+	RouterRegistrar func(method, path string, handle RouterHandle)
+
+	// RouterSyntax describes how a router declares path parameters.
+	RouterSyntax struct {
+		// Param returns the path segment that captures the named parameter, e.g. ":id"
+		// or "{id}".
+		Param func(name string) string
+		// CatchAll returns the path segment that captures the named catch-all
+		// parameter, e.g. "*filepath" or "{filepath...}".
+		CatchAll func(name string) string
+		// CatchAllKey returns the key under which the router exposes the value of the
+		// named catch-all parameter, e.g. "*" for routers with anonymous catch-alls.
+		CatchAllKey func(name string) string
+	}
+
+	// RouterMux is a ServeMux that mounts the generated action handlers on a third party
+	// router. It makes it possible to serve a goa service from a router that is already used
+	// by an existing application.
+	RouterMux struct {
+		handler  http.Handler
+		syntax   RouterSyntax
+		register RouterRegistrar
+		handles  map[string]MuxHandler
+	}
+)
+
+var (
+	// HTTPRouterSyntax is the path syntax of the httprouter, httptreemux and gin routers.
+	HTTPRouterSyntax = RouterSyntax{
+		Param:       func(n string) string { return ":" + n },
+		CatchAll:    func(n string) string { return "*" + n },
+		CatchAllKey: func(n string) string { return n },
+	}
+
+	// ChiSyntax is the path syntax of the chi router.
+	ChiSyntax = RouterSyntax{
+		Param:       func(n string) string { return "{" + n + "}" },
+		CatchAll:    func(string) string { return "*" },
+		CatchAllKey: func(string) string { return "*" },
+	}
+
+	// EchoSyntax is the path syntax of the echo router.
+	EchoSyntax = RouterSyntax{
+		Param:       func(n string) string { return ":" + n },
+		CatchAll:    func(string) string { return "*" },
+		CatchAllKey: func(string) string { return "*" },
+	}
+
+	// ServeMuxSyntax is the path syntax of the net/http ServeMux patterns introduced in Go
+	// 1.22.
+	ServeMuxSyntax = RouterSyntax{
+		Param:       func(n string) string { return "{" + n + "}" },
+		CatchAll:    func(n string) string { return "{" + n + "...}" },
+		CatchAllKey: func(n string) string { return n },
+	}
+
+	// routerParamRegex captures the path parameters of a route.
+	routerParamRegex = regexp.MustCompile(`/(:|\*)([a-zA-Z0-9_]+)`)
+)
+
+// NewRouterMux returns a ServeMux that registers the handlers of the goa service on a third party
+// router. handler is the router itself, syntax describes its path syntax and register adds a
+// route to it. The following examples mount the service controllers on popular routers:
+//
+//	// chi
+//	r := chi.NewRouter()
+//	service.Mux = goa.NewRouterMux(r, goa.ChiSyntax, func(m, p string, h goa.RouterHandle) {
+//		r.MethodFunc(m, p, func(rw http.ResponseWriter, req *http.Request) {
+//			h(rw, req, func(k string) string { return chi.URLParam(req, k) })
+//		})
+//	})
+//
+//	// echo
+//	e := echo.New()
+//	service.Mux = goa.NewRouterMux(e, goa.EchoSyntax, func(m, p string, h goa.RouterHandle) {
+//		e.Add(m, p, func(c echo.Context) error { h(c.Response(), c.Request(), c.Param); return nil })
+//	})
+//
+//	// gin
+//	g := gin.New()
+//	service.Mux = goa.NewRouterMux(g, goa.HTTPRouterSyntax, func(m, p string, h goa.RouterHandle) {
+//		g.Handle(m, p, func(c *gin.Context) { h(c.Writer, c.Request, c.Param) })
+//	})
+//
+//	// net/http (Go 1.22 and later)
+//	sm := http.NewServeMux()
+//	service.Mux = goa.NewRouterMux(sm, goa.ServeMuxSyntax, func(m, p string, h goa.RouterHandle) {
+//		sm.HandleFunc(m+" "+p, func(rw http.ResponseWriter, req *http.Request) { h(rw, req, req.PathValue) })
+//	})
+//
+// The service Mux must be set before the controllers are mounted. Requests that do not match any
+// route are handled by the router: HandleNotFound and HandleMethodNotAllowed do nothing.
+func NewRouterMux(handler http.Handler, syntax RouterSyntax, register RouterRegistrar) *RouterMux {
+	return &RouterMux{
+		handler:  handler,
+		syntax:   syntax,
+		register: register,
+		handles:  make(map[string]MuxHandler),
+	}
+}
+
+// Handle registers the handler for the given method and path on the router. The path uses the goa
+// syntax and is translated to the router syntax.
+func (m *RouterMux) Handle(method, path string, handle MuxHandler) {
+	var names, keys []string
+	catchAll := -1
+	rpath := routerParamRegex.ReplaceAllStringFunc(path, func(w string) string {
+		name := w[2:]
+		names = append(names, name)
+		if w[1] == '*' {
+			catchAll = len(keys)
+			keys = append(keys, m.syntax.CatchAllKey(name))
+			return "/" + m.syntax.CatchAll(name)
+		}
+		keys = append(keys, name)
+		return "/" + m.syntax.Param(name)
+	})
+	m.handles[method+path] = handle
+	m.register(method, rpath, func(rw http.ResponseWriter, req *http.Request, param func(string) string) {
+		params := req.URL.Query()
+		for i, n := range names {
+			v := param(keys[i])
+			if i == catchAll {
+				// Some routers include the leading slash in catch-all values.
+				v = strings.TrimPrefix(v, "/")
+			}
+			params.Set(n, v)
+		}
+		handle(rw, req, params)
+	})
+}
+
+// HandleNotFound does nothing, requests that do not match any route are handled by the router.
+func (m *RouterMux) HandleNotFound(handle MuxHandler) {}
+
+// HandleMethodNotAllowed does nothing, requests that do not match any route are handled by the
+// router.
+func (m *RouterMux) HandleMethodNotAllowed(handle MethodNotAllowedHandler) {}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (m *RouterMux) Lookup(method, path string) MuxHandler {
+	return m.handles[method+path]
+}
+
+// ServeHTTP dispatches the request to the router.
+func (m *RouterMux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	m.handler.ServeHTTP(rw, req)
+}
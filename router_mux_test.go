@@ -0,0 +1,97 @@
+package goa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/dimfeld/httptreemux"
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RouterMux", func() {
+	var mux goa.ServeMux
+	var values url.Values
+
+	handle := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+		values = vals
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	BeforeEach(func() {
+		values = nil
+	})
+
+	Context("with a router using the httprouter syntax", func() {
+		BeforeEach(func() {
+			router := httptreemux.New()
+			mux = goa.NewRouterMux(router, goa.HTTPRouterSyntax, func(m, p string, h goa.RouterHandle) {
+				router.Handle(m, p, func(rw http.ResponseWriter, req *http.Request, params map[string]string) {
+					h(rw, req, func(k string) string { return params[k] })
+				})
+			})
+			mux.Handle("GET", "/accounts/:accountID/bottles/:id", handle)
+			mux.Handle("GET", "/files/*filepath", handle)
+		})
+
+		It("merges the path and query string parameters", func() {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/accounts/1/bottles/42?view=tiny", nil)
+			mux.ServeHTTP(rw, req)
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(values).Should(Equal(url.Values{
+				"accountID": {"1"},
+				"id":        {"42"},
+				"view":      {"tiny"},
+			}))
+		})
+
+		It("captures the catch-all parameters", func() {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/files/css/main.css", nil)
+			mux.ServeHTTP(rw, req)
+			Ω(rw.Code).Should(Equal(http.StatusOK))
+			Ω(values.Get("filepath")).Should(Equal("css/main.css"))
+		})
+
+		It("looks up the registered handlers", func() {
+			Ω(mux.Lookup("GET", "/files/*filepath")).ShouldNot(BeNil())
+			Ω(mux.Lookup("POST", "/files/*filepath")).Should(BeNil())
+		})
+
+		It("lets the router handle unknown routes", func() {
+			rw := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/unknown", nil)
+			mux.ServeHTTP(rw, req)
+			Ω(rw.Code).Should(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("with a router using anonymous catch-alls", func() {
+		var paths []string
+		var handles []goa.RouterHandle
+
+		BeforeEach(func() {
+			paths, handles = nil, nil
+			mux = goa.NewRouterMux(http.NotFoundHandler(), goa.ChiSyntax, func(m, p string, h goa.RouterHandle) {
+				paths = append(paths, m+" "+p)
+				handles = append(handles, h)
+			})
+			mux.Handle("GET", "/accounts/:accountID/files/*filepath", handle)
+		})
+
+		It("translates the path to the router syntax", func() {
+			Ω(paths).Should(Equal([]string{"GET /accounts/{accountID}/files/*"}))
+		})
+
+		It("reads the catch-all value from its router key", func() {
+			router := map[string]string{"accountID": "1", "*": "/a/b.txt"}
+			req, _ := http.NewRequest("GET", "/accounts/1/files/a/b.txt", nil)
+			handles[0](httptest.NewRecorder(), req, func(k string) string { return router[k] })
+			Ω(values.Get("accountID")).Should(Equal("1"))
+			Ω(values.Get("filepath")).Should(Equal("a/b.txt"))
+		})
+	})
+})
@@ -0,0 +1,209 @@
+/*
+Package saga implements a minimal coordinator for compensable multi-step operations. A saga runs
+its steps in order, if a step fails the steps that completed are compensated in reverse order.
+The state of the saga is saved after each transition using the optional Store so that long
+running business transactions can be inspected. Sagas cannot be resumed from the saved state, a
+saga interrupted by a crash must be compensated or completed by the application.
+
+Actions that declare a saga with the Saga DSL get a generated constructor that builds the saga
+from the step implementations provided by the controller, e.g.:
+
+	s := app.NewCreateOrderSaga(&app.CreateOrderSagaSteps{Reserve: reserve, Charge: charge}, store)
+	if err := s.Run(ctx, orderID); err != nil {
+		return err
+	}
+*/
+package saga
+
+import (
+	"fmt"
+
+	"context"
+)
+
+// Status is the status of a saga.
+type Status string
+
+const (
+	// StatusRunning is the status of a saga whose steps are being executed.
+	StatusRunning Status = "running"
+	// StatusCompleted is the status of a saga whose steps all completed.
+	StatusCompleted Status = "completed"
+	// StatusCompensating is the status of a saga whose completed steps are being compensated.
+	StatusCompensating Status = "compensating"
+	// StatusCompensated is the status of a saga whose completed steps were all compensated.
+	StatusCompensated Status = "compensated"
+	// StatusFailed is the status of a saga for which at least one compensation failed.
+	StatusFailed Status = "failed"
+)
+
+type (
+	// Step is a single step of a saga.
+	Step interface {
+		// Execute runs the step.
+		Execute(ctx context.Context) error
+		// Compensate undoes the effects of a successful Execute.
+		Compensate(ctx context.Context) error
+	}
+
+	// Funcs is a Step built from functions. Compensate may be nil for steps that have no
+	// side effects to undo.
+	Funcs struct {
+		ExecuteFunc    func(ctx context.Context) error
+		CompensateFunc func(ctx context.Context) error
+	}
+
+	// Store persists the state of sagas.
+	Store interface {
+		// Save records the given state.
+		Save(ctx context.Context, state *State) error
+	}
+
+	// State describes the progress of a saga run.
+	State struct {
+		// ID identifies the saga run.
+		ID string
+		// Name is the saga name.
+		Name string
+		// Status is the saga status.
+		Status Status
+		// Completed lists the names of the steps that completed and have not been
+		// compensated in execution order.
+		Completed []string
+		// Error is the message of the error that caused the saga to be compensated if any.
+		Error string
+	}
+
+	// Saga coordinates the execution of a sequence of steps.
+	Saga struct {
+		// Name is the saga name.
+		Name string
+		// Store persists the saga state if not nil.
+		Store Store
+		steps []*namedStep
+	}
+
+	// Error is the error returned by Run when a step fails.
+	Error struct {
+		// Step is the name of the step that failed.
+		Step string
+		// Err is the error returned by the step.
+		Err error
+		// Compensation contains the errors returned by the compensations indexed by step
+		// name.
+		Compensation map[string]error
+	}
+
+	// namedStep is a step and its name.
+	namedStep struct {
+		name string
+		step Step
+	}
+)
+
+// New creates a saga with the given name and store. Steps are added with Add.
+func New(name string, store Store) *Saga {
+	return &Saga{Name: name, Store: store}
+}
+
+// Add appends a step to the saga. Steps run in the order they are added.
+func (s *Saga) Add(name string, step Step) *Saga {
+	s.steps = append(s.steps, &namedStep{name: name, step: step})
+	return s
+}
+
+// Steps returns the names of the saga steps in execution order.
+func (s *Saga) Steps() []string {
+	names := make([]string, len(s.steps))
+	for i, st := range s.steps {
+		names[i] = st.name
+	}
+	return names
+}
+
+// Run executes the saga steps in order. If a step fails the steps that completed are compensated
+// in reverse order and Run returns an *Error. id identifies the run in the saved state. Run returns
+// an error without executing any step if a step has no implementation.
+func (s *Saga) Run(ctx context.Context, id string) error {
+	for _, st := range s.steps {
+		if st.step == nil {
+			return fmt.Errorf("saga %s: no implementation for step %s", s.Name, st.name)
+		}
+	}
+	state := &State{ID: id, Name: s.Name, Status: StatusRunning}
+	if err := s.save(ctx, state); err != nil {
+		return err
+	}
+	var completed []*namedStep
+	for _, st := range s.steps {
+		if err := st.step.Execute(ctx); err != nil {
+			state.Status = StatusCompensating
+			state.Error = err.Error()
+			if serr := s.save(ctx, state); serr != nil {
+				return serr
+			}
+			return s.compensate(ctx, state, completed, &Error{Step: st.name, Err: err})
+		}
+		completed = append(completed, st)
+		state.Completed = append(state.Completed, st.name)
+		if err := s.save(ctx, state); err != nil {
+			return err
+		}
+	}
+	state.Status = StatusCompleted
+	return s.save(ctx, state)
+}
+
+// compensate undoes the completed steps in reverse order.
+func (s *Saga) compensate(ctx context.Context, state *State, completed []*namedStep, serr *Error) error {
+	var remaining []string
+	for i := len(completed) - 1; i >= 0; i-- {
+		st := completed[i]
+		if err := st.step.Compensate(ctx); err != nil {
+			if serr.Compensation == nil {
+				serr.Compensation = make(map[string]error)
+			}
+			serr.Compensation[st.name] = err
+			remaining = append([]string{st.name}, remaining...)
+		}
+	}
+	state.Completed = remaining
+	state.Status = StatusCompensated
+	if len(serr.Compensation) > 0 {
+		state.Status = StatusFailed
+	}
+	if err := s.save(ctx, state); err != nil {
+		return err
+	}
+	return serr
+}
+
+// save records the state if the saga has a store.
+func (s *Saga) save(ctx context.Context, state *State) error {
+	if s.Store == nil {
+		return nil
+	}
+	return s.Store.Save(ctx, state)
+}
+
+// Execute calls ExecuteFunc.
+func (f *Funcs) Execute(ctx context.Context) error {
+	return f.ExecuteFunc(ctx)
+}
+
+// Compensate calls CompensateFunc if not nil.
+func (f *Funcs) Compensate(ctx context.Context) error {
+	if f.CompensateFunc == nil {
+		return nil
+	}
+	return f.CompensateFunc(ctx)
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("step %s failed: %s", e.Step, e.Err)
+	if len(e.Compensation) > 0 {
+		msg += fmt.Sprintf(" (%d compensation(s) failed)", len(e.Compensation))
+	}
+	return msg
+}
@@ -0,0 +1,94 @@
+package saga_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"context"
+
+	"github.com/goadesign/goa/saga"
+)
+
+type recordingStore struct {
+	states []saga.State
+}
+
+func (s *recordingStore) Save(_ context.Context, state *saga.State) error {
+	st := *state
+	st.Completed = append([]string(nil), state.Completed...)
+	s.states = append(s.states, st)
+	return nil
+}
+
+func step(name string, log *[]string, fail bool) saga.Step {
+	return &saga.Funcs{
+		ExecuteFunc: func(context.Context) error {
+			if fail {
+				return errors.New(name + " failed")
+			}
+			*log = append(*log, name)
+			return nil
+		},
+		CompensateFunc: func(context.Context) error {
+			*log = append(*log, "undo "+name)
+			return nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	var log []string
+	store := &recordingStore{}
+	s := saga.New("order", store).
+		Add("reserve", step("reserve", &log, false)).
+		Add("charge", step("charge", &log, false))
+	if err := s.Run(context.Background(), "1"); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !reflect.DeepEqual(log, []string{"reserve", "charge"}) {
+		t.Errorf("got steps %v", log)
+	}
+	if last := store.states[len(store.states)-1]; last.Status != saga.StatusCompleted {
+		t.Errorf("got status %s, expected %s", last.Status, saga.StatusCompleted)
+	}
+}
+
+func TestCompensation(t *testing.T) {
+	var log []string
+	store := &recordingStore{}
+	s := saga.New("order", store).
+		Add("reserve", step("reserve", &log, false)).
+		Add("charge", step("charge", &log, false)).
+		Add("ship", step("ship", &log, true))
+	err := s.Run(context.Background(), "1")
+	serr, ok := err.(*saga.Error)
+	if !ok {
+		t.Fatalf("got error %v, expected a *saga.Error", err)
+	}
+	if serr.Step != "ship" {
+		t.Errorf("got failed step %s, expected ship", serr.Step)
+	}
+	if !reflect.DeepEqual(log, []string{"reserve", "charge", "undo charge", "undo reserve"}) {
+		t.Errorf("got steps %v", log)
+	}
+	last := store.states[len(store.states)-1]
+	if last.Status != saga.StatusCompensated || len(last.Completed) != 0 {
+		t.Errorf("got status %s and completed steps %v, expected %s and none", last.Status, last.Completed, saga.StatusCompensated)
+	}
+}
+
+func TestMissingStep(t *testing.T) {
+	var log []string
+	store := &recordingStore{}
+	s := saga.New("order", store).
+		Add("reserve", step("reserve", &log, false)).
+		Add("charge", nil).
+		Add("ship", step("ship", &log, false))
+	if err := s.Run(context.Background(), "1"); err == nil {
+		t.Fatal("expected an error for a step with no implementation")
+	}
+	if len(log) != 0 || len(store.states) != 0 {
+		t.Errorf("got steps %v and states %v, expected no step to run", log, store.states)
+	}
+}
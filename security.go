@@ -27,6 +27,37 @@ func WithRequiredScopes(ctx context.Context, scopes []string) context.Context {
 	return context.WithValue(ctx, securityScopesKey, scopes)
 }
 
+// ContextGrantedScopes extracts the scopes granted to the caller from the given context. The
+// generated response helpers use the granted scopes to omit the attributes whose visibility is
+// restricted with the VisibleTo DSL.
+func ContextGrantedScopes(ctx context.Context) []string {
+	if s := ctx.Value(grantedScopesKey); s != nil {
+		return s.([]string)
+	}
+	return nil
+}
+
+// WithGrantedScopes builds a context containing the scopes granted to the caller. Security
+// middlewares call this function once the caller credentials have been validated. The JWT
+// middleware (middleware/security/jwt) records the scopes of the token claims, other schemes such
+// as OAuth2, API key or basic auth have no standard scope claim: their middlewares must call
+// WithGrantedScopes explicitly for VisibleTo restrictions to take effect.
+func WithGrantedScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, grantedScopesKey, scopes)
+}
+
+// HasScope returns true if granted contains at least one of the given scopes.
+func HasScope(granted []string, scopes ...string) bool {
+	for _, g := range granted {
+		for _, s := range scopes {
+			if g == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // OAuth2Security represents the `oauth2` security scheme. It is instantiated by the generated code
 // accordingly to the use of the different `*Security()` DSL functions and `Security()` in the
 // design.
@@ -3,6 +3,7 @@ package goa
 import (
 	"context"
 	"fmt"
+	"html/template"
 	"io"
 	"log"
 	"net"
@@ -24,6 +25,9 @@ type (
 	// resource actions. goagen generates global functions - one per resource - that make it
 	// possible to mount the corresponding controller onto a service. A service contains the
 	// middleware, not found handler, encoders and muxes shared by all its controllers.
+	//
+	// Service implements http.Handler so that it may be served by any HTTP server, for example
+	// a httptest.Server in tests or a sub-tree of an existing application server.
 	Service struct {
 		// Name of service used for logging, tracing etc.
 		Name string
@@ -39,9 +43,19 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
-
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+		// MaxMultipartMemory is the maximum number of bytes of uploaded file content held in
+		// memory when decoding multipart form payloads, the content of the files that do not
+		// fit is written to temporary files. Defaults to 32MB.
+		MaxMultipartMemory int64
+
+		middleware       []Middleware                           // Middleware chain
+		cancel           context.CancelFunc                     // Service context cancel signal trigger
+		stages           []*outputStage                         // Service output stages
+		actionStages     []*outputStage                         // Controller action output stages
+		htmlTemplates    map[htmlTemplateKey]*template.Template // HTML templates indexed by media type and view
+		validationPolicy ValidationPolicy                       // Policy deciding whether payloads are validated
+		compilers        []func()                               // Pending mount-time preparation steps
+		compileLock      sync.Mutex                             // Protects compilers
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -96,29 +110,26 @@ func New(name string) *Service {
 		cctx, cancel = context.WithCancel(ctx)
 		mux          = NewMux()
 		service      = &Service{
-			Name:    name,
-			Context: cctx,
-			Mux:     mux,
-			Server: &http.Server{
-				Handler: mux,
-			},
-			Decoder: NewHTTPDecoder(),
-			Encoder: NewHTTPEncoder(),
+			Name:               name,
+			Context:            cctx,
+			Mux:                mux,
+			Server:             &http.Server{},
+			Decoder:            NewHTTPDecoder(),
+			Encoder:            NewHTTPEncoder(),
+			MaxMultipartMemory: 32 << 20, // 32 MB
 
 			cancel: cancel,
 		}
 		notFoundHandler         Handler
 		methodNotAllowedHandler Handler
+		initNotFound            sync.Once
 	)
+	service.Server.Handler = service
 
-	// Setup default NotFound handler
-	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
-		if resp := ContextResponse(ctx); resp != nil && resp.Written() {
-			return
-		}
-		// Use closure to do lazy computation of middleware chain so all middlewares are
-		// registered.
-		if notFoundHandler == nil {
+	// Use closure to do lazy computation of middleware chain so all middlewares are
+	// registered.
+	buildNotFound := func() {
+		initNotFound.Do(func() {
 			notFoundHandler = func(_ context.Context, _ http.ResponseWriter, req *http.Request) error {
 				return ErrNotFound(req.URL.Path)
 			}
@@ -127,7 +138,16 @@ func New(name string) *Service {
 			for i := range chain {
 				notFoundHandler = chain[ml-i-1](notFoundHandler)
 			}
+		})
+	}
+	service.onCompile(buildNotFound)
+
+	// Setup default NotFound handler
+	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		if resp := ContextResponse(ctx); resp != nil && resp.Written() {
+			return
 		}
+		buildNotFound()
 		ctx := NewContext(service.Context, rw, req, params)
 		err := notFoundHandler(ctx, ContextResponse(ctx), req)
 		if !ContextResponse(ctx).Written() {
@@ -199,6 +219,7 @@ func (service *Service) LogError(msg string, keyvals ...interface{}) {
 
 // ListenAndServe starts a HTTP server and sets up a listener on the given host/port.
 func (service *Service) ListenAndServe(addr string) error {
+	service.Compile()
 	service.LogInfo("listen", "transport", "http", "addr", addr)
 	service.Server.Addr = addr
 	return service.Server.ListenAndServe()
@@ -206,6 +227,7 @@ func (service *Service) ListenAndServe(addr string) error {
 
 // ListenAndServeTLS starts a HTTPS server and sets up a listener on the given host/port.
 func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	service.Compile()
 	service.LogInfo("listen", "transport", "https", "addr", addr)
 	service.Server.Addr = addr
 	return service.Server.ListenAndServeTLS(certFile, keyFile)
@@ -213,9 +235,25 @@ func (service *Service) ListenAndServeTLS(addr, certFile, keyFile string) error
 
 // Serve accepts incoming HTTP connections on the listener l, invoking the service mux handler for each.
 func (service *Service) Serve(l net.Listener) error {
+	service.Compile()
 	return service.Server.Serve(l)
 }
 
+// ServeHTTP dispatches the request to the service mux. It makes it possible to serve the service
+// with any HTTP server without calling ListenAndServe:
+//
+//	srv := httptest.NewServer(service)
+//	defer srv.Close()
+//
+// or to mount it under a prefix of an existing server:
+//
+//	http.Handle("/api/", http.StripPrefix("/api", service))
+//
+// The service should be compiled with Compile prior to serving requests this way.
+func (service *Service) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	service.Mux.ServeHTTP(rw, req)
+}
+
 // NewController returns a controller for the given resource. This method is mainly intended for
 // use by the generated code. User code shouldn't have to call it directly.
 func (service *Service) NewController(name string) *Controller {
@@ -237,6 +275,10 @@ func (service *Service) Send(ctx context.Context, code int, body interface{}) er
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	body, err := service.transform(ctx, body)
+	if err != nil {
+		return err
+	}
 	r.WriteHeader(code)
 	return service.EncodeResponse(ctx, body)
 }
@@ -247,6 +289,12 @@ func (service *Service) ServeFiles(path, filename string) error {
 	return ctrl.ServeFiles(path, filename)
 }
 
+// ServeContent creates a "FileServer" controller and calls ServeContent on it.
+func (service *Service) ServeContent(path string, content *StaticContent) error {
+	ctrl := service.NewController("FileServer")
+	return ctrl.ServeContent(path, content)
+}
+
 // DecodeRequest uses the HTTP decoder to unmarshal the request body into the provided value based
 // on the request Content-Type header.
 func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
@@ -284,6 +332,17 @@ func (ctrl *Controller) ServeFiles(path, filename string) error {
 	return nil
 }
 
+// ServeContent replies to GET requests sent to path with the given static content. See
+// StaticContent for details.
+func (ctrl *Controller) ServeContent(path string, content *StaticContent) error {
+	if strings.ContainsAny(path, ":*") {
+		return fmt.Errorf("static content path may not include wildcards")
+	}
+	LogInfo(ctrl.Context, "mount content", "name", content.Name, "route", fmt.Sprintf("GET %s", path))
+	ctrl.Service.Mux.Handle("GET", path, ctrl.MuxHandler("serve", content.Handler(), nil))
+	return nil
+}
+
 // Use adds a middleware to the controller.
 // Service-wide middleware should be added via the Service Use method instead.
 func (ctrl *Controller) Use(m Middleware) {
@@ -300,9 +359,7 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	// registered.
 	var handler Handler
 	var initHandler sync.Once
-
-	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
-		// Build handler middleware chains on first invocation
+	build := func() {
 		initHandler.Do(func() {
 			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 				if !ContextResponse(ctx).Written() {
@@ -316,10 +373,19 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 				handler = chain[ml-i-1](handler)
 			}
 		})
+	}
+	ctrl.Service.onCompile(build)
+
+	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		// Build handler middleware chains on first invocation unless the service was compiled
+		build()
 
 		// Build context
 		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
 
+		// Remove the temporary files created when decoding multipart forms if any
+		defer ContextRequest(ctx).removeFormFiles()
+
 		// Protect against request bodies with unreasonable length
 		if ctrl.MaxRequestBodyLength > 0 {
 			req.Body = http.MaxBytesReader(rw, req.Body, ctrl.MaxRequestBodyLength)
@@ -331,7 +397,7 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 				if err.Error() == "http: request body too large" {
 					msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
 					err = ErrRequestBodyTooLarge(msg)
-				} else {
+				} else if e, ok := err.(*ErrorResponse); !ok || (e.Code != IntegrityErrorCode && e.Code != RequestTooLargeCode) {
 					err = ErrBadRequest(err)
 				}
 				ctx = WithError(ctx, err)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -108,6 +109,55 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("ServeHTTP", func() {
+		var srv *httptest.Server
+
+		BeforeEach(func() {
+			ctrl := s.NewController("test")
+			s.Mux.Handle("GET", "/foo", ctrl.MuxHandler("foo", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return s.Send(ctx, 200, "bar")
+			}, nil))
+			s.Compile()
+		})
+
+		JustBeforeEach(func() {
+			srv = httptest.NewServer(s)
+		})
+
+		AfterEach(func() {
+			srv.Close()
+		})
+
+		It("serves the requests with the service mux", func() {
+			resp, err := http.Get(srv.URL + "/foo")
+			Ω(err).ShouldNot(HaveOccurred())
+			defer resp.Body.Close()
+			b, _ := ioutil.ReadAll(resp.Body)
+			Ω(resp.StatusCode).Should(Equal(200))
+			Ω(string(b)).Should(Equal(`"bar"` + "\n"))
+		})
+
+		It("is the handler of the service server", func() {
+			Ω(s.Server.Handler).Should(BeIdenticalTo(s))
+		})
+
+		Context("with a mux set after creation", func() {
+			BeforeEach(func() {
+				s.Mux = goa.NewMux()
+				s.Mux.Handle("GET", "/baz", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+					rw.WriteHeader(http.StatusTeapot)
+				})
+			})
+
+			It("uses the new mux", func() {
+				resp, err := http.Get(srv.URL + "/baz")
+				Ω(err).ShouldNot(HaveOccurred())
+				resp.Body.Close()
+				Ω(resp.StatusCode).Should(Equal(http.StatusTeapot))
+			})
+		})
+	})
+
 	Describe("MaxRequestBodyLength", func() {
 		var rw *TestResponseWriter
 		var req *http.Request
@@ -0,0 +1,144 @@
+/*
+Package signedurl implements pre-signed URLs that grant time-limited access to specific actions
+without requiring the client to authenticate. A signed URL carries its expiry, optional claims and
+a HMAC-SHA256 signature computed over the request method, path and query string.
+
+Actions that accept signed URLs are flagged with the SignedURL DSL in the design. The generated
+code verifies the signature of requests made to these actions using the middleware mounted with
+the generated UseSignedURLMiddleware function:
+
+	app.UseSignedURLMiddleware(service, signedurl.New(secret))
+
+The generated client exposes a Sign method for each of these actions which produces the URL to
+share.
+*/
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// ExpiresParam is the name of the query string parameter that contains the URL expiry
+	// expressed as the number of seconds since the Unix epoch.
+	ExpiresParam = "X-Goa-Expires"
+
+	// ClaimsParam is the name of the query string parameter that contains the URL claims.
+	ClaimsParam = "X-Goa-Claims"
+
+	// SignatureParam is the name of the query string parameter that contains the URL
+	// signature.
+	SignatureParam = "X-Goa-Signature"
+)
+
+// ErrInvalidSignature is the error returned when a signed URL is expired or its signature does not
+// match.
+var ErrInvalidSignature = goa.NewErrorClass("invalid_signature", 403)
+
+// key is the private type used to key context values.
+type key int
+
+const claimsKey key = iota + 1
+
+// Sign adds the expiry, claims and signature query string parameters to u so that it may be used
+// to make requests with the given method until expires. claims may be nil.
+func Sign(secret []byte, method string, u *url.URL, expires time.Time, claims map[string]string) *url.URL {
+	signed := *u
+	values := signed.Query()
+	values.Del(SignatureParam)
+	values.Set(ExpiresParam, strconv.FormatInt(expires.Unix(), 10))
+	if len(claims) > 0 {
+		c := make(url.Values, len(claims))
+		for k, v := range claims {
+			c.Set(k, v)
+		}
+		values.Set(ClaimsParam, base64.RawURLEncoding.EncodeToString([]byte(c.Encode())))
+	} else {
+		values.Del(ClaimsParam)
+	}
+	values.Set(SignatureParam, signature(secret, method, signed.EscapedPath(), values))
+	signed.RawQuery = values.Encode()
+	return &signed
+}
+
+// IsSigned returns true if the request URL contains a signature.
+func IsSigned(req *http.Request) bool {
+	return req.URL.Query().Get(SignatureParam) != ""
+}
+
+// Verify checks that the request URL signature is valid and has not expired at time now. It
+// returns the URL claims.
+func Verify(secret []byte, req *http.Request, now time.Time) (map[string]string, error) {
+	values := req.URL.Query()
+	sig := values.Get(SignatureParam)
+	if sig == "" {
+		return nil, ErrInvalidSignature("missing URL signature")
+	}
+	values.Del(SignatureParam)
+	expected := signature(secret, req.Method, req.URL.EscapedPath(), values)
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return nil, ErrInvalidSignature("invalid URL signature")
+	}
+	expires, err := strconv.ParseInt(values.Get(ExpiresParam), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature("invalid URL expiry")
+	}
+	if now.Unix() > expires {
+		return nil, ErrInvalidSignature("URL expired", "expires", time.Unix(expires, 0).UTC())
+	}
+	claims := make(map[string]string)
+	if c := values.Get(ClaimsParam); c != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(c)
+		if err != nil {
+			return nil, ErrInvalidSignature("invalid URL claims")
+		}
+		parsed, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return nil, ErrInvalidSignature("invalid URL claims")
+		}
+		for k := range parsed {
+			claims[k] = parsed.Get(k)
+		}
+	}
+	return claims, nil
+}
+
+// New returns a middleware that verifies the signature of request URLs using the given secret.
+// The URL claims are stored in the request context and can be retrieved with ContextClaims.
+func New(secret []byte) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			claims, err := Verify(secret, req, time.Now())
+			if err != nil {
+				return err
+			}
+			return h(context.WithValue(ctx, claimsKey, claims), rw, req)
+		}
+	}
+}
+
+// ContextClaims returns the claims of the signed URL used to make the request, nil if there is
+// none.
+func ContextClaims(ctx context.Context) map[string]string {
+	if c := ctx.Value(claimsKey); c != nil {
+		return c.(map[string]string)
+	}
+	return nil
+}
+
+// signature computes the signature of the given method, path and query string values.
+func signature(secret []byte, method, path string, values url.Values) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + path + "\n" + values.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package signedurl_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/goadesign/goa/signedurl"
+)
+
+var secret = []byte("secret")
+
+func TestSignVerify(t *testing.T) {
+	u, _ := url.Parse("http://example.com/files/1?inline=true")
+	signed := signedurl.Sign(secret, "GET", u, time.Now().Add(time.Minute), map[string]string{"user": "joe"})
+	req, _ := http.NewRequest("GET", signed.String(), nil)
+	if !signedurl.IsSigned(req) {
+		t.Fatalf("expected %s to be signed", signed)
+	}
+	claims, err := signedurl.Verify(secret, req, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if claims["user"] != "joe" {
+		t.Errorf("got user claim %q, expected joe", claims["user"])
+	}
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	u, _ := url.Parse("http://example.com/files/1")
+	signed := signedurl.Sign(secret, "GET", u, time.Now().Add(time.Minute), nil)
+
+	cases := map[string]struct {
+		method string
+		url    string
+		secret []byte
+		now    time.Time
+	}{
+		"expired":      {"GET", signed.String(), secret, time.Now().Add(time.Hour)},
+		"wrong method": {"DELETE", signed.String(), secret, time.Now()},
+		"wrong path":   {"GET", "http://example.com/files/2?" + signed.RawQuery, secret, time.Now()},
+		"wrong secret": {"GET", signed.String(), []byte("other"), time.Now()},
+		"unsigned":     {"GET", u.String(), secret, time.Now()},
+	}
+	for n, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+		if _, err := signedurl.Verify(c.secret, req, c.now); err == nil {
+			t.Errorf("%s: expected an error", n)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package goa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStaticCacheControl is the Cache-Control header value used by StaticContent unless
+// overridden. It lets clients and proxies cache the content but forces them to revalidate it,
+// revalidation being cheap thanks to the strong ETag.
+const DefaultStaticCacheControl = "public, no-cache"
+
+// StaticContent is a file held in memory, typically compiled into the service binary, such as the
+// generated Swagger specification. The compressed representation and the ETags are computed once
+// when the content is created so that serving it only costs a write.
+type StaticContent struct {
+	// Name is the name of the file, used to log requests and in error messages.
+	Name string
+	// ContentType is the value of the Content-Type response header.
+	ContentType string
+	// CacheControl is the value of the Cache-Control response header, defaults to
+	// DefaultStaticCacheControl.
+	CacheControl string
+	// ModTime is the modification time used to set the Last-Modified header, ignored if zero.
+	ModTime time.Time
+
+	content  []byte
+	gzipped  []byte
+	etag     string
+	gzipEtag string
+}
+
+// NewStaticContent creates a static content with the given name, content type and content. It
+// compresses the content with gzip, the compressed representation is only served to clients that
+// accept it and only if it is smaller than the original.
+func NewStaticContent(name, contentType string, content []byte) *StaticContent {
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:16])
+	sc := &StaticContent{
+		Name:         name,
+		ContentType:  contentType,
+		CacheControl: DefaultStaticCacheControl,
+		content:      content,
+		etag:         `"` + hash + `"`,
+		gzipEtag:     `"` + hash + `-gzip"`,
+	}
+	var buf bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := gz.Write(content); err == nil && gz.Close() == nil && buf.Len() < len(content) {
+		sc.gzipped = buf.Bytes()
+	}
+	return sc
+}
+
+// ETag returns the strong ETag of the uncompressed content.
+func (sc *StaticContent) ETag() string {
+	return sc.etag
+}
+
+// Handler returns a handler that serves the content. The handler supports HEAD and range
+// requests and responds with 304 Not Modified to conditional requests whose ETag or modification
+// time match.
+func (sc *StaticContent) Handler() Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		h := rw.Header()
+		body, etag := sc.content, sc.etag
+		if sc.gzipped != nil {
+			h.Add("Vary", "Accept-Encoding")
+			if acceptsGzip(req) {
+				body, etag = sc.gzipped, sc.gzipEtag
+				h.Set("Content-Encoding", "gzip")
+			}
+		}
+		h.Set("ETag", etag)
+		if sc.ContentType != "" {
+			h.Set("Content-Type", sc.ContentType)
+		}
+		if sc.CacheControl != "" {
+			h.Set("Cache-Control", sc.CacheControl)
+		}
+		http.ServeContent(rw, req, sc.Name, sc.ModTime, bytes.NewReader(body))
+		return nil
+	}
+}
+
+// acceptsGzip returns true if the request Accept-Encoding header lists gzip with a non zero
+// quality value.
+func acceptsGzip(req *http.Request) bool {
+	for _, enc := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(enc, ";")
+		if strings.TrimSpace(parts[0]) != "gzip" {
+			continue
+		}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
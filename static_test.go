@@ -0,0 +1,118 @@
+package goa_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StaticContent", func() {
+	var content []byte
+	var sc *goa.StaticContent
+	var req *http.Request
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		content = []byte(`{"swagger":"2.0","info":{"title":"` + strings.Repeat("spec", 100) + `"}}`)
+		req, _ = http.NewRequest("GET", "/swagger.json", nil)
+		rw = httptest.NewRecorder()
+	})
+
+	JustBeforeEach(func() {
+		sc = goa.NewStaticContent("swagger.json", "application/json", content)
+		Ω(sc.Handler()(context.Background(), rw, req)).Should(Succeed())
+	})
+
+	It("serves the content with cache headers", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Body.Bytes()).Should(Equal(content))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal(goa.DefaultStaticCacheControl))
+		Ω(rw.Header().Get("ETag")).Should(Equal(sc.ETag()))
+		Ω(rw.Header().Get("ETag")).Should(MatchRegexp(`^"[0-9a-f]{32}"$`))
+		Ω(rw.Header().Get("Vary")).Should(Equal("Accept-Encoding"))
+		Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+	})
+
+	Context("with a client that accepts gzip", func() {
+		BeforeEach(func() {
+			req.Header.Set("Accept-Encoding", "deflate, gzip")
+		})
+
+		It("serves the compressed content", func() {
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+			Ω(rw.Header().Get("ETag")).ShouldNot(Equal(sc.ETag()))
+			gz, err := gzip.NewReader(bytes.NewReader(rw.Body.Bytes()))
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadAll(gz)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b).Should(Equal(content))
+		})
+	})
+
+	Context("with a client that refuses gzip", func() {
+		BeforeEach(func() {
+			req.Header.Set("Accept-Encoding", "gzip;q=0")
+		})
+
+		It("serves the uncompressed content", func() {
+			Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+			Ω(rw.Body.Bytes()).Should(Equal(content))
+		})
+	})
+
+	Context("with content that does not compress", func() {
+		BeforeEach(func() {
+			content = []byte("{}")
+			req.Header.Set("Accept-Encoding", "gzip")
+		})
+
+		It("serves the uncompressed content", func() {
+			Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+			Ω(rw.Header().Get("Vary")).Should(BeEmpty())
+			Ω(rw.Body.Bytes()).Should(Equal(content))
+		})
+	})
+
+	Context("with a matching If-None-Match header", func() {
+		BeforeEach(func() {
+			req.Header.Set("If-None-Match", goa.NewStaticContent("swagger.json", "application/json", content).ETag())
+		})
+
+		It("responds with 304 Not Modified", func() {
+			Ω(rw.Code).Should(Equal(304))
+			Ω(rw.Body.Len()).Should(BeZero())
+		})
+	})
+})
+
+var _ = Describe("ServeContent", func() {
+	var s *goa.Service
+
+	BeforeEach(func() {
+		s = goa.New("static")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+	})
+
+	It("mounts the content", func() {
+		Ω(s.ServeContent("/swagger.json", goa.NewStaticContent("swagger.json", "application/json", []byte("{}")))).Should(Succeed())
+		req, _ := http.NewRequest("GET", "/swagger.json", nil)
+		rw := httptest.NewRecorder()
+		s.Mux.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Body.String()).Should(Equal("{}"))
+	})
+
+	It("rejects paths with wildcards", func() {
+		Ω(s.ServeContent("/swagger/*file", goa.NewStaticContent("swagger.json", "application/json", nil))).ShouldNot(Succeed())
+	})
+})
@@ -0,0 +1,155 @@
+package goa
+
+import (
+	"fmt"
+	"mime"
+	"reflect"
+
+	"context"
+)
+
+type (
+	// OutputStage transforms a response body before it is encoded. The body is the value given
+	// to the response method of the action context, e.g. the *app.Bottle passed to OK, so that
+	// stages operate on typed values rather than raw bytes. Stages may modify the body in place
+	// or return a different value. Typical stages convert units, format values for the request
+	// locale or mask fields.
+	OutputStage func(ctx context.Context, body interface{}) (interface{}, error)
+
+	// outputStage is a registered stage and the responses it applies to.
+	outputStage struct {
+		stage      OutputStage
+		controller string
+		action     string
+		mimeTypes  []string
+	}
+)
+
+var (
+	ctxType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Transform registers an output stage that applies to the responses of all the service actions.
+// If mime types are given the stage only applies to the responses whose Content-Type matches one
+// of them. Stages run in the order they are registered, service stages run before controller
+// stages.
+func (service *Service) Transform(stage OutputStage, mimeTypes ...string) {
+	service.stages = append(service.stages, &outputStage{stage: stage, mimeTypes: mimeTypes})
+}
+
+// Transform registers an output stage that applies to the responses of the given controller
+// action. If mime types are given the stage only applies to the responses whose Content-Type
+// matches one of them.
+func (ctrl *Controller) Transform(action string, stage OutputStage, mimeTypes ...string) {
+	ctrl.Service.actionStages = append(ctrl.Service.actionStages, &outputStage{
+		stage:      stage,
+		controller: ctrl.Name,
+		action:     action,
+		mimeTypes:  mimeTypes,
+	})
+}
+
+// TypedStage builds an output stage from a function whose signature is
+//
+//	func(context.Context, T) (T, error)
+//
+// where T is the type of the response bodies transformed by the stage, e.g. *app.Bottle. The
+// stage leaves response bodies of other types unchanged. TypedStage panics if fn does not have
+// the expected signature. Example:
+//
+//	service.Transform(goa.TypedStage(func(ctx context.Context, b *app.Bottle) (*app.Bottle, error) {
+//		b.Price = toLocalCurrency(ctx, b.Price)
+//		return b, nil
+//	}))
+//
+func TypedStage(fn interface{}) OutputStage {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.NumOut() != 2 ||
+		t.In(0) != ctxType || t.Out(0) != t.In(1) || t.Out(1) != errorType {
+		panic(fmt.Sprintf("goa: invalid typed stage %s, signature must be func(context.Context, T) (T, error)", t))
+	}
+	bodyType := t.In(1)
+	return func(ctx context.Context, body interface{}) (interface{}, error) {
+		if body == nil || reflect.TypeOf(body) != bodyType {
+			return body, nil
+		}
+		res := v.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(body)})
+		if err, _ := res[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return res[0].Interface(), nil
+	}
+}
+
+// MaskStage returns an output stage that clears the given fields of the response bodies. The
+// fields are identified by their Go struct field names and are only cleared if they exist in
+// the body struct.
+func MaskStage(fields ...string) OutputStage {
+	return func(ctx context.Context, body interface{}) (interface{}, error) {
+		mask(reflect.ValueOf(body), fields)
+		return body, nil
+	}
+}
+
+// transform runs the output stages that apply to the response of the request in ctx.
+func (service *Service) transform(ctx context.Context, body interface{}) (interface{}, error) {
+	if len(service.stages) == 0 && len(service.actionStages) == 0 {
+		return body, nil
+	}
+	var contentType string
+	if resp := ContextResponse(ctx); resp != nil {
+		contentType, _, _ = mime.ParseMediaType(resp.Header().Get("Content-Type"))
+	}
+	ctrl, action := ContextController(ctx), ContextAction(ctx)
+	var err error
+	for _, stages := range [][]*outputStage{service.stages, service.actionStages} {
+		for _, s := range stages {
+			if s.controller != "" && (s.controller != ctrl || s.action != action) {
+				continue
+			}
+			if !s.matches(contentType) {
+				continue
+			}
+			if body, err = s.stage(ctx, body); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return body, nil
+}
+
+// matches returns true if the stage applies to responses with the given content type.
+func (s *outputStage) matches(contentType string) bool {
+	if len(s.mimeTypes) == 0 {
+		return true
+	}
+	for _, mt := range s.mimeTypes {
+		if mt == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// mask clears the given fields of the struct held by v or of the structs held by the elements
+// of v if v is a slice.
+func mask(v reflect.Value, fields []string) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			mask(v.Elem(), fields)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			mask(v.Index(i), fields)
+		}
+	case reflect.Struct:
+		for _, f := range fields {
+			if fv := v.FieldByName(f); fv.IsValid() && fv.CanSet() {
+				fv.Set(reflect.Zero(fv.Type()))
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package goa_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type transformedBottle struct {
+	Name   string  `json:"name"`
+	Price  float64 `json:"price,omitempty"`
+	Secret string  `json:"secret,omitempty"`
+}
+
+var _ = Describe("Transform", func() {
+	var s *goa.Service
+	var ctrl *goa.Controller
+	var action string
+	var contentType string
+	var rw *httptest.ResponseRecorder
+	var sendErr error
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		ctrl = s.NewController("BottleController")
+		action = "show"
+		contentType = "application/vnd.bottle+json"
+	})
+
+	JustBeforeEach(func() {
+		handler := func(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+			goa.ContextResponse(ctx).Header().Set("Content-Type", contentType)
+			sendErr = s.Send(ctx, 200, &transformedBottle{Name: "merlot", Price: 10, Secret: "s3cr3t"})
+			return sendErr
+		}
+		req, err := http.NewRequest("GET", "/bottles/1", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = httptest.NewRecorder()
+		ctrl.MuxHandler(action, handler, nil)(rw, req, nil)
+	})
+
+	Context("with service stages", func() {
+		BeforeEach(func() {
+			s.Transform(goa.MaskStage("Secret"))
+			s.Transform(goa.TypedStage(func(ctx context.Context, b *transformedBottle) (*transformedBottle, error) {
+				b.Price = b.Price * 2
+				return b, nil
+			}), "application/vnd.bottle+json")
+		})
+
+		It("transforms the response body before encoding", func() {
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Body.String()).Should(MatchJSON(`{"name":"merlot","price":20}`))
+		})
+
+		Context("and a response with a different content type", func() {
+			BeforeEach(func() {
+				contentType = "application/json"
+			})
+
+			It("only runs the stages that apply", func() {
+				Ω(rw.Body.String()).Should(MatchJSON(`{"name":"merlot","price":10}`))
+			})
+		})
+	})
+
+	Context("with a controller action stage", func() {
+		BeforeEach(func() {
+			ctrl.Transform("show", goa.MaskStage("Price"))
+		})
+
+		It("transforms the action responses", func() {
+			Ω(rw.Body.String()).Should(MatchJSON(`{"name":"merlot","secret":"s3cr3t"}`))
+		})
+
+		Context("and another action", func() {
+			BeforeEach(func() {
+				action = "list"
+			})
+
+			It("does not transform the response", func() {
+				Ω(rw.Body.String()).Should(MatchJSON(`{"name":"merlot","price":10,"secret":"s3cr3t"}`))
+			})
+		})
+	})
+
+	Context("with a failing stage", func() {
+		BeforeEach(func() {
+			s.Transform(func(context.Context, interface{}) (interface{}, error) {
+				return nil, errors.New("boom")
+			})
+		})
+
+		It("does not write the response", func() {
+			Ω(sendErr).Should(MatchError("boom"))
+			Ω(rw.Body.Len()).Should(BeZero())
+		})
+	})
+
+	It("rejects invalid typed stages", func() {
+		Ω(func() { goa.TypedStage(func(b *transformedBottle) error { return nil }) }).Should(Panic())
+	})
+})
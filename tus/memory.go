@@ -0,0 +1,97 @@
+package tus
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/uuid"
+)
+
+type (
+	// memoryStorage is a Storage that keeps uploads in memory.
+	memoryStorage struct {
+		sync.Mutex
+		uploads map[string]*memoryUpload
+	}
+
+	// memoryUpload holds the state and content of a single upload. Its lock serializes writes
+	// to the upload so that the storage lock is not held while reading request bodies.
+	memoryUpload struct {
+		sync.Mutex
+		upload  *Upload
+		content bytes.Buffer
+	}
+)
+
+// NewMemoryStorage returns a storage that keeps uploads in memory. It is intended for tests and
+// services that run as a single process and handle small uploads.
+func NewMemoryStorage() Storage {
+	return &memoryStorage{uploads: make(map[string]*memoryUpload)}
+}
+
+// Create creates a new upload.
+func (s *memoryStorage) Create(_ context.Context, length int64, metadata map[string]string) (*Upload, error) {
+	u := &Upload{ID: uuid.NewV4().String(), Length: length, Metadata: metadata}
+	s.Lock()
+	defer s.Unlock()
+	s.uploads[u.ID] = &memoryUpload{upload: u}
+	return u.dup(), nil
+}
+
+// Get returns the upload with the given ID.
+func (s *memoryStorage) Get(_ context.Context, id string) (*Upload, error) {
+	mu := s.lookup(id)
+	if mu == nil {
+		return nil, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return mu.upload.dup(), nil
+}
+
+// Write appends to the upload content if the upload offset is offset.
+func (s *memoryStorage) Write(_ context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	mu := s.lookup(id)
+	if mu == nil {
+		return 0, goa.ErrNotFound("upload not found", "id", id)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if offset != mu.upload.Offset {
+		return 0, ErrOffsetMismatch
+	}
+	n, err := mu.content.ReadFrom(io.LimitReader(r, mu.upload.Length-mu.upload.Offset))
+	mu.upload.Offset += n
+	return n, err
+}
+
+// Open returns a reader on the upload content.
+func (s *memoryStorage) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	mu := s.lookup(id)
+	if mu == nil {
+		return nil, goa.ErrNotFound("upload not found", "id", id)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	b := make([]byte, mu.content.Len())
+	copy(b, mu.content.Bytes())
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+// lookup returns the upload with the given ID, nil if there is none.
+func (s *memoryStorage) lookup(id string) *memoryUpload {
+	s.Lock()
+	defer s.Unlock()
+	return s.uploads[id]
+}
+
+// dup returns a copy of the upload.
+func (u *Upload) dup() *Upload {
+	d := *u
+	return &d
+}
@@ -0,0 +1,220 @@
+/*
+Package tus implements the server side of the tus resumable upload protocol, see
+https://tus.io/protocols/resumable-upload.html. It supports the core protocol and the creation
+extension.
+
+The code generated for actions that use the ResumableUpload DSL wraps the action handler with
+Handler. The handler creates uploads on POST requests, reports the upload offset on HEAD requests
+and appends data on PATCH requests. The action controller is invoked once an upload completes,
+the upload is available via the generated context Upload field. The storage used to persist
+uploads must be set in the service context:
+
+	service.Context = tus.WithStorage(service.Context, tus.NewMemoryStorage())
+*/
+package tus
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// Version is the version of the tus protocol implemented by this package.
+const Version = "1.0.0"
+
+// ErrOffsetMismatch is the error returned by Storage.Write when the offset sent by the client is
+// not the upload offset.
+var ErrOffsetMismatch = errors.New("upload offset mismatch")
+
+// IDParam is the name of the path parameter that identifies an upload.
+const IDParam = "upload_id"
+
+type (
+	// Upload describes an upload.
+	Upload struct {
+		// ID is the upload unique identifier.
+		ID string
+		// Length is the total length of the upload in bytes.
+		Length int64
+		// Offset is the number of bytes received so far.
+		Offset int64
+		// Metadata contains the key/value pairs sent by the client on creation.
+		Metadata map[string]string
+	}
+
+	// Storage persists uploads.
+	Storage interface {
+		// Create creates a new upload with the given length and metadata.
+		Create(ctx context.Context, length int64, metadata map[string]string) (*Upload, error)
+		// Get returns the upload with the given ID, nil if there is none.
+		Get(ctx context.Context, id string) (*Upload, error)
+		// Write appends the content read from r to the upload with the given ID and
+		// returns the number of bytes written. offset is the upload offset expected by the
+		// client, Write returns ErrOffsetMismatch if it differs from the upload offset. The
+		// offset check and the append must be atomic so that concurrent requests cannot
+		// both write at the same offset, and Write must not write past the upload length.
+		Write(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+		// Open returns a reader on the content of the upload with the given ID.
+		Open(ctx context.Context, id string) (io.ReadCloser, error)
+	}
+
+	// key is the private type used to key context values.
+	key int
+)
+
+const (
+	storageKey key = iota + 1
+	uploadKey
+)
+
+// Complete returns true if all the upload bytes have been received.
+func (u *Upload) Complete() bool {
+	return u.Offset >= u.Length
+}
+
+// WithStorage returns a context containing the given storage.
+func WithStorage(ctx context.Context, s Storage) context.Context {
+	return context.WithValue(ctx, storageKey, s)
+}
+
+// ContextStorage extracts the storage from the given context, nil if there is none.
+func ContextStorage(ctx context.Context) Storage {
+	if s := ctx.Value(storageKey); s != nil {
+		return s.(Storage)
+	}
+	return nil
+}
+
+// ContextUpload extracts the completed upload from the given context, nil if there is none.
+func ContextUpload(ctx context.Context) *Upload {
+	if u := ctx.Value(uploadKey); u != nil {
+		return u.(*Upload)
+	}
+	return nil
+}
+
+// Handler returns a handler that implements the tus protocol and invokes h once an upload is
+// complete. The upload is stored in the context given to h and can be retrieved with
+// ContextUpload.
+func Handler(h goa.Handler) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Tus-Resumable", Version)
+		if req.Method == "OPTIONS" {
+			rw.Header().Set("Tus-Version", Version)
+			rw.Header().Set("Tus-Extension", "creation")
+			rw.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		if v := req.Header.Get("Tus-Resumable"); v != Version {
+			rw.Header().Set("Tus-Version", Version)
+			rw.WriteHeader(http.StatusPreconditionFailed)
+			return nil
+		}
+		store := ContextStorage(ctx)
+		if store == nil {
+			return goa.ErrInternal("no tus storage in service context")
+		}
+		var (
+			upload *Upload
+			err    error
+		)
+		switch req.Method {
+		case "POST":
+			length, err := strconv.ParseInt(req.Header.Get("Upload-Length"), 10, 64)
+			if err != nil || length < 0 {
+				return goa.ErrBadRequest("invalid or missing Upload-Length header")
+			}
+			metadata, err := parseMetadata(req.Header.Get("Upload-Metadata"))
+			if err != nil {
+				return goa.ErrBadRequest(err)
+			}
+			if upload, err = store.Create(ctx, length, metadata); err != nil {
+				return err
+			}
+			rw.Header().Set("Location", strings.TrimSuffix(req.URL.Path, "/")+"/"+upload.ID)
+			if !upload.Complete() {
+				rw.WriteHeader(http.StatusCreated)
+				return nil
+			}
+		case "HEAD", "PATCH":
+			id := goa.ContextRequest(ctx).Params.Get(IDParam)
+			if upload, err = store.Get(ctx, id); err != nil {
+				return err
+			}
+			if upload == nil {
+				return goa.ErrNotFound("upload not found", "id", id)
+			}
+			if req.Method == "HEAD" {
+				rw.Header().Set("Cache-Control", "no-store")
+				rw.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+				rw.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+				rw.WriteHeader(http.StatusOK)
+				return nil
+			}
+			if req.Header.Get("Content-Type") != "application/offset+octet-stream" {
+				rw.WriteHeader(http.StatusUnsupportedMediaType)
+				return nil
+			}
+			offset, err := strconv.ParseInt(req.Header.Get("Upload-Offset"), 10, 64)
+			if err != nil {
+				rw.WriteHeader(http.StatusConflict)
+				return nil
+			}
+			n, err := store.Write(ctx, id, offset, req.Body)
+			if err == ErrOffsetMismatch {
+				rw.WriteHeader(http.StatusConflict)
+				return nil
+			}
+			upload.Offset = offset + n
+			if err != nil {
+				return err
+			}
+			rw.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+			if !upload.Complete() {
+				rw.WriteHeader(http.StatusNoContent)
+				return nil
+			}
+		default:
+			return goa.MethodNotAllowedError(req.Method, []string{"OPTIONS", "POST", "HEAD", "PATCH"})
+		}
+		if err := h(context.WithValue(ctx, uploadKey, upload), rw, req); err != nil {
+			return err
+		}
+		if !goa.ContextResponse(ctx).Written() {
+			rw.WriteHeader(http.StatusNoContent)
+		}
+		return nil
+	}
+}
+
+// parseMetadata parses the value of the Upload-Metadata header.
+func parseMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if header == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		elems := strings.Fields(pair)
+		switch len(elems) {
+		case 1:
+			metadata[elems[0]] = ""
+		case 2:
+			v, err := base64.StdEncoding.DecodeString(elems[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid Upload-Metadata value for key %#v: %s", elems[0], err)
+			}
+			metadata[elems[0]] = string(v)
+		default:
+			return nil, fmt.Errorf("invalid Upload-Metadata header %#v", header)
+		}
+	}
+	return metadata, nil
+}
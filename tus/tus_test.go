@@ -0,0 +1,194 @@
+package tus_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/tus"
+)
+
+func TestUpload(t *testing.T) {
+	service := goa.New("test")
+	store := tus.NewMemoryStorage()
+	var completed *tus.Upload
+	h := tus.Handler(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		completed = tus.ContextUpload(ctx)
+		return nil
+	})
+	do := func(method, p string, params url.Values, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, p, strings.NewReader(body))
+		req.Header.Set("Tus-Resumable", tus.Version)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(tus.WithStorage(service.Context, store), rw, req, params)
+		if err := h(ctx, goa.ContextResponse(ctx), req); err != nil {
+			t.Fatalf("%s %s: unexpected error %s", method, p, err)
+		}
+		return rw
+	}
+
+	rw := do("POST", "/uploads", nil, "", map[string]string{
+		"Upload-Length":   "11",
+		"Upload-Metadata": "filename aGVsbG8udHh0",
+	})
+	if rw.Code != http.StatusCreated {
+		t.Fatalf("POST: got status %d, expected 201", rw.Code)
+	}
+	id := path.Base(rw.Header().Get("Location"))
+	params := url.Values{tus.IDParam: []string{id}}
+
+	rw = do("PATCH", "/uploads/"+id, params, "hello ", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "0",
+	})
+	if rw.Code != http.StatusNoContent || rw.Header().Get("Upload-Offset") != "6" {
+		t.Fatalf("PATCH: got status %d and offset %q, expected 204 and 6", rw.Code, rw.Header().Get("Upload-Offset"))
+	}
+	if completed != nil {
+		t.Fatalf("handler invoked before upload completed")
+	}
+
+	rw = do("HEAD", "/uploads/"+id, params, "", nil)
+	if rw.Header().Get("Upload-Offset") != "6" {
+		t.Errorf("HEAD: got offset %q, expected 6", rw.Header().Get("Upload-Offset"))
+	}
+
+	rw = do("PATCH", "/uploads/"+id, params, "world", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "0",
+	})
+	if rw.Code != http.StatusConflict {
+		t.Errorf("PATCH with invalid offset: got status %d, expected 409", rw.Code)
+	}
+
+	do("PATCH", "/uploads/"+id, params, "world", map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": "6",
+	})
+	if completed == nil {
+		t.Fatalf("handler not invoked after upload completed")
+	}
+	if completed.Metadata["filename"] != "hello.txt" {
+		t.Errorf("got filename metadata %q, expected hello.txt", completed.Metadata["filename"])
+	}
+	r, err := store.Open(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(r)
+	if string(content) != "hello world" {
+		t.Errorf("got content %q, expected %q", content, "hello world")
+	}
+}
+
+func TestMissingVersion(t *testing.T) {
+	h := tus.Handler(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	req := httptest.NewRequest("POST", "/uploads", nil)
+	rw := httptest.NewRecorder()
+	ctx := goa.NewContext(context.Background(), rw, req, nil)
+	if err := h(ctx, goa.ContextResponse(ctx), req); err != nil {
+		t.Fatal(err)
+	}
+	if rw.Code != http.StatusPreconditionFailed {
+		t.Errorf("got status %d, expected 412", rw.Code)
+	}
+}
+
+func TestMemoryStorageUnknownUpload(t *testing.T) {
+	store := tus.NewMemoryStorage()
+	ctx := context.Background()
+	if _, err := store.Write(ctx, "unknown", 0, strings.NewReader("foo")); err == nil {
+		t.Error("expected an error when writing to an unknown upload")
+	}
+	if _, err := store.Open(ctx, "unknown"); err == nil {
+		t.Error("expected an error when opening an unknown upload")
+	}
+	u, err := store.Create(ctx, 3, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if n, err := store.Write(ctx, u.ID, 0, strings.NewReader("foo")); err != nil || n != 3 {
+		t.Fatalf("got %d, %v, expected 3, nil", n, err)
+	}
+	got, _ := store.Get(ctx, u.ID)
+	if got.Offset != 3 {
+		t.Errorf("got offset %d, expected 3", got.Offset)
+	}
+}
+
+// blockingReader signals started on its first read and blocks until release is closed.
+type blockingReader struct {
+	r       io.Reader
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		close(b.started)
+		<-b.release
+	})
+	return b.r.Read(p)
+}
+
+func TestConcurrentPatch(t *testing.T) {
+	service := goa.New("test")
+	store := tus.NewMemoryStorage()
+	h := tus.Handler(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	u, err := store.Create(context.Background(), 20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := url.Values{tus.IDParam: []string{u.ID}}
+	patch := func(body io.Reader) int {
+		req := httptest.NewRequest("PATCH", "/uploads/"+u.ID, body)
+		req.Header.Set("Tus-Resumable", tus.Version)
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", "0")
+		rw := httptest.NewRecorder()
+		ctx := goa.NewContext(tus.WithStorage(service.Context, store), rw, req, params)
+		if err := h(ctx, goa.ContextResponse(ctx), req); err != nil {
+			t.Errorf("PATCH: unexpected error %s", err)
+		}
+		return rw.Code
+	}
+
+	first := &blockingReader{r: strings.NewReader("hello"), started: make(chan struct{}), release: make(chan struct{})}
+	codes := make(chan int, 2)
+	go func() { codes <- patch(first) }()
+	<-first.started
+	go func() { codes <- patch(strings.NewReader("world")) }()
+	// Give the second request a chance to reach the storage while the first one is writing.
+	time.Sleep(20 * time.Millisecond)
+	close(first.release)
+	c1, c2 := <-codes, <-codes
+	if c1+c2 != http.StatusNoContent+http.StatusConflict {
+		t.Errorf("got statuses %d and %d, expected 204 and 409", c1, c2)
+	}
+	r, err := store.Open(context.Background(), u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(r)
+	if string(content) != "hello" {
+		t.Errorf("got content %q, expected %q", content, "hello")
+	}
+}
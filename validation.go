@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goadesign/goa/uuid"
@@ -51,6 +52,12 @@ const (
 
 	// FormatRFC1123 defines RFC1123 date time values.
 	FormatRFC1123 = "rfc1123"
+
+	// FormatCurrency defines ISO 4217 currency codes.
+	FormatCurrency = "currency"
+
+	// FormatPhone defines ITU-T E.164 phone numbers in international format, e.g. "+14155552671".
+	FormatPhone = "phone"
 )
 
 var (
@@ -76,6 +83,10 @@ var (
 //     - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 //     - "regexp": Regular expression syntax accepted by RE2
 //     - "rfc1123": RFC1123 date time value
+//     - "currency": ISO 4217 currency code
+//     - "phone": ITU-T E.164 phone number in international format
+//
+// Custom formats registered with RegisterFormat are validated by their validator.
 func ValidateFormat(f Format, val string) error {
 	var err error
 	switch f {
@@ -115,8 +126,24 @@ func ValidateFormat(f Format, val string) error {
 		_, err = regexp.Compile(val)
 	case FormatRFC1123:
 		_, err = time.Parse(time.RFC1123, val)
+	case FormatCurrency:
+		if _, ok := CurrencyExponent(val); !ok {
+			err = fmt.Errorf("\"%s\" is not an ISO 4217 currency code", val)
+		}
+	case FormatPhone:
+		_, _, err = normalizePhoneNumber(val, "")
 	default:
-		return fmt.Errorf("unknown format %#v", f)
+		formats, _ := compiledFormats.Load().(map[Format]func(string) error)
+		validator, ok := formats[f]
+		if !ok {
+			customFormatsLock.RLock()
+			validator, ok = customFormats[f]
+			customFormatsLock.RUnlock()
+		}
+		if !ok {
+			return fmt.Errorf("unknown format %#v", f)
+		}
+		err = validator(val)
 	}
 	if err != nil {
 		go IncrCounter([]string{"goa", "validation", "error", string(f)}, 1.0)
@@ -125,20 +152,109 @@ func ValidateFormat(f Format, val string) error {
 	return nil
 }
 
-// knownPatterns records the compiled patterns.
-// TBD: refactor all this so that the generated code initializes the map on start to get rid of the
-// need for a RW mutex.
+// standardFormats lists the formats validated by ValidateFormat out of the box.
+var standardFormats = map[Format]bool{
+	FormatDateTime: true, FormatUUID: true, FormatEmail: true, FormatHostname: true,
+	FormatIPv4: true, FormatIPv6: true, FormatIP: true, FormatURI: true, FormatMAC: true,
+	FormatCIDR: true, FormatRegexp: true, FormatRFC1123: true, FormatCurrency: true,
+	FormatPhone: true,
+}
+
+// customFormats records the formats registered with RegisterFormat.
+var customFormats = make(map[Format]func(string) error)
+
+// customFormatsLock is the mutex used to access customFormats
+var customFormatsLock = &sync.RWMutex{}
+
+// RegisterFormat registers a custom format validated by validator, see the design package
+// RegisterFormat function. validator returns an error if the given value does not conform to the
+// format. The format must be registered before the service handles requests, typically in an init
+// function. Standard formats cannot be overridden.
+func RegisterFormat(name string, validator func(string) error) {
+	if standardFormats[Format(name)] {
+		return
+	}
+	customFormatsLock.Lock()
+	defer customFormatsLock.Unlock()
+	customFormats[Format(name)] = validator
+}
+
+// IsKnownFormat returns true if ValidateFormat knows how to validate values of format f, that is
+// if f is a standard format or was registered with RegisterFormat.
+func IsKnownFormat(f Format) bool {
+	if standardFormats[f] {
+		return true
+	}
+	customFormatsLock.RLock()
+	defer customFormatsLock.RUnlock()
+	_, ok := customFormats[f]
+	return ok
+}
+
+// knownPatterns records the compiled patterns. The generated code initializes it at mount time
+// using CompilePatterns.
 var knownPatterns = make(map[string]*regexp.Regexp)
 
 // knownPatternsLock is the mutex used to access knownPatterns
 var knownPatternsLock = &sync.RWMutex{}
 
+// compiledFormats and compiledPatterns hold copies of customFormats and knownPatterns made when
+// the service is compiled. ValidateFormat and ValidatePattern read them without locking.
+var compiledFormats, compiledPatterns atomic.Value
+
+// compileValidations snapshots the custom formats and the compiled patterns so that validating
+// requests does not require acquiring the locks that protect them.
+func compileValidations() {
+	customFormatsLock.RLock()
+	formats := make(map[Format]func(string) error, len(customFormats))
+	for f, v := range customFormats {
+		formats[f] = v
+	}
+	customFormatsLock.RUnlock()
+	knownPatternsLock.RLock()
+	patterns := make(map[string]*regexp.Regexp, len(knownPatterns))
+	for p, r := range knownPatterns {
+		patterns[p] = r
+	}
+	knownPatternsLock.RUnlock()
+	compiledFormats.Store(formats)
+	compiledPatterns.Store(patterns)
+}
+
+// ValidateTransition returns true if the state to can be reached from the state from given the
+// transitions indexed by originating state.
+func ValidateTransition(transitions map[string][]string, from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CompilePatterns compiles the given regular expressions ahead of time so that ValidatePattern
+// does not need to compile them while handling requests. The generated Mount functions call it
+// with the patterns used to validate the requests of the resource actions.
+func CompilePatterns(patterns ...string) {
+	knownPatternsLock.Lock()
+	defer knownPatternsLock.Unlock()
+	for _, p := range patterns {
+		if _, ok := knownPatterns[p]; !ok {
+			knownPatterns[p] = regexp.MustCompile(p) // DSL validation makes sure regexp is valid
+		}
+	}
+}
+
 // ValidatePattern returns an error if val does not match the regular expression p.
 // It makes an effort to minimize the number of times the regular expression needs to be compiled.
 func ValidatePattern(p string, val string) bool {
-	knownPatternsLock.RLock()
-	r, ok := knownPatterns[p]
-	knownPatternsLock.RUnlock()
+	patterns, _ := compiledPatterns.Load().(map[string]*regexp.Regexp)
+	r, ok := patterns[p]
+	if !ok {
+		knownPatternsLock.RLock()
+		r, ok = knownPatterns[p]
+		knownPatternsLock.RUnlock()
+	}
 	if !ok {
 		r = regexp.MustCompile(p) // DSL validation makes sure regexp is valid
 		knownPatternsLock.Lock()
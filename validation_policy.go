@@ -0,0 +1,69 @@
+package goa
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+)
+
+// ValidationPolicy decides whether the payload of a request is validated. It is called by the
+// generated code after the action middleware, including the security middleware, ran and before
+// the controller runs. The context contains the names of the controller and action handling the
+// request and the values recorded by the security middleware such as the granted scopes.
+type ValidationPolicy func(ctx context.Context, req *http.Request) bool
+
+// UseValidationPolicy sets the policy that decides whether request payloads are validated.
+// Payloads are always validated when no policy is set. Policies are typically used to reduce
+// the cost of service-to-service traffic by skipping or sampling the validation of payloads sent
+// by trusted internal callers, see SampleTrustedValidation.
+func (service *Service) UseValidationPolicy(policy ValidationPolicy) {
+	service.validationPolicy = policy
+}
+
+// ValidatePayload returns true if the payload of the given request must be validated according
+// to the service validation policy. The number of skipped validations is reported with the
+// "goa.validation.skipped" counter.
+func (service *Service) ValidatePayload(ctx context.Context, req *http.Request) bool {
+	if service.validationPolicy == nil || service.validationPolicy(ctx, req) {
+		return true
+	}
+	IncrCounter([]string{"goa", "validation", "skipped"}, 1.0)
+	return false
+}
+
+// SampleTrustedValidation returns a validation policy that always validates the payloads of the
+// requests for which trusted returns false and validates the payloads of the other requests with
+// the given probability, e.g. 0.01 validates one percent of the payloads sent by trusted callers.
+// trusted must identify callers using properties of the request that cannot be forged by external
+// clients such as the TLS client certificate or the network the request originates from.
+func SampleTrustedValidation(trusted func(req *http.Request) bool, rate float64) ValidationPolicy {
+	return func(ctx context.Context, req *http.Request) bool {
+		if !trusted(req) {
+			return true
+		}
+		return rate > 0 && (rate >= 1 || rand.Float64() < rate)
+	}
+}
+
+// DeferPayloadValidation records the function that validates the decoded request payload. The
+// generated unmarshalers record the validation instead of running it so that it runs once the
+// security middleware authenticated the caller, see ValidateRequestPayload.
+func (r *RequestData) DeferPayloadValidation(validate func() error) {
+	r.payloadValidator = validate
+}
+
+// ValidateRequestPayload runs the payload validation recorded with DeferPayloadValidation if the
+// service validation policy requires it. The generated handlers call it after the action
+// middleware ran so that policies may rely on the authenticated caller.
+func (service *Service) ValidateRequestPayload(ctx context.Context, req *http.Request) error {
+	r := ContextRequest(ctx)
+	if r == nil || r.payloadValidator == nil {
+		return nil
+	}
+	validate := r.payloadValidator
+	r.payloadValidator = nil
+	if !service.ValidatePayload(ctx, req) {
+		return nil
+	}
+	return validate()
+}
@@ -0,0 +1,91 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidatePayload", func() {
+	var service *goa.Service
+	var req *http.Request
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		var err error
+		req, err = http.NewRequest("POST", "/", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("validates payloads when no policy is set", func() {
+		Ω(service.ValidatePayload(context.Background(), req)).Should(BeTrue())
+	})
+
+	It("follows the service policy", func() {
+		service.UseValidationPolicy(func(context.Context, *http.Request) bool { return false })
+		Ω(service.ValidatePayload(context.Background(), req)).Should(BeFalse())
+	})
+})
+
+var _ = Describe("SampleTrustedValidation", func() {
+	var internal = func(req *http.Request) bool { return req.Header.Get("X-Internal") == "true" }
+	var external, trusted *http.Request
+
+	BeforeEach(func() {
+		external, _ = http.NewRequest("POST", "/", nil)
+		trusted, _ = http.NewRequest("POST", "/", nil)
+		trusted.Header.Set("X-Internal", "true")
+	})
+
+	It("always validates the payloads of untrusted callers", func() {
+		policy := goa.SampleTrustedValidation(internal, 0)
+		Ω(policy(context.Background(), external)).Should(BeTrue())
+	})
+
+	It("skips the validation of payloads sent by trusted callers", func() {
+		policy := goa.SampleTrustedValidation(internal, 0)
+		Ω(policy(context.Background(), trusted)).Should(BeFalse())
+	})
+
+	It("validates the payloads of trusted callers with the given rate", func() {
+		policy := goa.SampleTrustedValidation(internal, 1)
+		Ω(policy(context.Background(), trusted)).Should(BeTrue())
+	})
+})
+
+var _ = Describe("ValidateRequestPayload", func() {
+	var service *goa.Service
+	var ctx context.Context
+	var req *http.Request
+	var validated bool
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		var err error
+		req, err = http.NewRequest("POST", "/", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		ctx = goa.NewContext(context.Background(), nil, req, nil)
+		validated = false
+		goa.ContextRequest(ctx).DeferPayloadValidation(func() error {
+			validated = true
+			return goa.ErrBadRequest("invalid")
+		})
+	})
+
+	It("runs the deferred validation", func() {
+		Ω(service.ValidateRequestPayload(ctx, req)).Should(HaveOccurred())
+		Ω(validated).Should(BeTrue())
+	})
+
+	It("lets the policy use values set by the security middleware", func() {
+		service.UseValidationPolicy(func(ctx context.Context, _ *http.Request) bool {
+			return !goa.HasScope(goa.ContextGrantedScopes(ctx), "internal")
+		})
+		ctx = goa.WithGrantedScopes(ctx, []string{"internal"})
+		Ω(service.ValidateRequestPayload(ctx, req)).ShouldNot(HaveOccurred())
+		Ω(validated).Should(BeFalse())
+	})
+})
@@ -1,6 +1,9 @@
 package goa_test
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/goadesign/goa"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -369,4 +372,74 @@ var _ = Describe("ValidateFormat", func() {
 			})
 		})
 	})
+
+	Context("custom format", func() {
+		BeforeEach(func() {
+			f = goa.Format("semver")
+			goa.RegisterFormat("semver", func(v string) error {
+				if strings.Count(v, ".") != 2 {
+					return errors.New("must be MAJOR.MINOR.PATCH")
+				}
+				return nil
+			})
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "1.2"
+			})
+
+			It("does not validates", func() {
+				Ω(valErr).Should(MatchError("invalid semver value, must be MAJOR.MINOR.PATCH"))
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "1.2.3"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("unknown format", func() {
+		BeforeEach(func() {
+			f = goa.Format("unknown")
+		})
+
+		It("does not validates", func() {
+			Ω(valErr).Should(MatchError(`unknown format "unknown"`))
+		})
+	})
+
+	Context("standard format overridden with RegisterFormat", func() {
+		BeforeEach(func() {
+			f = goa.FormatEmail
+			val = "not an email"
+			goa.RegisterFormat("email", func(string) error { return nil })
+		})
+
+		It("uses the standard validation", func() {
+			Ω(valErr).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("IsKnownFormat", func() {
+	It("knows the standard formats", func() {
+		Ω(goa.IsKnownFormat(goa.FormatUUID)).Should(BeTrue())
+		Ω(goa.IsKnownFormat(goa.FormatPhone)).Should(BeTrue())
+	})
+
+	It("knows the formats registered with RegisterFormat", func() {
+		goa.RegisterFormat("isbn", func(string) error { return nil })
+		Ω(goa.IsKnownFormat("isbn")).Should(BeTrue())
+	})
+
+	It("does not know other formats", func() {
+		Ω(goa.IsKnownFormat("unknown")).Should(BeFalse())
+	})
 })
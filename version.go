@@ -0,0 +1,40 @@
+package goa
+
+import (
+	"context"
+	"net/http"
+)
+
+// VersionSelector describes how requests select an API version with a header or a query string
+// parameter set to the version name.
+type VersionSelector struct {
+	// Name of the version
+	Name string
+	// Header is the name of the header that selects the version if any.
+	Header string
+	// Query is the name of the query string parameter that selects the version if any.
+	Query string
+}
+
+// Selects returns true if the request selects the version.
+func (s VersionSelector) Selects(req *http.Request) bool {
+	if s.Header != "" && req.Header.Get(s.Header) == s.Name {
+		return true
+	}
+	return s.Query != "" && req.URL.Query().Get(s.Query) == s.Name
+}
+
+// RejectVersions returns a handler that returns an error of class ErrNotFound to requests that
+// select one of the given versions and calls h otherwise. The generated code uses it to hide the
+// resources that are not exposed by the versions selected with a header or a query string
+// parameter.
+func RejectVersions(h Handler, versions ...VersionSelector) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		for _, v := range versions {
+			if v.Selects(req) {
+				return ErrNotFound("resource is not exposed by this version", "version", v.Name)
+			}
+		}
+		return h(ctx, rw, req)
+	}
+}
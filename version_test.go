@@ -0,0 +1,79 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RejectVersions", func() {
+	var url string
+	var header string
+	var called bool
+	var err error
+
+	BeforeEach(func() {
+		url = "/accounts"
+		header = ""
+		called = false
+	})
+
+	JustBeforeEach(func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		h = goa.RejectVersions(h,
+			goa.VersionSelector{Name: "v1", Header: "X-Api-Version"},
+			goa.VersionSelector{Name: "v2", Query: "version"})
+		req, e := http.NewRequest("GET", url, nil)
+		Ω(e).ShouldNot(HaveOccurred())
+		if header != "" {
+			req.Header.Set("X-Api-Version", header)
+		}
+		err = h(context.Background(), httptest.NewRecorder(), req)
+	})
+
+	It("calls the handler when no version is selected", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	Context("with a header selecting a rejected version", func() {
+		BeforeEach(func() {
+			header = "v1"
+		})
+
+		It("returns a not found error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(404))
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with a query string parameter selecting a rejected version", func() {
+		BeforeEach(func() {
+			url = "/accounts?version=v2"
+		})
+
+		It("returns a not found error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with a header selecting another version", func() {
+		BeforeEach(func() {
+			header = "v3"
+		})
+
+		It("calls the handler", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(called).Should(BeTrue())
+		})
+	})
+})